@@ -0,0 +1,104 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/external/utils"
+)
+
+/**************************************************************************************************
+** ADDRESS_PARAMS are the path parameters holding an address, or a comma-separated list of them.
+**************************************************************************************************/
+var ADDRESS_PARAMS = map[string]bool{
+	`address`:   true,
+	`addresses`: true,
+	`token`:     true,
+	`vault`:     true,
+	`vaults`:    true,
+}
+
+/**************************************************************************************************
+** Resolving an ENS name takes two calls to the mainnet RPC, so a request can't hold more than
+** MAX_ENS_NAMES_PER_REQUEST names, and the resolutions not cached yet are rate limited per client
+** IP to ENS_RESOLUTIONS_BURST, one more being allowed every ENS_RESOLUTIONS_INTERVAL.
+**************************************************************************************************/
+const MAX_ENS_NAMES_PER_REQUEST = 5
+const ENS_RESOLUTIONS_BURST = 10
+const ENS_RESOLUTIONS_INTERVAL = 6 * time.Second
+
+/**************************************************************************************************
+** normalizeAddressParams rewrites the addresses of the path parameters before the handlers read
+** them: the hex addresses, checksummed or not, are set in their EIP-55 checksummed form, and the
+** ENS names are normalized (ENSIP-15) and resolved on mainnet. A value that is neither is left as
+** is, for the handler to reject it as an invalid address.
+**************************************************************************************************/
+func normalizeAddressParams() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ensNames := make(map[string]bool)
+		for _, param := range c.Params {
+			if !ADDRESS_PARAMS[param.Key] {
+				continue
+			}
+			for _, value := range strings.Split(param.Value, `,`) {
+				if name, ok := getENSName(value); ok {
+					ensNames[name] = true
+				}
+			}
+		}
+		if len(ensNames) > MAX_ENS_NAMES_PER_REQUEST {
+			utils.SendError(c, utils.NewError(utils.ERROR_INVALID_PARAM, `too many ENS names, at most `+strconv.Itoa(MAX_ENS_NAMES_PER_REQUEST)+` can be resolved per request`))
+			return
+		}
+		for name := range ensNames {
+			if ethereum.IsENSNameCached(name) {
+				continue
+			}
+			if !getLimiter(`ens:`+c.ClientIP(), ENS_RESOLUTIONS_INTERVAL, ENS_RESOLUTIONS_BURST, time.Hour).Allow() {
+				utils.SendError(c, utils.NewError(utils.ERROR_RATE_LIMITED, `too many ENS resolutions, retry later or use the addresses`))
+				return
+			}
+		}
+
+		for i, param := range c.Params {
+			if !ADDRESS_PARAMS[param.Key] {
+				continue
+			}
+			values := strings.Split(param.Value, `,`)
+			for j, value := range values {
+				values[j] = normalizeAddress(value)
+			}
+			c.Params[i].Value = strings.Join(values, `,`)
+		}
+		c.Next()
+	}
+}
+
+/**************************************************************************************************
+** getENSName returns the normalized ENS name of a value, false if the value is an address or is
+** not a valid name.
+**************************************************************************************************/
+func getENSName(value string) (string, bool) {
+	trimmed := strings.TrimSpace(value)
+	if common.IsHexAddress(trimmed) {
+		return ``, false
+	}
+	return ethereum.NormalizeENSName(trimmed)
+}
+
+func normalizeAddress(value string) string {
+	trimmed := strings.TrimSpace(value)
+	if common.IsHexAddress(trimmed) {
+		return common.HexToAddress(trimmed).Hex()
+	}
+	if normalized, ok := getENSName(trimmed); ok {
+		if address, ok := ethereum.ResolveENSName(normalized); ok {
+			return address.Hex()
+		}
+	}
+	return value
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+/**************************************************************************************************
+** TestNormalizeAddressParams checks that the hex addresses are checksummed, that a request can't
+** hold more than MAX_ENS_NAMES_PER_REQUEST names, and that the resolutions are rate limited per
+** client IP. The mainnet RPC is not configured in the tests, so the names are not resolved.
+**************************************************************************************************/
+func TestNormalizeAddressParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(normalizeAddressParams())
+	router.GET(`/:chainID/vaults/:addresses`, func(c *gin.Context) {
+		c.String(http.StatusOK, c.Param(`addresses`))
+	})
+	defer limiterSet.Delete(`ens:10.0.0.3`)
+
+	request := func(addresses string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, `/1/vaults/`+addresses, nil)
+		req.RemoteAddr = `10.0.0.3:1234`
+		router.ServeHTTP(w, req)
+		return w
+	}
+	names := func(prefix string, count int) string {
+		list := []string{}
+		for i := 0; i < count; i++ {
+			list = append(list, prefix+string(rune('a'+i))+`.eth`)
+		}
+		return strings.Join(list, `,`)
+	}
+
+	w := request(`0xfeb4acf3df3cdea7399794d0869ef76a6efaff52`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `0xFEB4acf3df3cDEA7399794D0869ef76A6EfAff52`, w.Body.String(), "The addresses should be checksummed")
+
+	w = request(names(`too-many-`, MAX_ENS_NAMES_PER_REQUEST+1))
+	assert.Equal(t, http.StatusBadRequest, w.Code, "A request can't hold more than the maximum of names")
+
+	w = request(names(`first-`, MAX_ENS_NAMES_PER_REQUEST) + `,first-a.eth,First-A.eth`)
+	assert.Equal(t, http.StatusOK, w.Code, "The names should be counted once")
+	w = request(names(`second-`, MAX_ENS_NAMES_PER_REQUEST))
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = request(names(`third-`, 1))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "The resolutions of the IP should be limited")
+	w = request(`0xfeb4acf3df3cdea7399794d0869ef76a6efaff52`)
+	assert.Equal(t, http.StatusOK, w.Code, "The addresses should not be limited")
+}
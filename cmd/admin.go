@@ -0,0 +1,115 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/external/utils"
+	"github.com/yearn/ydaemon/internal/backfill"
+	"github.com/yearn/ydaemon/processes/apr"
+)
+
+/**************************************************************************************************
+** restrictAdmin requires the `Authorization: Bearer <ADMIN_API_KEY>` header, the admin routes
+** being disabled when the key is not set.
+**************************************************************************************************/
+func restrictAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.TrimPrefix(c.GetHeader(`Authorization`), `Bearer `)
+		if env.ADMIN_API_KEY == `` || subtle.ConstantTimeCompare([]byte(token), []byte(env.ADMIN_API_KEY)) != 1 {
+			utils.SendError(c, utils.NewError(utils.ERROR_UNAUTHORIZED, `a valid bearer token is required`))
+			return
+		}
+		c.Next()
+	}
+}
+
+/**************************************************************************************************
+** getBackfillProgress returns the progress of the backfills of every chain, with the chains whose
+** backfills are paused.
+**************************************************************************************************/
+func getBackfillProgress(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"concurrency":  env.BACKFILL_CONCURRENCY,
+		"pausedChains": backfill.ListPausedChains(),
+		"backfills":    backfill.ListProgress(),
+	})
+}
+
+/**************************************************************************************************
+** pauseBackfills and resumeBackfills pause and resume the backfills of a chain. A paused backfill
+** stops before its next request, and resumes from there on the refresh following the resume.
+**************************************************************************************************/
+func pauseBackfills(c *gin.Context) {
+	chainID, ok := helpers.AssertChainID(c.Param("chainID"))
+	if !ok {
+		utils.SendChainIDError(c, c.Param("chainID"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"chainID": chainID, "isPaused": true, "changed": backfill.Pause(chainID)})
+}
+
+func resumeBackfills(c *gin.Context) {
+	chainID, ok := helpers.AssertChainID(c.Param("chainID"))
+	if !ok {
+		utils.SendChainIDError(c, c.Param("chainID"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"chainID": chainID, "isPaused": false, "changed": backfill.Resume(chainID)})
+}
+
+/**************************************************************************************************
+** parseSimulatedFee reads a fee of the what-if simulation from the query, in basis points. It
+** returns nil when the fee is not set, and false when it is not a valid fee.
+**************************************************************************************************/
+func parseSimulatedFee(c *gin.Context, name string) (*uint64, bool) {
+	raw, ok := c.GetQuery(name)
+	if !ok {
+		return nil, true
+	}
+	fee, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || fee > apr.MAX_SIMULATED_FEE_BPS {
+		utils.SendError(c, utils.NewError(utils.ERROR_INVALID_PARAM, name+` must be a fee in basis points, from 0 to 10000`))
+		return nil, false
+	}
+	return &fee, true
+}
+
+/**************************************************************************************************
+** simulateFeeChange returns the forward APY of a vault recomputed with the performanceFee and the
+** managementFee of the query, in basis points, for governance to preview the impact of a fee
+** proposal on the depositors before queuing it. A fee not set keeps its current value.
+**************************************************************************************************/
+func simulateFeeChange(c *gin.Context) {
+	chainID, ok := helpers.AssertChainID(c.Param("chainID"))
+	if !ok {
+		utils.SendChainIDError(c, c.Param("chainID"))
+		return
+	}
+	if !common.IsHexAddress(c.Param("vault")) {
+		utils.SendError(c, utils.NewError(utils.ERROR_INVALID_ADDRESS, `invalid vault address: `+c.Param("vault")))
+		return
+	}
+	vaultAddress := common.HexToAddress(c.Param("vault"))
+	performanceFee, ok := parseSimulatedFee(c, `performanceFee`)
+	if !ok {
+		return
+	}
+	managementFee, ok := parseSimulatedFee(c, `managementFee`)
+	if !ok {
+		return
+	}
+
+	simulation, ok := apr.SimulateForwardAPYWithFees(chainID, vaultAddress, performanceFee, managementFee)
+	if !ok {
+		utils.SendError(c, utils.NewError(utils.ERROR_VAULT_NOT_INDEXED, `no forward APY for vault `+vaultAddress.Hex()).WithChainID(chainID).WithAddress(vaultAddress.Hex()))
+		return
+	}
+	c.JSON(http.StatusOK, simulation)
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**************************************************************************************************
+** The allowances are read on chain for any address, so they are rate limited per client IP to
+** ALLOWANCES_BURST requests, one more being allowed every ALLOWANCES_INTERVAL.
+**************************************************************************************************/
+const ALLOWANCES_BURST = 20
+const ALLOWANCES_INTERVAL = 3 * time.Second
+
+/**************************************************************************************************
+** limitAllowances rate limits the allowances requests per client IP.
+**************************************************************************************************/
+func limitAllowances() gin.HandlerFunc {
+	return limitPerClientIP(`allowances`, ALLOWANCES_INTERVAL, ALLOWANCES_BURST, time.Hour, `too many allowances requests, retry later`)
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -14,128 +16,139 @@ import (
 type GetSimplifiedVaults func(c *gin.Context) ([]vaults.TSimplifiedExternalVault, error)
 type GetLegacyExternalVaults func(c *gin.Context) []vaults.TExternalVault
 type GetCustomVaults func(c *gin.Context) []vaults.TRotkiVaults
+type GetVaultAPYFigure func(c *gin.Context) (vaults.TVaultAPYFigure, bool)
 
 var simplifiedVaultsSingleflight singleflight.Group
 var legacyVaultsSingleflight singleflight.Group
 var customVaultsSingleflight singleflight.Group
 
-func CacheSimplifiedVaults(cachingStore *cache.Cache, expire time.Duration, handle GetSimplifiedVaults) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		cacheKey := c.Request.URL.String()
-
-		// Check cache first
-		if result, found := cachingStore.Get(cacheKey); found && result != nil {
-			vaults, ok := result.([]vaults.TSimplifiedExternalVault)
-			if ok && len(vaults) > 0 {
-				c.JSON(http.StatusOK, vaults)
-				return
-			}
-		}
-
-		// Use singleflight to prevent thundering herd on cache miss
-		// Only one goroutine will execute the handler, others will wait for the result
-		result, err, shared := simplifiedVaultsSingleflight.Do(cacheKey, func() (interface{}, error) {
-			vaults, err := handle(c)
-			if err != nil {
-				return nil, err
-			}
+/**************************************************************************************************
+** tRenderedResponse is a list of vaults already rendered as JSON, with the number of vaults in it.
+** It is cached and served as is: the clients hitting the same URL at the same time share a single
+** rendering instead of serializing the same vaults each.
+**************************************************************************************************/
+type tRenderedResponse struct {
+	body  []byte
+	count int
+}
 
-			// Cache the result
-			if len(vaults) > 0 {
-				cachingStore.Set(cacheKey, vaults, expire)
-				logs.Info(`Cache miss with`, len(vaults), `vaults`)
-			}
+/**************************************************************************************************
+** serveCoalesced serves the rendered response cached for the URL of the request. On a cache miss,
+** only one of the concurrent requests for the URL builds and renders the vaults, the others waiting
+** for its response (singleflight). The empty lists are not cached. The responses with localized
+** strings are cached per locale, the locale being negotiated from a header too. The cached
+** responses are keyed by the store version of the request, a snapshot changing the vaults making
+** the next requests build them again.
+**************************************************************************************************/
+func serveCoalesced(
+	c *gin.Context,
+	cachingStore *cache.Cache,
+	expire time.Duration,
+	group *singleflight.Group,
+	kind string,
+	locale string,
+	build func() (interface{}, int, error),
+) {
+	cacheKey := c.Request.URL.String() + `@` + c.Writer.Header().Get(vaults.STORE_VERSION_HEADER)
+	if locale != `` {
+		cacheKey += `#` + locale
+		c.Header(`Content-Language`, locale)
+		c.Header(`Vary`, `Accept-Language`)
+	}
 
-			return vaults, nil
-		})
+	// Check cache first
+	if result, found := cachingStore.Get(cacheKey); found && result != nil {
+		if rendered, ok := result.(tRenderedResponse); ok && rendered.count > 0 {
+			c.Data(http.StatusOK, `application/json; charset=utf-8`, rendered.body)
+			return
+		}
+	}
 
+	// Use singleflight to prevent thundering herd on cache miss
+	// Only one goroutine will build and render the response, others will wait for the result
+	result, err, shared := group.Do(cacheKey, func() (interface{}, error) {
+		vaults, count, err := build()
 		if err != nil {
-			logs.Error(`Error while getting simplified vaults`, err)
-			return
+			return nil, err
+		}
+		body, err := json.Marshal(vaults)
+		if err != nil {
+			return nil, err
 		}
+		rendered := tRenderedResponse{body: body, count: count}
 
-		if shared {
-			logs.Info(`Singleflight shared result with`, len(result.([]vaults.TSimplifiedExternalVault)), `vaults`)
+		// Cache the result
+		if count > 0 {
+			cachingStore.Set(cacheKey, rendered, expire)
+			logs.Info(`Cache miss with`, count, kind)
 		}
+		return rendered, nil
+	})
 
-		c.JSON(http.StatusOK, result)
+	if err != nil {
+		logs.Error(`Error while getting `+kind, err)
+		return
 	}
+
+	rendered := result.(tRenderedResponse)
+	if shared {
+		logs.Info(`Singleflight shared result with`, rendered.count, kind)
+	}
+	c.Data(http.StatusOK, `application/json; charset=utf-8`, rendered.body)
 }
 
-func CacheLegacyVaults(cachingStore *cache.Cache, expire time.Duration, handle GetLegacyExternalVaults) gin.HandlerFunc {
+func CacheSimplifiedVaults(cachingStore *cache.Cache, expire time.Duration, handle GetSimplifiedVaults) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		cacheKey := c.Request.URL.String()
-
-		// Check cache first
-		if result, found := cachingStore.Get(cacheKey); found && result != nil {
-			vaults, ok := result.([]vaults.TExternalVault)
-			if ok && len(vaults) > 0 {
-				c.JSON(http.StatusOK, vaults)
-				return
-			}
-		}
+		serveCoalesced(c, cachingStore, expire, &simplifiedVaultsSingleflight, `vaults`, vaults.NegotiateLocale(c), func() (interface{}, int, error) {
+			vaults, err := handle(c)
+			return vaults, len(vaults), err
+		})
+	}
+}
 
-		// Use singleflight to prevent thundering herd on cache miss
-		result, err, shared := legacyVaultsSingleflight.Do(cacheKey, func() (interface{}, error) {
+func CacheLegacyVaults(cachingStore *cache.Cache, expire time.Duration, handle GetLegacyExternalVaults) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		serveCoalesced(c, cachingStore, expire, &legacyVaultsSingleflight, `legacy vaults`, ``, func() (interface{}, int, error) {
 			vaults := handle(c)
-
-			// Cache the result
-			if len(vaults) > 0 {
-				cachingStore.Set(cacheKey, vaults, expire)
-				logs.Info(`Cache miss with`, len(vaults), `legacy vaults`)
-			}
-
-			return vaults, nil
+			return vaults, len(vaults), nil
 		})
-
-		if err != nil {
-			logs.Error(`Error while getting legacy vaults`, err)
-			return
-		}
-
-		if shared {
-			logs.Info(`Singleflight shared result with`, len(result.([]vaults.TExternalVault)), `legacy vaults`)
-		}
-
-		c.JSON(http.StatusOK, result)
 	}
 }
 
 func CacheCustomVaults(cachingStore *cache.Cache, expire time.Duration, handle GetCustomVaults) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		cacheKey := c.Request.URL.String()
+		serveCoalesced(c, cachingStore, expire, &customVaultsSingleflight, `custom vaults`, ``, func() (interface{}, int, error) {
+			vaults := handle(c)
+			return vaults, len(vaults), nil
+		})
+	}
+}
+
+/**************************************************************************************************
+** CacheVaultAPYFigure serves the APY of a vault as a plain number (`0.0523`), or as a tiny JSON
+** with `?format=json`, for the bots and the spreadsheets (`IMPORTDATA`). The figure is cached in
+** memory and by the clients and proxies for `expire`.
+**************************************************************************************************/
+func CacheVaultAPYFigure(cachingStore *cache.Cache, expire time.Duration, handle GetVaultAPYFigure) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cacheKey := `apy/` + c.Param(`chainID`) + `/` + c.Param(`address`) + `@` + c.Writer.Header().Get(vaults.STORE_VERSION_HEADER)
 
-		// Check cache first
+		var figure vaults.TVaultAPYFigure
 		if result, found := cachingStore.Get(cacheKey); found && result != nil {
-			vaults, ok := result.([]vaults.TRotkiVaults)
-			if ok && len(vaults) > 0 {
-				c.JSON(http.StatusOK, vaults)
+			figure = result.(vaults.TVaultAPYFigure)
+		} else {
+			var ok bool
+			if figure, ok = handle(c); !ok {
 				return
 			}
+			cachingStore.Set(cacheKey, figure, expire)
 		}
 
-		// Use singleflight to prevent thundering herd on cache miss
-		result, err, shared := customVaultsSingleflight.Do(cacheKey, func() (interface{}, error) {
-			vaults := handle(c)
-
-			// Cache the result
-			if len(vaults) > 0 {
-				cachingStore.Set(cacheKey, vaults, expire)
-				logs.Info(`Cache miss with`, len(vaults), `custom vaults`)
-			}
-
-			return vaults, nil
-		})
-
-		if err != nil {
-			logs.Error(`Error while getting custom vaults`, err)
+		c.Header(`Cache-Control`, `public, max-age=`+strconv.Itoa(int(expire.Seconds())))
+		if c.Query(`format`) == `json` {
+			c.JSON(http.StatusOK, figure)
 			return
 		}
-
-		if shared {
-			logs.Info(`Singleflight shared result with`, len(result.([]vaults.TRotkiVaults)), `custom vaults`)
-		}
-
-		c.JSON(http.StatusOK, result)
+		c.String(http.StatusOK, strconv.FormatFloat(figure.APY, 'f', -1, 64))
 	}
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/yearn/ydaemon/external/vaults"
+)
+
+/**************************************************************************************************
+** TestCacheVaultAPYFigure checks that the APY figure is served as a plain number or as JSON with
+** `?format=json`, computed once per vault for the duration of the cache, and that the failed
+** requests are not cached.
+**************************************************************************************************/
+func TestCacheVaultAPYFigure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	calls := 0
+	handler := func(c *gin.Context) (vaults.TVaultAPYFigure, bool) {
+		calls++
+		if c.Param(`address`) == `missing` {
+			c.JSON(http.StatusNotFound, gin.H{`error`: `not found`})
+			return vaults.TVaultAPYFigure{}, false
+		}
+		return vaults.TVaultAPYFigure{ChainID: 1, Address: c.Param(`address`), APY: 0.0523, Type: vaults.APY_FIGURE_FORWARD}, true
+	}
+	router := gin.New()
+	router.GET(`apy/:chainID/:address`, CacheVaultAPYFigure(cache.New(time.Minute, time.Minute), 5*time.Minute, handler))
+
+	testCases := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedBody   string
+		expectedCalls  int
+	}{
+		{name: "Plain number", path: `/apy/1/0xA`, expectedStatus: http.StatusOK, expectedBody: `0.0523`, expectedCalls: 1},
+		{name: "Cached", path: `/apy/1/0xA`, expectedStatus: http.StatusOK, expectedBody: `0.0523`, expectedCalls: 1},
+		{name: "JSON from the cache", path: `/apy/1/0xA?format=json`, expectedStatus: http.StatusOK, expectedCalls: 1},
+		{name: "Other vault", path: `/apy/1/0xB`, expectedStatus: http.StatusOK, expectedBody: `0.0523`, expectedCalls: 2},
+		{name: "Failure", path: `/apy/1/missing`, expectedStatus: http.StatusNotFound, expectedCalls: 3},
+		{name: "Failure not cached", path: `/apy/1/missing`, expectedStatus: http.StatusNotFound, expectedCalls: 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tc.path, nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedCalls, calls)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, `public, max-age=300`, w.Header().Get(`Cache-Control`))
+			if tc.expectedBody != `` {
+				assert.Equal(t, tc.expectedBody, w.Body.String())
+				return
+			}
+			figure := vaults.TVaultAPYFigure{}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &figure))
+			assert.Equal(t, 0.0523, figure.APY)
+			assert.Equal(t, vaults.APY_FIGURE_FORWARD, figure.Type)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/helpers"
+)
+
+/**************************************************************************************************
+** The fields of the responses are in camelCase. The `casing` query parameter selects the casing
+** profile of a response: `camel`, the default, serves it as is, and `snake` renames its fields in
+** snake_case, for the consumers written in Python. An unknown profile is ignored.
+**************************************************************************************************/
+const (
+	CASING_CAMEL = `camel`
+	CASING_SNAKE = `snake`
+)
+
+/**************************************************************************************************
+** CAMEL_CASE_KEY matches the field names to rename. The keys of the maps keyed by address, chain
+** ID or symbol do not match it and are kept as is.
+**************************************************************************************************/
+var CAMEL_CASE_KEY = regexp.MustCompile(`^[a-z][A-Za-z0-9]*$`)
+
+/**************************************************************************************************
+** tCasingWriter holds the body of a response until the handler is done, for it to be renamed.
+**************************************************************************************************/
+type tCasingWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *tCasingWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *tCasingWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+/**************************************************************************************************
+** applyCasingProfile renames the fields of the JSON responses of the requests asking for the
+** `snake` casing profile. The other responses, and the ones that are not valid JSON, are served
+** as the handler wrote them.
+**************************************************************************************************/
+func applyCasingProfile() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.EqualFold(c.Query(`casing`), CASING_SNAKE) {
+			c.Next()
+			return
+		}
+
+		writer := &tCasingWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		body := writer.body.Bytes()
+		if strings.Contains(writer.Header().Get(`Content-Type`), `application/json`) {
+			if renamed, err := renameJSONFields(body, helpers.ToSnakeCase); err == nil {
+				body = renamed
+			}
+		}
+		writer.Header().Del(`Content-Length`)
+		writer.ResponseWriter.Write(body)
+	}
+}
+
+/**************************************************************************************************
+** renameJSONFields renames the fields of a JSON document with rename, the numbers being kept as
+** written.
+**************************************************************************************************/
+func renameJSONFields(body []byte, rename func(string) string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	return json.Marshal(renameFields(document, rename))
+}
+
+func renameFields(value interface{}, rename func(string) string) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		renamed := make(map[string]interface{}, len(typed))
+		for key, field := range typed {
+			if CAMEL_CASE_KEY.MatchString(key) {
+				key = rename(key)
+			}
+			renamed[key] = renameFields(field, rename)
+		}
+		return renamed
+	case []interface{}:
+		for i, item := range typed {
+			typed[i] = renameFields(item, rename)
+		}
+		return typed
+	default:
+		return value
+	}
+}
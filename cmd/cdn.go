@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/external/vaults"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The prefixes of the routes serving the APY, TVL or price of the vaults, purged from the CDN when
+** they change. A prefix purges every URL starting with it, query strings included:
+** - CDN_VAULT_ROUTES for every changed vault, with its address checksummed and lowercased,
+** - CDN_CHAIN_ROUTES for every chain with a changed vault,
+** - CDN_GLOBAL_ROUTES, the multi-chain routes, on every change. `/vaults` covers all the lists of
+**   /vaults/*.
+**************************************************************************************************/
+var CDN_VAULT_ROUTES = []string{
+	`/{chainID}/vaults/{address}`,
+	`/{chainID}/vault/{address}`,
+	`/apy/{chainID}/{address}`,
+	`/vaults/{chainID}/{address}/`,
+}
+var CDN_CHAIN_ROUTES = []string{
+	`/{chainID}/vaults/`,
+	`/aggregates/{chainID}/`,
+	`/compare/{chainID}/`,
+	`/tokens/{chainID}/`,
+	`/fees/{chainID}`,
+}
+var CDN_GLOBAL_ROUTES = []string{
+	`/vaults`,
+	`/integrations/defillama/`,
+	`/rotki/`,
+	`/partners/`,
+	`/protocols/`,
+	`/strategies/leaderboard`,
+}
+
+/**************************************************************************************************
+** The Cloudflare API purges at most CLOUDFLARE_PURGE_BATCH_SIZE prefixes per request.
+**************************************************************************************************/
+const CLOUDFLARE_PURGE_URL = `https://api.cloudflare.com/client/v4/zones/`
+const CLOUDFLARE_PURGE_BATCH_SIZE = 30
+
+/**************************************************************************************************
+** storeVersionHeader sets the store version in the X-Store-Version header of every response: the
+** version of the chain of the route, or the version of all the chains for the multi-chain routes.
+** The responses served for the same URL with the same version hold the same data, making them
+** safe to cache until the version changes.
+**************************************************************************************************/
+func storeVersionHeader() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version := storage.GetStoreVersion()
+		if chainID, err := strconv.ParseUint(c.Param(`chainID`), 10, 64); err == nil {
+			version = storage.GetChainVersion(chainID)
+		}
+		c.Header(vaults.STORE_VERSION_HEADER, strconv.FormatUint(version, 10))
+		c.Next()
+	}
+}
+
+/**************************************************************************************************
+** TriggerCDNPurge purges from the Cloudflare cache the responses of the routes backed by the vaults
+** of a chain changed by the store version, when CLOUDFLARE_ZONE_ID and CLOUDFLARE_API_TOKEN are set.
+**************************************************************************************************/
+func TriggerCDNPurge(chainID uint64, version uint64, changedVaults []common.Address) {
+	if env.CLOUDFLARE_ZONE_ID == `` || env.CLOUDFLARE_API_TOKEN == `` || len(changedVaults) == 0 {
+		return
+	}
+
+	prefixes := listPurgedPrefixes(chainID, changedVaults)
+	for start := 0; start < len(prefixes); start += CLOUDFLARE_PURGE_BATCH_SIZE {
+		batch := prefixes[start:min(start+CLOUDFLARE_PURGE_BATCH_SIZE, len(prefixes))]
+		if err := purgeCloudflarePrefixes(batch); err != nil {
+			logs.Error(`Failed to purge the CDN for chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+			return
+		}
+	}
+	logs.Info(`Purged ` + strconv.Itoa(len(prefixes)) + ` prefixes from the CDN for chain ` + strconv.FormatUint(chainID, 10) +
+		` (version ` + strconv.FormatUint(version, 10) + `, ` + strconv.Itoa(len(changedVaults)) + ` vaults changed)`)
+}
+
+/**************************************************************************************************
+** listPurgedPrefixes lists the prefixes of the public URLs of the routes backed by the changed
+** vaults of a chain. Cloudflare expects the prefixes without their scheme.
+**************************************************************************************************/
+func listPurgedPrefixes(chainID uint64, changedVaults []common.Address) []string {
+	host := strings.TrimPrefix(strings.TrimPrefix(env.CDN_PUBLIC_URL, `https://`), `http://`)
+	chainIDStr := strconv.FormatUint(chainID, 10)
+	prefixes := []string{}
+	for _, vaultAddress := range changedVaults {
+		for _, address := range []string{vaultAddress.Hex(), strings.ToLower(vaultAddress.Hex())} {
+			for _, route := range CDN_VAULT_ROUTES {
+				route = strings.ReplaceAll(route, `{chainID}`, chainIDStr)
+				prefixes = append(prefixes, host+strings.ReplaceAll(route, `{address}`, address))
+			}
+		}
+	}
+	for _, route := range CDN_CHAIN_ROUTES {
+		prefixes = append(prefixes, host+strings.ReplaceAll(route, `{chainID}`, chainIDStr))
+	}
+	for _, route := range CDN_GLOBAL_ROUTES {
+		prefixes = append(prefixes, host+route)
+	}
+	return prefixes
+}
+
+func purgeCloudflarePrefixes(prefixes []string) error {
+	body, err := json.Marshal(map[string][]string{`prefixes`: prefixes})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, CLOUDFLARE_PURGE_URL+env.CLOUDFLARE_ZONE_ID+`/purge_cache`, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(`Content-Type`, `application/json`)
+	req.Header.Set(`Authorization`, `Bearer `+env.CLOUDFLARE_API_TOKEN)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New(`unexpected status ` + strconv.Itoa(resp.StatusCode))
+	}
+	return nil
+}
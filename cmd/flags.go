@@ -9,6 +9,7 @@ import (
 var chains = []uint64{}
 var endBlock *uint64
 var process TProcess
+var output string
 
 func initFlags() {
 	/**********************************************************************************************
@@ -31,6 +32,23 @@ func initFlags() {
 	** Default: daemon
 	**********************************************************************************************/
 	rawProcess := flag.String(`process`, `daemon`, `Define the process to run: --process daemon`)
+
+	/**********************************************************************************************
+	** Flag group: Shards
+	** Description: The instances behind the aggregation proxy and the chains they own. Only used
+	** with --process proxy.
+	** Default: none
+	**********************************************************************************************/
+	rawShards := flag.String(`shards`, ``, `List of shards for the proxy: --shards "1,10=http://ydaemon-a:8080;137,250=http://ydaemon-b:8080"`)
+
+	/**********************************************************************************************
+	** Flag group: Output
+	** Description: The directory the subgraph entities, the client types, the static API or the
+	** contract bindings are written to. Only used with --process export, codegen, static and
+	** bindings.
+	** Default: ./data/export
+	**********************************************************************************************/
+	flag.StringVar(&output, `output`, `./data/export`, `Directory of the subgraph export, of the generated client types, of the static API and of the contract bindings: --output ./data/export`)
 	flag.Parse()
 	if *endBlock == 0 {
 		endBlock = nil
@@ -39,5 +57,9 @@ func initFlags() {
 	logs.Info(`Initializing chains...`)
 	handleChainsInitialization(rawChains)
 	logs.Info(`Initializing process...`)
-	handleProcessInitialization(rawProcess)
+	process = handleProcessInitialization(rawProcess)
+	if process == ProcessProxy {
+		logs.Info(`Initializing shards...`)
+		handleShardsInitialization(rawShards)
+	}
 }
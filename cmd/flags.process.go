@@ -3,9 +3,36 @@ package main
 type TProcess string
 
 const (
-	ProcessServer TProcess = "server"
+	ProcessServer   TProcess = "server"
+	ProcessProxy    TProcess = "proxy"
+	ProcessExport   TProcess = "export"
+	ProcessCodegen  TProcess = "codegen"
+	ProcessStatic   TProcess = "static"
+	ProcessBindings TProcess = "bindings"
 )
 
+/**************************************************************************************************
+** handleProcessInitialization returns the process to run. `proxy` runs the aggregation proxy in
+** front of the shards, `export` writes the subgraph entities of the stored data and exits,
+** `codegen` writes the client types of the API models and exits, `static` writes the public API of
+** the stored data as JSON files and exits, `bindings` writes the Go bindings of the ABIs of
+** ABI_DIRECTORY and exits, anything else runs the regular daemon.
+**************************************************************************************************/
 func handleProcessInitialization(rawProcess *string) TProcess {
+	if rawProcess == nil {
+		return ProcessServer
+	}
+	switch TProcess(*rawProcess) {
+	case ProcessProxy:
+		return ProcessProxy
+	case ProcessExport:
+		return ProcessExport
+	case ProcessCodegen:
+		return ProcessCodegen
+	case ProcessStatic:
+		return ProcessStatic
+	case ProcessBindings:
+		return ProcessBindings
+	}
 	return ProcessServer
 }
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+/**************************************************************************************************
+** TShard is a yDaemon instance owning a subset of the chains, as seen by the aggregation proxy.
+**************************************************************************************************/
+type TShard struct {
+	ChainIDs []uint64
+	URL      *url.URL
+}
+
+var shards = []TShard{}
+
+/**************************************************************************************************
+** handleShardsInitialization parses the shards of the proxy process. The format is a list of
+** `chainIDs=URL` separated by semicolons, e.g.:
+** --shards "1,10=http://ydaemon-a:8080;137,250,8453=http://ydaemon-b:8080"
+** A chain can only be owned by one shard, the first one wins.
+**************************************************************************************************/
+func handleShardsInitialization(rawShards *string) []TShard {
+	if rawShards == nil || strings.TrimSpace(*rawShards) == `` {
+		return shards
+	}
+
+	ownedChains := make(map[uint64]bool)
+	for _, rawShard := range strings.Split(*rawShards, `;`) {
+		parts := strings.SplitN(strings.TrimSpace(rawShard), `=`, 2)
+		if len(parts) != 2 {
+			logs.Error(`Invalid shard: ` + rawShard)
+			continue
+		}
+		shardURL, err := url.Parse(strings.TrimSpace(parts[1]))
+		if err != nil || shardURL.Host == `` {
+			logs.Error(`Invalid shard URL: ` + parts[1])
+			continue
+		}
+
+		shard := TShard{URL: shardURL}
+		for _, chainIDString := range strings.Split(parts[0], `,`) {
+			chainID, err := strconv.ParseUint(strings.TrimSpace(chainIDString), 10, 64)
+			if err != nil {
+				logs.Error(`Invalid chain ID: ` + chainIDString)
+				continue
+			}
+			if _, ok := env.GetChain(chainID); !ok {
+				logs.Error(`Unsupported chain ID: ` + chainIDString)
+				continue
+			}
+			if ownedChains[chainID] {
+				logs.Error(`Chain ID already owned by another shard: ` + chainIDString)
+				continue
+			}
+			ownedChains[chainID] = true
+			shard.ChainIDs = append(shard.ChainIDs, chainID)
+		}
+		if len(shard.ChainIDs) > 0 {
+			shards = append(shards, shard)
+		}
+	}
+	return shards
+}
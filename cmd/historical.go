@@ -0,0 +1,29 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**************************************************************************************************
+** The forward APR at a past block (`?block=`) is read on the archive node, so these requests are
+** rate limited per client IP to HISTORICAL_APY_BURST requests, one more being allowed every
+** HISTORICAL_APY_INTERVAL. The requests without block are not limited.
+**************************************************************************************************/
+const HISTORICAL_APY_BURST = 10
+const HISTORICAL_APY_INTERVAL = 6 * time.Second
+
+/**************************************************************************************************
+** limitHistoricalAPY rate limits the historical forward APR requests per client IP.
+**************************************************************************************************/
+func limitHistoricalAPY() gin.HandlerFunc {
+	limit := limitPerClientIP(`historical`, HISTORICAL_APY_INTERVAL, HISTORICAL_APY_BURST, time.Hour, `too many historical requests, retry later`)
+	return func(c *gin.Context) {
+		if c.Query(`block`) == `` {
+			c.Next()
+			return
+		}
+		limit(c)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/patrickmn/go-cache"
 	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/external/utils"
 	"golang.org/x/time/rate"
 )
 
@@ -45,15 +46,11 @@ func NewRateLimiter(abort func(*gin.Context)) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		/******************************************************************************************
 		** Retrieve the origin from the request header and use it as the key for the rate limiter.
-		** If the origin is not present, we use an empty string as the key.
+		** If the origin is not present, we use an empty string as the origin. The key is prefixed
+		** with `origin:` for a crafted origin not to match the `prefix:IP` key of another limiter.
 		******************************************************************************************/
-		k := ``
 		origin := c.Request.Header.Get("Origin")
-		if len(origin) == 0 {
-			k = ``
-		} else {
-			k = origin
-		}
+		k := `origin:` + origin
 
 		/******************************************************************************************
 		** Allows the requests from the allowlist without rate limiting. This is to allow us to
@@ -67,18 +64,44 @@ func NewRateLimiter(abort func(*gin.Context)) gin.HandlerFunc {
 		/******************************************************************************************
 		** Otherwise, we use the rate limiter to limit the requests to 10 qps/clientIp and permit
 		******************************************************************************************/
-		limiter, ok := limiterSet.Get(k)
-		if !ok {
-			var expire time.Duration
-			// limit 25 query per second per origin and permit bursts of at most 25 tokens, and the limiter liveness time duration is 15 minutes
-			limiter, expire = rate.NewLimiter(rate.Every(1*time.Second), 50), 15*time.Minute
-			limiterSet.Set(k, limiter, expire)
-		}
-		ok = limiter.(*rate.Limiter).Allow()
-		if !ok {
+		// one query per second per origin and permit bursts of at most 50 tokens, and the limiter liveness time duration is 15 minutes
+		if !getLimiter(k, 1*time.Second, 50, 15*time.Minute).Allow() {
 			abort(c)
 			return
 		}
 		c.Next()
 	}
 }
+
+/**************************************************************************************************
+** getLimiter returns the rate limiter of a key, allowing burst requests and one more every
+** interval, and kept for ttl. The limiter is created atomically: when concurrent first requests
+** race to create it, they all get the one stored first.
+**************************************************************************************************/
+func getLimiter(key string, interval time.Duration, burst int, ttl time.Duration) *rate.Limiter {
+	if limiter, ok := limiterSet.Get(key); ok {
+		return limiter.(*rate.Limiter)
+	}
+	limiter := rate.NewLimiter(rate.Every(interval), burst)
+	if err := limiterSet.Add(key, limiter, ttl); err != nil {
+		if existing, ok := limiterSet.Get(key); ok {
+			return existing.(*rate.Limiter)
+		}
+	}
+	return limiter
+}
+
+/**************************************************************************************************
+** limitPerClientIP rate limits the requests per client IP, the IP being read from the headers of
+** the TRUSTED_PROXIES only: burst requests are allowed, one more every interval, the limiter of
+** an IP being kept for ttl. The requests over the limit are rejected with the message.
+**************************************************************************************************/
+func limitPerClientIP(prefix string, interval time.Duration, burst int, ttl time.Duration, message string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !getLimiter(prefix+`:`+c.ClientIP(), interval, burst, ttl).Allow() {
+			utils.SendError(c, utils.NewError(utils.ERROR_RATE_LIMITED, message))
+			return
+		}
+		c.Next()
+	}
+}
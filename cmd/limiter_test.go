@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+/**************************************************************************************************
+** TestGetLimiter checks that the concurrent first requests of a key all get the same limiter.
+**************************************************************************************************/
+func TestGetLimiter(t *testing.T) {
+	key := `test:concurrent`
+	defer limiterSet.Delete(key)
+
+	var wg sync.WaitGroup
+	limiters := make([]*rate.Limiter, 50)
+	for i := range limiters {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			limiters[i] = getLimiter(key, time.Minute, 1, time.Minute)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, limiter := range limiters {
+		assert.Same(t, limiters[0], limiter, "The concurrent requests should share the limiter of their key")
+	}
+}
+
+/**************************************************************************************************
+** TestLimitPerClientIP checks that the requests of an IP are rejected once its burst is used, the
+** other IPs and the other prefixes having their own limiters.
+**************************************************************************************************/
+func TestLimitPerClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET(`/first`, limitPerClientIP(`test-first`, time.Hour, 2, time.Minute, `too many requests`), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET(`/second`, limitPerClientIP(`test-second`, time.Hour, 2, time.Minute, `too many requests`), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	defer func() {
+		for _, key := range []string{`test-first:10.0.0.1`, `test-first:10.0.0.2`, `test-second:10.0.0.1`} {
+			limiterSet.Delete(key)
+		}
+	}()
+
+	request := func(path string, remoteAddr string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr + `:1234`
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request(`/first`, `10.0.0.1`))
+	assert.Equal(t, http.StatusOK, request(`/first`, `10.0.0.1`))
+	assert.Equal(t, http.StatusTooManyRequests, request(`/first`, `10.0.0.1`), "The burst of the IP should be used")
+	assert.Equal(t, http.StatusOK, request(`/first`, `10.0.0.2`), "Another IP should have its own limiter")
+	assert.Equal(t, http.StatusOK, request(`/second`, `10.0.0.1`), "Another prefix should have its own limiter")
+}
+
+/**************************************************************************************************
+** TestNewRateLimiterOriginNamespace checks that a crafted origin cannot use the tokens of the
+** limiter of a client IP, the origins having their own keys.
+**************************************************************************************************/
+func TestNewRateLimiterOriginNamespace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET(`/origin`, NewRateLimiter(func(c *gin.Context) { c.AbortWithStatus(http.StatusTooManyRequests) }), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET(`/ip`, limitPerClientIP(`test-namespace`, time.Hour, 2, time.Minute, `too many requests`), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	defer func() {
+		for _, key := range []string{`test-namespace:10.0.0.1`, `origin:test-namespace:10.0.0.1`} {
+			limiterSet.Delete(key)
+		}
+	}()
+
+	request := func(path string, remoteAddr string, origin string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr + `:1234`
+		if origin != `` {
+			req.Header.Set(`Origin`, origin)
+		}
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request(`/ip`, `10.0.0.1`, ``))
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, request(`/origin`, `10.0.0.2`, `test-namespace:10.0.0.1`))
+	}
+	assert.Equal(t, http.StatusOK, request(`/ip`, `10.0.0.1`, ``), "The crafted origin should not use the tokens of the IP")
+	assert.Equal(t, http.StatusTooManyRequests, request(`/ip`, `10.0.0.1`, ``))
+}
@@ -3,17 +3,38 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/abis"
+	"github.com/yearn/ydaemon/common/env"
 	"github.com/yearn/ydaemon/common/ethereum"
 	"github.com/yearn/ydaemon/common/logs"
+	jsonSchema "github.com/yearn/ydaemon/common/schema"
+	"github.com/yearn/ydaemon/common/tracing"
+	"github.com/yearn/ydaemon/external/grpcapi"
+	"github.com/yearn/ydaemon/external/schema"
 	"github.com/yearn/ydaemon/internal"
+	"github.com/yearn/ydaemon/internal/exporter"
+	"github.com/yearn/ydaemon/internal/fetcher"
+	"github.com/yearn/ydaemon/internal/notifications"
 	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/apr"
+	"github.com/yearn/ydaemon/processes/competitors"
+	"github.com/yearn/ydaemon/processes/fees"
+	"github.com/yearn/ydaemon/processes/losses"
+	"github.com/yearn/ydaemon/processes/mempool"
+	"github.com/yearn/ydaemon/processes/prices"
+	"github.com/yearn/ydaemon/processes/sharePrice"
 )
 
+/**************************************************************************************************
+** processServer starts the indexing of a chain. The chains are started in parallel and each one is
+** reported as initialized by onChainInitialized, once its first snapshot is complete.
+**************************************************************************************************/
 func processServer(chainID uint64) {
 	setStatusForChainID(chainID, `Loading`)
-	defer setStatusForChainID(chainID, `OK`)
 
 	logs.Info(`Initializing chain ` + strconv.FormatUint(chainID, 10) + ` indexing process`)
 	
@@ -26,19 +47,179 @@ func processServer(chainID uint64) {
 	logs.Info(`Starting main indexer for chain ` + strconv.FormatUint(chainID, 10))
 	internal.InitializeV2(chainID, nil)
 	
-	logs.Info(`Chain ` + strconv.FormatUint(chainID, 10) + ` initialization completed`)
+	logs.Info(`Chain ` + strconv.FormatUint(chainID, 10) + ` jobs scheduled`)
+
+	go mempool.Watch(chainID)
+	go internal.WatchHeads(chainID)
+}
+
+func onChainInitialized(chainID uint64) {
+	setStatusForChainID(chainID, `OK`)
 	TriggerInitializedStatus(chainID)
 }
 
+func onStoreVersionChanged(chainID uint64, version uint64, changedVaults []common.Address) {
+	grpcapi.NotifyVaultsChanged(chainID, version, changedVaults)
+	TriggerCDNPurge(chainID, version, changedVaults)
+}
+
+/**************************************************************************************************
+** initTracing starts the export of the traces when an OTLP endpoint is configured. The pending
+** spans are flushed by Shutdown when the process is asked to stop.
+**************************************************************************************************/
+func initTracing(serviceName string) {
+	shutdown, err := tracing.Initialize(serviceName, GetVersion())
+	if err != nil {
+		logs.Error(`Failed to initialize tracing: ` + err.Error())
+		return
+	}
+	if !tracing.IsEnabled() {
+		return
+	}
+	logs.Info(`Exporting traces to the OTLP endpoint`)
+	flushTraces = shutdown
+}
+
+/**************************************************************************************************
+** runProxy runs the aggregation proxy in front of the shards. The proxy does not index anything.
+**************************************************************************************************/
+func runProxy() {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	logs.Info(`Running yDaemon proxy for ` + strconv.Itoa(len(shards)) + ` shards on port ` + port)
+	if err := NewProxyRouter().Run(`:` + port); err != nil {
+		logs.Error(err)
+	}
+}
+
+/**************************************************************************************************
+** runExport writes the vaults, the strategies and the harvests of the stored data of each chain as
+** subgraph entities, without indexing anything.
+**************************************************************************************************/
+func runExport() {
+	storage.InitializeStorage()
+	for _, chainID := range chains {
+		logs.Info(`Exporting the subgraph entities of chain ` + strconv.FormatUint(chainID, 10) + ` to ` + output)
+		if err := exporter.ExportSubgraphEntities(chainID, output); err != nil {
+			logs.Error(`Failed to export chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+		}
+	}
+	logs.Success(`Subgraph export completed`)
+}
+
+/**************************************************************************************************
+** runCodegen writes the TypeScript and the Python types of the API models, generated from the
+** schemas served under /schema, to ydaemon.ts and ydaemon.py in the output directory.
+**************************************************************************************************/
+func runCodegen() {
+	document, _ := schema.GetDocument(``)
+	files := map[string]string{
+		`ydaemon.ts`: jsonSchema.GenerateTypeScript(document),
+		`ydaemon.py`: jsonSchema.GeneratePython(document),
+	}
+	if err := os.MkdirAll(output, 0755); err != nil {
+		logs.Error(`Failed to create ` + output + `: ` + err.Error())
+		return
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(output, name), []byte(content), 0644); err != nil {
+			logs.Error(`Failed to write ` + name + `: ` + err.Error())
+			return
+		}
+	}
+	logs.Success(`Client types written to ` + output)
+}
+
+/**************************************************************************************************
+** runBindings writes the Go bindings of the ABIs of ABI_DIRECTORY to the output directory, one file
+** per ABI, in the package named after the directory.
+**************************************************************************************************/
+func runBindings() {
+	outputDirectory, err := filepath.Abs(output)
+	if err != nil {
+		logs.Error(`Failed to resolve ` + output + `: ` + err.Error())
+		return
+	}
+	files, err := abis.GenerateBindings(env.ABI_DIRECTORY, filepath.Base(outputDirectory))
+	if err != nil {
+		logs.Error(`Failed to generate the bindings of ` + env.ABI_DIRECTORY + `: ` + err.Error())
+		return
+	}
+	if err := os.MkdirAll(outputDirectory, 0755); err != nil {
+		logs.Error(`Failed to create ` + outputDirectory + `: ` + err.Error())
+		return
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(outputDirectory, name), []byte(content), 0644); err != nil {
+			logs.Error(`Failed to write ` + name + `: ` + err.Error())
+			return
+		}
+	}
+	logs.Success(strconv.Itoa(len(files)) + ` bindings written to ` + outputDirectory)
+}
+
 /**************************************************************************************************
 ** Main entry point for the daemon, handling everything from initialization to running external
 ** processes.
 **************************************************************************************************/
 func main() {
 	initFlags()
+	if process == ProcessProxy {
+		initTracing(`ydaemon-proxy`)
+		go ListenToShutdownSignals()
+		runProxy()
+		return
+	}
+	if process == ProcessExport {
+		runExport()
+		return
+	}
+	if process == ProcessCodegen {
+		runCodegen()
+		return
+	}
+	if process == ProcessStatic {
+		runStaticExport()
+		return
+	}
+	if process == ProcessBindings {
+		runBindings()
+		return
+	}
+	initTracing(`ydaemon`)
 	ethereum.Initialize()
 	storage.InitializeStorage()
+	storage.LoadPartnerViews()
+	if count, err := abis.LoadDirectory(env.ABI_DIRECTORY); err != nil {
+		logs.Error(`Failed to load the ABIs of ` + env.ABI_DIRECTORY + `: ` + err.Error())
+	} else if count > 0 {
+		logs.Info(`Loaded ` + strconv.Itoa(count) + ` ABIs from ` + env.ABI_DIRECTORY)
+	}
 	go ListenToSignals()
+	go ListenToShutdownSignals()
+	go ListenToReloadSignals()
+	notifications.Send = TriggerTgMessage
+	notifications.StartDigest()
+	competitors.StartRefresh()
+	fetcher.OnStateDrift = TriggerStateDriftAlert
+	fetcher.OnNewStrategy = TriggerNewStrategyAlert
+	fees.OnFeeChange = TriggerFeeChangeAlert
+	losses.OnLoss = TriggerStrategyLossAlert
+	apr.OnAPYDrift = TriggerAPYDriftAlert
+	apr.OnAPYDivergence = TriggerAPYDivergenceAlert
+	sharePrice.OnSharePriceAnomaly = TriggerSharePriceAnomalyAlert
+	prices.OnVaultPriceLost = TriggerVaultPriceLostAlert
+	prices.OnSugarFetched = apr.RecordVeloSugarFetch
+	internal.OnChainInitialized = onChainInitialized
+	internal.OnChainLagging = TriggerChainLaggingAlert
+	internal.OnChainCaughtUp = TriggerChainCaughtUpAlert
+	internal.OnSequencerDown = TriggerSequencerDownAlert
+	internal.OnSequencerUp = TriggerSequencerUpAlert
+	internal.OnChainDegraded = TriggerChainDegradedAlert
+	internal.OnChainRecovered = TriggerChainRecoveredAlert
+	internal.OnStoreVersionChanged = onStoreVersionChanged
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -47,6 +228,13 @@ func main() {
 
 	logs.Info(`Running yDaemon server process...`)
 	go NewRouter().Run(`:` + port)
+	if env.GRPC_PORT != `` {
+		go func() {
+			if err := grpcapi.Serve(env.GRPC_PORT); err != nil {
+				logs.Error(`Failed to serve the gRPC API: ` + err.Error())
+			}
+		}()
+	}
 	go TriggerTgMessage(`💛 - yDaemon v` + GetVersion() + ` is ready to accept requests: https://ydaemon.yearn.fi/`)
 
 	logs.Info(`Starting indexing processes for ` + strconv.Itoa(len(chains)) + ` chains: ` + fmt.Sprintf("%v", chains))
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/external/utils"
+	"github.com/yearn/ydaemon/internal"
+)
+
+/**************************************************************************************************
+** The on-demand indexing is reserved to the operators holding ON_DEMAND_INDEX_API_KEY, and closed
+** when it is not set. It hydrates a vault with a few multicalls, so it is also rate limited per
+** client IP to ON_DEMAND_INDEX_BURST requests, refilled every ON_DEMAND_INDEX_INTERVAL.
+**************************************************************************************************/
+const ON_DEMAND_INDEX_BURST = 3
+const ON_DEMAND_INDEX_INTERVAL = time.Minute
+
+/**************************************************************************************************
+** restrictOnDemandIndex requires the `Authorization: Bearer <ON_DEMAND_INDEX_API_KEY>` header, the
+** endpoint being closed when the key is not set, and rate limits the requests per client IP.
+**************************************************************************************************/
+func restrictOnDemandIndex() gin.HandlerFunc {
+	limit := limitPerClientIP(`index`, ON_DEMAND_INDEX_INTERVAL, ON_DEMAND_INDEX_BURST, 15*time.Minute, `too many indexing requests, retry in a minute`)
+	return func(c *gin.Context) {
+		if env.ON_DEMAND_INDEX_API_KEY == `` {
+			utils.SendError(c, utils.NewError(utils.ERROR_NOT_FOUND, `the on-demand indexing is disabled`))
+			return
+		}
+		token := strings.TrimPrefix(c.GetHeader(`Authorization`), `Bearer `)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(env.ON_DEMAND_INDEX_API_KEY)) != 1 {
+			utils.SendError(c, utils.NewError(utils.ERROR_UNAUTHORIZED, `a valid bearer token is required`))
+			return
+		}
+
+		limit(c)
+	}
+}
+
+/**************************************************************************************************
+** indexVaultOnDemand indexes a vault not tracked yet. It returns 201 with the vault when it was
+** indexed, and 200 when it was already tracked.
+**************************************************************************************************/
+func indexVaultOnDemand(c *gin.Context) {
+	chainID, ok := helpers.AssertChainID(c.Param("chainID"))
+	if !ok {
+		utils.SendChainIDError(c, c.Param("chainID"))
+		return
+	}
+	var body struct {
+		Address string `json:"address"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Address == `` {
+		utils.SendError(c, utils.NewError(utils.ERROR_MISSING_PARAM, `the body must be { "address": "0x..." }`).WithChainID(chainID))
+		return
+	}
+	vaultAddress, ok := helpers.AssertAddress(body.Address, chainID)
+	if !ok {
+		utils.SendError(c, utils.NewError(utils.ERROR_INVALID_ADDRESS, `invalid address`).WithChainID(chainID))
+		return
+	}
+
+	vault, isNew, err := internal.IndexVaultOnDemand(chainID, vaultAddress)
+	switch {
+	case errors.Is(err, internal.ErrNotAYearnVault), errors.Is(err, internal.ErrNotDeployedVault):
+		utils.SendError(c, utils.NewError(utils.ERROR_INVALID_PARAM, err.Error()).WithChainID(chainID).WithAddress(vaultAddress.Hex()))
+		return
+	case err != nil:
+		utils.SendError(c, utils.NewError(utils.ERROR_PROCESSING_FAILED, err.Error()).WithChainID(chainID).WithAddress(vaultAddress.Hex()))
+		return
+	}
+
+	status := http.StatusOK
+	if isNew {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{
+		"chainID":        chainID,
+		"address":        vault.Address,
+		"version":        vault.Version,
+		"assetAddress":   vault.AssetAddress,
+		"alreadyTracked": !isNew,
+	})
+}
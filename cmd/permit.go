@@ -0,0 +1,22 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**************************************************************************************************
+** The permit data reads the code and the nonces of the tokens on chain for any owner, so it is
+** rate limited per client IP to PERMIT_DATA_BURST requests, one more being allowed every
+** PERMIT_DATA_INTERVAL.
+**************************************************************************************************/
+const PERMIT_DATA_BURST = 20
+const PERMIT_DATA_INTERVAL = 3 * time.Second
+
+/**************************************************************************************************
+** limitPermitData rate limits the permit data requests per client IP.
+**************************************************************************************************/
+func limitPermitData() gin.HandlerFunc {
+	return limitPerClientIP(`permit`, PERMIT_DATA_INTERVAL, PERMIT_DATA_BURST, time.Hour, `too many permit data requests, retry later`)
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-contrib/gzip"
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/common/tracing"
+	"github.com/yearn/ydaemon/external/utils"
+)
+
+/**************************************************************************************************
+** The aggregation proxy sits in front of several yDaemon instances, each one indexing a shard of
+** the chains (--chains). It exposes the same public API:
+** - the requests for one chain (chain ID in one of the first three segments of the path, like
+**   `/1/vaults/all`, `/vaults/1/0x...` or `/internal/backfill/1/pause`, or in the `chainID` query
+**   parameter, like `/strategies/leaderboard?chainID=1`) are forwarded to the shard owning the
+**   chain, whatever their method.
+** - the other requests are sent to every shard, with their method and body, and with the
+**   `chainIDs` query parameter restricted to the chains of the shard when provided. The JSON
+**   responses are merged: the arrays are concatenated and the objects keyed by chain are merged
+**   key by key, see mergeShardValues.
+** The merged lists are not sorted nor paginated again: the clients needing a global order should
+** query the shards per chain.
+**************************************************************************************************/
+var proxyClient = &http.Client{Timeout: 30 * time.Second}
+
+/**************************************************************************************************
+** getShardForChain returns the shard owning a chain, if any.
+**************************************************************************************************/
+func getShardForChain(chainID uint64) (TShard, bool) {
+	for _, shard := range shards {
+		if helpers.Contains(shard.ChainIDs, chainID) {
+			return shard, true
+		}
+	}
+	return TShard{}, false
+}
+
+/**************************************************************************************************
+** getRequestChainID extracts the chain ID of a request from the first three segments of the path,
+** matching the `/:chainID/...`, `/.../:chainID/...` and `/internal/.../:chainID/...` routes of
+** the API, or else from the `chainID` query parameter of the routes scoped to a single chain, like
+** `/users/:address/positions?chainID=1`. Their responses are not keyed by chain and can't be
+** merged across the shards.
+**************************************************************************************************/
+func getRequestChainID(path string, query url.Values) (uint64, bool) {
+	segments := strings.Split(strings.Trim(path, `/`), `/`)
+	for i := 0; i < len(segments) && i < 3; i++ {
+		if chainID, err := strconv.ParseUint(segments[i], 10, 64); err == nil {
+			return chainID, true
+		}
+	}
+	if chainID, err := strconv.ParseUint(strings.TrimSpace(query.Get(`chainID`)), 10, 64); err == nil {
+		return chainID, true
+	}
+	return 0, false
+}
+
+/**************************************************************************************************
+** getShardQuery returns the query to send to a shard. If the request selects some chains, only
+** the chains owned by the shard are kept, and false is returned if none of them are.
+**************************************************************************************************/
+func getShardQuery(query url.Values, shard TShard) (url.Values, bool) {
+	rawChainIDs := query.Get(`chainIDs`)
+	if rawChainIDs == `` {
+		return query, true
+	}
+	shardChainIDs := []string{}
+	for _, chainIDStr := range strings.Split(rawChainIDs, `,`) {
+		chainID, err := strconv.ParseUint(strings.TrimSpace(chainIDStr), 10, 64)
+		if err == nil && helpers.Contains(shard.ChainIDs, chainID) {
+			shardChainIDs = append(shardChainIDs, strconv.FormatUint(chainID, 10))
+		}
+	}
+	if len(shardChainIDs) == 0 {
+		return nil, false
+	}
+	shardQuery := url.Values{}
+	for key, values := range query {
+		shardQuery[key] = values
+	}
+	shardQuery.Set(`chainIDs`, strings.Join(shardChainIDs, `,`))
+	return shardQuery, true
+}
+
+/**************************************************************************************************
+** SHARD_KEYED_ENDPOINTS are the routes answering an object keyed by chain ID (or by token address
+** within a chain), which can be merged key by key across the shards as each shard owns distinct
+** chains. The routes are matched segment by segment, `:name` matching any segment. The objects
+** returned by the other routes can't be merged.
+**************************************************************************************************/
+var SHARD_KEYED_ENDPOINTS = []string{
+	`/info/chains`,
+	`/tokens/all`,
+	`/prices/all`,
+	`/prices/some`,
+	`/internal/unpriced`,
+	`/vaults/tvl`,
+}
+
+/**************************************************************************************************
+** matchShardRoute checks if the path of a request matches a route of SHARD_KEYED_ENDPOINTS.
+**************************************************************************************************/
+func matchShardRoute(path string, route string) bool {
+	pathSegments := strings.Split(strings.Trim(path, `/`), `/`)
+	routeSegments := strings.Split(strings.Trim(route, `/`), `/`)
+	if len(pathSegments) != len(routeSegments) {
+		return false
+	}
+	for i, segment := range routeSegments {
+		if !strings.HasPrefix(segment, `:`) && segment != pathSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isShardKeyedEndpoint(path string) bool {
+	for _, route := range SHARD_KEYED_ENDPOINTS {
+		if matchShardRoute(path, route) {
+			return true
+		}
+	}
+	return false
+}
+
+/**************************************************************************************************
+** mergeShardValues merges two JSON values returned by the shards for the same key:
+** - the arrays are concatenated, as they are the lists of the chains of each shard
+** - the objects are merged key by key, only if keyed is set (see SHARD_KEYED_ENDPOINTS), the keys
+**   present in both being merged recursively when they are arrays or objects
+** - any other value present in both is kept from the first shard: the scalars are never added,
+**   as a timestamp, a version or a ratio is not a sum
+** The boolean is false if the values can't be merged.
+**************************************************************************************************/
+func mergeShardValues(left json.RawMessage, right json.RawMessage, keyed bool) (json.RawMessage, bool) {
+	leftArray, rightArray := []json.RawMessage{}, []json.RawMessage{}
+	if json.Unmarshal(left, &leftArray) == nil && json.Unmarshal(right, &rightArray) == nil && leftArray != nil && rightArray != nil {
+		merged, _ := json.Marshal(append(leftArray, rightArray...))
+		return merged, true
+	}
+
+	leftObject, rightObject := map[string]json.RawMessage{}, map[string]json.RawMessage{}
+	if json.Unmarshal(left, &leftObject) == nil && json.Unmarshal(right, &rightObject) == nil && leftObject != nil && rightObject != nil {
+		if !keyed {
+			return nil, false
+		}
+		for key, value := range rightObject {
+			existing, ok := leftObject[key]
+			if !ok {
+				leftObject[key] = value
+				continue
+			}
+			if merged, ok := mergeShardValues(existing, value, true); ok {
+				leftObject[key] = merged
+			}
+		}
+		merged, _ := json.Marshal(leftObject)
+		return merged, true
+	}
+	return nil, false
+}
+
+/**************************************************************************************************
+** sumShardTVL computes again the total of the merged `/vaults/tvl` response from the TVL of its
+** chains, the total of each shard only covering its own chains.
+**************************************************************************************************/
+func sumShardTVL(merged json.RawMessage) json.RawMessage {
+	response := struct {
+		Total  float64            `json:"total"`
+		Chains map[string]float64 `json:"chains"`
+	}{}
+	if err := json.Unmarshal(merged, &response); err != nil {
+		return merged
+	}
+	response.Total = 0
+	for _, tvl := range response.Chains {
+		response.Total += tvl
+	}
+	content, _ := json.Marshal(response)
+	return content
+}
+
+/**************************************************************************************************
+** mergeShardResponses merges the JSON responses of the shards to a request with mergeShardValues,
+** in the order of the shards. The boolean is false if the responses can't be merged.
+**************************************************************************************************/
+func mergeShardResponses(path string, responses []json.RawMessage) (json.RawMessage, bool) {
+	if len(responses) == 0 {
+		return json.RawMessage(`[]`), true
+	}
+	keyed := isShardKeyedEndpoint(path)
+	merged := responses[0]
+	for _, response := range responses[1:] {
+		var ok bool
+		if merged, ok = mergeShardValues(merged, response, keyed); !ok {
+			return nil, false
+		}
+	}
+	if matchShardRoute(path, `/vaults/tvl`) {
+		merged = sumShardTVL(merged)
+	}
+	return merged, true
+}
+
+/**************************************************************************************************
+** forwardToShard forwards a single chain request to the shard owning the chain.
+**************************************************************************************************/
+func forwardToShard(c *gin.Context, shard TShard) {
+	proxy := httputil.NewSingleHostReverseProxy(shard.URL)
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		logs.Error(`Shard ` + shard.URL.String() + ` unreachable: ` + err.Error())
+		utils.SendError(c, utils.NewError(utils.ERROR_EXTERNAL_API_FAILED, `shard unreachable`))
+	}
+	c.Request.Header.Del(`Accept-Encoding`) // The proxy compresses the response itself
+	tracing.InjectHeaders(c.Request.Context(), c.Request.Header)
+	proxy.ServeHTTP(c.Writer, c.Request)
+}
+
+/**************************************************************************************************
+** fanOutToShards sends the request to every shard concerned, with its method and body, and merges
+** their responses. When some shards fail to answer, the responses of the others are still sent,
+** as partial content, the chains of the missing shards being named in the X-Missing-Chains
+** header. The request fails if none of the shards answered.
+**************************************************************************************************/
+func fanOutToShards(c *gin.Context) {
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		if body, err = io.ReadAll(c.Request.Body); err != nil {
+			utils.SendError(c, utils.NewError(utils.ERROR_INVALID_FORMAT, `invalid request body`))
+			return
+		}
+	}
+
+	var wg sync.WaitGroup
+	responses := make([]tShardResponse, len(shards))
+	for i, shard := range shards {
+		shardQuery, ok := getShardQuery(c.Request.URL.Query(), shard)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, shard TShard, shardQuery url.Values) {
+			defer wg.Done()
+			responses[i] = queryShard(c, shard, shardQuery, body)
+		}(i, shard, shardQuery)
+	}
+	wg.Wait()
+
+	validResponses := []json.RawMessage{}
+	missingChains := []string{}
+	var rejected *tShardResponse
+	for i, response := range responses {
+		if !response.queried {
+			continue
+		}
+		if response.status == http.StatusOK && json.Valid(response.body) {
+			validResponses = append(validResponses, response.body)
+			continue
+		}
+		if response.status >= 400 && response.status < 500 && rejected == nil {
+			rejected = &responses[i]
+		}
+		for _, chainID := range shards[i].ChainIDs {
+			missingChains = append(missingChains, strconv.FormatUint(chainID, 10))
+		}
+	}
+	if len(validResponses) == 0 {
+		if rejected != nil {
+			c.Data(rejected.status, rejected.contentType, rejected.body)
+			return
+		}
+		utils.SendError(c, utils.NewError(utils.ERROR_EXTERNAL_API_FAILED, `no shard available`))
+		return
+	}
+
+	merged, ok := mergeShardResponses(c.Request.URL.Path, validResponses)
+	if !ok {
+		utils.SendError(c, utils.NewError(utils.ERROR_PROCESSING_FAILED, `the responses of the shards can't be merged for this route, select the chains of a single shard with chainIDs`))
+		return
+	}
+	status := http.StatusOK
+	if len(missingChains) > 0 {
+		status = http.StatusPartialContent
+		c.Header(`X-Missing-Chains`, strings.Join(missingChains, `,`))
+	}
+	c.Data(status, `application/json; charset=utf-8`, merged)
+}
+
+/**************************************************************************************************
+** tShardResponse is the answer of a shard to a fanned out request, status being 0 if the shard
+** was unreachable.
+**************************************************************************************************/
+type tShardResponse struct {
+	queried     bool
+	status      int
+	contentType string
+	body        []byte
+}
+
+/**************************************************************************************************
+** queryShard sends the request to a shard with its query restricted to the chains of the shard.
+**************************************************************************************************/
+func queryShard(c *gin.Context, shard TShard, shardQuery url.Values, body []byte) tShardResponse {
+	response := tShardResponse{queried: true}
+	shardURL := shard.URL.JoinPath(c.Request.URL.Path)
+	shardURL.RawQuery = shardQuery.Encode()
+	req, err := http.NewRequestWithContext(c.Request.Context(), c.Request.Method, shardURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return response
+	}
+	for _, header := range []string{`Content-Type`, `Authorization`} {
+		if value := c.Request.Header.Get(header); value != `` {
+			req.Header.Set(header, value)
+		}
+	}
+	tracing.InjectHeaders(c.Request.Context(), req.Header)
+	resp, err := proxyClient.Do(req)
+	if err != nil {
+		logs.Error(`Shard ` + shard.URL.String() + ` unreachable: ` + err.Error())
+		return response
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logs.Error(`Shard ` + shard.URL.String() + ` response unreadable: ` + err.Error())
+		return response
+	}
+	if resp.StatusCode != http.StatusOK {
+		logs.Warning(`Shard ` + shard.URL.String() + ` answered ` + strconv.Itoa(resp.StatusCode) + ` to ` + c.Request.URL.Path)
+	}
+	response.status = resp.StatusCode
+	response.contentType = resp.Header.Get(`Content-Type`)
+	response.body = respBody
+	return response
+}
+
+/**************************************************************************************************
+** NewProxyRouter creates the router of the aggregation proxy.
+**************************************************************************************************/
+func NewProxyRouter() *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(tracing.Middleware())
+	router.Use(cors.New(cors.Config{
+		AllowAllOrigins: true,
+		AllowMethods:    []string{"GET", "POST", "HEAD", "OPTIONS"},
+		AllowHeaders:    []string{`Origin`, `Content-Length`, `Content-Type`, `Authorization`, `traceparent`, `tracestate`},
+		ExposeHeaders:   []string{`X-Missing-Chains`},
+	}))
+	router.Use(gzip.Gzip(gzip.DefaultCompression))
+
+	router.GET(`/health`, func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"status": "ok", "shards": len(shards), "timestamp": time.Now().Format(time.RFC3339)})
+	})
+	router.NoRoute(func(c *gin.Context) {
+		if chainID, ok := getRequestChainID(c.Request.URL.Path, c.Request.URL.Query()); ok {
+			shard, ok := getShardForChain(chainID)
+			if !ok {
+				utils.SendError(c, utils.NewError(utils.ERROR_CHAIN_NOT_SUPPORTED, `chain not served by any shard`).WithChainID(chainID))
+				return
+			}
+			forwardToShard(c, shard)
+			return
+		}
+		fanOutToShards(c)
+	})
+	return router
+}
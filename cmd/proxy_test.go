@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/**************************************************************************************************
+** TestMergeShardValues checks that the lists are concatenated, that the objects are only merged
+** key by key for the keyed routes, and that the scalars are never added.
+**************************************************************************************************/
+func TestMergeShardValues(t *testing.T) {
+	testCases := []struct {
+		name     string
+		left     string
+		right    string
+		keyed    bool
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "Arrays are concatenated",
+			left:     `[{"chainID":1}]`,
+			right:    `[{"chainID":10}]`,
+			expected: `[{"chainID":1},{"chainID":10}]`,
+			ok:       true,
+		},
+		{
+			name:     "Keyed objects are merged by chain",
+			left:     `{"1":{"0xa":1.5}}`,
+			right:    `{"10":{"0xb":2}}`,
+			keyed:    true,
+			expected: `{"1":{"0xa":1.5},"10":{"0xb":2}}`,
+			ok:       true,
+		},
+		{
+			name:     "Keyed objects are merged recursively",
+			left:     `{"chains":{"1":{"id":1}}}`,
+			right:    `{"chains":{"10":{"id":10}}}`,
+			keyed:    true,
+			expected: `{"chains":{"1":{"id":1},"10":{"id":10}}}`,
+			ok:       true,
+		},
+		{
+			name:     "Scalars are kept from the first shard",
+			left:     `{"timestamp":1700000000,"version":3,"chains":{"1":100}}`,
+			right:    `{"timestamp":1700000100,"version":3,"chains":{"10":50}}`,
+			keyed:    true,
+			expected: `{"chains":{"1":100,"10":50},"timestamp":1700000000,"version":3}`,
+			ok:       true,
+		},
+		{
+			name:  "Objects of other routes are not merged",
+			left:  `{"numberOfVaults":3}`,
+			right: `{"numberOfVaults":4}`,
+			ok:    false,
+		},
+		{
+			name:  "Scalars are not merged",
+			left:  `3`,
+			right: `4`,
+			keyed: true,
+			ok:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			merged, ok := mergeShardValues(json.RawMessage(tc.left), json.RawMessage(tc.right), tc.keyed)
+			assert.Equal(t, tc.ok, ok)
+			if tc.ok {
+				assert.JSONEq(t, tc.expected, string(merged))
+			}
+		})
+	}
+}
+
+/**************************************************************************************************
+** TestMergeShardResponses checks the routes matched as keyed and the total of the TVL computed
+** again from the merged chains.
+**************************************************************************************************/
+func TestMergeShardResponses(t *testing.T) {
+	merged, ok := mergeShardResponses(`/vaults/tvl`, []json.RawMessage{
+		json.RawMessage(`{"total":100,"chains":{"1":100}}`),
+		json.RawMessage(`{"total":50.5,"chains":{"10":50.5}}`),
+	})
+	assert.True(t, ok)
+	assert.JSONEq(t, `{"total":150.5,"chains":{"1":100,"10":50.5}}`, string(merged))
+
+	_, ok = mergeShardResponses(`/users/0xabc/positions`, []json.RawMessage{
+		json.RawMessage(`{"address":"0xabc","positions":[]}`),
+		json.RawMessage(`{"address":"0xabc","positions":[]}`),
+	})
+	assert.False(t, ok, "The objects of the other routes should not be merged")
+
+	merged, ok = mergeShardResponses(`/vaults/all`, []json.RawMessage{json.RawMessage(`{"address":"0xabc"}`)})
+	assert.True(t, ok, "A single response should be sent as is")
+	assert.JSONEq(t, `{"address":"0xabc"}`, string(merged))
+
+	assert.True(t, isShardKeyedEndpoint(`/prices/all`))
+	assert.False(t, isShardKeyedEndpoint(`/prices/all/extra`))
+}
+
+/**************************************************************************************************
+** TestFanOutToShards checks that the responses of the shards are merged, and that the chains of
+** the shards failing to answer are reported with a partial content.
+**************************************************************************************************/
+func TestFanOutToShards(t *testing.T) {
+	newShard := func(status int, body string, chainIDs ...uint64) (TShard, *httptest.Server) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(`Content-Type`, `application/json`)
+			w.WriteHeader(status)
+			io.WriteString(w, body)
+		}))
+		shardURL, _ := url.Parse(server.URL)
+		return TShard{ChainIDs: chainIDs, URL: shardURL}, server
+	}
+	originalShards := shards
+	defer func() { shards = originalShards }()
+
+	testCases := []struct {
+		name            string
+		statuses        []int
+		expectedStatus  int
+		expectedBody    string
+		expectedMissing string
+	}{
+		{
+			name:           "All the shards answer",
+			statuses:       []int{http.StatusOK, http.StatusOK},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"chainID":1},{"chainID":10}]`,
+		},
+		{
+			name:            "A shard fails",
+			statuses:        []int{http.StatusOK, http.StatusInternalServerError},
+			expectedStatus:  http.StatusPartialContent,
+			expectedBody:    `[{"chainID":1}]`,
+			expectedMissing: `10,137`,
+		},
+		{
+			name:           "All the shards reject the request",
+			statuses:       []int{http.StatusBadRequest, http.StatusBadRequest},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "No shard answers",
+			statuses:       []int{http.StatusServiceUnavailable, http.StatusInternalServerError},
+			expectedStatus: http.StatusBadGateway,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			first, firstServer := newShard(tc.statuses[0], `[{"chainID":1}]`, 1)
+			defer firstServer.Close()
+			second, secondServer := newShard(tc.statuses[1], `[{"chainID":10}]`, 10, 137)
+			defer secondServer.Close()
+			shards = []TShard{first, second}
+
+			w := httptest.NewRecorder()
+			NewProxyRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, `/vaults/all`, nil))
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedBody != `` {
+				assert.JSONEq(t, tc.expectedBody, w.Body.String())
+			}
+			assert.Equal(t, tc.expectedMissing, w.Header().Get(`X-Missing-Chains`))
+		})
+	}
+}
+
+/**************************************************************************************************
+** TestGetShardQuery checks that the chains selected by a request are restricted to the chains of
+** each shard, and that the shards owning none of them are not queried.
+**************************************************************************************************/
+func TestGetShardQuery(t *testing.T) {
+	shard := TShard{ChainIDs: []uint64{1, 10}}
+
+	query, ok := getShardQuery(url.Values{`chainIDs`: {`1,137`}}, shard)
+	assert.True(t, ok)
+	assert.Equal(t, `1`, query.Get(`chainIDs`))
+
+	_, ok = getShardQuery(url.Values{`chainIDs`: {`137`}}, shard)
+	assert.False(t, ok)
+
+	query, ok = getShardQuery(url.Values{}, shard)
+	assert.True(t, ok)
+	assert.Empty(t, query.Get(`chainIDs`))
+}
+
+/**************************************************************************************************
+** TestGetRequestChainID checks that the chain of a request is read from the path first, and else
+** from the singular `chainID` query parameter.
+**************************************************************************************************/
+func TestGetRequestChainID(t *testing.T) {
+	testCases := []struct {
+		name       string
+		path       string
+		query      url.Values
+		expectedOK bool
+		expected   uint64
+	}{
+		{name: "Chain first", path: `/1/vaults/all`, expectedOK: true, expected: 1},
+		{name: "Chain second", path: `/vaults/10/0xabc`, expectedOK: true, expected: 10},
+		{name: "Chain third", path: `/internal/backfill/137/pause`, expectedOK: true, expected: 137},
+		{name: "Path before query", path: `/vaults/10/0xabc`, query: url.Values{`chainID`: {`137`}}, expectedOK: true, expected: 10},
+		{name: "Query", path: `/strategies/leaderboard`, query: url.Values{`chainID`: {`137`}}, expectedOK: true, expected: 137},
+		{name: "Invalid query", path: `/strategies/leaderboard`, query: url.Values{`chainID`: {`polygon`}}},
+		{name: "Several chains", path: `/vaults/all`, query: url.Values{`chainIDs`: {`1,137`}}},
+		{name: "No chain", path: `/vaults/all`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			chainID, ok := getRequestChainID(tc.path, tc.query)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expected, chainID)
+		})
+	}
+}
+
+/**************************************************************************************************
+** TestProxyForwardsChainIDQuery checks that the routes scoped to a chain by the `chainID` query
+** parameter, answering objects that can't be merged, are forwarded to the shard owning the chain
+** only.
+**************************************************************************************************/
+func TestProxyForwardsChainIDQuery(t *testing.T) {
+	newShard := func(name string, chainIDs ...uint64) (TShard, *httptest.Server) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(`Content-Type`, `application/json`)
+			io.WriteString(w, `{"shard":"`+name+`","path":"`+r.URL.Path+`","chainID":"`+r.URL.Query().Get(`chainID`)+`"}`)
+		}))
+		shardURL, _ := url.Parse(server.URL)
+		return TShard{ChainIDs: chainIDs, URL: shardURL}, server
+	}
+	originalShards := shards
+	defer func() { shards = originalShards }()
+	first, firstServer := newShard(`first`, 1)
+	defer firstServer.Close()
+	second, secondServer := newShard(`second`, 10, 137)
+	defer secondServer.Close()
+	shards = []TShard{first, second}
+
+	testCases := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedShard  string
+	}{
+		{name: "Leaderboard", path: `/strategies/leaderboard?chainID=137`, expectedStatus: http.StatusOK, expectedShard: `second`},
+		{name: "User history", path: `/users/0xabc/history?chainID=1`, expectedStatus: http.StatusOK, expectedShard: `first`},
+		{name: "User positions", path: `/users/0xabc/positions?chainID=10`, expectedStatus: http.StatusOK, expectedShard: `second`},
+		{name: "User allowances", path: `/users/0xabc/allowances?chainID=1`, expectedStatus: http.StatusOK, expectedShard: `first`},
+		{name: "Internal APR sources", path: `/internal/apr-sources?chainID=137`, expectedStatus: http.StatusOK, expectedShard: `second`},
+		{name: "Internal adjustments", path: `/internal/adjustments?chainID=1&address=0xabc`, expectedStatus: http.StatusOK, expectedShard: `first`},
+		{name: "Chain of no shard", path: `/strategies/leaderboard?chainID=250`, expectedStatus: http.StatusBadRequest},
+		{name: "Objects of every shard", path: `/strategies/leaderboard`, expectedStatus: http.StatusInternalServerError},
+	}
+
+	proxy := httptest.NewServer(NewProxyRouter()) // The reverse proxy needs a real connection
+	defer proxy.Close()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Get(proxy.URL + tc.path)
+			if err != nil {
+				t.Fatalf("Failed to query the proxy: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			if tc.expectedShard == `` {
+				return
+			}
+			requestURL, _ := url.Parse(tc.path)
+			response := map[string]string{}
+			assert.NoError(t, json.Unmarshal(body, &response))
+			assert.Equal(t, tc.expectedShard, response[`shard`])
+			assert.Equal(t, requestURL.Path, response[`path`])
+			assert.Equal(t, requestURL.Query().Get(`chainID`), response[`chainID`])
+		})
+	}
+}
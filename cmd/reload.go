@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+var reloadMtx sync.Mutex
+
+/**************************************************************************************************
+** ListenToReloadSignals reloads the configuration of the daemon on every SIGHUP.
+**************************************************************************************************/
+func ListenToReloadSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		ReloadConfig(`received SIGHUP`)
+	}
+}
+
+/**************************************************************************************************
+** ReloadConfig applies the .env file and the operator files of data/meta again without restarting
+** the daemon: the in-memory state (vaults, prices, APYs, caches) is kept as it is and the new
+** settings are used from the next refresh or request. The RPC, multicall and websocket clients of
+** the chains whose URI changed are dialed again. The names of the changed variables are logged
+** and notified on Telegram.
+**************************************************************************************************/
+func ReloadConfig(reason string) {
+	reloadMtx.Lock()
+	defer reloadMtx.Unlock()
+
+	previousURIs := map[uint64][3]string{}
+	for _, chainID := range chains {
+		previousURIs[chainID] = listClientsURIs(chainID)
+	}
+
+	changed := env.Reload()
+	for _, chainID := range chains {
+		storage.LoadLocales(chainID)
+
+		currentURIs := listClientsURIs(chainID)
+		if currentURIs[0] != previousURIs[chainID][0] || currentURIs[1] != previousURIs[chainID][1] {
+			if err := ethereum.RedialClients(chainID); err != nil {
+				logs.Error(`Failed to dial the RPC URI again for chain`, chainID, err)
+			}
+		}
+		if currentURIs[2] != previousURIs[chainID][2] {
+			ethereum.ResetWSClient(chainID)
+		}
+	}
+	storage.LoadPartnerViews()
+
+	message := `🔄 - yDaemon configuration reloaded (` + reason + `)`
+	if len(changed) == 0 {
+		message += `: no variable changed`
+	} else {
+		message += `: ` + strings.Join(changed, `, `)
+	}
+	logs.Info(message)
+	TriggerTgMessage(message)
+}
+
+/**************************************************************************************************
+** listClientsURIs returns the URIs of the RPC, multicall and websocket clients of a chain, to find
+** the clients to dial again after a reload.
+**************************************************************************************************/
+func listClientsURIs(chainID uint64) [3]string {
+	return [3]string{
+		ethereum.GetRPCURI(chainID),
+		ethereum.GetMulticallURI(chainID),
+		ethereum.GetWSEnvURI(chainID),
+	}
+}
@@ -8,12 +8,20 @@ import (
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
 	"github.com/patrickmn/go-cache"
+	"github.com/yearn/ydaemon/common/env"
 	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/common/tracing"
 	"github.com/yearn/ydaemon/external/prices"
+	"github.com/yearn/ydaemon/external/schema"
 	"github.com/yearn/ydaemon/external/strategies"
 	"github.com/yearn/ydaemon/external/tokens"
+	"github.com/yearn/ydaemon/external/treasury"
 	"github.com/yearn/ydaemon/external/utils"
 	"github.com/yearn/ydaemon/external/vaults"
+	"github.com/yearn/ydaemon/internal"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/apr"
 )
 
 var cachingStore *cache.Cache
@@ -32,14 +40,35 @@ func NewRouter() *gin.Engine {
 	// gin.DefaultWriter = nil
 	router := gin.New()
 	// pprof.Register(router)
+
+	/**********************************************************************************************
+	** The routes rate limited per client IP read it from the X-Forwarded-For and X-Real-IP headers
+	** only when the request comes from one of the TRUSTED_PROXIES, the headers being set by
+	** anyone otherwise. Without trusted proxy, the client IP is the address of the connection.
+	**********************************************************************************************/
+	router.ForwardedByClientIP = true
+	router.RemoteIPHeaders = []string{`X-Forwarded-For`, `X-Real-IP`}
+	if err := router.SetTrustedProxies(env.TRUSTED_PROXIES); err != nil {
+		logs.Error(`Invalid TRUSTED_PROXIES, no proxy is trusted: ` + err.Error())
+		router.SetTrustedProxies(nil)
+	}
 	router.Use(gin.Recovery())
+	router.Use(tracing.Middleware())
 	corsConf := cors.Config{
 		AllowAllOrigins: true,
 		AllowMethods:    []string{"GET", "HEAD", "POST", "OPTIONS"},
-		AllowHeaders:    []string{`Origin`, `Content-Length`, `Content-Type`, `Authorization`},
+		AllowHeaders:    []string{`Origin`, `Content-Length`, `Content-Type`, `Authorization`, `traceparent`, `tracestate`},
+		ExposeHeaders: []string{
+			`X-Attestation-Signer`, `X-Attestation-Price`, `X-Attestation-Timestamp`,
+			`X-Attestation-Block`, `X-Attestation-Digest`, `X-Attestation-Signature`,
+			vaults.STORE_VERSION_HEADER,
+		},
 	}
 	router.Use(cors.New(corsConf))
+	router.Use(storeVersionHeader())
+	router.Use(normalizeAddressParams())
 	router.Use(gzip.Gzip(gzip.DefaultCompression))
+	router.Use(applyCasingProfile())
 	// router.Use(NewRateLimiter(func(c *gin.Context) {
 	// 	c.AbortWithStatus(http.StatusTooManyRequests)
 	// }))
@@ -78,6 +107,17 @@ func NewRouter() *gin.Engine {
 		router.GET(`vaults/velodrome`, CacheSimplifiedVaults(cachingStore, 5*time.Minute, c.GetIsVelodrome))
 		router.GET(`vaults/aerodrome`, CacheSimplifiedVaults(cachingStore, 5*time.Minute, c.GetIsAerodrome))
 		router.GET(`vaults/curve`, CacheSimplifiedVaults(cachingStore, 5*time.Minute, c.GetIsCurve))
+		router.GET(`partners/:partner/vaults`, CacheSimplifiedVaults(cachingStore, 5*time.Minute, c.GetPartnerVaults))
+		router.GET(`vaults/:chainID/diff`, c.GetVaultsDiff)
+		router.GET(`vaults/:chainID/migrations`, c.GetVaultsMigrations)
+		router.GET(`fees/:chainID`, c.GetFeesSummary)
+		router.GET(`vaults/:chainID/:address/pending`, c.GetVaultPendingFlows)
+		router.GET(`vaults/:chainID/:address/withdrawal`, c.GetVaultWithdrawal)
+		router.GET(`vaults/:chainID/:address/permit-data`, limitPermitData(), c.GetVaultPermitData)
+		router.POST(`vaults/:chainID/batch`, c.GetBatchVaults)
+		router.POST(`vaults/:chainID/index`, restrictOnDemandIndex(), indexVaultOnDemand)
+		router.POST(`suggestions/:chainID/:address`, limitSuggestions(), submitSuggestion)
+		router.GET(`vaults/movers`, c.GetVaultsMovers)
 
 		/******************************************************************************************
 		** Retrieve some/all vaults based on some specific criteria. This is chain specific and
@@ -96,27 +136,50 @@ func NewRouter() *gin.Engine {
 		******************************************************************************************/
 		router.GET(`rotki/list/vaults`, CacheCustomVaults(cachingStore, 5*time.Minute, c.GetVaultsForRotki))
 		router.GET(`rotki/count/vaults`, c.CountVaultsForRotki)
+		router.GET(`integrations/defillama/yields`, c.GetDefiLlamaYields)
+		router.GET(`integrations/defillama/tvl`, c.GetDefiLlamaTVL)
+		router.GET(`tokenlist.json`, c.GetTokenList)
 
 		/******************************************************************************************
 		** Retrieve a specific vault based on the address. This is chain specific and will return
 		** the vault for a specific chain.
 		******************************************************************************************/
-		router.GET(`:chainID/vaults/:address`, c.GetSimplifiedVault)
-		router.GET(`:chainID/vault/:address`, c.GetSimplifiedVault)
+		router.GET(`:chainID/vaults/:address`, limitHistoricalAPY(), c.GetSimplifiedVault)
+		router.GET(`:chainID/vault/:address`, limitHistoricalAPY(), c.GetSimplifiedVault)
+		router.GET(`:chainID/vaults/:address/apy/stats`, c.GetVaultAPYStats)
+		router.GET(`apy/:chainID/:address`, CacheVaultAPYFigure(cachingStore, 5*time.Minute, c.GetVaultAPYFigure))
 
 		router.GET(`:chainID/vaults/harvests/:addresses`, c.GetHarvestsForVault)
 		router.GET(`:chainID/earned/:address/:vaults`, c.GetEarnedPerVaultPerUser)
 		router.GET(`:chainID/earned/:address`, c.GetEarnedPerUser)
 		router.GET(`earned/:address`, c.GetEarnedPerUserForAllChains)
+		router.GET(`users/:address/allowances`, limitAllowances(), c.GetUserAllowances)
+		router.GET(`users/:address/history`, c.GetUserHistory)
+		router.GET(`users/:address/positions`, c.GetUserPositions)
 
 		// Retrieve the strategies for a specific chainID
 		router.GET(`:chainID/strategies/all`, c.GetAllStrategies)
 		router.GET(`:chainID/strategies/:address`, c.GetStrategy)
 		router.GET(`:chainID/strategy/:address`, c.GetStrategy)
+		router.GET(`strategies/leaderboard`, c.GetStrategiesLeaderboard)
 
 		// Retrieve the TVL
 		router.GET(`vaults/tvl`, c.GetAllVaultsTVL)
 		router.GET(`:chainID/vaults/tvl`, c.GetVaultsTVL)
+
+		// Retrieve the TVL and the APY of the vaults of an asset class: stables, eth or btc
+		router.GET(`aggregates/:chainID/:assetClass`, c.GetAssetClassAggregate)
+
+		/******************************************************************************************
+		** Reverse lookups: retrieve the vaults exposed to a specific token or protocol.
+		******************************************************************************************/
+		router.GET(`tokens/:chainID/:address/vaults`, c.GetVaultsForToken)
+		router.GET(`protocols/:name/vaults`, c.GetVaultsForProtocol)
+
+		/******************************************************************************************
+		** Comparison of the yields of a token with the ones of the competing protocols.
+		******************************************************************************************/
+		router.GET(`compare/:chainID/:token`, c.GetTokenComparison)
 	}
 
 	// Strategies section
@@ -135,11 +198,91 @@ func NewRouter() *gin.Engine {
 		router.GET(`:chainID/status`, func(ctx *gin.Context) {
 			chainID, ok := helpers.AssertChainID(ctx.Param("chainID"))
 			if !ok {
-				ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid chainID"})
+				utils.SendChainIDError(ctx, ctx.Param("chainID"))
 				return
 			}
 			ctx.JSON(http.StatusOK, getStatusForChainID(chainID))
 		})
+		router.GET(`:chainID/status/freshness`, func(ctx *gin.Context) {
+			chainID, ok := helpers.AssertChainID(ctx.Param("chainID"))
+			if !ok {
+				utils.SendChainIDError(ctx, ctx.Param("chainID"))
+				return
+			}
+			freshness, isLagging := storage.GetChainFreshness(chainID)
+			response := gin.H{
+				"chainID":   chainID,
+				"freshness": freshness,
+				"isLagging": isLagging,
+				"isPaused":  internal.IsChainPaused(chainID),
+				"processes": storage.ListProcessBlocks(chainID),
+			}
+			if sequencer, ok := storage.GetSequencerStatus(chainID); ok {
+				response["sequencer"] = sequencer
+			}
+			if rpcHealth, ok := storage.GetRPCHealth(chainID); ok {
+				response["rpc"] = rpcHealth
+			}
+			ctx.JSON(http.StatusOK, response)
+		})
+		router.GET(`internal/init-progress`, func(ctx *gin.Context) {
+			ctx.JSON(http.StatusOK, internal.GetInitProgress())
+		})
+		router.GET(`internal/backfill`, getBackfillProgress)
+		router.POST(`internal/backfill/:chainID/pause`, restrictAdmin(), pauseBackfills)
+		router.POST(`internal/backfill/:chainID/resume`, restrictAdmin(), resumeBackfills)
+		router.GET(`internal/apr/what-if/:chainID/:vault`, restrictAdmin(), simulateFeeChange)
+		router.GET(`internal/suggestions/:chainID`, restrictAdmin(), getSuggestions)
+		router.POST(`internal/suggestions/:chainID/:id`, restrictAdmin(), reviewSuggestion)
+		router.GET(`internal/adjustments`, func(ctx *gin.Context) {
+			var adjustments []apr.TAdjustment
+			if chainIDStr := ctx.Query("chainID"); chainIDStr != "" {
+				chainID, ok := helpers.AssertChainID(chainIDStr)
+				if !ok {
+					utils.SendChainIDError(ctx, chainIDStr)
+					return
+				}
+				adjustments = apr.ListAdjustments(chainID)
+			} else {
+				adjustments = apr.ListAllAdjustments()
+			}
+			if address := ctx.Query("address"); address != "" {
+				adjustments = apr.FilterAdjustments(adjustments, address)
+			}
+			ctx.JSON(http.StatusOK, adjustments)
+		})
+		router.GET(`internal/apr-sources`, func(ctx *gin.Context) {
+			if chainIDStr := ctx.Query("chainID"); chainIDStr != "" {
+				chainID, ok := helpers.AssertChainID(chainIDStr)
+				if !ok {
+					utils.SendChainIDError(ctx, chainIDStr)
+					return
+				}
+				ctx.JSON(http.StatusOK, apr.ListAPRSourcesHealth(chainID))
+				return
+			}
+			ctx.JSON(http.StatusOK, apr.ListAllAPRSourcesHealth())
+		})
+		router.GET(`internal/audit/:chainID/:address`, func(ctx *gin.Context) {
+			chainID, ok := helpers.AssertChainID(ctx.Param("chainID"))
+			if !ok {
+				utils.SendChainIDError(ctx, ctx.Param("chainID"))
+				return
+			}
+			address, ok := helpers.AssertAddress(ctx.Param("address"), chainID)
+			if !ok {
+				utils.SendError(ctx, utils.NewError(utils.ERROR_INVALID_ADDRESS, "invalid address"))
+				return
+			}
+			ctx.JSON(http.StatusOK, storage.ListAuditTrail(chainID, address))
+		})
+	}
+
+	// Schema API section
+	{
+		c := schema.Controller{}
+		router.GET(`schema`, c.GetSchema)
+		router.GET(`schema/:name`, c.GetSchemaDefinition)
 	}
 
 	// Tokens API section
@@ -157,6 +300,7 @@ func NewRouter() *gin.Engine {
 		router.GET(`:chainID/prices/:address`, c.GetPrice)
 		router.GET(`:chainID/prices/some/:addresses`, c.GetSomePricesForChain)
 		router.GET(`:chainID/prices/all/details`, c.GetAllPricesWithDetails)
+		router.GET(`internal/unpriced`, c.GetUnpricedTokens)
 
 		/******************************************************************************************
 		** Retrieve some/all prices based on some specific criteria. This is chain agnostic and
@@ -167,5 +311,16 @@ func NewRouter() *gin.Engine {
 
 	}
 
+	// Treasury API section
+	{
+		c := treasury.Controller{}
+		router.GET(`treasury/:chainID/holdings`, c.GetHoldings)
+		router.GET(`treasury/buybacks`, c.GetBuybacks)
+	}
+
+	router.NoRoute(func(ctx *gin.Context) {
+		utils.SendError(ctx, utils.NewError(utils.ERROR_NOT_FOUND, `route `+ctx.Request.URL.Path+` not found`))
+	})
+
 	return router
 }
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal"
+	"github.com/yearn/ydaemon/internal/notifications"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** SHUTDOWN_TIMEOUT is the deadline of the whole shutdown sequence. The running jobs are not waited
+** for past it and the state is flushed as it is.
+**************************************************************************************************/
+const SHUTDOWN_TIMEOUT = 60 * time.Second
+
+var shutdownOnce sync.Once
+var flushTraces func(ctx context.Context) error
+
+/**************************************************************************************************
+** TShutdownNotification is the body posted to the SHUTDOWN_WEBHOOK_URL when the daemon stops.
+**************************************************************************************************/
+type TShutdownNotification struct {
+	Event    string   `json:"event"`
+	Version  string   `json:"version"`
+	Process  string   `json:"process"`
+	Reason   string   `json:"reason"`
+	ExitCode int      `json:"exitCode"`
+	Running  []string `json:"running"`
+}
+
+/**************************************************************************************************
+** ListenToShutdownSignals stops the daemon gracefully on SIGINT or SIGTERM.
+**************************************************************************************************/
+func ListenToShutdownSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	received := <-signals
+	Shutdown(`received `+received.String(), 0)
+}
+
+/**************************************************************************************************
+** Shutdown stops the daemon gracefully before exiting with the given code:
+** - the schedulers are stopped and the in-flight refreshes, with their RPC batches, are given
+**   until the deadline to complete,
+** - the state is flushed to the storage backend for the next start to load it,
+** - the pending digests of the alerts are sent, and the stop is notified on Telegram and on the
+**   webhook,
+** - the pending traces are flushed.
+** Only the first call runs the sequence, the next ones wait for the process to exit.
+**************************************************************************************************/
+func Shutdown(reason string, code int) {
+	shutdownOnce.Do(func() {
+		logs.Warning(`Shutting down yDaemon: ` + reason)
+		ctx, cancel := context.WithTimeout(context.Background(), SHUTDOWN_TIMEOUT)
+		defer cancel()
+
+		running := []string{}
+		if process == ProcessServer {
+			running = internal.StopSchedulers(ctx)
+			if len(running) > 0 {
+				logs.Warning(`Jobs still running at the shutdown deadline: ` + strings.Join(running, `, `))
+			}
+			storage.FlushStorage()
+		}
+
+		notifications.Flush()
+		message := `🔴 - yDaemon v` + GetVersion() + ` is shutting down: ` + reason
+		if len(running) > 0 {
+			message += "\n- interrupted: " + strings.Join(running, `, `)
+		}
+		TriggerTgMessage(message)
+		triggerShutdownWebhook(ctx, TShutdownNotification{
+			Event:    `shutdown`,
+			Version:  GetVersion(),
+			Process:  string(process),
+			Reason:   reason,
+			ExitCode: code,
+			Running:  running,
+		})
+
+		if flushTraces != nil {
+			if err := flushTraces(ctx); err != nil {
+				logs.Error(`Failed to flush the traces: ` + err.Error())
+			}
+		}
+		os.Exit(code)
+	})
+	select {}
+}
+
+/**************************************************************************************************
+** triggerShutdownWebhook posts the shutdown notification to the SHUTDOWN_WEBHOOK_URL, if any.
+**************************************************************************************************/
+func triggerShutdownWebhook(ctx context.Context, notification TShutdownNotification) {
+	if env.SHUTDOWN_WEBHOOK_URL == `` {
+		return
+	}
+	body, err := json.Marshal(notification)
+	if err != nil {
+		logs.Error(`Failed to encode the shutdown notification: ` + err.Error())
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, env.SHUTDOWN_WEBHOOK_URL, bytes.NewReader(body))
+	if err != nil {
+		logs.Error(`Failed to create the shutdown webhook request: ` + err.Error())
+		return
+	}
+	req.Header.Set(`Content-Type`, `application/json`)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		logs.Error(`Failed to call the shutdown webhook: ` + err.Error())
+		return
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The static export renders the public API into a directory of JSON files, to stand up a
+** read-only mirror on a static host (S3, Cloudflare Pages, ...) when the daemon is down. Every GET
+** route of the router is replayed in memory against the stored data, without indexing anything:
+** `:chainID` is replaced by each chain of --chains and `:address` by the addresses listed by
+** STATIC_ADDRESS_SOURCES for the route. The routes with another parameter, the excluded ones and
+** the ones not answering 200 are not exported.
+** A route is written to `<output>/<path>.json`, and `<output>/manifest.json` lists the exported
+** paths with their file.
+**************************************************************************************************/
+var STATIC_EXCLUDED_PREFIXES = []string{`/internal/`, `/users/`, `/earned/`, `/health`}
+
+var STATIC_EXCLUDED_ROUTES = map[string]bool{
+	`/`:                                     true,
+	`/vaults/:chainID/diff`:                 true, // Needs the version to diff from
+	`/vaults/:chainID/:address/pending`:     true, // Read on chain
+	`/vaults/:chainID/:address/withdrawal`:  true, // Read on chain
+	`/vaults/:chainID/:address/permit-data`: true, // Read on chain, for an owner
+	`/:chainID/reports/:address`:            true, // Read from the database
+	`/:chainID/prices/all/details`:          true, // Internal details of the pricing
+}
+
+/**************************************************************************************************
+** STATIC_ADDRESS_SOURCES lists, for the routes with an `:address` parameter, the addresses of a
+** chain they are exported for.
+**************************************************************************************************/
+var STATIC_ADDRESS_SOURCES = map[string]func(chainID uint64) []common.Address{
+	`/:chainID/vaults/:address`:           listStaticVaults,
+	`/:chainID/vault/:address`:            listStaticVaults,
+	`/:chainID/vaults/:address/apy/stats`: listStaticVaults,
+	`/apy/:chainID/:address`:              listStaticVaults,
+	`/:chainID/strategies/:address`:       listStaticStrategies,
+	`/:chainID/strategy/:address`:         listStaticStrategies,
+	`/:chainID/prices/:address`:           listStaticTokens,
+	`/tokens/:chainID/:address/vaults`:    listStaticTokens,
+}
+
+/**************************************************************************************************
+** TStaticManifest lists the paths of the static export, with the file each one is written to.
+**************************************************************************************************/
+type TStaticManifest struct {
+	GeneratedAt  time.Time         `json:"generatedAt"`
+	StoreVersion uint64            `json:"storeVersion"`
+	Files        map[string]string `json:"files"`
+}
+
+func listStaticVaults(chainID uint64) []common.Address {
+	addresses := []common.Address{}
+	vaults, _ := storage.ListVaults(chainID)
+	for address := range vaults {
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
+
+func listStaticStrategies(chainID uint64) []common.Address {
+	addresses := []common.Address{}
+	_, strategies := storage.ListStrategies(chainID)
+	for _, strategy := range strategies {
+		addresses = append(addresses, strategy.Address)
+	}
+	return addresses
+}
+
+func listStaticTokens(chainID uint64) []common.Address {
+	return storage.ListERC20Addresses(chainID)
+}
+
+/**************************************************************************************************
+** listStaticPaths returns the paths a route is exported for, none when it is excluded or has a
+** parameter that can't be listed.
+**************************************************************************************************/
+func listStaticPaths(route string) []string {
+	if STATIC_EXCLUDED_ROUTES[route] {
+		return []string{}
+	}
+	for _, prefix := range STATIC_EXCLUDED_PREFIXES {
+		if strings.HasPrefix(route, prefix) {
+			return []string{}
+		}
+	}
+	hasChainID := strings.Contains(route, `:chainID`)
+	hasAddress := strings.Contains(route, `:address`)
+	switch parameters := strings.Count(route, `:`); {
+	case parameters == 0:
+		return []string{route}
+	case !hasChainID || parameters > 2 || (parameters == 2 && !hasAddress):
+		return []string{}
+	}
+
+	paths := []string{}
+	for _, chainID := range chains {
+		chainPath := strings.Replace(route, `:chainID`, strconv.FormatUint(chainID, 10), 1)
+		if !hasAddress {
+			paths = append(paths, chainPath)
+			continue
+		}
+		listAddresses, ok := STATIC_ADDRESS_SOURCES[route]
+		if !ok {
+			return []string{}
+		}
+		for _, address := range listAddresses(chainID) {
+			paths = append(paths, strings.Replace(chainPath, `:address`, address.Hex(), 1))
+		}
+	}
+	return paths
+}
+
+/**************************************************************************************************
+** getStaticFileName returns the file a path is written to, relative to the output directory.
+**************************************************************************************************/
+func getStaticFileName(path string) string {
+	name := strings.TrimPrefix(path, `/`)
+	if !strings.HasSuffix(name, `.json`) {
+		name += `.json`
+	}
+	return name
+}
+
+/**************************************************************************************************
+** renderStaticPath returns the body of a GET request on a path of the router, false if it did not
+** answer 200.
+**************************************************************************************************/
+func renderStaticPath(router *gin.Engine, path string) ([]byte, bool) {
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		return nil, false
+	}
+	return recorder.Body.Bytes(), true
+}
+
+/**************************************************************************************************
+** runStaticExport renders the public API of the stored data of the chains into the output
+** directory, without indexing anything.
+**************************************************************************************************/
+func runStaticExport() {
+	storage.InitializeStorage()
+	router := NewRouter()
+	manifest := TStaticManifest{
+		GeneratedAt:  time.Now(),
+		StoreVersion: storage.GetStoreVersion(),
+		Files:        make(map[string]string),
+	}
+	skipped := 0
+
+	for _, route := range router.Routes() {
+		if route.Method != http.MethodGet {
+			continue
+		}
+		for _, path := range listStaticPaths(route.Path) {
+			body, ok := renderStaticPath(router, path)
+			if !ok {
+				skipped++
+				continue
+			}
+			name := getStaticFileName(path)
+			file := filepath.Join(output, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+				logs.Error(`Failed to create the directory of ` + file + `: ` + err.Error())
+				return
+			}
+			if err := os.WriteFile(file, body, 0644); err != nil {
+				logs.Error(`Failed to write ` + file + `: ` + err.Error())
+				return
+			}
+			manifest.Files[path] = name
+		}
+	}
+
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		logs.Error(`Failed to marshal the manifest of the static export: ` + err.Error())
+		return
+	}
+	if err := os.WriteFile(filepath.Join(output, `manifest.json`), content, 0644); err != nil {
+		logs.Error(`Failed to write the manifest of the static export: ` + err.Error())
+		return
+	}
+	logs.Success(`Static export of ` + strconv.Itoa(len(manifest.Files)) + ` paths written to ` + output + `, ` + strconv.Itoa(skipped) + ` paths skipped`)
+}
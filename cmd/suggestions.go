@@ -0,0 +1,149 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/external/utils"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The suggestions are open to anyone, so they are rate limited per client IP to SUGGESTIONS_BURST
+** requests, one more being allowed every SUGGESTIONS_INTERVAL. Only the fields of the metadata
+** listed in SUGGESTION_FIELDS can be corrected, with a value of at most MAX_SUGGESTION_LENGTH
+** characters.
+**************************************************************************************************/
+const SUGGESTIONS_BURST = 5
+const SUGGESTIONS_INTERVAL = 12 * time.Minute
+const MAX_SUGGESTION_LENGTH = 2000
+const MAX_SUGGESTION_CONTACT_LENGTH = 200
+
+var SUGGESTION_FIELDS = []string{`name`, `description`, `protocols`, `category`}
+
+/**************************************************************************************************
+** limitSuggestions rate limits the suggestions per client IP, the IP being read from the headers
+** of the TRUSTED_PROXIES only.
+**************************************************************************************************/
+func limitSuggestions() gin.HandlerFunc {
+	return limitPerClientIP(`suggestions`, SUGGESTIONS_INTERVAL, SUGGESTIONS_BURST, 2*time.Hour, `too many suggestions, retry later`)
+}
+
+/**************************************************************************************************
+** submitSuggestion queues a correction of the metadata of a vault or of a strategy for moderation,
+** and notifies the operators. It returns 201 with the queued suggestion.
+**************************************************************************************************/
+func submitSuggestion(c *gin.Context) {
+	chainID, ok := helpers.AssertChainID(c.Param("chainID"))
+	if !ok {
+		utils.SendChainIDError(c, c.Param("chainID"))
+		return
+	}
+	address, ok := helpers.AssertAddress(c.Param("address"), chainID)
+	if !ok {
+		utils.SendError(c, utils.NewError(utils.ERROR_INVALID_ADDRESS, `invalid address`))
+		return
+	}
+	_, isVault := storage.GetVault(chainID, address)
+	_, isStrategy := storage.GuessStrategy(chainID, address)
+	if !isVault && !isStrategy {
+		utils.SendError(c, utils.NewError(utils.ERROR_VAULT_NOT_FOUND, `no vault or strategy at this address`))
+		return
+	}
+
+	var body struct {
+		Field   string `json:"field"`
+		Value   string `json:"value"`
+		Comment string `json:"comment"`
+		Contact string `json:"contact"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Field == `` || strings.TrimSpace(body.Value) == `` {
+		utils.SendError(c, utils.NewError(utils.ERROR_MISSING_PARAM, `the body must be { "field": "description", "value": "...", "comment": "...", "contact": "..." }`))
+		return
+	}
+	if !helpers.Contains(SUGGESTION_FIELDS, body.Field) {
+		utils.SendError(c, utils.NewError(utils.ERROR_INVALID_PARAM, `field must be one of `+strings.Join(SUGGESTION_FIELDS, `, `)))
+		return
+	}
+	if len(body.Value) > MAX_SUGGESTION_LENGTH || len(body.Comment) > MAX_SUGGESTION_LENGTH || len(body.Contact) > MAX_SUGGESTION_CONTACT_LENGTH {
+		utils.SendError(c, utils.NewError(utils.ERROR_INVALID_PARAM, `value and comment are limited to `+strconv.Itoa(MAX_SUGGESTION_LENGTH)+
+			` characters, contact to `+strconv.Itoa(MAX_SUGGESTION_CONTACT_LENGTH)))
+		return
+	}
+
+	submitter := sha256.Sum256([]byte(c.ClientIP()))
+	suggestion, err := storage.AddSuggestion(storage.TSuggestion{
+		ChainID:   chainID,
+		Address:   address,
+		Field:     body.Field,
+		Value:     strings.TrimSpace(body.Value),
+		Comment:   strings.TrimSpace(body.Comment),
+		Contact:   strings.TrimSpace(body.Contact),
+		Submitter: hex.EncodeToString(submitter[:8]),
+	})
+	if errors.Is(err, storage.ErrTooManyPendingSuggestions) {
+		utils.SendError(c, utils.NewError(utils.ERROR_RATE_LIMITED, `too many of your suggestions are pending, retry once they are reviewed`))
+		return
+	}
+	if err != nil {
+		utils.SendError(c, utils.NewError(utils.ERROR_RATE_LIMITED, `the moderation queue of the chain is full, retry later`))
+		return
+	}
+	TriggerMetadataSuggestionAlert(suggestion)
+	c.JSON(http.StatusCreated, suggestion)
+}
+
+/**************************************************************************************************
+** getSuggestions returns the suggestions of a chain with the status of the query, the pending ones
+** by default, `all` returning every status.
+**************************************************************************************************/
+func getSuggestions(c *gin.Context) {
+	chainID, ok := helpers.AssertChainID(c.Param("chainID"))
+	if !ok {
+		utils.SendChainIDError(c, c.Param("chainID"))
+		return
+	}
+	status := c.DefaultQuery(`status`, storage.SUGGESTION_PENDING)
+	if status == `all` {
+		status = ``
+	}
+	c.JSON(http.StatusOK, storage.ListSuggestions(chainID, status))
+}
+
+/**************************************************************************************************
+** reviewSuggestion accepts or rejects a suggestion of a chain, from the `status` of the body. The
+** accepted corrections still have to be applied to the meta repo by the operator.
+**************************************************************************************************/
+func reviewSuggestion(c *gin.Context) {
+	chainID, ok := helpers.AssertChainID(c.Param("chainID"))
+	if !ok {
+		utils.SendChainIDError(c, c.Param("chainID"))
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendError(c, utils.NewError(utils.ERROR_INVALID_FORMAT, `invalid suggestion id: `+c.Param("id")))
+		return
+	}
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || (body.Status != storage.SUGGESTION_ACCEPTED && body.Status != storage.SUGGESTION_REJECTED) {
+		utils.SendError(c, utils.NewError(utils.ERROR_INVALID_PARAM, `the body must be { "status": "accepted" | "rejected" }`))
+		return
+	}
+
+	suggestion, ok := storage.ReviewSuggestion(chainID, id, body.Status)
+	if !ok {
+		utils.SendError(c, utils.NewError(utils.ERROR_NOT_FOUND, `no suggestion `+c.Param("id")+` on chain `+c.Param("chainID")))
+		return
+	}
+	c.JSON(http.StatusOK, suggestion)
+}
@@ -6,15 +6,26 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/yearn/ydaemon/common/env"
 	"github.com/yearn/ydaemon/common/helpers"
 	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal"
+	"github.com/yearn/ydaemon/internal/fetcher"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/notifications"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/fees"
+	"github.com/yearn/ydaemon/processes/losses"
 	"github.com/yearn/ydaemon/processes/prices"
+	"github.com/yearn/ydaemon/processes/sharePrice"
 )
 
-var initializedCounter = 0
+var initializedCounter atomic.Int64
 
 func TriggerTgMessage(message string) {
 	telegramToken, ok := os.LookupEnv("TELEGRAM_BOT")
@@ -40,10 +51,151 @@ func TriggerTgMessage(message string) {
 	_ = m
 }
 
+/**************************************************************************************************
+** TriggerStateDriftAlert sends a summary of the drifts detected by the verification job between
+** the stored state and the on-chain state.
+**************************************************************************************************/
+func TriggerStateDriftAlert(chainID uint64, drifts []fetcher.TStateDrift) {
+	message := `🔍 - yDaemon detected ` + strconv.Itoa(len(drifts)) + ` drift(s) on chain ` + strconv.FormatUint(chainID, 10)
+	for i, drift := range drifts {
+		if i == 10 {
+			message += "\n- ..."
+			break
+		}
+		message += "\n- " + drift.Address + ` ` + drift.Field + `: stored ` + drift.Stored + `, onchain ` + drift.OnChain
+	}
+	notifications.Notify(chainID, notifications.RULE_STATE_DRIFT, message)
+}
+
+func TriggerSharePriceAnomalyAlert(anomaly sharePrice.TSharePriceAnomaly) {
+	message := `🚨 - yDaemon detected a share price ` + anomaly.Type + ` on vault ` + anomaly.VaultAddress +
+		` (chain ` + strconv.FormatUint(anomaly.ChainID, 10) + `): ` + strconv.FormatFloat(anomaly.Change*100, 'f', 2, 64) + `%` +
+		`, from ` + anomaly.PreviousPricePerShare + ` to ` + anomaly.PricePerShare
+	notifications.Notify(anomaly.ChainID, notifications.RULE_SHARE_PRICE_ANOMALY, message)
+}
+
+/**************************************************************************************************
+** TriggerVaultPriceLostAlert notifies when a vault above UNPRICED_ALERT_MIN_TVL_USD loses the price
+** of its underlying token, its TVL relying on the last known price, or being zero without one.
+**************************************************************************************************/
+func TriggerVaultPriceLostAlert(token prices.TUnpricedToken, vault prices.TUnpricedVault) {
+	fallback := `no last known price, its TVL is zero`
+	if token.LastKnownPrice != nil {
+		fallback = `using the last known price of ` + token.LastKnownPrice.String() + ` USD from ` + token.LastSource
+	}
+	message := `💸 - yDaemon lost the price of ` + token.Symbol + ` (` + token.Address + `) on chain ` + strconv.FormatUint(token.ChainID, 10) +
+		`, underlying of the vault ` + vault.Name + ` (` + vault.Address + `, TVL ` + strconv.FormatFloat(vault.TVL, 'f', 0, 64) + ` USD): ` + fallback
+	notifications.Notify(token.ChainID, notifications.RULE_PRICE_LOST, message)
+}
+
+/**************************************************************************************************
+** TriggerChainLaggingAlert and TriggerChainCaughtUpAlert notify when the data of a chain starts
+** and stops lagging behind the head of the chain.
+**************************************************************************************************/
+func TriggerChainLaggingAlert(chainID uint64, freshness storage.TDataFreshness) {
+	message := `⏱️ - yDaemon data of chain ` + strconv.FormatUint(chainID, 10) + ` is lagging ` +
+		(time.Duration(freshness.LagSeconds) * time.Second).String() + ` behind the head (block ` + strconv.FormatUint(freshness.Block, 10) + `)`
+	notifications.Notify(chainID, notifications.RULE_CHAIN_LAGGING, message)
+}
+
+func TriggerChainCaughtUpAlert(chainID uint64, freshness storage.TDataFreshness) {
+	message := `✅ - yDaemon data of chain ` + strconv.FormatUint(chainID, 10) + ` caught up with the head (lag ` +
+		(time.Duration(freshness.LagSeconds) * time.Second).String() + `)`
+	notifications.Notify(chainID, notifications.RULE_CHAIN_CAUGHT_UP, message)
+}
+
+/**************************************************************************************************
+** TriggerSequencerDownAlert and TriggerSequencerUpAlert notify when the sequencer of a L2 chain
+** goes down, pausing its refreshes, and when it is back up.
+**************************************************************************************************/
+func TriggerSequencerDownAlert(chainID uint64, status storage.TSequencerStatus) {
+	message := `🚦 - yDaemon detected the sequencer of chain ` + strconv.FormatUint(chainID, 10) + ` down since ` +
+		time.Unix(int64(status.Since), 0).UTC().Format(time.RFC3339) + `, its refreshes are paused`
+	notifications.Notify(chainID, notifications.RULE_SEQUENCER_DOWN, message)
+}
+
+func TriggerSequencerUpAlert(chainID uint64, status storage.TSequencerStatus) {
+	message := `✅ - yDaemon detected the sequencer of chain ` + strconv.FormatUint(chainID, 10) + ` back up since ` +
+		time.Unix(int64(status.Since), 0).UTC().Format(time.RFC3339) + `, its refreshes are resumed`
+	notifications.Notify(chainID, notifications.RULE_SEQUENCER_UP, message)
+}
+
+/**************************************************************************************************
+** TriggerChainDegradedAlert and TriggerChainRecoveredAlert notify when a chain is degraded because
+** its RPC endpoints are dead, stopping its refreshes, and when it is re-enabled.
+**************************************************************************************************/
+func TriggerChainDegradedAlert(chainID uint64, health storage.TRPCHealth) {
+	message := `🩺 - yDaemon found every RPC endpoint of chain ` + strconv.FormatUint(chainID, 10) + ` dead since ` +
+		time.Unix(int64(health.FailingSince), 0).UTC().Format(time.RFC3339) + `, its refreshes are stopped and its data served as stale`
+	notifications.Notify(chainID, notifications.RULE_CHAIN_DEGRADED, message)
+}
+
+func TriggerChainRecoveredAlert(chainID uint64, health storage.TRPCHealth) {
+	message := `✅ - yDaemon reached an RPC endpoint of chain ` + strconv.FormatUint(chainID, 10) + ` again, its refreshes are resumed`
+	notifications.Notify(chainID, notifications.RULE_CHAIN_RECOVERED, message)
+}
+
+/**************************************************************************************************
+** TriggerStrategyLossAlert notifies when a strategy of a vault reports a loss on its harvest.
+**************************************************************************************************/
+func TriggerStrategyLossAlert(chainID uint64, vault models.TVault, loss losses.TLoss) {
+	message := `🩸 - yDaemon detected a loss of ` + loss.Amount.String() + ` (~$` + strconv.FormatFloat(loss.AmountUSD, 'f', 2, 64) + `) reported by the strategy ` +
+		loss.Strategy.Hex() + ` of the vault ` + vault.Address.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10) + ` (tx ` + loss.TxHash.Hex() + `)`
+	notifications.Notify(chainID, notifications.RULE_STRATEGY_LOSS, message)
+}
+
+/**************************************************************************************************
+** TriggerNewStrategyAlert, TriggerFeeChangeAlert and TriggerAPYDriftAlert notify the routine
+** changes of the vaults, batched in the digest of their chain by default.
+**************************************************************************************************/
+func TriggerNewStrategyAlert(chainID uint64, strategy models.TStrategy) {
+	message := `🆕 - yDaemon detected the new strategy ` + strategy.Name + ` (` + strategy.Address.Hex() + `) of the vault ` +
+		strategy.VaultAddress.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10)
+	notifications.Notify(chainID, notifications.RULE_NEW_STRATEGY, message)
+}
+
+func TriggerFeeChangeAlert(chainID uint64, vaultAddress common.Address, change fees.TFeeChange) {
+	message := `🧾 - yDaemon detected a fee change ` + change.Type + ` for the vault ` + vaultAddress.Hex() + ` on chain ` +
+		strconv.FormatUint(chainID, 10) + `: management ` + strconv.FormatUint(change.ManagementFee, 10) + ` bps, performance ` +
+		strconv.FormatUint(change.PerformanceFee, 10) + ` bps, effective at ` + time.Unix(int64(change.EffectiveAt), 0).UTC().Format(time.RFC3339)
+	notifications.Notify(chainID, notifications.RULE_FEE_CHANGE, message)
+}
+
+func TriggerAPYDriftAlert(chainID uint64, vaultAddress common.Address, previous float64, current float64) {
+	message := `📈 - yDaemon detected a drift of the forward APY of the vault ` + vaultAddress.Hex() + ` on chain ` +
+		strconv.FormatUint(chainID, 10) + `: from ` + strconv.FormatFloat(previous*100, 'f', 2, 64) + `% to ` + strconv.FormatFloat(current*100, 'f', 2, 64) + `%`
+	notifications.Notify(chainID, notifications.RULE_APY_DRIFT, message)
+}
+
+/**************************************************************************************************
+** TriggerAPYDivergenceAlert notifies when the forward APY of a vault starts diverging from the APY
+** it realized over the last 7 days, usually a bug in the source of the forward APY.
+**************************************************************************************************/
+func TriggerAPYDivergenceAlert(chainID uint64, vaultAddress common.Address, divergence models.TAPYDivergence) {
+	message := `🧭 - yDaemon detected a forward APY of ` + strconv.FormatFloat(divergence.ForwardAPY*100, 'f', 2, 64) + `% for the vault ` +
+		vaultAddress.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10) + `, diverging from the ` +
+		strconv.FormatFloat(divergence.RealizedAPY*100, 'f', 2, 64) + `% realized over the last 7 days`
+	notifications.Notify(chainID, notifications.RULE_APY_DIVERGENCE, message)
+}
+
+/**************************************************************************************************
+** TriggerMetadataSuggestionAlert notifies a correction of the metadata suggested by the community,
+** waiting for a review in the moderation queue.
+**************************************************************************************************/
+func TriggerMetadataSuggestionAlert(suggestion storage.TSuggestion) {
+	value := []rune(suggestion.Value)
+	if len(value) > 200 {
+		value = append(value[:200], []rune(`...`)...)
+	}
+	message := `💬 - yDaemon received the suggestion #` + strconv.FormatUint(suggestion.ID, 10) + ` for the ` + suggestion.Field + ` of ` +
+		suggestion.Address.Hex() + ` on chain ` + strconv.FormatUint(suggestion.ChainID, 10) + `: ` + string(value)
+	notifications.Notify(suggestion.ChainID, notifications.RULE_METADATA_SUGGESTION, message)
+}
+
 func TriggerInitializedStatus(chainID uint64) {
-	initializedCounter++
-	TriggerTgMessage(`✅ - yDaemon initialized for chain ` + strconv.FormatUint(chainID, 10) + ` (` + strconv.Itoa(initializedCounter) + `/` + strconv.Itoa(len(chains)) + `)`)
-	logs.Success(`✅ - yDaemon initialized for chain ` + strconv.FormatUint(chainID, 10) + ` (` + strconv.Itoa(initializedCounter) + `/` + strconv.Itoa(len(chains)) + `)`)
+	initialized := strconv.FormatInt(initializedCounter.Add(1), 10)
+	TriggerTgMessage(`✅ - yDaemon initialized for chain ` + strconv.FormatUint(chainID, 10) + ` (` + initialized + `/` + strconv.Itoa(len(chains)) + `)`)
+	logs.Success(`✅ - yDaemon initialized for chain ` + strconv.FormatUint(chainID, 10) + ` (` + initialized + `/` + strconv.Itoa(len(chains)) + `)`)
 }
 
 func ListenToSignals() {
@@ -87,12 +239,19 @@ func ListenToSignals() {
 			TriggerTgMessage(`Available commands:
 - /help: Show this help message
 - /restart: Restart the daemon
+- /reload: Reload the configuration without restarting
 - /update: Update yDaemon with the latest version
 - /upd_prices <chainID>: Update the prices for a given chain
+- /pause <chainID>: Pause the refreshes of a chain
+- /resume <chainID>: Resume the refreshes of a chain
+- /mute <rule> <duration>: Mute the alerts of a rule, e.g. /mute fee_change 2h (0 unmutes it)
+- /loglevel <level>: Set the log level (DEBUG, INFO, WARNING, SUCCESS, ERROR)
 - /origins: Get the origins of access`)
 		case "restart":
 			TriggerTgMessage(`🔴 - ` + update.Message.From.UserName + ` asked for a restart`)
-			os.Exit(1)
+			go Shutdown(update.Message.From.UserName+` asked for a restart`, 1)
+		case "reload":
+			go ReloadConfig(update.Message.From.UserName + ` asked for a reload`)
 		case "update":
 			//this might be useless
 			reason := ` without a reason`
@@ -122,7 +281,7 @@ func ListenToSignals() {
 			}
 
 			//service ydaemon restart
-			os.Exit(1)
+			go Shutdown(update.Message.From.UserName+` asked for an update`, 1)
 		case "origins":
 			listOfOrigins := []string{}
 			itemsInLimiter := limiterSet.Items()
@@ -153,6 +312,57 @@ func ListenToSignals() {
 			}
 			TriggerTgMessage(`💰 - ` + update.Message.From.UserName + ` asked for a price update for chain ` + strconv.FormatUint(chainID, 10))
 			prices.UpdatePrices(chainID)
+		case "pause", "resume":
+			chainID, err := strconv.ParseUint(update.Message.CommandArguments(), 10, 64)
+			if err != nil {
+				TriggerTgMessage(`🔴 - Incorrect format. Should be /` + update.Message.Command() + ` <chainID> (number)`)
+				continue
+			}
+			if _, ok := env.GetChain(chainID); !ok {
+				TriggerTgMessage(`🔴 - Chain not supported`)
+				continue
+			}
+			if update.Message.Command() == "pause" {
+				if !internal.PauseChain(chainID) {
+					TriggerTgMessage(`⏸️ - Chain ` + strconv.FormatUint(chainID, 10) + ` is already paused`)
+					continue
+				}
+				TriggerTgMessage(`⏸️ - ` + update.Message.From.UserName + ` paused chain ` + strconv.FormatUint(chainID, 10) + `: its refreshes are skipped until /resume`)
+			} else {
+				if !internal.ResumeChain(chainID) {
+					TriggerTgMessage(`▶️ - Chain ` + strconv.FormatUint(chainID, 10) + ` is not paused`)
+					continue
+				}
+				TriggerTgMessage(`▶️ - ` + update.Message.From.UserName + ` resumed chain ` + strconv.FormatUint(chainID, 10) + `: its refreshes restart on their next schedule`)
+			}
+		case "mute":
+			arguments := strings.Fields(update.Message.CommandArguments())
+			if len(arguments) != 2 {
+				TriggerTgMessage(`🔴 - Incorrect format. Should be /mute <rule> <duration>`)
+				continue
+			}
+			if !notifications.IsRule(arguments[0]) {
+				TriggerTgMessage(`🔴 - Unknown rule ` + arguments[0])
+				continue
+			}
+			duration, err := time.ParseDuration(arguments[1])
+			if err != nil || duration < 0 {
+				TriggerTgMessage(`🔴 - Incorrect duration. Should be like 30m or 2h`)
+				continue
+			}
+			until := notifications.Mute(arguments[0], duration)
+			if duration == 0 {
+				TriggerTgMessage(`🔔 - ` + update.Message.From.UserName + ` unmuted the ` + arguments[0] + ` alerts`)
+				continue
+			}
+			TriggerTgMessage(`🔕 - ` + update.Message.From.UserName + ` muted the ` + arguments[0] + ` alerts until ` + until.UTC().Format(time.RFC3339))
+		case "loglevel":
+			level := strings.ToUpper(update.Message.CommandArguments())
+			if !logs.SetLevel(level) {
+				TriggerTgMessage(`🔴 - Incorrect level. Should be /loglevel <DEBUG|INFO|WARNING|SUCCESS|ERROR>`)
+				continue
+			}
+			TriggerTgMessage(`📝 - ` + update.Message.From.UserName + ` set the log level to ` + level)
 		default:
 			msg.Text = "I don't know that command"
 			bot.Send(msg)
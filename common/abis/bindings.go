@@ -0,0 +1,62 @@
+package abis
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+/**************************************************************************************************
+** The bindings are generated like abigen does, one file per ABI of the directory. The type of a
+** binding is the name of its ABI, without the characters Go does not accept in an identifier:
+** `yVault.3.0.0.json` gives a `YVault300` type in `yVault.3.0.0.go`.
+**************************************************************************************************/
+var nonIdentifierCharacters = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+/**************************************************************************************************
+** bindingType returns the type of the binding of an ABI, from its name.
+**************************************************************************************************/
+func bindingType(name string) string {
+	typeName := nonIdentifierCharacters.ReplaceAllString(name, ``)
+	if typeName == `` || (typeName[0] >= '0' && typeName[0] <= '9') {
+		typeName = `Contract` + typeName
+	}
+	return strings.ToUpper(typeName[:1]) + typeName[1:]
+}
+
+/**************************************************************************************************
+** GenerateBindings returns the Go bindings of the ABIs of a directory, in the package pkg, keyed
+** by file name.
+**************************************************************************************************/
+func GenerateBindings(directory string, pkg string) (map[string]string, error) {
+	rawABIs, err := readRawABIs(directory)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(rawABIs))
+	for name := range rawABIs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make(map[string]string, len(names))
+	for _, name := range names {
+		code, err := bind.Bind(
+			[]string{bindingType(name)},
+			[]string{string(rawABIs[name])},
+			[]string{``},
+			[]map[string]string{nil},
+			pkg,
+			bind.LangGo,
+			nil,
+			nil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		files[name+`.go`] = code
+	}
+	return files, nil
+}
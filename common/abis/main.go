@@ -0,0 +1,216 @@
+package abis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	gethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/ethereum"
+)
+
+/**************************************************************************************************
+** The bindings of common/contracts are generated and committed for every contract yDaemon calls.
+** For a new version of a contract only read through a few view functions (an oracle, an
+** accountant, an allocator), dropping its ABI in ABI_DIRECTORY is enough: the ABIs of the
+** directory are loaded at startup, named after their file (`APROracleV2.json` is `APROracleV2`),
+** and their view functions are called by name, the arguments and the results being converted by
+** reflection. A file is either the ABI itself or a compiler artifact with an `abi` field. The
+** `bindings` process generates the Go bindings of the directory, for the contracts outgrowing it.
+**************************************************************************************************/
+var (
+	loadedABIs = make(map[string]*abi.ABI)
+	abisMtx    sync.RWMutex
+)
+
+/**************************************************************************************************
+** extractABI returns the ABI of the content of an ABI file: the content itself, or the `abi` field
+** of an artifact.
+**************************************************************************************************/
+func extractABI(content []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return trimmed, nil
+	}
+	var artifact struct {
+		ABI json.RawMessage `json:"abi"`
+	}
+	if err := json.Unmarshal(trimmed, &artifact); err != nil {
+		return nil, err
+	}
+	if len(artifact.ABI) == 0 {
+		return nil, errors.New(`no abi field in the artifact`)
+	}
+	return artifact.ABI, nil
+}
+
+/**************************************************************************************************
+** readRawABIs reads the ABIs of a directory as JSON, keyed by name. A missing directory has no ABI.
+**************************************************************************************************/
+func readRawABIs(directory string) (map[string][]byte, error) {
+	rawABIs := make(map[string][]byte)
+	files, err := filepath.Glob(filepath.Join(directory, `*.json`))
+	if err != nil {
+		return rawABIs, err
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return rawABIs, err
+		}
+		rawABI, err := extractABI(content)
+		if err != nil {
+			return rawABIs, errors.New(filepath.Base(file) + `: ` + err.Error())
+		}
+		rawABIs[strings.TrimSuffix(filepath.Base(file), `.json`)] = rawABI
+	}
+	return rawABIs, nil
+}
+
+/**************************************************************************************************
+** ReadDirectory reads and parses the ABIs of a directory, keyed by name.
+**************************************************************************************************/
+func ReadDirectory(directory string) (map[string]*abi.ABI, error) {
+	abis := make(map[string]*abi.ABI)
+	rawABIs, err := readRawABIs(directory)
+	if err != nil {
+		return abis, err
+	}
+	for name, rawABI := range rawABIs {
+		parsedABI, err := abi.JSON(bytes.NewReader(rawABI))
+		if err != nil {
+			return abis, errors.New(name + `: ` + err.Error())
+		}
+		abis[name] = &parsedABI
+	}
+	return abis, nil
+}
+
+/**************************************************************************************************
+** LoadDirectory loads the ABIs of a directory, replacing the ones loaded before. Nothing is
+** replaced when one of the files is invalid.
+**************************************************************************************************/
+func LoadDirectory(directory string) (int, error) {
+	abis, err := ReadDirectory(directory)
+	if err != nil {
+		return 0, err
+	}
+	abisMtx.Lock()
+	defer abisMtx.Unlock()
+	loadedABIs = abis
+	return len(abis), nil
+}
+
+/**************************************************************************************************
+** GetABI returns a loaded ABI by name.
+**************************************************************************************************/
+func GetABI(name string) (*abi.ABI, bool) {
+	abisMtx.RLock()
+	defer abisMtx.RUnlock()
+	parsedABI, ok := loadedABIs[name]
+	return parsedABI, ok
+}
+
+/**************************************************************************************************
+** ListABIs returns the names of the loaded ABIs, sorted.
+**************************************************************************************************/
+func ListABIs() []string {
+	abisMtx.RLock()
+	defer abisMtx.RUnlock()
+	names := make([]string, 0, len(loadedABIs))
+	for name := range loadedABIs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/**************************************************************************************************
+** packView returns the ABI and the call data of a call to a view function of a loaded ABI.
+**************************************************************************************************/
+func packView(abiName string, method string, args ...interface{}) (*abi.ABI, []byte, error) {
+	parsedABI, ok := GetABI(abiName)
+	if !ok {
+		return nil, nil, errors.New(`unknown abi ` + abiName)
+	}
+	abiMethod, ok := parsedABI.Methods[method]
+	if !ok {
+		return nil, nil, errors.New(`unknown method ` + abiName + `.` + method)
+	}
+	if !abiMethod.IsConstant() {
+		return nil, nil, errors.New(abiName + `.` + method + ` is not a view function`)
+	}
+	callData, err := parsedABI.Pack(method, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parsedABI, callData, nil
+}
+
+/**************************************************************************************************
+** NewCall returns the multicall call of a view function of a loaded ABI, for multicalls.Perform.
+**************************************************************************************************/
+func NewCall(abiName string, name string, target common.Address, method string, args ...interface{}) (ethereum.Call, error) {
+	parsedABI, callData, err := packView(abiName, method, args...)
+	if err != nil {
+		return ethereum.Call{}, err
+	}
+	return ethereum.Call{
+		Target:   target,
+		Abi:      parsedABI,
+		Method:   method,
+		CallData: callData,
+		Name:     name,
+	}, nil
+}
+
+/**************************************************************************************************
+** callView calls a view function of a loaded ABI on a contract, at the latest block.
+**************************************************************************************************/
+func callView(chainID uint64, abiName string, target common.Address, method string, args ...interface{}) (*abi.ABI, []byte, error) {
+	parsedABI, callData, err := packView(abiName, method, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	client := ethereum.GetRPC(chainID)
+	if client == nil {
+		return nil, nil, errors.New(`no RPC for the chain`)
+	}
+	result, err := client.CallContract(context.Background(), gethereum.CallMsg{To: &target, Data: callData}, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parsedABI, result, nil
+}
+
+/**************************************************************************************************
+** CallView calls a view function of a loaded ABI on a contract and returns its outputs.
+**************************************************************************************************/
+func CallView(chainID uint64, abiName string, target common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	parsedABI, result, err := callView(chainID, abiName, target, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parsedABI.Unpack(method, result)
+}
+
+/**************************************************************************************************
+** CallViewInto calls a view function of a loaded ABI on a contract and copies its outputs into
+** out: a pointer to the type of the output for a single output, to a struct with a field per
+** output otherwise.
+**************************************************************************************************/
+func CallViewInto(chainID uint64, abiName string, target common.Address, out interface{}, method string, args ...interface{}) error {
+	parsedABI, result, err := callView(chainID, abiName, target, method, args...)
+	if err != nil {
+		return err
+	}
+	return parsedABI.UnpackIntoInterface(out, method, result)
+}
@@ -0,0 +1,74 @@
+package abis
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const testOracleABI = `[{"inputs":[{"name":"vault","type":"address"},{"name":"delta","type":"int256"}],"name":"getExpectedApr","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"name":"vault","type":"address"}],"name":"setOracle","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+
+func writeTestABIs(t *testing.T) string {
+	directory := t.TempDir()
+	files := map[string]string{
+		`APROracleV2.json`:  testOracleABI,
+		`Accountant.2.json`: `{"contractName":"Accountant","abi":` + testOracleABI + `}`,
+		`notes.txt`:         `ignored`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(directory, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return directory
+}
+
+func TestLoadDirectory(t *testing.T) {
+	count, err := LoadDirectory(writeTestABIs(t))
+	if err != nil || count != 2 {
+		t.Fatalf("expected the ABI and the artifact to be loaded, got %d %v", count, err)
+	}
+	if names := ListABIs(); len(names) != 2 || names[0] != `APROracleV2` || names[1] != `Accountant.2` {
+		t.Errorf("expected the ABIs to be named after their file, got %v", names)
+	}
+	if count, err := LoadDirectory(filepath.Join(t.TempDir(), `missing`)); err != nil || count != 0 {
+		t.Errorf("expected a missing directory to have no ABI, got %d %v", count, err)
+	}
+}
+
+func TestNewCall(t *testing.T) {
+	if _, err := LoadDirectory(writeTestABIs(t)); err != nil {
+		t.Fatal(err)
+	}
+	vault := common.HexToAddress(`0x182863131F9a4630fF9E27830d945B1413e347E8`)
+	call, err := NewCall(`APROracleV2`, `oracle`, vault, `getExpectedApr`, vault, big.NewInt(0))
+	if err != nil || call.Method != `getExpectedApr` || len(call.CallData) != 4+32*2 {
+		t.Errorf("expected the call of the view function to be packed, got %v %v", call, err)
+	}
+	if _, err := NewCall(`APROracleV2`, `oracle`, vault, `setOracle`, vault); err == nil {
+		t.Error("expected the functions changing the state to be rejected")
+	}
+	if _, err := NewCall(`APROracleV2`, `oracle`, vault, `getExpectedApr`, vault); err == nil {
+		t.Error("expected the missing arguments to be rejected")
+	}
+	if _, err := NewCall(`Unknown`, `oracle`, vault, `getExpectedApr`); err == nil {
+		t.Error("expected the unknown ABIs to be rejected")
+	}
+}
+
+func TestGenerateBindings(t *testing.T) {
+	files, err := GenerateBindings(writeTestABIs(t), `contracts`)
+	if err != nil || len(files) != 2 {
+		t.Fatalf("expected a binding per ABI, got %d %v", len(files), err)
+	}
+	if !strings.Contains(files[`APROracleV2.go`], `type APROracleV2 struct`) {
+		t.Error("expected the binding to be typed after its ABI")
+	}
+	if !strings.Contains(files[`Accountant.2.go`], `type Accountant2 struct`) {
+		t.Error("expected the characters not accepted in an identifier to be dropped")
+	}
+}
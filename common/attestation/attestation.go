@@ -0,0 +1,160 @@
+package attestation
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+/**************************************************************************************************
+** An attestation is the signature, by the key of the operator, of the data served for a vault or a
+** token, so the consumers can verify where it comes from and how fresh it is before acting on it.
+**
+** The digest is the keccak256 of the ABI encoding of
+**     (uint256 chainID, address address, int256 apy, uint256 price, uint256 timestamp, uint256 block)
+** with the APY scaled by 1e18 (0 for a token) and the price in USD scaled by 1e6, as stored. The
+** digest is signed as an EIP-191 personal message, so it can be checked on-chain with
+** `ECDSA.recover(MessageHashUtils.toEthSignedMessageHash(digest), signature)`.
+**************************************************************************************************/
+type TAttestation struct {
+	Signer      string `json:"signer"`
+	ChainID     uint64 `json:"chainID"`
+	Address     string `json:"address"`
+	APY         string `json:"apy"`
+	Price       string `json:"price"`
+	Timestamp   uint64 `json:"timestamp"`
+	BlockNumber uint64 `json:"blockNumber"`
+	Digest      string `json:"digest"`
+	Signature   string `json:"signature"`
+}
+
+var digestArguments abi.Arguments
+
+var signerKey *ecdsa.PrivateKey
+var signerOnce sync.Once
+
+func init() {
+	uint256Type, _ := abi.NewType(`uint256`, ``, nil)
+	int256Type, _ := abi.NewType(`int256`, ``, nil)
+	addressType, _ := abi.NewType(`address`, ``, nil)
+	digestArguments = abi.Arguments{
+		{Type: uint256Type},
+		{Type: addressType},
+		{Type: int256Type},
+		{Type: uint256Type},
+		{Type: uint256Type},
+		{Type: uint256Type},
+	}
+}
+
+/**************************************************************************************************
+** getSigner returns the key of the operator, parsed once from ATTESTATION_PRIVATE_KEY. It returns
+** nil if no key is configured or the key is invalid.
+**************************************************************************************************/
+func getSigner() *ecdsa.PrivateKey {
+	signerOnce.Do(func() {
+		if env.ATTESTATION_PRIVATE_KEY == `` {
+			return
+		}
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(env.ATTESTATION_PRIVATE_KEY, `0x`))
+		if err != nil {
+			logs.Error(`Invalid ATTESTATION_PRIVATE_KEY, the responses will not be signed: ` + err.Error())
+			return
+		}
+		signerKey = key
+	})
+	return signerKey
+}
+
+/**************************************************************************************************
+** IsEnabled returns true if an operator key is configured to sign the responses.
+**************************************************************************************************/
+func IsEnabled() bool {
+	return getSigner() != nil
+}
+
+/**************************************************************************************************
+** ScaleAPY converts an APY (0.05 for 5%) to the fixed point value of the digest, scaled by 1e18.
+**************************************************************************************************/
+func ScaleAPY(apy *bigNumber.Float) *big.Int {
+	if apy == nil {
+		return big.NewInt(0)
+	}
+	scaled := new(big.Float).Mul(&apy.Float, big.NewFloat(1e18))
+	value, _ := scaled.Int(nil)
+	return value
+}
+
+/**************************************************************************************************
+** ComputeDigest returns the digest signed for the given data. It is exposed for the consumers and
+** the tests to verify an attestation.
+**************************************************************************************************/
+func ComputeDigest(chainID uint64, address common.Address, apy *big.Int, price *big.Int, timestamp uint64, blockNumber uint64) (common.Hash, error) {
+	encoded, err := digestArguments.Pack(
+		new(big.Int).SetUint64(chainID),
+		address,
+		apy,
+		price,
+		new(big.Int).SetUint64(timestamp),
+		new(big.Int).SetUint64(blockNumber),
+	)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+/**************************************************************************************************
+** Sign signs the APY and the price of a vault or a token, as of the snapshot of the chain they
+** were read in: its block, and its time, which is the timestamp signed, for stale data never to
+** get a fresh signature. The APY is nil for a token. It returns false if no key is configured, if
+** no snapshot was taken yet or if the signature failed.
+**************************************************************************************************/
+func Sign(chainID uint64, address common.Address, apy *bigNumber.Float, price *bigNumber.Int, blockNumber uint64, snapshotTime time.Time) (*TAttestation, bool) {
+	key := getSigner()
+	if key == nil || snapshotTime.IsZero() {
+		return nil, false
+	}
+
+	scaledAPY := ScaleAPY(apy)
+	rawPrice := big.NewInt(0)
+	if price != nil {
+		rawPrice = new(big.Int).Set(&price.Int)
+	}
+	timestamp := uint64(snapshotTime.Unix())
+
+	digest, err := ComputeDigest(chainID, address, scaledAPY, rawPrice, timestamp, blockNumber)
+	if err != nil {
+		logs.Error(`Failed to compute the attestation digest: ` + err.Error())
+		return nil, false
+	}
+	signature, err := crypto.Sign(accounts.TextHash(digest.Bytes()), key)
+	if err != nil {
+		logs.Error(`Failed to sign the attestation: ` + err.Error())
+		return nil, false
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+
+	return &TAttestation{
+		Signer:      crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		ChainID:     chainID,
+		Address:     address.Hex(),
+		APY:         scaledAPY.String(),
+		Price:       rawPrice.String(),
+		Timestamp:   timestamp,
+		BlockNumber: blockNumber,
+		Digest:      digest.Hex(),
+		Signature:   hexutil.Encode(signature),
+	}, true
+}
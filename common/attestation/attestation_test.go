@@ -0,0 +1,76 @@
+package attestation
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+)
+
+/**************************************************************************************************
+** TestSign verifies that an attestation can be checked by a consumer: the digest is the one of the
+** signed values and the signature, as an EIP-191 personal message, recovers the operator address.
+**************************************************************************************************/
+func TestSign(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate a key: %v", err)
+	}
+	env.ATTESTATION_PRIVATE_KEY = hexutil.Encode(crypto.FromECDSA(key))
+	if !IsEnabled() {
+		t.Fatal("Expected the attestations to be enabled with a key")
+	}
+
+	vault := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	apy := bigNumber.NewFloat(0.05)
+	price := bigNumber.NewInt(1_020_000)
+	if _, ok := Sign(1, vault, apy, price, 19_000_000, time.Time{}); ok {
+		t.Error("Expected no attestation before the first snapshot")
+	}
+	snapshotTime := time.Now().Add(-2 * time.Hour)
+	signed, ok := Sign(1, vault, apy, price, 19_000_000, snapshotTime)
+	if !ok {
+		t.Fatal("Expected the attestation to be signed")
+	}
+	if signed.Timestamp != uint64(snapshotTime.Unix()) {
+		t.Errorf("Timestamp mismatch, got %d, expected the snapshot time %d", signed.Timestamp, snapshotTime.Unix())
+	}
+
+	expectedSigner := crypto.PubkeyToAddress(key.PublicKey)
+	if signed.Signer != expectedSigner.Hex() {
+		t.Errorf("Signer mismatch, got %s, expected %s", signed.Signer, expectedSigner.Hex())
+	}
+	if signed.APY != "50000000000000000" {
+		t.Errorf("APY mismatch, got %s, expected 50000000000000000", signed.APY)
+	}
+	if signed.Price != "1020000" {
+		t.Errorf("Price mismatch, got %s, expected 1020000", signed.Price)
+	}
+
+	digest, err := ComputeDigest(1, vault, ScaleAPY(apy), big.NewInt(1_020_000), signed.Timestamp, 19_000_000)
+	if err != nil {
+		t.Fatalf("Failed to compute the digest: %v", err)
+	}
+	if digest.Hex() != signed.Digest {
+		t.Errorf("Digest mismatch, got %s, expected %s", signed.Digest, digest.Hex())
+	}
+
+	signature := hexutil.MustDecode(signed.Signature)
+	if signature[crypto.RecoveryIDOffset] != 27 && signature[crypto.RecoveryIDOffset] != 28 {
+		t.Errorf("Expected a recovery id of 27 or 28, got %d", signature[crypto.RecoveryIDOffset])
+	}
+	signature[crypto.RecoveryIDOffset] -= 27
+	publicKey, err := crypto.SigToPub(accounts.TextHash(digest.Bytes()), signature)
+	if err != nil {
+		t.Fatalf("Failed to recover the signer: %v", err)
+	}
+	if crypto.PubkeyToAddress(*publicKey) != expectedSigner {
+		t.Errorf("Recovered signer mismatch, got %s, expected %s", crypto.PubkeyToAddress(*publicKey).Hex(), expectedSigner.Hex())
+	}
+}
@@ -10,14 +10,32 @@ import (
 )
 
 var ARBITRUM = TChain{
-	ID:              42161,
-	RpcURI:          `https://arbitrum.public-rpc.com`,
-	SubgraphURI:     `https://api.thegraph.com/subgraphs/name/yearn/yearn-vaults-v2-arbitrum`,
-	EtherscanURI:    `https://api.etherscan.io/v2/api`,
-	MaxBlockRange:   100_000_000,
-	MaxBatchSize:    math.MaxInt64,
-	AvgBlocksPerDay: 320_000,
-	CanUseWebsocket: false,
+	ID:                 42161,
+	RpcURI:             `https://arbitrum.public-rpc.com`,
+	SubgraphURI:        `https://api.thegraph.com/subgraphs/name/yearn/yearn-vaults-v2-arbitrum`,
+	EtherscanURI:       `https://api.etherscan.io/v2/api`,
+	MaxBlockRange:      100_000_000,
+	MaxBatchSize:       math.MaxInt64,
+	AvgBlocksPerDay:    320_000,
+	ConfirmationBlocks: 240,
+	CanUseWebsocket:    false,
+	Capabilities: TChainCapabilities{
+		SupportsLogsAddressArray: true,
+		HasMulticall:             true,
+		SupportsTraces:           false,
+	},
+	APRPolicy: TChainAPRPolicy{
+		ShouldUseV2APR: false,
+	},
+	GasPolicy: TChainGasPolicy{
+		WrappedCoin:         common.HexToAddress(`0x82aF49447D8a07e3bd95BD0d56f35241523fBab1`),
+		HarvestGasUnits:     1_000_000,
+		HarvestsPerYear:     52,
+		L1FeeOracle:         L1_FEE_ORACLE_ARBITRUM,
+		L1FeeOracleContract: common.HexToAddress(`0x000000000000000000000000000000000000006C`),
+		L1DataBytes:         600,
+		TVLThresholdUSD:     1_000_000,
+	},
 	LensContract: TContractData{
 		Address: common.HexToAddress(`0x043518AB266485dC085a1DB095B8d9C2Fc78E9b9`),
 		Block:   2396321,
@@ -26,10 +44,15 @@ var ARBITRUM = TChain{
 		Address: common.HexToAddress(`0x842eC2c7D803033Edf55E478F461FC547Bc54EB2`),
 		Block:   821923,
 	},
+	SequencerUptimeFeed:  common.HexToAddress(`0xFdB631F5EE196F0ed6FAa767959853A9F217697D`),
+	UniV3PositionManager: common.HexToAddress(`0xC36442b4a4522E871399CD717aBDD847Ab11FE88`),
 	PartnerContract: TContractData{
 		Address: common.HexToAddress(`0x0e5b46E4b2a05fd53F5a4cD974eb98a9a613bcb7`),
 		Block:   30385403,
 	},
+	V3RouterContract: TContractData{
+		Address: common.HexToAddress(`0x1112dbCF805682e828606f74AB717abf4b4FD8DE`),
+	},
 	APROracleContract: TContractData{
 		Address: common.HexToAddress(`0x1981AD9F44F2EA9aDd2dC4AD7D075c102C70aF92`),
 		Block:   265347717,
@@ -10,14 +10,32 @@ import (
 )
 
 var BASE = TChain{
-	ID:              8453,
-	RpcURI:          `https://developer-access-mainnet.base.org`,
-	SubgraphURI:     ``,
-	EtherscanURI:    `https://api.etherscan.io/v2/api`,
-	MaxBlockRange:   100_000_000,
-	MaxBatchSize:    math.MaxInt64,
-	AvgBlocksPerDay: 43_200,
-	CanUseWebsocket: true,
+	ID:                 8453,
+	RpcURI:             `https://developer-access-mainnet.base.org`,
+	SubgraphURI:        ``,
+	EtherscanURI:       `https://api.etherscan.io/v2/api`,
+	MaxBlockRange:      100_000_000,
+	MaxBatchSize:       math.MaxInt64,
+	AvgBlocksPerDay:    43_200,
+	ConfirmationBlocks: 120,
+	CanUseWebsocket:    true,
+	Capabilities: TChainCapabilities{
+		SupportsLogsAddressArray: true,
+		HasMulticall:             true,
+		SupportsTraces:           false,
+	},
+	APRPolicy: TChainAPRPolicy{
+		ShouldUseV2APR: false,
+	},
+	GasPolicy: TChainGasPolicy{
+		WrappedCoin:         common.HexToAddress(`0x4200000000000000000000000000000000000006`),
+		HarvestGasUnits:     1_000_000,
+		HarvestsPerYear:     52,
+		L1FeeOracle:         L1_FEE_ORACLE_OP_STACK,
+		L1FeeOracleContract: common.HexToAddress(`0x420000000000000000000000000000000000000F`),
+		L1DataBytes:         600,
+		TVLThresholdUSD:     1_000_000,
+	},
 	LensContract: TContractData{
 		Address: common.HexToAddress(`0xE0F3D78DB7bC111996864A32d22AB0F59Ca5Fa86`),
 		Block:   3318817,
@@ -26,6 +44,8 @@ var BASE = TChain{
 		Address: common.HexToAddress(`0xca11bde05977b3631167028862be2a173976ca11`),
 		Block:   5022,
 	},
+	SequencerUptimeFeed:  common.HexToAddress(`0xBCF85224fc0756B9Fa45aA7892530B47e10b6433`),
+	UniV3PositionManager: common.HexToAddress(`0x03a520b32C04BF3bEEf7BEb72E919cf822Ed34f1`),
 	Coin: models.TERC20Token{
 		Address:                   DEFAULT_COIN_ADDRESS,
 		UnderlyingTokensAddresses: []common.Address{},
@@ -10,14 +10,34 @@ import (
 )
 
 var ETHEREUM = TChain{
-	ID:              1,
-	RpcURI:          `https://eth.public-rpc.com`,
-	SubgraphURI:     ``,
-	EtherscanURI:    `https://api.etherscan.io/v2/api`,
-	MaxBlockRange:   100_000_000,
-	MaxBatchSize:    math.MaxInt64,
-	AvgBlocksPerDay: 7150,
-	CanUseWebsocket: true,
+	ID:                 1,
+	RpcURI:             `https://eth.public-rpc.com`,
+	SubgraphURI:        ``,
+	EtherscanURI:       `https://api.etherscan.io/v2/api`,
+	MaxBlockRange:      100_000_000,
+	MaxBatchSize:       math.MaxInt64,
+	AvgBlocksPerDay:    7150,
+	ConfirmationBlocks: 12,
+	CanUseWebsocket:    true,
+	Capabilities: TChainCapabilities{
+		SupportsLogsAddressArray: true,
+		HasMulticall:             true,
+		SupportsTraces:           true,
+	},
+	APRPolicy: TChainAPRPolicy{
+		ShouldUseV2APR: false,
+	},
+	Treasury: TChainTreasury{
+		Addresses: []common.Address{
+			common.HexToAddress(`0x93A62dA5a14C80f265DAbC077fCEE437B1a0Efde`), // brain.ychad.eth
+			common.HexToAddress(`0xFEB4acf3df3cDEA7399794D0869ef76A6EfAff52`), // ychad.eth
+		},
+		BuybackContracts: []common.Address{
+			common.HexToAddress(`0x6903223578806940bd3ff0C51f87aa43968424c8`), // YFI Buyer
+		},
+		BuybackToken: common.HexToAddress(`0x0bc529c00C6401aEF6D220BE8C6Ea1667F6Ad93e`), // YFI
+		StartBlock:   10_950_000,
+	},
 	YBribeV3Contract: TContractData{
 		Address: common.HexToAddress(`0x03dFdBcD4056E2F92251c7B07423E1a33a7D3F6d`),
 		Block:   15878262,
@@ -34,10 +54,19 @@ var ETHEREUM = TChain{
 		Address: common.HexToAddress(`0x8ee392a4787397126C163Cb9844d7c447da419D8`),
 		Block:   14166636,
 	},
+	V3RouterContract: TContractData{
+		Address: common.HexToAddress(`0x1112dbCF805682e828606f74AB717abf4b4FD8DE`),
+	},
 	APROracleContract: TContractData{
 		Address: common.HexToAddress(`0x1981AD9F44F2EA9aDd2dC4AD7D075c102C70aF92`),
 		Block:   19070394,
 	},
+	ReportTriggerContract: TContractData{
+		Address: common.HexToAddress(`0xA045D4dAeA28BA7Bfe234c96eAa03daFae85A147`),
+	},
+	AaveV3DataProvider:   common.HexToAddress(`0x7B4EB56E7CD4b454BA8ff71E4518426369a138a3`),
+	LlamaLendFactory:     common.HexToAddress(`0xeA6876DDE9e3467564acBeE1Ed5bac88783205E0`),
+	UniV3PositionManager: common.HexToAddress(`0xC36442b4a4522E871399CD717aBDD847Ab11FE88`),
 	ExtraStakingContracts: []TExtraStakingContracts{
 		{
 			VaultAddress:   common.HexToAddress(`0xe24BA27551aBE96Ca401D39761cA2319Ea14e3CB`),
@@ -45,6 +74,48 @@ var ETHEREUM = TChain{
 			Tag:            `JUICED`,
 		},
 	},
+	RateProviders: []TRateProvider{
+		{
+			Token:    common.HexToAddress(`0x83F20F44975D03b1b09e64809B757c47f942BEeA`), // sDAI
+			Provider: common.HexToAddress(`0x83F20F44975D03b1b09e64809B757c47f942BEeA`),
+			Method:   RATE_PROVIDER_ERC4626,
+		},
+		{
+			Token:    common.HexToAddress(`0xa3931d71877C0E7a3148CB7Eb4463524FEc27fbD`), // sUSDS
+			Provider: common.HexToAddress(`0xa3931d71877C0E7a3148CB7Eb4463524FEc27fbD`),
+			Method:   RATE_PROVIDER_ERC4626,
+		},
+		{
+			Token:    common.HexToAddress(`0x9D39A5DE30e57443BfF2A8307A4256c8797A3497`), // sUSDe
+			Provider: common.HexToAddress(`0x9D39A5DE30e57443BfF2A8307A4256c8797A3497`),
+			Method:   RATE_PROVIDER_ERC4626,
+		},
+		{
+			Token:    common.HexToAddress(`0xac3E018457B222d93114458476f3E3416Abbe38F`), // sfrxETH
+			Provider: common.HexToAddress(`0xac3E018457B222d93114458476f3E3416Abbe38F`),
+			Method:   RATE_PROVIDER_ERC4626,
+		},
+		{
+			Token:    common.HexToAddress(`0x7f39C581F595B53c5cb19bD0b3f8dA6c935E2Ca0`), // wstETH
+			Provider: common.HexToAddress(`0x7f39C581F595B53c5cb19bD0b3f8dA6c935E2Ca0`),
+			Method:   RATE_PROVIDER_STETH_PER_TOKEN,
+		},
+		{
+			Token:    common.HexToAddress(`0xae78736Cd615f374D3085123A210448E74Fc6393`), // rETH
+			Provider: common.HexToAddress(`0xae78736Cd615f374D3085123A210448E74Fc6393`),
+			Method:   RATE_PROVIDER_GET_EXCHANGE_RATE,
+		},
+		{
+			Token:    common.HexToAddress(`0xBe9895146f7AF43049ca1c1AE358B0541Ea49704`), // cbETH
+			Provider: common.HexToAddress(`0xBe9895146f7AF43049ca1c1AE358B0541Ea49704`),
+			Method:   RATE_PROVIDER_EXCHANGE_RATE,
+		},
+		{
+			Token:    common.HexToAddress(`0xCd5fE23C85820F7B72D0926FC9b05b43E359b7ee`), // weETH
+			Provider: common.HexToAddress(`0xCd5fE23C85820F7B72D0926FC9b05b43E359b7ee`),
+			Method:   RATE_PROVIDER_GET_RATE,
+		},
+	},
 	Coin: models.TERC20Token{
 		Address:                   DEFAULT_COIN_ADDRESS,
 		UnderlyingTokensAddresses: []common.Address{},
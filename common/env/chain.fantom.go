@@ -10,14 +10,24 @@ import (
 )
 
 var FANTOM = TChain{
-	ID:              250,
-	RpcURI:          `https://rpc.ftm.tools`,
-	SubgraphURI:     ``,
-	EtherscanURI:    `https://api.etherscan.io/v2/api`,
-	MaxBlockRange:   100_000_000,
-	MaxBatchSize:    math.MaxInt64,
-	AvgBlocksPerDay: 45_000,
-	CanUseWebsocket: true,
+	ID:                 250,
+	RpcURI:             `https://rpc.ftm.tools`,
+	SubgraphURI:        ``,
+	EtherscanURI:       `https://api.etherscan.io/v2/api`,
+	MaxBlockRange:      100_000_000,
+	MaxBatchSize:       math.MaxInt64,
+	AvgBlocksPerDay:    45_000,
+	ConfirmationBlocks: 10,
+	CanUseWebsocket:    true,
+	IsSunset:           true,
+	Capabilities: TChainCapabilities{
+		SupportsLogsAddressArray: true,
+		HasMulticall:             true,
+		SupportsTraces:           false,
+	},
+	APRPolicy: TChainAPRPolicy{
+		ShouldUseV2APR: false,
+	},
 	LensContract: TContractData{
 		Address: common.HexToAddress(`0x57AA88A0810dfe3f9b71a9b179Dd8bF5F956C46A`),
 		Block:   17091856,
@@ -10,15 +10,26 @@ import (
 )
 
 var GNOSIS = TChain{
-	ID:              100,
-	RpcURI:          `https://rpc.gnosis.gateway.fm`,
-	SubgraphURI:     ``,
-	EtherscanURI:    `https://api.etherscan.io/v2/api`,
-	MaxBlockRange:   9_000,
-	MaxBatchSize:    math.MaxInt64,
-	AvgBlocksPerDay: 16_000,
-	CanUseWebsocket: false,
-	LensContract:    TContractData{},
+	ID:                 100,
+	RpcURI:             `https://rpc.gnosis.gateway.fm`,
+	SubgraphURI:        ``,
+	EtherscanURI:       `https://api.etherscan.io/v2/api`,
+	MaxBlockRange:      9_000,
+	MaxBatchSize:       math.MaxInt64,
+	AvgBlocksPerDay:    16_000,
+	ConfirmationBlocks: 20,
+	CanUseWebsocket:    false,
+	Capabilities: TChainCapabilities{
+		SupportsLogsAddressArray: true,
+		HasMulticall:             true,
+		SupportsTraces:           false,
+		SkipsRegistryIndexing:    true,
+		ComputesRetiredVaultsAPY: true,
+	},
+	APRPolicy: TChainAPRPolicy{
+		ShouldUseV2APR: false,
+	},
+	LensContract: TContractData{},
 	MulticallContract: TContractData{
 		Address: common.HexToAddress(`0xca11bde05977b3631167028862be2a173976ca11`),
 		Block:   821923,
@@ -10,15 +10,26 @@ import (
 )
 
 var KATANA = TChain{
-	ID:              747474,
-	RpcURI:          `https://rpc.katana.network`,
-	SubgraphURI:     ``,
-	EtherscanURI:    `https://api.etherscan.io/v2/api`,
-	MaxBlockRange:   100_000_000,
-	MaxBatchSize:    math.MaxInt64,
-	AvgBlocksPerDay: 86_400,
-	CanUseWebsocket: true, //CHECK!
-	LensContract:    TContractData{},
+	ID:                 747474,
+	RpcURI:             `https://rpc.katana.network`,
+	SubgraphURI:        ``,
+	EtherscanURI:       `https://api.etherscan.io/v2/api`,
+	MaxBlockRange:      100_000_000,
+	MaxBatchSize:       math.MaxInt64,
+	AvgBlocksPerDay:    86_400,
+	ConfirmationBlocks: 120,
+	CanUseWebsocket:    true, //CHECK!
+	Capabilities: TChainCapabilities{
+		SupportsLogsAddressArray: false,
+		MaxLogsRange:             10_000,
+		HasMulticall:             true,
+		SupportsTraces:           false,
+		PricesTokensAsMainnet:    true,
+	},
+	APRPolicy: TChainAPRPolicy{
+		ShouldUseV2APR: false,
+	},
+	LensContract: TContractData{},
 	// // this one is multicall1
 	// MulticallContract: TContractData{
 	// 	Address: common.HexToAddress(`0x1F4c1E0afBeb5b5B86d7722549274434b29884F6`),
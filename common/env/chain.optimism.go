@@ -10,14 +10,32 @@ import (
 )
 
 var OPTIMISM = TChain{
-	ID:              10,
-	RpcURI:          `https://mainnet.optimism.io`,
-	SubgraphURI:     `https://api.thegraph.com/subgraphs/name/yearn/yearn-vaults-v2-optimism`,
-	EtherscanURI:    `https://api.etherscan.io/v2/api`,
-	MaxBlockRange:   100_000_000,
-	MaxBatchSize:    math.MaxInt64,
-	AvgBlocksPerDay: 43_200,
-	CanUseWebsocket: true,
+	ID:                 10,
+	RpcURI:             `https://mainnet.optimism.io`,
+	SubgraphURI:        `https://api.thegraph.com/subgraphs/name/yearn/yearn-vaults-v2-optimism`,
+	EtherscanURI:       `https://api.etherscan.io/v2/api`,
+	MaxBlockRange:      100_000_000,
+	MaxBatchSize:       math.MaxInt64,
+	AvgBlocksPerDay:    43_200,
+	ConfirmationBlocks: 120,
+	CanUseWebsocket:    true,
+	Capabilities: TChainCapabilities{
+		SupportsLogsAddressArray: true,
+		HasMulticall:             true,
+		SupportsTraces:           false,
+	},
+	APRPolicy: TChainAPRPolicy{
+		ShouldUseV2APR: false,
+	},
+	GasPolicy: TChainGasPolicy{
+		WrappedCoin:         common.HexToAddress(`0x4200000000000000000000000000000000000006`),
+		HarvestGasUnits:     1_000_000,
+		HarvestsPerYear:     52,
+		L1FeeOracle:         L1_FEE_ORACLE_OP_STACK,
+		L1FeeOracleContract: common.HexToAddress(`0x420000000000000000000000000000000000000F`),
+		L1DataBytes:         600,
+		TVLThresholdUSD:     1_000_000,
+	},
 	LensContract: TContractData{
 		Address: common.HexToAddress(`0xB082d9f4734c535D9d80536F7E87a6f4F471bF65`),
 		Block:   18109291,
@@ -26,6 +44,8 @@ var OPTIMISM = TChain{
 		Address: common.HexToAddress(`0xca11bde05977b3631167028862be2a173976ca11`),
 		Block:   4286263,
 	},
+	SequencerUptimeFeed:  common.HexToAddress(`0x371EAD81c9102C9BF4874A9075FFFf170F2Ee389`),
+	UniV3PositionManager: common.HexToAddress(`0xC36442b4a4522E871399CD717aBDD847Ab11FE88`),
 	StakingRewardRegistry: []TContractData{
 		{
 			Address: common.HexToAddress(`0x8ED9F6343f057870F1DeF47AaE7CD88dfAA049A8`),
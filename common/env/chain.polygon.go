@@ -10,15 +10,24 @@ import (
 )
 
 var POLYGON = TChain{
-	ID:              137,
-	RpcURI:          `https://polygon.llamarpc.com`,
-	SubgraphURI:     ``, //TODO: not deployed
-	EtherscanURI:    `https://api.etherscan.io/v2/api`,
-	MaxBlockRange:   100_000_000,
-	MaxBatchSize:    math.MaxInt64,
-	AvgBlocksPerDay: 40_000,
-	CanUseWebsocket: true,
-	LensContract:    TContractData{}, //TODO: not deployed
+	ID:                 137,
+	RpcURI:             `https://polygon.llamarpc.com`,
+	SubgraphURI:        ``, //TODO: not deployed
+	EtherscanURI:       `https://api.etherscan.io/v2/api`,
+	MaxBlockRange:      100_000_000,
+	MaxBatchSize:       math.MaxInt64,
+	AvgBlocksPerDay:    40_000,
+	ConfirmationBlocks: 128,
+	CanUseWebsocket:    true,
+	Capabilities: TChainCapabilities{
+		SupportsLogsAddressArray: true,
+		HasMulticall:             true,
+		SupportsTraces:           false,
+	},
+	APRPolicy: TChainAPRPolicy{
+		ShouldUseV2APR: false,
+	},
+	LensContract: TContractData{}, //TODO: not deployed
 	MulticallContract: TContractData{
 		Address: common.HexToAddress(`0xca11bde05977b3631167028862be2a173976ca11`),
 		Block:   25770160,
@@ -27,6 +36,10 @@ var POLYGON = TChain{
 		Address: common.HexToAddress(`0x1981AD9F44F2EA9aDd2dC4AD7D075c102C70aF92`),
 		Block:   52516525,
 	},
+	UniV3PositionManager: common.HexToAddress(`0xC36442b4a4522E871399CD717aBDD847Ab11FE88`),
+	V3RouterContract: TContractData{
+		Address: common.HexToAddress(`0x1112dbCF805682e828606f74AB717abf4b4FD8DE`),
+	},
 	Coin: models.TERC20Token{
 		Address:                   DEFAULT_COIN_ADDRESS,
 		UnderlyingTokensAddresses: []common.Address{},
@@ -0,0 +1,67 @@
+package env
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** POLYGON_ZKEVM has no APR oracle, its v3 vaults getting the debt ratio APR of their strategies,
+** and no registry: its vaults are listed in ExtraVaults. The chain is registered for the API but
+** is opt-in for the indexing, being out of the default --chains: it is indexed with
+** --chains ...,1101.
+**************************************************************************************************/
+var POLYGON_ZKEVM = TChain{
+	ID:                 1101,
+	RpcURI:             `https://zkevm-rpc.com`,
+	SubgraphURI:        ``,
+	EtherscanURI:       `https://api.etherscan.io/v2/api`,
+	MaxBlockRange:      9_000,
+	MaxBatchSize:       math.MaxInt64,
+	AvgBlocksPerDay:    25_000,
+	ConfirmationBlocks: 20,
+	CanUseWebsocket:    false,
+	Capabilities: TChainCapabilities{
+		SupportsLogsAddressArray: true,
+		MaxLogsRange:             10_000,
+		HasMulticall:             true,
+		SupportsTraces:           false,
+	},
+	APRPolicy: TChainAPRPolicy{
+		ShouldUseV2APR: false,
+	},
+	LensContract: TContractData{},
+	MulticallContract: TContractData{
+		Address: common.HexToAddress(`0xca11bde05977b3631167028862be2a173976ca11`),
+		Block:   57746,
+	},
+	PartnerContract:   TContractData{},
+	APROracleContract: TContractData{},
+	APRFallbackLens:   TContractData{}, // Not deployed yet: the strategies get their lending market APRs
+	Coin: models.TERC20Token{
+		Address:                   DEFAULT_COIN_ADDRESS,
+		UnderlyingTokensAddresses: []common.Address{},
+		Type:                      models.TokenTypeNative,
+		Name:                      `Ether`,
+		Symbol:                    `ETH`,
+		DisplayName:               `Ether`,
+		DisplaySymbol:             `ETH`,
+		Description:               `Ether is the native coin of Polygon zkEVM`,
+		Icon:                      BASE_ASSET_URL + strconv.FormatUint(1101, 10) + `/` + strings.ToLower(DEFAULT_COIN_ADDRESS.Hex()) + `/logo-128.png`,
+		Decimals:                  18,
+		ChainID:                   1101,
+	},
+	Registries:            []TContractData{}, // The vaults are added with the ExtraVaults until a registry is deployed
+	StakingRewardRegistry: []TContractData{},
+	ExtraStakingContracts: []TExtraStakingContracts{},
+	ExtraVaults:           []models.TVaultsFromRegistry{},
+	BlacklistedVaults:     []common.Address{},
+	ExtraTokens:           []common.Address{},
+	IgnoredTokens:         []common.Address{},
+	Curve:                 TChainCurve{},
+	ExtraURI:              TChainExtraURI{},
+}
@@ -10,15 +10,24 @@ import (
 )
 
 var SONIC = TChain{
-	ID:              146,
-	RpcURI:          `https://sonic.drpc.org`,
-	SubgraphURI:     ``,
-	EtherscanURI:    `https://api.etherscan.io/v2/api`,
-	MaxBlockRange:   100_000_000,
-	MaxBatchSize:    math.MaxInt64,
-	AvgBlocksPerDay: 45_000,
-	CanUseWebsocket: true,
-	LensContract:    TContractData{},
+	ID:                 146,
+	RpcURI:             `https://sonic.drpc.org`,
+	SubgraphURI:        ``,
+	EtherscanURI:       `https://api.etherscan.io/v2/api`,
+	MaxBlockRange:      100_000_000,
+	MaxBatchSize:       math.MaxInt64,
+	AvgBlocksPerDay:    45_000,
+	ConfirmationBlocks: 10,
+	CanUseWebsocket:    true,
+	Capabilities: TChainCapabilities{
+		SupportsLogsAddressArray: true,
+		HasMulticall:             true,
+		SupportsTraces:           false,
+	},
+	APRPolicy: TChainAPRPolicy{
+		ShouldUseV2APR: false,
+	},
+	LensContract: TContractData{},
 	MulticallContract: TContractData{
 		Address: common.HexToAddress(`0xca11bde05977b3631167028862be2a173976ca11`),
 		Block:   60,
@@ -1,6 +1,9 @@
 package env
 
 import (
+	"sync"
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/yearn/ydaemon/internal/models"
 )
@@ -20,6 +23,92 @@ type TExtraStakingContracts struct {
 	Tag            string
 }
 
+/**************************************************************************************************
+** TExternalVaultFee registers the entry and exit fees charged by an external vault in which a
+** strategy deposits its funds (e.g. some ERC4626 wrappers). These fees are not visible in the
+** Yearn vault itself but reduce the yield of its depositors.
+**
+** @field StrategyAddress The address of the Yearn strategy depositing in the external vault
+** @field ExternalVault The address of the external ERC4626 vault. If set, the fees are also read
+** on-chain and the highest value between the config and the chain is used.
+** @field EntryFeeBps The entry fee, in basis points
+** @field ExitFeeBps The exit fee, in basis points
+**************************************************************************************************/
+type TExternalVaultFee struct {
+	StrategyAddress common.Address
+	ExternalVault   common.Address
+	EntryFeeBps     uint64
+	ExitFeeBps      uint64
+}
+
+/**************************************************************************************************
+** Lending protocols supported by the lending market APR sources.
+**************************************************************************************************/
+const (
+	LENDING_PROTOCOL_AAVE_V3     = `aave-v3`
+	LENDING_PROTOCOL_MORPHO_BLUE = `morpho-blue`
+	LENDING_PROTOCOL_EULER_V2    = `euler-v2`
+	LENDING_PROTOCOL_LLAMALEND   = `llamalend`
+)
+
+/**************************************************************************************************
+** TLendingMarket registers the lending market in which a strategy supplies its funds. The supply
+** APY is computed from the rate model of the protocol and used as a cross-check of, and a fallback
+** for, the APR oracle.
+**
+** @field StrategyAddress The address of the Yearn strategy (or tokenized strategy) lending
+** @field Protocol One of the LENDING_PROTOCOL_* values
+** @field Market The Aave v3 pool data provider, the Morpho Blue contract, the Euler v2 vault or
+** the LlamaLend vault
+** @field MarketID The ID of the Morpho Blue market, unused for the other protocols
+** @field Asset The asset supplied to the Aave v3 pool, unused for the other protocols
+** @field Gauge The Curve gauge in which the LlamaLend vault shares are staked, if any
+**************************************************************************************************/
+type TLendingMarket struct {
+	StrategyAddress common.Address
+	Protocol        string
+	Market          common.Address
+	MarketID        common.Hash
+	Asset           common.Address
+	Gauge           common.Address
+}
+
+/**************************************************************************************************
+** The methods of the rate providers returning the exchange rate of a yield-bearing token, scaled
+** by 1e18. The ERC4626 vaults are read with `convertToAssets` of one share.
+**************************************************************************************************/
+const (
+	RATE_PROVIDER_ERC4626           = `convertToAssets`
+	RATE_PROVIDER_GET_RATE          = `getRate`
+	RATE_PROVIDER_STETH_PER_TOKEN   = `stEthPerToken`
+	RATE_PROVIDER_GET_EXCHANGE_RATE = `getExchangeRate`
+	RATE_PROVIDER_EXCHANGE_RATE     = `exchangeRate`
+)
+
+/**************************************************************************************************
+** TRateProvider registers the canonical rate provider of a yield-bearing token (sDAI, sUSDe,
+** wstETH, ...). The growth of its rate is the intrinsic APR of the vaults using the token as asset,
+** earned on top of the yield of their strategies.
+**
+** @field Token The yield-bearing token
+** @field Provider The contract returning the rate, often the token itself
+** @field Method One of the RATE_PROVIDER_* values
+**************************************************************************************************/
+type TRateProvider struct {
+	Token    common.Address
+	Provider common.Address
+	Method   string
+}
+
+/**************************************************************************************************
+** TKeeper is a known keeper of the Yearn strategies of a chain, with the network it belongs to
+** (e.g. `keep3r`, `gelato`, `yHaaS`). The keepers not listed here are classified on-chain.
+**************************************************************************************************/
+type TKeeper struct {
+	Address common.Address
+	Type    string
+}
+
 /**************************************************************************************************
 ** TChainCurve contains Curve protocol specific addresses and endpoints for a particular chain.
 ** Curve is a major DeFi protocol that Yearn integrates with, requiring specific configuration.
@@ -50,6 +139,91 @@ type TChainExtraURI struct {
 	PendleCoreURI      string
 }
 
+/**************************************************************************************************
+** TChainAPRPolicy holds the defaults deciding which APR is the primary one for the v3 vaults of a
+** chain: the oracle APR of the vault, or the APR of the strategies weighted by their debt ratio
+** (the "v2" APR). The resolution goes from the most specific to the least specific level:
+** vault metadata → category → chain.
+** It also holds the expected holding period of the depositors of the chain, over which the entry
+** and exit fees of the external vaults are amortized.
+**************************************************************************************************/
+type TChainAPRPolicy struct {
+	ShouldUseV2APR             bool                               // Default for the vaults of the chain
+	ShouldUseV2APRByCategory   map[models.TVaultCategoryType]bool // Override per vault category
+	EntryExitFeesHoldingPeriod time.Duration                      // One year if 0
+}
+
+/**************************************************************************************************
+** TChainGasPolicy describes the cost of a harvest on a chain, used to estimate the APY net of the
+** harvest costs of the small vaults, for which the gas meaningfully reduces the realized yield.
+** An empty policy (HarvestGasUnits of 0) disables the estimation for the chain.
+**
+** @field WrappedCoin The wrapped gas token, priced in place of the native coin
+** @field HarvestGasUnits The execution gas used by the harvest of a strategy
+** @field HarvestsPerYear The expected number of harvests of a strategy per year
+** @field L1FeeOracle The L1 data fee model of the chain, for the rollups (L1_FEE_ORACLE_*)
+** @field L1FeeOracleContract The contract returning the L1 data fee
+** @field L1DataBytes The size of the calldata of a harvest transaction posted to L1
+** @field TVLThresholdUSD The TVL under which the net of gas APY is computed
+**************************************************************************************************/
+type TChainGasPolicy struct {
+	WrappedCoin         common.Address
+	HarvestGasUnits     uint64
+	HarvestsPerYear     uint64
+	L1FeeOracle         string
+	L1FeeOracleContract common.Address
+	L1DataBytes         uint64
+	TVLThresholdUSD     float64
+}
+
+const (
+	L1_FEE_ORACLE_NONE     = ``
+	L1_FEE_ORACLE_OP_STACK = `opStack`
+	L1_FEE_ORACLE_ARBITRUM = `arbitrum`
+)
+
+/**************************************************************************************************
+** TChainTreasury lists the treasury of Yearn on a chain, indexed for the transparency dashboards.
+** An empty Addresses disables the treasury indexing for the chain.
+**
+** @field Addresses The addresses holding the treasury funds and receiving the vault fees
+** @field BuybackContracts The contracts buying YFI and sending it to the treasury
+** @field BuybackToken The token bought back, YFI
+** @field StartBlock The first block scanned for the fee inflows and the buybacks
+**************************************************************************************************/
+type TChainTreasury struct {
+	Addresses        []common.Address
+	BuybackContracts []common.Address
+	BuybackToken     common.Address
+	StartBlock       uint64
+}
+
+/**************************************************************************************************
+** TChainCapabilities describes what the RPC and the deployment of a chain support. The indexers,
+** the price fetchers and the APR computation consult it to select a compatible code path, instead
+** of special-casing chain IDs when a chain with quirks is onboarded.
+**
+** @field SupportsLogsAddressArray Whether eth_getLogs accepts an array of addresses
+** @field MaxLogsRange The maximum block range accepted by eth_getLogs, 0 for no extra limit
+** @field HasMulticall Whether a Multicall3 contract is deployed and callable
+** @field SupportsTraces Whether the trace and debug namespaces are available, for the calls leaving
+** no event behind (trace_filter)
+** @field SkipsRegistryIndexing Whether the registries are not scanned, the vaults of the chain
+** being the ones already stored
+** @field ComputesRetiredVaultsAPY Whether the APY of the retired vaults is still computed
+** @field PricesTokensAsMainnet Whether DefiLlama misses the tokens of the chain, the ones bridged
+** from Ethereum being priced as their mainnet counterpart
+**************************************************************************************************/
+type TChainCapabilities struct {
+	SupportsLogsAddressArray bool
+	MaxLogsRange             uint64
+	HasMulticall             bool
+	SupportsTraces           bool
+	SkipsRegistryIndexing    bool
+	ComputesRetiredVaultsAPY bool
+	PricesTokensAsMainnet    bool
+}
+
 /**************************************************************************************************
 ** TChain is the primary configuration structure for a blockchain network supported by yDaemon.
 ** It contains all the necessary information to interact with a specific chain, including:
@@ -69,17 +243,34 @@ type TChain struct {
 	MaxBlockRange         uint64
 	MaxBatchSize          uint64
 	AvgBlocksPerDay       int
+	ConfirmationBlocks    uint64 // Number of confirmations before an event is considered final
 	CanUseWebsocket       bool
+	Capabilities          TChainCapabilities
+	APRPolicy             TChainAPRPolicy
+	GasPolicy             TChainGasPolicy
+	Treasury              TChainTreasury
 	LensContract          TContractData
 	MulticallContract     TContractData
 	YBribeV3Contract      TContractData
 	PartnerContract       TContractData
+	V3RouterContract      TContractData // Yearn 4626 router, pulling the underlying tokens on the v3 vault deposits
 	APROracleContract     TContractData
+	APRFallbackLens       TContractData // Strategy APR lens with the getStrategyApr interface of the oracle, for the chains without APR oracle
+	ReportTriggerContract TContractData
+	SequencerUptimeFeed   common.Address // Chainlink L2 sequencer uptime feed, zero on the chains without sequencer
+	AaveV3DataProvider    common.Address // Aave v3 pool data provider, the strategies holding its aTokens get the Aave v3 lending market APR
+	LlamaLendFactory      common.Address // Curve LlamaLend factory, the strategies holding the shares of its vaults get the LlamaLend market APR
+	UniV3PositionManager  common.Address // Uniswap v3 position manager, the strategies holding its positions get the fees APR of their ranges
+	IsSunset              bool           // Legacy chain kept queryable for the withdrawals: hourly refreshes and no event indexing
 	Coin                  models.TERC20Token
 	StakingRewardRegistry []TContractData
 	Registries            []TContractData
 	YearnXRegistries      []TContractData
 	ExtraStakingContracts []TExtraStakingContracts
+	ExternalVaultFees     []TExternalVaultFee
+	Keepers               []TKeeper
+	LendingMarkets        []TLendingMarket
+	RateProviders         []TRateProvider
 	ExtraVaults           []models.TVaultsFromRegistry
 	BlacklistedVaults     []common.Address
 	ExtraTokens           []common.Address
@@ -98,8 +289,11 @@ var DEFAULT_COIN_ADDRESS = common.HexToAddress(`0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEe
 /**************************************************************************************************
 ** CHAINS is a global map that stores configuration for all supported blockchain networks.
 ** The map is indexed by chain ID (uint64) for easy lookup of specific chain configurations.
+** Once published, the map is never modified: a reload of the configuration builds a new one and
+** swaps it under chainsMtx (see setChains), so it must be read through GetChain or GetChains.
 **************************************************************************************************/
 var CHAINS = map[uint64]TChain{}
+var chainsMtx sync.RWMutex
 
 /**************************************************************************************************
 ** SUPPORTED_CHAIN_IDS contains a list of all chain IDs supported by yDaemon.
@@ -115,9 +309,35 @@ var SUPPORTED_CHAIN_IDS = []uint64{}
 ** @return map[uint64]TChain A map of chain IDs to their respective configurations
 **************************************************************************************************/
 func GetChains() map[uint64]TChain {
+	chainsMtx.RLock()
+	defer chainsMtx.RUnlock()
 	return CHAINS
 }
 
+/**************************************************************************************************
+** copyChains returns a copy of the current chain configurations, to be changed then published
+** with setChains.
+**************************************************************************************************/
+func copyChains() map[uint64]TChain {
+	chainsMtx.RLock()
+	defer chainsMtx.RUnlock()
+	chains := make(map[uint64]TChain, len(CHAINS))
+	for chainID, chain := range CHAINS {
+		chains[chainID] = chain
+	}
+	return chains
+}
+
+/**************************************************************************************************
+** setChains publishes new chain configurations, the readers of the previous map keeping an
+** unchanged copy.
+**************************************************************************************************/
+func setChains(chains map[uint64]TChain) {
+	chainsMtx.Lock()
+	defer chainsMtx.Unlock()
+	CHAINS = chains
+}
+
 /**************************************************************************************************
 ** GetChain retrieves the configuration for a specific chain by its ID.
 ** This is the preferred method to access chain configuration as it handles the case
@@ -128,6 +348,29 @@ func GetChains() map[uint64]TChain {
 ** @return bool True if the chain is supported, false otherwise
 **************************************************************************************************/
 func GetChain(chainID uint64) (TChain, bool) {
-	chain, ok := CHAINS[chainID]
+	chain, ok := GetChains()[chainID]
 	return chain, ok
 }
+
+/**************************************************************************************************
+** IsSunsetChain returns true if a chain is in sunset mode: a legacy chain only kept queryable for
+** the withdrawals, refreshed hourly and without event indexing, its vaults flagged with
+** deprecatedChain.
+**************************************************************************************************/
+func IsSunsetChain(chainID uint64) bool {
+	chain, ok := GetChains()[chainID]
+	return ok && chain.IsSunset
+}
+
+/**************************************************************************************************
+** GetLogsRange returns the maximum number of blocks that can be scanned in a single eth_getLogs
+** call for this chain, taking into account both the configured block range and the RPC limit.
+**
+** @return uint64 The maximum block range to use for the event filters
+**************************************************************************************************/
+func (chain TChain) GetLogsRange() uint64 {
+	if chain.Capabilities.MaxLogsRange > 0 && chain.Capabilities.MaxLogsRange < chain.MaxBlockRange {
+		return chain.Capabilities.MaxLogsRange
+	}
+	return chain.MaxBlockRange
+}
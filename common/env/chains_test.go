@@ -202,3 +202,108 @@ func TestSupportedChainIDs(t *testing.T) {
 		}
 	}
 }
+
+/**************************************************************************************************
+** TestPolygonZkEVMRegistered tests that Polygon zkEVM is a supported chain, without APR oracle
+** and with a multicall, so its v3 vaults get the debt ratio APR of their strategies.
+**************************************************************************************************/
+func TestPolygonZkEVMRegistered(t *testing.T) {
+	chain, exists := GetChain(1101)
+	if !exists {
+		t.Fatal("Polygon zkEVM (1101) should be registered")
+	}
+	if chain.ID != 1101 || chain.RpcURI == `` {
+		t.Errorf("Polygon zkEVM should have its ID and a default RPC, got %d and %q", chain.ID, chain.RpcURI)
+	}
+	if (chain.APROracleContract.Address != common.Address{}) {
+		t.Errorf("Polygon zkEVM has no APR oracle, got %s", chain.APROracleContract.Address.Hex())
+	}
+	if !chain.Capabilities.HasMulticall || (chain.MulticallContract.Address == common.Address{}) {
+		t.Error("Polygon zkEVM should have a multicall")
+	}
+	found := false
+	for _, chainID := range SUPPORTED_CHAIN_IDS {
+		found = found || chainID == 1101
+	}
+	if !found {
+		t.Error("Polygon zkEVM should be in SUPPORTED_CHAIN_IDS")
+	}
+}
+
+/**************************************************************************************************
+** TestGetLogsRange tests the GetLogsRange method to ensure the RPC limit from the capability
+** matrix is honored. This test validates:
+** - The configured block range is used when the RPC has no limit
+** - The RPC limit is used when it is lower than the configured block range
+** - The configured block range is used when it is lower than the RPC limit
+**************************************************************************************************/
+func TestGetLogsRange(t *testing.T) {
+	chain := TChain{MaxBlockRange: 100_000}
+	if logsRange := chain.GetLogsRange(); logsRange != 100_000 {
+		t.Errorf("Expected 100000, got %d", logsRange)
+	}
+
+	chain.Capabilities.MaxLogsRange = 10_000
+	if logsRange := chain.GetLogsRange(); logsRange != 10_000 {
+		t.Errorf("Expected 10000, got %d", logsRange)
+	}
+
+	chain.Capabilities.MaxLogsRange = 1_000_000
+	if logsRange := chain.GetLogsRange(); logsRange != 100_000 {
+		t.Errorf("Expected 100000, got %d", logsRange)
+	}
+}
+
+/**************************************************************************************************
+** TestGasPolicies tests that the gas policy of every chain with one is complete, for the net of
+** gas APY to be computed: a gas token to price, a harvest frequency, a TVL threshold and, for the
+** rollups, the contract and the calldata size of the L1 data fee.
+**************************************************************************************************/
+func TestGasPolicies(t *testing.T) {
+	for chainID, chain := range GetChains() {
+		policy := chain.GasPolicy
+		if policy.HarvestGasUnits == 0 {
+			continue
+		}
+		if (policy.WrappedCoin == common.Address{}) {
+			t.Errorf("Chain %d: missing the wrapped gas token", chainID)
+		}
+		if policy.HarvestsPerYear == 0 || policy.TVLThresholdUSD <= 0 {
+			t.Errorf("Chain %d: missing the harvest frequency or the TVL threshold", chainID)
+		}
+		if policy.L1FeeOracle != L1_FEE_ORACLE_NONE {
+			if (policy.L1FeeOracleContract == common.Address{}) || policy.L1DataBytes == 0 {
+				t.Errorf("Chain %d: missing the L1 fee oracle contract or the calldata size", chainID)
+			}
+		}
+	}
+}
+
+/**************************************************************************************************
+** TestChainQuirkCapabilities tests that the quirks of Gnosis and Katana are declared as capabilities
+** of their config, and that no other chain inherits them.
+**************************************************************************************************/
+func TestChainQuirkCapabilities(t *testing.T) {
+	tests := []struct {
+		chain                    TChain
+		skipsRegistryIndexing    bool
+		computesRetiredVaultsAPY bool
+		pricesTokensAsMainnet    bool
+	}{
+		{chain: ETHEREUM},
+		{chain: GNOSIS, skipsRegistryIndexing: true, computesRetiredVaultsAPY: true},
+		{chain: KATANA, pricesTokensAsMainnet: true},
+	}
+	for _, tt := range tests {
+		capabilities := tt.chain.Capabilities
+		if capabilities.SkipsRegistryIndexing != tt.skipsRegistryIndexing {
+			t.Errorf("chain %d: SkipsRegistryIndexing mismatch, got %v", tt.chain.ID, capabilities.SkipsRegistryIndexing)
+		}
+		if capabilities.ComputesRetiredVaultsAPY != tt.computesRetiredVaultsAPY {
+			t.Errorf("chain %d: ComputesRetiredVaultsAPY mismatch, got %v", tt.chain.ID, capabilities.ComputesRetiredVaultsAPY)
+		}
+		if capabilities.PricesTokensAsMainnet != tt.pricesTokensAsMainnet {
+			t.Errorf("chain %d: PricesTokensAsMainnet mismatch, got %v", tt.chain.ID, capabilities.PricesTokensAsMainnet)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -66,6 +67,12 @@ var GECKO_PRICE_URL = `https://api.coingecko.com/api/v3/simple/token_price/`
 **************************************************************************************************/
 var LLAMA_PRICE_URL = `https://coins.llama.fi/prices/current/`
 
+/**************************************************************************************************
+** LLAMA_HISTORICAL_PRICE_URL contains the base URL for the prices of the DeFiLlama pricing API at
+** a past timestamp, followed by the timestamp and the coins.
+**************************************************************************************************/
+var LLAMA_HISTORICAL_PRICE_URL = `https://coins.llama.fi/prices/historical/`
+
 /**************************************************************************************************
 ** CG_DEMO_KEYS stores an array of CoinGecko API keys that can be used for API requests.
 ** Having multiple keys allows for distribution of requests to avoid rate limiting.
@@ -94,3 +101,183 @@ var RISK_CDN_URL = `https://risk.yearn.fi/cdn/`
 ** and strategy discovery. Defaults to https://kong.yearn.farm/api/gql
 **************************************************************************************************/
 var KONG_API_URL = `https://kong.yearn.farm/api/gql`
+
+/**************************************************************************************************
+** SIMULATION_API_URL is the endpoint of the transaction simulation API (Tenderly-compatible) used
+** to simulate the next `report()` of the strategies. The simulation is disabled when empty.
+** SIMULATION_API_KEY is sent as the `X-Access-Key` header of the simulation requests.
+**************************************************************************************************/
+var SIMULATION_API_URL = ``
+var SIMULATION_API_KEY = ``
+
+/**************************************************************************************************
+** STORAGE_BACKEND selects where the storage layer persists its data between restarts:
+** - `files` (default): one JSON file per element and chain in BASE_DATA_PATH/meta
+** - `memory`: nothing is persisted, the data is rebuilt from scratch at every start
+** - `bolt`: an embedded BoltDB key/value store, at STORAGE_BOLT_PATH
+** - `postgres`: a Postgres table, at STORAGE_POSTGRES_DSN, to query the data with SQL
+**************************************************************************************************/
+var STORAGE_BACKEND = `files`
+var STORAGE_BOLT_PATH = ``
+var STORAGE_POSTGRES_DSN = ``
+
+/**************************************************************************************************
+** ATTESTATION_PRIVATE_KEY is the hex private key of the operator signing the APY and the price of
+** the single vault and single price responses. The responses are not signed when empty.
+**************************************************************************************************/
+var ATTESTATION_PRIVATE_KEY = ``
+
+/**************************************************************************************************
+** SHUTDOWN_WEBHOOK_URL is the URL notified with a JSON POST when the daemon stops. No webhook is
+** called when empty.
+**************************************************************************************************/
+var SHUTDOWN_WEBHOOK_URL = ``
+
+/**************************************************************************************************
+** The alerts are sent immediately when their rule is critical, and batched in a digest per chain
+** sent every NOTIFICATION_DIGEST_INTERVAL otherwise (0 sends them all immediately). The severity
+** of a rule can be overridden with NOTIFICATION_SEVERITIES (`fee_change=critical,new_strategy=off`,
+** see internal/notifications). The alerts and the digests are sent on Telegram and, as JSON, to
+** NOTIFICATION_WEBHOOK_URL when set.
+**************************************************************************************************/
+var NOTIFICATION_DIGEST_INTERVAL = time.Hour
+var NOTIFICATION_SEVERITIES = map[string]string{}
+var NOTIFICATION_WEBHOOK_URL = ``
+
+/**************************************************************************************************
+** MEMPOOL_WATCH enables the watch of the pending transactions of the chains able to use websockets,
+** for the deposits and withdrawals of the vaults worth at least MEMPOOL_MIN_FLOW_USD.
+**************************************************************************************************/
+var MEMPOOL_WATCH = false
+var MEMPOOL_MIN_FLOW_USD = 250000.0
+
+/**************************************************************************************************
+** APR_SOURCES_DISABLED lists, by chain, the APR sources (see processes/apr) not used to compute the
+** forward APY of the vaults, the ones of the chain 0 being disabled on all the chains.
+**************************************************************************************************/
+var APR_SOURCES_DISABLED = map[uint64]map[string]bool{}
+
+/**************************************************************************************************
+** HEAD_REFRESH_BLOCKS enables the refresh of the price per share and the total assets of the
+** vaults every HEAD_REFRESH_BLOCKS new heads, on the chains able to use websockets. 0 disables it.
+**************************************************************************************************/
+var HEAD_REFRESH_BLOCKS uint64 = 0
+
+/**************************************************************************************************
+** RPC_DEAD_WINDOW is how long all the RPC endpoints of a chain must fail their health checks for
+** the chain to be degraded, its refreshes being stopped until one of them answers again.
+**************************************************************************************************/
+var RPC_DEAD_WINDOW = 15 * time.Minute
+
+/**************************************************************************************************
+** UNPRICED_ALERT_MIN_TVL_USD is the TVL above which a vault losing the price of its underlying
+** token triggers an alert.
+**************************************************************************************************/
+var UNPRICED_ALERT_MIN_TVL_USD = 100000.0
+
+/**************************************************************************************************
+** STALE_PRICE_MAX_AGE is how long the last known price of a token no source prices anymore is
+** carried over. Past it, the token has no price and the TVL of its vaults drops to zero.
+**************************************************************************************************/
+var STALE_PRICE_MAX_AGE = 72 * time.Hour
+
+/**************************************************************************************************
+** FORWARD_APY_USE_PENDING_FEES computes the forward APY of the vaults with pending fees, a config
+** applied by their accountant but not charged yet, from the pending fees instead of the current ones.
+**************************************************************************************************/
+var FORWARD_APY_USE_PENDING_FEES = false
+
+/**************************************************************************************************
+** APY_DIVERGENCE_FACTOR is the factor between the forward APY of a v3 vault and its APY realized
+** over the last 7 days above which the forward APY is flagged as diverging. 0 disables the check.
+**************************************************************************************************/
+var APY_DIVERGENCE_FACTOR = 3.0
+
+/**************************************************************************************************
+** COMPETITOR_SOURCES lists the external yield sources (`beefy`, `sommelier`) compared with the
+** vaults by the /compare route. The comparison is disabled when empty. The Sommelier source also
+** needs SOMMELIER_API_URL, the feed of its cellars.
+**************************************************************************************************/
+var COMPETITOR_SOURCES = []string{}
+var BEEFY_API_URL = `https://api.beefy.finance`
+var SOMMELIER_API_URL = ``
+
+/**************************************************************************************************
+** CLOUDFLARE_ZONE_ID and CLOUDFLARE_API_TOKEN enable the purge of the responses cached by the
+** Cloudflare zone serving CDN_PUBLIC_URL, every time the store version of a chain is bumped. The
+** token needs the Zone.Cache Purge permission. Nothing is purged when they are empty.
+**************************************************************************************************/
+var CLOUDFLARE_ZONE_ID = ``
+var CLOUDFLARE_API_TOKEN = ``
+var CDN_PUBLIC_URL = `https://ydaemon.yearn.fi`
+
+/**************************************************************************************************
+** IPFS_PINNING_JWT enables the pinning of the icons and of the metadata of the tokens to IPFS, via
+** the Pinata-compatible pinning service at IPFS_PINNING_API_URL. The pinned assets are served with
+** their ipfs:// URI and their URL on IPFS_GATEWAY_URL. Nothing is pinned when it is empty.
+**************************************************************************************************/
+var IPFS_PINNING_JWT = ``
+var IPFS_PINNING_API_URL = `https://api.pinata.cloud`
+var IPFS_GATEWAY_URL = `https://ipfs.io/ipfs/`
+
+/**************************************************************************************************
+** ON_DEMAND_INDEX_API_KEY restricts the on-demand indexing of the vaults to the requests with an
+** `Authorization: Bearer <key>` header. The on-demand indexing is disabled when it is empty.
+**************************************************************************************************/
+var ON_DEMAND_INDEX_API_KEY = ``
+
+/**************************************************************************************************
+** TRUSTED_PROXIES lists the IPs and CIDRs of the reverse proxies in front of the API, whose
+** X-Forwarded-For and X-Real-IP headers give the client IP the routes are rate limited by. When it
+** is empty, no proxy is trusted and the client IP is the address of the connection, the headers
+** being set by anyone.
+**************************************************************************************************/
+var TRUSTED_PROXIES = []string{}
+
+/**************************************************************************************************
+** BACKFILL_CONCURRENCY is the number of historical backfill requests (the first scans of the
+** events of the vaults, the inceptions read from the archive node) run at the same time on a
+** chain, the live refreshes keeping the rest of the capacity of its RPC. ADMIN_API_KEY enables the
+** admin routes pausing and resuming the backfills, for the requests with an
+** `Authorization: Bearer <key>` header. They are disabled when it is empty.
+**************************************************************************************************/
+var BACKFILL_CONCURRENCY = 2
+var ADMIN_API_KEY = ``
+
+/**************************************************************************************************
+** TIMESERIES_EXPORTER exports the APY, TVL and price per share of every vault, recorded at every
+** snapshot, to a time-series database for the long term analytics. Nothing is exported when it is
+** empty. Otherwise:
+** - `influxdb`: the points are written to the bucket TIMESERIES_INFLUXDB_BUCKET of the InfluxDB v2
+**   at TIMESERIES_INFLUXDB_URL, authenticated with TIMESERIES_INFLUXDB_TOKEN
+** - `timescaledb`: the points are inserted in the `ydaemon_vault_metrics` hypertable of the
+**   TimescaleDB at TIMESERIES_POSTGRES_DSN
+**************************************************************************************************/
+var TIMESERIES_EXPORTER = ``
+var TIMESERIES_INFLUXDB_URL = ``
+var TIMESERIES_INFLUXDB_TOKEN = ``
+var TIMESERIES_INFLUXDB_ORG = ``
+var TIMESERIES_INFLUXDB_BUCKET = `ydaemon`
+var TIMESERIES_POSTGRES_DSN = ``
+
+/**************************************************************************************************
+** GRPC_PORT serves the gRPC API of the vaults, tokens and prices on this port, for the internal
+** services. It is not served when empty. GRPC_API_KEY restricts it to the requests with an
+** `authorization: Bearer <key>` metadata.
+**************************************************************************************************/
+var GRPC_PORT = ``
+var GRPC_API_KEY = ``
+
+/**************************************************************************************************
+** RPC_FIXTURES_MODE records (`record`) or replays (`replay`) the calls to the nodes and to the
+** external APIs in RPC_FIXTURES_PATH, for the computations to be run offline against a recorded
+** state of the chains (see common/fixtures). The calls are sent as is when it is empty.
+**************************************************************************************************/
+var RPC_FIXTURES_MODE = ``
+var RPC_FIXTURES_PATH = ``
+
+/**************************************************************************************************
+** ABI_DIRECTORY is the directory of the ABIs loaded at startup, for their view functions to be
+** called without generated bindings (see common/abis).
+**************************************************************************************************/
+var ABI_DIRECTORY = filepath.Join(BASE_DATA_PATH, `abis`)
@@ -2,8 +2,10 @@ package env
 
 import (
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/yearn/ydaemon/common/logs"
@@ -21,26 +23,47 @@ import (
 ** requiring code changes or recompilation.
 **************************************************************************************************/
 func SetEnv() {
-	for _, chain := range CHAINS {
+	/**********************************************************************************************
+	** The chains are changed on a copy, published at once for the readers to never see a map
+	** being written
+	**********************************************************************************************/
+	chains := copyChains()
+	for _, chain := range chains {
 		baseKey := `RPC_URI_FOR_`
 		chainID := strconv.FormatUint(chain.ID, 10)
 		RPCURI, exists := os.LookupEnv(baseKey + chainID)
 		if !exists {
 			logs.Debug(baseKey + chainID + " not set, using default value")
 		} else {
-			chain := CHAINS[chain.ID]
 			chain.RpcURI = RPCURI
-			CHAINS[chain.ID] = chain
+			chains[chain.ID] = chain
 		}
 	}
 
+	/**********************************************************************************************
+	** Optional list of the chains in sunset mode, replacing the ones flagged in their config
+	**********************************************************************************************/
+	if sunsetChainIDs, exists := os.LookupEnv("SUNSET_CHAIN_IDS"); exists && sunsetChainIDs != `` {
+		isSunset := make(map[uint64]bool)
+		for _, chainIDStr := range strings.Split(sunsetChainIDs, ",") {
+			if chainID, err := strconv.ParseUint(strings.TrimSpace(chainIDStr), 10, 64); err == nil {
+				isSunset[chainID] = true
+			}
+		}
+		for chainID, chain := range chains {
+			chain.IsSunset = isSunset[chainID]
+			chains[chainID] = chain
+		}
+	}
+	setChains(chains)
+
 	/**********************************************************************************************
 	** Array of Coingecko keys to use
 	**********************************************************************************************/
 	allCGKeys, _ := os.LookupEnv("CG_DEMO_KEYS")
 	if allCGKeys != `` {
 		splittedKeys := strings.Split(allCGKeys, ",")
-		CG_DEMO_KEYS = append(CG_DEMO_KEYS, splittedKeys...)
+		CG_DEMO_KEYS = splittedKeys
 	}
 
 	/**********************************************************************************************
@@ -63,6 +86,309 @@ func SetEnv() {
 	if kongURL, exists := os.LookupEnv("KONG_API_URL"); exists {
 		KONG_API_URL = kongURL
 	}
+
+	/**********************************************************************************************
+	** Optional simulation API configuration, used to estimate the pending profit of strategies
+	**********************************************************************************************/
+	if simulationURL, exists := os.LookupEnv("SIMULATION_API_URL"); exists {
+		SIMULATION_API_URL = simulationURL
+	}
+	if simulationKey, exists := os.LookupEnv("SIMULATION_API_KEY"); exists {
+		SIMULATION_API_KEY = simulationKey
+	}
+
+	/**********************************************************************************************
+	** Storage backend configuration
+	**********************************************************************************************/
+	if storageBackend, exists := os.LookupEnv("STORAGE_BACKEND"); exists && storageBackend != `` {
+		STORAGE_BACKEND = storageBackend
+	}
+	if boltPath, exists := os.LookupEnv("STORAGE_BOLT_PATH"); exists {
+		STORAGE_BOLT_PATH = boltPath
+	}
+	if postgresDSN, exists := os.LookupEnv("STORAGE_POSTGRES_DSN"); exists {
+		STORAGE_POSTGRES_DSN = postgresDSN
+	}
+
+	/**********************************************************************************************
+	** Optional operator key signing the APY and price responses
+	**********************************************************************************************/
+	if attestationKey, exists := os.LookupEnv("ATTESTATION_PRIVATE_KEY"); exists {
+		ATTESTATION_PRIVATE_KEY = attestationKey
+	}
+
+	/**********************************************************************************************
+	** Optional webhook notified when the daemon stops
+	**********************************************************************************************/
+	if shutdownWebhook, exists := os.LookupEnv("SHUTDOWN_WEBHOOK_URL"); exists {
+		SHUTDOWN_WEBHOOK_URL = shutdownWebhook
+	}
+
+	/**********************************************************************************************
+	** Optional digest of the non-critical alerts, and severities of the alert rules
+	**********************************************************************************************/
+	if digestInterval, exists := os.LookupEnv("NOTIFICATION_DIGEST_INTERVAL"); exists && digestInterval != `` {
+		if interval, err := time.ParseDuration(digestInterval); err == nil && interval >= 0 {
+			NOTIFICATION_DIGEST_INTERVAL = interval
+		} else {
+			logs.Warning(`Invalid NOTIFICATION_DIGEST_INTERVAL ` + digestInterval + `, using ` + NOTIFICATION_DIGEST_INTERVAL.String())
+		}
+	}
+	if severities, exists := os.LookupEnv("NOTIFICATION_SEVERITIES"); exists {
+		NOTIFICATION_SEVERITIES = map[string]string{}
+		for _, ruleSeverity := range strings.Split(severities, ",") {
+			rule, severity, ok := strings.Cut(strings.TrimSpace(ruleSeverity), `=`)
+			if ok && rule != `` {
+				NOTIFICATION_SEVERITIES[strings.TrimSpace(rule)] = strings.ToLower(strings.TrimSpace(severity))
+			}
+		}
+	}
+	if notificationWebhook, exists := os.LookupEnv("NOTIFICATION_WEBHOOK_URL"); exists {
+		NOTIFICATION_WEBHOOK_URL = notificationWebhook
+	}
+	if onDemandIndexAPIKey, exists := os.LookupEnv("ON_DEMAND_INDEX_API_KEY"); exists {
+		ON_DEMAND_INDEX_API_KEY = onDemandIndexAPIKey
+	}
+	if trustedProxies, exists := os.LookupEnv("TRUSTED_PROXIES"); exists {
+		TRUSTED_PROXIES = []string{}
+		for _, proxy := range strings.Split(trustedProxies, ",") {
+			if proxy = strings.TrimSpace(proxy); proxy != `` {
+				TRUSTED_PROXIES = append(TRUSTED_PROXIES, proxy)
+			}
+		}
+	}
+
+	/**********************************************************************************************
+	** Optional throttling of the historical backfills, and key of the admin routes
+	**********************************************************************************************/
+	if backfillConcurrency, exists := os.LookupEnv("BACKFILL_CONCURRENCY"); exists {
+		if value, err := strconv.Atoi(backfillConcurrency); err == nil && value > 0 {
+			BACKFILL_CONCURRENCY = value
+		} else {
+			logs.Warning(`Invalid BACKFILL_CONCURRENCY ` + backfillConcurrency + `, using ` + strconv.Itoa(BACKFILL_CONCURRENCY))
+		}
+	}
+	if adminAPIKey, exists := os.LookupEnv("ADMIN_API_KEY"); exists {
+		ADMIN_API_KEY = adminAPIKey
+	}
+
+	/**********************************************************************************************
+	** Optional export of the vault metrics to a time-series database
+	**********************************************************************************************/
+	if timeseriesExporter, exists := os.LookupEnv("TIMESERIES_EXPORTER"); exists {
+		TIMESERIES_EXPORTER = timeseriesExporter
+	}
+	if influxURL, exists := os.LookupEnv("TIMESERIES_INFLUXDB_URL"); exists {
+		TIMESERIES_INFLUXDB_URL = influxURL
+	}
+	if influxToken, exists := os.LookupEnv("TIMESERIES_INFLUXDB_TOKEN"); exists {
+		TIMESERIES_INFLUXDB_TOKEN = influxToken
+	}
+	if influxOrg, exists := os.LookupEnv("TIMESERIES_INFLUXDB_ORG"); exists {
+		TIMESERIES_INFLUXDB_ORG = influxOrg
+	}
+	if influxBucket, exists := os.LookupEnv("TIMESERIES_INFLUXDB_BUCKET"); exists && influxBucket != `` {
+		TIMESERIES_INFLUXDB_BUCKET = influxBucket
+	}
+	if timescaleDSN, exists := os.LookupEnv("TIMESERIES_POSTGRES_DSN"); exists {
+		TIMESERIES_POSTGRES_DSN = timescaleDSN
+	}
+
+	/**********************************************************************************************
+	** Optional gRPC API for the internal services
+	**********************************************************************************************/
+	if grpcPort, exists := os.LookupEnv("GRPC_PORT"); exists {
+		GRPC_PORT = grpcPort
+	}
+	if grpcAPIKey, exists := os.LookupEnv("GRPC_API_KEY"); exists {
+		GRPC_API_KEY = grpcAPIKey
+	}
+
+	/**********************************************************************************************
+	** Optional watch of the large pending deposits and withdrawals
+	**********************************************************************************************/
+	if mempoolWatch, exists := os.LookupEnv("MEMPOOL_WATCH"); exists {
+		MEMPOOL_WATCH = mempoolWatch == `true`
+	}
+	if minFlowUSD, exists := os.LookupEnv("MEMPOOL_MIN_FLOW_USD"); exists {
+		if value, err := strconv.ParseFloat(minFlowUSD, 64); err == nil && value > 0 {
+			MEMPOOL_MIN_FLOW_USD = value
+		}
+	}
+
+	/**********************************************************************************************
+	** Optional list of the APR sources disabled by chain, as `1=pendle,gamma;*=velodrome`
+	**********************************************************************************************/
+	if disabledSources, exists := os.LookupEnv("APR_SOURCES_DISABLED"); exists {
+		APR_SOURCES_DISABLED = map[uint64]map[string]bool{}
+		for _, chainSources := range strings.Split(disabledSources, ";") {
+			rawChainID, sources, ok := strings.Cut(strings.TrimSpace(chainSources), `=`)
+			if !ok {
+				continue
+			}
+			chainID := uint64(0)
+			if rawChainID = strings.TrimSpace(rawChainID); rawChainID != `*` {
+				value, err := strconv.ParseUint(rawChainID, 10, 64)
+				if err != nil {
+					logs.Warning(`Invalid chain ` + rawChainID + ` in APR_SOURCES_DISABLED`)
+					continue
+				}
+				chainID = value
+			}
+			if APR_SOURCES_DISABLED[chainID] == nil {
+				APR_SOURCES_DISABLED[chainID] = map[string]bool{}
+			}
+			for _, source := range strings.Split(sources, ",") {
+				if source = strings.ToLower(strings.TrimSpace(source)); source != `` {
+					APR_SOURCES_DISABLED[chainID][source] = true
+				}
+			}
+		}
+	}
+
+	/**********************************************************************************************
+	** Optional refresh of the vaults on the new heads
+	**********************************************************************************************/
+	if headRefreshBlocks, exists := os.LookupEnv("HEAD_REFRESH_BLOCKS"); exists {
+		if value, err := strconv.ParseUint(headRefreshBlocks, 10, 64); err == nil {
+			HEAD_REFRESH_BLOCKS = value
+		}
+	}
+
+	/**********************************************************************************************
+	** Optional window of failed health checks after which a chain is degraded
+	**********************************************************************************************/
+	if deadWindow, exists := os.LookupEnv("RPC_DEAD_WINDOW"); exists && deadWindow != `` {
+		if window, err := time.ParseDuration(deadWindow); err == nil && window > 0 {
+			RPC_DEAD_WINDOW = window
+		} else {
+			logs.Warning(`Invalid RPC_DEAD_WINDOW ` + deadWindow + `, using ` + RPC_DEAD_WINDOW.String())
+		}
+	}
+
+	/**********************************************************************************************
+	** Optional threshold of the alerts on the vaults losing their price
+	**********************************************************************************************/
+	if minTVLUSD, exists := os.LookupEnv("UNPRICED_ALERT_MIN_TVL_USD"); exists {
+		if value, err := strconv.ParseFloat(minTVLUSD, 64); err == nil && value >= 0 {
+			UNPRICED_ALERT_MIN_TVL_USD = value
+		}
+	}
+
+	/**********************************************************************************************
+	** Optional maximum age of the stale prices carried over
+	**********************************************************************************************/
+	if maxAge, exists := os.LookupEnv("STALE_PRICE_MAX_AGE"); exists {
+		if age, err := time.ParseDuration(maxAge); err == nil && age > 0 {
+			STALE_PRICE_MAX_AGE = age
+		} else {
+			logs.Warning(`Invalid STALE_PRICE_MAX_AGE ` + maxAge + `, using ` + STALE_PRICE_MAX_AGE.String())
+		}
+	}
+
+	/**********************************************************************************************
+	** Optional use of the queued fees in the forward APY
+	**********************************************************************************************/
+	if usePendingFees, exists := os.LookupEnv("FORWARD_APY_USE_PENDING_FEES"); exists {
+		FORWARD_APY_USE_PENDING_FEES = usePendingFees == `true`
+	}
+
+	/**********************************************************************************************
+	** Optional factor of the divergence between the forward and the realized APYs
+	**********************************************************************************************/
+	if divergenceFactor, exists := os.LookupEnv("APY_DIVERGENCE_FACTOR"); exists {
+		if value, err := strconv.ParseFloat(divergenceFactor, 64); err == nil && (value == 0 || value > 1) {
+			APY_DIVERGENCE_FACTOR = value
+		}
+	}
+
+	/**********************************************************************************************
+	** Optional comparison with the yields of the competing protocols
+	**********************************************************************************************/
+	if competitorSources, exists := os.LookupEnv("COMPETITOR_SOURCES"); exists {
+		COMPETITOR_SOURCES = []string{}
+		for _, source := range strings.Split(competitorSources, ",") {
+			if source = strings.ToLower(strings.TrimSpace(source)); source != `` {
+				COMPETITOR_SOURCES = append(COMPETITOR_SOURCES, source)
+			}
+		}
+	}
+	if beefyURL, exists := os.LookupEnv("BEEFY_API_URL"); exists && beefyURL != `` {
+		BEEFY_API_URL = strings.TrimSuffix(beefyURL, `/`)
+	}
+	if sommelierURL, exists := os.LookupEnv("SOMMELIER_API_URL"); exists {
+		SOMMELIER_API_URL = sommelierURL
+	}
+
+	/**********************************************************************************************
+	** Optional purge of the CDN on the changes of the store
+	**********************************************************************************************/
+	if zoneID, exists := os.LookupEnv("CLOUDFLARE_ZONE_ID"); exists {
+		CLOUDFLARE_ZONE_ID = zoneID
+	}
+	if apiToken, exists := os.LookupEnv("CLOUDFLARE_API_TOKEN"); exists {
+		CLOUDFLARE_API_TOKEN = apiToken
+	}
+	if publicURL, exists := os.LookupEnv("CDN_PUBLIC_URL"); exists && publicURL != `` {
+		CDN_PUBLIC_URL = strings.TrimSuffix(publicURL, `/`)
+	}
+
+	/**********************************************************************************************
+	** Optional record or replay of the calls to the nodes and to the external APIs
+	**********************************************************************************************/
+	if fixturesMode, exists := os.LookupEnv("RPC_FIXTURES_MODE"); exists {
+		RPC_FIXTURES_MODE = fixturesMode
+	}
+	if fixturesPath, exists := os.LookupEnv("RPC_FIXTURES_PATH"); exists && fixturesPath != `` {
+		RPC_FIXTURES_PATH = fixturesPath
+	}
+
+	/**********************************************************************************************
+	** Optional directory of the ABIs called without generated bindings
+	**********************************************************************************************/
+	if abiDirectory, exists := os.LookupEnv("ABI_DIRECTORY"); exists && abiDirectory != `` {
+		ABI_DIRECTORY = abiDirectory
+	}
+
+	/**********************************************************************************************
+	** Optional pinning of the token assets to IPFS
+	**********************************************************************************************/
+	if pinningJWT, exists := os.LookupEnv("IPFS_PINNING_JWT"); exists {
+		IPFS_PINNING_JWT = pinningJWT
+	}
+	if pinningURL, exists := os.LookupEnv("IPFS_PINNING_API_URL"); exists && pinningURL != `` {
+		IPFS_PINNING_API_URL = strings.TrimSuffix(pinningURL, `/`)
+	}
+	if gatewayURL, exists := os.LookupEnv("IPFS_GATEWAY_URL"); exists && gatewayURL != `` {
+		IPFS_GATEWAY_URL = strings.TrimSuffix(gatewayURL, `/`) + `/`
+	}
+}
+
+/**************************************************************************************************
+** Reload reads the .env file again and applies its values on top of the current environment,
+** then calls SetEnv for the settings derived from them to take the new values. It returns the
+** names of the variables whose value changed, without their values as some of them are secrets.
+**
+** A variable removed from the .env file keeps its previous value. The chain configurations are
+** swapped at once (see setChains), the RPC and multicall clients being dialed again by the caller
+** for the chains whose URL changed. The settings only used at startup (the storage backend) are
+** not affected until a restart.
+**************************************************************************************************/
+func Reload() []string {
+	values, err := godotenv.Read(`.env`)
+	if err != nil {
+		logs.Warning(`Failed to read the .env file: ` + err.Error())
+	}
+	changed := []string{}
+	for key, value := range values {
+		if current, exists := os.LookupEnv(key); !exists || current != value {
+			os.Setenv(key, value)
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	SetEnv()
+	return changed
 }
 
 /**************************************************************************************************
@@ -89,6 +415,7 @@ func init() {
 	CHAINS[137] = POLYGON
 	CHAINS[146] = SONIC
 	CHAINS[250] = FANTOM
+	CHAINS[1101] = POLYGON_ZKEVM
 	CHAINS[8453] = BASE
 	CHAINS[42161] = ARBITRUM
 	CHAINS[747474] = KATANA
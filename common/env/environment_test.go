@@ -2,6 +2,8 @@ package env
 
 import (
 	"os"
+	"strconv"
+	"sync"
 	"testing"
 )
 
@@ -161,3 +163,54 @@ func TestSetEnvOverrides(t *testing.T) {
 	os.Unsetenv("RPC_URI_FOR_1")
 	os.Unsetenv("CG_DEMO_KEYS")
 }
+
+/**************************************************************************************************
+** TestSetEnvConcurrentReads tests that the chain configurations can be read while SetEnv applies
+** the environment again, as it does on a reload of the configuration. The readers must always
+** find every chain and never a partially written map. Run with -race to catch a regression.
+**************************************************************************************************/
+func TestSetEnvConcurrentReads(t *testing.T) {
+	originalRPC := os.Getenv("RPC_URI_FOR_1")
+	defer func() {
+		os.Setenv("RPC_URI_FOR_1", originalRPC)
+		SetEnv()
+	}()
+
+	expectedChains := len(GetChains())
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if chains := GetChains(); len(chains) != expectedChains {
+					t.Errorf("Expected %d chains, got %d", expectedChains, len(chains))
+					return
+				}
+				for chainID := range GetChains() {
+					if _, ok := GetChain(chainID); !ok {
+						t.Errorf("Chain %d is missing during SetEnv", chainID)
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		os.Setenv("RPC_URI_FOR_1", "https://reload-"+strconv.Itoa(i)+".example.com")
+		SetEnv()
+	}
+	close(done)
+	wg.Wait()
+
+	if chain, _ := GetChain(1); chain.RpcURI != "https://reload-49.example.com" {
+		t.Errorf("Expected the last RPC URI to be applied, got %s", chain.RpcURI)
+	}
+}
@@ -643,7 +643,7 @@ func fetchBlockNumberFromAPI(chain env.TChain, timestamp uint64, apiKey string)
 		daysDiff := int(now.Sub(time.Unix(timestampInt64, 0).UTC()).Hours() / 24)
 		if daysDiff >= 0 {
 			// Get current block number
-			latestBlock, err := RPC[chain.ID].BlockNumber(context.Background())
+			latestBlock, err := GetRPC(chain.ID).BlockNumber(context.Background())
 			if err == nil {
 				// Estimate block number
 				estimatedBlock := latestBlock - uint64(chain.AvgBlocksPerDay*daysDiff)
@@ -757,6 +757,21 @@ func GetTimeBlock(chainID uint64, timestamp uint64) (uint64, bool) {
 	return blockNum, exists
 }
 
+/**************************************************************************************************
+** GetNearestTimeBlock returns the stored block the closest to a timestamp, at most maxDays away
+** (0 = unlimited), preferring the ones at or before it. The daily blocks are stored at noon UTC.
+**
+** @param chainID The chain ID to search within
+** @param timestamp The desired Unix timestamp
+** @param maxDays The maximum number of days to look around the timestamp (0 = unlimited)
+** @return uint64 The block number
+** @return uint64 The timestamp the block number is associated with
+** @return bool True if a suitable block was found
+**************************************************************************************************/
+func GetNearestTimeBlock(chainID uint64, timestamp uint64, maxDays uint64) (uint64, uint64, bool) {
+	return getNearestTimeBlock(chainID, timestamp, maxDays)
+}
+
 /**************************************************************************************************
 ** getNearestTimeBlock attempts to find the closest stored timestamp->block mapping for a chain.
 ** Preference is given to timestamps at or before the target to avoid using future blocks.
@@ -899,7 +914,7 @@ func GetBlockTime(chainID uint64, blockNumber uint64) (blockTime uint64) {
 	blockTimeMutex.RUnlock()
 
 	// Not found in our data, fetch from blockchain
-	client := RPC[chainID]
+	client := GetRPC(chainID)
 	block, err := client.HeaderByNumber(context.Background(), big.NewInt(int64(blockNumber)))
 	if err != nil {
 		blocktimeWarning(fmt.Sprintf("impossible to retrieve block %s on chain %s",
@@ -942,6 +957,8 @@ func chainIDToName(chainID uint64) string {
 		return "polygon"
 	case 250:
 		return "fantom"
+	case 1101:
+		return "polygon_zkevm"
 	case 8453:
 		return "base"
 	case 42161:
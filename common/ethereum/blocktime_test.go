@@ -77,6 +77,13 @@ func TestBlockTimeDataStructure(t *testing.T) {
 ** This ensures that the core timestamp-to-block mapping functionality works correctly.
 **************************************************************************************************/
 func TestGetTimeBlockAndStoreTimeBlock(t *testing.T) {
+	// StoreTimeBlock appends to the CSV of the chain, which must not be the one of the data directory
+	originalDataDir := blockTimeDataDir
+	blockTimeDataDir = t.TempDir()
+	defer func() {
+		blockTimeDataDir = originalDataDir
+	}()
+
 	// Initialize a BlockTimeData structure for testing
 	if blockTimeData == nil {
 		blockTimeData = &BlockTimeData{
@@ -234,17 +241,9 @@ func TestBlockNumberByPeriod(t *testing.T) {
 ** This test creates a minimal dataset for validation.
 **************************************************************************************************/
 func TestExportBlockTimeDataAsCSV(t *testing.T) {
-	// Create a temporary directory for blocktime data
-	tempDir, err := os.MkdirTemp("", "blocktime_test")
-	if err != nil {
-		t.Skip("Cannot create temporary directory for testing")
-		return
-	}
-	defer os.RemoveAll(tempDir)
-
 	// Save original directory to restore later
 	originalDataDir := blockTimeDataDir
-	blockTimeDataDir = tempDir
+	blockTimeDataDir = t.TempDir()
 	defer func() {
 		blockTimeDataDir = originalDataDir
 	}()
@@ -310,17 +309,9 @@ func TestExportBlockTimeDataAsCSV(t *testing.T) {
 ** The test creates a temporary directory and files for validation.
 **************************************************************************************************/
 func TestCSVFileOperations(t *testing.T) {
-	// Create a temporary directory for test files
-	tempDir, err := os.MkdirTemp("", "blocktime_test")
-	if err != nil {
-		t.Skip("Cannot create temporary directory for testing")
-		return
-	}
-	defer os.RemoveAll(tempDir)
-
 	// Save original directory to restore later
 	originalDataDir := blockTimeDataDir
-	blockTimeDataDir = tempDir
+	blockTimeDataDir = t.TempDir()
 	defer func() {
 		blockTimeDataDir = originalDataDir
 	}()
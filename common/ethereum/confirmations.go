@@ -0,0 +1,102 @@
+package ethereum
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+/**************************************************************************************************
+** CONFIRMATION_POLL_INTERVAL is how often the confirmed block is checked while some streamed logs
+** wait for their confirmation.
+**************************************************************************************************/
+const CONFIRMATION_POLL_INTERVAL = 15 * time.Second
+
+/**************************************************************************************************
+** splitConfirmedLogs splits the streamed logs between the ones at or below the confirmed block
+** and the others.
+**************************************************************************************************/
+func splitConfirmedLogs(streamed []types.Log, confirmedBlock uint64) (confirmed []types.Log, pending []types.Log) {
+	for _, log := range streamed {
+		if log.BlockNumber <= confirmedBlock {
+			confirmed = append(confirmed, log)
+		} else {
+			pending = append(pending, log)
+		}
+	}
+	return confirmed, pending
+}
+
+/**************************************************************************************************
+** dropRemovedLog removes from pending the log a reorg removed, matched by its block hash, its
+** transaction and its index.
+**************************************************************************************************/
+func dropRemovedLog(pending []types.Log, removed types.Log) []types.Log {
+	kept := pending[:0]
+	for _, log := range pending {
+		if log.BlockHash == removed.BlockHash && log.TxHash == removed.TxHash && log.Index == removed.Index {
+			continue
+		}
+		kept = append(kept, log)
+	}
+	return kept
+}
+
+/**************************************************************************************************
+** ConfirmLogs holds the logs of a websocket subscription until the chain reaches their confirmation
+** depth, like the event filters stop at the confirmed block. The history logs above the confirmed
+** block are held as well, and the logs removed by a reorg before their confirmation are dropped.
+** The confirmed logs are sent on the returned stream, in order, until ctx is done.
+**
+** @param ctx The context of the subscription, the logs stop being forwarded once it is done
+** @param chainID The ID of the blockchain
+** @param stream The stream of the subscription
+** @param history The history of the subscription
+** @return <-chan types.Log The stream of the confirmed logs
+** @return []types.Log The confirmed history
+**************************************************************************************************/
+func ConfirmLogs(ctx context.Context, chainID uint64, stream <-chan types.Log, history []types.Log) (<-chan types.Log, []types.Log) {
+	confirmedBlock, err := GetConfirmedBlockNumber(chainID)
+	if err != nil {
+		logs.Warning(`Failed to get the confirmed block, the streamed logs are not held: ` + err.Error())
+		return stream, history
+	}
+	confirmedHistory, pending := splitConfirmedLogs(history, confirmedBlock)
+
+	confirmedStream := make(chan types.Log, cap(stream))
+	go func() {
+		ticker := time.NewTicker(CONFIRMATION_POLL_INTERVAL)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case log := <-stream:
+				if log.Removed {
+					pending = dropRemovedLog(pending, log)
+					continue
+				}
+				pending = append(pending, log)
+			case <-ticker.C:
+				if len(pending) == 0 {
+					continue
+				}
+				if confirmedBlock, err = GetConfirmedBlockNumber(chainID); err != nil {
+					continue
+				}
+				var confirmed []types.Log
+				confirmed, pending = splitConfirmedLogs(pending, confirmedBlock)
+				for _, log := range confirmed {
+					select {
+					case confirmedStream <- log:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+	return confirmedStream, confirmedHistory
+}
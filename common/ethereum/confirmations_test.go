@@ -0,0 +1,29 @@
+package ethereum
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+/**************************************************************************************************
+** TestSplitConfirmedLogs tests that the streamed logs are only released once their block is
+** confirmed, and that a log removed by a reorg is dropped before its confirmation.
+**************************************************************************************************/
+func TestSplitConfirmedLogs(t *testing.T) {
+	streamed := []types.Log{
+		{BlockNumber: 10, BlockHash: common.HexToHash(`0xa`), Index: 0},
+		{BlockNumber: 12, BlockHash: common.HexToHash(`0xb`), Index: 1},
+		{BlockNumber: 15, BlockHash: common.HexToHash(`0xc`), Index: 2},
+	}
+	confirmed, pending := splitConfirmedLogs(streamed, 12)
+	if len(confirmed) != 2 || len(pending) != 1 || pending[0].BlockNumber != 15 {
+		t.Fatalf("expected the logs up to block 12 to be confirmed, got %v and %v", confirmed, pending)
+	}
+
+	pending = dropRemovedLog(pending, types.Log{BlockNumber: 15, BlockHash: common.HexToHash(`0xc`), Index: 2, Removed: true})
+	if len(pending) != 0 {
+		t.Errorf("expected the removed log to be dropped, got %v", pending)
+	}
+}
@@ -0,0 +1,165 @@
+package ethereum
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/patrickmn/go-cache"
+	"golang.org/x/net/idna"
+)
+
+/**************************************************************************************************
+** The ENS names are resolved with the ENS registry of Ethereum mainnet, whatever the chain of the
+** request: the registry returns the resolver of the name, and the resolver its address. The
+** resolved names are cached for ENS_CACHE_DURATION, the unresolved ones for
+** ENS_NEGATIVE_CACHE_DURATION, for a name registered meanwhile to be picked up soon, and the
+** failed resolutions (the RPC being down) for ENS_FAILURE_CACHE_DURATION, for the requests not to
+** hammer the RPC with the same name.
+**************************************************************************************************/
+var ENS_REGISTRY_ADDRESS = common.HexToAddress(`0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e`)
+
+const ENS_CACHE_DURATION = time.Hour
+const ENS_NEGATIVE_CACHE_DURATION = 5 * time.Minute
+const ENS_FAILURE_CACHE_DURATION = 30 * time.Second
+
+var (
+	ensResolverSelector = crypto.Keccak256([]byte(`resolver(bytes32)`))[:4]
+	ensAddrSelector     = crypto.Keccak256([]byte(`addr(bytes32)`))[:4]
+	ensCache            = cache.New(ENS_CACHE_DURATION, 10*time.Minute)
+	ensNormalizer       = idna.New(idna.MapForLookup(), idna.Transitional(false), idna.CheckHyphens(false), idna.StrictDomainName(false))
+)
+
+/**************************************************************************************************
+** IsENSName checks if a string looks like an ENS name: dot-separated non-empty labels, without the
+** characters of a path or a query. The names are normalized in lowercase.
+**************************************************************************************************/
+func IsENSName(name string) bool {
+	if !strings.Contains(name, `.`) || strings.ContainsAny(name, " /?#%&,:") {
+		return false
+	}
+	for _, label := range strings.Split(name, `.`) {
+		if label == `` {
+			return false
+		}
+	}
+	return true
+}
+
+/**************************************************************************************************
+** NormalizeENSName normalizes an ENS name as ENSIP-15 does for the names without emoji sequences
+** or confusables: the labels are mapped with UTS-46 (case folding, width mapping, NFC), without
+** the transitional mappings nor the punycode decoding, the underscores are only allowed at the
+** start of a label, and the ASCII labels can't have hyphens at their third and fourth positions.
+** It returns false when the name is not a valid name once normalized.
+**************************************************************************************************/
+func NormalizeENSName(name string) (string, bool) {
+	hasReservedHyphens := func(label string) bool {
+		for _, char := range label {
+			if char > 0x7f {
+				return false
+			}
+		}
+		return len(label) >= 4 && label[2:4] == `--`
+	}
+
+	for _, label := range strings.Split(name, `.`) {
+		if hasReservedHyphens(label) {
+			return ``, false // Includes the punycode labels, which ENSIP-15 does not decode
+		}
+	}
+	normalized, err := ensNormalizer.ToUnicode(name)
+	if err != nil || !IsENSName(normalized) {
+		return ``, false
+	}
+	for _, label := range strings.Split(normalized, `.`) {
+		if hasReservedHyphens(label) || strings.Contains(strings.TrimLeft(label, `_`), `_`) {
+			return ``, false
+		}
+	}
+	return normalized, true
+}
+
+/**************************************************************************************************
+** ENSNamehash computes the namehash of an ENS name, as defined by EIP-137.
+**************************************************************************************************/
+func ENSNamehash(name string) common.Hash {
+	node := common.Hash{}
+	if name == `` {
+		return node
+	}
+	labels := strings.Split(name, `.`)
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = crypto.Keccak256Hash(node.Bytes(), crypto.Keccak256([]byte(labels[i])))
+	}
+	return node
+}
+
+/**************************************************************************************************
+** IsENSNameCached checks if the resolution of an ENS name, successful or not, is cached, resolving
+** it again not calling the RPC.
+**************************************************************************************************/
+func IsENSNameCached(name string) bool {
+	name, ok := NormalizeENSName(name)
+	if !ok {
+		return false
+	}
+	_, ok = ensCache.Get(name)
+	return ok
+}
+
+/**************************************************************************************************
+** ResolveENSName returns the address an ENS name resolves to on mainnet, once normalized. It
+** returns false if the name is invalid, not registered, has no address, or if the mainnet RPC is
+** not configured or fails.
+**************************************************************************************************/
+func ResolveENSName(name string) (common.Address, bool) {
+	name, ok := NormalizeENSName(name)
+	if !ok {
+		return common.Address{}, false
+	}
+	if cached, ok := ensCache.Get(name); ok {
+		address := cached.(common.Address)
+		return address, address != common.Address{}
+	}
+	client := GetRPC(1)
+	if client == nil {
+		return common.Address{}, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	node := ENSNamehash(name)
+	resolver, err := client.CallContract(ctx, ethereum.CallMsg{
+		To:   &ENS_REGISTRY_ADDRESS,
+		Data: append(append([]byte{}, ensResolverSelector...), node.Bytes()...),
+	}, nil)
+	if err != nil {
+		ensCache.Set(name, common.Address{}, ENS_FAILURE_CACHE_DURATION)
+		return common.Address{}, false
+	}
+	address := common.Address{}
+	if resolverAddress := common.BytesToAddress(resolver); len(resolver) == 32 && (resolverAddress != common.Address{}) {
+		resolved, err := client.CallContract(ctx, ethereum.CallMsg{
+			To:   &resolverAddress,
+			Data: append(append([]byte{}, ensAddrSelector...), node.Bytes()...),
+		}, nil)
+		if err != nil {
+			ensCache.Set(name, common.Address{}, ENS_FAILURE_CACHE_DURATION)
+			return common.Address{}, false
+		}
+		if len(resolved) == 32 {
+			address = common.BytesToAddress(resolved)
+		}
+	}
+
+	if (address == common.Address{}) {
+		ensCache.Set(name, address, ENS_NEGATIVE_CACHE_DURATION)
+		return address, false
+	}
+	ensCache.Set(name, address, ENS_CACHE_DURATION)
+	return address, true
+}
@@ -0,0 +1,104 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/assert"
+)
+
+/**************************************************************************************************
+** TestNormalizeENSName tests that the ENS names are mapped as ENSIP-15 does, and that the labels
+** with misplaced underscores or reserved hyphens (punycode included) are rejected.
+**************************************************************************************************/
+func TestNormalizeENSName(t *testing.T) {
+	valid := map[string]string{
+		`Vitalik.ETH`: `vitalik.eth`,
+		`ｖｉｔａｌｉｋ．eth`: `vitalik.eth`,
+		`_yearn.eth`:  `_yearn.eth`,
+		`straße.eth`:  `straße.eth`,
+		`a-b.eth`:     `a-b.eth`,
+	}
+	for name, expected := range valid {
+		normalized, ok := NormalizeENSName(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, expected, normalized, name)
+	}
+
+	for _, name := range []string{`xn--mnchen-3ya.eth`, `ab--cd.eth`, `a_b.eth`, `vitalik`, `a..eth`, `a b.eth`} {
+		_, ok := NormalizeENSName(name)
+		assert.False(t, ok, name)
+	}
+}
+
+/**************************************************************************************************
+** TestResolveENSName tests the resolution of the ENS names against a fake mainnet node, and that
+** the resolutions, successful or not, are cached for the RPC not to be called again.
+**************************************************************************************************/
+func TestResolveENSName(t *testing.T) {
+	resolver := common.HexToAddress(`0x4976fb03C32e5B8cfe2b6cCB31c09Ba78EBaBa41`)
+	resolved := common.HexToAddress(`0xFEB4acf3df3cDEA7399794D0869ef76A6EfAff52`)
+	registered := ENSNamehash(`ydaemon-test.eth`)
+
+	var calls atomic.Int32
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		request := struct {
+			ID     json.RawMessage `json:"id"`
+			Params []struct {
+				To    common.Address `json:"to"`
+				Input hexutil.Bytes  `json:"input"`
+			} `json:"params"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&request)
+		result := common.Hash{}
+		call := request.Params[0]
+		if len(call.Input) == 36 && common.BytesToHash(call.Input[4:]) == registered {
+			if call.To == ENS_REGISTRY_ADDRESS {
+				result = common.BytesToHash(resolver.Bytes())
+			} else if call.To == resolver {
+				result = common.BytesToHash(resolved.Bytes())
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{`jsonrpc`: `2.0`, `id`: request.ID, `result`: hexutil.Bytes(result.Bytes())})
+	}))
+	defer node.Close()
+
+	client, err := ethclient.Dial(node.URL)
+	assert.NoError(t, err)
+	clientsMtx.Lock()
+	previousClient := RPC[1]
+	RPC[1] = client
+	clientsMtx.Unlock()
+	defer func() {
+		clientsMtx.Lock()
+		RPC[1] = previousClient
+		clientsMtx.Unlock()
+	}()
+
+	assert.False(t, IsENSNameCached(`ydaemon-test.eth`))
+	address, ok := ResolveENSName(`YDaemon-Test.eth`)
+	assert.True(t, ok)
+	assert.Equal(t, resolved, address)
+	assert.Equal(t, int32(2), calls.Load(), "The registry and the resolver should be called")
+	assert.True(t, IsENSNameCached(`ydaemon-test.eth`))
+
+	address, ok = ResolveENSName(`ydaemon-test.eth`)
+	assert.True(t, ok)
+	assert.Equal(t, resolved, address)
+	assert.Equal(t, int32(2), calls.Load(), "A resolved name should be cached")
+
+	_, ok = ResolveENSName(`ydaemon-unregistered.eth`)
+	assert.False(t, ok)
+	assert.Equal(t, int32(3), calls.Load(), "An unregistered name has no resolver to call")
+	_, ok = ResolveENSName(`ydaemon-unregistered.eth`)
+	assert.False(t, ok)
+	assert.Equal(t, int32(3), calls.Load(), "An unregistered name should be cached")
+	assert.True(t, IsENSNameCached(`ydaemon-unregistered.eth`))
+}
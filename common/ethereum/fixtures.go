@@ -0,0 +1,30 @@
+package ethereum
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/yearn/ydaemon/common/fixtures"
+)
+
+/**************************************************************************************************
+** dialRPC connects to the node of a chain. When the fixtures are enabled (see common/fixtures), the
+** calls are recorded or replayed in the namespace of the chain, the node not being dialed at all
+** in replay mode.
+**************************************************************************************************/
+func dialRPC(chainID uint64, uri string) (*ethclient.Client, error) {
+	if !fixtures.IsEnabled() {
+		return ethclient.Dial(uri)
+	}
+	if fixtures.IsReplaying() {
+		uri = fixtures.REPLAY_URI
+	}
+	namespace := `rpc.` + strconv.FormatUint(chainID, 10)
+	client, err := rpc.DialOptions(context.Background(), uri, rpc.WithHTTPClient(fixtures.NewHTTPClient(namespace)))
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(client), nil
+}
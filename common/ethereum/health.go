@@ -0,0 +1,51 @@
+package ethereum
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+/**************************************************************************************************
+** RPC_HEALTH_TIMEOUT bounds the time an RPC endpoint has to answer a health check.
+**************************************************************************************************/
+const RPC_HEALTH_TIMEOUT = 10 * time.Second
+
+/**************************************************************************************************
+** listRPCClients returns the distinct clients of the RPC endpoints of a chain: its node, the one
+** of its multicalls and its archive node, the last two being only set when configured.
+**************************************************************************************************/
+func listRPCClients(chainID uint64) []*ethclient.Client {
+	clients := []*ethclient.Client{}
+	archiveClient, isArchive := GetArchiveRPC(chainID)
+	if !isArchive {
+		archiveClient = nil
+	}
+	for _, client := range []*ethclient.Client{GetRPC(chainID), GetMulticall(chainID).Client, archiveClient} {
+		isKnown := client == nil
+		for _, known := range clients {
+			isKnown = isKnown || known == client
+		}
+		if !isKnown {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
+/**************************************************************************************************
+** IsAnyRPCHealthy returns true when one of the RPC endpoints of a chain answers its block number
+** within RPC_HEALTH_TIMEOUT, false when they all fail or none is configured.
+**************************************************************************************************/
+func IsAnyRPCHealthy(chainID uint64) bool {
+	for _, client := range listRPCClients(chainID) {
+		ctx, cancel := context.WithTimeout(context.Background(), RPC_HEALTH_TIMEOUT)
+		_, err := client.BlockNumber(ctx)
+		cancel()
+		if err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -1,11 +1,14 @@
 package ethereum
 
 import (
+	"errors"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/fixtures"
 	"github.com/yearn/ydaemon/common/logs"
 )
 
@@ -23,10 +26,17 @@ func Initialize() {
 		EnableVerboseBlocktime()
 	}
 
+	// Record or replay the calls to the nodes and to the external APIs, for the tests and benchmarks
+	if env.RPC_FIXTURES_MODE != `` {
+		if err := fixtures.Enable(env.RPC_FIXTURES_MODE, env.RPC_FIXTURES_PATH); err != nil {
+			logs.Error(err)
+		}
+	}
+
 	// Create the RPC client for all the chains supported by yDaemon
 	for _, chain := range env.GetChains() {
 		logs.Info(`Dial RPC URI for chain`, chain.ID)
-		client, err := ethclient.Dial(GetRPCURI(chain.ID))
+		client, err := dialRPC(chain.ID, GetRPCURI(chain.ID))
 		if err != nil {
 			logs.Error(err, "Failed to connect to node")
 			continue
@@ -34,14 +44,33 @@ func Initialize() {
 		RPC[chain.ID] = client
 	}
 
-	// Create the multicall client for all the chains supported by yDaemon
+	// Create the archive RPC client for the chains with an archive node configured
 	for _, chain := range env.GetChains() {
-		rpcToUse := GetRPCURI(chain.ID)
-		multiCallURI, exists := os.LookupEnv("MULTICALL_RPC_URI_FOR_" + strconv.FormatUint(chain.ID, 10))
-		if exists {
-			rpcToUse = multiCallURI
+		archiveURI, exists := os.LookupEnv("ARCHIVE_RPC_URI_FOR_" + strconv.FormatUint(chain.ID, 10))
+		if !exists || archiveURI == `` {
+			continue
+		}
+		client, err := dialRPC(chain.ID, archiveURI)
+		if err != nil {
+			logs.Error(err, "Failed to connect to archive node")
+			continue
 		}
+		ARCHIVE_RPC[chain.ID] = client
+		ArchiveMulticallClientForChainID[chain.ID] = newMulticallWithClient(client, chain.MulticallContract.Address)
+	}
 
+	// Create the multicall client for all the chains supported by yDaemon
+	for _, chain := range env.GetChains() {
+		rpcToUse := GetMulticallURI(chain.ID)
+		if fixtures.IsEnabled() {
+			client, err := dialRPC(chain.ID, rpcToUse)
+			if err != nil {
+				logs.Error(err, "Failed to connect to multicall node")
+				continue
+			}
+			MulticallClientForChainID[chain.ID] = newMulticallWithClient(client, chain.MulticallContract.Address)
+			continue
+		}
 		MulticallClientForChainID[chain.ID] = NewMulticall(
 			rpcToUse,
 			chain.MulticallContract.Address,
@@ -63,3 +92,57 @@ func Initialize() {
 	}
 	logs.Info(`Completed blockchain initialization for all chains`)
 }
+
+/**************************************************************************************************
+** GetMulticallURI returns the URI of the node used by the multicalls of a chain: the one of
+** MULTICALL_RPC_URI_FOR_[chainID] when set, the RPC URI of the chain otherwise.
+**************************************************************************************************/
+func GetMulticallURI(chainID uint64) string {
+	if multiCallURI, exists := os.LookupEnv("MULTICALL_RPC_URI_FOR_" + strconv.FormatUint(chainID, 10)); exists {
+		return multiCallURI
+	}
+	return GetRPCURI(chainID)
+}
+
+/**************************************************************************************************
+** RPC_REDIAL_GRACE is the time the previous clients of a chain are kept open after being replaced,
+** for the calls in flight to end.
+**************************************************************************************************/
+const RPC_REDIAL_GRACE = time.Minute
+
+/**************************************************************************************************
+** RedialClients dials the node and the multicall node of a chain again, after a reload of the
+** configuration changed their URL, and swaps the clients. The previous clients are closed after
+** RPC_REDIAL_GRACE. The clients are kept as they are if the new node cannot be dialed.
+**************************************************************************************************/
+func RedialClients(chainID uint64) error {
+	chain, ok := env.GetChain(chainID)
+	if !ok {
+		return errors.New(`chain not found`)
+	}
+	client, err := dialRPC(chainID, GetRPCURI(chainID))
+	if err != nil {
+		return err
+	}
+	multicallClient, err := dialRPC(chainID, GetMulticallURI(chainID))
+	if err != nil {
+		client.Close()
+		return err
+	}
+
+	clientsMtx.Lock()
+	previousClients := []*ethclient.Client{RPC[chainID], MulticallClientForChainID[chainID].Client}
+	RPC[chainID] = client
+	MulticallClientForChainID[chainID] = newMulticallWithClient(multicallClient, chain.MulticallContract.Address)
+	clientsMtx.Unlock()
+
+	time.AfterFunc(RPC_REDIAL_GRACE, func() {
+		for _, previous := range previousClients {
+			if previous != nil {
+				previous.Close()
+			}
+		}
+	})
+	logs.Info(`Dialed the RPC URI again for chain`, chainID)
+	return nil
+}
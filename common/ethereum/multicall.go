@@ -64,12 +64,18 @@ func NewMulticall(rpcURI string, multicallAddress common.Address) TEthMultiCalle
 		return NewMulticall(rpcURI, multicallAddress)
 	}
 
+	return newMulticallWithClient(client, multicallAddress)
+}
+
+// newMulticallWithClient creates a new instance of a TEthMultiCaller using an already
+// dialed client.
+func newMulticallWithClient(client *ethclient.Client, multicallAddress common.Address) TEthMultiCaller {
 	// Load Multicall abi for later use
 	mcAbi, err := contracts.Multicall3MetaData.GetAbi()
 	if err != nil {
 		logs.Error(err)
 		time.Sleep(time.Second)
-		return NewMulticall(rpcURI, multicallAddress)
+		return newMulticallWithClient(client, multicallAddress)
 	}
 
 	return TEthMultiCaller{
@@ -264,3 +270,40 @@ func (caller *TEthMultiCaller) ExecuteByBatch(
 
 	return results
 }
+
+// ExecuteSequentially performs the calls one by one, without the multicall contract. This is
+// slower but required for the chains where no multicall contract can be used. The results use
+// the same format as ExecuteByBatch.
+func (caller *TEthMultiCaller) ExecuteSequentially(
+	calls []Call,
+	blockNumber *big.Int,
+) map[string][]interface{} {
+	if caller.Client == nil {
+		logs.Error("No client provided.")
+		return nil
+	}
+
+	results := make(map[string][]interface{})
+	for _, call := range calls {
+		target := call.Target
+		resp, err := caller.Client.CallContract(
+			context.Background(),
+			ethereum.CallMsg{
+				To:   &target,
+				Data: call.CallData,
+			},
+			blockNumber,
+		)
+		if err != nil || len(resp) == 0 {
+			results[call.Name+call.Method] = nil
+			continue
+		}
+		unpacked, err := call.Abi.Unpack(call.Method, resp)
+		if err != nil {
+			results[call.Name+call.Method] = nil
+			continue
+		}
+		results[call.Name+call.Method] = unpacked
+	}
+	return results
+}
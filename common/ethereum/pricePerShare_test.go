@@ -269,10 +269,11 @@ func TestFetchPPSFunctions(t *testing.T) {
 
 	chainID := uint64(1) // Ethereum Mainnet
 	vaultAddress := common.HexToAddress("0x0000000000000000000000000000000000000000")
+	vaultActivation := uint64(0)
 	decimals := uint64(18)
 
 	// Test FetchPPSToday
-	todayPPS := FetchPPSToday(chainID, vaultAddress, decimals)
+	todayPPS := FetchPPSToday(chainID, vaultAddress, vaultActivation, decimals)
 	// We can't assert specific values since this is an integration test,
 	// but we can check that it returns a valid Float (even if it's zero due to invalid inputs)
 	if todayPPS == nil {
@@ -280,13 +281,13 @@ func TestFetchPPSFunctions(t *testing.T) {
 	}
 
 	// Test FetchPPSLastWeek
-	lastWeekPPS := FetchPPSLastWeek(chainID, vaultAddress, decimals)
+	lastWeekPPS := FetchPPSLastWeek(chainID, vaultAddress, vaultActivation, decimals)
 	if lastWeekPPS == nil {
 		t.Error("FetchPPSLastWeek returned nil")
 	}
 
 	// Test FetchPPSLastMonth
-	lastMonthPPS := FetchPPSLastMonth(chainID, vaultAddress, decimals)
+	lastMonthPPS := FetchPPSLastMonth(chainID, vaultAddress, vaultActivation, decimals)
 	if lastMonthPPS == nil {
 		t.Error("FetchPPSLastMonth returned nil")
 	}
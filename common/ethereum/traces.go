@@ -0,0 +1,93 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/yearn/ydaemon/common/env"
+)
+
+/**************************************************************************************************
+** Some actions leave no event behind (a tend() of a strategy, a call relayed by a keeper contract)
+** and can only be found in the call traces of the transactions. The traces are read with
+** trace_filter, only on the chains whose RPC exposes the trace namespace (SupportsTraces).
+**************************************************************************************************/
+var ErrTracesNotSupported = errors.New(`the trace namespace is not available on this chain`)
+
+/**************************************************************************************************
+** TCallTrace is a successful call to a contract found in the traces, the internal calls included.
+**************************************************************************************************/
+type TCallTrace struct {
+	From            common.Address
+	To              common.Address
+	Input           []byte
+	BlockNumber     uint64
+	TransactionHash common.Hash
+}
+
+type tRawTrace struct {
+	Type   string `json:"type"`
+	Error  string `json:"error"`
+	Action struct {
+		CallType string         `json:"callType"`
+		From     common.Address `json:"from"`
+		To       common.Address `json:"to"`
+		Input    hexutil.Bytes  `json:"input"`
+	} `json:"action"`
+	BlockNumber     uint64      `json:"blockNumber"`
+	TransactionHash common.Hash `json:"transactionHash"`
+}
+
+/**************************************************************************************************
+** FilterCallTraces returns the successful calls made to one of the addresses between two blocks
+** (both included) whose calldata starts with the selector, the delegate and static calls being
+** ignored. It returns ErrTracesNotSupported on the chains without the trace namespace.
+**************************************************************************************************/
+func FilterCallTraces(chainID uint64, fromBlock uint64, toBlock uint64, toAddresses []common.Address, selector []byte) ([]TCallTrace, error) {
+	chain, ok := env.GetChain(chainID)
+	if !ok || !chain.Capabilities.SupportsTraces {
+		return nil, ErrTracesNotSupported
+	}
+	client := GetRPC(chainID)
+	if client == nil {
+		return nil, errors.New(`no RPC client for chain ` + strconv.FormatUint(chainID, 10))
+	}
+
+	rawTraces := []tRawTrace{}
+	filter := map[string]interface{}{
+		`fromBlock`: hexutil.EncodeUint64(fromBlock),
+		`toBlock`:   hexutil.EncodeUint64(toBlock),
+		`toAddress`: toAddresses,
+	}
+	if err := client.Client().CallContext(context.Background(), &rawTraces, `trace_filter`, filter); err != nil {
+		return nil, err
+	}
+	return selectCallTraces(rawTraces, selector), nil
+}
+
+/**************************************************************************************************
+** selectCallTraces keeps the successful plain calls whose calldata starts with the selector.
+**************************************************************************************************/
+func selectCallTraces(rawTraces []tRawTrace, selector []byte) []TCallTrace {
+	calls := []TCallTrace{}
+	for _, trace := range rawTraces {
+		if trace.Type != `call` || trace.Action.CallType != `call` || trace.Error != `` {
+			continue
+		}
+		if !bytes.HasPrefix(trace.Action.Input, selector) {
+			continue
+		}
+		calls = append(calls, TCallTrace{
+			From:            trace.Action.From,
+			To:              trace.Action.To,
+			Input:           trace.Action.Input,
+			BlockNumber:     trace.BlockNumber,
+			TransactionHash: trace.TransactionHash,
+		})
+	}
+	return calls
+}
@@ -0,0 +1,31 @@
+package ethereum
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSelectCallTraces(t *testing.T) {
+	strategy := common.HexToAddress(`0x1`)
+	newTrace := func(traceType string, callType string, input []byte, err string) tRawTrace {
+		trace := tRawTrace{Type: traceType, Error: err, BlockNumber: 42}
+		trace.Action.CallType = callType
+		trace.Action.To = strategy
+		trace.Action.Input = input
+		return trace
+	}
+	selector := []byte{0x44, 0x03, 0x68, 0xa3}
+	calls := selectCallTraces([]tRawTrace{
+		newTrace(`call`, `call`, selector, ``),
+		newTrace(`call`, `delegatecall`, selector, ``),
+		newTrace(`call`, `staticcall`, selector, ``),
+		newTrace(`call`, `call`, selector, `Reverted`),
+		newTrace(`call`, `call`, []byte{0x01, 0x02, 0x03, 0x04}, ``),
+		newTrace(`create`, ``, selector, ``),
+	}, selector)
+
+	if len(calls) != 1 || calls[0].To != strategy || calls[0].BlockNumber != 42 {
+		t.Errorf("expected only the successful plain call with the selector, got %v", calls)
+	}
+}
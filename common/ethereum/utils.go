@@ -10,6 +10,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -26,10 +27,23 @@ import (
 var RPC = map[uint64]*ethclient.Client{}
 
 /**************************************************************************************************
-** WS stores WebSocket client connections for each chain ID.
+** ARCHIVE_RPC stores the connections to the archive nodes, for the chains with an archive node
+** configured (ARCHIVE_RPC_URI_FOR_[chainID]). They are used to read the state at past blocks.
+**************************************************************************************************/
+var ARCHIVE_RPC = map[uint64]*ethclient.Client{}
+
+/**************************************************************************************************
+** clientsMtx guards RPC, ARCHIVE_RPC and the multicall clients, replaced at runtime when the URL
+** of a node changes on a reload of the configuration (see RedialClients).
+**************************************************************************************************/
+var clientsMtx sync.RWMutex
+
+/**************************************************************************************************
+** WS stores WebSocket client connections for each chain ID, guarded by wsMtx.
 ** This map allows for easy access to WebSocket clients across the application.
 **************************************************************************************************/
 var WS = map[uint64]*ethclient.Client{}
+var wsMtx sync.Mutex
 
 /**************************************************************************************************
 ** GetRPC returns the current RPC connection for a specific chain.
@@ -41,9 +55,28 @@ var WS = map[uint64]*ethclient.Client{}
 ** @return *ethclient.Client The Ethereum client for the specified chain
 **************************************************************************************************/
 func GetRPC(chainID uint64) *ethclient.Client {
+	clientsMtx.RLock()
+	defer clientsMtx.RUnlock()
 	return RPC[chainID]
 }
 
+/**************************************************************************************************
+** GetArchiveRPC returns the connection to use to read the state at a past block: the archive node
+** of the chain when configured, the regular node otherwise (which may not serve old states).
+**
+** @param chainID The ID of the blockchain to get the connection for
+** @return *ethclient.Client The Ethereum client for the specified chain
+** @return bool True if the client is a configured archive node
+**************************************************************************************************/
+func GetArchiveRPC(chainID uint64) (*ethclient.Client, bool) {
+	clientsMtx.RLock()
+	defer clientsMtx.RUnlock()
+	if client, ok := ARCHIVE_RPC[chainID]; ok {
+		return client, true
+	}
+	return RPC[chainID], false
+}
+
 /**************************************************************************************************
 ** GetRPCURI returns the URI used to connect to the node for a specific chain ID.
 **
@@ -91,6 +124,38 @@ func GetWSEnvURI(chainID uint64) string {
 **************************************************************************************************/
 var MulticallClientForChainID = make(map[uint64]TEthMultiCaller)
 
+/**************************************************************************************************
+** ArchiveMulticallClientForChainID stores the multicall clients using the archive node of the
+** chains with one configured (ARCHIVE_RPC_URI_FOR_[chainID]), to batch the calls at past blocks.
+**************************************************************************************************/
+var ArchiveMulticallClientForChainID = make(map[uint64]TEthMultiCaller)
+
+/**************************************************************************************************
+** GetMulticall returns the multicall client of a chain.
+**************************************************************************************************/
+func GetMulticall(chainID uint64) TEthMultiCaller {
+	clientsMtx.RLock()
+	defer clientsMtx.RUnlock()
+	return MulticallClientForChainID[chainID]
+}
+
+/**************************************************************************************************
+** GetArchiveMulticall returns the multicall client to use to read the state at a past block: the
+** one of the archive node of the chain when configured, the regular one otherwise.
+**
+** @param chainID The ID of the blockchain to get the multicall client for
+** @return TEthMultiCaller The multicall client for the specified chain
+** @return bool True if the client uses a configured archive node
+**************************************************************************************************/
+func GetArchiveMulticall(chainID uint64) (TEthMultiCaller, bool) {
+	clientsMtx.RLock()
+	defer clientsMtx.RUnlock()
+	if caller, ok := ArchiveMulticallClientForChainID[chainID]; ok {
+		return caller, true
+	}
+	return MulticallClientForChainID[chainID], false
+}
+
 /**************************************************************************************************
 ** randomSigner generates a fake signer for the Ethereum client.
 **
@@ -142,42 +207,114 @@ func GetWSClient(chainID uint64, shouldRetry bool) (*ethclient.Client, error) {
 		return nil, errors.New("chain cannot use websocket")
 	}
 
-	if WS[chainID] == nil {
-		uriString := GetWSEnvURI(chainID)
-		uri, _ := url.Parse(uriString)
-		if strings.HasPrefix(uri.Host, `nd-`) {
-			uri.Host = strings.Replace(uri.Host, `nd-`, `ws-nd-`, 1)
-		}
-		if strings.Contains(uri.Host, `infura.io`) && uri.Scheme == `https` {
-			uri.Path = strings.Replace(uri.Path, `v3`, `ws/v3`, 1)
-		}
-		if strings.Contains(uri.Host, `chainstack.com`) && uri.Scheme == `https` {
-			uri.Path = `ws` + uri.Path
-		}
+	wsMtx.Lock()
+	client, ok := WS[chainID]
+	wsMtx.Unlock()
+	if ok && client != nil {
+		return client, nil
+	}
 
-		switch uri.Scheme {
-		case `https`:
-			uri.Scheme = `wss`
-		case `http`:
-			uri.Scheme = `ws`
-		}
+	client, err := dialWSClient(chainID, shouldRetry)
+	if err != nil {
+		return nil, err
+	}
+	wsMtx.Lock()
+	defer wsMtx.Unlock()
+	if current, ok := WS[chainID]; ok && current != nil {
+		client.Close() // Opened concurrently by another caller
+		return current, nil
+	}
+	WS[chainID] = client
+	return client, nil
+}
+
+/**************************************************************************************************
+** ResetWSClient closes and drops the WebSocket connection of a chain, for the next GetWSClient to
+** open a new one. It is used when a subscription fails, the connection being likely closed.
+**************************************************************************************************/
+func ResetWSClient(chainID uint64) {
+	wsMtx.Lock()
+	defer wsMtx.Unlock()
+	if client, ok := WS[chainID]; ok && client != nil {
+		client.Close()
+	}
+	delete(WS, chainID)
+}
+
+func dialWSClient(chainID uint64, shouldRetry bool) (*ethclient.Client, error) {
+	uriString := GetWSEnvURI(chainID)
+	uri, _ := url.Parse(uriString)
+	if strings.HasPrefix(uri.Host, `nd-`) {
+		uri.Host = strings.Replace(uri.Host, `nd-`, `ws-nd-`, 1)
+	}
+	if strings.Contains(uri.Host, `infura.io`) && uri.Scheme == `https` {
+		uri.Path = strings.Replace(uri.Path, `v3`, `ws/v3`, 1)
+	}
+	if strings.Contains(uri.Host, `chainstack.com`) && uri.Scheme == `https` {
+		uri.Path = `ws` + uri.Path
+	}
+
+	switch uri.Scheme {
+	case `https`:
+		uri.Scheme = `wss`
+	case `http`:
+		uri.Scheme = `ws`
+	}
 
-		// contextTimeout, cancel := context.WithDeadline(context.Background(), time.Now().Add(10*time.Second))
-		// defer cancel()
-
-		ctx, _ := context.WithTimeout(context.Background(), 10*time.Second)
-		client, err := ethclient.DialContext(ctx, uri.String())
-		if err != nil {
-			if shouldRetry && err.Error() == `i/o timeout` {
-				logs.Warning(fmt.Sprintf("Chain %d - Timeout while opening WS client with RPC %v",
-					chainID, err))
-				return GetWSClient(chainID, false)
-			}
-			logs.Error(fmt.Sprintf("Chain %d - Error while opening WS client with RPC %v",
+	// The context only bounds the connection establishment, the client outlives it
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, uri.String())
+	if err != nil {
+		if shouldRetry && err.Error() == `i/o timeout` {
+			logs.Warning(fmt.Sprintf("Chain %d - Timeout while opening WS client with RPC %v",
 				chainID, err))
-			return nil, err
+			return dialWSClient(chainID, false)
 		}
-		WS[chainID] = client
+		logs.Error(fmt.Sprintf("Chain %d - Error while opening WS client with RPC %v",
+			chainID, err))
+		return nil, err
+	}
+	return client, nil
+}
+
+/**************************************************************************************************
+** CapToConfirmedBlock returns the highest block that can be considered final given the current
+** head of the chain and the number of confirmations required. Events emitted after this block
+** may still be reorged and should be scanned again on the next pass.
+**
+** @param head The current block number of the chain
+** @param confirmations The number of confirmations required
+** @return uint64 The highest confirmed block, or 0 if the chain is shorter than the confirmations
+**************************************************************************************************/
+func CapToConfirmedBlock(head uint64, confirmations uint64) uint64 {
+	if head <= confirmations {
+		return 0
+	}
+	return head - confirmations
+}
+
+/**************************************************************************************************
+** GetConfirmedBlockNumber returns the latest block of a chain minus the chain confirmation depth.
+** This should be used as the upper bound of the event filters to avoid indexing events that may
+** later be removed by a reorg, which is frequent on fast L2s.
+**
+** @param chainID The ID of the blockchain
+** @return uint64 The highest confirmed block number
+** @return error Any error encountered while fetching the current block number
+**************************************************************************************************/
+func GetConfirmedBlockNumber(chainID uint64) (uint64, error) {
+	client := GetRPC(chainID)
+	if client == nil {
+		return 0, errors.New(`no RPC client for chain ` + strconv.FormatUint(chainID, 10))
+	}
+	head, err := client.BlockNumber(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	chain, ok := env.GetChain(chainID)
+	if !ok {
+		return head, nil
 	}
-	return WS[chainID], nil
+	return CapToConfirmedBlock(head, chain.ConfirmationBlocks), nil
 }
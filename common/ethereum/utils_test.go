@@ -169,3 +169,22 @@ func TestMulticallClientMap(t *testing.T) {
 		t.Error("Expected MulticallClientForChainID to be initialized, got nil")
 	}
 }
+
+/**************************************************************************************************
+** TestCapToConfirmedBlock tests the CapToConfirmedBlock function to ensure the upper bound of the
+** event filters always leaves the unconfirmed blocks out. This test validates:
+** - The confirmations are removed from the head
+** - The function never underflows when the chain is shorter than the confirmations
+** - A zero confirmation depth returns the head itself
+**************************************************************************************************/
+func TestCapToConfirmedBlock(t *testing.T) {
+	if block := CapToConfirmedBlock(1000, 12); block != 988 {
+		t.Errorf("Expected 988, got %d", block)
+	}
+	if block := CapToConfirmedBlock(10, 12); block != 0 {
+		t.Errorf("Expected 0 when the head is lower than the confirmations, got %d", block)
+	}
+	if block := CapToConfirmedBlock(1000, 0); block != 1000 {
+		t.Errorf("Expected 1000 without confirmations, got %d", block)
+	}
+}
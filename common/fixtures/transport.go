@@ -0,0 +1,391 @@
+package fixtures
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+/**************************************************************************************************
+** The fixtures are the recorded responses of the nodes and of the external APIs, for the
+** computations to be run offline against a frozen state of the chains (regression tests and
+** benchmarks). In MODE_RECORD the requests are sent and their responses saved, in MODE_REPLAY
+** they are only answered from the saved responses, a request never recorded failing.
+**
+** The fixtures are saved in `<dir>/<namespace>/<key>.json`. The JSON-RPC calls are saved one by
+** one, even when sent in a batch, in the namespace of their chain (`rpc.<chainID>`), and keyed by
+** their method and params only: the URL of the node and the id of the call don't matter. The
+** other requests are saved in the `http` namespace, keyed by their method, URL and body, without
+** the secrets of their query (SECRET_QUERY_PARAMS).
+**************************************************************************************************/
+const (
+	MODE_RECORD = `record`
+	MODE_REPLAY = `replay`
+)
+
+const HTTP_NAMESPACE = `http`
+const REPLAY_URI = `http://fixtures.replay`
+
+var SECRET_QUERY_PARAMS = []string{`key`, `apikey`, `api_key`, `x_cg_demo_api_key`, `token`}
+
+type tFixture struct {
+	Status      int             `json:"status,omitempty"`
+	ContentType string          `json:"contentType,omitempty"`
+	Body        []byte          `json:"body,omitempty"` // Encoded in base64, the body may be binary
+	Call        json.RawMessage `json:"call,omitempty"`
+	Response    json.RawMessage `json:"response,omitempty"`
+}
+
+/**************************************************************************************************
+** TTransport records or replays the requests of a namespace.
+**************************************************************************************************/
+type TTransport struct {
+	Namespace string
+	next      http.RoundTripper
+}
+
+var (
+	mode          = ``
+	directory     = ``
+	baseTransport = http.DefaultTransport
+	fixturesMtx   sync.RWMutex
+	failedCalls   = []string{}
+)
+
+/**************************************************************************************************
+** Enable records or replays, depending on the mode, the requests of the RPC clients dialed with
+** NewHTTPClient and of the default HTTP client.
+**************************************************************************************************/
+func Enable(newMode string, dir string) error {
+	if newMode != MODE_RECORD && newMode != MODE_REPLAY {
+		return errors.New(`unknown fixtures mode ` + newMode)
+	}
+	fixturesMtx.Lock()
+	mode, directory = newMode, dir
+	failedCalls = []string{}
+	fixturesMtx.Unlock()
+	http.DefaultTransport = &TTransport{Namespace: HTTP_NAMESPACE, next: baseTransport}
+	logs.Info(`Fixtures in ` + newMode + ` mode from ` + dir)
+	return nil
+}
+
+/**************************************************************************************************
+** Disable restores the default HTTP transport. The RPC clients already dialed keep their
+** fixtures.
+**************************************************************************************************/
+func Disable() {
+	fixturesMtx.Lock()
+	mode, directory = ``, ``
+	fixturesMtx.Unlock()
+	http.DefaultTransport = baseTransport
+}
+
+func IsEnabled() bool {
+	fixturesMtx.RLock()
+	defer fixturesMtx.RUnlock()
+	return mode != ``
+}
+
+func IsReplaying() bool {
+	fixturesMtx.RLock()
+	defer fixturesMtx.RUnlock()
+	return mode == MODE_REPLAY
+}
+
+/**************************************************************************************************
+** NewHTTPClient returns an HTTP client recording or replaying its requests in a namespace.
+**************************************************************************************************/
+func NewHTTPClient(namespace string) *http.Client {
+	return &http.Client{Transport: &TTransport{Namespace: namespace, next: baseTransport}}
+}
+
+/**************************************************************************************************
+** FailedCalls returns the JSON-RPC calls which could not be answered since the fixtures were
+** enabled, or since the last reset: the calls never recorded when replaying, and the calls the
+** node failed to answer when recording. A run with failed calls is not a frozen state of the
+** chain, and must not be used as a reference.
+**************************************************************************************************/
+func FailedCalls() []string {
+	fixturesMtx.RLock()
+	defer fixturesMtx.RUnlock()
+	return append([]string{}, failedCalls...)
+}
+
+func ResetFailedCalls() {
+	fixturesMtx.Lock()
+	failedCalls = []string{}
+	fixturesMtx.Unlock()
+}
+
+func (t *TTransport) failCall(call map[string]json.RawMessage) {
+	fixturesMtx.Lock()
+	failedCalls = append(failedCalls, t.Namespace+` `+string(call[`method`])+` `+string(call[`params`]))
+	fixturesMtx.Unlock()
+}
+
+func getConfig() (string, string) {
+	fixturesMtx.RLock()
+	defer fixturesMtx.RUnlock()
+	return mode, directory
+}
+
+func hashKey(parts ...string) string {
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(hash[:])
+}
+
+func (t *TTransport) path(dir string, key string) string {
+	return filepath.Join(dir, t.Namespace, key+`.json`)
+}
+
+func (t *TTransport) load(dir string, key string) (tFixture, bool) {
+	content, err := os.ReadFile(t.path(dir, key))
+	if err != nil {
+		return tFixture{}, false
+	}
+	fixture := tFixture{}
+	if err := json.Unmarshal(content, &fixture); err != nil {
+		return tFixture{}, false
+	}
+	return fixture, true
+}
+
+func (t *TTransport) save(dir string, key string, fixture tFixture) {
+	path := t.path(dir, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		logs.Error(`Failed to create the fixtures directory: ` + err.Error())
+		return
+	}
+	content, _ := json.MarshalIndent(fixture, "", "\t")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		logs.Error(`Failed to write the fixture ` + path + `: ` + err.Error())
+	}
+}
+
+/**************************************************************************************************
+** RoundTrip records or replays a request, or sends it as is when the fixtures are disabled.
+**************************************************************************************************/
+func (t *TTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	currentMode, dir := getConfig()
+	if currentMode == `` {
+		return t.next.RoundTrip(req)
+	}
+
+	body := []byte{}
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if calls, isBatch, ok := parseCalls(body); ok && t.Namespace != HTTP_NAMESPACE {
+		return t.roundTripCalls(req, currentMode, dir, calls, isBatch)
+	}
+
+	key := hashKey(req.Method, stripSecrets(req.URL), string(body))
+	if currentMode == MODE_REPLAY {
+		fixture, ok := t.load(dir, key)
+		if !ok {
+			return nil, errors.New(`no fixture recorded for ` + req.Method + ` ` + req.URL.Host + req.URL.Path)
+		}
+		return newResponse(req, fixture.Status, fixture.ContentType, fixture.Body), nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	t.save(dir, key, tFixture{Status: resp.StatusCode, ContentType: resp.Header.Get(`Content-Type`), Body: respBody})
+	return newResponse(req, resp.StatusCode, resp.Header.Get(`Content-Type`), respBody), nil
+}
+
+/**************************************************************************************************
+** roundTripCalls records or replays the JSON-RPC calls of a request one by one, the response to
+** each call being matched by its id.
+**************************************************************************************************/
+func (t *TTransport) roundTripCalls(req *http.Request, currentMode string, dir string, calls []map[string]json.RawMessage, isBatch bool) (*http.Response, error) {
+	keys := make([]string, len(calls))
+	for i, call := range calls {
+		keys[i] = callKey(call)
+	}
+
+	responses := make([]map[string]json.RawMessage, len(calls))
+	if currentMode == MODE_REPLAY {
+		for i, call := range calls {
+			fixture, ok := t.load(dir, keys[i])
+			if !ok {
+				t.failCall(call)
+				return nil, errors.New(`no fixture recorded for the ` + string(call[`method`]) + ` call on ` + t.Namespace)
+			}
+			response := map[string]json.RawMessage{}
+			if err := json.Unmarshal(fixture.Response, &response); err != nil {
+				return nil, err
+			}
+			response[`id`] = call[`id`]
+			responses[i] = response
+		}
+	} else {
+		resp, err := t.next.RoundTrip(req)
+		if err != nil {
+			for _, call := range calls {
+				t.failCall(call)
+			}
+			return nil, err
+		}
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		received, _, ok := parseCalls(respBody)
+		if resp.StatusCode != http.StatusOK || !ok {
+			for _, call := range calls {
+				t.failCall(call)
+			}
+			return newResponse(req, resp.StatusCode, resp.Header.Get(`Content-Type`), respBody), nil
+		}
+		byID := make(map[string]map[string]json.RawMessage)
+		for _, response := range received {
+			byID[string(response[`id`])] = response
+		}
+		for i, call := range calls {
+			response, ok := byID[string(call[`id`])]
+			if !ok {
+				t.failCall(call)
+				continue
+			}
+			responses[i] = response
+			withoutID := map[string]json.RawMessage{}
+			for field, value := range response {
+				if field != `id` {
+					withoutID[field] = value
+				}
+			}
+			recordedCall, _ := json.Marshal(withoutCallID(call))
+			recordedResponse, _ := json.Marshal(withoutID)
+			t.save(dir, keys[i], tFixture{Call: recordedCall, Response: recordedResponse})
+		}
+	}
+
+	var content []byte
+	if isBatch {
+		answered := []map[string]json.RawMessage{}
+		for _, response := range responses {
+			if response != nil {
+				answered = append(answered, response)
+			}
+		}
+		content, _ = json.Marshal(answered)
+	} else {
+		content, _ = json.Marshal(responses[0])
+	}
+	return newResponse(req, http.StatusOK, `application/json`, content), nil
+}
+
+/**************************************************************************************************
+** parseCalls returns the JSON-RPC calls (or responses) of a body, and whether it is a batch. The
+** body is not JSON-RPC when the boolean is false.
+**************************************************************************************************/
+func parseCalls(body []byte) ([]map[string]json.RawMessage, bool, bool) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, false, false
+	}
+	if trimmed[0] == '[' {
+		calls := []map[string]json.RawMessage{}
+		if err := json.Unmarshal(trimmed, &calls); err != nil || len(calls) == 0 {
+			return nil, false, false
+		}
+		for _, call := range calls {
+			if _, ok := call[`jsonrpc`]; !ok {
+				return nil, false, false
+			}
+		}
+		return calls, true, true
+	}
+	call := map[string]json.RawMessage{}
+	if err := json.Unmarshal(trimmed, &call); err != nil {
+		return nil, false, false
+	}
+	if _, ok := call[`jsonrpc`]; !ok {
+		return nil, false, false
+	}
+	return []map[string]json.RawMessage{call}, false, true
+}
+
+func withoutCallID(call map[string]json.RawMessage) map[string]json.RawMessage {
+	withoutID := map[string]json.RawMessage{}
+	for field, value := range call {
+		if field == `method` || field == `params` {
+			withoutID[field] = value
+		}
+	}
+	return withoutID
+}
+
+/**************************************************************************************************
+** callKey is the key of a JSON-RPC call: its method and its params, compacted for the formatting
+** not to matter.
+**************************************************************************************************/
+func callKey(call map[string]json.RawMessage) string {
+	params := &bytes.Buffer{}
+	if err := json.Compact(params, call[`params`]); err != nil {
+		params.Write(call[`params`])
+	}
+	return hashKey(string(call[`method`]), params.String())
+}
+
+/**************************************************************************************************
+** stripSecrets returns the URL of a request without the values of its secret query params.
+**************************************************************************************************/
+func stripSecrets(uri *url.URL) string {
+	stripped := *uri
+	query := stripped.Query()
+	for param := range query {
+		for _, secret := range SECRET_QUERY_PARAMS {
+			if strings.EqualFold(param, secret) {
+				query.Set(param, ``)
+			}
+		}
+	}
+	stripped.RawQuery = query.Encode()
+	return stripped.String()
+}
+
+func newResponse(req *http.Request, status int, contentType string, body []byte) *http.Response {
+	header := http.Header{}
+	if contentType != `` {
+		header.Set(`Content-Type`, contentType)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(status) + ` ` + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         `HTTP/1.1`,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,93 @@
+package fixtures
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+/**************************************************************************************************
+** TestRecordReplayCalls checks that the JSON-RPC calls recorded in a batch are replayed one by
+** one, with the ids of the replayed request, once the node is gone.
+**************************************************************************************************/
+func TestRecordReplayCalls(t *testing.T) {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(`Content-Type`, `application/json`)
+		io.WriteString(w, `[{"jsonrpc":"2.0","id":2,"result":"0x2"},{"jsonrpc":"2.0","id":1,"result":"0x1"}]`)
+	}))
+	dir := t.TempDir()
+	client := NewHTTPClient(`rpc.1`)
+
+	if err := Enable(MODE_RECORD, dir); err != nil {
+		t.Fatal(err)
+	}
+	defer Disable()
+	batch := `[{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]},{"jsonrpc":"2.0","id":2,"method":"eth_chainId","params":[]}]`
+	if _, err := client.Post(node.URL, `application/json`, strings.NewReader(batch)); err != nil {
+		t.Fatal(err)
+	}
+	node.Close()
+
+	if err := Enable(MODE_REPLAY, dir); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Post(REPLAY_URI, `application/json`, strings.NewReader(`{"jsonrpc":"2.0","id":7,"method":"eth_chainId","params":[]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != `{"id":7,"jsonrpc":"2.0","result":"0x2"}` {
+		t.Errorf("unexpected replayed response %s", body)
+	}
+
+	if failed := FailedCalls(); len(failed) != 0 {
+		t.Errorf("unexpected failed calls %v", failed)
+	}
+	if _, err := client.Post(REPLAY_URI, `application/json`, strings.NewReader(`{"jsonrpc":"2.0","id":8,"method":"eth_gasPrice","params":[]}`)); err == nil {
+		t.Error("expected a call never recorded to fail")
+	}
+	if failed := FailedCalls(); len(failed) != 1 || failed[0] != `rpc.1 "eth_gasPrice" []` {
+		t.Errorf("expected the call never recorded to be reported, got %v", failed)
+	}
+	ResetFailedCalls()
+	if _, err := NewHTTPClient(`rpc.10`).Post(REPLAY_URI, `application/json`, strings.NewReader(batch)); err == nil {
+		t.Error("expected the calls recorded for another chain not to be replayed")
+	}
+}
+
+/**************************************************************************************************
+** TestRecordReplayHTTP checks that the other requests are replayed by URL, the secrets of their
+** query being ignored.
+**************************************************************************************************/
+func TestRecordReplayHTTP(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"price":1.5}`)
+	}))
+	dir := t.TempDir()
+
+	if err := Enable(MODE_RECORD, dir); err != nil {
+		t.Fatal(err)
+	}
+	defer Disable()
+	if _, err := http.Get(api.URL + `/prices?apikey=secret`); err != nil {
+		t.Fatal(err)
+	}
+	api.Close()
+
+	if err := Enable(MODE_REPLAY, dir); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get(api.URL + `/prices?apikey=other`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != `{"price":1.5}` {
+		t.Errorf("unexpected replayed response %d %s", resp.StatusCode, body)
+	}
+	if _, err := http.Get(api.URL + `/other`); err == nil {
+		t.Error("expected a request never recorded to fail")
+	}
+}
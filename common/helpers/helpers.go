@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/yearn/ydaemon/common/addresses"
@@ -568,3 +569,46 @@ func SafeStringToUint64(n string, defaultValue uint64) uint64 {
 	}
 	return result
 }
+
+/**************************************************************************************************
+** Percentile returns the p-th percentile (0 to 1) of a list of values sorted in ascending order,
+** linearly interpolated between the two closest ranks. An empty list returns 0.
+**
+** @param sortedValues The values, sorted in ascending order
+** @param p The percentile to compute, between 0 (min) and 1 (max)
+** @return float64 The interpolated percentile
+**************************************************************************************************/
+func Percentile(sortedValues []float64, p float64) float64 {
+	if len(sortedValues) == 0 {
+		return 0
+	}
+	rank := math.Max(0, math.Min(1, p)) * float64(len(sortedValues)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	weight := rank - float64(lower)
+	return sortedValues[lower]*(1-weight) + sortedValues[upper]*weight
+}
+
+/**************************************************************************************************
+** ToSnakeCase converts a camelCase identifier to snake_case, the acronyms being kept as one word:
+** `pricePerShare` becomes `price_per_share`, `chainID` becomes `chain_id` and `netAPRType`
+** becomes `net_apr_type`.
+**
+** @param s The camelCase identifier
+** @return string The snake_case identifier
+**************************************************************************************************/
+func ToSnakeCase(s string) string {
+	runes := []rune(s)
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				builder.WriteRune('_')
+			}
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+	return builder.String()
+}
@@ -1,6 +1,7 @@
 package helpers
 
 import (
+	"math"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -376,6 +377,36 @@ func TestToNormalizedAmount(t *testing.T) {
 	}
 }
 
+/**************************************************************************************************
+** TestNormalize tests the Normalize function and the detection of the amounts normalized with the
+** wrong decimals. This test validates:
+** - The raw amount and the decimals are kept along with the normalized amount
+** - A nil amount is normalized to zero
+** - An 18 decimals amount normalized with 6 decimals is flagged as suspicious
+**************************************************************************************************/
+func TestNormalize(t *testing.T) {
+	supply := bigNumber.NewInt(0).SetString("50000000000000000000000000") // 50M tokens with 18 decimals
+
+	normalized := Normalize(supply, 18)
+	if normalized.Float().String() != "50000000" {
+		t.Errorf("Normalize(%s, 18) = %s, expected 50000000", supply.String(), normalized.Float().String())
+	}
+	if normalized.Decimals != 18 || normalized.Raw.String() != supply.String() {
+		t.Errorf("Normalize did not keep the raw amount and the decimals")
+	}
+	if normalized.IsSuspicious() {
+		t.Errorf("Expected 50M tokens not to be suspicious")
+	}
+
+	if Normalize(nil, 18).Float().String() != "0" {
+		t.Errorf("Expected a nil amount to be normalized to 0")
+	}
+
+	if !Normalize(supply, 6).IsSuspicious() {
+		t.Errorf("Expected an 18 decimals supply normalized with 6 decimals to be suspicious")
+	}
+}
+
 /**************************************************************************************************
 ** TestSafeString tests the SafeString function to ensure it correctly handles and sanitizes
 ** string inputs. This test validates:
@@ -880,3 +911,55 @@ func TestFetchJSONWithReject(t *testing.T) {
 		})
 	*/
 }
+
+/**************************************************************************************************
+** TestPercentile tests the Percentile function, interpolating between the closest ranks of the
+** sorted values and clamping the percentile between the min and the max.
+**************************************************************************************************/
+func TestPercentile(t *testing.T) {
+	values := []float64{0.01, 0.02, 0.03, 0.04, 0.10}
+	testCases := []struct {
+		name     string
+		values   []float64
+		p        float64
+		expected float64
+	}{
+		{name: "Min", values: values, p: 0, expected: 0.01},
+		{name: "Max", values: values, p: 1, expected: 0.10},
+		{name: "Median", values: values, p: 0.5, expected: 0.03},
+		{name: "Interpolated", values: values, p: 0.875, expected: 0.07},
+		{name: "Clamped", values: values, p: 2, expected: 0.10},
+		{name: "Single value", values: []float64{0.05}, p: 0.25, expected: 0.05},
+		{name: "Empty", values: []float64{}, p: 0.5, expected: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := Percentile(tc.values, tc.p)
+			if math.Abs(result-tc.expected) > 1e-9 {
+				t.Errorf("Percentile(%v, %v) = %v, expected %v", tc.values, tc.p, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestToSnakeCase(t *testing.T) {
+	testCases := map[string]string{
+		"address":       "address",
+		"pricePerShare": "price_per_share",
+		"chainID":       "chain_id",
+		"netAPR":        "net_apr",
+		"netAPRType":    "net_apr_type",
+		"APY":           "apy",
+		"v3":            "v3",
+		"weekAgo":       "week_ago",
+		"tvl30d":        "tvl30d",
+		"last7DaysAPY":  "last7_days_apy",
+	}
+
+	for input, expected := range testCases {
+		if result := ToSnakeCase(input); result != expected {
+			t.Errorf("ToSnakeCase(%q) = %q, expected %q", input, result, expected)
+		}
+	}
+}
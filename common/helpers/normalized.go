@@ -0,0 +1,61 @@
+package helpers
+
+import (
+	"github.com/yearn/ydaemon/common/bigNumber"
+)
+
+/**************************************************************************************************
+** MAX_PLAUSIBLE_NORMALIZED_AMOUNT is the largest normalized amount expected from a token supply,
+** a balance or a reward rate. The largest supplies are in the hundreds of trillions of tokens, so
+** a bigger amount is almost always a raw amount normalized with too few decimals (e.g. an 18
+** decimals amount normalized with 6).
+**************************************************************************************************/
+const MAX_PLAUSIBLE_NORMALIZED_AMOUNT = 1e15
+
+/**************************************************************************************************
+** TNormalized is a raw amount normalized with the decimals of its source. It keeps the raw amount
+** and the decimals used, for a wrong normalization to be detected and logged with its context
+** instead of silently propagated to the APYs and TVLs computed from it.
+**************************************************************************************************/
+type TNormalized struct {
+	Raw      *bigNumber.Int
+	Decimals uint64
+	Amount   *bigNumber.Float
+}
+
+/**************************************************************************************************
+** Normalize converts a raw amount to a TNormalized with the given decimals, which must be the
+** authoritative decimals of the token (or of the fixed point value) the amount comes from.
+**
+** @param amount The raw amount to normalize
+** @param decimals The number of decimal places of the source of the amount
+** @return TNormalized The normalized amount along with its raw amount and decimals
+**************************************************************************************************/
+func Normalize(amount *bigNumber.Int, decimals uint64) TNormalized {
+	if amount == nil {
+		amount = bigNumber.NewInt(0)
+	}
+	return TNormalized{
+		Raw:      amount,
+		Decimals: decimals,
+		Amount:   ToNormalizedAmount(amount, decimals),
+	}
+}
+
+/**************************************************************************************************
+** Float returns the normalized amount.
+**************************************************************************************************/
+func (n TNormalized) Float() *bigNumber.Float {
+	if n.Amount == nil {
+		return bigNumber.NewFloat(0)
+	}
+	return n.Amount
+}
+
+/**************************************************************************************************
+** IsSuspicious returns true when the magnitude of the normalized amount suggests that it was not
+** normalized with the decimals of its source.
+**************************************************************************************************/
+func (n TNormalized) IsSuspicious() bool {
+	return n.Float().Gt(bigNumber.NewFloat(MAX_PLAUSIBLE_NORMALIZED_AMOUNT))
+}
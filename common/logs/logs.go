@@ -27,6 +27,18 @@ func isLogLevelAtLeast(minimum string) bool {
 	return true
 }
 
+/**************************************************************************************************
+** SetLevel sets the LOG_LEVEL of the process, from DEBUG (all the logs) to ERROR (only the errors).
+** It returns false for an unknown level.
+**************************************************************************************************/
+func SetLevel(level string) bool {
+	if _, ok := levels[level]; !ok {
+		return false
+	}
+	os.Setenv("LOG_LEVEL", level)
+	return true
+}
+
 var colorGreen = color.New(color.FgGreen).Add(color.Bold).SprintFunc()
 var colorRed = color.New(color.FgRed).Add(color.Bold).SprintFunc()
 var colorYellow = color.New(color.FgYellow).Add(color.Bold).SprintFunc()
@@ -0,0 +1,217 @@
+package schema
+
+import (
+	"sort"
+	"strings"
+)
+
+/**************************************************************************************************
+** The client types are generated from the definitions of a document, sorted by name, for the
+** generated files to only change when the models do.
+**************************************************************************************************/
+const GENERATED_HEADER = `Code generated by ydaemon --process codegen. DO NOT EDIT.`
+
+var pythonKeywords = map[string]bool{
+	`False`: true, `None`: true, `True`: true, `and`: true, `as`: true, `assert`: true, `async`: true,
+	`await`: true, `break`: true, `class`: true, `continue`: true, `def`: true, `del`: true,
+	`elif`: true, `else`: true, `except`: true, `finally`: true, `for`: true, `from`: true,
+	`global`: true, `if`: true, `import`: true, `in`: true, `is`: true, `lambda`: true,
+	`nonlocal`: true, `not`: true, `or`: true, `pass`: true, `raise`: true, `return`: true,
+	`try`: true, `while`: true, `with`: true, `yield`: true,
+}
+
+func sortedKeys(properties map[string]*TSchema) []string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isRequired(s *TSchema, name string) bool {
+	for _, required := range s.Required {
+		if required == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isIdentifier(name string) bool {
+	if name == `` {
+		return false
+	}
+	for i, r := range name {
+		isLetter := r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !isLetter && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+/**************************************************************************************************
+** GenerateTypeScript returns the TypeScript interfaces of the definitions of a document. A field
+** not required is optional, and a field that can be null is typed `| null`.
+**************************************************************************************************/
+func GenerateTypeScript(document TDocument) string {
+	var builder strings.Builder
+	builder.WriteString(`// ` + GENERATED_HEADER + "\n")
+	for _, name := range sortedKeys(document.Definitions) {
+		builder.WriteString("\nexport interface " + name + " " + typeScriptObject(document.Definitions[name], ``) + "\n")
+	}
+	return builder.String()
+}
+
+func typeScriptObject(s *TSchema, indent string) string {
+	if len(s.Properties) == 0 {
+		return `{}`
+	}
+	var builder strings.Builder
+	builder.WriteString("{\n")
+	for _, key := range sortedKeys(s.Properties) {
+		field := key
+		if !isIdentifier(key) {
+			field = `"` + key + `"`
+		}
+		if !isRequired(s, key) {
+			field += `?`
+		}
+		builder.WriteString(indent + "\t" + field + `: ` + typeScriptType(s.Properties[key], indent+"\t") + ";\n")
+	}
+	builder.WriteString(indent + `}`)
+	return builder.String()
+}
+
+func typeScriptType(s *TSchema, indent string) string {
+	if s.Ref != `` {
+		return strings.TrimPrefix(s.Ref, `#/definitions/`)
+	}
+	if len(s.AnyOf) > 0 {
+		options := []string{}
+		for _, option := range s.AnyOf {
+			options = append(options, typeScriptType(option, indent))
+		}
+		return strings.Join(options, ` | `)
+	}
+	switch s.Type {
+	case `string`:
+		return `string`
+	case `integer`, `number`:
+		return `number`
+	case `boolean`:
+		return `boolean`
+	case `null`:
+		return `null`
+	case `array`:
+		item := typeScriptType(s.Items, indent)
+		if strings.Contains(item, ` | `) {
+			return `(` + item + `)[]`
+		}
+		return item + `[]`
+	case `object`:
+		if s.AdditionalProperties != nil {
+			return `Record<string, ` + typeScriptType(s.AdditionalProperties, indent) + `>`
+		}
+		return typeScriptObject(s, indent)
+	}
+	return `unknown`
+}
+
+/**************************************************************************************************
+** GeneratePython returns the TypedDict classes of the definitions of a document, for Python 3.11
+** or later. A field not required is NotRequired, and a field that can be null is Optional. The
+** classes with a field name that is not a Python identifier use the functional syntax.
+**************************************************************************************************/
+func GeneratePython(document TDocument) string {
+	var builder strings.Builder
+	builder.WriteString(`# ` + GENERATED_HEADER + "\n")
+	builder.WriteString("from __future__ import annotations\n\n")
+	builder.WriteString("from typing import Any, Dict, List, NotRequired, Optional, TypedDict, Union\n")
+	for _, name := range sortedKeys(document.Definitions) {
+		definition := document.Definitions[name]
+		builder.WriteString("\n\n")
+		if hasPythonFieldNames(definition) {
+			builder.WriteString("class " + name + "(TypedDict):\n")
+			if len(definition.Properties) == 0 {
+				builder.WriteString("    pass\n")
+			}
+			for _, key := range sortedKeys(definition.Properties) {
+				builder.WriteString(`    ` + key + `: ` + pythonField(definition, key) + "\n")
+			}
+			continue
+		}
+		builder.WriteString(name + " = TypedDict(\"" + name + "\", {\n")
+		for _, key := range sortedKeys(definition.Properties) {
+			builder.WriteString(`    "` + key + `": "` + pythonField(definition, key) + "\",\n")
+		}
+		builder.WriteString("})\n")
+	}
+	return builder.String()
+}
+
+func hasPythonFieldNames(s *TSchema) bool {
+	for key := range s.Properties {
+		if !isIdentifier(key) || strings.Contains(key, `$`) || pythonKeywords[key] {
+			return false
+		}
+	}
+	return true
+}
+
+func pythonField(s *TSchema, key string) string {
+	fieldType := pythonType(s.Properties[key])
+	if !isRequired(s, key) {
+		return `NotRequired[` + fieldType + `]`
+	}
+	return fieldType
+}
+
+func pythonType(s *TSchema) string {
+	if s.Ref != `` {
+		return strings.TrimPrefix(s.Ref, `#/definitions/`)
+	}
+	if len(s.AnyOf) > 0 {
+		options := []string{}
+		isNullable := false
+		for _, option := range s.AnyOf {
+			if option.Type == `null` {
+				isNullable = true
+				continue
+			}
+			options = append(options, pythonType(option))
+		}
+		if len(options) == 0 {
+			return `None`
+		}
+		optionsType := options[0]
+		if len(options) > 1 {
+			optionsType = `Union[` + strings.Join(options, `, `) + `]`
+		}
+		if isNullable {
+			return `Optional[` + optionsType + `]`
+		}
+		return optionsType
+	}
+	switch s.Type {
+	case `string`:
+		return `str`
+	case `integer`:
+		return `int`
+	case `number`:
+		return `float`
+	case `boolean`:
+		return `bool`
+	case `null`:
+		return `None`
+	case `array`:
+		return `List[` + pythonType(s.Items) + `]`
+	case `object`:
+		if s.AdditionalProperties != nil {
+			return `Dict[str, ` + pythonType(s.AdditionalProperties) + `]`
+		}
+		return `Dict[str, Any]`
+	}
+	return `Any`
+}
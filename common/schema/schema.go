@@ -0,0 +1,262 @@
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+)
+
+/**************************************************************************************************
+** The type definitions of the API responses are JSON Schemas (draft-07) derived from the Go models
+** by reflection, following the rules of encoding/json: the name of a field is the one of its json
+** tag, a field with omitempty is not required, an embedded struct without tag is flattened and a
+** pointer can be null. Every named struct is a definition, referenced with $ref.
+**************************************************************************************************/
+const JSON_SCHEMA_DRAFT = `http://json-schema.org/draft-07/schema#`
+
+type TSchema struct {
+	Ref                  string              `json:"$ref,omitempty"`
+	Type                 string              `json:"type,omitempty"`
+	Format               string              `json:"format,omitempty"`
+	Pattern              string              `json:"pattern,omitempty"`
+	Properties           map[string]*TSchema `json:"properties,omitempty"`
+	Required             []string            `json:"required,omitempty"`
+	Items                *TSchema            `json:"items,omitempty"`
+	AdditionalProperties *TSchema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*TSchema          `json:"anyOf,omitempty"`
+}
+
+/**************************************************************************************************
+** TDocument is a set of definitions. When Ref is set, the document is the schema of that
+** definition, the other ones being the types it references.
+**************************************************************************************************/
+type TDocument struct {
+	Schema      string              `json:"$schema"`
+	Ref         string              `json:"$ref,omitempty"`
+	Definitions map[string]*TSchema `json:"definitions"`
+}
+
+/**************************************************************************************************
+** The types encoding themselves (json.Marshaler, encoding.TextMarshaler) can't be reflected. The
+** ones of the models are described here, the other ones being strings when they implement
+** encoding.TextMarshaler and anything otherwise.
+**************************************************************************************************/
+var knownTypes = map[reflect.Type]TSchema{
+	reflect.TypeOf(common.Address{}):     {Type: `string`, Pattern: `^0x[0-9a-fA-F]{40}$`},
+	reflect.TypeOf(common.Hash{}):        {Type: `string`, Pattern: `^0x[0-9a-fA-F]{64}$`},
+	reflect.TypeOf(bigNumber.Int{}):      {Type: `string`, Pattern: `^-?[0-9]+$`},
+	reflect.TypeOf(bigNumber.Float{}):    {Type: `number`},
+	reflect.TypeOf(time.Time{}):          {Type: `string`, Format: `date-time`},
+	reflect.TypeOf(json.RawMessage{}):    {},
+	reflect.TypeOf(json.Number(``)):      {Type: `number`},
+	reflect.TypeOf((*error)(nil)).Elem(): {},
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+/**************************************************************************************************
+** TGenerator builds the definitions of a set of types. The name of a definition is the name of its
+** Go type, prefixed with its package when two packages use the same name.
+**************************************************************************************************/
+type TGenerator struct {
+	definitions map[string]*TSchema
+	names       map[reflect.Type]string
+}
+
+func NewGenerator() *TGenerator {
+	return &TGenerator{
+		definitions: make(map[string]*TSchema),
+		names:       make(map[reflect.Type]string),
+	}
+}
+
+/**************************************************************************************************
+** Add adds the definition of the type of a value, and of the types it references, and returns its
+** name.
+**************************************************************************************************/
+func (g *TGenerator) Add(value interface{}) string {
+	t := reflect.TypeOf(value)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return g.define(t)
+}
+
+/**************************************************************************************************
+** Document returns the document of all the definitions added, or the one of a definition and of
+** the types it references when ref is set.
+**************************************************************************************************/
+func (g *TGenerator) Document(ref string) (TDocument, bool) {
+	document := TDocument{Schema: JSON_SCHEMA_DRAFT, Definitions: make(map[string]*TSchema)}
+	if ref == `` {
+		for name, definition := range g.definitions {
+			document.Definitions[name] = definition
+		}
+		return document, true
+	}
+	if _, ok := g.definitions[ref]; !ok {
+		return document, false
+	}
+	document.Ref = `#/definitions/` + ref
+	g.collect(ref, document.Definitions)
+	return document, true
+}
+
+/**************************************************************************************************
+** Names returns the names of all the definitions added, sorted.
+**************************************************************************************************/
+func (g *TGenerator) Names() []string {
+	names := make([]string, 0, len(g.definitions))
+	for name := range g.definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (g *TGenerator) collect(name string, definitions map[string]*TSchema) {
+	if _, ok := definitions[name]; ok {
+		return
+	}
+	definition := g.definitions[name]
+	definitions[name] = definition
+	for _, ref := range listRefs(definition) {
+		g.collect(ref, definitions)
+	}
+}
+
+func listRefs(s *TSchema) []string {
+	if s == nil {
+		return nil
+	}
+	refs := []string{}
+	if s.Ref != `` {
+		refs = append(refs, strings.TrimPrefix(s.Ref, `#/definitions/`))
+	}
+	for _, property := range s.Properties {
+		refs = append(refs, listRefs(property)...)
+	}
+	for _, option := range s.AnyOf {
+		refs = append(refs, listRefs(option)...)
+	}
+	refs = append(refs, listRefs(s.Items)...)
+	return append(refs, listRefs(s.AdditionalProperties)...)
+}
+
+/**************************************************************************************************
+** define adds the definition of a named struct and returns its name. The definition is reserved
+** before its fields are reflected, for the recursive types to reference themselves.
+**************************************************************************************************/
+func (g *TGenerator) define(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := g.definitions[name]; taken {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), `/`)+1:]
+		name = pkg + `_` + name
+	}
+	g.names[t] = name
+	g.definitions[name] = &TSchema{}
+	*g.definitions[name] = g.object(t)
+	return name
+}
+
+func (g *TGenerator) reflect(t reflect.Type) *TSchema {
+	if known, ok := knownTypes[t]; ok {
+		return &known
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return &TSchema{AnyOf: []*TSchema{g.reflect(t.Elem()), {Type: `null`}}}
+	case reflect.Interface:
+		return &TSchema{}
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return &TSchema{}
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return &TSchema{Type: `string`}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &TSchema{Type: `boolean`}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &TSchema{Type: `integer`}
+	case reflect.Float32, reflect.Float64:
+		return &TSchema{Type: `number`}
+	case reflect.String:
+		return &TSchema{Type: `string`}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &TSchema{Type: `string`} // Encoded in base64
+		}
+		return &TSchema{Type: `array`, Items: g.reflect(t.Elem())}
+	case reflect.Map:
+		return &TSchema{Type: `object`, AdditionalProperties: g.reflect(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == `` {
+			object := g.object(t)
+			return &object
+		}
+		return &TSchema{Ref: `#/definitions/` + g.define(t)}
+	}
+	return &TSchema{}
+}
+
+/**************************************************************************************************
+** object reflects the fields of a struct as the properties of an object.
+**************************************************************************************************/
+func (g *TGenerator) object(t reflect.Type) TSchema {
+	object := TSchema{Type: `object`, Properties: make(map[string]*TSchema)}
+	g.addFields(t, &object)
+	sort.Strings(object.Required)
+	return object
+}
+
+func (g *TGenerator) addFields(t reflect.Type, object *TSchema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get(`json`)
+		if tag == `-` {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, `,`)
+		fieldType := field.Type
+		if field.Anonymous && name == `` {
+			for fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				g.addFields(fieldType, object)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == `` {
+			name = field.Name
+		}
+
+		property := g.reflect(field.Type)
+		if strings.Contains(options, `string`) {
+			property = &TSchema{Type: `string`}
+		}
+		object.Properties[name] = property
+		if !strings.Contains(options, `omitempty`) {
+			object.Required = append(object.Required, name)
+		}
+	}
+}
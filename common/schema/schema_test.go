@@ -0,0 +1,100 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+)
+
+type tTestEmbedded struct {
+	Kind string `json:"kind"`
+}
+
+type tTestChild struct {
+	Value *bigNumber.Float `json:"value"`
+	Child *tTestChild      `json:"child,omitempty"`
+}
+
+type tTestModel struct {
+	tTestEmbedded
+	Address  common.Address        `json:"address"`
+	Balance  *bigNumber.Int        `json:"balance,omitempty"`
+	Children []tTestChild          `json:"children"`
+	Labels   map[string]string     `json:"labels"`
+	From     uint64                `json:"from,string"`
+	Hidden   string                `json:"-"`
+	Extra    map[string]tTestChild `json:"extra,omitempty"`
+}
+
+func TestGenerator(t *testing.T) {
+	generator := NewGenerator()
+	if name := generator.Add(&tTestModel{}); name != `tTestModel` {
+		t.Fatalf("expected tTestModel, got %s", name)
+	}
+	document, ok := generator.Document(`tTestModel`)
+	if !ok {
+		t.Fatal("expected the definition to be found")
+	}
+	if document.Ref != `#/definitions/tTestModel` || len(document.Definitions) != 2 {
+		t.Fatalf("unexpected document %+v", document)
+	}
+
+	model := document.Definitions[`tTestModel`]
+	if strings.Join(model.Required, `,`) != `address,children,from,kind,labels` {
+		t.Errorf("unexpected required fields %v", model.Required)
+	}
+	if _, ok := model.Properties[`Hidden`]; ok {
+		t.Error("expected the ignored field to be skipped")
+	}
+	if model.Properties[`address`].Pattern == `` || model.Properties[`from`].Type != `string` {
+		t.Error("expected the address and the from fields to be patterned strings")
+	}
+	if balance := model.Properties[`balance`]; len(balance.AnyOf) != 2 || balance.AnyOf[1].Type != `null` {
+		t.Errorf("expected the pointer to be nullable, got %+v", balance)
+	}
+	if children := model.Properties[`children`]; children.Items.Ref != `#/definitions/tTestChild` {
+		t.Errorf("expected the children to reference tTestChild, got %+v", children.Items)
+	}
+	if _, ok := generator.Document(`unknown`); ok {
+		t.Error("expected an unknown definition not to be found")
+	}
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	generator := NewGenerator()
+	generator.Add(tTestModel{})
+	document, _ := generator.Document(``)
+	generated := GenerateTypeScript(document)
+	for _, expected := range []string{
+		"export interface tTestChild {",
+		"\tchild?: tTestChild | null;",
+		"\tchildren: tTestChild[];",
+		"\tlabels: Record<string, string>;",
+		"\tvalue: number | null;",
+	} {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("expected %q in\n%s", expected, generated)
+		}
+	}
+}
+
+func TestGeneratePython(t *testing.T) {
+	generator := NewGenerator()
+	generator.Add(tTestModel{})
+	document, _ := generator.Document(``)
+	generated := GeneratePython(document)
+	for _, expected := range []string{
+		"class tTestChild(TypedDict):",
+		"    child: NotRequired[Optional[tTestChild]]",
+		`    "children": "List[tTestChild]",`,
+		"    value: Optional[float]",
+		`tTestModel = TypedDict("tTestModel", {`,
+		`    "from": "str",`,
+	} {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("expected %q in\n%s", expected, generated)
+		}
+	}
+}
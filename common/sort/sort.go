@@ -1,6 +1,7 @@
 package sort
 
 import (
+	"math/big"
 	"reflect"
 	"sort"
 	"strings"
@@ -8,6 +9,27 @@ import (
 	"github.com/yearn/ydaemon/common/logs"
 )
 
+/**************************************************************************************************
+** floatLike matches the arbitrary precision floats (big.Float, bigNumber.Float) so they can be
+** used as sorting keys.
+**************************************************************************************************/
+type floatLike interface {
+	Float64() (float64, big.Accuracy)
+}
+
+var floatLikeType = reflect.TypeOf((*floatLike)(nil)).Elem()
+
+/**************************************************************************************************
+** toFloat64 converts a floatLike value to a float64. A nil pointer is considered as 0.
+**************************************************************************************************/
+func toFloat64(v reflect.Value) float64 {
+	if !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return 0
+	}
+	value, _ := v.Interface().(floatLike).Float64()
+	return value
+}
+
 func SortBy[T any](jsonField string, sortOrder string, arr []T) {
 	if len(arr) == 0 {
 		return
@@ -66,6 +88,13 @@ func SortBy[T any](jsonField string, sortOrder string, arr []T) {
 			lastField = field
 		}
 
+		if lastField.Type.Kind() == reflect.Pointer && lastField.Type.Implements(floatLikeType) {
+			if sortOrder == "asc" {
+				return toFloat64(v1) < toFloat64(v2)
+			}
+			return toFloat64(v1) > toFloat64(v2)
+		}
+
 		switch lastField.Type.Name() {
 		case "int", "int8", "int16", "int32", "int64":
 			if sortOrder == "asc" {
@@ -1,6 +1,8 @@
 package sort
 
 import (
+	"math/big"
+	"reflect"
 	"strconv"
 	"testing"
 
@@ -135,3 +137,26 @@ func TestSortBy(t *testing.T) {
 		assert.Equal(t, sortedData[7].Nested.Nested.Uint, sortedData[7].Nested.Nested.Uint)
 	}
 }
+
+type TStructBigFloat struct {
+	Value *big.Float `json:"value"`
+}
+
+func TestSortByBigFloat(t *testing.T) {
+	sortedData := []TStructBigFloat{
+		{Value: big.NewFloat(0.05)},
+		{Value: nil},
+		{Value: big.NewFloat(0.2)},
+		{Value: big.NewFloat(-0.01)},
+	}
+
+	SortBy("value", "desc", sortedData)
+	assert.Equal(t, 0.2, toFloat64(reflect.ValueOf(sortedData[0].Value)))
+	assert.Equal(t, 0.05, toFloat64(reflect.ValueOf(sortedData[1].Value)))
+	assert.Nil(t, sortedData[2].Value)
+	assert.Equal(t, -0.01, toFloat64(reflect.ValueOf(sortedData[3].Value)))
+
+	SortBy("value", "asc", sortedData)
+	assert.Equal(t, -0.01, toFloat64(reflect.ValueOf(sortedData[0].Value)))
+	assert.Equal(t, 0.2, toFloat64(reflect.ValueOf(sortedData[3].Value)))
+}
@@ -0,0 +1,144 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+/**************************************************************************************************
+** The tracing is enabled by setting the standard OTLP environment variables, at least
+** OTEL_EXPORTER_OTLP_ENDPOINT (or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT). The spans are then exported
+** over OTLP/HTTP. Without them, the global tracer is a no-op and the instrumentation is free.
+**************************************************************************************************/
+const TRACER_NAME = `github.com/yearn/ydaemon`
+
+/**************************************************************************************************
+** The attributes set on the spans of the refresh pipeline, to attribute a slow refresh to a chain
+** and a stage.
+**************************************************************************************************/
+const (
+	ATTRIBUTE_CHAIN_ID = attribute.Key(`ydaemon.chain_id`)
+	ATTRIBUTE_STAGE    = attribute.Key(`ydaemon.stage`)
+)
+
+var isEnabled = false
+
+/**************************************************************************************************
+** IsEnabled returns true once the tracer provider exporting to the OTLP endpoint is initialized.
+**************************************************************************************************/
+func IsEnabled() bool {
+	return isEnabled
+}
+
+/**************************************************************************************************
+** Initialize sets up the global tracer provider exporting to the configured OTLP endpoint, and the
+** W3C trace context propagation used for the incoming and outgoing HTTP requests. It returns the
+** function flushing the pending spans on shutdown.
+**
+** @param serviceName string - The name of the service reported with the spans
+** @param serviceVersion string - The version of the service reported with the spans
+** @return func(context.Context) error - The shutdown function, a no-op if tracing is disabled
+** @return error - An error if the exporter could not be created
+**************************************************************************************************/
+func Initialize(serviceName string, serviceVersion string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if os.Getenv(`OTEL_EXPORTER_OTLP_ENDPOINT`) == `` && os.Getenv(`OTEL_EXPORTER_OTLP_TRACES_ENDPOINT`) == `` {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(context.Background())
+	if err != nil {
+		return func(context.Context) error { return nil }, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion(serviceVersion),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	isEnabled = true
+	return provider.Shutdown, nil
+}
+
+/**************************************************************************************************
+** StartStage starts the span of a stage of the refresh pipeline of a chain. The span is a child of
+** the span carried by the context, if any.
+**
+** @param ctx context.Context - The context carrying the parent span
+** @param chainID uint64 - The chain being refreshed
+** @param stage string - The name of the stage (e.g. `pricing`)
+** @return context.Context - The context carrying the new span
+** @return trace.Span - The new span, to end once the stage is done
+**************************************************************************************************/
+func StartStage(ctx context.Context, chainID uint64, stage string) (context.Context, trace.Span) {
+	return otel.Tracer(TRACER_NAME).Start(ctx, stage, trace.WithAttributes(
+		ATTRIBUTE_CHAIN_ID.Int64(int64(chainID)),
+		ATTRIBUTE_STAGE.String(stage),
+	))
+}
+
+/**************************************************************************************************
+** Middleware creates a server span for every HTTP request, continuing the trace of the caller
+** when the request carries a `traceparent` header. The span is named after the route template
+** (e.g. `GET /:chainID/vaults/:address`) to keep the cardinality low, and the context of the
+** request carries the span for the handlers.
+**************************************************************************************************/
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsEnabled() {
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		if route == `` {
+			route = `unmatched`
+		}
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := otel.Tracer(TRACER_NAME).Start(ctx, c.Request.Method+` `+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+			),
+		)
+		defer span.End()
+		if chainID, err := strconv.ParseUint(c.Param(`chainID`), 10, 64); err == nil {
+			span.SetAttributes(ATTRIBUTE_CHAIN_ID.Int64(int64(chainID)))
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, strconv.Itoa(status))
+		}
+	}
+}
+
+/**************************************************************************************************
+** InjectHeaders adds the trace context of the context to the headers of an outgoing request, for
+** the downstream service to continue the trace.
+**************************************************************************************************/
+func InjectHeaders(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+/**************************************************************************************************
+** setupRecorder installs a tracer provider recording the spans in memory.
+**************************************************************************************************/
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	isEnabled = true
+	t.Cleanup(func() { isEnabled = false })
+	return recorder
+}
+
+/**************************************************************************************************
+** TestMiddleware tests that a request creates a span named after the route template, continuing
+** the trace of the caller and carrying the chain ID and the status of the response.
+**************************************************************************************************/
+func TestMiddleware(t *testing.T) {
+	recorder := setupRecorder(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET(`/:chainID/vaults/:address`, func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(`GET`, `/1/vaults/0x16388463d60ffe0661cf7f1f31a7d658ac790ff7`, nil)
+	req.Header.Set(`traceparent`, `00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01`)
+	router.ServeHTTP(w, req)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, `GET /:chainID/vaults/:address`, spans[0].Name())
+	assert.Equal(t, `4bf92f3577b34da6a3ce929d0e0e4736`, spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, `00f067aa0ba902b7`, spans[0].Parent().SpanID().String())
+
+	attributes := map[string]interface{}{}
+	for _, attribute := range spans[0].Attributes() {
+		attributes[string(attribute.Key)] = attribute.Value.AsInterface()
+	}
+	assert.Equal(t, int64(1), attributes[string(ATTRIBUTE_CHAIN_ID)])
+	assert.Equal(t, int64(http.StatusInternalServerError), attributes[`http.response.status_code`])
+}
+
+/**************************************************************************************************
+** TestStartStage tests that the stages of a refresh are children of the span of the refresh.
+**************************************************************************************************/
+func TestStartStage(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	ctx, refresh := StartStage(context.Background(), 10, `refresh`)
+	_, pricing := StartStage(ctx, 10, `pricing`)
+	pricing.End()
+	refresh.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, `pricing`, spans[0].Name())
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, spans[1].SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+}
@@ -0,0 +1,265 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"net"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+	pb "github.com/yearn/ydaemon/external/grpcapi/ydaemonv1"
+	"github.com/yearn/ydaemon/internal/fetcher"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/apr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+/**************************************************************************************************
+** The gRPC API serves the vaults, the tokens and the prices of the stores to the internal Yearn
+** services, typed by the protobuf schemas of ydaemonv1/ydaemon.proto, on GRPC_PORT. The requests
+** must carry an `authorization: Bearer <GRPC_API_KEY>` metadata when the key is set.
+**************************************************************************************************/
+type TServer struct {
+	pb.UnimplementedYDaemonServer
+}
+
+/**************************************************************************************************
+** Serve listens on the port and serves the gRPC API until the listener fails.
+**************************************************************************************************/
+func Serve(port string) error {
+	listener, err := net.Listen(`tcp`, `:`+port)
+	if err != nil {
+		return err
+	}
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(authorizeUnary),
+		grpc.StreamInterceptor(authorizeStream),
+	)
+	pb.RegisterYDaemonServer(server, &TServer{})
+	logs.Info(`Serving the gRPC API on port ` + port)
+	return server.Serve(listener)
+}
+
+/**************************************************************************************************
+** authorize checks the bearer token of a request against GRPC_API_KEY, when it is set.
+**************************************************************************************************/
+func authorize(ctx context.Context) error {
+	if env.GRPC_API_KEY == `` {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get(`authorization`) {
+		token := strings.TrimPrefix(value, `Bearer `)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(env.GRPC_API_KEY)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, `a valid bearer token is required`)
+}
+
+func authorizeUnary(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := authorize(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func authorizeStream(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := authorize(stream.Context()); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
+
+/**************************************************************************************************
+** assertChainID and assertAddress validate the chain and the address of a request, like the
+** parameters of the REST routes.
+**************************************************************************************************/
+func assertChainID(chainID uint64) error {
+	if _, ok := env.GetChain(chainID); !ok {
+		return status.Errorf(codes.InvalidArgument, `unsupported chain %d`, chainID)
+	}
+	return nil
+}
+
+func assertAddress(address string) (common.Address, error) {
+	if !common.IsHexAddress(address) {
+		return common.Address{}, status.Errorf(codes.InvalidArgument, `invalid address %s`, address)
+	}
+	return common.HexToAddress(address), nil
+}
+
+/**************************************************************************************************
+** toVault converts a vault of the store to its protobuf message, with its TVL, its APYs and its
+** strategies.
+**************************************************************************************************/
+func toVault(vault models.TVault) *pb.Vault {
+	message := &pb.Vault{
+		ChainId:      vault.ChainID,
+		Address:      vault.Address.Hex(),
+		Version:      vault.Version,
+		Kind:         string(vault.Kind),
+		Type:         string(vault.Type),
+		AssetAddress: vault.AssetAddress.Hex(),
+		Endorsed:     vault.Endorsed,
+		Retired:      models.IsVaultRetired(vault),
+		Strategies:   []string{},
+	}
+	if token, ok := storage.GetERC20(vault.ChainID, vault.Address); ok {
+		message.Name = helpers.SafeString(token.DisplayName, token.Name)
+		message.Symbol = helpers.SafeString(token.DisplaySymbol, token.Symbol)
+	}
+	tvl := fetcher.BuildVaultTVL(vault)
+	message.Tvl = tvl.TVL
+	if tvl.TotalAssets != nil {
+		message.TotalAssets = tvl.TotalAssets.String()
+	}
+	if vault.LastPricePerShare != nil {
+		message.PricePerShare = vault.LastPricePerShare.String()
+	}
+	if computedAPY, ok := apr.GetComputedAPY(vault.ChainID, vault.Address); ok {
+		if vaultAPY, ok := computedAPY.(apr.TVaultAPY); ok {
+			if vaultAPY.NetAPY != nil {
+				netAPY, _ := vaultAPY.NetAPY.Float64()
+				message.NetApy = &netAPY
+			}
+			if vaultAPY.ForwardAPY.NetAPY != nil && vaultAPY.ForwardAPY.Type != `` {
+				forwardAPY, _ := vaultAPY.ForwardAPY.NetAPY.Float64()
+				message.ForwardApy = &forwardAPY
+			}
+		}
+	}
+	_, strategies := storage.ListStrategiesForVault(vault.ChainID, vault.Address)
+	for _, strategy := range strategies {
+		message.Strategies = append(message.Strategies, strategy.Address.Hex())
+	}
+	if version, ok := storage.GetVaultVersion(vault.ChainID, vault.Address); ok {
+		message.VersionChanged = version
+	}
+	return message
+}
+
+func toToken(token models.TERC20Token) *pb.Token {
+	message := &pb.Token{
+		ChainId:          token.ChainID,
+		Address:          token.Address.Hex(),
+		Name:             token.Name,
+		Symbol:           token.Symbol,
+		Decimals:         token.Decimals,
+		Type:             string(token.Type),
+		Category:         token.Category,
+		Icon:             token.Icon,
+		UnderlyingTokens: []string{},
+	}
+	for _, underlying := range token.UnderlyingTokensAddresses {
+		message.UnderlyingTokens = append(message.UnderlyingTokens, underlying.Hex())
+	}
+	return message
+}
+
+func toPrice(chainID uint64, price models.TPrices) *pb.Price {
+	message := &pb.Price{
+		ChainId: chainID,
+		Address: price.Address.Hex(),
+		Source:  price.Source,
+		Stale:   price.IsStale,
+	}
+	if price.HumanizedPrice != nil {
+		message.Price, _ = price.HumanizedPrice.Float64()
+	}
+	if price.Price != nil {
+		message.RawPrice = price.Price.String()
+	}
+	return message
+}
+
+func (s *TServer) GetVault(_ context.Context, req *pb.GetVaultRequest) (*pb.Vault, error) {
+	if err := assertChainID(req.GetChainId()); err != nil {
+		return nil, err
+	}
+	address, err := assertAddress(req.GetAddress())
+	if err != nil {
+		return nil, err
+	}
+	vault, ok := storage.GetVault(req.GetChainId(), address)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, `vault %s not found`, address.Hex())
+	}
+	return toVault(vault), nil
+}
+
+func (s *TServer) ListVaults(_ context.Context, req *pb.ListVaultsRequest) (*pb.ListVaultsResponse, error) {
+	if err := assertChainID(req.GetChainId()); err != nil {
+		return nil, err
+	}
+	_, vaults := storage.ListVaults(req.GetChainId())
+	response := &pb.ListVaultsResponse{
+		Vaults:  make([]*pb.Vault, 0, len(vaults)),
+		Version: storage.GetChainVersion(req.GetChainId()),
+	}
+	for _, vault := range vaults {
+		response.Vaults = append(response.Vaults, toVault(vault))
+	}
+	return response, nil
+}
+
+func (s *TServer) GetToken(_ context.Context, req *pb.GetTokenRequest) (*pb.Token, error) {
+	if err := assertChainID(req.GetChainId()); err != nil {
+		return nil, err
+	}
+	address, err := assertAddress(req.GetAddress())
+	if err != nil {
+		return nil, err
+	}
+	token, ok := storage.GetERC20(req.GetChainId(), address)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, `token %s not found`, address.Hex())
+	}
+	return toToken(token), nil
+}
+
+func (s *TServer) ListTokens(_ context.Context, req *pb.ListTokensRequest) (*pb.ListTokensResponse, error) {
+	if err := assertChainID(req.GetChainId()); err != nil {
+		return nil, err
+	}
+	_, tokens := storage.ListERC20(req.GetChainId())
+	response := &pb.ListTokensResponse{Tokens: make([]*pb.Token, 0, len(tokens))}
+	for _, token := range tokens {
+		response.Tokens = append(response.Tokens, toToken(token))
+	}
+	return response, nil
+}
+
+func (s *TServer) GetPrice(_ context.Context, req *pb.GetPriceRequest) (*pb.Price, error) {
+	if err := assertChainID(req.GetChainId()); err != nil {
+		return nil, err
+	}
+	address, err := assertAddress(req.GetAddress())
+	if err != nil {
+		return nil, err
+	}
+	price, ok := storage.GetPrice(req.GetChainId(), address)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, `price of %s not found`, address.Hex())
+	}
+	return toPrice(req.GetChainId(), price), nil
+}
+
+func (s *TServer) ListPrices(_ context.Context, req *pb.ListPricesRequest) (*pb.ListPricesResponse, error) {
+	if err := assertChainID(req.GetChainId()); err != nil {
+		return nil, err
+	}
+	_, prices := storage.ListPrices(req.GetChainId())
+	response := &pb.ListPricesResponse{Prices: make([]*pb.Price, 0, len(prices))}
+	for _, price := range prices {
+		response.Prices = append(response.Prices, toPrice(req.GetChainId(), price))
+	}
+	return response, nil
+}
@@ -0,0 +1,106 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/yearn/ydaemon/common/env"
+	pb "github.com/yearn/ydaemon/external/grpcapi/ydaemonv1"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+/**************************************************************************************************
+** newTestClient serves the gRPC API in memory and returns a client connected to it.
+**************************************************************************************************/
+func newTestClient(t *testing.T) pb.YDaemonClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(authorizeUnary),
+		grpc.StreamInterceptor(authorizeStream),
+	)
+	pb.RegisterYDaemonServer(server, &TServer{})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(`passthrough:///bufnet`,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewYDaemonClient(conn)
+}
+
+/**************************************************************************************************
+** TestGetVaultErrors tests that an unsupported chain and an invalid address are invalid arguments,
+** and that an unknown vault is not found.
+**************************************************************************************************/
+func TestGetVaultErrors(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	_, err := client.GetVault(ctx, &pb.GetVaultRequest{ChainId: 123456789, Address: common.Address{}.Hex()})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.GetVault(ctx, &pb.GetVaultRequest{ChainId: 1, Address: `0x123`})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.GetVault(ctx, &pb.GetVaultRequest{ChainId: 1, Address: `0x000000000000000000000000000000000000dEaD`})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+/**************************************************************************************************
+** TestAuthorization tests that the requests need the bearer token when GRPC_API_KEY is set.
+**************************************************************************************************/
+func TestAuthorization(t *testing.T) {
+	client := newTestClient(t)
+	env.GRPC_API_KEY = `secret`
+	defer func() { env.GRPC_API_KEY = `` }()
+
+	_, err := client.ListPrices(context.Background(), &pb.ListPricesRequest{ChainId: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), `authorization`, `Bearer secret`)
+	_, err = client.ListPrices(ctx, &pb.ListPricesRequest{ChainId: 1})
+	assert.NoError(t, err)
+}
+
+/**************************************************************************************************
+** TestWatchVaults tests that a stream receives the current state of its vault, then the vault again
+** when a snapshot changes it, and not the other vaults.
+**************************************************************************************************/
+func TestWatchVaults(t *testing.T) {
+	client := newTestClient(t)
+	watchedVault := common.HexToAddress(`0x00000000000000000000000000000000000A11CE`)
+	otherVault := common.HexToAddress(`0x0000000000000000000000000000000000000B0B`)
+	storage.StoreVault(1, models.TVault{ChainID: 1, Address: watchedVault, Version: `3.0.2`})
+	storage.StoreVault(1, models.TVault{ChainID: 1, Address: otherVault, Version: `3.0.2`})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.WatchVaults(ctx, &pb.WatchVaultsRequest{ChainId: 1, Addresses: []string{watchedVault.Hex()}})
+	assert.NoError(t, err)
+
+	vault, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, watchedVault.Hex(), vault.GetAddress())
+
+	NotifyVaultsChanged(1, 2, []common.Address{otherVault, watchedVault})
+	vault, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, watchedVault.Hex(), vault.GetAddress())
+	assert.Equal(t, `3.0.2`, vault.GetVersion())
+}
@@ -0,0 +1,119 @@
+package grpcapi
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	pb "github.com/yearn/ydaemon/external/grpcapi/ydaemonv1"
+	"github.com/yearn/ydaemon/internal/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+/**************************************************************************************************
+** The streams of WatchVaults are fed by the snapshots bumping the store version of their chain,
+** with the vaults changed by them. A stream not reading its updates fast enough, with
+** WATCH_BUFFER_SIZE snapshots pending, is closed with RESOURCE_EXHAUSTED rather than blocking the
+** snapshots: the client reconnects and receives the current state of its vaults again.
+**************************************************************************************************/
+const WATCH_BUFFER_SIZE = 16
+
+var _watchersMtx sync.Mutex
+var _watchers = make(map[uint64]map[chan []common.Address]struct{})
+
+/**************************************************************************************************
+** NotifyVaultsChanged sends the vaults changed by a snapshot to the streams watching the chain.
+**************************************************************************************************/
+func NotifyVaultsChanged(chainID uint64, version uint64, changedVaults []common.Address) {
+	if len(changedVaults) == 0 {
+		return
+	}
+	_watchersMtx.Lock()
+	defer _watchersMtx.Unlock()
+	for watcher := range _watchers[chainID] {
+		select {
+		case watcher <- changedVaults:
+		default:
+			delete(_watchers[chainID], watcher)
+			close(watcher)
+		}
+	}
+}
+
+func subscribe(chainID uint64) chan []common.Address {
+	_watchersMtx.Lock()
+	defer _watchersMtx.Unlock()
+	watcher := make(chan []common.Address, WATCH_BUFFER_SIZE)
+	if _watchers[chainID] == nil {
+		_watchers[chainID] = make(map[chan []common.Address]struct{})
+	}
+	_watchers[chainID][watcher] = struct{}{}
+	return watcher
+}
+
+func unsubscribe(chainID uint64, watcher chan []common.Address) {
+	_watchersMtx.Lock()
+	defer _watchersMtx.Unlock()
+	if _, ok := _watchers[chainID][watcher]; ok {
+		delete(_watchers[chainID], watcher)
+		close(watcher)
+	}
+}
+
+/**************************************************************************************************
+** WatchVaults sends the watched vaults, then each of them again when a snapshot changes it. The
+** stream is subscribed before sending the current state, so no change is missed in between.
+**************************************************************************************************/
+func (s *TServer) WatchVaults(req *pb.WatchVaultsRequest, stream pb.YDaemon_WatchVaultsServer) error {
+	chainID := req.GetChainId()
+	if err := assertChainID(chainID); err != nil {
+		return err
+	}
+	watched := make(map[common.Address]bool)
+	for _, address := range req.GetAddresses() {
+		vaultAddress, err := assertAddress(address)
+		if err != nil {
+			return err
+		}
+		watched[vaultAddress] = true
+	}
+	isWatched := func(vaultAddress common.Address) bool {
+		return len(watched) == 0 || watched[vaultAddress]
+	}
+
+	watcher := subscribe(chainID)
+	defer unsubscribe(chainID, watcher)
+
+	_, vaults := storage.ListVaults(chainID)
+	for _, vault := range vaults {
+		if !isWatched(vault.Address) {
+			continue
+		}
+		if err := stream.Send(toVault(vault)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case changedVaults, ok := <-watcher:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, `the stream did not keep up with the updates`)
+			}
+			for _, vaultAddress := range changedVaults {
+				if !isWatched(vaultAddress) {
+					continue
+				}
+				vault, ok := storage.GetVault(chainID, vaultAddress)
+				if !ok {
+					continue
+				}
+				if err := stream.Send(toVault(vault)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
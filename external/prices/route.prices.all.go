@@ -1,13 +1,12 @@
 package prices
 
 import (
-	"net/http"
-
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
 	"github.com/yearn/ydaemon/common/bigNumber"
 	"github.com/yearn/ydaemon/common/env"
 	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/external/utils"
 	"github.com/yearn/ydaemon/internal/models"
 	"github.com/yearn/ydaemon/internal/storage"
 )
@@ -87,7 +86,7 @@ func (y Controller) GetAllPrices(c *gin.Context) {
 func (y Controller) GetPrices(c *gin.Context) {
 	chainID, ok := helpers.AssertChainID(c.Param("chainID"))
 	if !ok {
-		c.String(http.StatusBadRequest, "invalid chainID")
+		utils.SendChainIDError(c, c.Param("chainID"))
 		return
 	}
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/external/utils"
 	"github.com/yearn/ydaemon/internal/storage"
 )
 
@@ -27,7 +28,7 @@ import (
 func (y Controller) GetAllPricesWithDetails(c *gin.Context) {
 	chainID, ok := helpers.AssertChainID(c.Param("chainID"))
 	if !ok {
-		c.String(http.StatusBadRequest, "invalid chainID")
+		utils.SendChainIDError(c, c.Param("chainID"))
 		return
 	}
 
@@ -43,8 +44,11 @@ func (y Controller) GetAllPricesWithDetails(c *gin.Context) {
 			"humanizedPrice": priceDetail.HumanizedPrice,
 		}
 
-		// Add additional fields if the model has them (timestamp, source, etc.)
-		// This approach avoids accessing fields that might not exist
+		// Flag the last known prices carried over while no source prices the token
+		if priceDetail.IsStale {
+			details["isStale"] = true
+			details["staleSince"] = priceDetail.StaleSince
+		}
 
 		result[addr.Hex()] = details
 	}
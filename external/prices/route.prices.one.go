@@ -1,12 +1,12 @@
 package prices
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yearn/ydaemon/common/addresses"
 	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/external/utils"
 	"github.com/yearn/ydaemon/internal/storage"
 )
 
@@ -30,21 +30,21 @@ func (y Controller) GetOnePrice(c *gin.Context) {
 	// Validate chain ID
 	chainID, ok := helpers.AssertChainID(c.Param("chainID"))
 	if !ok {
-		c.String(http.StatusBadRequest, "invalid chainID")
+		utils.SendChainIDError(c, c.Param("chainID"))
 		return
 	}
 
 	// Validate token address
 	address, ok := helpers.AssertAddress(c.Param("address"), chainID)
 	if !ok {
-		c.String(http.StatusBadRequest, "invalid address")
+		utils.SendError(c, utils.NewError(utils.ERROR_INVALID_ADDRESS, "invalid address"))
 		return
 	}
 
 	// Get price data
 	price, ok := storage.GetPrice(chainID, address)
 	if !ok {
-		c.String(http.StatusNotFound, "price not found")
+		utils.SendError(c, utils.NewError(utils.ERROR_PRICE_DATA_MISSING, "price not found"))
 		return
 	}
 
@@ -52,6 +52,7 @@ func (y Controller) GetOnePrice(c *gin.Context) {
 	humanized := helpers.StringToBool(helpers.SafeString(getQuery(c, "humanized"), "false"))
 
 	// Use helper to format response
+	setPriceAttestation(c, chainID, address, price.Price)
 	formatSinglePrice(c, price.Price, price.HumanizedPrice, humanized)
 }
 
@@ -66,21 +67,21 @@ func (y Controller) GetOnePrice(c *gin.Context) {
 func (c *Controller) GetPrice(ctx *gin.Context) {
 	chainID, ok := helpers.AssertChainID(ctx.Param("chainID"))
 	if !ok {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid chainID"})
+		utils.SendChainIDError(ctx, ctx.Param("chainID"))
 		return
 	}
 
 	// Get the token address from the URL
 	rawAddress := ctx.Param("address")
 	if rawAddress == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "address is required"})
+		utils.SendError(ctx, utils.NewError(utils.ERROR_MISSING_PARAM, "address is required"))
 		return
 	}
 
 	// Convert the address to checksummed format
 	tokenAddress := addresses.ToAddress(strings.ToLower(rawAddress))
 	if tokenAddress.Hex() == "0x0000000000000000000000000000000000000000" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid token address"})
+		utils.SendError(ctx, utils.NewError(utils.ERROR_INVALID_ADDRESS, "invalid token address"))
 		return
 	}
 
@@ -90,9 +91,10 @@ func (c *Controller) GetPrice(ctx *gin.Context) {
 	// Fetch price from storage
 	price, ok := storage.GetPrice(chainID, tokenAddress)
 	if !ok {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "price not found"})
+		utils.SendError(ctx, utils.NewError(utils.ERROR_PRICE_DATA_MISSING, "price not found"))
 		return
 	}
 
+	setPriceAttestation(ctx, chainID, tokenAddress, price.Price)
 	formatSinglePrice(ctx, price.Price, price.HumanizedPrice, humanized)
 }
@@ -1,12 +1,14 @@
 package prices
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yearn/ydaemon/common/bigNumber"
 	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/external/utils"
 	"github.com/yearn/ydaemon/internal/storage"
 )
 
@@ -32,7 +34,7 @@ import (
 func (y Controller) GetSomePricesForChain(c *gin.Context) {
 	chainID, ok := helpers.AssertChainID(c.Param("chainID"))
 	if !ok {
-		c.String(http.StatusBadRequest, "invalid chainID")
+		utils.SendChainIDError(c, c.Param("chainID"))
 		return
 	}
 	humanized := helpers.StringToBool(helpers.SafeString(getQuery(c, "humanized"), "false"))
@@ -83,14 +85,14 @@ func (y Controller) GetSomePrices(c *gin.Context) {
 	// Validate chain ID
 	chainID, ok := helpers.AssertChainID(c.Param("chainID"))
 	if !ok {
-		c.String(http.StatusBadRequest, "invalid chainID")
+		utils.SendChainIDError(c, c.Param("chainID"))
 		return
 	}
 
 	// Get and validate address list
 	addressesStr := c.Param("addressList")
 	if addressesStr == "" {
-		c.String(http.StatusNotFound, "not found")
+		utils.SendError(c, utils.NewError(utils.ERROR_MISSING_PARAM, "addressList is required"))
 		return
 	}
 
@@ -102,10 +104,7 @@ func (y Controller) GetSomePrices(c *gin.Context) {
 	validAddresses, invalidAddresses := validateAndParseAddressList(addressList, chainID)
 
 	if len(validAddresses) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":            "No valid addresses provided",
-			"invalidAddresses": invalidAddresses,
-		})
+		utils.SendError(c, utils.NewError(utils.ERROR_INVALID_ADDRESS, fmt.Sprintf("no valid addresses provided: %v", invalidAddresses)))
 		return
 	}
 
@@ -173,7 +172,7 @@ func (y Controller) GetSomePostPrices(c *gin.Context) {
 	humanizedPrices := make(map[uint64]map[string]*bigNumber.Float)
 	var body expectedBody
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		utils.SendError(c, utils.NewError(utils.ERROR_INVALID_FORMAT, err.Error()))
 		return
 	}
 	addresses := body.Addresses
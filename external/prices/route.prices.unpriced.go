@@ -0,0 +1,33 @@
+package prices
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/external/utils"
+	pricesProcess "github.com/yearn/ydaemon/processes/prices"
+)
+
+/**************************************************************************************************
+** GetUnpricedTokens lists, by chain, the tokens no price source priced during the last refresh,
+** with the vaults using them as underlying and their last TVL. The tokens priced before carry
+** their last known price, flagged as stale, the TVL of the others being zero.
+**
+** Endpoint: GET /internal/unpriced
+**
+** @param c The Gin context containing request parameters
+** - chainID: Optional query parameter limiting the response to a chain
+**************************************************************************************************/
+func (y Controller) GetUnpricedTokens(c *gin.Context) {
+	if chainIDStr := c.Query("chainID"); chainIDStr != "" {
+		chainID, ok := helpers.AssertChainID(chainIDStr)
+		if !ok {
+			utils.SendChainIDError(c, chainIDStr)
+			return
+		}
+		c.JSON(http.StatusOK, map[uint64][]pricesProcess.TUnpricedToken{chainID: pricesProcess.ListUnpricedTokens(chainID)})
+		return
+	}
+	c.JSON(http.StatusOK, pricesProcess.ListAllUnpricedTokens())
+}
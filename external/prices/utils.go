@@ -1,13 +1,17 @@
 package prices
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/attestation"
 	"github.com/yearn/ydaemon/common/bigNumber"
 	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/external/utils"
+	"github.com/yearn/ydaemon/internal/storage"
 )
 
 /**************************************************************************************************
@@ -46,6 +50,29 @@ func getQuery(c *gin.Context, targetKey string) string {
 	return ""
 }
 
+/**************************************************************************************************
+** setPriceAttestation signs the price of a token as of the last snapshot of its chain, when an
+** operator key is configured, and sets the attestation in the `X-Attestation-*` headers so the
+** body of the response keeps being the price alone.
+**
+** @param c The Gin context of the response
+** @param chainID The chain of the token
+** @param address The address of the token
+** @param price The raw price of the token, with 6 decimals
+**************************************************************************************************/
+func setPriceAttestation(c *gin.Context, chainID uint64, address common.Address, price *bigNumber.Int) {
+	signed, ok := attestation.Sign(chainID, address, nil, price, storage.GetChainSnapshotBlock(chainID), storage.GetChainLastSnapshot(chainID))
+	if !ok {
+		return
+	}
+	c.Header(`X-Attestation-Signer`, signed.Signer)
+	c.Header(`X-Attestation-Price`, signed.Price)
+	c.Header(`X-Attestation-Timestamp`, fmt.Sprint(signed.Timestamp))
+	c.Header(`X-Attestation-Block`, fmt.Sprint(signed.BlockNumber))
+	c.Header(`X-Attestation-Digest`, signed.Digest)
+	c.Header(`X-Attestation-Signature`, signed.Signature)
+}
+
 /**************************************************************************************************
 ** formatSinglePrice formats a price response for a single token based on the 'humanized' parameter.
 ** This function handles the common pattern of returning either a raw price or a humanized price
@@ -180,11 +207,7 @@ func BatchLimitMiddleware(maxItems int) gin.HandlerFunc {
 		if addressesStr != "" {
 			addresses := splitAndTrim(addressesStr, ",")
 			if len(addresses) > maxItems {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"error": "Too many addresses requested",
-					"limit": maxItems,
-				})
-				c.Abort()
+				utils.SendError(c, utils.NewError(utils.ERROR_INVALID_PARAM, fmt.Sprintf("too many addresses requested, the limit is %d", maxItems)))
 				return
 			}
 		}
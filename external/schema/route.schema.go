@@ -0,0 +1,89 @@
+package schema
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	jsonSchema "github.com/yearn/ydaemon/common/schema"
+	"github.com/yearn/ydaemon/external/tokens"
+	"github.com/yearn/ydaemon/external/treasury"
+	"github.com/yearn/ydaemon/external/utils"
+	"github.com/yearn/ydaemon/external/vaults"
+)
+
+/**************************************************************************************************
+** Controller serves the JSON Schemas of the models returned by the API, for the clients to check
+** the responses or to generate their types (see the `codegen` process).
+**************************************************************************************************/
+type Controller struct{}
+
+/**************************************************************************************************
+** ROOT_MODELS lists the models returned by the public endpoints. Their definitions, and the ones
+** of the types they reference, are generated once, the first time they are requested.
+**************************************************************************************************/
+var ROOT_MODELS = []interface{}{
+	vaults.TExternalVault{},
+	vaults.TSimplifiedExternalVault{},
+	vaults.TExternalStrategy{},
+	vaults.TExternalVaultExposure{},
+	vaults.TVaultAPYFigure{},
+	vaults.TVaultAPYStats{},
+	vaults.TVaultsDiff{},
+	vaults.TVaultMovers{},
+	vaults.TVaultMigrationPrompt{},
+	vaults.TVaultPermitData{},
+	vaults.TStrategiesLeaderboard{},
+	vaults.TTokenList{},
+	vaults.TEarned{},
+	tokens.TAllTokens{},
+	treasury.THoldingsResponse{},
+}
+
+var (
+	generator     *jsonSchema.TGenerator
+	generatorOnce sync.Once
+)
+
+func getGenerator() *jsonSchema.TGenerator {
+	generatorOnce.Do(func() {
+		generator = jsonSchema.NewGenerator()
+		for _, model := range ROOT_MODELS {
+			generator.Add(model)
+		}
+	})
+	return generator
+}
+
+/**************************************************************************************************
+** GetDocument returns the document of all the definitions, or the one of a definition and of the
+** types it references when name is set. The boolean is false when the definition is unknown.
+**************************************************************************************************/
+func GetDocument(name string) (jsonSchema.TDocument, bool) {
+	return getGenerator().Document(name)
+}
+
+/**************************************************************************************************
+** GetSchema returns the document of all the definitions of the API models.
+**
+** Endpoint: GET /schema
+**************************************************************************************************/
+func (y Controller) GetSchema(c *gin.Context) {
+	document, _ := GetDocument(``)
+	c.JSON(http.StatusOK, document)
+}
+
+/**************************************************************************************************
+** GetSchemaDefinition returns the schema of one model, with the definitions of the types it
+** references.
+**
+** Endpoint: GET /schema/:name
+**************************************************************************************************/
+func (y Controller) GetSchemaDefinition(c *gin.Context) {
+	document, ok := GetDocument(c.Param(`name`))
+	if !ok {
+		utils.SendError(c, utils.NewError(utils.ERROR_NOT_FOUND, `unknown definition `+c.Param(`name`)))
+		return
+	}
+	c.JSON(http.StatusOK, document)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/yearn/ydaemon/common/env"
 	"github.com/yearn/ydaemon/common/helpers"
 	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/external/utils"
 	"github.com/yearn/ydaemon/internal/models"
 )
 
@@ -98,14 +99,14 @@ func (y Controller) GetReports(c *gin.Context) {
 	// Validate chain ID
 	chainID, ok := helpers.AssertChainID(c.Param("chainID"))
 	if !ok {
-		c.String(http.StatusBadRequest, "invalid chainID")
+		utils.SendChainIDError(c, c.Param("chainID"))
 		return
 	}
 
 	// Validate strategy address
 	address, ok := helpers.AssertAddress(c.Param("address"), chainID)
 	if !ok {
-		c.String(http.StatusBadRequest, "invalid address")
+		utils.SendError(c, utils.NewError(utils.ERROR_INVALID_ADDRESS, "invalid address"))
 		return
 	}
 
@@ -119,7 +120,7 @@ func (y Controller) GetReports(c *gin.Context) {
 	graphQLEndpoint := chain.SubgraphURI
 	if graphQLEndpoint == "" {
 		logs.Error("No graph endpoint for chainID", chainID)
-		c.String(http.StatusInternalServerError, "impossible to fetch subgraph")
+		utils.SendError(c, utils.NewError(utils.ERROR_CHAIN_CONFIG_MISSING, "impossible to fetch subgraph"))
 		return
 	}
 
@@ -131,7 +132,7 @@ func (y Controller) GetReports(c *gin.Context) {
 	var responseRaw models.TReportsFromGraph
 	if err := runGraphQLRequest(context.Background(), client, request, &responseRaw); err != nil {
 		logs.Error(err)
-		c.String(http.StatusInternalServerError, "invalid graphQL response")
+		utils.SendError(c, utils.NewError(utils.ERROR_GRAPHQL_FAILED, "invalid graphQL response"))
 		return
 	}
 
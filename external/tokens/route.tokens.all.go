@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/yearn/ydaemon/common/env"
 	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/external/utils"
 	"github.com/yearn/ydaemon/internal/models"
 	"github.com/yearn/ydaemon/internal/storage"
 )
@@ -146,7 +147,7 @@ func (y Controller) GetTokens(c *gin.Context) {
 	// Validate chain ID
 	chainID, ok := helpers.AssertChainID(c.Param("chainID"))
 	if !ok {
-		c.String(http.StatusBadRequest, "invalid chainID")
+		utils.SendChainIDError(c, c.Param("chainID"))
 		return
 	}
 
@@ -185,21 +186,21 @@ func (y Controller) GetToken(c *gin.Context) {
 	// Validate chain ID
 	chainID, ok := helpers.AssertChainID(c.Param("chainID"))
 	if !ok {
-		c.String(http.StatusBadRequest, "invalid chainID")
+		utils.SendChainIDError(c, c.Param("chainID"))
 		return
 	}
 
 	// Validate token address
 	address, ok := helpers.AssertAddress(c.Param("address"), chainID)
 	if !ok {
-		c.String(http.StatusBadRequest, "invalid address")
+		utils.SendError(c, utils.NewError(utils.ERROR_INVALID_ADDRESS, "invalid address"))
 		return
 	}
 
 	// Get token data from storage
 	token, ok := storage.GetERC20(chainID, address)
 	if !ok {
-		c.String(http.StatusNotFound, "token not found")
+		utils.SendError(c, utils.NewError(utils.ERROR_TOKEN_NOT_FOUND, "token not found"))
 		return
 	}
 
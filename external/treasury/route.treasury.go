@@ -0,0 +1,145 @@
+package treasury
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/external/utils"
+	"github.com/yearn/ydaemon/processes/treasury"
+)
+
+/**************************************************************************************************
+** Controller is the handler of the treasury endpoints, exposing the holdings of the Yearn treasury
+** and the funds it received for the transparency dashboards.
+**************************************************************************************************/
+type Controller struct{}
+
+/**************************************************************************************************
+** TFeeInflowsByVault sums the fees received by the treasury from one vault.
+**************************************************************************************************/
+type TFeeInflowsByVault struct {
+	Vault         string  `json:"vault"`
+	Symbol        string  `json:"symbol"`
+	Count         int     `json:"count"`
+	Amount        float64 `json:"amount"`
+	Value         float64 `json:"value"`
+	LastTimestamp uint64  `json:"lastTimestamp"`
+}
+
+/**************************************************************************************************
+** THoldingsResponse is the response of the holdings endpoint.
+**************************************************************************************************/
+type THoldingsResponse struct {
+	ChainID         uint64               `json:"chainID"`
+	Addresses       []string             `json:"addresses"`
+	TotalValue      float64              `json:"totalValue"`
+	Holdings        []treasury.THolding  `json:"holdings"`
+	FeeInflows      []TFeeInflowsByVault `json:"feeInflows"`
+	FeeInflowsValue float64              `json:"feeInflowsValue"`
+}
+
+/**************************************************************************************************
+** TBuybacksResponse is the response of the buybacks endpoint.
+**************************************************************************************************/
+type TBuybacksResponse struct {
+	TotalAmount float64              `json:"totalAmount"`
+	TotalValue  float64              `json:"totalValue"`
+	Buybacks    []treasury.TTransfer `json:"buybacks"`
+}
+
+/**************************************************************************************************
+** GetHoldings returns the tokens held by the treasury addresses of a chain, by decreasing value,
+** along with the fees received from each vault since the treasury is indexed, by decreasing value.
+**
+** Endpoint: GET /treasury/:chainID/holdings
+**************************************************************************************************/
+func (y Controller) GetHoldings(c *gin.Context) {
+	chainID, ok := helpers.AssertChainID(c.Param("chainID"))
+	if !ok {
+		utils.SendChainIDError(c, c.Param("chainID"))
+		return
+	}
+	if !treasury.IsTracked(chainID) {
+		utils.SendError(c, utils.NewError(utils.ERROR_NOT_FOUND, `the treasury of chain `+strconv.FormatUint(chainID, 10)+` is not tracked`))
+		return
+	}
+
+	chain, _ := env.GetChain(chainID)
+	response := THoldingsResponse{
+		ChainID:    chainID,
+		Addresses:  []string{},
+		Holdings:   treasury.ListHoldings(chainID),
+		FeeInflows: []TFeeInflowsByVault{},
+	}
+	for _, address := range chain.Treasury.Addresses {
+		response.Addresses = append(response.Addresses, address.Hex())
+	}
+	for _, holding := range response.Holdings {
+		response.TotalValue += holding.Value
+	}
+
+	byVault := map[string]*TFeeInflowsByVault{}
+	for _, inflow := range treasury.ListFeeInflows(chainID) {
+		vault := inflow.Token.Hex()
+		if _, ok := byVault[vault]; !ok {
+			byVault[vault] = &TFeeInflowsByVault{Vault: vault, Symbol: inflow.Symbol}
+		}
+		byVault[vault].Count++
+		byVault[vault].Amount += inflow.Amount
+		byVault[vault].Value += inflow.Value
+		if inflow.Timestamp > byVault[vault].LastTimestamp {
+			byVault[vault].LastTimestamp = inflow.Timestamp
+		}
+		response.FeeInflowsValue += inflow.Value
+	}
+	for _, inflows := range byVault {
+		response.FeeInflows = append(response.FeeInflows, *inflows)
+	}
+	sort.Slice(response.FeeInflows, func(i, j int) bool {
+		return response.FeeInflows[i].Value > response.FeeInflows[j].Value
+	})
+
+	c.JSON(http.StatusOK, response)
+}
+
+/**************************************************************************************************
+** GetBuybacks returns the YFI buybacks received by the treasury, most recent first, on all the
+** chains with a tracked treasury or on the ones of the `chainIDs` query parameter (comma
+** separated).
+**
+** Endpoint: GET /treasury/buybacks
+**************************************************************************************************/
+func (y Controller) GetBuybacks(c *gin.Context) {
+	chainIDs := []uint64{}
+	if rawChainIDs := c.Query(`chainIDs`); rawChainIDs != `` {
+		for _, rawChainID := range strings.Split(rawChainIDs, `,`) {
+			chainID, ok := helpers.AssertChainID(strings.TrimSpace(rawChainID))
+			if !ok {
+				utils.SendChainIDError(c, rawChainID)
+				return
+			}
+			chainIDs = append(chainIDs, chainID)
+		}
+	} else {
+		chainIDs = env.SUPPORTED_CHAIN_IDS
+	}
+
+	response := TBuybacksResponse{Buybacks: []treasury.TTransfer{}}
+	for _, chainID := range chainIDs {
+		for _, buyback := range treasury.ListBuybacks(chainID) {
+			response.TotalAmount += buyback.Amount
+			response.TotalValue += buyback.Value
+			response.Buybacks = append(response.Buybacks, buyback)
+		}
+	}
+	sort.SliceStable(response.Buybacks, func(i, j int) bool {
+		return response.Buybacks[i].Timestamp > response.Buybacks[j].Timestamp
+	})
+
+	c.JSON(http.StatusOK, response)
+}
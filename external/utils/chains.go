@@ -17,6 +17,6 @@ import (
 **************************************************************************************************/
 func GetSupportedChains(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"chains": env.CHAINS,
+		"chains": env.GetChains(),
 	})
 }
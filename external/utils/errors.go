@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** TErrorCode is the machine readable code of an API error. Integrators should program against
+** these codes rather than against the messages, which are meant for humans and may change.
+**************************************************************************************************/
+type TErrorCode string
+
+const (
+	// Configuration errors
+	ERROR_CHAIN_NOT_SUPPORTED  TErrorCode = "chain_not_supported"
+	ERROR_CHAIN_CONFIG_MISSING TErrorCode = "chain_config_missing"
+
+	// Validation errors
+	ERROR_MISSING_PARAM      TErrorCode = "missing_param"
+	ERROR_INVALID_PARAM      TErrorCode = "invalid_param"
+	ERROR_INVALID_ADDRESS    TErrorCode = "invalid_address"
+	ERROR_INVALID_FORMAT     TErrorCode = "invalid_format"
+	ERROR_INVALID_CONDITION  TErrorCode = "invalid_condition"
+	ERROR_INCOMPATIBLE_PARAM TErrorCode = "incompatible_param"
+	ERROR_METHOD_NOT_ALLOWED TErrorCode = "method_not_allowed"
+	ERROR_UNAUTHORIZED       TErrorCode = "unauthorized"
+	ERROR_RATE_LIMITED       TErrorCode = "rate_limited"
+
+	// Data errors
+	ERROR_NOT_FOUND          TErrorCode = "not_found"
+	ERROR_VAULT_NOT_FOUND    TErrorCode = "vault_not_found"
+	ERROR_VAULT_NOT_INDEXED  TErrorCode = "vault_not_indexed"
+	ERROR_STRATEGY_NOT_FOUND TErrorCode = "strategy_not_found"
+	ERROR_TOKEN_NOT_FOUND    TErrorCode = "token_not_found"
+	ERROR_PRICE_DATA_MISSING TErrorCode = "price_data_missing"
+	ERROR_DATA_STALE         TErrorCode = "data_stale"
+
+	// Processing errors
+	ERROR_TIMEOUT           TErrorCode = "timeout"
+	ERROR_PROCESSING_FAILED TErrorCode = "processing_failed"
+
+	// External errors
+	ERROR_GRAPHQL_FAILED      TErrorCode = "graphql_failed"
+	ERROR_EXTERNAL_API_FAILED TErrorCode = "external_api_failed"
+)
+
+/**************************************************************************************************
+** errorDefinitions gives the HTTP status and whether the request can be retried later for each
+** error code. A retryable error is expected to go away without any change of the request: a vault
+** being indexed, a stale chain catching up, a timeout or an upstream failure.
+**************************************************************************************************/
+var errorDefinitions = map[TErrorCode]struct {
+	status    int
+	retryable bool
+}{
+	ERROR_CHAIN_NOT_SUPPORTED:  {http.StatusBadRequest, false},
+	ERROR_CHAIN_CONFIG_MISSING: {http.StatusInternalServerError, false},
+	ERROR_MISSING_PARAM:        {http.StatusBadRequest, false},
+	ERROR_INVALID_PARAM:        {http.StatusBadRequest, false},
+	ERROR_INVALID_ADDRESS:      {http.StatusBadRequest, false},
+	ERROR_INVALID_FORMAT:       {http.StatusBadRequest, false},
+	ERROR_INVALID_CONDITION:    {http.StatusBadRequest, false},
+	ERROR_INCOMPATIBLE_PARAM:   {http.StatusBadRequest, false},
+	ERROR_METHOD_NOT_ALLOWED:   {http.StatusMethodNotAllowed, false},
+	ERROR_UNAUTHORIZED:         {http.StatusUnauthorized, false},
+	ERROR_RATE_LIMITED:         {http.StatusTooManyRequests, true},
+	ERROR_NOT_FOUND:            {http.StatusNotFound, false},
+	ERROR_VAULT_NOT_FOUND:      {http.StatusNotFound, false},
+	ERROR_VAULT_NOT_INDEXED:    {http.StatusNotFound, true},
+	ERROR_STRATEGY_NOT_FOUND:   {http.StatusNotFound, false},
+	ERROR_TOKEN_NOT_FOUND:      {http.StatusNotFound, false},
+	ERROR_PRICE_DATA_MISSING:   {http.StatusNotFound, false},
+	ERROR_DATA_STALE:           {http.StatusServiceUnavailable, true},
+	ERROR_TIMEOUT:              {http.StatusGatewayTimeout, true},
+	ERROR_PROCESSING_FAILED:    {http.StatusInternalServerError, false},
+	ERROR_GRAPHQL_FAILED:       {http.StatusInternalServerError, true},
+	ERROR_EXTERNAL_API_FAILED:  {http.StatusBadGateway, true},
+}
+
+/**************************************************************************************************
+** TAPIError is the error envelope returned by every route of the API. The chain ID and address
+** are set when the request targets a specific chain or contract.
+**************************************************************************************************/
+type TAPIError struct {
+	Code      TErrorCode `json:"code"`
+	Message   string     `json:"message"`
+	ChainID   uint64     `json:"chainID,omitempty"`
+	Address   string     `json:"address,omitempty"`
+	Retryable bool       `json:"retryable"`
+}
+
+/**************************************************************************************************
+** Error implements the error interface.
+**************************************************************************************************/
+func (e TAPIError) Error() string {
+	return string(e.Code) + `: ` + e.Message
+}
+
+/**************************************************************************************************
+** NewError creates an API error for a code, with the retryable flag of the code.
+**************************************************************************************************/
+func NewError(code TErrorCode, message string) TAPIError {
+	return TAPIError{
+		Code:      code,
+		Message:   message,
+		Retryable: errorDefinitions[code].retryable,
+	}
+}
+
+/**************************************************************************************************
+** WithChainID sets the chain ID targeted by the request.
+**************************************************************************************************/
+func (e TAPIError) WithChainID(chainID uint64) TAPIError {
+	e.ChainID = chainID
+	return e
+}
+
+/**************************************************************************************************
+** WithAddress sets the address targeted by the request.
+**************************************************************************************************/
+func (e TAPIError) WithAddress(address string) TAPIError {
+	e.Address = address
+	return e
+}
+
+/**************************************************************************************************
+** GetErrorStatus returns the HTTP status of an error code, 500 for an unknown code.
+**************************************************************************************************/
+func GetErrorStatus(code TErrorCode) int {
+	if definition, ok := errorDefinitions[code]; ok {
+		return definition.status
+	}
+	return http.StatusInternalServerError
+}
+
+/**************************************************************************************************
+** SendError aborts the request with the error envelope and the HTTP status of the error code.
+**************************************************************************************************/
+func SendError(c *gin.Context, err TAPIError) {
+	SendErrorWithStatus(c, GetErrorStatus(err.Code), err)
+}
+
+/**************************************************************************************************
+** SendErrorWithStatus aborts the request with the error envelope and a specific HTTP status. When
+** not set, the chain ID and address are filled from the `chainID` and `address` path parameters
+** of the request, if well formed.
+**************************************************************************************************/
+func SendErrorWithStatus(c *gin.Context, status int, err TAPIError) {
+	if err.ChainID == 0 {
+		if chainID, parseErr := strconv.ParseUint(c.Param(`chainID`), 10, 64); parseErr == nil {
+			err.ChainID = chainID
+		}
+	}
+	if err.Address == `` && common.IsHexAddress(c.Param(`address`)) {
+		err.Address = common.HexToAddress(c.Param(`address`)).Hex()
+	}
+	c.AbortWithStatusJSON(status, err)
+}
+
+/**************************************************************************************************
+** The data of a chain is refreshed every 30 minutes. Without any refresh for STALE_DATA_THRESHOLD,
+** the indexing of the chain is considered broken and its data stale.
+**************************************************************************************************/
+const STALE_DATA_THRESHOLD = 2 * time.Hour
+
+/**************************************************************************************************
+** IsChainDataStale returns true if the last snapshot of the chain is older than the threshold. A
+** chain without any snapshot since the start of the daemon serves the data loaded from the disk
+** while the first snapshot runs, and is not considered stale. A chain degraded because its RPC
+** endpoints are dead serves its last known data, labeled with `rpcDown`.
+**************************************************************************************************/
+func IsChainDataStale(chainID uint64) bool {
+	lastSnapshot := storage.GetChainLastSnapshot(chainID)
+	if lastSnapshot.IsZero() || storage.IsRPCDown(chainID) {
+		return false
+	}
+	return time.Since(lastSnapshot) > STALE_DATA_THRESHOLD
+}
+
+/**************************************************************************************************
+** SendChainIDError sends the error for a chain ID rejected by the validation: a well formed chain
+** ID is not supported by this instance, anything else is an invalid format.
+**************************************************************************************************/
+func SendChainIDError(c *gin.Context, rawChainID string) {
+	if _, err := strconv.ParseUint(rawChainID, 10, 64); err == nil {
+		SendError(c, NewError(ERROR_CHAIN_NOT_SUPPORTED, `chain `+rawChainID+` is not supported`))
+		return
+	}
+	SendError(c, NewError(ERROR_INVALID_FORMAT, `invalid chainID: `+rawChainID))
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+/**************************************************************************************************
+** performErrorRequest registers a handler on a test router and returns the recorded response for
+** the given path.
+**************************************************************************************************/
+func performErrorRequest(route string, path string, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET(route, handler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+/**************************************************************************************************
+** TestSendError tests that the error envelope carries the code, the retryable flag and the chain
+** ID and address of the request, with the HTTP status of the code.
+**************************************************************************************************/
+func TestSendError(t *testing.T) {
+	w := performErrorRequest("/:chainID/vaults/:address", "/1/vaults/0x16388463d60ffe0661cf7f1f31a7d658ac790ff7", func(c *gin.Context) {
+		SendError(c, NewError(ERROR_VAULT_NOT_INDEXED, "vault not indexed yet"))
+	})
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var response TAPIError
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, ERROR_VAULT_NOT_INDEXED, response.Code)
+	assert.Equal(t, "vault not indexed yet", response.Message)
+	assert.Equal(t, uint64(1), response.ChainID)
+	assert.Equal(t, "0x16388463d60FFE0661Cf7F1f31a7D658aC790ff7", response.Address)
+	assert.True(t, response.Retryable)
+}
+
+/**************************************************************************************************
+** TestSendChainIDError tests that an unknown chain is reported as not supported, while a
+** malformed chain ID is reported as an invalid format.
+**************************************************************************************************/
+func TestSendChainIDError(t *testing.T) {
+	handler := func(c *gin.Context) {
+		SendChainIDError(c, c.Param("chainID"))
+	}
+
+	tests := []struct {
+		path         string
+		expectedCode TErrorCode
+	}{
+		{"/123456789/status", ERROR_CHAIN_NOT_SUPPORTED},
+		{"/abc/status", ERROR_INVALID_FORMAT},
+	}
+	for _, tc := range tests {
+		w := performErrorRequest("/:chainID/status", tc.path, handler)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response TAPIError
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, tc.expectedCode, response.Code)
+		assert.False(t, response.Retryable)
+	}
+}
@@ -0,0 +1,123 @@
+package vaults
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** matchLocale returns the available locale matching a BCP 47 tag: the tag itself (`pt-br`), or its
+** language (`pt`). The boolean is false when none is available.
+**************************************************************************************************/
+func matchLocale(tag string, available map[string]bool) (string, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), `_`, `-`))
+	if available[tag] {
+		return tag, true
+	}
+	if language, _, found := strings.Cut(tag, `-`); found && available[language] {
+		return language, true
+	}
+	return ``, false
+}
+
+/**************************************************************************************************
+** NegotiateLocale returns the locale of the strings of a response: the `locale` query parameter
+** when available, then the first available language of the Accept-Language header, by decreasing
+** quality. It falls back to English.
+**
+** @param c *gin.Context - The Gin context containing the request
+** @return string - The negotiated locale, `en` by default
+**************************************************************************************************/
+func NegotiateLocale(c *gin.Context) string {
+	available := make(map[string]bool)
+	for _, locale := range storage.ListLocales() {
+		available[locale] = true
+	}
+	if locale, ok := matchLocale(getQueryParam(c, `locale`), available); ok {
+		return locale
+	}
+
+	type tWeightedTag struct {
+		tag     string
+		quality float64
+	}
+	tags := []tWeightedTag{}
+	for _, part := range strings.Split(c.GetHeader(`Accept-Language`), `,`) {
+		tag, params, _ := strings.Cut(part, `;`)
+		quality := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), `q=`); found {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if strings.TrimSpace(tag) != `` && quality > 0 {
+			tags = append(tags, tWeightedTag{tag: tag, quality: quality})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].quality > tags[j].quality })
+	for _, weighted := range tags {
+		if locale, ok := matchLocale(weighted.tag, available); ok {
+			return locale
+		}
+	}
+	return storage.DEFAULT_LOCALE
+}
+
+/**************************************************************************************************
+** getLocalizedStrings returns the translations of the name and the description of a vault or a
+** strategy in a locale. The strings not translated are empty, to be left in English.
+**************************************************************************************************/
+func getLocalizedStrings(chainID uint64, address string, locale string) storage.TLocalizedStrings {
+	if locale == storage.DEFAULT_LOCALE {
+		return storage.TLocalizedStrings{}
+	}
+	localized, _ := storage.GetLocalizedStrings(chainID, common.HexToAddress(address), locale)
+	return localized
+}
+
+/**************************************************************************************************
+** localize translates the name and the description of a strategy of a chain in a locale.
+**************************************************************************************************/
+func (strategy *TExternalStrategy) localize(chainID uint64, locale string) {
+	localized := getLocalizedStrings(chainID, strategy.Address, locale)
+	strategy.Name = helpers.SafeString(localized.Name, strategy.Name)
+	strategy.Description = helpers.SafeString(localized.Description, strategy.Description)
+}
+
+/**************************************************************************************************
+** localize translates the name and the description of a vault, and the ones of its strategies, in
+** a locale.
+**************************************************************************************************/
+func (vault *TSimplifiedExternalVault) localize(locale string) {
+	localized := getLocalizedStrings(vault.ChainID, vault.Address, locale)
+	vault.Name = helpers.SafeString(localized.Name, vault.Name)
+	vault.Description = helpers.SafeString(localized.Description, vault.Description)
+	for i := range vault.Strategies {
+		vault.Strategies[i].localize(vault.ChainID, locale)
+	}
+}
+
+func (vault *TExternalVault) localize(locale string) {
+	localized := getLocalizedStrings(vault.ChainID, vault.Address, locale)
+	vault.Name = helpers.SafeString(localized.Name, vault.Name)
+	vault.DisplayName = helpers.SafeString(localized.Name, vault.DisplayName)
+	vault.Description = helpers.SafeString(localized.Description, vault.Description)
+	for i := range vault.Strategies {
+		vault.Strategies[i].localize(vault.ChainID, locale)
+	}
+}
+
+/**************************************************************************************************
+** setContentLanguage tells the clients and proxies in which locale the strings of a response are,
+** and that it depends on the Accept-Language header.
+**************************************************************************************************/
+func setContentLanguage(c *gin.Context, locale string) {
+	c.Header(`Content-Language`, locale)
+	c.Header(`Vary`, `Accept-Language`)
+}
@@ -2,14 +2,26 @@ package vaults
 
 import (
 	"errors"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/attestation"
 	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
 	"github.com/yearn/ydaemon/internal/fetcher"
 	"github.com/yearn/ydaemon/internal/models"
 	"github.com/yearn/ydaemon/internal/storage"
 	"github.com/yearn/ydaemon/processes/apr"
+	"github.com/yearn/ydaemon/processes/assets"
+	"github.com/yearn/ydaemon/processes/fees"
+	"github.com/yearn/ydaemon/processes/governance"
+	"github.com/yearn/ydaemon/processes/holders"
+	"github.com/yearn/ydaemon/processes/inception"
+	"github.com/yearn/ydaemon/processes/liquidity"
+	"github.com/yearn/ydaemon/processes/losses"
+	"github.com/yearn/ydaemon/processes/migrations"
 	"github.com/yearn/ydaemon/processes/risks"
+	"github.com/yearn/ydaemon/processes/sharePrice"
 )
 
 /**************************************************************************************************
@@ -40,12 +52,16 @@ type TExternalVaultHarvest struct {
 ** TExternalVaultMigration contains migration information for a vault.
 **
 ** When vaults need to be migrated to newer versions, this structure provides data about the
-** migration target and availability status, helping users transition their funds.
+** migration target and availability status, helping users transition their funds. The source and
+** the APY delta (forward net APY of the target minus the one of the vault) are set when the
+** migration is resolved (see processes/migrations).
 **************************************************************************************************/
 type TExternalVaultMigration struct {
-	Available bool   `json:"available"`
-	Address   string `json:"address"`
-	Contract  string `json:"contract"`
+	Available bool     `json:"available"`
+	Address   string   `json:"address"`
+	Contract  string   `json:"contract"`
+	Source    string   `json:"source,omitempty"`
+	APYDelta  *float64 `json:"apyDelta,omitempty"`
 }
 
 /**************************************************************************************************
@@ -95,6 +111,8 @@ type TExternalERC20Token struct {
 	DisplaySymbol             string            `json:"display_symbol"`
 	Description               string            `json:"description"`
 	Icon                      string            `json:"icon"`
+	IconIPFS                  string            `json:"iconIPFS,omitempty"`     // ipfs:// URI of the icon, once pinned
+	MetadataIPFS              string            `json:"metadataIPFS,omitempty"` // ipfs:// URI of the metadata document, once pinned
 	Decimals                  uint64            `json:"decimals"`
 }
 
@@ -127,6 +145,8 @@ type TExternalERC20Token struct {
 ** - UINotice: Optional message to display to users (warnings, information)
 ** - SourceURL: Link to relevant external resource (e.g., token purchase site)
 ** - Status flags: Control how the vault appears in listings
+** - SharePriceWarning: Set for a few days when the share price moved without a matching harvest
+**   report or dropped, a possible donation (inflation) attack or loss
 **************************************************************************************************/
 type TExternalVaultInfo struct {
 	SourceURL        string   `json:"sourceURL,omitempty"` // The vault might require some specific tokens that needs to be bought by a specific provider. It's the URL of the provider.
@@ -138,6 +158,8 @@ type TExternalVaultInfo struct {
 	IsHighlighted    bool     `json:"isHighlighted"`
 	RiskScore        [11]int8 `json:"riskScore"`                  // All risk scores of the Single Strategy Vault. Multi-Strategy Vault won't have this object because its risk score is combination of multiple vaults. For risk value use `riskLevel`. (empty for Multi-Strategy Vault). Array of 11 integers: [review, testing, complexity, riskExposure, protocolIntegration, centralizationRisk, externalProtocolAudit, externalProtocolCentralisation, externalProtocolTvl, externalProtocolLongevity, externalProtocolType]
 	RiskScoreComment string   `json:"riskScoreComment,omitempty"` // Comment for the risk score to the strategy. Can be empty.
+
+	SharePriceWarning *sharePrice.TSharePriceAnomaly `json:"sharePriceWarning,omitempty"` // Set when the share price recently moved in a way inconsistent with the harvest reports (donation, loss)
 }
 
 /**************************************************************************************************
@@ -148,16 +170,19 @@ type TExternalVaultInfo struct {
 ** contribute to the overall vault yield.
 **************************************************************************************************/
 type TExternalCompositeData struct {
-	Boost                 *bigNumber.Float `json:"boost"`
-	PoolAPY               *bigNumber.Float `json:"poolAPY"`
-	BoostedAPR            *bigNumber.Float `json:"boostedAPR"`
-	BaseAPR               *bigNumber.Float `json:"baseAPR"`
-	CvxAPR                *bigNumber.Float `json:"cvxAPR"`
-	RewardsAPR            *bigNumber.Float `json:"rewardsAPR"`
-	V3OracleCurrentAPR    *bigNumber.Float `json:"v3OracleCurrentAPR,omitempty"`
-	V3OracleStratRatioAPR *bigNumber.Float `json:"v3OracleStratRatioAPR,omitempty"`
-	KeepCRV               *bigNumber.Float `json:"keepCRV,omitempty"`
-	KeepVelo              *bigNumber.Float `json:"keepVELO,omitempty"`
+	Boost                 *bigNumber.Float      `json:"boost"`
+	PoolAPY               *bigNumber.Float      `json:"poolAPY"`
+	BoostedAPR            *bigNumber.Float      `json:"boostedAPR"`
+	BaseAPR               *bigNumber.Float      `json:"baseAPR"`
+	CvxAPR                *bigNumber.Float      `json:"cvxAPR"`
+	RewardsAPR            *bigNumber.Float      `json:"rewardsAPR"`
+	V3OracleCurrentAPR    *bigNumber.Float      `json:"v3OracleCurrentAPR,omitempty"`
+	V3OracleStratRatioAPR *bigNumber.Float      `json:"v3OracleStratRatioAPR,omitempty"`
+	KeepCRV               *bigNumber.Float      `json:"keepCRV,omitempty"`
+	KeepVelo              *bigNumber.Float      `json:"keepVELO,omitempty"`
+	EmissionsMinBoostAPR  *bigNumber.Float      `json:"emissionsMinBoostAPR,omitempty"`
+	EmissionsMaxBoostAPR  *bigNumber.Float      `json:"emissionsMaxBoostAPR,omitempty"`
+	APROverrides          []models.TAPROverride `json:"aprOverrides,omitempty"`
 }
 
 /**************************************************************************************************
@@ -167,8 +192,9 @@ type TExternalCompositeData struct {
 ** the standard yield, such as staking rewards and gamma (protocol-specific) rewards.
 **************************************************************************************************/
 type TExternalExtraRewards struct {
-	StakingRewardsAPR *bigNumber.Float `json:"stakingRewardsAPR"`
-	GammaRewardAPR    *bigNumber.Float `json:"gammaRewardAPR"`
+	StakingRewardsAPR  *bigNumber.Float `json:"stakingRewardsAPR"`
+	GammaRewardAPR     *bigNumber.Float `json:"gammaRewardAPR"`
+	UnderlyingAssetAPR *bigNumber.Float `json:"underlyingAssetAPR,omitempty"`
 }
 
 /**************************************************************************************************
@@ -179,9 +205,20 @@ type TExternalExtraRewards struct {
 ** breakdown of yield sources.
 **************************************************************************************************/
 type TExternalForwardAPR struct {
-	Type      string                 `json:"type"`
-	NetAPR    *bigNumber.Float       `json:"netAPR"`
-	Composite TExternalCompositeData `json:"composite"`
+	Type               string                 `json:"type"`
+	NetAPR             *bigNumber.Float       `json:"netAPR"`
+	NetAPY             *bigNumber.Float       `json:"netAPY,omitempty"`      // Set with the yieldFormat query parameter
+	YieldFormat        string                 `json:"yieldFormat,omitempty"` // Set with the yieldFormat query parameter
+	NetAPRDeployedOnly *bigNumber.Float       `json:"netAPRDeployedOnly,omitempty"`
+	IdleRatio          *bigNumber.Float       `json:"idleRatio,omitempty"`
+	PrimarySource      string                 `json:"primarySource,omitempty"`
+	TotalAPR           *bigNumber.Float       `json:"totalAPR,omitempty"` // NetAPR combined with the APY of a yield-bearing asset
+	Composite          TExternalCompositeData `json:"composite"`
+	BlockNumber        *uint64                `json:"blockNumber,omitempty"`        // Set when computed at a past block
+	CompoundingPeriods float64                `json:"compoundingPeriods,omitempty"` // Set when the vault is tended more than weekly
+	DivergenceWarning  *apr.TAPYDivergence    `json:"divergenceWarning,omitempty"`  // Set when NetAPR diverges from the realized APY
+	simpleNetAPR       *bigNumber.Float       // Net APR before compounding, see applyYieldFormat
+	compoundedNetAPY   *bigNumber.Float       // Net APY, see applyYieldFormat
 }
 
 /**************************************************************************************************
@@ -199,6 +236,33 @@ type TExternalVaultAPR struct {
 	PricePerShare apr.TPricePerShare    `json:"pricePerShare"`
 	Extra         TExternalExtraRewards `json:"extra"`
 	ForwardAPR    TExternalForwardAPR   `json:"forwardAPR"`
+	FeeImpact     apr.TFeeImpact        `json:"feeImpact"`
+	GasImpact     *apr.TGasImpact       `json:"gasImpact,omitempty"`
+
+	RewardContributions []apr.TRewardContribution `json:"rewardContributions,omitempty"`
+}
+
+/**************************************************************************************************
+** applyYieldFormat sets the forward net yield of a vault in the format asked with the yieldFormat
+** query parameter. Without it, netAPR keeps its historical value, the net APY compounded over
+** apr.FORWARD_APY_COMPOUNDING_PERIODS periods. Otherwise:
+** - apr: netAPR is the simple annualized net rate,
+** - apy: netAPY is the compounded net rate and netAPR is null,
+** - both: netAPR and netAPY are both set.
+**************************************************************************************************/
+func (vaultAPR *TExternalVaultAPR) applyYieldFormat(format string) {
+	forward := &vaultAPR.ForwardAPR
+	switch format {
+	case YIELD_FORMAT_APR:
+		forward.NetAPR, forward.NetAPY = forward.simpleNetAPR, nil
+	case YIELD_FORMAT_APY:
+		forward.NetAPR, forward.NetAPY = nil, forward.compoundedNetAPY
+	case YIELD_FORMAT_BOTH:
+		forward.NetAPR, forward.NetAPY = forward.simpleNetAPR, forward.compoundedNetAPY
+	default:
+		return
+	}
+	forward.YieldFormat = format
 }
 
 /**************************************************************************************************
@@ -233,35 +297,49 @@ type TExternalVaultAPR struct {
 ** consider using TSimplifiedExternalVault instead.
 **************************************************************************************************/
 type TExternalVault struct {
-	Address           string                  `json:"address"`
-	Type              models.TTokenType       `json:"type"`
-	Kind              models.TVaultKind       `json:"kind"`
-	Symbol            string                  `json:"symbol"`
-	DisplaySymbol     string                  `json:"displaySymbol"`
-	FormatedSymbol    string                  `json:"formatedSymbol"`
-	Name              string                  `json:"name"`
-	DisplayName       string                  `json:"displayName"`
-	FormatedName      string                  `json:"formatedName"`
-	Description       string                  `json:"description,omitempty"`
-	Icon              string                  `json:"icon"`
-	Version           string                  `json:"version"`
-	Category          string                  `json:"category"`
-	Decimals          uint64                  `json:"decimals"`
-	ChainID           uint64                  `json:"chainID"`
-	Endorsed          bool                    `json:"endorsed"`
-	Boosted           bool                    `json:"boosted"`
-	EmergencyShutdown bool                    `json:"emergency_shutdown"`
-	Token             TExternalERC20Token     `json:"token"`
-	TVL               models.TTVL             `json:"tvl"`
-	APR               TExternalVaultAPR       `json:"apr"`
-	Details           TExternalVaultDetails   `json:"details"`
-	Strategies        []TExternalStrategy     `json:"strategies"`
-	Migration         TExternalVaultMigration `json:"migration"`
-	Staking           TStakingData            `json:"staking"`
-	Info              TExternalVaultInfo      `json:"info,omitempty"`
-	FeaturingScore    float64                 `json:"featuringScore"` // Computing only
-	PricePerShare     *bigNumber.Int          `json:"pricePerShare"`
-	Debts             []models.TKongDebt      `json:"debts"`
+	Address            string                          `json:"address"`
+	Type               models.TTokenType               `json:"type"`
+	Kind               models.TVaultKind               `json:"kind"`
+	Symbol             string                          `json:"symbol"`
+	DisplaySymbol      string                          `json:"displaySymbol"`
+	FormatedSymbol     string                          `json:"formatedSymbol"`
+	Name               string                          `json:"name"`
+	DisplayName        string                          `json:"displayName"`
+	FormatedName       string                          `json:"formatedName"`
+	Description        string                          `json:"description,omitempty"`
+	Icon               string                          `json:"icon"`
+	IconIPFS           string                          `json:"iconIPFS,omitempty"`     // ipfs:// URI of the icon, once pinned
+	MetadataIPFS       string                          `json:"metadataIPFS,omitempty"` // ipfs:// URI of the metadata document, once pinned
+	Version            string                          `json:"version"`
+	Category           string                          `json:"category"`
+	Decimals           uint64                          `json:"decimals"`
+	ChainID            uint64                          `json:"chainID"`
+	Endorsed           bool                            `json:"endorsed"`
+	Stage              models.TVaultStage              `json:"stage"`
+	Boosted            bool                            `json:"boosted"`
+	EmergencyShutdown  bool                            `json:"emergency_shutdown"`
+	Token              TExternalERC20Token             `json:"token"`
+	TVL                models.TTVL                     `json:"tvl"`
+	APR                TExternalVaultAPR               `json:"apr"`
+	Details            TExternalVaultDetails           `json:"details"`
+	Strategies         []TExternalStrategy             `json:"strategies"`
+	Migration          TExternalVaultMigration         `json:"migration"`
+	Staking            TStakingData                    `json:"staking"`
+	Info               TExternalVaultInfo              `json:"info,omitempty"`
+	FeaturingScore     float64                         `json:"featuringScore"` // Computing only
+	PricePerShare      *bigNumber.Int                  `json:"pricePerShare"`
+	Debts              []models.TKongDebt              `json:"debts"`
+	EntryExitFeeBps    uint64                          `json:"entryExitFeeBps,omitempty"`     // Entry + exit fees charged by the external vaults used by the strategies
+	DataFreshness      *storage.TDataFreshness         `json:"dataFreshness,omitempty"`       // Set when the data of the chain lags behind its head
+	DeprecatedChain    bool                            `json:"deprecatedChain,omitempty"`     // Set when the chain is in sunset mode, only kept for the withdrawals
+	HolderStats        *holders.THolderStats           `json:"holderStats,omitempty"`         // Distribution of the shares among the holders, once indexed
+	PendingFees        *fees.TPendingFees              `json:"pendingFees,omitempty"`         // Fees applied by the accountant, not charged yet
+	AccountantConfig   *fees.TAccountantConfig         `json:"accountantConfig,omitempty"`    // Only v3 | Default and custom fee configs of the strategies, from the accountant
+	Liquidity          *liquidity.TWithdrawalLiquidity `json:"withdrawalLiquidity,omitempty"` // Only v3 | The assets withdrawable without exceeding the liquidity of the vault
+	Inception          *inception.TInception           `json:"inception,omitempty"`           // Creation of the vault and return since then, once backfilled
+	RecentLoss         *losses.TLoss                   `json:"recentLoss,omitempty"`          // Last loss reported by a strategy, in the last 30 days
+	RiskAdjustedReturn *float64                        `json:"riskAdjustedReturn,omitempty"`  // Mean of the weekly returns over 90 days divided by their standard deviation
+	Display            *TVaultDisplay                  `json:"display,omitempty"`             // Headline APY picked by the display policy, with the policy query parameter
 }
 
 /**************************************************************************************************
@@ -271,9 +349,12 @@ type TExternalVault struct {
 ** including the total assets in raw form, the calculated TVL in USD, and the token price.
 **************************************************************************************************/
 type TSimplifiedExternalVaultTVL struct {
-	TotalAssets *bigNumber.Int `json:"totalAssets"`
-	TVL         float64        `json:"tvl"`
-	Price       float64        `json:"price"`
+	TotalAssets     *bigNumber.Int         `json:"totalAssets"`
+	TVL             float64                `json:"tvl"`
+	Price           float64                `json:"price"`
+	Breakdown       []models.TTVLComponent `json:"breakdown,omitempty"`
+	IsPriceStale    bool                   `json:"isPriceStale,omitempty"`
+	PriceStaleSince int64                  `json:"priceStaleSince,omitempty"`
 }
 
 /**************************************************************************************************
@@ -329,25 +410,43 @@ type TStakingData struct {
 ** token information, TVL, APR, strategies, and metadata.
 **************************************************************************************************/
 type TSimplifiedExternalVault struct {
-	Address        string                        `json:"address"`
-	Type           models.TTokenType             `json:"type"`
-	Kind           models.TVaultKind             `json:"kind"`
-	Symbol         string                        `json:"symbol"`
-	Name           string                        `json:"name"`
-	Category       string                        `json:"category"`
-	Version        string                        `json:"version"`
-	Description    string                        `json:"description,omitempty"`
-	Decimals       uint64                        `json:"decimals"`
-	ChainID        uint64                        `json:"chainID"`
-	Token          TSimplifiedExternalERC20Token `json:"token"`
-	TVL            TSimplifiedExternalVaultTVL   `json:"tvl"`
-	APR            TExternalVaultAPR             `json:"apr"`
-	Strategies     []TExternalStrategy           `json:"strategies"`
-	Staking        TStakingData                  `json:"staking,omitempty"`
-	Migration      TExternalVaultMigration       `json:"migration,omitempty"`
-	FeaturingScore float64                       `json:"featuringScore"`
-	PricePerShare  *bigNumber.Int                `json:"pricePerShare"`
-	Info           TExternalVaultInfo            `json:"info,omitempty"`
+	Address            string                          `json:"address"`
+	Type               models.TTokenType               `json:"type"`
+	Kind               models.TVaultKind               `json:"kind"`
+	Symbol             string                          `json:"symbol"`
+	Name               string                          `json:"name"`
+	Category           string                          `json:"category"`
+	Version            string                          `json:"version"`
+	Description        string                          `json:"description,omitempty"`
+	Decimals           uint64                          `json:"decimals"`
+	ChainID            uint64                          `json:"chainID"`
+	Token              TSimplifiedExternalERC20Token   `json:"token"`
+	TVL                TSimplifiedExternalVaultTVL     `json:"tvl"`
+	APR                TExternalVaultAPR               `json:"apr"`
+	Strategies         []TExternalStrategy             `json:"strategies"`
+	Staking            TStakingData                    `json:"staking,omitempty"`
+	Migration          TExternalVaultMigration         `json:"migration,omitempty"`
+	FeaturingScore     float64                         `json:"featuringScore"`
+	PricePerShare      *bigNumber.Int                  `json:"pricePerShare"`
+	Info               TExternalVaultInfo              `json:"info,omitempty"`
+	EntryExitFeeBps    uint64                          `json:"entryExitFeeBps,omitempty"`
+	Stage              models.TVaultStage              `json:"stage"`
+	APYDelta24h        *float64                        `json:"apyDelta24h,omitempty"`         // Change of the APY over 24h, in points (0.01 = +1%)
+	TVLDelta24h        *float64                        `json:"tvlDelta24h,omitempty"`         // Relative change of the TVL over 24h (0.05 = +5%)
+	TVLDelta7d         *float64                        `json:"tvlDelta7d,omitempty"`          // Relative change of the TVL over 7 days
+	DataFreshness      *storage.TDataFreshness         `json:"dataFreshness,omitempty"`       // Set when the data of the chain lags behind its head
+	DeprecatedChain    bool                            `json:"deprecatedChain,omitempty"`     // Set when the chain is in sunset mode, only kept for the withdrawals
+	HolderStats        *holders.THolderStats           `json:"holderStats,omitempty"`         // Distribution of the shares among the holders, once indexed
+	PendingFees        *fees.TPendingFees              `json:"pendingFees,omitempty"`         // Fees applied by the accountant, not charged yet
+	AccountantConfig   *fees.TAccountantConfig         `json:"accountantConfig,omitempty"`    // Only v3 | Default and custom fee configs of the strategies, from the accountant
+	Liquidity          *liquidity.TWithdrawalLiquidity `json:"withdrawalLiquidity,omitempty"` // Only v3 | The assets withdrawable without exceeding the liquidity of the vault
+	Inception          *inception.TInception           `json:"inception,omitempty"`           // Creation of the vault and return since then, once backfilled
+	RecentLoss         *losses.TLoss                   `json:"recentLoss,omitempty"`          // Last loss reported by a strategy, in the last 30 days
+	RiskAdjustedReturn *float64                        `json:"riskAdjustedReturn,omitempty"`  // Mean of the weekly returns over 90 days divided by their standard deviation
+	Display            *TVaultDisplay                  `json:"display,omitempty"`             // Headline APY picked by the display policy, with the policy query parameter
+	Attestation        *attestation.TAttestation       `json:"attestation,omitempty"`         // Signature of the APY and price by the operator, if enabled
+	Governance         *governance.TVaultGovernance    `json:"governance,omitempty"`          // Role holders and role changes of a v3 vault, on the single vault routes
+	Partner            *storage.TPartnerFields         `json:"partner,omitempty"`             // Deposit contract and referral code of the partner, on the partner views
 }
 
 /************************************************************************************************
@@ -399,6 +498,9 @@ func ApplyKongData(externalVault *TExternalVault, vault models.TVault) {
 ** - PricePerShare: Token value growth data for verification
 ** - Extra: Additional yield sources (staking rewards, protocol rewards)
 ** - ForwardAPR: Projected future yield information
+** - FeeImpact: Gross APR, net APR and the fee drag between them
+** - GasImpact: Forward APY before and after the amortized harvest costs, for the small vaults
+** - RewardContributions: Part of the APY paid in each reward token, with its price sensitivity
 **
 ** @param vault models.TVault - The vault containing fee information
 ** @param vaultAPY apr.TVaultAPY - The internal APY structure to convert
@@ -418,12 +520,19 @@ func assignVaultAPR(vault models.TVault, vaultAPY apr.TVaultAPY) TExternalVaultA
 		Points:        vaultAPY.Points,
 		PricePerShare: vaultAPY.PricePerShare,
 		Extra: TExternalExtraRewards{
-			StakingRewardsAPR: vaultAPY.Extra.StakingRewardsAPY,
-			GammaRewardAPR:    vaultAPY.Extra.GammaRewardAPY,
+			StakingRewardsAPR:  vaultAPY.Extra.StakingRewardsAPY,
+			GammaRewardAPR:     vaultAPY.Extra.GammaRewardAPY,
+			UnderlyingAssetAPR: vaultAPY.Extra.UnderlyingAssetAPR,
 		},
 		ForwardAPR: TExternalForwardAPR{
-			Type:   vaultAPY.ForwardAPY.Type,
-			NetAPR: vaultAPY.ForwardAPY.NetAPY,
+			Type:               vaultAPY.ForwardAPY.Type,
+			NetAPR:             vaultAPY.ForwardAPY.NetAPY,
+			NetAPRDeployedOnly: vaultAPY.ForwardAPY.NetAPYDeployedOnly,
+			IdleRatio:          vaultAPY.ForwardAPY.IdleRatio,
+			PrimarySource:      string(vaultAPY.ForwardAPY.PrimarySource),
+			TotalAPR:           vaultAPY.ForwardAPY.TotalAPY,
+			CompoundingPeriods: vaultAPY.ForwardAPY.CompoundingPeriods,
+			DivergenceWarning:  vaultAPY.ForwardAPY.DivergenceWarning,
 			Composite: TExternalCompositeData{
 				Boost:                 vaultAPY.ForwardAPY.Composite.Boost,
 				PoolAPY:               vaultAPY.ForwardAPY.Composite.PoolAPY,
@@ -433,8 +542,17 @@ func assignVaultAPR(vault models.TVault, vaultAPY apr.TVaultAPY) TExternalVaultA
 				RewardsAPR:            vaultAPY.ForwardAPY.Composite.RewardsAPY,
 				V3OracleCurrentAPR:    vaultAPY.ForwardAPY.Composite.V3OracleCurrentAPR,
 				V3OracleStratRatioAPR: vaultAPY.ForwardAPY.Composite.V3OracleStratRatioAPR,
+				EmissionsMinBoostAPR:  vaultAPY.ForwardAPY.Composite.EmissionsMinBoostAPR,
+				EmissionsMaxBoostAPR:  vaultAPY.ForwardAPY.Composite.EmissionsMaxBoostAPR,
+				APROverrides:          vaultAPY.ForwardAPY.Composite.APROverrides,
 			},
+			simpleNetAPR:     vaultAPY.ForwardAPY.NetAPR,
+			compoundedNetAPY: vaultAPY.ForwardAPY.NetAPY,
 		},
+		FeeImpact: vaultAPY.FeeImpact,
+		GasImpact: vaultAPY.GasImpact,
+
+		RewardContributions: vaultAPY.RewardContributions,
 	}
 }
 
@@ -481,7 +599,7 @@ func CreateExternalVault(vault models.TVault) (TExternalVault, error) {
 		EmergencyShutdown: vault.EmergencyShutdown,
 		ChainID:           vault.ChainID,
 		TVL:               fetcher.BuildVaultTVL(vault),
-		Migration:         toTExternalVaultMigration(vault.Metadata.Migration),
+		Migration:         toResolvedExternalVaultMigration(vault),
 		Symbol:            symbol,
 		DisplaySymbol:     displaySymbol,
 		FormatedSymbol:    formatedSymbol,
@@ -497,7 +615,7 @@ func CreateExternalVault(vault models.TVault) (TExternalVault, error) {
 		PricePerShare:     vault.LastPricePerShare,
 		Debts:             vault.Debts,
 		Details: TExternalVaultDetails{
-			IsRetired:       vault.Metadata.IsRetired,
+			IsRetired:       models.IsVaultRetired(vault),
 			IsHidden:        vault.Metadata.IsHidden,
 			IsAggregator:    vault.Metadata.IsAggregator,
 			IsBoosted:       vault.Metadata.IsBoosted,
@@ -516,6 +634,12 @@ func CreateExternalVault(vault models.TVault) (TExternalVault, error) {
 		},
 	}
 
+	// Set lifecycle stage
+	externalVault.Stage = vault.Stage
+	if externalVault.Stage == `` {
+		externalVault.Stage = fetcher.BuildVaultStage(vault, externalVault.TVL.TVL, ``)
+	}
+
 	// Set staking data
 	externalVault.Staking = assignStakingData(vault.ChainID, vault.Address)
 
@@ -526,6 +650,55 @@ func CreateExternalVault(vault models.TVault) (TExternalVault, error) {
 	asyncAPR, ok := apr.GetComputedAPY(vault.ChainID, vault.Address)
 	if ok {
 		externalVault.APR = assignVaultAPR(vault, asyncAPR.(apr.TVaultAPY))
+		externalVault.EntryExitFeeBps = asyncAPR.(apr.TVaultAPY).EntryExitFeeBps
+	}
+
+	// Label the data of a lagging chain with its freshness
+	externalVault.DataFreshness = storage.GetLaggingChainFreshness(vault.ChainID)
+	externalVault.DeprecatedChain = env.IsSunsetChain(vault.ChainID)
+
+	// Serve the icon and the metadata pinned to IPFS
+	externalVault.Icon, externalVault.IconIPFS, externalVault.MetadataIPFS = assets.ResolveAssets(vault.ChainID, vault.Address, externalVault.Icon)
+
+	// Set the fees applied by the accountant of the vault, not charged yet
+	if pendingFees, ok := fees.GetPendingFees(vault.ChainID, vault.Address); ok {
+		externalVault.PendingFees = &pendingFees
+	}
+
+	// Set the fee configs of the accountant of the vault
+	if accountantConfig, ok := fees.GetAccountantConfig(vault.ChainID, vault.Address); ok {
+		externalVault.AccountantConfig = &accountantConfig
+	}
+
+	// Set the liquidity of a v3 vault for the withdrawals
+	if withdrawalLiquidity, ok := liquidity.GetWithdrawalLiquidity(vault); ok {
+		externalVault.Liquidity = &withdrawalLiquidity
+	}
+
+	// Set the creation of the vault, backfilled from the archive node
+	if vaultInception, ok := inception.GetVaultInception(vault); ok {
+		externalVault.Inception = &vaultInception
+	}
+
+	// Set the last loss reported by a strategy of the vault
+	if recentLoss, ok := losses.GetRecentLoss(vault.ChainID, vault.Address); ok {
+		externalVault.RecentLoss = &recentLoss
+	}
+
+	// Set the risk-adjusted return of the vault, from its daily APY history
+	externalVault.RiskAdjustedReturn = computeRiskAdjustedReturn(
+		storage.ListVaultDailyAPY(vault.ChainID, vault.Address),
+		uint64(time.Now().Unix()),
+	)
+
+	// Set the distribution of the shares among the holders
+	if holderStats, ok := holders.GetHolderStats(vault.ChainID, vault.Address); ok {
+		externalVault.HolderStats = &holderStats
+	}
+
+	// Set share price warning
+	if anomaly, ok := sharePrice.GetSharePriceWarning(vault.ChainID, vault.Address); ok {
+		externalVault.Info.SharePriceWarning = &anomaly
 	}
 
 	// Set stability defaults
@@ -581,6 +754,27 @@ func toTExternalVaultMigration(migration models.TMigration) TExternalVaultMigrat
 	}
 }
 
+/**************************************************************************************************
+** toResolvedExternalVaultMigration returns the migration of a vault resolved from the overrides,
+** the CMS and the registry, falling back to the migration of its metadata when none is resolved.
+**
+** @param vault models.TVault - The vault to get the migration for
+** @return TExternalVaultMigration - The migration of the vault in the external format
+**************************************************************************************************/
+func toResolvedExternalVaultMigration(vault models.TVault) TExternalVaultMigration {
+	migration, ok := migrations.GetMigration(vault.ChainID, vault.Address)
+	if !ok {
+		return toTExternalVaultMigration(vault.Metadata.Migration)
+	}
+	return TExternalVaultMigration{
+		Available: true,
+		Address:   migration.Target.Hex(),
+		Contract:  migration.Contract.Hex(),
+		Source:    migration.Source,
+		APYDelta:  migration.APYDelta,
+	}
+}
+
 /**************************************************************************************************
 ** getUnderlyingTokenInfo retrieves and formats token information for a vault.
 **
@@ -636,6 +830,7 @@ func getUnderlyingTokenInfo(chainID uint64, vaultAddress, assetAddress common.Ad
 ** @return TExternalERC20Token - The formatted external token structure
 **************************************************************************************************/
 func convertToExternalToken(token models.TERC20Token) TExternalERC20Token {
+	icon, iconIPFS, metadataIPFS := assets.ResolveAssets(token.ChainID, token.Address, token.Icon)
 	return TExternalERC20Token{
 		Address:                   token.Address.Hex(),
 		UnderlyingTokensAddresses: toArrTMixedcaseAddress(token.UnderlyingTokensAddresses),
@@ -645,7 +840,9 @@ func convertToExternalToken(token models.TERC20Token) TExternalERC20Token {
 		DisplayName:               token.DisplayName,
 		DisplaySymbol:             token.DisplaySymbol,
 		Description:               token.Description,
-		Icon:                      token.Icon,
+		Icon:                      icon,
+		IconIPFS:                  iconIPFS,
+		MetadataIPFS:              metadataIPFS,
 		Decimals:                  token.Decimals,
 	}
 }
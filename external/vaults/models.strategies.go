@@ -1,8 +1,12 @@
 package vaults
 
 import (
+	"time"
+
 	"github.com/yearn/ydaemon/common/bigNumber"
 	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/processes/keepers"
+	"github.com/yearn/ydaemon/processes/simulations"
 )
 
 /**************************************************************************************************
@@ -19,15 +23,48 @@ import (
 ** @field LastReport uint64 - Timestamp of the last strategy report to its vault
 ** @field DebtRatio uint64 - The percentage of vault funds allocated to this strategy (for v0.2.2+)
 ** @field InQueue bool - Whether the strategy is in the vault's withdrawal queue
+** @field MaxDebt *bigNumber.Int - The max_debt of the strategy in its vault (v3 only), TotalDebt
+** being its current_debt
+** @field Utilization *float64 - The current_debt over the max_debt (v3 only, unset when the
+** max_debt is 0), 1 meaning the strategy has no capacity left
+** @field SecondsSinceReport uint64 - The time elapsed since the last report, in seconds
+** @field MaxWithdraw *bigNumber.Int - The assets the vault can withdraw from the strategy right
+** now (v3 only)
+** @field UnrealisedLoss *bigNumber.Int - The share of its debt the vault would lose withdrawing it
+** all (v3 only)
 **************************************************************************************************/
 type TExternalStrategyDetails struct {
-	TotalDebt      *bigNumber.Int `json:"totalDebt"`
-	TotalLoss      *bigNumber.Int `json:"totalLoss"`
-	TotalGain      *bigNumber.Int `json:"totalGain"`
-	PerformanceFee uint64         `json:"performanceFee"`
-	LastReport     uint64         `json:"lastReport"`
-	DebtRatio      uint64         `json:"debtRatio,omitempty"` // Only > 0.2.2
-	InQueue        bool           `json:"-"`
+	TotalDebt          *bigNumber.Int `json:"totalDebt"`
+	TotalLoss          *bigNumber.Int `json:"totalLoss"`
+	TotalGain          *bigNumber.Int `json:"totalGain"`
+	PerformanceFee     uint64         `json:"performanceFee"`
+	LastReport         uint64         `json:"lastReport"`
+	DebtRatio          uint64         `json:"debtRatio,omitempty"` // Only > 0.2.2
+	MaxDebt            *bigNumber.Int `json:"maxDebt,omitempty"`   // Only v3
+	Utilization        *float64       `json:"utilization,omitempty"`
+	SecondsSinceReport uint64         `json:"secondsSinceReport,omitempty"`
+	MaxWithdraw        *bigNumber.Int `json:"maxWithdraw,omitempty"`    // Only v3
+	UnrealisedLoss     *bigNumber.Int `json:"unrealisedLoss,omitempty"` // Only v3
+	InQueue            bool           `json:"-"`
+}
+
+/**************************************************************************************************
+** TExternalStrategyExtra contains optional data about a strategy that is not always available.
+**
+** @field PendingProfit *bigNumber.Float - The expected gain of the next report, in the strategy
+** asset, obtained by simulating the next `report()` call. Only set when a simulation API is
+** configured.
+** @field PendingLoss *bigNumber.Float - The expected loss of the next report, in the strategy asset
+** @field Keeper *keepers.TKeeperStatus - The keeper of the strategy, its type, the state of the
+** harvest trigger and the next expected harvest
+** @field Tend *keepers.TTendActivity - The tends of the strategy between its harvests, over the
+** last 30 days, for the strategies tended by their keeper
+**************************************************************************************************/
+type TExternalStrategyExtra struct {
+	PendingProfit *bigNumber.Float       `json:"pendingProfit,omitempty"`
+	PendingLoss   *bigNumber.Float       `json:"pendingLoss,omitempty"`
+	Keeper        *keepers.TKeeperStatus `json:"keeper,omitempty"`
+	Tend          *keepers.TTendActivity `json:"tend,omitempty"`
 }
 
 /**************************************************************************************************
@@ -41,7 +78,9 @@ type TExternalStrategyDetails struct {
 ** @field Name string - The human-readable name of the strategy
 ** @field Description string - A description of the strategy's approach and mechanisms
 ** @field Status string - The operational status of the strategy (active, not_active, unallocated)
+** @field Protocols []string - The protocols the strategy is exposed to
 ** @field Details *TExternalStrategyDetails - Detailed performance and configuration metrics
+** @field Extra *TExternalStrategyExtra - Optional data, like the simulated pending profit
 **************************************************************************************************/
 type TExternalStrategy struct {
 	Address     string                    `json:"address"`
@@ -49,7 +88,9 @@ type TExternalStrategy struct {
 	Description string                    `json:"description,omitempty"`
 	Status      string                    `json:"status"`
 	NetAPR      float64                   `json:"netAPR,omitempty"`
+	Protocols   []string                  `json:"protocols,omitempty"`
 	Details     *TExternalStrategyDetails `json:"details,omitempty"`
+	Extra       *TExternalStrategyExtra   `json:"extra,omitempty"`
 }
 
 /**************************************************************************************************
@@ -78,21 +119,58 @@ func CreateExternalStrategy(strategy models.TStrategy) TExternalStrategy {
 		}
 	}
 
+	var extra *TExternalStrategyExtra
+	if pendingReport, ok := simulations.GetPendingReport(strategy.ChainID, strategy.Address); ok {
+		extra = &TExternalStrategyExtra{
+			PendingProfit: pendingReport.PendingProfit,
+			PendingLoss:   pendingReport.PendingLoss,
+		}
+	}
+	if keeperStatus, ok := keepers.GetKeeperStatus(strategy.ChainID, strategy.Address); ok {
+		if extra == nil {
+			extra = &TExternalStrategyExtra{}
+		}
+		extra.Keeper = &keeperStatus
+	}
+	if tendActivity, ok := keepers.GetTendActivity(strategy.ChainID, strategy.Address); ok {
+		if extra == nil {
+			extra = &TExternalStrategyExtra{}
+		}
+		extra.Tend = &tendActivity
+	}
+
+	details := &TExternalStrategyDetails{
+		TotalDebt:      strategy.LastTotalDebt,
+		TotalLoss:      strategy.LastTotalLoss,
+		TotalGain:      strategy.LastTotalGain,
+		PerformanceFee: strategy.LastPerformanceFee.Uint64(),
+		LastReport:     strategy.LastReport.Uint64(),
+		DebtRatio:      strategy.LastDebtRatio.Uint64(),
+		MaxDebt:        strategy.LastMaxDebt,
+		MaxWithdraw:    strategy.LastMaxWithdraw,
+		UnrealisedLoss: strategy.LastUnrealisedLoss,
+		InQueue:        strategy.IsInQueue,
+	}
+	if strategy.LastMaxDebt != nil && !strategy.LastMaxDebt.IsZero() && strategy.LastTotalDebt != nil {
+		utilization, _ := bigNumber.NewFloat(0).Div(
+			bigNumber.NewFloat(0).SetInt(strategy.LastTotalDebt),
+			bigNumber.NewFloat(0).SetInt(strategy.LastMaxDebt),
+		).Float64()
+		details.Utilization = &utilization
+	}
+	if now := uint64(time.Now().Unix()); details.LastReport > 0 && details.LastReport < now {
+		details.SecondsSinceReport = now - details.LastReport
+	}
+
 	return TExternalStrategy{
 		Address:     strategy.Address.Hex(),
 		Name:        name,
 		Description: strategy.Description,
 		Status:      status,
 		NetAPR:      strategy.NetAPR,
-		Details: &TExternalStrategyDetails{
-			TotalDebt:      strategy.LastTotalDebt,
-			TotalLoss:      strategy.LastTotalLoss,
-			TotalGain:      strategy.LastTotalGain,
-			PerformanceFee: strategy.LastPerformanceFee.Uint64(),
-			LastReport:     strategy.LastReport.Uint64(),
-			DebtRatio:      strategy.LastDebtRatio.Uint64(),
-			InQueue:        strategy.IsInQueue,
-		},
+		Protocols:   strategy.Protocols,
+		Details:     details,
+		Extra:       extra,
 	}
 }
 
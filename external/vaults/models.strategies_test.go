@@ -11,7 +11,7 @@ import (
 
 /**************************************************************************************************
 ** TestCreateExternalStrategy tests the CreateExternalStrategy function to verify it properly converts
-** an internal strategy model to the external TStrategy format.
+** an internal strategy model to the external TExternalStrategy format.
 **************************************************************************************************/
 func TestCreateExternalStrategy(t *testing.T) {
 	// Create test data
@@ -75,13 +75,13 @@ func TestStrategyShouldBeIncluded(t *testing.T) {
 	// Create various test cases
 	testCases := []struct {
 		name           string
-		strategy       TStrategy
+		strategy       TExternalStrategy
 		condition      string
 		expectedResult bool
 	}{
 		{
 			name: "All condition",
-			strategy: TStrategy{
+			strategy: TExternalStrategy{
 				Details: &TExternalStrategyDetails{
 					TotalDebt: bigNumber.NewInt(0),
 					DebtRatio: 0,
@@ -93,7 +93,7 @@ func TestStrategyShouldBeIncluded(t *testing.T) {
 		},
 		{
 			name: "Absolute condition with debt",
-			strategy: TStrategy{
+			strategy: TExternalStrategy{
 				Details: &TExternalStrategyDetails{
 					TotalDebt: bigNumber.NewInt(100),
 					DebtRatio: 0,
@@ -105,7 +105,7 @@ func TestStrategyShouldBeIncluded(t *testing.T) {
 		},
 		{
 			name: "Absolute condition without debt",
-			strategy: TStrategy{
+			strategy: TExternalStrategy{
 				Details: &TExternalStrategyDetails{
 					TotalDebt: bigNumber.NewInt(0),
 					DebtRatio: 0,
@@ -117,7 +117,7 @@ func TestStrategyShouldBeIncluded(t *testing.T) {
 		},
 		{
 			name: "InQueue condition with strategy in queue",
-			strategy: TStrategy{
+			strategy: TExternalStrategy{
 				Details: &TExternalStrategyDetails{
 					TotalDebt: bigNumber.NewInt(0),
 					DebtRatio: 0,
@@ -129,7 +129,7 @@ func TestStrategyShouldBeIncluded(t *testing.T) {
 		},
 		{
 			name: "InQueue condition with strategy not in queue",
-			strategy: TStrategy{
+			strategy: TExternalStrategy{
 				Details: &TExternalStrategyDetails{
 					TotalDebt: bigNumber.NewInt(0),
 					DebtRatio: 0,
@@ -141,7 +141,7 @@ func TestStrategyShouldBeIncluded(t *testing.T) {
 		},
 		{
 			name: "DebtRatio condition with positive debt ratio",
-			strategy: TStrategy{
+			strategy: TExternalStrategy{
 				Details: &TExternalStrategyDetails{
 					TotalDebt: bigNumber.NewInt(0),
 					DebtRatio: 5000,
@@ -153,7 +153,7 @@ func TestStrategyShouldBeIncluded(t *testing.T) {
 		},
 		{
 			name: "DebtRatio condition with zero debt ratio",
-			strategy: TStrategy{
+			strategy: TExternalStrategy{
 				Details: &TExternalStrategyDetails{
 					TotalDebt: bigNumber.NewInt(0),
 					DebtRatio: 0,
@@ -165,7 +165,7 @@ func TestStrategyShouldBeIncluded(t *testing.T) {
 		},
 		{
 			name: "Unknown condition",
-			strategy: TStrategy{
+			strategy: TExternalStrategy{
 				Details: &TExternalStrategyDetails{
 					TotalDebt: bigNumber.NewInt(100),
 					DebtRatio: 5000,
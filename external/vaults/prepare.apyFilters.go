@@ -0,0 +1,98 @@
+package vaults
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/helpers"
+)
+
+/**************************************************************************************************
+** tAPYFilters holds the optional APY based filters of the list endpoints. A nil value means the
+** filter is not set. The APY values are expressed as fractions (0.05 = 5%).
+**************************************************************************************************/
+type tAPYFilters struct {
+	MinNetAPY         *float64
+	MaxNetAPY         *float64
+	MinForwardAPY     *float64
+	MaxForwardAPY     *float64
+	HasStakingRewards *bool
+}
+
+/**************************************************************************************************
+** parseOptionalFloatQuery reads an optional float query parameter. Invalid values are reported
+** and ignored.
+**************************************************************************************************/
+func parseOptionalFloatQuery(c *gin.Context, paramName string) *float64 {
+	paramValue := getQueryParam(c, paramName)
+	if paramValue == "" {
+		return nil
+	}
+	value, err := strconv.ParseFloat(paramValue, 64)
+	if err != nil {
+		c.Error(fmt.Errorf("invalid %s parameter: %s, ignoring", paramName, paramValue))
+		return nil
+	}
+	return &value
+}
+
+/**************************************************************************************************
+** validateAPYFilters extracts the APY based filters from the query parameters:
+** - minNetAPY/maxNetAPY: bounds on the historical net APY
+** - minForwardAPY/maxForwardAPY: bounds on the forward net APY
+** - hasStakingRewards: only keep the vaults with (or without) a staking opportunity
+**
+** @param c *gin.Context - The Gin context containing the request
+** @return tAPYFilters - The filters to apply
+**************************************************************************************************/
+func validateAPYFilters(c *gin.Context) tAPYFilters {
+	filters := tAPYFilters{
+		MinNetAPY:     parseOptionalFloatQuery(c, `minNetAPY`),
+		MaxNetAPY:     parseOptionalFloatQuery(c, `maxNetAPY`),
+		MinForwardAPY: parseOptionalFloatQuery(c, `minForwardAPY`),
+		MaxForwardAPY: parseOptionalFloatQuery(c, `maxForwardAPY`),
+	}
+	if hasStakingRewards := getQueryParam(c, `hasStakingRewards`); hasStakingRewards != "" {
+		value := helpers.StringToBool(hasStakingRewards)
+		filters.HasStakingRewards = &value
+	}
+	return filters
+}
+
+/**************************************************************************************************
+** isInRange checks that an APY is within the optional bounds. A missing APY is considered as 0.
+**************************************************************************************************/
+func isInRange(apy *bigNumber.Float, minValue *float64, maxValue *float64) bool {
+	value := 0.0
+	if apy != nil {
+		value, _ = apy.Float64()
+	}
+	if minValue != nil && value < *minValue {
+		return false
+	}
+	if maxValue != nil && value > *maxValue {
+		return false
+	}
+	return true
+}
+
+/**************************************************************************************************
+** matches returns true if the vault satisfies all the APY based filters.
+**
+** @param vault TExternalVault - The vault to check
+** @return bool - True if the vault should be kept
+**************************************************************************************************/
+func (filters tAPYFilters) matches(vault TExternalVault) bool {
+	if !isInRange(vault.APR.NetAPR, filters.MinNetAPY, filters.MaxNetAPY) {
+		return false
+	}
+	if !isInRange(vault.APR.ForwardAPR.NetAPR, filters.MinForwardAPY, filters.MaxForwardAPY) {
+		return false
+	}
+	if filters.HasStakingRewards != nil && vault.Staking.Available != *filters.HasStakingRewards {
+		return false
+	}
+	return true
+}
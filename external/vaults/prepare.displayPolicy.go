@@ -0,0 +1,122 @@
+package vaults
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The frontends do not all want the same headline APY: some show the APY with the staking rewards
+** and the max boost, some a smoothed one, some hide the dust APYs. A display policy, selected with
+** the policy query parameter, picks which of the computed APYs is surfaced as display.apy. The raw
+** components stay in apr, whatever the policy.
+**
+** The headline APY is the forward net APY when the vault has one, the historical net APY
+** otherwise, like the APY figure endpoint. A policy can then:
+** - IncludeStaking: add the APR of the staking rewards,
+** - IncludeMaxBoost: count the dYFI emissions of the veYFI gauge at the max boost, in place of
+**   the staking rewards they are paid as,
+** - Smoothed: cap it to the median of the daily APYs of the last DISPLAY_SMOOTHING_DAYS days,
+** - HideBelow: return a null APY when it is below this fraction.
+**************************************************************************************************/
+type TDisplayPolicy struct {
+	IncludeStaking  bool
+	IncludeMaxBoost bool
+	Smoothed        bool
+	HideBelow       float64
+}
+
+const DISPLAY_POLICY_NONE = ``
+const DISPLAY_SMOOTHING_DAYS = 30
+
+const (
+	DISPLAY_SOURCE_FORWARD    = APY_FIGURE_FORWARD
+	DISPLAY_SOURCE_HISTORICAL = APY_FIGURE_HISTORICAL
+	DISPLAY_SOURCE_SMOOTHED   = `smoothed`
+)
+
+var DISPLAY_POLICIES = map[string]TDisplayPolicy{
+	`default`:      {},
+	`boosted`:      {IncludeStaking: true, IncludeMaxBoost: true},
+	`conservative`: {Smoothed: true, HideBelow: 0.001},
+}
+
+/**************************************************************************************************
+** TVaultDisplay is the APY to display for a vault with the policy asked. APY is null when hidden
+** by the policy, and Source is the APY it is based on: forward, historical or smoothed.
+**************************************************************************************************/
+type TVaultDisplay struct {
+	APY    *float64 `json:"apy"`
+	Policy string   `json:"policy"`
+	Source string   `json:"source"`
+}
+
+/**************************************************************************************************
+** listDisplayPolicies returns the names of the display policies, sorted for the validation.
+**************************************************************************************************/
+func listDisplayPolicies() []string {
+	names := make([]string, 0, len(DISPLAY_POLICIES))
+	for name := range DISPLAY_POLICIES {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/**************************************************************************************************
+** computeVaultDisplay applies a display policy to the APR of a vault. It does not depend on the
+** yieldFormat query parameter, the compounded forward APY being kept aside. It returns nil when no
+** policy was asked.
+**************************************************************************************************/
+func computeVaultDisplay(chainID uint64, address string, vaultAPR TExternalVaultAPR, policyName string) *TVaultDisplay {
+	policy, ok := DISPLAY_POLICIES[policyName]
+	if !ok {
+		return nil
+	}
+
+	display := &TVaultDisplay{Policy: policyName, Source: DISPLAY_SOURCE_HISTORICAL}
+	headline := vaultAPR.NetAPR
+	if vaultAPR.ForwardAPR.Type != `` && vaultAPR.ForwardAPR.compoundedNetAPY != nil {
+		headline = vaultAPR.ForwardAPR.compoundedNetAPY
+		display.Source = DISPLAY_SOURCE_FORWARD
+	}
+	if headline == nil {
+		return display
+	}
+
+	apy, _ := headline.Float64()
+	stakingAPY := 0.0
+	if policy.IncludeStaking {
+		stakingAPY = floatOrZero(vaultAPR.Extra.StakingRewardsAPR)
+	}
+	if policy.IncludeMaxBoost && vaultAPR.ForwardAPR.Composite.EmissionsMaxBoostAPR != nil {
+		// The dYFI emissions are the staking rewards of the veYFI gauge: the max boost replaces them
+		stakingAPY = floatOrZero(vaultAPR.ForwardAPR.Composite.EmissionsMaxBoostAPR)
+	}
+	apy += stakingAPY
+	if policy.Smoothed {
+		history := storage.ListVaultDailyAPY(chainID, common.HexToAddress(address))
+		since := uint64(time.Now().AddDate(0, 0, -DISPLAY_SMOOTHING_DAYS).Unix())
+		if stats := computeAPYStats(history, since); stats != nil && stats.Median < apy {
+			apy = stats.Median
+			display.Source = DISPLAY_SOURCE_SMOOTHED
+		}
+	}
+	if apy < policy.HideBelow {
+		return display
+	}
+	display.APY = &apy
+	return display
+}
+
+func floatOrZero(value *bigNumber.Float) float64 {
+	if value == nil {
+		return 0
+	}
+	valueFloat, _ := value.Float64()
+	return valueFloat
+}
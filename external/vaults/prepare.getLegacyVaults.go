@@ -95,6 +95,8 @@ func getLegacyVaults(
 	** from the 'chainID' path parameter in the request.
 	**************************************************************************************************/
 	strategiesCondition := validateStrategyCondition(c, "strategiesCondition")
+	yieldFormat := validateYieldFormat(c, "yieldFormat")
+	displayPolicy := validateDisplayPolicy(c, "policy")
 	migrable := validateMigrableCondition(c, "migrable")
 
 	// Validate chain ID using the utility function
@@ -165,6 +167,8 @@ func getLegacyVaults(
 			newVault.Strategies = append(newVault.Strategies, strategyWithDetails)
 		}
 
+		newVault.Display = computeVaultDisplay(chainID, newVault.Address, newVault.APR, displayPolicy)
+		newVault.APR.applyYieldFormat(yieldFormat)
 		data = append(data, newVault)
 	}
 
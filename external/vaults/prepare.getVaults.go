@@ -30,6 +30,12 @@ import (
 ** - migrable: Condition for including migrable vaults (default: 'none')
 ** - page/limit: Pagination controls (defaults: page 1, limit 200)
 ** - chainIDs: Comma-separated list of chain IDs to include (default: all supported chains)
+** - stages: Comma-separated list of lifecycle stages to include (default: all stages)
+** - minNetAPY/maxNetAPY, minForwardAPY/maxForwardAPY: APY bounds, as fractions (default: none)
+** - hasStakingRewards: Only include vaults with (or without) staking rewards (default: all)
+** - protocols: Comma-separated list of protocols the vaults or their strategies use (default: all)
+**
+** The orderBy parameter accepts nested fields, e.g. `apr.forwardAPR.netAPR`.
 **
 ** @param c *gin.Context - The Gin context containing the HTTP request
 ** @param filterFunc func(vault models.TVault) bool - Function that determines if a vault should be included
@@ -59,17 +65,29 @@ func getVaults(
 	**
 	** strategiesCondition: A string that determines the condition for selecting strategies. It is
 	** obtained from the 'strategiesCondition' query parameter in the request.
+	**
+	** yieldFormat: The optional format of the forward net yield (apr, apy or both). It is obtained
+	** from the 'yieldFormat' query parameter in the request.
+	**
+	** displayPolicy: The optional display policy picking the headline APY set as display.apy. It is
+	** obtained from the 'policy' query parameter in the request.
 	**************************************************************************************************/
 	orderBy := helpers.SafeString(getQueryParam(c, `orderBy`), `featuringScore`)
 	orderDirection := helpers.SafeString(getQueryParam(c, `orderDirection`), `asc`)
 	hideAlways := helpers.StringToBool(getQueryParam(c, `hideAlways`))
 	stratCon := validateStrategyCondition(c, "strategiesCondition")
+	yieldFormat := validateYieldFormat(c, `yieldFormat`)
+	displayPolicy := validateDisplayPolicy(c, `policy`)
+	locale := NegotiateLocale(c)
 
 	/** 🔵 - Yearn *************************************************************************************
 	** migrable: A string that determines the condition for selecting migrable vaults. It is
 	** obtained from the 'migrable' query parameter in the request.
 	**************************************************************************************************/
 	migrable := validateMigrableCondition(c, `migrable`)
+	stages := validateStagesParam(c, `stages`)
+	apyFilters := validateAPYFilters(c)
+	protocols := validateProtocolsParam(c, `protocols`)
 	if migrable != `none` && hideAlways {
 		handleError(c, fmt.Errorf("migrable and hideAlways cannot be true at the same time"),
 			http.StatusBadRequest, "Invalid parameter combination", "GetVaults")
@@ -159,8 +177,13 @@ func getVaults(
 				continue
 			}
 
+			// Apply protocols filter
+			if len(protocols) > 0 && !vaultUsesProtocols(currentVault, protocols) {
+				continue
+			}
+
 			// Skip retired vaults when hideAlways is true
-			if migrable == `none` && currentVault.Metadata.IsRetired && hideAlways {
+			if migrable == `none` && models.IsVaultRetired(currentVault) && hideAlways {
 				continue
 			}
 
@@ -171,6 +194,16 @@ func getVaults(
 				continue
 			}
 
+			// Apply lifecycle stage filter
+			if len(stages) > 0 && !helpers.Contains(stages, newVault.Stage) {
+				continue
+			}
+
+			// Apply APY components filters
+			if !apyFilters.matches(newVault) {
+				continue
+			}
+
 			// Calculate APR and featuring score
 			APRAsFloat := 0.0
 			if newVault.APR.NetAPR != nil {
@@ -183,7 +216,7 @@ func getVaults(
 			}
 
 			// Apply migrable-specific filters
-			if !currentVault.Metadata.IsRetired {
+			if !models.IsVaultRetired(currentVault) {
 				if migrable == MIGRABLE_CONDITION_NO_DUST && (newVault.TVL.TVL < MIN_DUST_TVL || !newVault.Migration.Available) {
 					continue
 				} else if migrable == MIGRABLE_CONDITION_ALL && !newVault.Migration.Available {
@@ -207,6 +240,9 @@ func getVaults(
 			// Convert directly to simplified format
 			simplified := toSimplifiedVersion(newVault, models.TStrategy{})
 			simplified.Description = newVault.Description
+			simplified.Display = computeVaultDisplay(chainID, simplified.Address, simplified.APR, displayPolicy)
+			simplified.APR.applyYieldFormat(yieldFormat)
+			simplified.localize(locale)
 			allVaults = append(allVaults, simplified)
 		}
 	}
@@ -0,0 +1,77 @@
+package vaults
+
+import (
+	"math"
+
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** The risk-adjusted return of a vault compares how much it earns with how steadily it earns it: the
+** mean of its weekly returns over the last RISK_ADJUSTED_RETURN_DAYS days divided by their standard
+** deviation, like a Sharpe ratio without the risk-free rate. A choppy 12% vault scores below a
+** steady 8% one. The weekly return of a week is the average of its daily APYs, compounded over a
+** week. At least RISK_ADJUSTED_RETURN_MIN_WEEKS weeks of history are needed.
+**************************************************************************************************/
+const RISK_ADJUSTED_RETURN_DAYS = 90
+const RISK_ADJUSTED_RETURN_MIN_WEEKS = 4
+
+/**************************************************************************************************
+** listWeeklyReturns returns the weekly returns of the weeks of the daily APY history ending at the
+** day of now, the weeks without any daily APY being skipped.
+**************************************************************************************************/
+func listWeeklyReturns(history []models.TVaultDailyAPY, now uint64, days uint64) []float64 {
+	today := now - now%86400
+	since := today - (days-1)*86400
+	weeks := int((days + 6) / 7)
+	sums := make([]float64, weeks)
+	counts := make([]int, weeks)
+	for _, point := range history {
+		if point.Timestamp < since || point.Timestamp > today {
+			continue
+		}
+		week := int((today - point.Timestamp) / (7 * 86400))
+		sums[week] += point.APY
+		counts[week]++
+	}
+
+	returns := []float64{}
+	for week := weeks - 1; week >= 0; week-- {
+		if counts[week] == 0 {
+			continue
+		}
+		weeklyAPY := sums[week] / float64(counts[week])
+		returns = append(returns, math.Pow(1+weeklyAPY, 1.0/52)-1)
+	}
+	return returns
+}
+
+/**************************************************************************************************
+** computeRiskAdjustedReturn returns the mean of the weekly returns of a vault over the last
+** RISK_ADJUSTED_RETURN_DAYS days divided by their sample standard deviation. It returns nil when
+** the history is too short, or when the returns do not vary at all and the ratio is undefined.
+**************************************************************************************************/
+func computeRiskAdjustedReturn(history []models.TVaultDailyAPY, now uint64) *float64 {
+	returns := listWeeklyReturns(history, now, RISK_ADJUSTED_RETURN_DAYS)
+	if len(returns) < RISK_ADJUSTED_RETURN_MIN_WEEKS {
+		return nil
+	}
+
+	mean := 0.0
+	for _, weeklyReturn := range returns {
+		mean += weeklyReturn
+	}
+	mean /= float64(len(returns))
+
+	variance := 0.0
+	for _, weeklyReturn := range returns {
+		variance += (weeklyReturn - mean) * (weeklyReturn - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(returns)-1))
+	if stddev == 0 || math.IsNaN(stddev) {
+		return nil
+	}
+
+	riskAdjustedReturn := mean / stddev
+	return &riskAdjustedReturn
+}
@@ -209,6 +209,10 @@ func toSimplifiedVersion(
 		info.RiskScoreComment = cachedRiskScore.RiskScore.Comment
 	}
 
+	// Rate of change of the APY and TVL, from the rolling history of the vault
+	deltas24h := getVaultDeltas(vault.ChainID, common.HexToAddress(vault.Address), moversWindows[MOVERS_WINDOW_24H])
+	deltas7d := getVaultDeltas(vault.ChainID, common.HexToAddress(vault.Address), moversWindows[MOVERS_WINDOW_7D])
+
 	// Create the simplified vault directly without intermediate objects
 	return TSimplifiedExternalVault{
 		Address:        vault.Address,
@@ -226,14 +230,31 @@ func toSimplifiedVersion(
 		FeaturingScore: vault.FeaturingScore,
 		Token:          tokenInfo,
 		TVL: TSimplifiedExternalVaultTVL{
-			TotalAssets: vault.TVL.TotalAssets,
-			TVL:         vault.TVL.TVL,
-			Price:       vault.TVL.Price,
+			TotalAssets:     vault.TVL.TotalAssets,
+			TVL:             vault.TVL.TVL,
+			Price:           vault.TVL.Price,
+			Breakdown:       vault.TVL.Breakdown,
+			IsPriceStale:    vault.TVL.IsPriceStale,
+			PriceStaleSince: vault.TVL.PriceStaleSince,
 		},
-		Strategies:    vault.Strategies,
-		Staking:       assignStakingData(vault.ChainID, common.HexToAddress(vault.Address)),
-		Info:          info,
-		PricePerShare: vault.PricePerShare,
+		Strategies:         vault.Strategies,
+		Staking:            assignStakingData(vault.ChainID, common.HexToAddress(vault.Address)),
+		Info:               info,
+		PricePerShare:      vault.PricePerShare,
+		EntryExitFeeBps:    vault.EntryExitFeeBps,
+		Stage:              vault.Stage,
+		APYDelta24h:        deltas24h.APYDelta,
+		TVLDelta24h:        deltas24h.TVLDelta,
+		TVLDelta7d:         deltas7d.TVLDelta,
+		DataFreshness:      vault.DataFreshness,
+		DeprecatedChain:    vault.DeprecatedChain,
+		HolderStats:        vault.HolderStats,
+		PendingFees:        vault.PendingFees,
+		AccountantConfig:   vault.AccountantConfig,
+		Liquidity:          vault.Liquidity,
+		Inception:          vault.Inception,
+		RecentLoss:         vault.RecentLoss,
+		RiskAdjustedReturn: vault.RiskAdjustedReturn,
 	}
 }
 
@@ -0,0 +1,199 @@
+package vaults
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/common/sort"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** DEFILLAMA_PROJECT is the slug of the Yearn project on DefiLlama.
+**************************************************************************************************/
+const DEFILLAMA_PROJECT = `yearn-finance`
+
+/**************************************************************************************************
+** DEFILLAMA_CHAIN_NAMES maps the supported chain IDs to the chain names used by DefiLlama in the
+** yields and TVL adapters.
+**************************************************************************************************/
+var DEFILLAMA_CHAIN_NAMES = map[uint64]string{
+	1:      `Ethereum`,
+	10:     `Optimism`,
+	100:    `xDai`,
+	137:    `Polygon`,
+	146:    `Sonic`,
+	250:    `Fantom`,
+	1101:   `Polygon zkEVM`,
+	8453:   `Base`,
+	42161:  `Arbitrum`,
+	747474: `Katana`,
+}
+
+/**************************************************************************************************
+** defiLlamaUnnamedChains holds the supported chains missing from DEFILLAMA_CHAIN_NAMES, so they
+** are only reported once.
+**************************************************************************************************/
+var defiLlamaUnnamedChains sync.Map
+
+/**************************************************************************************************
+** TDefiLlamaYieldPool is a vault formatted as a pool of the DefiLlama yields adapters. The APYs
+** are expressed in percent, as expected by DefiLlama (5 = 5%).
+**************************************************************************************************/
+type TDefiLlamaYieldPool struct {
+	Pool             string   `json:"pool"`
+	Chain            string   `json:"chain"`
+	Project          string   `json:"project"`
+	Symbol           string   `json:"symbol"`
+	TvlUsd           float64  `json:"tvlUsd"`
+	ApyBase          float64  `json:"apyBase"`
+	ApyReward        float64  `json:"apyReward"`
+	RewardTokens     []string `json:"rewardTokens"`
+	UnderlyingTokens []string `json:"underlyingTokens"`
+	PoolMeta         string   `json:"poolMeta,omitempty"`
+	URL              string   `json:"url"`
+}
+
+/**************************************************************************************************
+** TDefiLlamaChainTVL is the TVL of Yearn on a chain, as consumed by the DefiLlama TVL adapters.
+**************************************************************************************************/
+type TDefiLlamaChainTVL struct {
+	Chain   string  `json:"chain"`
+	ChainID uint64  `json:"chainID"`
+	TvlUsd  float64 `json:"tvlUsd"`
+}
+
+/**************************************************************************************************
+** toPercent converts a yield expressed as a fraction to a percentage, 0 if not set.
+**************************************************************************************************/
+func toPercent(value *bigNumber.Float) float64 {
+	if value == nil {
+		return 0
+	}
+	valueAsFloat, _ := value.Float64()
+	return valueAsFloat * 100
+}
+
+/**************************************************************************************************
+** getDefiLlamaChains returns the chains to include in the DefiLlama responses: all the supported
+** chains known by DefiLlama, or the chains provided in the `chainIDs` query parameter.
+**************************************************************************************************/
+func getDefiLlamaChains(c *gin.Context) []uint64 {
+	chains := []uint64{}
+	if chainIDs := getQueryParam(c, `chainIDs`); chainIDs != `` {
+		for _, chainStr := range strings.Split(chainIDs, `,`) {
+			if chainID, ok := helpers.AssertChainID(chainStr); ok {
+				chains = append(chains, chainID)
+			}
+		}
+	} else {
+		chains = env.SUPPORTED_CHAIN_IDS
+	}
+
+	llamaChains := []uint64{}
+	for _, chainID := range chains {
+		if !helpers.Contains(env.SUPPORTED_CHAIN_IDS, chainID) {
+			continue
+		}
+		if _, ok := DEFILLAMA_CHAIN_NAMES[chainID]; !ok {
+			if _, warned := defiLlamaUnnamedChains.LoadOrStore(chainID, true); !warned {
+				logs.Warning(`Chain ` + strconv.FormatUint(chainID, 10) + ` has no DefiLlama name, its vaults are left out of the DefiLlama integration`)
+			}
+			continue
+		}
+		llamaChains = append(llamaChains, chainID)
+	}
+	return llamaChains
+}
+
+/**************************************************************************************************
+** GetDefiLlamaYields returns the active vaults in the schema of the DefiLlama yields adapters. The
+** base APY is the forward net APY when available, the historical net APY otherwise. The reward
+** APY comes from the staking rewards. Retired and blacklisted vaults are excluded and the pools
+** are sorted by TVL, highest first.
+**
+** Endpoint: GET /integrations/defillama/yields
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the list of pools
+**************************************************************************************************/
+func (y Controller) GetDefiLlamaYields(c *gin.Context) {
+	pools := []TDefiLlamaYieldPool{}
+	for _, chainID := range getDefiLlamaChains(c) {
+		chain, ok := env.GetChain(chainID)
+		if !ok {
+			continue
+		}
+		_, allVaults := storage.ListVaults(chainID)
+		for _, currentVault := range allVaults {
+			if helpers.Contains(chain.BlacklistedVaults, currentVault.Address) || models.IsVaultRetired(currentVault) {
+				continue
+			}
+			vault, err := CreateExternalVault(currentVault)
+			if err != nil {
+				continue
+			}
+
+			apyBase := toPercent(vault.APR.NetAPR)
+			if vault.APR.ForwardAPR.NetAPR != nil && !vault.APR.ForwardAPR.NetAPR.IsZero() {
+				apyBase = toPercent(vault.APR.ForwardAPR.NetAPR)
+			}
+			rewardTokens := []string{}
+			for _, reward := range vault.Staking.Rewards {
+				if !reward.IsFinished {
+					rewardTokens = append(rewardTokens, reward.Address)
+				}
+			}
+			underlyingTokens := []string{vault.Token.Address}
+			if len(vault.Token.UnderlyingTokensAddresses) > 0 {
+				underlyingTokens = vault.Token.UnderlyingTokensAddresses
+			}
+
+			pools = append(pools, TDefiLlamaYieldPool{
+				Pool:             strings.ToLower(vault.Address) + `-` + strings.ToLower(DEFILLAMA_CHAIN_NAMES[chainID]),
+				Chain:            DEFILLAMA_CHAIN_NAMES[chainID],
+				Project:          DEFILLAMA_PROJECT,
+				Symbol:           vault.Token.Symbol,
+				TvlUsd:           vault.TVL.TVL,
+				ApyBase:          apyBase,
+				ApyReward:        toPercent(vault.APR.Extra.StakingRewardsAPR),
+				RewardTokens:     rewardTokens,
+				UnderlyingTokens: underlyingTokens,
+				PoolMeta:         vault.Name,
+				URL:              `https://yearn.fi/vaults/` + strconv.FormatUint(chainID, 10) + `/` + vault.Address,
+			})
+		}
+	}
+
+	sort.SortBy(`tvlUsd`, `desc`, pools)
+	c.JSON(http.StatusOK, pools)
+}
+
+/**************************************************************************************************
+** GetDefiLlamaTVL returns the TVL of the non-blacklisted Yearn vaults for each chain, in the
+** schema of the DefiLlama TVL adapters.
+**
+** Endpoint: GET /integrations/defillama/tvl
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the TVL per chain
+**************************************************************************************************/
+func (y Controller) GetDefiLlamaTVL(c *gin.Context) {
+	tvl := []TDefiLlamaChainTVL{}
+	for _, chainID := range getDefiLlamaChains(c) {
+		tvl = append(tvl, TDefiLlamaChainTVL{
+			Chain:   DEFILLAMA_CHAIN_NAMES[chainID],
+			ChainID: chainID,
+			TvlUsd:  computeChainTVL(chainID, c),
+		})
+	}
+	c.JSON(http.StatusOK, tvl)
+}
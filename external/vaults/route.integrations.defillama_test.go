@@ -0,0 +1,105 @@
+package vaults
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+)
+
+/**************************************************************************************************
+** TestGetDefiLlamaChains verifies that only the supported chains known by DefiLlama are kept, from
+** the `chainIDs` query parameter when provided, and that a supported chain without DefiLlama name
+** is reported instead of being silently dropped.
+**************************************************************************************************/
+func TestGetDefiLlamaChains(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const unnamedChainID = 31337
+	env.CHAINS[unnamedChainID] = env.TChain{ID: unnamedChainID}
+	defer delete(env.CHAINS, unnamedChainID)
+	env.SUPPORTED_CHAIN_IDS = append(env.SUPPORTED_CHAIN_IDS, unnamedChainID)
+	defer func() { env.SUPPORTED_CHAIN_IDS = env.SUPPORTED_CHAIN_IDS[:len(env.SUPPORTED_CHAIN_IDS)-1] }()
+
+	namedSupportedChains := []uint64{}
+	for _, chainID := range env.SUPPORTED_CHAIN_IDS {
+		if _, ok := DEFILLAMA_CHAIN_NAMES[chainID]; ok {
+			namedSupportedChains = append(namedSupportedChains, chainID)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		query    string
+		expected []uint64
+	}{
+		{name: "All the named supported chains", query: "", expected: namedSupportedChains},
+		{name: "Selected chains", query: "?chainIDs=1,10", expected: []uint64{1, 10}},
+		{name: "Case insensitive parameter", query: "?chainids=8453", expected: []uint64{8453}},
+		{name: "Unsupported chain", query: "?chainIDs=1,999999", expected: []uint64{1}},
+		{name: "Invalid chain", query: "?chainIDs=abc,42161", expected: []uint64{42161}},
+		{name: "Supported chain without DefiLlama name", query: "?chainIDs=31337", expected: []uint64{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request, _ = http.NewRequest(http.MethodGet, "/integrations/defillama/tvl"+tc.query, nil)
+			assert.ElementsMatch(t, tc.expected, getDefiLlamaChains(c))
+		})
+	}
+
+	_, reported := defiLlamaUnnamedChains.Load(uint64(unnamedChainID))
+	assert.True(t, reported, "The supported chain without DefiLlama name should be reported")
+}
+
+/**************************************************************************************************
+** TestToPercent verifies the conversion of the yields to the percentages expected by DefiLlama.
+**************************************************************************************************/
+func TestToPercent(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    *bigNumber.Float
+		expected float64
+	}{
+		{name: "Not set", value: nil, expected: 0},
+		{name: "Zero", value: bigNumber.NewFloat(0), expected: 0},
+		{name: "Five percent", value: bigNumber.NewFloat(0.05), expected: 5},
+		{name: "Negative", value: bigNumber.NewFloat(-0.01), expected: -1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.InDelta(t, tc.expected, toPercent(tc.value), 1e-9)
+		})
+	}
+}
+
+/**************************************************************************************************
+** TestGetDefiLlamaTVL verifies that the TVL route returns one entry per selected chain, named as
+** DefiLlama names it.
+**************************************************************************************************/
+func TestGetDefiLlamaTVL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	controller := Controller{}
+	router.GET("/integrations/defillama/tvl", controller.GetDefiLlamaTVL)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/integrations/defillama/tvl?chainIDs=1,100", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	response := []TDefiLlamaChainTVL{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response, 2)
+	names := map[uint64]string{}
+	for _, chainTVL := range response {
+		names[chainTVL.ChainID] = chainTVL.Chain
+	}
+	assert.Equal(t, map[uint64]string{1: `Ethereum`, 100: `xDai`}, names)
+}
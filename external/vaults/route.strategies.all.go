@@ -23,6 +23,8 @@ import (
 ** - orderDirection: Sort direction, 'asc' or 'desc' (default: 'asc')
 ** - strategiesCondition: Filter for strategies, values: 'inQueue', 'debtLimit', 'debtRatio',
 **   'absolute', 'all' (default: 'debtRatio')
+** - protocols: Comma-separated list of protocols, only the strategies labelled with one of them
+**   are returned (default: all)
 **
 ** The function processes data through the following steps:
 ** 1. Validates the chain ID and retrieves sorting parameters
@@ -42,6 +44,7 @@ func (y Controller) GetAllStrategies(c *gin.Context) {
 	orderBy := helpers.SafeString(getQueryParam(c, `orderBy`), `address`)
 	orderDirection := helpers.SafeString(getQueryParam(c, `orderDirection`), `asc`)
 	strategiesCondition := validateStrategyCondition(c, "strategiesCondition")
+	protocols := validateProtocolsParam(c, `protocols`)
 
 	// Validate chain ID using the utility function
 	chainID, ok := validateChainID(c, `chainID`)
@@ -80,6 +83,9 @@ func (y Controller) GetAllStrategies(c *gin.Context) {
 		}
 		vaultStrategies, _ := storage.ListStrategiesForVault(chainID, currentVault.Address)
 		for _, strategy := range vaultStrategies {
+			if len(protocols) > 0 && !strategyUsesProtocols(strategy, protocols) {
+				continue
+			}
 			strategyWithDetails := CreateExternalStrategy(strategy)
 			if !strategyWithDetails.ShouldBeIncluded(strategiesCondition) {
 				continue
@@ -88,6 +94,11 @@ func (y Controller) GetAllStrategies(c *gin.Context) {
 		}
 	}
 
+	locale := NegotiateLocale(c)
+	for i := range data {
+		data[i].localize(chainID, locale)
+	}
+	setContentLanguage(c, locale)
 	sort.SortBy(orderBy, orderDirection, data)
 	c.JSON(http.StatusOK, data)
 }
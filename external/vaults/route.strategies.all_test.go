@@ -150,7 +150,7 @@ func TestGetAllStrategies_QueryParams(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 
 	// Parse the response to ensure it's valid JSON
-	var strategies []TStrategy
+	var strategies []TExternalStrategy
 	err := json.Unmarshal(w.Body.Bytes(), &strategies)
 	assert.NoError(t, err, "Response should be valid JSON")
 }
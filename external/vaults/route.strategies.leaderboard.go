@@ -0,0 +1,253 @@
+package vaults
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/apr"
+)
+
+/**************************************************************************************************
+** The windows of the strategy leaderboard, in days, and the number of strategies returned on each
+** side of each ranking.
+**************************************************************************************************/
+var leaderboardWindows = map[string]uint64{
+	`7d`:  7,
+	`30d`: 30,
+	`90d`: 90,
+}
+
+const DEFAULT_LEADERBOARD_LIMIT = 10
+const MAX_LEADERBOARD_LIMIT = 100
+
+/**************************************************************************************************
+** TLeaderboardVault is the vault a strategy of the leaderboard is attached to.
+**************************************************************************************************/
+type TLeaderboardVault struct {
+	Address string `json:"address"`
+	Name    string `json:"name"`
+	Symbol  string `json:"symbol"`
+}
+
+/**************************************************************************************************
+** TLeaderboardStrategy is a strategy of the leaderboard. RealizedAPR is the average net APR of the
+** reports of the window, ForwardAPR the net APY expected by the APR oracle (v3 only). Either is
+** null when unknown.
+**************************************************************************************************/
+type TLeaderboardStrategy struct {
+	Address     string            `json:"address"`
+	Name        string            `json:"name"`
+	Vault       TLeaderboardVault `json:"vault"`
+	Protocols   []string          `json:"protocols"`
+	RealizedAPR *float64          `json:"realizedAPR"`
+	Reports     uint64            `json:"reports"`
+	ForwardAPR  *float64          `json:"forwardAPR"`
+}
+
+/**************************************************************************************************
+** TLeaderboardRanking holds the best and the worst strategies for one metric, the best first in
+** Best and the worst first in Worst.
+**************************************************************************************************/
+type TLeaderboardRanking struct {
+	Best  []TLeaderboardStrategy `json:"best"`
+	Worst []TLeaderboardStrategy `json:"worst"`
+}
+
+/**************************************************************************************************
+** TStrategiesLeaderboard is the response of the strategy leaderboard endpoint.
+**************************************************************************************************/
+type TStrategiesLeaderboard struct {
+	ChainID           uint64              `json:"chainID"`
+	Window            string              `json:"window"`
+	From              int64               `json:"from"`
+	RealizedAPR       TLeaderboardRanking `json:"realizedAPR"`
+	ForwardAPR        TLeaderboardRanking `json:"forwardAPR"`
+	RealizedAPRSource string              `json:"realizedAPRSource"`
+}
+
+/**************************************************************************************************
+** rankLeaderboard sorts the strategies with a value for the metric and returns the `limit` best
+** and worst ones.
+**************************************************************************************************/
+func rankLeaderboard(strategies []TLeaderboardStrategy, metric func(TLeaderboardStrategy) *float64, limit int) TLeaderboardRanking {
+	ranked := []TLeaderboardStrategy{}
+	for _, strategy := range strategies {
+		if metric(strategy) != nil {
+			ranked = append(ranked, strategy)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return *metric(ranked[i]) > *metric(ranked[j])
+	})
+
+	count := limit
+	if count > len(ranked) {
+		count = len(ranked)
+	}
+	ranking := TLeaderboardRanking{
+		Best:  make([]TLeaderboardStrategy, 0, count),
+		Worst: make([]TLeaderboardStrategy, 0, count),
+	}
+	for i := 0; i < count; i++ {
+		ranking.Best = append(ranking.Best, ranked[i])
+		ranking.Worst = append(ranking.Worst, ranked[len(ranked)-1-i])
+	}
+	return ranking
+}
+
+/**************************************************************************************************
+** GetStrategiesLeaderboard ranks the active strategies of a chain by their realized APR over a
+** window and by their forward APR, for the strategy team and the community to spot the best and
+** the worst performers.
+**
+** The realized APR is the average net APR of the harvest reports of the window, read from the
+** Kong database. Without a database, the APR of the last report is used for the strategies that
+** reported during the window, and `realizedAPRSource` is `lastReport` instead of `reports`.
+** The forward APR is the one computed from the APR oracle when the strategies are refreshed.
+**
+** Query parameters:
+** - chainID: the chain of the strategies (required)
+** - window: 7d, 30d or 90d (default: 30d)
+** - limit: number of strategies on each side of each ranking (default: 10, max: 100)
+**
+** Endpoint: GET /strategies/leaderboard
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the leaderboard
+**************************************************************************************************/
+func (y Controller) GetStrategiesLeaderboard(c *gin.Context) {
+	chainIDStr := getQueryParam(c, `chainID`)
+	if chainIDStr == `` {
+		err := NewAPIError(
+			ErrorTypeValidation,
+			ErrorCodeMissingParam,
+			"Missing required parameter",
+			"chainID query parameter is required",
+		).WithContext("GetStrategiesLeaderboard")
+		handleError(c, err, http.StatusBadRequest, "Missing required parameter", "GetStrategiesLeaderboard")
+		return
+	}
+	chainID, ok := helpers.AssertChainID(chainIDStr)
+	if !ok {
+		err := NewAPIError(
+			ErrorTypeValidation,
+			ErrorCodeChainNotSupported,
+			"Chain not supported",
+			fmt.Sprintf("chain %s is not supported", chainIDStr),
+		).WithContext("GetStrategiesLeaderboard")
+		handleError(c, err, http.StatusBadRequest, "Chain not supported", "GetStrategiesLeaderboard")
+		return
+	}
+	chain, _ := env.GetChain(chainID)
+
+	window := helpers.SafeString(getQueryParam(c, `window`), `30d`)
+	days, ok := leaderboardWindows[window]
+	if !ok {
+		err := NewAPIError(
+			ErrorTypeValidation,
+			ErrorCodeInvalidParam,
+			"Invalid window",
+			fmt.Sprintf("window must be 7d, 30d or 90d, got '%s'", window),
+		).WithContext("GetStrategiesLeaderboard")
+		handleError(c, err, http.StatusBadRequest, "Invalid window", "GetStrategiesLeaderboard")
+		return
+	}
+
+	limit := DEFAULT_LEADERBOARD_LIMIT
+	if limitStr := getQueryParam(c, `limit`); limitStr != `` {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit <= 0 || parsedLimit > MAX_LEADERBOARD_LIMIT {
+			err := NewAPIError(
+				ErrorTypeValidation,
+				ErrorCodeInvalidParam,
+				"Invalid limit",
+				fmt.Sprintf("limit must be between 1 and %d, got '%s'", MAX_LEADERBOARD_LIMIT, limitStr),
+			).WithContext("GetStrategiesLeaderboard")
+			handleError(c, err, http.StatusBadRequest, "Invalid limit", "GetStrategiesLeaderboard")
+			return
+		}
+		limit = parsedLimit
+	}
+
+	/**********************************************************************************************
+	** The realized APRs of the window, aggregated from the reports in the database when available.
+	**********************************************************************************************/
+	since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	realizedAPRSource := `reports`
+	realizedAPRs, err := apr.GetRealizedStrategiesAPRFromDB(chainID, since)
+	if err != nil {
+		logs.Warning(`Failed to read the reports of chain ` + chainIDStr + `, using the last reports: ` + err.Error())
+		realizedAPRSource = `lastReport`
+	}
+
+	/**********************************************************************************************
+	** Collect the active strategies of the vaults that are not blacklisted.
+	**********************************************************************************************/
+	strategies := []TLeaderboardStrategy{}
+	allVaults, _ := storage.ListVaults(chainID)
+	for _, vault := range allVaults {
+		if helpers.Contains(chain.BlacklistedVaults, vault.Address) {
+			continue
+		}
+		vaultStrategies, _ := storage.ListStrategiesForVault(chainID, vault.Address)
+		for _, strategy := range vaultStrategies {
+			if strategy.Status != models.StrategyStatusActive {
+				continue
+			}
+			entry := TLeaderboardStrategy{
+				Address: strategy.Address.Hex(),
+				Name:    strategy.DisplayName,
+				Vault: TLeaderboardVault{
+					Address: vault.Address.Hex(),
+					Name:    vault.Metadata.DisplayName,
+					Symbol:  vault.Metadata.DisplaySymbol,
+				},
+				Protocols: strategy.Protocols,
+			}
+			if entry.Name == `` {
+				entry.Name = strategy.Name
+			}
+			if entry.Protocols == nil {
+				entry.Protocols = []string{}
+			}
+
+			if realizedAPRSource == `reports` {
+				if realized, ok := realizedAPRs[strategy.Address]; ok {
+					entry.RealizedAPR = &realized.NetAPR
+					entry.Reports = realized.Reports
+				}
+			} else if strategy.APRType == models.APRTypeCurrent && strategy.LastReport != nil && strategy.LastReport.Uint64() >= uint64(since.Unix()) {
+				netAPR := strategy.NetAPR
+				entry.RealizedAPR = &netAPR
+				entry.Reports = 1
+			}
+			if strategy.APRType == models.APRTypeForward {
+				netAPR := strategy.NetAPR
+				entry.ForwardAPR = &netAPR
+			}
+			strategies = append(strategies, entry)
+		}
+	}
+
+	c.JSON(http.StatusOK, TStrategiesLeaderboard{
+		ChainID: chainID,
+		Window:  window,
+		From:    since.Unix(),
+		RealizedAPR: rankLeaderboard(strategies, func(s TLeaderboardStrategy) *float64 {
+			return s.RealizedAPR
+		}, limit),
+		ForwardAPR: rankLeaderboard(strategies, func(s TLeaderboardStrategy) *float64 {
+			return s.ForwardAPR
+		}, limit),
+		RealizedAPRSource: realizedAPRSource,
+	})
+}
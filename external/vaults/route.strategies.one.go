@@ -61,8 +61,8 @@ func (y Controller) GetStrategy(c *gin.Context) {
 	// Look up the strategy
 	strategy, ok := storage.GuessStrategy(chainID, address)
 	if !ok {
-		handleError(c, fmt.Errorf("strategy not found for address %s on chain %d",
-			address.String(), chainID),
+		handleError(c, NewAPIError(ErrorTypeData, ErrorCodeStrategyNotFound, "Strategy not found",
+			fmt.Sprintf("strategy not found for address %s on chain %d", address.String(), chainID)),
 			http.StatusNotFound, "Strategy not found", "GetStrategy")
 		return
 	}
@@ -106,6 +106,9 @@ func (y Controller) GetStrategy(c *gin.Context) {
 		// Continue processing
 	}
 
-	// Return the strategy
+	// Return the strategy, with its strings in the locale of the request
+	locale := NegotiateLocale(c)
+	newStrategy.localize(chainID, locale)
+	setContentLanguage(c, locale)
 	c.JSON(http.StatusOK, newStrategy)
 }
@@ -0,0 +1,66 @@
+package vaults
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The name, logo and keywords of the Yearn tokenlist. The logo is the one of YFI.
+**************************************************************************************************/
+const TOKENLIST_NAME = `Yearn Vaults`
+
+var TOKENLIST_LOGO_URI = env.BASE_ASSET_URL + `1/0x0bc529c00c6401aef6d220be8c6ea1667f6ad93e/logo-128.png`
+var TOKENLIST_KEYWORDS = []string{`yearn`, `yvTokens`, `vaults`}
+
+/**************************************************************************************************
+** TTokenList is the list of the yvTokens in the Uniswap tokenlist schema
+** (https://uniswap.org/tokenlist.schema.json). The timestamp and the version are the ones of the
+** last change of the tokens.
+**************************************************************************************************/
+type TTokenList struct {
+	Name      string                    `json:"name"`
+	Timestamp string                    `json:"timestamp"`
+	Version   storage.TVersion          `json:"version"`
+	LogoURI   string                    `json:"logoURI"`
+	Keywords  []string                  `json:"keywords"`
+	Tokens    []storage.TTokenListToken `json:"tokens"`
+}
+
+/**************************************************************************************************
+** GetTokenList returns the share tokens of the Yearn vaults of all the supported chains in the
+** Uniswap tokenlist schema, for the wallets and the aggregators. The list is built and versioned
+** by the refresh of the vaults (see tokenlist.RefreshTokenList), this route only reads it. The
+** tokens are sorted by chain, then by address.
+**
+** Endpoint: GET /tokenlist.json
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the tokenlist
+**************************************************************************************************/
+func (y Controller) GetTokenList(c *gin.Context) {
+	metadata, tokens := storage.GetTokenList()
+	tokenList := TTokenList{
+		Name:      TOKENLIST_NAME,
+		Timestamp: metadata.LastUpdate.UTC().Format(time.RFC3339),
+		Version:   metadata.Version,
+		LogoURI:   TOKENLIST_LOGO_URI,
+		Keywords:  TOKENLIST_KEYWORDS,
+		Tokens:    []storage.TTokenListToken{},
+	}
+	for _, token := range tokens {
+		tokenList.Tokens = append(tokenList.Tokens, token)
+	}
+	sort.Slice(tokenList.Tokens, func(i, j int) bool {
+		if tokenList.Tokens[i].ChainID != tokenList.Tokens[j].ChainID {
+			return tokenList.Tokens[i].ChainID < tokenList.Tokens[j].ChainID
+		}
+		return tokenList.Tokens[i].Address < tokenList.Tokens[j].Address
+	})
+	c.JSON(http.StatusOK, tokenList)
+}
@@ -0,0 +1,216 @@
+package vaults
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/patrickmn/go-cache"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** MAX_ALLOWANCES_VAULTS limits the number of vaults that can be passed in the `spender` parameter
+** of a single request.
+**************************************************************************************************/
+const MAX_ALLOWANCES_VAULTS = 100
+
+/**************************************************************************************************
+** The allowances read for a user are cached for ALLOWANCES_CACHE_TTL, the frontends polling them
+** while the user approves, so the repeated requests for the same user and vaults do not trigger a
+** new multicall.
+**************************************************************************************************/
+const ALLOWANCES_CACHE_TTL = 30 * time.Second
+
+var allowancesCache = cache.New(ALLOWANCES_CACHE_TTL, 2*ALLOWANCES_CACHE_TTL)
+
+/**************************************************************************************************
+** TAllowance is the allowance given by a user on the underlying token of a vault to one of the
+** contracts able to pull it on deposit: the vault itself, or the partner tracker routing the
+** deposits of the v2 vaults.
+**************************************************************************************************/
+type TAllowance struct {
+	Vault     string `json:"vault"`
+	Token     string `json:"token"`
+	Spender   string `json:"spender"`
+	Allowance string `json:"allowance"`
+}
+
+/**************************************************************************************************
+** tAllowanceRequest is one allowance to read on chain.
+**************************************************************************************************/
+type tAllowanceRequest struct {
+	vault   common.Address
+	token   common.Address
+	spender common.Address
+}
+
+/**************************************************************************************************
+** getAllowanceSpenders returns the contracts that can pull the underlying token of a vault on
+** deposit: the vault, the partner tracker of the chain for the v2 vaults and the Yearn 4626 router
+** of the chain for the v3 vaults.
+**************************************************************************************************/
+func getAllowanceSpenders(chainID uint64, vault models.TVault) []common.Address {
+	spenders := []common.Address{vault.Address}
+	chain, ok := env.GetChain(chainID)
+	if !ok {
+		return spenders
+	}
+	if VaultVersionChecks.IsV2(vault) && (chain.PartnerContract.Address != common.Address{}) {
+		spenders = append(spenders, chain.PartnerContract.Address)
+	}
+	if VaultVersionChecks.IsV3(vault) && (chain.V3RouterContract.Address != common.Address{}) {
+		spenders = append(spenders, chain.V3RouterContract.Address)
+	}
+	return spenders
+}
+
+/**************************************************************************************************
+** GetUserAllowances returns, in a single request, the allowances given by a user on the underlying
+** tokens of the vaults to their deposit spenders, read on chain with one multicall. This lets the
+** frontends render the approve or deposit state without an eth_call per vault. The result is cached
+** for ALLOWANCES_CACHE_TTL per user and list of vaults.
+**
+** Query parameters:
+** - chainID: the chain of the vaults (required)
+** - spender: comma separated list of vault addresses (max 100). When omitted, all the active vaults
+**   of the chain are returned
+**
+** Endpoint: GET /users/:address/allowances
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the list of allowances
+**************************************************************************************************/
+func (y Controller) GetUserAllowances(c *gin.Context) {
+	userAddressStr := c.Param(`address`)
+	if !common.IsHexAddress(userAddressStr) {
+		err := NewAPIError(
+			ErrorTypeValidation,
+			ErrorCodeInvalidAddress,
+			"Invalid address format",
+			fmt.Sprintf("The value '%s' is not a valid address", userAddressStr),
+		).WithContext("GetUserAllowances")
+		handleError(c, err, http.StatusBadRequest, "Invalid address format", "GetUserAllowances")
+		return
+	}
+	userAddress := common.HexToAddress(userAddressStr)
+
+	chainIDStr := getQueryParam(c, `chainID`)
+	if chainIDStr == `` {
+		err := NewAPIError(
+			ErrorTypeValidation,
+			ErrorCodeMissingParam,
+			"Missing required parameter",
+			"chainID query parameter is required",
+		).WithContext("GetUserAllowances")
+		handleError(c, err, http.StatusBadRequest, "Missing required parameter", "GetUserAllowances")
+		return
+	}
+	chainID, ok := helpers.AssertChainID(chainIDStr)
+	if !ok {
+		err := NewAPIError(
+			ErrorTypeValidation,
+			ErrorCodeChainNotSupported,
+			"Chain not supported",
+			fmt.Sprintf("chain %s is not supported", chainIDStr),
+		).WithContext("GetUserAllowances")
+		handleError(c, err, http.StatusBadRequest, "Chain not supported", "GetUserAllowances")
+		return
+	}
+
+	/**********************************************************************************************
+	** Select the vaults: the ones passed as spenders, or all the active vaults of the chain.
+	**********************************************************************************************/
+	vaults := []models.TVault{}
+	if spendersStr := getQueryParam(c, `spender`); spendersStr != `` {
+		spenders := strings.Split(spendersStr, `,`)
+		if len(spenders) > MAX_ALLOWANCES_VAULTS {
+			err := NewAPIError(
+				ErrorTypeValidation,
+				ErrorCodeInvalidParam,
+				"Too many spenders",
+				fmt.Sprintf("at most %d spenders can be requested at once", MAX_ALLOWANCES_VAULTS),
+			).WithContext("GetUserAllowances")
+			handleError(c, err, http.StatusBadRequest, "Too many spenders", "GetUserAllowances")
+			return
+		}
+		for _, spenderStr := range spenders {
+			vaultAddress, ok := helpers.AssertAddress(strings.TrimSpace(spenderStr), chainID)
+			if !ok {
+				err := NewAPIError(
+					ErrorTypeValidation,
+					ErrorCodeInvalidAddress,
+					"Invalid address format",
+					fmt.Sprintf("The value '%s' is not a valid address", spenderStr),
+				).WithContext("GetUserAllowances")
+				handleError(c, err, http.StatusBadRequest, "Invalid address format", "GetUserAllowances")
+				return
+			}
+			vault, ok := storage.GetVault(chainID, vaultAddress)
+			if !ok {
+				handleVaultNotFound(c, chainID, vaultAddress, "GetUserAllowances")
+				return
+			}
+			vaults = append(vaults, vault)
+		}
+	} else {
+		_, allVaults := storage.ListVaults(chainID)
+		for _, vault := range allVaults {
+			if models.IsVaultRetired(vault) || IsVaultBlacklisted(chainID, vault.Address) {
+				continue
+			}
+			vaults = append(vaults, vault)
+		}
+	}
+
+	/**********************************************************************************************
+	** Read all the allowances with a single multicall, unless they were read recently.
+	**********************************************************************************************/
+	vaultAddresses := []string{}
+	for _, vault := range vaults {
+		vaultAddresses = append(vaultAddresses, vault.Address.Hex())
+	}
+	sort.Strings(vaultAddresses)
+	cacheKey := strconv.FormatUint(chainID, 10) + `:` + userAddress.Hex() + `:` + strings.Join(vaultAddresses, `,`)
+	if cached, ok := allowancesCache.Get(cacheKey); ok {
+		c.JSON(http.StatusOK, cached)
+		return
+	}
+
+	requests := []tAllowanceRequest{}
+	calls := []ethereum.Call{}
+	for _, vault := range vaults {
+		for _, spender := range getAllowanceSpenders(chainID, vault) {
+			request := tAllowanceRequest{vault: vault.Address, token: vault.AssetAddress, spender: spender}
+			requests = append(requests, request)
+			calls = append(calls, multicalls.GetAllowance(request.vault.Hex()+request.spender.Hex(), request.token, userAddress, request.spender))
+		}
+	}
+	response := multicalls.Perform(chainID, calls, nil)
+
+	allowances := []TAllowance{}
+	for _, request := range requests {
+		rawAllowance := response[request.vault.Hex()+request.spender.Hex()+`allowance`]
+		if len(rawAllowance) == 0 {
+			continue
+		}
+		allowances = append(allowances, TAllowance{
+			Vault:     request.vault.Hex(),
+			Token:     request.token.Hex(),
+			Spender:   request.spender.Hex(),
+			Allowance: helpers.DecodeBigInt(rawAllowance).String(),
+		})
+	}
+	allowancesCache.Set(cacheKey, allowances, cache.DefaultExpiration)
+	c.JSON(http.StatusOK, allowances)
+}
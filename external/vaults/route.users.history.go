@@ -0,0 +1,125 @@
+package vaults
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/processes/portfolio"
+)
+
+/**************************************************************************************************
+** The number of points returned by default and at most for each granularity of the user history,
+** the blocks of the points being only known for the last year.
+**************************************************************************************************/
+var DEFAULT_USER_HISTORY_POINTS = map[portfolio.TGranularity]int{
+	portfolio.GranularityDaily:  30,
+	portfolio.GranularityWeekly: 12,
+}
+var MAX_USER_HISTORY_POINTS = map[portfolio.TGranularity]int{
+	portfolio.GranularityDaily:  portfolio.MAX_HISTORY_POINTS,
+	portfolio.GranularityWeekly: 52,
+}
+
+/**************************************************************************************************
+** TUserHistory is the value history of the vault holdings of a user on a chain.
+**************************************************************************************************/
+type TUserHistory struct {
+	Address     string             `json:"address"`
+	ChainID     uint64             `json:"chainID"`
+	Granularity string             `json:"granularity"`
+	History     []portfolio.TPoint `json:"history"`
+}
+
+/**************************************************************************************************
+** GetUserHistory returns the value in USD of the vault holdings of a user over time: at each
+** point, the shares held or staked in the vaults of the indexed positions of the user times their
+** price per share and the price of the underlying token then. This lets the frontends chart the
+** earnings of a user without reading an archive node themselves. The history is cached for an hour
+** by user and granularity.
+**
+** Query parameters:
+** - chainID: the chain of the vaults (required)
+** - granularity: `daily` (default) or `weekly`
+** - limit: the number of points, up to 365 daily or 52 weekly points (default 30 and 12)
+**
+** Endpoint: GET /users/:address/history
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the TUserHistory
+**************************************************************************************************/
+func (y Controller) GetUserHistory(c *gin.Context) {
+	chainIDStr := getQueryParam(c, `chainID`)
+	if chainIDStr == `` {
+		err := NewAPIError(
+			ErrorTypeValidation,
+			ErrorCodeMissingParam,
+			"Missing required parameter",
+			"chainID query parameter is required",
+		).WithContext("GetUserHistory")
+		handleError(c, err, http.StatusBadRequest, "Missing required parameter", "GetUserHistory")
+		return
+	}
+	chainID, ok := helpers.AssertChainID(chainIDStr)
+	if !ok {
+		err := NewAPIError(
+			ErrorTypeValidation,
+			ErrorCodeChainNotSupported,
+			"Chain not supported",
+			fmt.Sprintf("chain %s is not supported", chainIDStr),
+		).WithContext("GetUserHistory")
+		handleError(c, err, http.StatusBadRequest, "Chain not supported", "GetUserHistory")
+		return
+	}
+	userAddress, ok := validateAddress(c, `address`, chainID)
+	if !ok {
+		return
+	}
+
+	granularity := portfolio.TGranularity(helpers.SafeString(getQueryParam(c, `granularity`), string(portfolio.GranularityDaily)))
+	limit, ok := DEFAULT_USER_HISTORY_POINTS[granularity]
+	if !ok {
+		err := NewAPIError(
+			ErrorTypeValidation,
+			ErrorCodeInvalidParam,
+			"Invalid granularity",
+			fmt.Sprintf("granularity must be daily or weekly, got '%s'", granularity),
+		).WithContext("GetUserHistory")
+		handleError(c, err, http.StatusBadRequest, "Invalid granularity", "GetUserHistory")
+		return
+	}
+	if limitStr := getQueryParam(c, `limit`); limitStr != `` {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit <= 0 || parsedLimit > MAX_USER_HISTORY_POINTS[granularity] {
+			err := NewAPIError(
+				ErrorTypeValidation,
+				ErrorCodeInvalidParam,
+				"Invalid limit",
+				fmt.Sprintf("limit must be between 1 and %d, got '%s'", MAX_USER_HISTORY_POINTS[granularity], limitStr),
+			).WithContext("GetUserHistory")
+			handleError(c, err, http.StatusBadRequest, "Invalid limit", "GetUserHistory")
+			return
+		}
+		limit = parsedLimit
+	}
+
+	history, err := portfolio.ComputeUserHistory(chainID, userAddress, granularity, limit)
+	if err != nil {
+		apiErr := NewAPIError(
+			ErrorTypeValidation,
+			ErrorCodeChainNotSupported,
+			"History not available",
+			err.Error(),
+		).WithContext("GetUserHistory")
+		handleError(c, apiErr, http.StatusBadRequest, "History not available", "GetUserHistory")
+		return
+	}
+	c.JSON(http.StatusOK, TUserHistory{
+		Address:     userAddress.Hex(),
+		ChainID:     chainID,
+		Granularity: string(granularity),
+		History:     history,
+	})
+}
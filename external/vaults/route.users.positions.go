@@ -0,0 +1,135 @@
+package vaults
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/entries"
+)
+
+/**************************************************************************************************
+** TUserPosition is the position of a user in a vault with its entry: the price per share and the
+** forward net APY of the vault when the user deposited, next to the current ones and the return
+** realized since then.
+**************************************************************************************************/
+type TUserPosition struct {
+	Vault            common.Address `json:"vault"`
+	Balance          *bigNumber.Int `json:"balance"`
+	EntryPPS         *bigNumber.Int `json:"entryPPS"`
+	EntryAPY         *float64       `json:"entryAPY,omitempty"`
+	EntryTime        uint64         `json:"entryTime"`
+	LastDepositAt    uint64         `json:"lastDepositAt"`
+	PricePerShare    *bigNumber.Int `json:"pricePerShare"`
+	NetAPY           *float64       `json:"netAPY,omitempty"`
+	ReturnSinceEntry *float64       `json:"returnSinceEntry,omitempty"`
+	APYSinceEntry    *float64       `json:"apySinceEntry,omitempty"`
+}
+
+/**************************************************************************************************
+** TUserPositions is the list of the positions of a user on a chain.
+**************************************************************************************************/
+type TUserPositions struct {
+	Address   string          `json:"address"`
+	ChainID   uint64          `json:"chainID"`
+	Positions []TUserPosition `json:"positions"`
+}
+
+/**************************************************************************************************
+** GetUserPositions returns the positions of a user in the vaults of a chain with their entry: the
+** price per share and the forward net APY of the vault at the deposits of the user, weighted by
+** their shares, next to the current ones and the return realized since the entry. This lets the
+** dashboards show "you joined at X%, you've realized Y%". Only the deposits indexed by yDaemon
+** have an entry, and the positions whose shares were all transferred out are not listed.
+**
+** Query parameters:
+** - chainID: the chain of the vaults (required)
+**
+** Endpoint: GET /users/:address/positions
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the TUserPositions
+**************************************************************************************************/
+func (y Controller) GetUserPositions(c *gin.Context) {
+	chainIDStr := getQueryParam(c, `chainID`)
+	if chainIDStr == `` {
+		err := NewAPIError(
+			ErrorTypeValidation,
+			ErrorCodeMissingParam,
+			"Missing required parameter",
+			"chainID query parameter is required",
+		).WithContext("GetUserPositions")
+		handleError(c, err, http.StatusBadRequest, "Missing required parameter", "GetUserPositions")
+		return
+	}
+	chainID, ok := helpers.AssertChainID(chainIDStr)
+	if !ok {
+		err := NewAPIError(
+			ErrorTypeValidation,
+			ErrorCodeChainNotSupported,
+			"Chain not supported",
+			fmt.Sprintf("chain %s is not supported", chainIDStr),
+		).WithContext("GetUserPositions")
+		handleError(c, err, http.StatusBadRequest, "Chain not supported", "GetUserPositions")
+		return
+	}
+	userAddress, ok := validateAddress(c, `address`, chainID)
+	if !ok {
+		return
+	}
+
+	userEntries := entries.GetDepositorEntries(chainID, userAddress)
+	calls := []ethereum.Call{}
+	for vaultAddress := range userEntries {
+		calls = append(calls, multicalls.GetBalanceOf(vaultAddress.Hex(), vaultAddress, userAddress))
+	}
+	response := multicalls.Perform(chainID, calls, nil)
+
+	now := time.Now()
+	positions := []TUserPosition{}
+	for vaultAddress, entry := range userEntries {
+		position := TUserPosition{
+			Vault:         vaultAddress,
+			EntryTime:     entry.EntryTime,
+			LastDepositAt: entry.LastDepositAt,
+		}
+		if rawBalance := response[vaultAddress.Hex()+`balanceOf`]; len(rawBalance) > 0 {
+			position.Balance = helpers.DecodeBigInt(rawBalance)
+			if position.Balance.IsZero() {
+				continue
+			}
+		}
+		decimals := uint64(18)
+		if token, ok := storage.GetERC20(chainID, vaultAddress); ok {
+			decimals = token.Decimals
+		}
+		position.EntryPPS = entries.GetEntryPricePerShare(entry, decimals)
+		if entry.APYShares > 0 {
+			entryAPY := entry.APY
+			position.EntryAPY = &entryAPY
+		}
+		if vault, ok := storage.GetVault(chainID, vaultAddress); ok {
+			position.PricePerShare = vault.LastPricePerShare
+			position.ReturnSinceEntry, position.APYSinceEntry = entries.ComputeReturnSinceEntry(position.EntryPPS, vault.LastPricePerShare, entry.EntryTime, now)
+		}
+		if netAPY, ok := getVaultNetAPY(chainID, vaultAddress); ok {
+			position.NetAPY = &netAPY
+		}
+		positions = append(positions, position)
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i].Vault.Hex() < positions[j].Vault.Hex() })
+
+	c.JSON(http.StatusOK, TUserPositions{
+		Address:   userAddress.Hex(),
+		ChainID:   chainID,
+		Positions: positions,
+	})
+}
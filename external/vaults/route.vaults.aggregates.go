@@ -0,0 +1,158 @@
+package vaults
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/fetcher"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/apr"
+	"github.com/yearn/ydaemon/processes/classification"
+)
+
+/**************************************************************************************************
+** TAssetClassAggregateVault is a vault of an asset class aggregate, with its TVL and its APY. The
+** APY is nil when the vault has no computed APY yet.
+**************************************************************************************************/
+type TAssetClassAggregateVault struct {
+	Address string   `json:"address"`
+	Symbol  string   `json:"symbol"`
+	TVL     float64  `json:"tvl"`
+	APY     *float64 `json:"apy"`
+}
+
+/**************************************************************************************************
+** TAssetClassAggregate is the aggregate of the Yearn vaults of a chain in an asset class: their
+** combined TVL, their APY weighted by their TVL, and the vaults themselves by decreasing TVL.
+**************************************************************************************************/
+type TAssetClassAggregate struct {
+	ChainID    uint64                      `json:"chainID"`
+	AssetClass string                      `json:"assetClass"`
+	TVL        float64                     `json:"tvl"`
+	APY        float64                     `json:"apy"`
+	VaultCount int                         `json:"vaultCount"`
+	Vaults     []TAssetClassAggregateVault `json:"vaults"`
+}
+
+/**************************************************************************************************
+** getVaultNetAPY returns the net APY of a vault: the forward one when computed, the historical
+** one otherwise. False when no APY is computed for the vault yet.
+**************************************************************************************************/
+func getVaultNetAPY(chainID uint64, vault common.Address) (float64, bool) {
+	computedAPY, ok := apr.GetComputedAPY(chainID, vault)
+	if !ok {
+		return 0, false
+	}
+	vaultAPY, ok := computedAPY.(apr.TVaultAPY)
+	if !ok {
+		return 0, false
+	}
+	if vaultAPY.ForwardAPY.NetAPY != nil {
+		netAPY, _ := vaultAPY.ForwardAPY.NetAPY.Float64()
+		return netAPY, true
+	}
+	if vaultAPY.NetAPY != nil {
+		netAPY, _ := vaultAPY.NetAPY.Float64()
+		return netAPY, true
+	}
+	return 0, false
+}
+
+/**************************************************************************************************
+** computeAssetClassAggregate aggregates the Yearn vaults of a chain in an asset class, skipping
+** the blacklisted and the retired ones. The APY is weighted by the TVL of the vaults having an
+** APY, the vaults without one only counting in the TVL.
+**************************************************************************************************/
+func computeAssetClassAggregate(chainID uint64, assetClass classification.TAssetClass) TAssetClassAggregate {
+	aggregate := TAssetClassAggregate{
+		ChainID:    chainID,
+		AssetClass: string(assetClass),
+		Vaults:     []TAssetClassAggregateVault{},
+	}
+	chain, ok := env.GetChain(chainID)
+	if !ok {
+		return aggregate
+	}
+
+	weightedAPY := 0.0
+	weightedTVL := 0.0
+	_, vaultsList := storage.ListVaults(chainID)
+	for _, vault := range vaultsList {
+		if !vault.Metadata.Inclusion.IsYearn || models.IsVaultRetired(vault) {
+			continue
+		}
+		if helpers.Contains(chain.BlacklistedVaults, vault.Address) {
+			continue
+		}
+		if class, ok := classification.ClassifyVault(vault); !ok || class != assetClass {
+			continue
+		}
+
+		tvl := fetcher.BuildVaultTVL(vault).TVL
+		if math.IsNaN(tvl) || math.IsInf(tvl, 0) {
+			tvl = 0
+		}
+		aggregateVault := TAssetClassAggregateVault{
+			Address: vault.Address.Hex(),
+			TVL:     tvl,
+		}
+		if token, ok := storage.GetERC20(chainID, vault.Address); ok {
+			aggregateVault.Symbol = token.Symbol
+		}
+		if netAPY, ok := getVaultNetAPY(chainID, vault.Address); ok && !math.IsNaN(netAPY) && !math.IsInf(netAPY, 0) {
+			aggregateVault.APY = &netAPY
+			weightedAPY += netAPY * tvl
+			weightedTVL += tvl
+		}
+		aggregate.TVL += tvl
+		aggregate.Vaults = append(aggregate.Vaults, aggregateVault)
+	}
+
+	if weightedTVL > 0 {
+		aggregate.APY = weightedAPY / weightedTVL
+	}
+	aggregate.VaultCount = len(aggregate.Vaults)
+	sort.SliceStable(aggregate.Vaults, func(i, j int) bool {
+		return aggregate.Vaults[i].TVL > aggregate.Vaults[j].TVL
+	})
+	return aggregate
+}
+
+/**************************************************************************************************
+** GetAssetClassAggregate returns the combined TVL, the TVL-weighted APY and the count of the
+** Yearn vaults of a chain in an asset class: stables, eth or btc.
+**
+** Endpoint: GET /aggregates/:chainID/:assetClass
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the aggregate
+**************************************************************************************************/
+func (y Controller) GetAssetClassAggregate(c *gin.Context) {
+	chainID, ok := validateChainID(c, "chainID")
+	if !ok {
+		return
+	}
+
+	assetClass := strings.ToLower(c.Param("assetClass"))
+	if !classification.IsAssetClass(assetClass) {
+		err := NewAPIError(
+			ErrorTypeValidation,
+			ErrorCodeInvalidParam,
+			"Invalid asset class",
+			fmt.Sprintf("The asset class '%s' is not one of stables, eth or btc", assetClass),
+		).WithContext("GetAssetClassAggregate")
+
+		handleError(c, err, http.StatusBadRequest, "Invalid asset class", "GetAssetClassAggregate")
+		return
+	}
+
+	c.JSON(http.StatusOK, computeAssetClassAggregate(chainID, classification.TAssetClass(assetClass)))
+}
@@ -0,0 +1,77 @@
+package vaults
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/apr"
+)
+
+/**************************************************************************************************
+** The kinds of APY returned by the APY figure endpoint: the forward net APY when the vault has one,
+** the historical net APY otherwise.
+**************************************************************************************************/
+const (
+	APY_FIGURE_FORWARD    = `forward`
+	APY_FIGURE_HISTORICAL = `historical`
+)
+
+/**************************************************************************************************
+** TVaultAPYFigure is the net APY of a vault alone, as a fraction (0.05 for 5%), for the bots and
+** spreadsheets needing a single figure.
+**************************************************************************************************/
+type TVaultAPYFigure struct {
+	ChainID       uint64                  `json:"chainID"`
+	Address       string                  `json:"address"`
+	APY           float64                 `json:"apy"`
+	Type          string                  `json:"type"`
+	DataFreshness *storage.TDataFreshness `json:"dataFreshness,omitempty"`
+}
+
+/**************************************************************************************************
+** GetVaultAPYFigure returns the net APY of a vault, the same one as the APY signed in the
+** attestations: the forward one when the vault has one, the historical one otherwise. It returns
+** false when the error response was sent. The formatting and the caching of the response are done
+** by the router.
+**
+** Endpoint: GET /apy/:chainID/:address
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return TVaultAPYFigure - The net APY of the vault
+** @return bool - False when the request failed and the response was already sent
+**************************************************************************************************/
+func (y Controller) GetVaultAPYFigure(c *gin.Context) (TVaultAPYFigure, bool) {
+	chainID, ok := validateChainID(c, "chainID")
+	if !ok {
+		return TVaultAPYFigure{}, false
+	}
+	address, ok := validateAddress(c, "address", chainID)
+	if !ok {
+		return TVaultAPYFigure{}, false
+	}
+	if _, ok := storage.GetVault(chainID, address); !ok {
+		handleVaultNotFound(c, chainID, address, "GetVaultAPYFigure")
+		return TVaultAPYFigure{}, false
+	}
+	if !validateChainFreshness(c, chainID, "GetVaultAPYFigure") {
+		return TVaultAPYFigure{}, false
+	}
+
+	figure := TVaultAPYFigure{
+		ChainID:       chainID,
+		Address:       address.Hex(),
+		Type:          APY_FIGURE_HISTORICAL,
+		DataFreshness: storage.GetLaggingChainFreshness(chainID),
+	}
+	stored, ok := apr.GetComputedAPY(chainID, address)
+	if !ok {
+		return figure, true
+	}
+	vaultAPY := stored.(apr.TVaultAPY)
+	if vaultAPY.ForwardAPY.NetAPY != nil && vaultAPY.ForwardAPY.Type != `` {
+		figure.APY, _ = vaultAPY.ForwardAPY.NetAPY.Float64()
+		figure.Type = APY_FIGURE_FORWARD
+	} else if vaultAPY.NetAPY != nil {
+		figure.APY, _ = vaultAPY.NetAPY.Float64()
+	}
+	return figure, true
+}
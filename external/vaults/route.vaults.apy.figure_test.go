@@ -0,0 +1,73 @@
+package vaults
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/apr"
+)
+
+/**************************************************************************************************
+** TestGetVaultAPYFigure verifies that the figure is the forward net APY when the vault has one,
+** the historical net APY otherwise, and that the invalid or unknown vaults are rejected.
+**************************************************************************************************/
+func TestGetVaultAPYFigure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	withForward := common.HexToAddress(`0xAF1`)
+	withHistorical := common.HexToAddress(`0xAF2`)
+	withoutAPY := common.HexToAddress(`0xAF3`)
+	for _, address := range []common.Address{withForward, withHistorical, withoutAPY} {
+		storage.StoreVault(1, models.TVault{Address: address, ChainID: 1})
+	}
+	apr.COMPUTED_APY[1].Store(withForward, apr.TVaultAPY{
+		NetAPY:     bigNumber.NewFloat(0.03),
+		ForwardAPY: apr.TForwardAPY{Type: `v3:onchainOracle`, NetAPY: bigNumber.NewFloat(0.0523)},
+	})
+	apr.COMPUTED_APY[1].Store(withHistorical, apr.TVaultAPY{
+		NetAPY:     bigNumber.NewFloat(0.04),
+		ForwardAPY: apr.TForwardAPY{NetAPY: bigNumber.NewFloat(0)},
+	})
+
+	testCases := []struct {
+		name           string
+		chainID        string
+		address        string
+		expectedStatus int
+		expectedAPY    float64
+		expectedType   string
+	}{
+		{name: "Invalid chain ID", chainID: "invalid", address: withForward.Hex(), expectedStatus: http.StatusBadRequest},
+		{name: "Invalid address", chainID: "1", address: "invalid", expectedStatus: http.StatusBadRequest},
+		{name: "Unknown vault", chainID: "1", address: common.HexToAddress(`0xAF4`).Hex(), expectedStatus: http.StatusNotFound},
+		{name: "Forward APY", chainID: "1", address: withForward.Hex(), expectedStatus: http.StatusOK, expectedAPY: 0.0523, expectedType: APY_FIGURE_FORWARD},
+		{name: "Historical APY", chainID: "1", address: withHistorical.Hex(), expectedStatus: http.StatusOK, expectedAPY: 0.04, expectedType: APY_FIGURE_HISTORICAL},
+		{name: "No APY computed", chainID: "1", address: withoutAPY.Hex(), expectedStatus: http.StatusOK, expectedAPY: 0, expectedType: APY_FIGURE_HISTORICAL},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/apy/"+tc.chainID+"/"+tc.address, nil)
+			c.Params = gin.Params{{Key: "chainID", Value: tc.chainID}, {Key: "address", Value: tc.address}}
+
+			figure, ok := Controller{}.GetVaultAPYFigure(c)
+			assert.Equal(t, tc.expectedStatus == http.StatusOK, ok)
+			if !ok {
+				assert.Equal(t, tc.expectedStatus, w.Code)
+				return
+			}
+			assert.InDelta(t, tc.expectedAPY, figure.APY, 1e-12)
+			assert.Equal(t, tc.expectedType, figure.Type)
+			assert.Equal(t, common.HexToAddress(tc.address).Hex(), figure.Address)
+			assert.Equal(t, uint64(1), figure.ChainID)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package vaults
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The windows of the APY statistics, in days.
+**************************************************************************************************/
+var apyStatsWindows = map[string]uint64{
+	`30d`:  30,
+	`90d`:  90,
+	`365d`: 365,
+}
+
+/**************************************************************************************************
+** TAPYStats holds the distribution of the daily APYs of a vault over a window, expressed as
+** fractions (0.05 = 5%). Days is the number of days of history available in the window: a window
+** only partially covered by the history still returns the statistics of the available days.
+**************************************************************************************************/
+type TAPYStats struct {
+	Min    float64 `json:"min"`
+	P25    float64 `json:"p25"`
+	Median float64 `json:"median"`
+	P75    float64 `json:"p75"`
+	Max    float64 `json:"max"`
+	Days   int     `json:"days"`
+}
+
+/**************************************************************************************************
+** TVaultAPYStats is the response of the APY statistics endpoint. A window without any history is
+** null.
+**************************************************************************************************/
+type TVaultAPYStats struct {
+	Address string                `json:"address"`
+	ChainID uint64                `json:"chainID"`
+	Windows map[string]*TAPYStats `json:"windows"`
+}
+
+/**************************************************************************************************
+** computeAPYStats computes the statistics of the daily APYs recorded since the given timestamp.
+** It returns nil if none were.
+**************************************************************************************************/
+func computeAPYStats(history []models.TVaultDailyAPY, since uint64) *TAPYStats {
+	values := []float64{}
+	for _, point := range history {
+		if point.Timestamp >= since {
+			values = append(values, point.APY)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	sort.Float64s(values)
+	return &TAPYStats{
+		Min:    values[0],
+		P25:    helpers.Percentile(values, 0.25),
+		Median: helpers.Percentile(values, 0.5),
+		P75:    helpers.Percentile(values, 0.75),
+		Max:    values[len(values)-1],
+		Days:   len(values),
+	}
+}
+
+/**************************************************************************************************
+** GetVaultAPYStats returns the min, max, median and quartiles of the daily APY of a vault over the
+** last 30, 90 and 365 days, for the frontends to show the range a vault typically earns rather
+** than only its current APY. The daily APY is the average of the forward net APY (historical net
+** APY when not available) recorded at every snapshot of the day.
+**
+** Endpoint: GET /:chainID/vaults/:address/apy/stats
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the statistics per window
+**************************************************************************************************/
+func (y Controller) GetVaultAPYStats(c *gin.Context) {
+	chainID, ok := validateChainID(c, "chainID")
+	if !ok {
+		return
+	}
+	address, ok := validateAddress(c, "address", chainID)
+	if !ok {
+		return
+	}
+	if _, ok := storage.GetVault(chainID, address); !ok {
+		handleVaultNotFound(c, chainID, address, "GetVaultAPYStats")
+		return
+	}
+
+	history := storage.ListVaultDailyAPY(chainID, address)
+	today := uint64(time.Now().Unix())
+	today -= today % 86400
+
+	response := TVaultAPYStats{
+		Address: address.Hex(),
+		ChainID: chainID,
+		Windows: make(map[string]*TAPYStats),
+	}
+	for window, days := range apyStatsWindows {
+		response.Windows[window] = computeAPYStats(history, today-(days-1)*86400)
+	}
+	c.JSON(http.StatusOK, response)
+}
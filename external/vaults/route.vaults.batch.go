@@ -0,0 +1,184 @@
+package vaults
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** MAX_BATCH_VAULTS is the maximum number of addresses accepted by the batch endpoint.
+**************************************************************************************************/
+const MAX_BATCH_VAULTS = 50
+
+/**************************************************************************************************
+** TBatchVaultsRequest is the body of the batch endpoint.
+**************************************************************************************************/
+type TBatchVaultsRequest struct {
+	Addresses []string `json:"addresses"`
+}
+
+/**************************************************************************************************
+** applyStrategiesDebt sets the total debt of the strategies to the current debt reported by Kong
+** for the vault, which is more recent than the one read from the vault.
+**************************************************************************************************/
+func applyStrategiesDebt(strategies []TExternalStrategy, debts []models.TKongDebt) {
+	for i, strategy := range strategies {
+		strategyAddress := common.HexToAddress(strategy.Address)
+		for _, debt := range debts {
+			if debt.Strategy != strategyAddress.Hex() {
+				continue
+			}
+			if debt.CurrentDebt != nil {
+				strategies[i].Details.TotalDebt = bigNumber.NewInt().SetString(*debt.CurrentDebt)
+			} else if debt.TotalDebt != nil {
+				strategies[i].Details.TotalDebt = bigNumber.NewInt().SetString(*debt.TotalDebt)
+			} else {
+				strategies[i].Details.TotalDebt = bigNumber.NewInt().SetString("0")
+			}
+			break
+		}
+	}
+}
+
+/**************************************************************************************************
+** GetBatchVaults returns the details of the given vaults of a chain in one response, for the
+** portfolio apps tracking a few specific vaults without requesting the full list or each vault
+** one by one.
+**
+** The body is a JSON object with the addresses of the vaults:
+**     { "addresses": ["0x...", "0x..."] }
+** Up to MAX_BATCH_VAULTS distinct addresses are accepted. Each vault has the same details as the
+** single vault endpoint, in the order of the request. The unknown and blacklisted vaults are
+** omitted.
+**
+** The endpoint accepts the following parameters:
+** - chainID: The ID of the chain the vaults are deployed on (path parameter)
+** - strategiesCondition: Filter condition for strategies to include (query parameter)
+**   Valid values: "all", "inQueue", "debtRatio", "absolute" (default: "debtRatio")
+**
+** Endpoint: POST /vaults/:chainID/batch
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the requested vaults
+**************************************************************************************************/
+func (y Controller) GetBatchVaults(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), DEFAULT_REQUEST_TIMEOUT)
+	defer cancel()
+
+	chainID, ok := validateChainID(c, "chainID")
+	if !ok {
+		return
+	}
+	strategiesCondition := validateStrategyCondition(c, "strategiesCondition")
+	yieldFormat := validateYieldFormat(c, "yieldFormat")
+	displayPolicy := validateDisplayPolicy(c, "policy")
+	locale := NegotiateLocale(c)
+	setContentLanguage(c, locale)
+
+	var body TBatchVaultsRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		apiErr := NewAPIError(
+			ErrorTypeValidation,
+			ErrorCodeInvalidParam,
+			"Invalid request body",
+			fmt.Sprintf("the body must be a JSON object with an addresses array: %s", err.Error()),
+		).WithContext("GetBatchVaults")
+		handleError(c, apiErr, http.StatusBadRequest, "Invalid request body", "GetBatchVaults")
+		return
+	}
+	if len(body.Addresses) == 0 {
+		apiErr := NewAPIError(
+			ErrorTypeValidation,
+			ErrorCodeMissingParam,
+			"Missing required parameter",
+			"at least one address must be provided",
+		).WithContext("GetBatchVaults")
+		handleError(c, apiErr, http.StatusBadRequest, "Missing required parameter", "GetBatchVaults")
+		return
+	}
+
+	/**********************************************************************************************
+	** Validate the addresses, dropping the duplicates, before counting them against the limit.
+	**********************************************************************************************/
+	addresses := []common.Address{}
+	for i, rawAddress := range body.Addresses {
+		address, ok := helpers.AssertAddress(rawAddress, chainID)
+		if !ok {
+			apiErr := NewAPIError(
+				ErrorTypeValidation,
+				ErrorCodeInvalidAddress,
+				"Invalid address format",
+				fmt.Sprintf("invalid address format at position %d: %s", i, rawAddress),
+			).WithContext("GetBatchVaults")
+			handleError(c, apiErr, http.StatusBadRequest, "Invalid address format", "GetBatchVaults")
+			return
+		}
+		if !helpers.Contains(addresses, address) {
+			addresses = append(addresses, address)
+		}
+	}
+	if len(addresses) > MAX_BATCH_VAULTS {
+		apiErr := NewAPIError(
+			ErrorTypeValidation,
+			ErrorCodeInvalidParam,
+			"Too many addresses",
+			fmt.Sprintf("at most %d addresses can be requested, got %d", MAX_BATCH_VAULTS, len(addresses)),
+		).WithContext("GetBatchVaults")
+		handleError(c, apiErr, http.StatusBadRequest, "Too many addresses", "GetBatchVaults")
+		return
+	}
+	if !validateChainFreshness(c, chainID, "GetBatchVaults") {
+		return
+	}
+
+	data := []TSimplifiedExternalVault{}
+	for _, address := range addresses {
+		currentVault, ok := storage.GetVault(chainID, address)
+		if !ok || IsVaultBlacklisted(chainID, address) {
+			continue
+		}
+
+		newVault, err := CreateExternalVault(currentVault)
+		if err != nil {
+			c.Error(fmt.Errorf("failed to process vault %s on chain %d: %w", address.Hex(), chainID, err))
+			continue
+		}
+
+		APRAsFloat := 0.0
+		if newVault.APR.NetAPR != nil {
+			APRAsFloat, _ = newVault.APR.NetAPR.Float64()
+		}
+		newVault.FeaturingScore = newVault.TVL.TVL * APRAsFloat
+		if newVault.Details.IsHighlighted {
+			newVault.FeaturingScore = newVault.FeaturingScore * HIGHLIGHTING_MULTIPLIER
+		}
+
+		strategies, success := ProcessStrategiesForVault(ctx, c, chainID, address, strategiesCondition, "GetBatchVaults")
+		if !success {
+			return
+		}
+		applyStrategiesDebt(strategies, newVault.Debts)
+		newVault.Strategies = strategies
+
+		vaultAsStrategy, isStrategy := storage.GuessStrategy(chainID, address)
+		simplified := toSimplifiedVersion(newVault, vaultAsStrategy)
+		simplified.Description = newVault.Description
+		if simplified.Description == "" && isStrategy {
+			simplified.Description = vaultAsStrategy.Description
+		}
+		simplified.Display = computeVaultDisplay(chainID, simplified.Address, simplified.APR, displayPolicy)
+		simplified.APR.applyYieldFormat(yieldFormat)
+		simplified.localize(locale)
+		data = append(data, simplified)
+	}
+
+	c.JSON(http.StatusOK, data)
+}
@@ -0,0 +1,116 @@
+package vaults
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** TestGetBatchVaults verifies that the batch route validates its body, counts the distinct
+** addresses against MAX_BATCH_VAULTS, and returns the known vaults in the order of the request.
+**************************************************************************************************/
+func TestGetBatchVaults(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	controller := Controller{}
+	router.POST("/vaults/:chainID/batch", controller.GetBatchVaults)
+
+	first := common.HexToAddress(`0xBA1`)
+	second := common.HexToAddress(`0xBA2`)
+	unknown := common.HexToAddress(`0xBA3`)
+	for _, address := range []common.Address{first, second} {
+		storage.StoreVault(1, models.TVault{Address: address, ChainID: 1, Kind: models.VaultKindMultiple, Version: `v3`})
+		storage.StoreERC20(1, models.TERC20Token{Address: address, ChainID: 1, Name: `Batch Vault`, Symbol: `yvBATCH`, Decimals: 18})
+	}
+
+	addresses := func(count int) string {
+		list := []string{}
+		for i := 0; i < count; i++ {
+			list = append(list, `"`+common.BigToAddress(big.NewInt(int64(0xC000+i))).Hex()+`"`)
+		}
+		return `{"addresses":[` + strings.Join(list, `,`) + `]}`
+	}
+	duplicates := []string{}
+	for i := 0; i < MAX_BATCH_VAULTS+10; i++ {
+		duplicates = append(duplicates, `"`+first.Hex()+`"`)
+	}
+
+	testCases := []struct {
+		name           string
+		chainID        string
+		body           string
+		expectedStatus int
+		expectedOrder  []common.Address
+	}{
+		{name: "Invalid chain ID", chainID: "invalid", body: `{"addresses":["` + first.Hex() + `"]}`, expectedStatus: http.StatusBadRequest},
+		{name: "Invalid body", chainID: "1", body: `not json`, expectedStatus: http.StatusBadRequest},
+		{name: "No address", chainID: "1", body: `{"addresses":[]}`, expectedStatus: http.StatusBadRequest},
+		{name: "Invalid address", chainID: "1", body: `{"addresses":["` + first.Hex() + `","0x123"]}`, expectedStatus: http.StatusBadRequest},
+		{name: "Too many addresses", chainID: "1", body: addresses(MAX_BATCH_VAULTS + 1), expectedStatus: http.StatusBadRequest},
+		{name: "Duplicates counted once", chainID: "1", body: `{"addresses":[` + strings.Join(duplicates, `,`) + `]}`, expectedStatus: http.StatusOK, expectedOrder: []common.Address{first}},
+		{name: "Order of the request, unknown omitted", chainID: "1", body: `{"addresses":["` + second.Hex() + `","` + unknown.Hex() + `","` + first.Hex() + `"]}`, expectedStatus: http.StatusOK, expectedOrder: []common.Address{second, first}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/vaults/"+tc.chainID+"/batch", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			response := []TSimplifiedExternalVault{}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			order := []common.Address{}
+			for _, vault := range response {
+				order = append(order, common.HexToAddress(vault.Address))
+			}
+			assert.Equal(t, tc.expectedOrder, order)
+		})
+	}
+}
+
+/**************************************************************************************************
+** TestApplyStrategiesDebt verifies that the debt of a strategy is the current debt from Kong, its
+** total debt otherwise, and that the strategies unknown to Kong are left untouched.
+**************************************************************************************************/
+func TestApplyStrategiesDebt(t *testing.T) {
+	strategy := common.HexToAddress(`0xBB1`)
+	currentDebt, totalDebt := `200`, `100`
+
+	testCases := []struct {
+		name     string
+		debts    []models.TKongDebt
+		expected string
+	}{
+		{name: "Current debt", debts: []models.TKongDebt{{Strategy: strategy.Hex(), CurrentDebt: &currentDebt, TotalDebt: &totalDebt}}, expected: `200`},
+		{name: "Total debt", debts: []models.TKongDebt{{Strategy: strategy.Hex(), TotalDebt: &totalDebt}}, expected: `100`},
+		{name: "No debt", debts: []models.TKongDebt{{Strategy: strategy.Hex()}}, expected: `0`},
+		{name: "Unknown to Kong", debts: []models.TKongDebt{{Strategy: common.HexToAddress(`0xBB2`).Hex(), CurrentDebt: &currentDebt}}, expected: `42`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			strategies := []TExternalStrategy{{
+				Address: strings.ToLower(strategy.Hex()),
+				Details: &TExternalStrategyDetails{TotalDebt: bigNumber.NewInt(42)},
+			}}
+			applyStrategiesDebt(strategies, tc.debts)
+			assert.Equal(t, tc.expected, strategies[0].Details.TotalDebt.String())
+		})
+	}
+}
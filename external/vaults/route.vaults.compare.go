@@ -0,0 +1,106 @@
+package vaults
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/addresses"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/sort"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/competitors"
+)
+
+/**************************************************************************************************
+** TTokenComparison compares the yields of the vaults of a token with the ones of the competing
+** protocols. Both lists are sorted by APY, highest first, and APYDelta is the best Yearn APY minus
+** the best competing APY (0 when one of the lists is empty). Sources lists the competing protocols
+** compared, the comparison being disabled when it is empty.
+**************************************************************************************************/
+type TTokenComparison struct {
+	ChainID     uint64                         `json:"chainID"`
+	Token       string                         `json:"token"`
+	Sources     []string                       `json:"sources"`
+	Yearn       []competitors.TCompetitorVault `json:"yearn"`
+	Competitors []competitors.TCompetitorVault `json:"competitors"`
+	APYDelta    float64                        `json:"apyDelta"`
+}
+
+/**************************************************************************************************
+** GetTokenComparison lists the yields of the active Yearn vaults of a token next to the ones of
+** the vaults of the same token on the competing protocols of COMPETITOR_SOURCES, for the internal
+** analysis and the competitive dashboards. The Yearn APY is the forward net APY when available,
+** the historical net APY otherwise.
+**
+** Endpoint: GET /compare/:chainID/:token
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the comparison
+**************************************************************************************************/
+func (y Controller) GetTokenComparison(c *gin.Context) {
+	chainID, ok := validateChainID(c, "chainID")
+	if !ok {
+		return
+	}
+	tokenAddress, ok := validateAddress(c, "token", chainID)
+	if !ok {
+		return
+	}
+
+	chain, _ := env.GetChain(chainID)
+	yearnVaults := []competitors.TCompetitorVault{}
+	_, allVaults := storage.ListVaults(chainID)
+	for _, currentVault := range allVaults {
+		if !addresses.Equals(currentVault.AssetAddress, tokenAddress) || models.IsVaultRetired(currentVault) {
+			continue
+		}
+		if helpers.Contains(chain.BlacklistedVaults, currentVault.Address) {
+			continue
+		}
+		vault, err := CreateExternalVault(currentVault)
+		if err != nil {
+			continue
+		}
+		apy := vault.APR.NetAPR
+		if vault.APR.ForwardAPR.NetAPR != nil && !vault.APR.ForwardAPR.NetAPR.IsZero() {
+			apy = vault.APR.ForwardAPR.NetAPR
+		}
+		apyValue := 0.0
+		if apy != nil {
+			apyValue, _ = apy.Float64()
+		}
+		yearnVaults = append(yearnVaults, competitors.TCompetitorVault{
+			Protocol: `yearn`,
+			ID:       vault.Address,
+			ChainID:  chainID,
+			Address:  currentVault.Address,
+			Token:    currentVault.AssetAddress,
+			Name:     vault.Name,
+			APY:      apyValue,
+			TVL:      vault.TVL.TVL,
+			URL:      `https://yearn.fi/vaults/` + strconv.FormatUint(chainID, 10) + `/` + vault.Address,
+		})
+	}
+
+	competitorVaults := []competitors.TCompetitorVault{}
+	if competitors.IsEnabled() {
+		competitorVaults = competitors.ListCompetitorVaults(chainID, tokenAddress)
+	}
+
+	sort.SortBy(`apy`, `desc`, yearnVaults)
+	sort.SortBy(`apy`, `desc`, competitorVaults)
+	comparison := TTokenComparison{
+		ChainID:     chainID,
+		Token:       tokenAddress.Hex(),
+		Sources:     competitors.ListSources(),
+		Yearn:       yearnVaults,
+		Competitors: competitorVaults,
+	}
+	if len(yearnVaults) > 0 && len(competitorVaults) > 0 {
+		comparison.APYDelta = yearnVaults[0].APY - competitorVaults[0].APY
+	}
+	c.JSON(http.StatusOK, comparison)
+}
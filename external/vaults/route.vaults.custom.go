@@ -211,7 +211,7 @@ func (y Controller) GetVaultsForRotki(c *gin.Context) []TRotkiVaults {
 					Name:     vaultToken.Name,
 					Symbol:   vaultToken.Symbol,
 					Decimals: vaultToken.Decimals,
-					Icon:     vaultToken.Icon,
+					Icon:     newVault.Token.Icon,
 				},
 			}
 
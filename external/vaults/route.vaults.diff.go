@@ -0,0 +1,108 @@
+package vaults
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** STORE_VERSION_HEADER is the response header containing the current store version of the chain.
+** Clients should send it back as the `since` query parameter of their next diff request.
+**************************************************************************************************/
+const STORE_VERSION_HEADER = `X-Store-Version`
+
+/**************************************************************************************************
+** TVaultsDiff is the response of the diff endpoint. IsFullSnapshot is true when all the vaults are
+** returned, because no `since` version was provided or because it is not known by this instance.
+**************************************************************************************************/
+type TVaultsDiff struct {
+	Version        uint64                     `json:"version"`
+	Since          uint64                     `json:"since"`
+	IsFullSnapshot bool                       `json:"isFullSnapshot"`
+	Vaults         []TSimplifiedExternalVault `json:"vaults"`
+}
+
+/**************************************************************************************************
+** GetVaultsDiff returns the vaults of a chain whose APY, TVL or price changed since the given
+** store version. The current version is returned in the `X-Store-Version` header and in the body.
+** Combined with the gzip compression of the responses, this lets the clients refreshing every
+** minute pull a few kilobytes instead of the full list of vaults.
+**
+** Query parameters:
+** - since: the store version returned by the previous call. If missing, 0, or more recent than
+**   the current version (e.g. another instance), all the vaults are returned.
+** - strategiesCondition: the condition used to select the strategies of each vault
+**
+** Endpoint: GET /vaults/:chainID/diff
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the changed vaults
+**************************************************************************************************/
+func (y Controller) GetVaultsDiff(c *gin.Context) {
+	chainID, ok := validateChainID(c, "chainID")
+	if !ok {
+		return
+	}
+
+	since := uint64(0)
+	if sinceStr := getQueryParam(c, `since`); sinceStr != `` {
+		parsedSince, err := strconv.ParseUint(sinceStr, 10, 64)
+		if err != nil {
+			handleError(c, fmt.Errorf("invalid since parameter: %s", sinceStr),
+				http.StatusBadRequest, "Invalid since parameter", "GetVaultsDiff")
+			return
+		}
+		since = parsedSince
+	}
+	strategiesCondition := validateStrategyCondition(c, "strategiesCondition")
+	if !validateChainFreshness(c, chainID, "GetVaultsDiff") {
+		return
+	}
+
+	version := storage.GetChainVersion(chainID)
+	isFullSnapshot := since == 0 || since > version
+	c.Header(STORE_VERSION_HEADER, strconv.FormatUint(version, 10))
+
+	chain, _ := env.GetChain(chainID)
+	vaults := []TSimplifiedExternalVault{}
+	_, allVaults := storage.ListVaults(chainID)
+	for _, currentVault := range allVaults {
+		if helpers.Contains(chain.BlacklistedVaults, currentVault.Address) {
+			continue
+		}
+		if !isFullSnapshot {
+			if vaultVersion, ok := storage.GetVaultVersion(chainID, currentVault.Address); !ok || vaultVersion <= since {
+				continue
+			}
+		}
+
+		newVault, err := CreateExternalVault(currentVault)
+		if err != nil {
+			continue
+		}
+		vaultStrategies, _ := storage.ListStrategiesForVault(chainID, currentVault.Address)
+		newVault.Strategies = []TExternalStrategy{}
+		for _, strategy := range vaultStrategies {
+			strategyWithDetails := CreateExternalStrategy(strategy)
+			if !strategyWithDetails.ShouldBeIncluded(strategiesCondition) {
+				continue
+			}
+			newVault.Strategies = append(newVault.Strategies, strategyWithDetails)
+		}
+		vaults = append(vaults, toSimplifiedVersion(newVault, models.TStrategy{}))
+	}
+
+	c.JSON(http.StatusOK, TVaultsDiff{
+		Version:        version,
+		Since:          since,
+		IsFullSnapshot: isFullSnapshot,
+		Vaults:         vaults,
+	})
+}
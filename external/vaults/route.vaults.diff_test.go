@@ -0,0 +1,81 @@
+package vaults
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** TestGetVaultsDiff verifies that the diff route returns all the vaults without a known `since`
+** version, and only the vaults changed after it otherwise, with the current version in the body
+** and in the X-Store-Version header.
+**************************************************************************************************/
+func TestGetVaultsDiff(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	controller := Controller{}
+	router.GET("/vaults/:chainID/diff", controller.GetVaultsDiff)
+
+	unchanged := common.HexToAddress(`0xD1`)
+	changed := common.HexToAddress(`0xD2`)
+	for _, address := range []common.Address{unchanged, changed} {
+		storage.StoreVault(1, models.TVault{Address: address, ChainID: 1, Kind: models.VaultKindMultiple, Version: `v3`})
+		storage.StoreERC20(1, models.TERC20Token{Address: address, ChainID: 1, Name: `Diff Vault`, Symbol: `yvDIFF`, Decimals: 18})
+	}
+	initialVersion := storage.StoreVaultsFingerprints(1, map[common.Address]string{unchanged: `a`, changed: `b`})
+	currentVersion := storage.StoreVaultsFingerprints(1, map[common.Address]string{unchanged: `a`, changed: `c`})
+
+	testCases := []struct {
+		name             string
+		query            string
+		expectedStatus   int
+		expectedFull     bool
+		expectedIncluded []common.Address
+		expectedExcluded []common.Address
+	}{
+		{name: "No since", query: "", expectedStatus: http.StatusOK, expectedFull: true, expectedIncluded: []common.Address{unchanged, changed}},
+		{name: "Since 0", query: "?since=0", expectedStatus: http.StatusOK, expectedFull: true, expectedIncluded: []common.Address{unchanged, changed}},
+		{name: "Since a future version", query: "?since=" + strconv.FormatUint(currentVersion+100, 10), expectedStatus: http.StatusOK, expectedFull: true, expectedIncluded: []common.Address{unchanged, changed}},
+		{name: "Since the initial version", query: "?since=" + strconv.FormatUint(initialVersion, 10), expectedStatus: http.StatusOK, expectedIncluded: []common.Address{changed}, expectedExcluded: []common.Address{unchanged}},
+		{name: "Since the current version", query: "?since=" + strconv.FormatUint(currentVersion, 10), expectedStatus: http.StatusOK, expectedExcluded: []common.Address{unchanged, changed}},
+		{name: "Invalid since", query: "?since=abc", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/vaults/1/diff"+tc.query, nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			response := TVaultsDiff{}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, currentVersion, response.Version)
+			assert.Equal(t, strconv.FormatUint(currentVersion, 10), w.Header().Get(STORE_VERSION_HEADER))
+			assert.Equal(t, tc.expectedFull, response.IsFullSnapshot)
+
+			addresses := map[string]bool{}
+			for _, vault := range response.Vaults {
+				addresses[vault.Address] = true
+			}
+			for _, address := range tc.expectedIncluded {
+				assert.True(t, addresses[address.Hex()], "Expected the vault %s", address.Hex())
+			}
+			for _, address := range tc.expectedExcluded {
+				assert.False(t, addresses[address.Hex()], "Expected no vault %s", address.Hex())
+			}
+		})
+	}
+}
@@ -382,7 +382,9 @@ func (y Controller) GetEarnedPerUser(c *gin.Context) {
 	graphQLEndpoint := chain.SubgraphURI
 	if graphQLEndpoint == "" {
 		logs.Error(`No graph endpoint for chainID`, chainID)
-		c.String(http.StatusInternalServerError, `impossible to fetch subgraph`)
+		handleError(c, NewAPIError(ErrorTypeConfig, ErrorCodeChainConfigMissing, "Subgraph not available",
+			fmt.Sprintf("no graph endpoint configured for chainID %d", chainID)),
+			http.StatusInternalServerError, "Subgraph not available", "GetEarnedPerUser")
 		return
 	}
 
@@ -391,7 +393,8 @@ func (y Controller) GetEarnedPerUser(c *gin.Context) {
 	var response models.TFIFOForUserForVault
 	if err := client.Run(context.Background(), request, &response); err != nil {
 		logs.Error(err)
-		c.String(http.StatusInternalServerError, `invalid graphQL response`)
+		handleError(c, NewAPIError(ErrorTypeExternal, ErrorCodeGraphQLFailed, "Failed to fetch data from subgraph", err.Error()),
+			http.StatusInternalServerError, "Failed to fetch data from subgraph", "GetEarnedPerUser")
 		return
 	}
 
@@ -661,7 +664,9 @@ func (y Controller) GetEarnedPerUserForAllChains(c *gin.Context) {
 		graphQLEndpoint := chain.SubgraphURI
 		if graphQLEndpoint == "" {
 			logs.Error(`No graph endpoint for chainID`, chainID)
-			c.String(http.StatusInternalServerError, `impossible to fetch subgraph`)
+			handleError(c, NewAPIError(ErrorTypeConfig, ErrorCodeChainConfigMissing, "Subgraph not available",
+				fmt.Sprintf("no graph endpoint configured for chainID %d", chainID)),
+				http.StatusInternalServerError, "Subgraph not available", "GetEarnedPerUserForAllChains")
 			return
 		}
 
@@ -670,7 +675,8 @@ func (y Controller) GetEarnedPerUserForAllChains(c *gin.Context) {
 		var response models.TFIFOForUserForVault
 		if err := client.Run(context.Background(), request, &response); err != nil {
 			logs.Error(err)
-			c.String(http.StatusInternalServerError, `invalid graphQL response`)
+			handleError(c, NewAPIError(ErrorTypeExternal, ErrorCodeGraphQLFailed, "Failed to fetch data from subgraph", err.Error()),
+				http.StatusInternalServerError, "Failed to fetch data from subgraph", "GetEarnedPerUserForAllChains")
 			return
 		}
 
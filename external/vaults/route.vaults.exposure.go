@@ -0,0 +1,330 @@
+package vaults
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/addresses"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/sort"
+	"github.com/yearn/ydaemon/internal/fetcher"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/apr"
+)
+
+/**************************************************************************************************
+** Exposure sources, explaining why a vault is returned by the reverse lookup endpoints.
+**************************************************************************************************/
+const (
+	EXPOSURE_VIA_UNDERLYING        = `underlying`
+	EXPOSURE_VIA_UNDERLYING_TOKENS = `underlyingTokens`
+	EXPOSURE_VIA_STAKING_REWARDS   = `stakingRewards`
+	EXPOSURE_VIA_VAULT_PROTOCOLS   = `vaultProtocols`
+	EXPOSURE_VIA_STRATEGY          = `strategy`
+	EXPOSURE_VIA_STRATEGY_REWARDS  = `strategyRewards`
+	EXPOSURE_VIA_NESTED_VAULT      = `nestedVault`
+)
+
+/**************************************************************************************************
+** TExternalVaultExposure is a lightweight representation of a vault exposed to a token or a
+** protocol. It contains the value at risk (TVL) and the list of the paths through which the vault
+** is exposed, so risk teams can quickly assess the impact of an incident.
+**************************************************************************************************/
+type TExternalVaultExposure struct {
+	Address      string   `json:"address"`
+	ChainID      uint64   `json:"chainID"`
+	Name         string   `json:"name"`
+	Symbol       string   `json:"symbol"`
+	Version      string   `json:"version"`
+	TVL          float64  `json:"tvl"`
+	IsRetired    bool     `json:"isRetired"`
+	ExposedVia   []string `json:"exposedVia"`
+	Strategies   []string `json:"strategies,omitempty"`   // Strategies exposed to the protocol, if any
+	NestedVaults []string `json:"nestedVaults,omitempty"` // Vaults used as strategies through which the vault is exposed, if any
+}
+
+/**************************************************************************************************
+** toVaultExposure builds the exposure entry for a vault with the given exposure paths.
+**************************************************************************************************/
+func toVaultExposure(vault models.TVault, exposure tResolvedExposure) TExternalVaultExposure {
+	name, _, _ := fetcher.BuildVaultNames(vault, vault.Metadata.DisplayName)
+	symbol, _, _ := fetcher.BuildVaultSymbol(vault, vault.Metadata.DisplaySymbol)
+	return TExternalVaultExposure{
+		Address:      vault.Address.Hex(),
+		ChainID:      vault.ChainID,
+		Name:         name,
+		Symbol:       symbol,
+		Version:      vault.Version,
+		TVL:          fetcher.BuildVaultTVL(vault).TVL,
+		IsRetired:    models.IsVaultRetired(vault),
+		ExposedVia:   exposure.exposedVia,
+		Strategies:   exposure.strategies,
+		NestedVaults: exposure.nestedVaults,
+	}
+}
+
+/**************************************************************************************************
+** tResolvedExposure are the paths through which a vault is exposed, with the strategies and the
+** nested vaults involved.
+**************************************************************************************************/
+type tResolvedExposure struct {
+	exposedVia   []string
+	strategies   []string
+	nestedVaults []string
+}
+
+/**************************************************************************************************
+** tExposureResolver resolves the exposure of the vaults of a request: their direct exposure and
+** the one of the vaults they deposit into, a vault being used as a strategy by another one. The
+** exposure of each vault is resolved once, and a vault nested in itself is not followed again.
+**************************************************************************************************/
+type tExposureResolver struct {
+	direct   func(vault models.TVault) ([]string, []string)
+	resolved map[common.Address]tResolvedExposure
+	visiting map[common.Address]bool
+}
+
+func newExposureResolver(direct func(vault models.TVault) ([]string, []string)) *tExposureResolver {
+	return &tExposureResolver{
+		direct:   direct,
+		resolved: make(map[common.Address]tResolvedExposure),
+		visiting: make(map[common.Address]bool),
+	}
+}
+
+/**************************************************************************************************
+** resolve returns the exposure of a vault: its direct exposure, and the nested vaults exposed
+** themselves, directly or through their own nested vaults, among its active strategies.
+**************************************************************************************************/
+func (r *tExposureResolver) resolve(vault models.TVault) tResolvedExposure {
+	if resolved, ok := r.resolved[vault.Address]; ok {
+		return resolved
+	}
+	if r.visiting[vault.Address] {
+		return tResolvedExposure{}
+	}
+	r.visiting[vault.Address] = true
+	defer delete(r.visiting, vault.Address)
+
+	exposedVia, strategies := r.direct(vault)
+	nestedVaults := []string{}
+	vaultStrategies, _ := storage.ListStrategiesForVault(vault.ChainID, vault.Address)
+	for _, strategy := range vaultStrategies {
+		if strategy.IsRetired {
+			continue
+		}
+		nestedVault, ok := storage.GetVault(vault.ChainID, strategy.Address)
+		if !ok {
+			continue
+		}
+		if len(r.resolve(nestedVault).exposedVia) > 0 {
+			nestedVaults = append(nestedVaults, nestedVault.Address.Hex())
+		}
+	}
+	if len(nestedVaults) > 0 {
+		slices.Sort(nestedVaults)
+		exposedVia = append(exposedVia, EXPOSURE_VIA_NESTED_VAULT)
+	}
+
+	resolved := tResolvedExposure{exposedVia: exposedVia, strategies: strategies, nestedVaults: nestedVaults}
+	r.resolved[vault.Address] = resolved
+	return resolved
+}
+
+/**************************************************************************************************
+** getTokenExposure returns the paths through which a vault is exposed to a given token:
+** - the token is the underlying asset of the vault
+** - the token is one of the tokens composing the underlying asset (LP tokens, wrappers, ...)
+** - the token is distributed as a staking reward for the vault
+** - the token is harvested as a reward by the strategies of the vault
+**************************************************************************************************/
+func getTokenExposure(vault models.TVault, tokenAddress common.Address) []string {
+	exposedVia := []string{}
+	if addresses.Equals(vault.AssetAddress, tokenAddress) {
+		exposedVia = append(exposedVia, EXPOSURE_VIA_UNDERLYING)
+	}
+	if underlying, ok := storage.GetERC20(vault.ChainID, vault.AssetAddress); ok {
+		if helpers.Contains(underlying.UnderlyingTokensAddresses, tokenAddress) {
+			exposedVia = append(exposedVia, EXPOSURE_VIA_UNDERLYING_TOKENS)
+		}
+	}
+	staking := assignStakingData(vault.ChainID, vault.Address)
+	for _, reward := range staking.Rewards {
+		if addresses.Equals(common.HexToAddress(reward.Address), tokenAddress) {
+			exposedVia = append(exposedVia, EXPOSURE_VIA_STAKING_REWARDS)
+			break
+		}
+	}
+	if computedAPY, ok := apr.GetComputedAPY(vault.ChainID, vault.Address); ok {
+		for _, reward := range computedAPY.(apr.TVaultAPY).ForwardAPY.Composite.Rewards {
+			if addresses.Equals(reward.Token, tokenAddress) {
+				exposedVia = append(exposedVia, EXPOSURE_VIA_STRATEGY_REWARDS)
+				break
+			}
+		}
+	}
+	return exposedVia
+}
+
+/**************************************************************************************************
+** getProtocolExposure returns the paths through which a vault is exposed to a given protocol,
+** based on the protocols set in the vault and strategies metadata. The match is case-insensitive.
+** The list of the exposed strategies is returned as well.
+**************************************************************************************************/
+func getProtocolExposure(vault models.TVault, protocol string) ([]string, []string) {
+	exposedVia := []string{}
+	exposedStrategies := []string{}
+	for _, vaultProtocol := range vault.Metadata.Protocols {
+		if strings.EqualFold(vaultProtocol, protocol) {
+			exposedVia = append(exposedVia, EXPOSURE_VIA_VAULT_PROTOCOLS)
+			break
+		}
+	}
+
+	strategies, _ := storage.ListStrategiesForVault(vault.ChainID, vault.Address)
+	for _, strategy := range strategies {
+		if strategy.IsRetired {
+			continue
+		}
+		for _, strategyProtocol := range strategy.Protocols {
+			if strings.EqualFold(strategyProtocol, protocol) {
+				exposedStrategies = append(exposedStrategies, strategy.Address.Hex())
+				break
+			}
+		}
+	}
+	if len(exposedStrategies) > 0 {
+		exposedVia = append(exposedVia, EXPOSURE_VIA_STRATEGY)
+	}
+	return exposedVia, exposedStrategies
+}
+
+/**************************************************************************************************
+** strategyUsesProtocols returns true if the strategy is labelled with one of the protocols. The
+** match is case-insensitive.
+**************************************************************************************************/
+func strategyUsesProtocols(strategy models.TStrategy, protocols []string) bool {
+	for _, strategyProtocol := range strategy.Protocols {
+		for _, protocol := range protocols {
+			if strings.EqualFold(strategyProtocol, protocol) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/**************************************************************************************************
+** vaultUsesProtocols returns true if the vault is exposed to one of the protocols, through its own
+** labels or the ones of its strategies.
+**************************************************************************************************/
+func vaultUsesProtocols(vault models.TVault, protocols []string) bool {
+	for _, protocol := range protocols {
+		if exposedVia, _ := getProtocolExposure(vault, protocol); len(exposedVia) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+/**************************************************************************************************
+** GetVaultsForToken returns all the vaults of a chain exposed to a specific token, either as
+** underlying asset, as a component of the underlying asset, as a staking or strategy reward, or
+** through a vault they deposit into. The vaults are sorted by TVL, highest first.
+**
+** Endpoint: GET /tokens/:chainID/:address/vaults
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the list of exposed vaults
+**************************************************************************************************/
+func (y Controller) GetVaultsForToken(c *gin.Context) {
+	chainID, ok := validateChainID(c, "chainID")
+	if !ok {
+		return
+	}
+	tokenAddress, ok := validateAddress(c, "address", chainID)
+	if !ok {
+		return
+	}
+
+	chain, _ := env.GetChain(chainID)
+	exposures := []TExternalVaultExposure{}
+	resolver := newExposureResolver(func(vault models.TVault) ([]string, []string) {
+		return getTokenExposure(vault, tokenAddress), nil
+	})
+	_, allVaults := storage.ListVaults(chainID)
+	for _, vault := range allVaults {
+		if helpers.Contains(chain.BlacklistedVaults, vault.Address) {
+			continue
+		}
+		exposure := resolver.resolve(vault)
+		if len(exposure.exposedVia) == 0 {
+			continue
+		}
+		exposures = append(exposures, toVaultExposure(vault, exposure))
+	}
+
+	sort.SortBy(`tvl`, `desc`, exposures)
+	c.JSON(http.StatusOK, exposures)
+}
+
+/**************************************************************************************************
+** GetVaultsForProtocol returns all the vaults, across all the supported chains or the chains
+** provided in the `chainIDs` query parameter, exposed to a specific protocol through their own
+** metadata, through one of their strategies or through a vault they deposit into. The vaults are
+** sorted by TVL, highest first.
+**
+** Endpoint: GET /protocols/:name/vaults
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the list of exposed vaults
+**************************************************************************************************/
+func (y Controller) GetVaultsForProtocol(c *gin.Context) {
+	protocol := strings.TrimSpace(c.Param("name"))
+	if protocol == "" {
+		handleError(c, fmt.Errorf("name parameter is required"),
+			http.StatusBadRequest, "Missing required parameter", "GetVaultsForProtocol")
+		return
+	}
+
+	chains := env.SUPPORTED_CHAIN_IDS
+	if chainIDs := getQueryParam(c, `chainIDs`); chainIDs != `` {
+		chains = []uint64{}
+		for _, chainStr := range strings.Split(chainIDs, `,`) {
+			if chainID, ok := helpers.AssertChainID(chainStr); ok {
+				chains = append(chains, chainID)
+			}
+		}
+	}
+
+	exposures := []TExternalVaultExposure{}
+	for _, chainID := range chains {
+		chain, ok := env.GetChain(chainID)
+		if !ok {
+			continue
+		}
+		resolver := newExposureResolver(func(vault models.TVault) ([]string, []string) {
+			return getProtocolExposure(vault, protocol)
+		})
+		_, allVaults := storage.ListVaults(chainID)
+		for _, vault := range allVaults {
+			if helpers.Contains(chain.BlacklistedVaults, vault.Address) {
+				continue
+			}
+			exposure := resolver.resolve(vault)
+			if len(exposure.exposedVia) == 0 {
+				continue
+			}
+			exposures = append(exposures, toVaultExposure(vault, exposure))
+		}
+	}
+
+	sort.SortBy(`tvl`, `desc`, exposures)
+	c.JSON(http.StatusOK, exposures)
+}
@@ -0,0 +1,124 @@
+package vaults
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/apr"
+)
+
+/**************************************************************************************************
+** mockExposureVaults stores a vault per exposure path on chain 1:
+** - 0xE1 has the token 0xEA as underlying, and 0xE2 deposits into it
+** - 0xE3 has a strategy harvesting the token 0xEB
+** - 0xE4 has a strategy labelled Morpho, and 0xE5 deposits into 0xE2 and 0xE4
+** - 0xE6 and 0xE7 deposit into each other without any exposure
+**************************************************************************************************/
+func mockExposureVaults() {
+	vault := func(address string, asset string) {
+		storage.StoreVault(1, models.TVault{
+			Address:      common.HexToAddress(address),
+			AssetAddress: common.HexToAddress(asset),
+			ChainID:      1,
+			Kind:         models.VaultKindMultiple,
+			Version:      `3.0.2`,
+		})
+	}
+	strategy := func(vaultAddress string, address string, protocols ...string) {
+		storage.StoreStrategy(1, models.TStrategy{
+			Address:      common.HexToAddress(address),
+			VaultAddress: common.HexToAddress(vaultAddress),
+			ChainID:      1,
+			Protocols:    protocols,
+		})
+	}
+	vault(`0xE1`, `0xEA`)
+	vault(`0xE2`, `0xEC`)
+	vault(`0xE3`, `0xEC`)
+	vault(`0xE4`, `0xEC`)
+	vault(`0xE5`, `0xEC`)
+	vault(`0xE6`, `0xEC`)
+	vault(`0xE7`, `0xEC`)
+	strategy(`0xE2`, `0xE1`)
+	strategy(`0xE3`, `0xF3`)
+	strategy(`0xE4`, `0xF4`, `Morpho`)
+	strategy(`0xE5`, `0xE2`)
+	strategy(`0xE5`, `0xE4`)
+	strategy(`0xE6`, `0xE7`)
+	strategy(`0xE7`, `0xE6`)
+	apr.COMPUTED_APY[1].Store(common.HexToAddress(`0xE3`), apr.TVaultAPY{ForwardAPY: apr.TForwardAPY{
+		Composite: models.TCompositeData{Rewards: []models.TRewardAPR{{Token: common.HexToAddress(`0xEB`), APR: bigNumber.NewFloat(0.01)}}},
+	}})
+}
+
+/**************************************************************************************************
+** getExposures serves a request on the exposure routes and returns the exposures of the mocked
+** vaults, by address.
+**************************************************************************************************/
+func getExposures(t *testing.T, path string) map[string]TExternalVaultExposure {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	controller := Controller{}
+	router.GET("/tokens/:chainID/:address/vaults", controller.GetVaultsForToken)
+	router.GET("/protocols/:name/vaults", controller.GetVaultsForProtocol)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	response := []TExternalVaultExposure{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	exposures := make(map[string]TExternalVaultExposure)
+	for _, exposure := range response {
+		exposures[exposure.Address] = exposure
+	}
+	return exposures
+}
+
+/**************************************************************************************************
+** TestGetVaultsForToken checks that the vaults are found through their underlying, the rewards of
+** their strategies and the vaults they deposit into, the nested vaults being followed recursively.
+**************************************************************************************************/
+func TestGetVaultsForToken(t *testing.T) {
+	mockExposureVaults()
+	e1, e2, e3, e5 := common.HexToAddress(`0xE1`).Hex(), common.HexToAddress(`0xE2`).Hex(), common.HexToAddress(`0xE3`).Hex(), common.HexToAddress(`0xE5`).Hex()
+
+	exposures := getExposures(t, `/tokens/1/`+common.HexToAddress(`0xEA`).Hex()+`/vaults`)
+	assert.Equal(t, []string{EXPOSURE_VIA_UNDERLYING}, exposures[e1].ExposedVia)
+	assert.Equal(t, []string{EXPOSURE_VIA_NESTED_VAULT}, exposures[e2].ExposedVia)
+	assert.Equal(t, []string{e1}, exposures[e2].NestedVaults)
+	assert.Equal(t, []string{EXPOSURE_VIA_NESTED_VAULT}, exposures[e5].ExposedVia)
+	assert.Equal(t, []string{e2}, exposures[e5].NestedVaults)
+	assert.NotContains(t, exposures, e3)
+	assert.NotContains(t, exposures, common.HexToAddress(`0xE6`).Hex())
+
+	exposures = getExposures(t, `/tokens/1/`+common.HexToAddress(`0xEB`).Hex()+`/vaults`)
+	assert.Equal(t, []string{EXPOSURE_VIA_STRATEGY_REWARDS}, exposures[e3].ExposedVia)
+	assert.NotContains(t, exposures, e1)
+}
+
+/**************************************************************************************************
+** TestGetVaultsForProtocol checks that the vaults are found through the protocols of their
+** strategies and through the vaults they deposit into, and that a cycle of vaults ends.
+**************************************************************************************************/
+func TestGetVaultsForProtocol(t *testing.T) {
+	mockExposureVaults()
+	e4, e5 := common.HexToAddress(`0xE4`).Hex(), common.HexToAddress(`0xE5`).Hex()
+
+	exposures := getExposures(t, `/protocols/morpho/vaults?chainIDs=1`)
+	assert.Equal(t, []string{EXPOSURE_VIA_STRATEGY}, exposures[e4].ExposedVia)
+	assert.Equal(t, []string{common.HexToAddress(`0xF4`).Hex()}, exposures[e4].Strategies)
+	assert.Equal(t, []string{EXPOSURE_VIA_NESTED_VAULT}, exposures[e5].ExposedVia)
+	assert.Equal(t, []string{e4}, exposures[e5].NestedVaults)
+	assert.NotContains(t, exposures, common.HexToAddress(`0xE2`).Hex())
+	assert.NotContains(t, exposures, common.HexToAddress(`0xE6`).Hex())
+}
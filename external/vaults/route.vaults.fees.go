@@ -0,0 +1,97 @@
+package vaults
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/sort"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/fees"
+)
+
+/**************************************************************************************************
+** TVaultFeesSummary are the current fees of a vault, in basis points, with its pending fees and the
+** config of its accountant for the multi-strategy v3 vaults. ChangesCount is the number of fee
+** changes indexed from the events of the vault and of its accountant, and LastChangedAt and
+** LastChangedBlock the time and the block of the event of the last one, 0 when none was indexed.
+**************************************************************************************************/
+type TVaultFeesSummary struct {
+	Address          string                  `json:"address"`
+	Name             string                  `json:"name"`
+	Symbol           string                  `json:"symbol"`
+	Kind             string                  `json:"kind"`
+	Version          string                  `json:"version"`
+	ManagementFee    uint64                  `json:"managementFee"`
+	PerformanceFee   uint64                  `json:"performanceFee"`
+	PendingFees      *fees.TPendingFees      `json:"pendingFees,omitempty"`
+	AccountantConfig *fees.TAccountantConfig `json:"accountantConfig,omitempty"`
+	ChangesCount     uint64                  `json:"changesCount"`
+	LastChangedAt    uint64                  `json:"lastChangedAt"`
+	LastChangedBlock uint64                  `json:"lastChangedBlock"`
+}
+
+/**************************************************************************************************
+** GetFeesSummary returns the current fees of all the vaults of a chain, with the config of their
+** accountant for the multi-strategy v3 vaults and the number and time of the changes of their
+** fees, so the fee landscape of the protocol can be audited without fetching every vault. The
+** fees from Kong take precedence over the ones read onchain, like on the vault routes.
+**
+** Query parameters:
+** - orderBy: Field to sort results by, one of 'performanceFee', 'managementFee', 'changesCount',
+**   'lastChangedAt', 'name' or 'address' (default: 'performanceFee')
+** - orderDirection: Sort direction, 'asc' or 'desc' (default: 'desc')
+**
+** Endpoint: GET /fees/:chainID
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the fees of the vaults of the chain
+**************************************************************************************************/
+func (y Controller) GetFeesSummary(c *gin.Context) {
+	chainID, ok := validateChainID(c, "chainID")
+	if !ok {
+		return
+	}
+
+	validOrderFields := []string{
+		"performanceFee", "managementFee", "changesCount", "lastChangedAt", "name", "address",
+	}
+	orderBy := validateStringChoiceQuery(c, "orderBy", "performanceFee", validOrderFields, "GetFeesSummary")
+	validDirections := []string{"asc", "desc"}
+	orderDirection := validateStringChoiceQuery(c, "orderDirection", "desc", validDirections, "GetFeesSummary")
+
+	_, vaults := storage.ListVaults(chainID)
+	summaries := []TVaultFeesSummary{}
+	for _, vault := range vaults {
+		summary := TVaultFeesSummary{
+			Address:        vault.Address.Hex(),
+			Kind:           string(vault.Kind),
+			Version:        vault.Version,
+			ManagementFee:  vault.ManagementFee,
+			PerformanceFee: vault.PerformanceFee,
+		}
+		if kongData, ok := storage.GetKongVaultData(chainID, vault.Address); ok {
+			summary.ManagementFee = kongData.ManagementFee
+			summary.PerformanceFee = kongData.PerformanceFee
+		}
+		if vaultToken, ok := storage.GetERC20(chainID, vault.Address); ok {
+			summary.Name = vaultToken.Name
+			summary.Symbol = vaultToken.Symbol
+		}
+		if pendingFees, ok := fees.GetPendingFees(chainID, vault.Address); ok {
+			summary.PendingFees = &pendingFees
+		}
+		if accountantConfig, ok := fees.GetAccountantConfig(chainID, vault.Address); ok {
+			summary.AccountantConfig = &accountantConfig
+		}
+		if history := storage.ListFeeHistory(chainID, vault.Address); len(history) > 0 {
+			lastChange := history[len(history)-1]
+			summary.ChangesCount = uint64(len(history))
+			summary.LastChangedAt = lastChange.Timestamp
+			summary.LastChangedBlock = lastChange.BlockNumber
+		}
+		summaries = append(summaries, summary)
+	}
+
+	sort.SortBy(orderBy, orderDirection, summaries)
+	c.JSON(http.StatusOK, summaries)
+}
@@ -0,0 +1,80 @@
+package vaults
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** getFeesSummaries serves a request on the fee summary route and returns the summaries by address.
+**************************************************************************************************/
+func getFeesSummaries(t *testing.T, path string) (map[string]TVaultFeesSummary, []TVaultFeesSummary) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	controller := Controller{}
+	router.GET("/fees/:chainID", controller.GetFeesSummary)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	response := []TVaultFeesSummary{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	summaries := make(map[string]TVaultFeesSummary)
+	for _, summary := range response {
+		summaries[summary.Address] = summary
+	}
+	return summaries, response
+}
+
+/**************************************************************************************************
+** TestGetFeesSummary checks that the last change of the fees of a vault is the one of the last fee
+** event indexed for it, with the time and the block of that event, and that a vault without any
+** indexed change reports none.
+**************************************************************************************************/
+func TestGetFeesSummary(t *testing.T) {
+	changed := common.HexToAddress(`0xFE1`)
+	unchanged := common.HexToAddress(`0xFE2`)
+	for _, address := range []common.Address{changed, unchanged} {
+		storage.StoreVault(1, models.TVault{
+			Address:        address,
+			ChainID:        1,
+			Kind:           models.VaultKindLegacy,
+			Version:        `0.4.6`,
+			PerformanceFee: 1000,
+			ManagementFee:  0,
+		})
+	}
+	storage.StoreFeeChange(1, changed, models.TFeeSnapshot{BlockNumber: 18_000_000, Timestamp: 1_690_000_000, PerformanceFee: 2000})
+	storage.StoreFeeChange(1, changed, models.TFeeSnapshot{BlockNumber: 19_000_000, Timestamp: 1_705_000_000, PerformanceFee: 1000})
+
+	summaries, _ := getFeesSummaries(t, `/fees/1`)
+
+	summary, ok := summaries[changed.Hex()]
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1000), summary.PerformanceFee)
+	assert.Equal(t, uint64(2), summary.ChangesCount)
+	assert.Equal(t, uint64(1_705_000_000), summary.LastChangedAt)
+	assert.Equal(t, uint64(19_000_000), summary.LastChangedBlock)
+
+	summary, ok = summaries[unchanged.Hex()]
+	assert.True(t, ok)
+	assert.Equal(t, uint64(0), summary.ChangesCount)
+	assert.Equal(t, uint64(0), summary.LastChangedAt)
+	assert.Equal(t, uint64(0), summary.LastChangedBlock)
+
+	_, ordered := getFeesSummaries(t, `/fees/1?orderBy=lastChangedAt&orderDirection=desc`)
+	assert.NotEmpty(t, ordered)
+	for i := 1; i < len(ordered); i++ {
+		assert.GreaterOrEqual(t, ordered[i-1].LastChangedAt, ordered[i].LastChangedAt)
+	}
+}
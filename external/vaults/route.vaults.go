@@ -100,7 +100,7 @@ func (y Controller) GetV2(c *gin.Context) ([]TSimplifiedExternalVault, error) {
 **************************************************************************************************/
 func (y Controller) GetRetired(c *gin.Context) ([]TSimplifiedExternalVault, error) {
 	return getVaults(c, func(vault models.TVault) bool {
-		return vault.Metadata.IsRetired
+		return models.IsVaultRetired(vault)
 	})
 }
 
@@ -161,6 +161,6 @@ func (y Controller) GetLegacyIsGimme(c *gin.Context) []TExternalVault {
 **************************************************************************************************/
 func (y Controller) GetLegacyRetired(c *gin.Context) []TExternalVault {
 	return getLegacyVaults(c, func(vault models.TVault) bool {
-		return vault.Metadata.IsRetired
+		return models.IsVaultRetired(vault)
 	})
 }
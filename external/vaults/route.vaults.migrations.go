@@ -0,0 +1,64 @@
+package vaults
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/migrations"
+)
+
+/**************************************************************************************************
+** TVaultMigrationPrompt is a deprecated vault with its suggested replacement, with all a frontend
+** needs to render a "Migrate for +2.3%" prompt.
+**************************************************************************************************/
+type TVaultMigrationPrompt struct {
+	Vault        string   `json:"vault"`
+	Name         string   `json:"name"`
+	Symbol       string   `json:"symbol"`
+	Target       string   `json:"target"`
+	TargetName   string   `json:"targetName"`
+	TargetSymbol string   `json:"targetSymbol"`
+	Contract     string   `json:"contract"`
+	Source       string   `json:"source"`
+	APYDelta     *float64 `json:"apyDelta"`
+}
+
+/**************************************************************************************************
+** GetVaultsMigrations returns the deprecated vaults of a chain having a replacement, with the
+** target vault, the migration contract, the source of the migration (override, cms or registry)
+** and the APY delta of the migration.
+**
+** Endpoint: GET /vaults/:chainID/migrations
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the migrations of the chain
+**************************************************************************************************/
+func (y Controller) GetVaultsMigrations(c *gin.Context) {
+	chainID, ok := validateChainID(c, "chainID")
+	if !ok {
+		return
+	}
+
+	prompts := []TVaultMigrationPrompt{}
+	for _, migration := range migrations.ListMigrations(chainID) {
+		prompt := TVaultMigrationPrompt{
+			Vault:    migration.Vault.Hex(),
+			Target:   migration.Target.Hex(),
+			Contract: migration.Contract.Hex(),
+			Source:   migration.Source,
+			APYDelta: migration.APYDelta,
+		}
+		if vaultToken, ok := storage.GetERC20(chainID, migration.Vault); ok {
+			prompt.Name = vaultToken.Name
+			prompt.Symbol = vaultToken.Symbol
+		}
+		if targetToken, ok := storage.GetERC20(chainID, migration.Target); ok {
+			prompt.TargetName = targetToken.Name
+			prompt.TargetSymbol = targetToken.Symbol
+		}
+		prompts = append(prompts, prompt)
+	}
+
+	c.JSON(http.StatusOK, prompts)
+}
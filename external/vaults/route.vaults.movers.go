@@ -0,0 +1,181 @@
+package vaults
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The windows supported by the rate-of-change fields and by the movers endpoint.
+**************************************************************************************************/
+const (
+	MOVERS_WINDOW_24H = `24h`
+	MOVERS_WINDOW_7D  = `7d`
+)
+
+var moversWindows = map[string]time.Duration{
+	MOVERS_WINDOW_24H: 24 * time.Hour,
+	MOVERS_WINDOW_7D:  7 * 24 * time.Hour,
+}
+
+/**************************************************************************************************
+** Below MOVERS_MIN_TVL (USD), the relative TVL change of a vault is mostly noise: the vaults under
+** this threshold, now and at the start of the window, are ignored by the movers endpoint.
+**************************************************************************************************/
+const MOVERS_MIN_TVL = 10_000
+
+/**************************************************************************************************
+** tVaultDeltas holds the changes of the APY and TVL of a vault over a window. The APY delta is the
+** difference between the two APYs (0.01 = +1 point), the TVL delta is relative to the TVL at the
+** start of the window (0.05 = +5%). A nil value means the history does not cover the window.
+**************************************************************************************************/
+type tVaultDeltas struct {
+	APY      float64
+	TVL      float64
+	APYDelta *float64
+	TVLDelta *float64
+}
+
+/**************************************************************************************************
+** getVaultDeltas computes the changes of the APY and TVL of a vault between its last snapshot and
+** the snapshot taken one window earlier.
+**************************************************************************************************/
+func getVaultDeltas(chainID uint64, vaultAddress common.Address, window time.Duration) tVaultDeltas {
+	current, ok := storage.GetLastVaultMetrics(chainID, vaultAddress)
+	if !ok {
+		return tVaultDeltas{}
+	}
+	deltas := tVaultDeltas{APY: current.APY, TVL: current.TVL}
+	previousTimestamp := current.Timestamp - uint64(window.Seconds())
+	previous, ok := storage.GetVaultMetricsAt(chainID, vaultAddress, previousTimestamp)
+	if !ok {
+		return deltas
+	}
+	apyDelta := current.APY - previous.APY
+	deltas.APYDelta = &apyDelta
+	if previous.TVL > 0 {
+		tvlDelta := (current.TVL - previous.TVL) / previous.TVL
+		deltas.TVLDelta = &tvlDelta
+	}
+	return deltas
+}
+
+/**************************************************************************************************
+** TVaultMover is a vault of the movers endpoint, with its current APY and TVL and their changes
+** over the requested window.
+**************************************************************************************************/
+type TVaultMover struct {
+	Address  string  `json:"address"`
+	ChainID  uint64  `json:"chainID"`
+	Name     string  `json:"name"`
+	Symbol   string  `json:"symbol"`
+	APY      float64 `json:"apy"`
+	APYDelta float64 `json:"apyDelta"`
+	TVL      float64 `json:"tvl"`
+	TVLDelta float64 `json:"tvlDelta"`
+}
+
+/**************************************************************************************************
+** TVaultMovers is the response of the movers endpoint.
+**************************************************************************************************/
+type TVaultMovers struct {
+	Window  string        `json:"window"`
+	Metric  string        `json:"metric"`
+	Gainers []TVaultMover `json:"gainers"`
+	Losers  []TVaultMover `json:"losers"`
+}
+
+/**************************************************************************************************
+** GetVaultsMovers returns the vaults whose APY or TVL moved the most over the window, from the
+** rolling history recorded at every snapshot. Retired and blacklisted vaults are ignored, as well
+** as the vaults without history covering the window.
+**
+** Query parameters:
+** - window: `24h` (default) or `7d`
+** - metric: `tvl` (default, relative change) or `apy` (change in points)
+** - limit: the number of gainers and of losers to return (default 10, max 100)
+** - chainIDs: comma separated list of chain IDs, all the supported chains by default
+**
+** Endpoint: GET /vaults/movers
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the gainers and losers
+**************************************************************************************************/
+func (y Controller) GetVaultsMovers(c *gin.Context) {
+	window := validateStringChoiceQuery(c, `window`, MOVERS_WINDOW_24H, []string{MOVERS_WINDOW_24H, MOVERS_WINDOW_7D}, "GetVaultsMovers")
+	metric := validateStringChoiceQuery(c, `metric`, `tvl`, []string{`tvl`, `apy`}, "GetVaultsMovers")
+	limit := int(validateNumericQuery(c, `limit`, 10, 1, 100, "GetVaultsMovers"))
+
+	chains := env.SUPPORTED_CHAIN_IDS
+	if chainsStr := getQueryParam(c, `chainIDs`); chainsStr != `` {
+		chains = []uint64{}
+		for _, chainStr := range strings.Split(chainsStr, `,`) {
+			if chainID, ok := helpers.AssertChainID(chainStr); ok {
+				chains = append(chains, chainID)
+			}
+		}
+	}
+
+	movers := []TVaultMover{}
+	for _, chainID := range chains {
+		_, allVaults := storage.ListVaults(chainID)
+		for _, vault := range allVaults {
+			if models.IsVaultRetired(vault) || IsVaultBlacklisted(chainID, vault.Address) {
+				continue
+			}
+			deltas := getVaultDeltas(chainID, vault.Address, moversWindows[window])
+			if deltas.APYDelta == nil || deltas.TVLDelta == nil {
+				continue
+			}
+			previousTVL := deltas.TVL / (1 + *deltas.TVLDelta)
+			if deltas.TVL < MOVERS_MIN_TVL && previousTVL < MOVERS_MIN_TVL {
+				continue
+			}
+			mover := TVaultMover{
+				Address:  vault.Address.Hex(),
+				ChainID:  chainID,
+				APY:      deltas.APY,
+				APYDelta: *deltas.APYDelta,
+				TVL:      deltas.TVL,
+				TVLDelta: *deltas.TVLDelta,
+			}
+			if vaultToken, ok := storage.GetERC20(chainID, vault.Address); ok {
+				mover.Name = vaultToken.Name
+				mover.Symbol = vaultToken.Symbol
+			}
+			movers = append(movers, mover)
+		}
+	}
+
+	delta := func(mover TVaultMover) float64 {
+		if metric == `apy` {
+			return mover.APYDelta
+		}
+		return mover.TVLDelta
+	}
+	sort.SliceStable(movers, func(i, j int) bool {
+		return delta(movers[i]) > delta(movers[j])
+	})
+
+	response := TVaultMovers{Window: window, Metric: metric, Gainers: []TVaultMover{}, Losers: []TVaultMover{}}
+	for i := 0; i < len(movers) && len(response.Gainers) < limit; i++ {
+		if delta(movers[i]) > 0 {
+			response.Gainers = append(response.Gainers, movers[i])
+		}
+	}
+	for i := len(movers) - 1; i >= 0 && len(response.Losers) < limit; i-- {
+		if delta(movers[i]) < 0 {
+			response.Losers = append(response.Losers, movers[i])
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
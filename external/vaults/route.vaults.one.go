@@ -8,8 +8,10 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/attestation"
 	"github.com/yearn/ydaemon/internal/models"
 	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/governance"
 )
 
 /************************************************************************************************
@@ -60,12 +62,18 @@ func (y Controller) GetVault(c *gin.Context) {
 
 	// Validate and process strategiesCondition
 	strategiesCondition := validateStrategyCondition(c, "strategiesCondition")
+	yieldFormat := validateYieldFormat(c, "yieldFormat")
+	displayPolicy := validateDisplayPolicy(c, "policy")
+	locale := NegotiateLocale(c)
+	setContentLanguage(c, locale)
 
 	// Get vault from storage
 	currentVault, ok := storage.GetVault(chainID, address)
 	if !ok {
-		handleError(c, fmt.Errorf("vault not found: %s on chain %d", address.Hex(), chainID),
-			http.StatusNotFound, "Vault not found", "GetVault")
+		handleVaultNotFound(c, chainID, address, "GetVault")
+		return
+	}
+	if !validateChainFreshness(c, chainID, "GetVault") {
 		return
 	}
 
@@ -122,6 +130,11 @@ func (y Controller) GetVault(c *gin.Context) {
 		if simplified.Description == "" {
 			simplified.Description = vaultAsStrategy.Description
 		}
+		simplified.Attestation = signVaultAttestation(simplified)
+		simplified.Governance = getVaultGovernance(newVault.ChainID, newVault.Address)
+		simplified.Display = computeVaultDisplay(simplified.ChainID, simplified.Address, simplified.APR, displayPolicy)
+		simplified.APR.applyYieldFormat(yieldFormat)
+		simplified.localize(locale)
 		c.JSON(http.StatusOK, simplified)
 		return
 	}
@@ -129,5 +142,42 @@ func (y Controller) GetVault(c *gin.Context) {
 	// Standard vault response
 	simplified := toSimplifiedVersion(newVault, models.TStrategy{})
 	simplified.Description = newVault.Description
+	simplified.Attestation = signVaultAttestation(simplified)
+	simplified.Governance = getVaultGovernance(newVault.ChainID, newVault.Address)
+	simplified.Display = computeVaultDisplay(simplified.ChainID, simplified.Address, simplified.APR, displayPolicy)
+	simplified.APR.applyYieldFormat(yieldFormat)
+	simplified.localize(locale)
 	c.JSON(http.StatusOK, simplified)
 }
+
+/************************************************************************************************
+** getVaultGovernance returns the role holders and the role changes of a v3 vault, nil for the
+** other vaults.
+************************************************************************************************/
+func getVaultGovernance(chainID uint64, vaultAddress string) *governance.TVaultGovernance {
+	vaultGovernance, ok := governance.GetVaultGovernance(chainID, common.HexToAddress(vaultAddress))
+	if !ok {
+		return nil
+	}
+	return &vaultGovernance
+}
+
+/************************************************************************************************
+** signVaultAttestation signs the APY and the price of a vault as of the last snapshot of its
+** chain, when an operator key is configured. The APY signed is the forward one when the vault
+** has one, the historical one otherwise, and is returned in the attestation. It returns nil when
+** the responses are not signed. The responses for a past block are never signed.
+************************************************************************************************/
+func signVaultAttestation(vault TSimplifiedExternalVault) *attestation.TAttestation {
+	if !attestation.IsEnabled() {
+		return nil
+	}
+	apy := vault.APR.NetAPR
+	if vault.APR.ForwardAPR.NetAPR != nil && vault.APR.ForwardAPR.Type != `` {
+		apy = vault.APR.ForwardAPR.NetAPR
+	}
+	address := common.HexToAddress(vault.Address)
+	price, _ := storage.GetPrice(vault.ChainID, address)
+	signed, _ := attestation.Sign(vault.ChainID, address, apy, price.Price, storage.GetChainSnapshotBlock(vault.ChainID), storage.GetChainLastSnapshot(vault.ChainID))
+	return signed
+}
@@ -2,15 +2,17 @@ package vaults
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
-	"github.com/yearn/ydaemon/common/bigNumber"
 	"github.com/yearn/ydaemon/internal/models"
 	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/apr"
 )
 
 /**************************************************************************************************
@@ -28,6 +30,10 @@ import (
 ** 5. Handling special cases where the vault is also registered as a strategy
 ** 6. Returning a simplified representation with essential vault information
 **
+** With the `block` query parameter, the forward APR of a v3 vault is recomputed at that block from
+** the APR oracle and the debt ratios of the strategies, on the archive node of the chain when
+** configured (ARCHIVE_RPC_URI_FOR_[chainID]). The rest of the vault is the current one.
+**
 ** Endpoint: GET /vaults/:chainID/:address/simplified
 **
 ** @param c *gin.Context - The Gin context containing the HTTP request
@@ -63,8 +69,38 @@ func (y Controller) GetSimplifiedVault(c *gin.Context) {
 	/** 🔵 - Yearn *************************************************************************************
 	** strategiesCondition: A string that determines the condition for selecting strategies. It is
 	** obtained from the 'strategiesCondition' query parameter in the request.
+	**
+	** yieldFormat: The optional format of the forward net yield (apr, apy or both). It is obtained
+	** from the 'yieldFormat' query parameter in the request.
+	**
+	** displayPolicy: The optional display policy picking the headline APY set as display.apy. It is
+	** obtained from the 'policy' query parameter in the request.
 	**************************************************************************************************/
 	strategiesCondition := validateStrategyCondition(c, "strategiesCondition")
+	yieldFormat := validateYieldFormat(c, "yieldFormat")
+	displayPolicy := validateDisplayPolicy(c, "policy")
+	locale := NegotiateLocale(c)
+	setContentLanguage(c, locale)
+
+	/** 🔵 - Yearn *************************************************************************************
+	** block: The optional past block at which the forward APR should be computed. It is obtained
+	** from the 'block' query parameter in the request.
+	**************************************************************************************************/
+	var historicalBlock uint64
+	if blockStr := getQueryParam(c, "block"); blockStr != "" {
+		block, err := strconv.ParseUint(blockStr, 10, 64)
+		if err != nil || block == 0 {
+			apiErr := NewAPIError(
+				ErrorTypeValidation,
+				ErrorCodeInvalidParam,
+				"Invalid block number",
+				fmt.Sprintf("The value '%s' is not a valid block number", blockStr),
+			).WithContext("GetSimplifiedVault")
+			handleError(c, apiErr, http.StatusBadRequest, "Invalid block number", "GetSimplifiedVault")
+			return
+		}
+		historicalBlock = block
+	}
 
 	/** 🔵 - Yearn *************************************************************************************
 	** The following block of code will store the final vault to be returned in the response, which will
@@ -72,8 +108,10 @@ func (y Controller) GetSimplifiedVault(c *gin.Context) {
 	**************************************************************************************************/
 	currentVault, ok := storage.GetVault(chainID, address)
 	if !ok {
-		handleError(c, fmt.Errorf("vault not found for chain %d and address %s", chainID, address.Hex()),
-			http.StatusNotFound, "Vault not found", "GetSimplifiedVault")
+		handleVaultNotFound(c, chainID, address, "GetSimplifiedVault")
+		return
+	}
+	if !validateChainFreshness(c, chainID, "GetSimplifiedVault") {
 		return
 	}
 
@@ -142,23 +180,9 @@ func (y Controller) GetSimplifiedVault(c *gin.Context) {
 			continue
 		}
 
-		strategyAddress := common.HexToAddress(strategyWithDetails.Address)
-		
-		for _, debt := range newVault.Debts {
-			if debt.Strategy == strategyAddress.Hex() {
-				if debt.CurrentDebt != nil {
-					strategyWithDetails.Details.TotalDebt = bigNumber.NewInt().SetString(*debt.CurrentDebt)
-				} else if debt.TotalDebt != nil {
-					strategyWithDetails.Details.TotalDebt = bigNumber.NewInt().SetString(*debt.TotalDebt)
-				} else {
-					strategyWithDetails.Details.TotalDebt = bigNumber.NewInt().SetString("0")
-				}
-				break
-			}
-		}
-
 		newVault.Strategies = append(newVault.Strategies, strategyWithDetails)
 	}
+	applyStrategiesDebt(newVault.Strategies, newVault.Debts)
 
 	// Special handling for vaults that are also registered as strategies
 	if vaultAsStrategy, ok := storage.GuessStrategy(newVault.ChainID, common.HexToAddress(newVault.Address)); ok {
@@ -167,12 +191,64 @@ func (y Controller) GetSimplifiedVault(c *gin.Context) {
 		if simplified.Description == "" {
 			simplified.Description = vaultAsStrategy.Description
 		}
+		if historicalBlock > 0 && !applyHistoricalForwardAPR(c, currentVault, historicalBlock, &simplified) {
+			return
+		}
+		if historicalBlock == 0 {
+			simplified.Attestation = signVaultAttestation(simplified)
+			simplified.Governance = getVaultGovernance(newVault.ChainID, newVault.Address)
+		}
+		simplified.Display = computeVaultDisplay(simplified.ChainID, simplified.Address, simplified.APR, displayPolicy)
+		simplified.APR.applyYieldFormat(yieldFormat)
+		simplified.localize(locale)
 		c.JSON(http.StatusOK, simplified)
 		return
 	}
 
 	simplified := toSimplifiedVersion(newVault, models.TStrategy{})
 	simplified.Description = newVault.Description
+	if historicalBlock > 0 && !applyHistoricalForwardAPR(c, currentVault, historicalBlock, &simplified) {
+		return
+	}
+	if historicalBlock == 0 {
+		simplified.Attestation = signVaultAttestation(simplified)
+		simplified.Governance = getVaultGovernance(newVault.ChainID, newVault.Address)
+	}
+	simplified.Display = computeVaultDisplay(simplified.ChainID, simplified.Address, simplified.APR, displayPolicy)
+	simplified.APR.applyYieldFormat(yieldFormat)
+	simplified.localize(locale)
 
 	c.JSON(http.StatusOK, simplified)
 }
+
+/**************************************************************************************************
+** applyHistoricalForwardAPR replaces the forward APR of the simplified vault with the one computed
+** at a past block. On failure, the error response is sent and false is returned.
+**************************************************************************************************/
+func applyHistoricalForwardAPR(c *gin.Context, vault models.TVault, block uint64, simplified *TSimplifiedExternalVault) bool {
+	historicalAPY, err := apr.ComputeHistoricalForwardAPY(vault, block)
+	if errors.Is(err, apr.ErrHistoricalAPYNotSupported) {
+		apiErr := NewAPIError(ErrorTypeValidation, ErrorCodeInvalidParam, "Historical APR not supported", err.Error()).WithContext("GetSimplifiedVault")
+		handleError(c, apiErr, http.StatusBadRequest, err.Error(), "GetSimplifiedVault")
+		return false
+	}
+	if err != nil {
+		apiErr := NewAPIError(ErrorTypeExternal, ErrorCodeExternalAPIFailed, "Historical APR unavailable", err.Error()).WithContext("GetSimplifiedVault")
+		handleError(c, apiErr, http.StatusBadGateway, "Failed to read the state at block "+strconv.FormatUint(block, 10), "GetSimplifiedVault")
+		return false
+	}
+
+	simplified.APR.ForwardAPR = TExternalForwardAPR{
+		Type:          historicalAPY.Type,
+		NetAPR:        historicalAPY.NetAPY,
+		PrimarySource: string(historicalAPY.PrimarySource),
+		Composite: TExternalCompositeData{
+			V3OracleCurrentAPR:    historicalAPY.Composite.V3OracleCurrentAPR,
+			V3OracleStratRatioAPR: historicalAPY.Composite.V3OracleStratRatioAPR,
+		},
+		BlockNumber:      &historicalAPY.BlockNumber,
+		simpleNetAPR:     historicalAPY.NetAPR,
+		compoundedNetAPY: historicalAPY.NetAPY,
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package vaults
+
+import (
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/external/utils"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** GetPartnerVaults retrieves the vaults approved by a partner, with the deposit contract and the
+** referral code of the partner for each of them.
+**
+** The views of the partners are set in data/meta/partners.json (see storage.LoadPartnerViews), so
+** a partner gets its own filtered list without running a filtering proxy. All the query parameters
+** of the vault lists (sorting, pagination, chainIDs, ...) are supported.
+**
+** Endpoint: GET /partners/:partner/vaults
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return []TSimplifiedExternalVault - The vaults of the partner view
+** @return error - Any error encountered during processing
+**************************************************************************************************/
+func (y Controller) GetPartnerVaults(c *gin.Context) ([]TSimplifiedExternalVault, error) {
+	partner := c.Param(`partner`)
+	view, ok := storage.GetPartnerView(partner)
+	if !ok {
+		apiErr := NewAPIError(ErrorTypeData, utils.ERROR_NOT_FOUND, "Partner not found", partner)
+		handleError(c, apiErr, http.StatusNotFound, "Partner not found", "GetPartnerVaults")
+		return nil, apiErr
+	}
+
+	vaults, err := getVaults(c, func(vault models.TVault) bool {
+		_, isApproved := view.GetPartnerFields(vault)
+		return isApproved
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, vault := range vaults {
+		internalVault, ok := storage.GetVault(vault.ChainID, common.HexToAddress(vault.Address))
+		if !ok {
+			continue
+		}
+		fields, _ := view.GetPartnerFields(internalVault)
+		vaults[i].Partner = &fields
+	}
+	return vaults, nil
+}
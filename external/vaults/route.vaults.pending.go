@@ -0,0 +1,49 @@
+package vaults
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/processes/mempool"
+)
+
+/**************************************************************************************************
+** TVaultPendingFlows lists the large deposits and withdrawals of a vault waiting in the mempool.
+** IsWatched is false when the mempool of the chain is not watched, the list being always empty.
+**************************************************************************************************/
+type TVaultPendingFlows struct {
+	ChainID      uint64                 `json:"chainID"`
+	Address      string                 `json:"address"`
+	IsWatched    bool                   `json:"isWatched"`
+	MinAmountUSD float64                `json:"minAmountUSD"`
+	Flows        []mempool.TPendingFlow `json:"flows"`
+}
+
+/**************************************************************************************************
+** GetVaultPendingFlows returns the pending deposits and withdrawals of a vault worth at least
+** MEMPOOL_MIN_FLOW_USD, the largest first, to warn of the TVL shifts before they are mined.
+**
+** Endpoint: GET /vaults/:chainID/:address/pending
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the pending flows of the vault
+**************************************************************************************************/
+func (y Controller) GetVaultPendingFlows(c *gin.Context) {
+	chainID, ok := validateChainID(c, "chainID")
+	if !ok {
+		return
+	}
+	address, ok := validateAddress(c, "address", chainID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, TVaultPendingFlows{
+		ChainID:      chainID,
+		Address:      address.Hex(),
+		IsWatched:    mempool.IsEnabled(chainID),
+		MinAmountUSD: env.MEMPOOL_MIN_FLOW_USD,
+		Flows:        mempool.ListPendingFlows(chainID, address),
+	})
+}
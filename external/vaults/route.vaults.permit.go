@@ -0,0 +1,353 @@
+package vaults
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** PERMIT2_ADDRESS is the address of the Uniswap Permit2 contract, the same on every chain it is
+** deployed on. PERMIT_DEFAULT_VALIDITY is the validity of the permits when no deadline is given.
+**************************************************************************************************/
+var PERMIT2_ADDRESS = common.HexToAddress(`0x000000000022D473030F116dDEE9F6B43aC78BA3`)
+
+const PERMIT_DEFAULT_VALIDITY = time.Hour
+
+var maxUint160 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 160), big.NewInt(1))
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+/**************************************************************************************************
+** TTypedDataField and TTypedData are EIP-712 typed data, in the format of eth_signTypedData_v4:
+** the frontends pass them as is to the wallet to sign.
+**************************************************************************************************/
+type TTypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type TTypedData struct {
+	Types       map[string][]TTypedDataField `json:"types"`
+	PrimaryType string                       `json:"primaryType"`
+	Domain      map[string]any               `json:"domain"`
+	Message     map[string]any               `json:"message"`
+}
+
+/**************************************************************************************************
+** TTokenPermitData holds the typed data to sign to approve a token to a spender without a
+** transaction: the EIP-2612 permit of the token when it supports it, and the Permit2 permit, which
+** needs a one-time approval of the token to Permit2 (IsPermit2Approved).
+**************************************************************************************************/
+type TTokenPermitData struct {
+	Token             string      `json:"token"`
+	Spender           string      `json:"spender"`
+	EIP2612           *TTypedData `json:"eip2612,omitempty"`
+	Permit2           *TTypedData `json:"permit2,omitempty"`
+	IsPermit2Approved bool        `json:"isPermit2Approved"`
+}
+
+/**************************************************************************************************
+** TVaultPermitData is the permit data of the underlying token (Asset) and of the share token
+** (Share) of a vault. Share is only set when a spender is given.
+**************************************************************************************************/
+type TVaultPermitData struct {
+	ChainID  uint64            `json:"chainID"`
+	Vault    string            `json:"vault"`
+	Owner    string            `json:"owner"`
+	Amount   string            `json:"amount"`
+	Deadline uint64            `json:"deadline"`
+	Asset    TTokenPermitData  `json:"asset"`
+	Share    *TTokenPermitData `json:"share,omitempty"`
+}
+
+/**************************************************************************************************
+** tPermitRequest is one token to build the permits of.
+**************************************************************************************************/
+type tPermitRequest struct {
+	token    common.Address
+	spender  common.Address
+	names    []string
+	versions []string
+}
+
+/**************************************************************************************************
+** GetVaultPermitData returns the EIP-712 typed data an owner signs to approve the underlying token
+** of a vault to the vault (or to the `spender`), and the share token of the vault to the `spender`,
+** with an EIP-2612 permit or with Permit2. The domain of the EIP-2612 permits is not hardcoded per
+** token: it is the candidate domain matching the DOMAIN_SEPARATOR of the token, so the typed data
+** is only returned when it can be verified.
+**
+** Query parameters:
+** - owner: the address signing the permits (required)
+** - amount: the raw amount to approve (defaults to the max)
+** - deadline: the unix timestamp the permits expire at (defaults to 1 hour from now)
+** - spender: the address allowed to spend the tokens (defaults to the vault for the underlying)
+**
+** Endpoint: GET /vaults/:chainID/:address/permit-data
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the permit data
+**************************************************************************************************/
+func (y Controller) GetVaultPermitData(c *gin.Context) {
+	chainID, ok := validateChainID(c, "chainID")
+	if !ok {
+		return
+	}
+	vaultAddress, ok := validateAddress(c, "address", chainID)
+	if !ok {
+		return
+	}
+	vault, ok := storage.GetVault(chainID, vaultAddress)
+	if !ok {
+		handleVaultNotFound(c, chainID, vaultAddress, "GetVaultPermitData")
+		return
+	}
+
+	ownerStr := getQueryParam(c, `owner`)
+	if !common.IsHexAddress(ownerStr) {
+		handlePermitParamError(c, `owner`, ownerStr)
+		return
+	}
+	owner := common.HexToAddress(ownerStr)
+
+	amount := new(big.Int).Set(maxUint256)
+	if amountStr := getQueryParam(c, `amount`); amountStr != `` {
+		value, ok := new(big.Int).SetString(amountStr, 10)
+		if !ok || value.Sign() < 0 || value.Cmp(maxUint256) > 0 {
+			handlePermitParamError(c, `amount`, amountStr)
+			return
+		}
+		amount = value
+	}
+
+	deadline := uint64(time.Now().Add(PERMIT_DEFAULT_VALIDITY).Unix())
+	if deadlineStr := getQueryParam(c, `deadline`); deadlineStr != `` {
+		value, err := strconv.ParseUint(deadlineStr, 10, 64)
+		if err != nil || value < uint64(time.Now().Unix()) {
+			handlePermitParamError(c, `deadline`, deadlineStr)
+			return
+		}
+		deadline = value
+	}
+
+	hasSpender := false
+	spender := vault.Address
+	if spenderStr := getQueryParam(c, `spender`); spenderStr != `` {
+		if !common.IsHexAddress(spenderStr) {
+			handlePermitParamError(c, `spender`, spenderStr)
+			return
+		}
+		spender = common.HexToAddress(spenderStr)
+		hasSpender = true
+	}
+
+	/**********************************************************************************************
+	** The candidate domains of the tokens: their ERC20 name, or the name used by the v2 vaults,
+	** and their version() if any, the API version of the vault, or the common versions.
+	**********************************************************************************************/
+	requests := []tPermitRequest{{token: vault.AssetAddress, spender: spender, versions: []string{`1`, `2`}}}
+	if asset, ok := storage.GetERC20(chainID, vault.AssetAddress); ok {
+		requests[0].names = []string{asset.Name}
+	}
+	if hasSpender {
+		share := tPermitRequest{token: vault.Address, spender: spender, names: []string{`Yearn Vault`}, versions: []string{vault.Version, `1`}}
+		if shareToken, ok := storage.GetERC20(chainID, vault.Address); ok {
+			share.names = append([]string{shareToken.Name}, share.names...)
+		}
+		requests = append(requests, share)
+	}
+
+	calls := []ethereum.Call{}
+	for _, request := range requests {
+		key := request.token.Hex()
+		calls = append(calls,
+			multicalls.GetDomainSeparator(key, request.token),
+			multicalls.GetPermitNonce(key, request.token, owner),
+			multicalls.GetPermitVersion(key, request.token),
+			multicalls.GetAllowance(key, request.token, owner, PERMIT2_ADDRESS),
+			multicalls.GetPermit2Allowance(key+`permit2`, PERMIT2_ADDRESS, owner, request.token, request.spender),
+		)
+	}
+	response := multicalls.Perform(chainID, calls, nil)
+	code, _ := ethereum.GetRPC(chainID).CodeAt(context.Background(), PERMIT2_ADDRESS, nil)
+	isPermit2Deployed := len(code) > 0
+
+	permit2Amount := amount
+	if amount.Cmp(maxUint160) > 0 {
+		permit2Amount = maxUint160
+	}
+	result := TVaultPermitData{
+		ChainID:  chainID,
+		Vault:    vault.Address.Hex(),
+		Owner:    owner.Hex(),
+		Amount:   amount.String(),
+		Deadline: deadline,
+	}
+	for i, request := range requests {
+		key := request.token.Hex()
+		permitData := TTokenPermitData{
+			Token:   request.token.Hex(),
+			Spender: request.spender.Hex(),
+		}
+
+		rawSeparator := response[key+`DOMAIN_SEPARATOR`]
+		rawNonce := response[key+`nonces`]
+		if len(rawSeparator) > 0 && len(rawNonce) > 0 {
+			separator, _ := rawSeparator[0].([32]byte)
+			versions := request.versions
+			if rawVersion := response[key+`version`]; len(rawVersion) > 0 {
+				versions = append([]string{helpers.DecodeString(rawVersion)}, versions...)
+			}
+			if domain, ok := matchPermitDomain(chainID, request.token, separator, request.names, versions); ok {
+				permitData.EIP2612 = buildEIP2612TypedData(domain, owner, request.spender, amount, bigNumber.ToInt(helpers.DecodeBigInt(rawNonce)), deadline)
+			}
+		}
+
+		if isPermit2Deployed {
+			nonce := big.NewInt(0)
+			if rawPermit2 := response[key+`permit2allowance`]; len(rawPermit2) == 3 {
+				if value, ok := rawPermit2[2].(*big.Int); ok {
+					nonce = value
+				}
+			}
+			permitData.Permit2 = buildPermit2TypedData(chainID, request.token, request.spender, permit2Amount, nonce, deadline)
+			if rawAllowance := response[key+`allowance`]; len(rawAllowance) > 0 {
+				permitData.IsPermit2Approved = bigNumber.ToInt(helpers.DecodeBigInt(rawAllowance)).Cmp(permit2Amount) >= 0
+			}
+		}
+
+		if i == 0 {
+			result.Asset = permitData
+		} else {
+			result.Share = &permitData
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+/**************************************************************************************************
+** matchPermitDomain returns the EIP-712 domain of a token whose separator is the one of the token,
+** among the candidate names and versions, with and without a version.
+**************************************************************************************************/
+func matchPermitDomain(chainID uint64, token common.Address, separator [32]byte, names []string, versions []string) (map[string]any, bool) {
+	for _, name := range names {
+		if name == `` {
+			continue
+		}
+		for _, version := range append(versions, ``) {
+			if hashPermitDomain(chainID, token, name, version) == common.Hash(separator) {
+				domain := map[string]any{`name`: name, `chainId`: chainID, `verifyingContract`: token.Hex()}
+				if version != `` {
+					domain[`version`] = version
+				}
+				return domain, true
+			}
+		}
+	}
+	return nil, false
+}
+
+/**************************************************************************************************
+** hashPermitDomain computes the EIP-712 domain separator of a token, without the version field
+** when the version is empty.
+**************************************************************************************************/
+func hashPermitDomain(chainID uint64, token common.Address, name string, version string) common.Hash {
+	chainIDBytes := common.LeftPadBytes(new(big.Int).SetUint64(chainID).Bytes(), 32)
+	tokenBytes := common.LeftPadBytes(token.Bytes(), 32)
+	if version == `` {
+		typeHash := crypto.Keccak256([]byte(`EIP712Domain(string name,uint256 chainId,address verifyingContract)`))
+		return crypto.Keccak256Hash(typeHash, crypto.Keccak256([]byte(name)), chainIDBytes, tokenBytes)
+	}
+	typeHash := crypto.Keccak256([]byte(`EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)`))
+	return crypto.Keccak256Hash(typeHash, crypto.Keccak256([]byte(name)), crypto.Keccak256([]byte(version)), chainIDBytes, tokenBytes)
+}
+
+func buildEIP2612TypedData(domain map[string]any, owner common.Address, spender common.Address, amount *big.Int, nonce *big.Int, deadline uint64) *TTypedData {
+	domainFields := []TTypedDataField{{Name: `name`, Type: `string`}}
+	if _, ok := domain[`version`]; ok {
+		domainFields = append(domainFields, TTypedDataField{Name: `version`, Type: `string`})
+	}
+	domainFields = append(domainFields, TTypedDataField{Name: `chainId`, Type: `uint256`}, TTypedDataField{Name: `verifyingContract`, Type: `address`})
+	return &TTypedData{
+		Types: map[string][]TTypedDataField{
+			`EIP712Domain`: domainFields,
+			`Permit`: {
+				{Name: `owner`, Type: `address`},
+				{Name: `spender`, Type: `address`},
+				{Name: `value`, Type: `uint256`},
+				{Name: `nonce`, Type: `uint256`},
+				{Name: `deadline`, Type: `uint256`},
+			},
+		},
+		PrimaryType: `Permit`,
+		Domain:      domain,
+		Message: map[string]any{
+			`owner`:    owner.Hex(),
+			`spender`:  spender.Hex(),
+			`value`:    amount.String(),
+			`nonce`:    nonce.String(),
+			`deadline`: strconv.FormatUint(deadline, 10),
+		},
+	}
+}
+
+/**************************************************************************************************
+** buildPermit2TypedData builds the PermitSingle of the AllowanceTransfer of Permit2, the amount
+** being an uint160. The allowance expires with the signature.
+**************************************************************************************************/
+func buildPermit2TypedData(chainID uint64, token common.Address, spender common.Address, amount *big.Int, nonce *big.Int, deadline uint64) *TTypedData {
+	return &TTypedData{
+		Types: map[string][]TTypedDataField{
+			`EIP712Domain`: {
+				{Name: `name`, Type: `string`},
+				{Name: `chainId`, Type: `uint256`},
+				{Name: `verifyingContract`, Type: `address`},
+			},
+			`PermitSingle`: {
+				{Name: `details`, Type: `PermitDetails`},
+				{Name: `spender`, Type: `address`},
+				{Name: `sigDeadline`, Type: `uint256`},
+			},
+			`PermitDetails`: {
+				{Name: `token`, Type: `address`},
+				{Name: `amount`, Type: `uint160`},
+				{Name: `expiration`, Type: `uint48`},
+				{Name: `nonce`, Type: `uint48`},
+			},
+		},
+		PrimaryType: `PermitSingle`,
+		Domain:      map[string]any{`name`: `Permit2`, `chainId`: chainID, `verifyingContract`: PERMIT2_ADDRESS.Hex()},
+		Message: map[string]any{
+			`details`: map[string]any{
+				`token`:      token.Hex(),
+				`amount`:     amount.String(),
+				`expiration`: strconv.FormatUint(deadline, 10),
+				`nonce`:      nonce.String(),
+			},
+			`spender`:     spender.Hex(),
+			`sigDeadline`: strconv.FormatUint(deadline, 10),
+		},
+	}
+}
+
+func handlePermitParamError(c *gin.Context, param string, value string) {
+	err := NewAPIError(
+		ErrorTypeValidation,
+		ErrorCodeInvalidParam,
+		"Invalid parameter",
+		fmt.Sprintf("The value '%s' is not a valid %s", value, param),
+	).WithContext("GetVaultPermitData")
+	handleError(c, err, http.StatusBadRequest, "Invalid parameter", "GetVaultPermitData")
+}
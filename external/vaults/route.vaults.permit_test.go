@@ -0,0 +1,174 @@
+package vaults
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+var usdcAddress = common.HexToAddress(`0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48`)
+
+/**************************************************************************************************
+** TestHashPermitDomain checks the domain separators against the one of USDC on mainnet and against
+** the EIP-712 implementation of go-ethereum, with and without a version.
+**************************************************************************************************/
+func TestHashPermitDomain(t *testing.T) {
+	usdcSeparator := common.HexToHash(`0x06c37168a7db5138defc7866392bb87a741f9b3d104deb5094588ce041cae335`)
+	assert.Equal(t, usdcSeparator, hashPermitDomain(1, usdcAddress, `USD Coin`, `2`))
+
+	testCases := []struct {
+		name    string
+		chainID uint64
+		token   common.Address
+		domain  string
+		version string
+	}{
+		{name: "With version", chainID: 1, token: usdcAddress, domain: `USD Coin`, version: `2`},
+		{name: "Without version", chainID: 10, token: common.HexToAddress(`0xA1`), domain: `Token`, version: ``},
+		{name: "Vault", chainID: 42161, token: common.HexToAddress(`0xA2`), domain: `Yearn Vault`, version: `0.4.6`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			typedData := apitypes.TypedData{
+				Types: apitypes.Types{`EIP712Domain`: {{Name: `name`, Type: `string`}}},
+				Domain: apitypes.TypedDataDomain{
+					Name:              tc.domain,
+					Version:           tc.version,
+					ChainId:           math.NewHexOrDecimal256(int64(tc.chainID)),
+					VerifyingContract: tc.token.Hex(),
+				},
+			}
+			if tc.version != `` {
+				typedData.Types[`EIP712Domain`] = append(typedData.Types[`EIP712Domain`], apitypes.Type{Name: `version`, Type: `string`})
+			}
+			typedData.Types[`EIP712Domain`] = append(typedData.Types[`EIP712Domain`],
+				apitypes.Type{Name: `chainId`, Type: `uint256`},
+				apitypes.Type{Name: `verifyingContract`, Type: `address`},
+			)
+			expected, err := typedData.HashStruct(`EIP712Domain`, typedData.Domain.Map())
+			assert.NoError(t, err)
+			assert.Equal(t, common.BytesToHash(expected), hashPermitDomain(tc.chainID, tc.token, tc.domain, tc.version))
+		})
+	}
+}
+
+/**************************************************************************************************
+** TestMatchPermitDomain checks that the domain returned is the candidate matching the separator of
+** the token, the version field being omitted when the token has none.
+**************************************************************************************************/
+func TestMatchPermitDomain(t *testing.T) {
+	token := common.HexToAddress(`0xA3`)
+	testCases := []struct {
+		name            string
+		separator       common.Hash
+		names           []string
+		versions        []string
+		expectedOK      bool
+		expectedName    string
+		expectedVersion string
+	}{
+		{name: "Name and version", separator: hashPermitDomain(1, token, `Token`, `2`), names: []string{`Token`}, versions: []string{`1`, `2`}, expectedOK: true, expectedName: `Token`, expectedVersion: `2`},
+		{name: "Second name", separator: hashPermitDomain(1, token, `Yearn Vault`, `0.4.6`), names: []string{`yvUSDC`, `Yearn Vault`}, versions: []string{`0.4.6`, `1`}, expectedOK: true, expectedName: `Yearn Vault`, expectedVersion: `0.4.6`},
+		{name: "Without version", separator: hashPermitDomain(1, token, `Token`, ``), names: []string{`Token`}, versions: []string{`1`}, expectedOK: true, expectedName: `Token`},
+		{name: "Empty name skipped", separator: hashPermitDomain(1, token, ``, `1`), names: []string{``}, versions: []string{`1`}},
+		{name: "Other chain", separator: hashPermitDomain(10, token, `Token`, `1`), names: []string{`Token`}, versions: []string{`1`}},
+		{name: "No candidate", separator: common.HexToHash(`0x01`), names: []string{`Token`}, versions: []string{`1`, `2`}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			domain, ok := matchPermitDomain(1, token, tc.separator, tc.names, tc.versions)
+			assert.Equal(t, tc.expectedOK, ok)
+			if !ok {
+				return
+			}
+			assert.Equal(t, tc.expectedName, domain[`name`])
+			assert.Equal(t, uint64(1), domain[`chainId`])
+			assert.Equal(t, token.Hex(), domain[`verifyingContract`])
+			version, hasVersion := domain[`version`]
+			assert.Equal(t, tc.expectedVersion != ``, hasVersion)
+			if hasVersion {
+				assert.Equal(t, tc.expectedVersion, version)
+			}
+		})
+	}
+}
+
+/**************************************************************************************************
+** TestPermitTypedDataIsValid checks that the EIP-2612 and Permit2 typed data can be hashed by an
+** EIP-712 implementation, as a wallet does, once passed through JSON.
+**************************************************************************************************/
+func TestPermitTypedDataIsValid(t *testing.T) {
+	owner := common.HexToAddress(`0xB1`)
+	spender := common.HexToAddress(`0xB2`)
+	deadline := uint64(1_800_000_000)
+	testCases := []struct {
+		name      string
+		typedData *TTypedData
+	}{
+		{name: "EIP-2612 with version", typedData: buildEIP2612TypedData(map[string]any{`name`: `USD Coin`, `version`: `2`, `chainId`: uint64(1), `verifyingContract`: usdcAddress.Hex()}, owner, spender, big.NewInt(1_000_000), big.NewInt(3), deadline)},
+		{name: "EIP-2612 without version", typedData: buildEIP2612TypedData(map[string]any{`name`: `Token`, `chainId`: uint64(1), `verifyingContract`: usdcAddress.Hex()}, owner, spender, maxUint256, big.NewInt(0), deadline)},
+		{name: "Permit2", typedData: buildPermit2TypedData(1, usdcAddress, spender, maxUint160, big.NewInt(7), deadline)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rawTypedData, err := json.Marshal(tc.typedData)
+			assert.NoError(t, err)
+			typedData := apitypes.TypedData{}
+			assert.NoError(t, json.Unmarshal(rawTypedData, &typedData))
+			_, _, err = apitypes.TypedDataAndHash(typedData)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+/**************************************************************************************************
+** TestGetVaultPermitDataValidation verifies that the invalid parameters are rejected before any
+** call to the node.
+**************************************************************************************************/
+func TestGetVaultPermitDataValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	controller := Controller{}
+	router.GET("/vaults/:chainID/:address/permit-data", controller.GetVaultPermitData)
+
+	vault := common.HexToAddress(`0xA4`)
+	storage.StoreVault(1, models.TVault{Address: vault, AssetAddress: usdcAddress, ChainID: 1})
+	owner := common.HexToAddress(`0xB1`).Hex()
+	past := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	testCases := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{name: "Invalid chain ID", path: "/vaults/invalid/" + vault.Hex() + "/permit-data?owner=" + owner, expectedStatus: http.StatusBadRequest},
+		{name: "Unknown vault", path: "/vaults/1/" + common.HexToAddress(`0xA5`).Hex() + "/permit-data?owner=" + owner, expectedStatus: http.StatusNotFound},
+		{name: "Missing owner", path: "/vaults/1/" + vault.Hex() + "/permit-data", expectedStatus: http.StatusBadRequest},
+		{name: "Invalid owner", path: "/vaults/1/" + vault.Hex() + "/permit-data?owner=0x123", expectedStatus: http.StatusBadRequest},
+		{name: "Invalid amount", path: "/vaults/1/" + vault.Hex() + "/permit-data?owner=" + owner + "&amount=abc", expectedStatus: http.StatusBadRequest},
+		{name: "Negative amount", path: "/vaults/1/" + vault.Hex() + "/permit-data?owner=" + owner + "&amount=-1", expectedStatus: http.StatusBadRequest},
+		{name: "Amount above uint256", path: "/vaults/1/" + vault.Hex() + "/permit-data?owner=" + owner + "&amount=" + new(big.Int).Add(maxUint256, big.NewInt(1)).String(), expectedStatus: http.StatusBadRequest},
+		{name: "Past deadline", path: "/vaults/1/" + vault.Hex() + "/permit-data?owner=" + owner + "&deadline=" + past, expectedStatus: http.StatusBadRequest},
+		{name: "Invalid spender", path: "/vaults/1/" + vault.Hex() + "/permit-data?owner=" + owner + "&spender=abc", expectedStatus: http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tc.path, nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tc.expectedStatus, w.Code)
+		})
+	}
+}
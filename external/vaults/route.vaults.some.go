@@ -45,6 +45,10 @@ func (y Controller) GetLegacySomeVaults(c *gin.Context) {
 	orderBy := helpers.SafeString(getQueryParam(c, `orderBy`), `featuringScore`)
 	orderDir := helpers.SafeString(getQueryParam(c, `orderDirection`), `asc`)
 	stratCon := validateStrategyCondition(c, "strategiesCondition")
+	yieldFormat := validateYieldFormat(c, "yieldFormat")
+	displayPolicy := validateDisplayPolicy(c, "policy")
+	locale := NegotiateLocale(c)
+	setContentLanguage(c, locale)
 
 	// Validate chain ID using the utility function
 	chainID, ok := validateChainID(c, `chainID`)
@@ -131,6 +135,9 @@ func (y Controller) GetLegacySomeVaults(c *gin.Context) {
 			newVault.Strategies = append(newVault.Strategies, strategyWithDetails)
 		}
 
+		newVault.Display = computeVaultDisplay(chainID, newVault.Address, newVault.APR, displayPolicy)
+		newVault.APR.applyYieldFormat(yieldFormat)
+		newVault.localize(locale)
 		data = append(data, newVault)
 	}
 
@@ -0,0 +1,85 @@
+package vaults
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/liquidity"
+)
+
+/**************************************************************************************************
+** TVaultWithdrawal is the liquidity of a v3 vault for the withdrawals and, when an amount is
+** given, the simulation of its withdrawal.
+**************************************************************************************************/
+type TVaultWithdrawal struct {
+	ChainID    uint64                           `json:"chainID"`
+	Address    string                           `json:"address"`
+	Liquidity  liquidity.TWithdrawalLiquidity   `json:"liquidity"`
+	Simulation *liquidity.TWithdrawalSimulation `json:"simulation,omitempty"`
+}
+
+/**************************************************************************************************
+** GetVaultWithdrawal returns the assets of a v3 vault withdrawable without exceeding its
+** liquidity, and the strategies of its default queue a withdrawal of the given amount would
+** unwind, for the frontends to warn before a withdrawal fails or takes a loss.
+**
+** Query parameters:
+** - amount: the raw amount of assets to withdraw (optional)
+**
+** Endpoint: GET /vaults/:chainID/:address/withdrawal
+**
+** @param c *gin.Context - The Gin context containing the HTTP request
+** @return void - Response is sent directly via Gin with the withdrawal liquidity of the vault
+**************************************************************************************************/
+func (y Controller) GetVaultWithdrawal(c *gin.Context) {
+	chainID, ok := validateChainID(c, "chainID")
+	if !ok {
+		return
+	}
+	vaultAddress, ok := validateAddress(c, "address", chainID)
+	if !ok {
+		return
+	}
+	vault, ok := storage.GetVault(chainID, vaultAddress)
+	if !ok {
+		handleVaultNotFound(c, chainID, vaultAddress, "GetVaultWithdrawal")
+		return
+	}
+	withdrawalLiquidity, ok := liquidity.GetWithdrawalLiquidity(vault)
+	if !ok {
+		err := NewAPIError(
+			ErrorTypeData,
+			ErrorCodeInvalidCondition,
+			"Withdrawal liquidity not available",
+			fmt.Sprintf("The liquidity of vault %s is only known for the v3 vaults", vaultAddress.Hex()),
+		).WithContext("GetVaultWithdrawal")
+		handleError(c, err, http.StatusBadRequest, "Withdrawal liquidity not available", "GetVaultWithdrawal")
+		return
+	}
+
+	response := TVaultWithdrawal{
+		ChainID:   chainID,
+		Address:   vaultAddress.Hex(),
+		Liquidity: withdrawalLiquidity,
+	}
+	if amountStr := getQueryParam(c, `amount`); amountStr != `` {
+		value, ok := new(big.Int).SetString(amountStr, 10)
+		if !ok || value.Sign() < 0 {
+			err := NewAPIError(
+				ErrorTypeValidation,
+				ErrorCodeInvalidParam,
+				"Invalid parameter",
+				fmt.Sprintf("The value '%s' is not a valid amount", amountStr),
+			).WithContext("GetVaultWithdrawal")
+			handleError(c, err, http.StatusBadRequest, "Invalid parameter", "GetVaultWithdrawal")
+			return
+		}
+		simulation := liquidity.SimulateWithdrawal(withdrawalLiquidity, bigNumber.SetInt(value))
+		response.Simulation = &simulation
+	}
+	c.JSON(http.StatusOK, response)
+}
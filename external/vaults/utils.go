@@ -7,11 +7,14 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
 	"github.com/yearn/ydaemon/common/env"
 	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/external/utils"
+	"github.com/yearn/ydaemon/internal/storage"
 )
 
 /************************************************************************************************
@@ -22,8 +25,9 @@ import (
 // ErrorType defines the category of an error for consistent handling
 type ErrorType string
 
-// ErrorCode provides a specific error code for detailed error identification
-type ErrorCode string
+// ErrorCode provides a specific error code for detailed error identification. The codes are shared
+// by all the routes of the API and returned to the clients in the error envelope.
+type ErrorCode = utils.TErrorCode
 
 // Defined error types
 const (
@@ -46,30 +50,32 @@ const (
 // Defined error codes for specific error scenarios
 const (
 	// Configuration errors
-	ErrorCodeChainNotSupported  ErrorCode = "chain_not_supported"
-	ErrorCodeChainConfigMissing ErrorCode = "chain_config_missing"
+	ErrorCodeChainNotSupported  = utils.ERROR_CHAIN_NOT_SUPPORTED
+	ErrorCodeChainConfigMissing = utils.ERROR_CHAIN_CONFIG_MISSING
 
 	// Validation errors
-	ErrorCodeMissingParam      ErrorCode = "missing_param"
-	ErrorCodeInvalidParam      ErrorCode = "invalid_param"
-	ErrorCodeInvalidAddress    ErrorCode = "invalid_address"
-	ErrorCodeInvalidFormat     ErrorCode = "invalid_format"
-	ErrorCodeInvalidCondition  ErrorCode = "invalid_condition"
-	ErrorCodeIncompatibleParam ErrorCode = "incompatible_param"
+	ErrorCodeMissingParam      = utils.ERROR_MISSING_PARAM
+	ErrorCodeInvalidParam      = utils.ERROR_INVALID_PARAM
+	ErrorCodeInvalidAddress    = utils.ERROR_INVALID_ADDRESS
+	ErrorCodeInvalidFormat     = utils.ERROR_INVALID_FORMAT
+	ErrorCodeInvalidCondition  = utils.ERROR_INVALID_CONDITION
+	ErrorCodeIncompatibleParam = utils.ERROR_INCOMPATIBLE_PARAM
 
 	// Data errors
-	ErrorCodeVaultNotFound    ErrorCode = "vault_not_found"
-	ErrorCodeStrategyNotFound ErrorCode = "strategy_not_found"
-	ErrorCodeTokenNotFound    ErrorCode = "token_not_found"
-	ErrorCodePriceDataMissing ErrorCode = "price_data_missing"
+	ErrorCodeVaultNotFound    = utils.ERROR_VAULT_NOT_FOUND
+	ErrorCodeVaultNotIndexed  = utils.ERROR_VAULT_NOT_INDEXED
+	ErrorCodeStrategyNotFound = utils.ERROR_STRATEGY_NOT_FOUND
+	ErrorCodeTokenNotFound    = utils.ERROR_TOKEN_NOT_FOUND
+	ErrorCodePriceDataMissing = utils.ERROR_PRICE_DATA_MISSING
+	ErrorCodeDataStale        = utils.ERROR_DATA_STALE
 
 	// Processing errors
-	ErrorCodeTimeout          ErrorCode = "timeout"
-	ErrorCodeProcessingFailed ErrorCode = "processing_failed"
+	ErrorCodeTimeout          = utils.ERROR_TIMEOUT
+	ErrorCodeProcessingFailed = utils.ERROR_PROCESSING_FAILED
 
 	// External errors
-	ErrorCodeGraphQLFailed     ErrorCode = "graphql_failed"
-	ErrorCodeExternalAPIFailed ErrorCode = "external_api_failed"
+	ErrorCodeGraphQLFailed     = utils.ERROR_GRAPHQL_FAILED
+	ErrorCodeExternalAPIFailed = utils.ERROR_EXTERNAL_API_FAILED
 )
 
 // APIError represents a structured error with type, code, and context
@@ -358,12 +364,62 @@ func handleError(c *gin.Context, err error, statusCode int, userMessage string,
 		return
 	}
 
-	// Return appropriate response based on what the client would expect
-	if strings.Contains(c.GetHeader("Accept"), "application/json") {
-		c.JSON(statusCode, apiErr)
-	} else {
-		c.String(statusCode, fmt.Sprintf("%s: %v", apiErr.Message, apiErr.Details))
+	// Return the error envelope shared by all the routes
+	message := apiErr.Message
+	if apiErr.Details != "" {
+		message = fmt.Sprintf("%s: %s", apiErr.Message, apiErr.Details)
+	}
+	utils.SendErrorWithStatus(c, statusCode, utils.NewError(apiErr.Code, message))
+}
+
+/************************************************************************************************
+** handleVaultNotFound sends the error for a vault missing from the storage. A vault already
+** detected in a registry but not processed yet is reported as not indexed, and the client can
+** retry later. Any other address is reported as not found.
+**
+** @param c *gin.Context - The Gin context for the request
+** @param chainID uint64 - The chain ID of the vault
+** @param address common.Address - The address of the vault
+** @param fnContext string - Function or operation context (e.g., function name)
+************************************************************************************************/
+func handleVaultNotFound(c *gin.Context, chainID uint64, address common.Address, fnContext string) {
+	if _, ok := storage.GetVaultFromRegistry(chainID, address); ok {
+		handleError(c, NewAPIError(
+			ErrorTypeData,
+			ErrorCodeVaultNotIndexed,
+			"Vault not indexed yet",
+			fmt.Sprintf("vault %s on chain %d is known but not indexed yet", address.Hex(), chainID),
+		), http.StatusNotFound, "Vault not indexed yet", fnContext)
+		return
+	}
+	handleError(c, NewAPIError(
+		ErrorTypeData,
+		ErrorCodeVaultNotFound,
+		"Vault not found",
+		fmt.Sprintf("vault %s not found on chain %d", address.Hex(), chainID),
+	), http.StatusNotFound, "Vault not found", fnContext)
+}
+
+/************************************************************************************************
+** validateChainFreshness checks that the data of the chain is not stale, and sends a retryable
+** error if it is, instead of silently serving outdated APYs and TVLs.
+**
+** @param c *gin.Context - The Gin context for the request
+** @param chainID uint64 - The chain ID to check
+** @param fnContext string - Function or operation context (e.g., function name)
+** @return bool - True if the data is fresh, false if it is stale (and response was sent)
+************************************************************************************************/
+func validateChainFreshness(c *gin.Context, chainID uint64, fnContext string) bool {
+	if !utils.IsChainDataStale(chainID) {
+		return true
 	}
+	handleError(c, NewAPIError(
+		ErrorTypeData,
+		ErrorCodeDataStale,
+		"Data stale",
+		fmt.Sprintf("chain %d was not refreshed since %s", chainID, storage.GetChainLastSnapshot(chainID).Format(time.RFC3339)),
+	), http.StatusServiceUnavailable, "Data stale", fnContext)
+	return false
 }
 
 // inferErrorType determines the likely error type based on the HTTP status code
@@ -388,8 +444,8 @@ func inferErrorCode(statusCode int) ErrorCode {
 	case http.StatusBadRequest:
 		return ErrorCodeInvalidParam
 	case http.StatusNotFound:
-		return ErrorCodeVaultNotFound
-	case http.StatusRequestTimeout:
+		return utils.ERROR_NOT_FOUND
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
 		return ErrorCodeTimeout
 	case http.StatusInternalServerError:
 		return ErrorCodeProcessingFailed
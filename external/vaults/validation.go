@@ -54,6 +54,17 @@ const (
 	MIGRABLE_CONDITION_IGNORE  = "ignore"
 )
 
+/************************************************************************************************
+** YieldFormat constants define the formats of the forward net yield of the vaults, set with the
+** yieldFormat query parameter. Without it, the legacy netAPR field is returned unchanged.
+************************************************************************************************/
+const (
+	YIELD_FORMAT_LEGACY = ""
+	YIELD_FORMAT_APR    = "apr"
+	YIELD_FORMAT_APY    = "apy"
+	YIELD_FORMAT_BOTH   = "both"
+)
+
 /************************************************************************************************
 ** Common constants used across the vaults package.
 ** These include timeouts, default values, and array sizes.
@@ -154,6 +165,88 @@ func validateMigrableCondition(c *gin.Context, paramName string) string {
 	return MIGRABLE_CONDITION_NONE
 }
 
+/************************************************************************************************
+** validateYieldFormat validates the yield format parameter and returns the appropriate value to
+** use.
+**
+** @param c *gin.Context - The Gin context containing the request
+** @param paramName string - The name of the query parameter to validate
+** @return string - The validated yield format or the legacy format by default
+************************************************************************************************/
+func validateYieldFormat(c *gin.Context, paramName string) string {
+	return validateStringChoiceQuery(c, paramName, YIELD_FORMAT_LEGACY,
+		[]string{YIELD_FORMAT_APR, YIELD_FORMAT_APY, YIELD_FORMAT_BOTH}, "validateYieldFormat")
+}
+
+/************************************************************************************************
+** validateDisplayPolicy validates the display policy parameter and returns the appropriate value
+** to use.
+**
+** @param c *gin.Context - The Gin context containing the request
+** @param paramName string - The name of the query parameter to validate
+** @return string - The validated policy, or none by default
+************************************************************************************************/
+func validateDisplayPolicy(c *gin.Context, paramName string) string {
+	return validateStringChoiceQuery(c, paramName, DISPLAY_POLICY_NONE, listDisplayPolicies(), "validateDisplayPolicy")
+}
+
+/************************************************************************************************
+** validateStagesParam validates the comma-separated list of lifecycle stages used to filter the
+** vaults. Unknown stages are ignored. An empty list means no filtering.
+**
+** @param c *gin.Context - The Gin context containing the request
+** @param paramName string - The name of the query parameter to validate
+** @return []models.TVaultStage - The list of valid stages to keep
+************************************************************************************************/
+func validateStagesParam(c *gin.Context, paramName string) []models.TVaultStage {
+	stagesParam := getQueryParam(c, paramName)
+	if stagesParam == "" {
+		return nil
+	}
+
+	validStages := map[models.TVaultStage]bool{
+		models.VaultStageExperimental: true,
+		models.VaultStageEndorsed:     true,
+		models.VaultStageDeprecated:   true,
+		models.VaultStageRetired:      true,
+	}
+
+	stages := []models.TVaultStage{}
+	for _, stage := range strings.Split(stagesParam, ",") {
+		stage := models.TVaultStage(strings.ToLower(strings.TrimSpace(stage)))
+		if !validStages[stage] {
+			c.Error(fmt.Errorf("invalid stage: %s, ignoring", stage))
+			continue
+		}
+		stages = append(stages, stage)
+	}
+	return stages
+}
+
+/************************************************************************************************
+** validateProtocolsParam extracts the comma-separated list of protocols to filter on, e.g.
+** `protocols=Convex,Aura`. The protocols are matched case-insensitively against the labels of the
+** vaults and of their strategies.
+**
+** @param c *gin.Context - The Gin context containing the request
+** @param paramName string - The name of the query parameter to validate
+** @return []string - The protocols to keep, nil if the filter is not set
+************************************************************************************************/
+func validateProtocolsParam(c *gin.Context, paramName string) []string {
+	protocolsParam := getQueryParam(c, paramName)
+	if protocolsParam == "" {
+		return nil
+	}
+
+	protocols := []string{}
+	for _, protocol := range strings.Split(protocolsParam, ",") {
+		if protocol = strings.TrimSpace(protocol); protocol != "" {
+			protocols = append(protocols, protocol)
+		}
+	}
+	return protocols
+}
+
 /************************************************************************************************
 ** ProcessStrategiesForVault processes and filters strategies for a vault based on the
 ** specified condition.
@@ -0,0 +1,313 @@
+package backfill
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The backfills read the history of the chains: the first scan of the events of the vaults since
+** their activation, or the inception of the vaults from the archive node. Left alone, a backfill
+** sends its requests as fast as the RPC answers, starving the live refresh of the chain. The
+** controller runs at most BACKFILL_CONCURRENCY backfill requests at the same time on a chain, all
+** backfills included, the vaults with the highest TVL first. The backfills of a chain can be
+** paused by the operators, a paused backfill stopping before its next request and resuming from
+** where it stopped on the refresh following the resume. The live refreshes are never throttled.
+**************************************************************************************************/
+const (
+	STATUS_RUNNING     = `running`
+	STATUS_PAUSED      = `paused`
+	STATUS_DONE        = `done`
+	STATUS_INTERRUPTED = `interrupted`
+)
+
+/**************************************************************************************************
+** TProgress is the progress of the last run of a backfill of a chain, in requests for the event
+** scans and in vaults for the per-vault backfills. Completion is the share done, from 0 to 1.
+**************************************************************************************************/
+type TProgress struct {
+	ChainID     uint64     `json:"chainID"`
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	Total       int        `json:"total"`
+	Done        int        `json:"done"`
+	Failed      int        `json:"failed"`
+	Completion  float64    `json:"completion"`
+	StartedAt   time.Time  `json:"startedAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+/**************************************************************************************************
+** TTask is a backfill of one item, run by priority: the highest first.
+**************************************************************************************************/
+type TTask struct {
+	Priority float64
+	Run      func() error
+}
+
+/**************************************************************************************************
+** TTracker follows a run of a backfill. A tracker of a live refresh, scanning a single chunk, is
+** neither throttled nor reported.
+**************************************************************************************************/
+type TTracker struct {
+	chainID uint64
+	name    string
+	isLive  bool
+}
+
+var (
+	slots       = make(map[uint64]chan struct{})
+	pausedChain = make(map[uint64]bool)
+	progress    = make(map[uint64]map[string]*TProgress)
+	backfillMtx sync.RWMutex
+)
+
+/**************************************************************************************************
+** Pause and Resume pause and resume the backfills of a chain. They return false when the chain
+** was already in that state.
+**************************************************************************************************/
+func Pause(chainID uint64) bool {
+	backfillMtx.Lock()
+	defer backfillMtx.Unlock()
+	if pausedChain[chainID] {
+		return false
+	}
+	pausedChain[chainID] = true
+	return true
+}
+
+func Resume(chainID uint64) bool {
+	backfillMtx.Lock()
+	defer backfillMtx.Unlock()
+	if !pausedChain[chainID] {
+		return false
+	}
+	delete(pausedChain, chainID)
+	return true
+}
+
+/**************************************************************************************************
+** IsPaused returns true when the backfills of a chain are paused.
+**************************************************************************************************/
+func IsPaused(chainID uint64) bool {
+	backfillMtx.RLock()
+	defer backfillMtx.RUnlock()
+	return pausedChain[chainID]
+}
+
+func getSlots(chainID uint64) chan struct{} {
+	backfillMtx.Lock()
+	defer backfillMtx.Unlock()
+	if _, ok := slots[chainID]; !ok {
+		slots[chainID] = make(chan struct{}, env.BACKFILL_CONCURRENCY)
+	}
+	return slots[chainID]
+}
+
+/**************************************************************************************************
+** Start registers a run of a backfill of a chain, of total requests or items.
+**************************************************************************************************/
+func Start(chainID uint64, name string, total int) *TTracker {
+	now := time.Now()
+	backfillMtx.Lock()
+	defer backfillMtx.Unlock()
+	if _, ok := progress[chainID]; !ok {
+		progress[chainID] = make(map[string]*TProgress)
+	}
+	progress[chainID][name] = &TProgress{
+		ChainID:   chainID,
+		Name:      name,
+		Status:    STATUS_RUNNING,
+		Total:     total,
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+	return &TTracker{chainID: chainID, name: name}
+}
+
+/**************************************************************************************************
+** StartScan returns the tracker of a scan of the events of a chain from start to end, by chunks
+** of logsRange blocks. A scan of a single chunk is a live refresh.
+**************************************************************************************************/
+func StartScan(chainID uint64, name string, start uint64, end uint64, logsRange uint64) *TTracker {
+	if logsRange == 0 || end < start || (end-start)/logsRange == 0 {
+		return &TTracker{chainID: chainID, name: name, isLive: true}
+	}
+	return Start(chainID, name, int((end-start)/logsRange)+1)
+}
+
+/**************************************************************************************************
+** Acquire waits for a backfill slot of the chain to be free, and takes it. It returns false,
+** without a slot, once the backfills of the chain are paused.
+**************************************************************************************************/
+func (t *TTracker) Acquire() bool {
+	if t.isLive {
+		return true
+	}
+	chainSlots := getSlots(t.chainID)
+	for {
+		if IsPaused(t.chainID) {
+			t.update(func(p *TProgress) { p.Status = STATUS_PAUSED })
+			return false
+		}
+		select {
+		case chainSlots <- struct{}{}:
+			return true
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+/**************************************************************************************************
+** Release frees the slot taken by Acquire, counting the request as done or failed.
+**************************************************************************************************/
+func (t *TTracker) Release(err error) {
+	if t.isLive {
+		return
+	}
+	<-getSlots(t.chainID)
+	t.update(func(p *TProgress) {
+		if err != nil {
+			p.Failed++
+			return
+		}
+		p.Done++
+	})
+}
+
+/**************************************************************************************************
+** Finish ends the run: done when every request was sent, interrupted when it stopped early for
+** another reason than a pause.
+**************************************************************************************************/
+func (t *TTracker) Finish() {
+	if t.isLive {
+		return
+	}
+	t.update(func(p *TProgress) {
+		switch {
+		case p.Done+p.Failed >= p.Total:
+			now := time.Now()
+			p.Status = STATUS_DONE
+			p.CompletedAt = &now
+		case p.Status != STATUS_PAUSED:
+			p.Status = STATUS_INTERRUPTED
+		}
+	})
+}
+
+func (t *TTracker) update(apply func(p *TProgress)) {
+	backfillMtx.Lock()
+	defer backfillMtx.Unlock()
+	p, ok := progress[t.chainID][t.name]
+	if !ok {
+		return
+	}
+	apply(p)
+	p.UpdatedAt = time.Now()
+	if p.Total > 0 {
+		p.Completion = float64(p.Done) / float64(p.Total)
+	}
+}
+
+/**************************************************************************************************
+** sortTasks orders the tasks by priority, the highest first.
+**************************************************************************************************/
+func sortTasks(tasks []TTask) []TTask {
+	sorted := append([]TTask{}, tasks...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+	return sorted
+}
+
+/**************************************************************************************************
+** Run runs the tasks of a backfill of a chain by priority, on the backfill slots of the chain. It
+** stops starting tasks once the backfills of the chain are paused, and returns when the started
+** ones are over.
+**************************************************************************************************/
+func Run(chainID uint64, name string, tasks []TTask) {
+	tracker := Start(chainID, name, len(tasks))
+	defer tracker.Finish()
+
+	wg := sync.WaitGroup{}
+	for _, task := range sortTasks(tasks) {
+		if !tracker.Acquire() {
+			break
+		}
+		wg.Add(1)
+		go func(task TTask) {
+			defer wg.Done()
+			tracker.Release(task.Run())
+		}(task)
+	}
+	wg.Wait()
+}
+
+/**************************************************************************************************
+** GetVaultPriority returns the priority of the backfill of a vault: its TVL, as known by Kong.
+**************************************************************************************************/
+func GetVaultPriority(vault models.TVault) float64 {
+	tvl, _ := storage.GetKongTVL(vault.ChainID, vault.Address)
+	return tvl
+}
+
+/**************************************************************************************************
+** SortVaultsByPriority returns the vaults with the highest TVL first.
+**************************************************************************************************/
+func SortVaultsByPriority(vaults []models.TVault) []models.TVault {
+	priorities := make(map[int]float64, len(vaults))
+	indexes := make([]int, len(vaults))
+	for i, vault := range vaults {
+		priorities[i] = GetVaultPriority(vault)
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return priorities[indexes[i]] > priorities[indexes[j]]
+	})
+	sorted := make([]models.TVault, len(vaults))
+	for i, index := range indexes {
+		sorted[i] = vaults[index]
+	}
+	return sorted
+}
+
+/**************************************************************************************************
+** ListProgress returns the progress of the last run of every backfill, by chain and name.
+**************************************************************************************************/
+func ListProgress() []TProgress {
+	backfillMtx.RLock()
+	defer backfillMtx.RUnlock()
+	list := []TProgress{}
+	for _, chainProgress := range progress {
+		for _, p := range chainProgress {
+			list = append(list, *p)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].ChainID != list[j].ChainID {
+			return list[i].ChainID < list[j].ChainID
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+/**************************************************************************************************
+** ListPausedChains returns the chains whose backfills are paused, sorted.
+**************************************************************************************************/
+func ListPausedChains() []uint64 {
+	backfillMtx.RLock()
+	defer backfillMtx.RUnlock()
+	chainIDs := []uint64{}
+	for chainID := range pausedChain {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Slice(chainIDs, func(i, j int) bool { return chainIDs[i] < chainIDs[j] })
+	return chainIDs
+}
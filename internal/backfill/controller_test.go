@@ -0,0 +1,56 @@
+package backfill
+
+import (
+	"testing"
+)
+
+/**************************************************************************************************
+** TestStartScan checks that only the scans of more than one chunk are throttled backfills.
+**************************************************************************************************/
+func TestStartScan(t *testing.T) {
+	if scan := StartScan(1, `live`, 100, 150, 100); !scan.isLive {
+		t.Error("expected a scan of a single chunk to be live")
+	}
+	scan := StartScan(1, `history`, 100, 1_000, 100)
+	if scan.isLive {
+		t.Fatal("expected a scan of several chunks to be a backfill")
+	}
+	if p := progress[1][`history`]; p.Total != 10 || p.Status != STATUS_RUNNING {
+		t.Errorf("expected a running backfill of 10 chunks, got %+v", p)
+	}
+}
+
+/**************************************************************************************************
+** TestRun checks that the tasks run by priority, and that none starts once the chain is paused.
+**************************************************************************************************/
+func TestRun(t *testing.T) {
+	chainID := uint64(10)
+	slots[chainID] = make(chan struct{}, 1) // One task at a time, in order
+	order := []float64{}
+	tasks := []TTask{}
+	for _, priority := range []float64{5, 50, 0.5} {
+		priority := priority
+		tasks = append(tasks, TTask{Priority: priority, Run: func() error {
+			order = append(order, priority)
+			return nil
+		}})
+	}
+	Run(chainID, `inception`, tasks)
+	if len(order) != 3 || order[0] != 50 || order[1] != 5 || order[2] != 0.5 {
+		t.Errorf("expected the tasks to run by priority, got %v", order)
+	}
+	if p := progress[chainID][`inception`]; p.Status != STATUS_DONE || p.Done != 3 || p.Completion != 1 {
+		t.Errorf("expected a completed backfill, got %+v", p)
+	}
+
+	Pause(chainID)
+	defer Resume(chainID)
+	order = []float64{}
+	Run(chainID, `inception`, tasks)
+	if len(order) != 0 {
+		t.Errorf("expected no task to run while paused, got %v", order)
+	}
+	if p := progress[chainID][`inception`]; p.Status != STATUS_PAUSED {
+		t.Errorf("expected a paused backfill, got %+v", p)
+	}
+}
@@ -0,0 +1,226 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The snapshot of a chain reads its inputs from the chain and from Kong every time: the vaults
+** (with their fees), the strategies (with their last harvest) and the prices. The stages derived
+** from them only run again when one of the artifacts they depend on changed since their last run,
+** or when their result is older than MaxAge, for the data they read from elsewhere (the APR
+** oracle, the lending markets, the gauges, the staking pools, ...) to stay fresh:
+** - price change -> tvl -> apr (the USD components of the APY)
+** - harvest (strategies) -> apr
+** - fee change (vaults) -> apr, which records the fee history
+**************************************************************************************************/
+type TDerivedStage struct {
+	DependsOn []string
+	MaxAge    time.Duration
+}
+
+var HYDRATION_DEPENDENCIES = map[string]TDerivedStage{
+	`tvl`:        {DependsOn: []string{`vaults`, `prices`}, MaxAge: time.Hour},
+	`apr`:        {DependsOn: []string{`vaults`, `strategies`, `prices`, `tvl`}, MaxAge: time.Hour},
+	`migrations`: {DependsOn: []string{`vaults`, `apr`}, MaxAge: 2 * time.Hour},
+	`protocols`:  {DependsOn: []string{`strategies`}, MaxAge: 6 * time.Hour},
+	`sharePrice`: {DependsOn: []string{`vaults`}, MaxAge: 2 * time.Hour},
+}
+
+/**************************************************************************************************
+** The prices move a bit on every snapshot. They are only considered changed when one of them moved
+** by more than PRICE_CHANGE_TOLERANCE since the last change, or when a token got a price.
+**************************************************************************************************/
+const PRICE_CHANGE_TOLERANCE = 0.02
+
+/**************************************************************************************************
+** Each artifact of a chain has a generation, bumped every time it changes: when the fingerprint
+** of an input changes, and every time a derived stage runs. A derived stage remembers the
+** generations of its dependencies at its last run.
+**************************************************************************************************/
+type tStageState struct {
+	seen    map[string]uint64
+	lastRun time.Time
+}
+
+var (
+	artifactGenerations = make(map[uint64]map[string]uint64)
+	inputFingerprints   = make(map[uint64]map[string]string)
+	referencePrices     = make(map[uint64]map[common.Address]float64)
+	stageStates         = make(map[uint64]map[string]*tStageState)
+	dependenciesMtx     sync.Mutex
+)
+
+func bumpGeneration(chainID uint64, artifact string) {
+	if _, ok := artifactGenerations[chainID]; !ok {
+		artifactGenerations[chainID] = make(map[string]uint64)
+	}
+	artifactGenerations[chainID][artifact]++
+}
+
+/**************************************************************************************************
+** recordInput bumps the generation of an input of a chain when its fingerprint changed since the
+** last snapshot. It returns whether it changed.
+**************************************************************************************************/
+func recordInput(chainID uint64, input string, fingerprint string) bool {
+	dependenciesMtx.Lock()
+	defer dependenciesMtx.Unlock()
+	if _, ok := inputFingerprints[chainID]; !ok {
+		inputFingerprints[chainID] = make(map[string]string)
+	}
+	if previous, ok := inputFingerprints[chainID][input]; ok && previous == fingerprint {
+		return false
+	}
+	inputFingerprints[chainID][input] = fingerprint
+	bumpGeneration(chainID, input)
+	return true
+}
+
+/**************************************************************************************************
+** recordPricesInput bumps the generation of the prices of a chain when a price moved by more than
+** PRICE_CHANGE_TOLERANCE since the last change, or when a new token got a price. The reference
+** prices are then reset to the current ones.
+**************************************************************************************************/
+func recordPricesInput(chainID uint64) bool {
+	current := make(map[common.Address]float64)
+	allPrices, _ := storage.ListPrices(chainID)
+	for address, price := range allPrices {
+		if price.HumanizedPrice != nil {
+			current[address], _ = price.HumanizedPrice.Float64()
+		}
+	}
+
+	dependenciesMtx.Lock()
+	defer dependenciesMtx.Unlock()
+	references, hasReferences := referencePrices[chainID]
+	hasChanged := !hasReferences
+	for address, price := range current {
+		reference, ok := references[address]
+		if !ok || (reference == 0 && price != 0) || (reference != 0 && math.Abs(price-reference)/reference > PRICE_CHANGE_TOLERANCE) {
+			hasChanged = true
+			break
+		}
+	}
+	if hasChanged {
+		referencePrices[chainID] = current
+		bumpGeneration(chainID, `prices`)
+	}
+	return hasChanged
+}
+
+/**************************************************************************************************
+** shouldRecompute tells whether a derived stage of a chain must run, with the reason why: its
+** first run, a changed dependency, or a result older than its MaxAge.
+**************************************************************************************************/
+func shouldRecompute(chainID uint64, stage string) (bool, string) {
+	dependencies, ok := HYDRATION_DEPENDENCIES[stage]
+	if !ok {
+		return true, `not a derived stage`
+	}
+	dependenciesMtx.Lock()
+	defer dependenciesMtx.Unlock()
+	state, ok := stageStates[chainID][stage]
+	if !ok {
+		return true, `first run`
+	}
+	for _, dependency := range dependencies.DependsOn {
+		if artifactGenerations[chainID][dependency] != state.seen[dependency] {
+			return true, dependency + ` changed`
+		}
+	}
+	if time.Since(state.lastRun) > dependencies.MaxAge {
+		return true, `older than ` + dependencies.MaxAge.String()
+	}
+	return false, ``
+}
+
+/**************************************************************************************************
+** markRecomputed records the generations of the dependencies a derived stage of a chain ran with,
+** and bumps the generation of its own artifact for the stages depending on it.
+**************************************************************************************************/
+func markRecomputed(chainID uint64, stage string) {
+	dependenciesMtx.Lock()
+	defer dependenciesMtx.Unlock()
+	state := &tStageState{seen: make(map[string]uint64), lastRun: time.Now()}
+	for _, dependency := range HYDRATION_DEPENDENCIES[stage].DependsOn {
+		state.seen[dependency] = artifactGenerations[chainID][dependency]
+	}
+	if _, ok := stageStates[chainID]; !ok {
+		stageStates[chainID] = make(map[string]*tStageState)
+	}
+	stageStates[chainID][stage] = state
+	bumpGeneration(chainID, stage)
+}
+
+/**************************************************************************************************
+** traceDerivedStage runs a derived stage like traceStage, unless none of its dependencies changed
+** and its result is recent enough. A skipped stage is still up to date with the chain, so the block
+** of the snapshot is recorded for its freshness either way.
+**************************************************************************************************/
+func traceDerivedStage(ctx context.Context, chainID uint64, stage string, run func(ctx context.Context)) {
+	traceStage(ctx, chainID, stage, func(ctx context.Context) {
+		mustRun, reason := shouldRecompute(chainID, stage)
+		if !mustRun {
+			logs.Info(fmt.Sprintf("♻️ [DEPENDENCIES] %s skipped chain=%d: nothing changed", stage, chainID))
+			return
+		}
+		logs.Info(fmt.Sprintf("♻️ [DEPENDENCIES] %s recomputed chain=%d: %s", stage, chainID, reason))
+		run(ctx)
+		markRecomputed(chainID, stage)
+	})
+}
+
+/**************************************************************************************************
+** hashFingerprints hashes the fingerprints of the elements of an input, in a deterministic order.
+**************************************************************************************************/
+func hashFingerprints(fingerprints []string) string {
+	sort.Strings(fingerprints)
+	hash := sha256.Sum256([]byte(strings.Join(fingerprints, "\n")))
+	return hex.EncodeToString(hash[:])
+}
+
+/**************************************************************************************************
+** fingerprintVaults fingerprints the vaults of a chain, with all their fields: their fees, assets,
+** price per share, debts and metadata.
+**************************************************************************************************/
+func fingerprintVaults(chainID uint64) string {
+	fingerprints := []string{}
+	_, allVaults := storage.ListVaults(chainID)
+	for _, vault := range allVaults {
+		serialized, _ := json.Marshal(vault)
+		fingerprints = append(fingerprints, string(serialized))
+	}
+	return hashFingerprints(fingerprints)
+}
+
+/**************************************************************************************************
+** fingerprintStrategies fingerprints the strategies of a chain on the fields read from the chain
+** and Kong: their allocation and their last harvest. The protocols are left out, being labelled
+** by the `protocols` stage depending on the strategies.
+**************************************************************************************************/
+func fingerprintStrategies(chainID uint64) string {
+	fingerprints := []string{}
+	_, allStrategies := storage.ListStrategies(chainID)
+	for _, strategy := range allStrategies {
+		fingerprints = append(fingerprints, fmt.Sprintf("%s|%s|%t|%t|%t|%s|%v|%v|%v|%v|%v|%v|%v",
+			strategy.Address.Hex(), strategy.VaultAddress.Hex(),
+			strategy.IsActive, strategy.IsInQueue, strategy.IsRetired, strategy.Status,
+			strategy.LastDebtRatio, strategy.LastTotalDebt, strategy.LastTotalGain, strategy.LastTotalLoss,
+			strategy.LastReport, strategy.LastPerformanceFee, strategy.NetAPR,
+		))
+	}
+	return hashFingerprints(fingerprints)
+}
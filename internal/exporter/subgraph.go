@@ -0,0 +1,289 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The export writes the vaults, the strategies and the harvests known by yDaemon as the entities
+** of the Yearn subgraphs, one JSON object per line, for the teams migrating off the hosted
+** subgraphs to load them with minimal changes to their consumers:
+** - the field names are the ones of the subgraph schema,
+** - the ids and the relations are the lowercase addresses (`<txHash>-<logIndex>` for the events),
+** - the BigInt and BigDecimal values are strings,
+** - each object carries its `__typename`, as in a GraphQL response.
+** The files are written to `<output>/<chainID>/{vaults,strategies,harvests}.jsonl`.
+**************************************************************************************************/
+
+type TSubgraphVault struct {
+	TypeName          string   `json:"__typename"`
+	ID                string   `json:"id"`
+	Token             string   `json:"token"`
+	ShareToken        string   `json:"shareToken"`
+	Registry          string   `json:"registry"`
+	APIVersion        string   `json:"apiVersion"`
+	Activation        string   `json:"activation"`
+	ManagementFeeBps  int64    `json:"managementFeeBps"`
+	PerformanceFeeBps int64    `json:"performanceFeeBps"`
+	BalanceTokens     string   `json:"balanceTokens"`
+	PricePerShare     string   `json:"latestPricePerShare"`
+	EmergencyShutdown bool     `json:"emergencyShutdown"`
+	Endorsed          bool     `json:"isTemplateListOrigin"`
+	Strategies        []string `json:"strategies"`
+}
+
+type TSubgraphStrategy struct {
+	TypeName          string `json:"__typename"`
+	ID                string `json:"id"`
+	Address           string `json:"address"`
+	Name              string `json:"name"`
+	Vault             string `json:"vault"`
+	APIVersion        string `json:"apiVersion"`
+	Activation        string `json:"activation"`
+	InQueue           bool   `json:"inQueue"`
+	DoHealthCheck     bool   `json:"doHealthCheck"`
+	DebtRatio         string `json:"debtRatio"`
+	PerformanceFeeBps int64  `json:"performanceFeeBps"`
+	TotalDebt         string `json:"totalDebt"`
+	TotalGain         string `json:"totalGain"`
+	TotalLoss         string `json:"totalLoss"`
+	LastReport        string `json:"lastReport"`
+}
+
+type TSubgraphHarvest struct {
+	TypeName        string `json:"__typename"`
+	ID              string `json:"id"`
+	Timestamp       string `json:"timestamp"`
+	BlockNumber     string `json:"blockNumber"`
+	Transaction     string `json:"transaction"`
+	Vault           string `json:"vault"`
+	Strategy        string `json:"strategy"`
+	Profit          string `json:"profit"`
+	Loss            string `json:"loss"`
+	DebtPaid        string `json:"debtPaid"`
+	DebtOutstanding string `json:"debtOutstanding"`
+}
+
+/**************************************************************************************************
+** toSubgraphID returns the id of an entity for an address, lowercase as in the subgraphs.
+**************************************************************************************************/
+func toSubgraphID(address common.Address) string {
+	return strings.ToLower(address.Hex())
+}
+
+func toBigIntString(value *bigNumber.Int) string {
+	if value == nil {
+		return `0`
+	}
+	return value.String()
+}
+
+func toBigIntOrZero(value string) string {
+	if value == `` {
+		return `0`
+	}
+	return value
+}
+
+/**************************************************************************************************
+** writeJSONL writes the entities to the file, one per line.
+**************************************************************************************************/
+func writeJSONL[T any](path string, entities []T) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, entity := range entities {
+		if err := encoder.Encode(entity); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+/**************************************************************************************************
+** buildSubgraphVaults maps the vaults of a chain and the ids of their strategies to the Vault
+** entity, sorted by id for stable exports.
+**************************************************************************************************/
+func buildSubgraphVaults(vaults map[common.Address]models.TVault, strategies map[string]models.TStrategy) []TSubgraphVault {
+	strategiesPerVault := make(map[common.Address][]string)
+	for _, strategy := range strategies {
+		strategiesPerVault[strategy.VaultAddress] = append(strategiesPerVault[strategy.VaultAddress], toSubgraphID(strategy.Address))
+	}
+
+	entities := make([]TSubgraphVault, 0, len(vaults))
+	for _, vault := range vaults {
+		vaultStrategies := strategiesPerVault[vault.Address]
+		if vaultStrategies == nil {
+			vaultStrategies = []string{}
+		}
+		sort.Strings(vaultStrategies)
+		entities = append(entities, TSubgraphVault{
+			TypeName:          `Vault`,
+			ID:                toSubgraphID(vault.Address),
+			Token:             toSubgraphID(vault.AssetAddress),
+			ShareToken:        toSubgraphID(vault.Address),
+			Registry:          toSubgraphID(vault.RegistryAddress),
+			APIVersion:        vault.Version,
+			Activation:        strconv.FormatUint(vault.Activation, 10),
+			ManagementFeeBps:  int64(vault.ManagementFee),
+			PerformanceFeeBps: int64(vault.PerformanceFee),
+			BalanceTokens:     toBigIntString(vault.LastTotalAssets),
+			PricePerShare:     toBigIntString(vault.LastPricePerShare),
+			EmergencyShutdown: vault.EmergencyShutdown,
+			Endorsed:          vault.Endorsed,
+			Strategies:        vaultStrategies,
+		})
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].ID < entities[j].ID
+	})
+	return entities
+}
+
+/**************************************************************************************************
+** buildSubgraphStrategies maps the strategies of a chain to the Strategy entity, sorted by vault
+** then by id.
+**************************************************************************************************/
+func buildSubgraphStrategies(strategies map[string]models.TStrategy) []TSubgraphStrategy {
+	entities := make([]TSubgraphStrategy, 0, len(strategies))
+	for _, strategy := range strategies {
+		performanceFee := int64(0)
+		if strategy.LastPerformanceFee != nil {
+			performanceFee = strategy.LastPerformanceFee.Int64()
+		}
+		entities = append(entities, TSubgraphStrategy{
+			TypeName:          `Strategy`,
+			ID:                toSubgraphID(strategy.Address),
+			Address:           toSubgraphID(strategy.Address),
+			Name:              strategy.Name,
+			Vault:             toSubgraphID(strategy.VaultAddress),
+			APIVersion:        strategy.VaultVersion,
+			Activation:        strconv.FormatUint(strategy.Activation, 10),
+			InQueue:           strategy.IsInQueue,
+			DoHealthCheck:     strategy.DoHealthCheck,
+			DebtRatio:         toBigIntString(strategy.LastDebtRatio),
+			PerformanceFeeBps: performanceFee,
+			TotalDebt:         toBigIntString(strategy.LastTotalDebt),
+			TotalGain:         toBigIntString(strategy.LastTotalGain),
+			TotalLoss:         toBigIntString(strategy.LastTotalLoss),
+			LastReport:        toBigIntString(strategy.LastReport),
+		})
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		if entities[i].Vault != entities[j].Vault {
+			return entities[i].Vault < entities[j].Vault
+		}
+		return entities[i].ID < entities[j].ID
+	})
+	return entities
+}
+
+/**************************************************************************************************
+** listSubgraphHarvests reads the harvests of the known strategies of a chain from the Kong
+** database: the `Harvested` events of the v2 strategies and the `Reported` events of the v3 ones.
+**************************************************************************************************/
+func listSubgraphHarvests(chainID uint64, strategies map[string]models.TStrategy) ([]TSubgraphHarvest, error) {
+	db := storage.GetDB()
+	if db == nil {
+		return nil, errors.New("database connection not available")
+	}
+
+	vaultPerStrategy := make(map[string]string, len(strategies))
+	strategyAddresses := make([]string, 0, len(strategies))
+	for _, strategy := range strategies {
+		vaultPerStrategy[toSubgraphID(strategy.Address)] = toSubgraphID(strategy.VaultAddress)
+		strategyAddresses = append(strategyAddresses, strategy.Address.Hex())
+	}
+	if len(strategyAddresses) == 0 {
+		return []TSubgraphHarvest{}, nil
+	}
+
+	query := `
+		SELECT
+			address,
+			args->>'profit' AS profit,
+			args->>'loss' AS loss,
+			args->>'debtPayment' AS debt_payment,
+			args->>'debtOutstanding' AS debt_outstanding,
+			block_number,
+			EXTRACT(EPOCH FROM block_time)::bigint AS block_time,
+			log_index,
+			transaction_hash
+		FROM evmlog
+		WHERE
+			(chain_id = ?) AND (address IN ?)
+			AND (event_name = 'Reported' OR event_name = 'Harvested')
+		ORDER BY
+			block_number ASC, log_index ASC`
+
+	reports := []models.TStrategyReportDB{}
+	if err := db.Raw(query, chainID, strategyAddresses).Scan(&reports).Error; err != nil {
+		return nil, err
+	}
+
+	entities := make([]TSubgraphHarvest, 0, len(reports))
+	for _, report := range reports {
+		strategyID := strings.ToLower(report.Address)
+		transaction := strings.ToLower(report.TransactionHash)
+		entities = append(entities, TSubgraphHarvest{
+			TypeName:        `Harvest`,
+			ID:              transaction + `-` + strconv.FormatUint(report.LogIndex, 10),
+			Timestamp:       strconv.FormatUint(report.BlockTime, 10),
+			BlockNumber:     strconv.FormatUint(report.BlockNumber, 10),
+			Transaction:     transaction,
+			Vault:           vaultPerStrategy[strategyID],
+			Strategy:        strategyID,
+			Profit:          toBigIntOrZero(report.Profit),
+			Loss:            toBigIntOrZero(report.Loss),
+			DebtPaid:        toBigIntOrZero(report.DebtPayment),
+			DebtOutstanding: toBigIntOrZero(report.DebtOutstanding),
+		})
+	}
+	return entities, nil
+}
+
+/**************************************************************************************************
+** ExportSubgraphEntities writes the entities of a chain to `<output>/<chainID>/`. The harvests are
+** only exported when the Kong database is configured; the vaults and the strategies come from the
+** store and are always exported.
+**************************************************************************************************/
+func ExportSubgraphEntities(chainID uint64, output string) error {
+	directory := filepath.Join(output, strconv.FormatUint(chainID, 10))
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+
+	vaults, _ := storage.ListVaults(chainID)
+	strategies, _ := storage.ListStrategies(chainID)
+	if err := writeJSONL(filepath.Join(directory, `vaults.jsonl`), buildSubgraphVaults(vaults, strategies)); err != nil {
+		return err
+	}
+	if err := writeJSONL(filepath.Join(directory, `strategies.jsonl`), buildSubgraphStrategies(strategies)); err != nil {
+		return err
+	}
+
+	harvests, err := listSubgraphHarvests(chainID, strategies)
+	if err != nil {
+		logs.Warning(`Skipping the harvests of chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+		return nil
+	}
+	return writeJSONL(filepath.Join(directory, `harvests.jsonl`), harvests)
+}
@@ -0,0 +1,294 @@
+package exporter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/logs"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+/**************************************************************************************************
+** The time-series export writes the APY, the TVL and the price per share of every vault, recorded
+** at every snapshot by the metrics stage, to an external time-series database. The daemon only
+** keeps a few days of snapshots and a daily APY: the long term analytics query the database
+** instead, without loading the serving process. The points are the ones of the metrics history,
+** so the exported values are the ones served.
+**************************************************************************************************/
+type TVaultPoint struct {
+	ChainID       uint64
+	Vault         string
+	Timestamp     uint64
+	APY           *float64 // Nil when the vault has no APY yet
+	TVL           float64  // In USD
+	PricePerShare float64  // Normalized with the decimals of the asset
+}
+
+/**************************************************************************************************
+** TTimeseriesBackend is a time-series database the points are written to.
+**************************************************************************************************/
+type TTimeseriesBackend interface {
+	// Name returns the name of the backend, as set in TIMESERIES_EXPORTER
+	Name() string
+	// Write stores the points of a snapshot
+	Write(points []TVaultPoint) error
+}
+
+const (
+	TIMESERIES_EXPORTER_INFLUXDB    = `influxdb`
+	TIMESERIES_EXPORTER_TIMESCALEDB = `timescaledb`
+)
+
+/**************************************************************************************************
+** TIMESERIES_OPEN_RETRY_DELAY is the delay before opening again a backend which failed to open,
+** for an unavailable database to be exported to once it is back. TIMESERIES_QUEUE_SIZE is the
+** number of snapshots waiting to be written, the new ones being dropped once it is full.
+**************************************************************************************************/
+const TIMESERIES_OPEN_RETRY_DELAY = 5 * time.Minute
+const TIMESERIES_QUEUE_SIZE = 32
+
+type tTimeseriesBatch struct {
+	chainID uint64
+	points  []TVaultPoint
+}
+
+var (
+	_timeseriesBackend       TTimeseriesBackend
+	_timeseriesBackendMtx    sync.Mutex
+	_timeseriesNextOpen      time.Time
+	_timeseriesQueue         = make(chan tTimeseriesBatch, TIMESERIES_QUEUE_SIZE)
+	_timeseriesWriterOnce    sync.Once
+	_timeseriesUnknownWarned bool
+)
+
+/**************************************************************************************************
+** getTimeseriesBackend returns the backend selected by TIMESERIES_EXPORTER, opened on first use.
+** It returns nil when the export is disabled or the backend cannot be opened, in which case it is
+** opened again on the first use after TIMESERIES_OPEN_RETRY_DELAY.
+**************************************************************************************************/
+func getTimeseriesBackend() TTimeseriesBackend {
+	_timeseriesBackendMtx.Lock()
+	defer _timeseriesBackendMtx.Unlock()
+	if _timeseriesBackend != nil || env.TIMESERIES_EXPORTER == `` || time.Now().Before(_timeseriesNextOpen) {
+		return _timeseriesBackend
+	}
+
+	var backend TTimeseriesBackend
+	var err error
+	switch env.TIMESERIES_EXPORTER {
+	case TIMESERIES_EXPORTER_INFLUXDB:
+		backend, err = newInfluxBackend(env.TIMESERIES_INFLUXDB_URL, env.TIMESERIES_INFLUXDB_TOKEN, env.TIMESERIES_INFLUXDB_ORG, env.TIMESERIES_INFLUXDB_BUCKET)
+	case TIMESERIES_EXPORTER_TIMESCALEDB:
+		backend, err = newTimescaleBackend(env.TIMESERIES_POSTGRES_DSN)
+	default:
+		if !_timeseriesUnknownWarned {
+			_timeseriesUnknownWarned = true
+			logs.Warning(`Unknown time-series exporter ` + env.TIMESERIES_EXPORTER + `, nothing will be exported`)
+		}
+		return nil
+	}
+	if err != nil {
+		_timeseriesNextOpen = time.Now().Add(TIMESERIES_OPEN_RETRY_DELAY)
+		logs.Error(`Failed to open the ` + env.TIMESERIES_EXPORTER + ` time-series exporter, retrying in ` + TIMESERIES_OPEN_RETRY_DELAY.String() + `: ` + err.Error())
+		return nil
+	}
+	_timeseriesBackend = backend
+	logs.Info(`Exporting the vault metrics to ` + backend.Name())
+	return _timeseriesBackend
+}
+
+/**************************************************************************************************
+** ExportVaultPoints queues the points of a snapshot, written in the background by a single writer
+** for a slow or unavailable database not to delay the refresh. The snapshots are dropped when
+** TIMESERIES_QUEUE_SIZE of them are already waiting, and a failed write is only logged: the points
+** of the next snapshot are written anyway.
+**************************************************************************************************/
+func ExportVaultPoints(chainID uint64, points []TVaultPoint) {
+	if len(points) == 0 || getTimeseriesBackend() == nil {
+		return
+	}
+	_timeseriesWriterOnce.Do(func() {
+		go writeTimeseriesBatches()
+	})
+	select {
+	case _timeseriesQueue <- tTimeseriesBatch{chainID: chainID, points: points}:
+	default:
+		logs.Warning(fmt.Sprintf("The time-series export is late, dropping the vault metrics of chain %d", chainID))
+	}
+}
+
+/**************************************************************************************************
+** writeTimeseriesBatches writes the queued snapshots one at a time.
+**************************************************************************************************/
+func writeTimeseriesBatches() {
+	for batch := range _timeseriesQueue {
+		backend := getTimeseriesBackend()
+		if backend == nil {
+			continue
+		}
+		if err := backend.Write(batch.points); err != nil {
+			logs.Error(fmt.Sprintf("Failed to export the vault metrics of chain %d to %s: %s", batch.chainID, backend.Name(), err.Error()))
+		}
+	}
+}
+
+/**************************************************************************************************
+** tInfluxBackend writes the points to an InfluxDB v2 bucket with its HTTP API, in the line
+** protocol: one `vault_metrics` point per vault, tagged with its chain and address.
+**************************************************************************************************/
+type tInfluxBackend struct {
+	writeURL string
+	token    string
+	client   *http.Client
+}
+
+func newInfluxBackend(baseURL, token, org, bucket string) (*tInfluxBackend, error) {
+	if baseURL == `` {
+		return nil, errors.New(`TIMESERIES_INFLUXDB_URL is not set`)
+	}
+	query := url.Values{}
+	query.Set(`org`, org)
+	query.Set(`bucket`, bucket)
+	query.Set(`precision`, `s`)
+	return &tInfluxBackend{
+		writeURL: strings.TrimSuffix(baseURL, `/`) + `/api/v2/write?` + query.Encode(),
+		token:    token,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (b *tInfluxBackend) Name() string {
+	return TIMESERIES_EXPORTER_INFLUXDB
+}
+
+func (b *tInfluxBackend) Write(points []TVaultPoint) error {
+	request, err := http.NewRequest(http.MethodPost, b.writeURL, bytes.NewReader(encodeLineProtocol(points)))
+	if err != nil {
+		return err
+	}
+	request.Header.Set(`Content-Type`, `text/plain; charset=utf-8`)
+	if b.token != `` {
+		request.Header.Set(`Authorization`, `Token `+b.token)
+	}
+	response, err := b.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf(`status %d: %s`, response.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+/**************************************************************************************************
+** encodeLineProtocol encodes the points in the InfluxDB line protocol. The tags are addresses and
+** chain IDs, which never need to be escaped. The APY field is omitted when unknown.
+**************************************************************************************************/
+func encodeLineProtocol(points []TVaultPoint) []byte {
+	var buffer bytes.Buffer
+	for _, point := range points {
+		buffer.WriteString(`vault_metrics,chainID=`)
+		buffer.WriteString(strconv.FormatUint(point.ChainID, 10))
+		buffer.WriteString(`,vault=`)
+		buffer.WriteString(point.Vault)
+		buffer.WriteString(` tvl=`)
+		buffer.WriteString(strconv.FormatFloat(point.TVL, 'f', -1, 64))
+		buffer.WriteString(`,pricePerShare=`)
+		buffer.WriteString(strconv.FormatFloat(point.PricePerShare, 'f', -1, 64))
+		if point.APY != nil {
+			buffer.WriteString(`,apy=`)
+			buffer.WriteString(strconv.FormatFloat(*point.APY, 'f', -1, 64))
+		}
+		buffer.WriteString(` `)
+		buffer.WriteString(strconv.FormatUint(point.Timestamp, 10))
+		buffer.WriteString("\n")
+	}
+	return buffer.Bytes()
+}
+
+/**************************************************************************************************
+** TVaultMetricsRow is a row of the `ydaemon_vault_metrics` hypertable of the TimescaleDB backend,
+** e.g. to query the weekly TVL of a vault:
+**
+**   SELECT time_bucket('7 days', time) AS week, avg(tvl)
+**   FROM ydaemon_vault_metrics
+**   WHERE chain_id = 1 AND vault = '0x...'
+**   GROUP BY week ORDER BY week;
+**************************************************************************************************/
+type TVaultMetricsRow struct {
+	Time          time.Time `gorm:"primaryKey;not null"`
+	ChainID       uint64    `gorm:"primaryKey"`
+	Vault         string    `gorm:"primaryKey"`
+	APY           *float64
+	TVL           float64
+	PricePerShare float64
+}
+
+func (TVaultMetricsRow) TableName() string {
+	return `ydaemon_vault_metrics`
+}
+
+/**************************************************************************************************
+** tTimescaleBackend inserts the points in a TimescaleDB hypertable, distinct from the Kong and the
+** storage databases.
+**************************************************************************************************/
+type tTimescaleBackend struct {
+	db *gorm.DB
+}
+
+/**************************************************************************************************
+** newTimescaleBackend connects to the database and creates the `ydaemon_vault_metrics` hypertable
+** if needed. The table is kept as a plain Postgres table when the timescaledb extension is not
+** available, the points being inserted anyway.
+**************************************************************************************************/
+func newTimescaleBackend(dsn string) (*tTimescaleBackend, error) {
+	if dsn == `` {
+		return nil, errors.New(`TIMESERIES_POSTGRES_DSN is not set`)
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(&TVaultMetricsRow{}); err != nil {
+		return nil, err
+	}
+	if err := db.Exec(`SELECT create_hypertable('ydaemon_vault_metrics', 'time', if_not_exists => TRUE, migrate_data => TRUE)`).Error; err != nil {
+		logs.Warning(`Failed to make ydaemon_vault_metrics a hypertable, is timescaledb installed? ` + err.Error())
+	}
+	return &tTimescaleBackend{db: db}, nil
+}
+
+func (b *tTimescaleBackend) Name() string {
+	return TIMESERIES_EXPORTER_TIMESCALEDB
+}
+
+func (b *tTimescaleBackend) Write(points []TVaultPoint) error {
+	rows := make([]TVaultMetricsRow, 0, len(points))
+	for _, point := range points {
+		rows = append(rows, TVaultMetricsRow{
+			Time:          time.Unix(int64(point.Timestamp), 0).UTC(),
+			ChainID:       point.ChainID,
+			Vault:         point.Vault,
+			APY:           point.APY,
+			TVL:           point.TVL,
+			PricePerShare: point.PricePerShare,
+		})
+	}
+	return b.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(rows, 500).Error
+}
@@ -0,0 +1,87 @@
+package exporter
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yearn/ydaemon/common/env"
+)
+
+/**************************************************************************************************
+** TestEncodeLineProtocol tests that a point is encoded with its chain and vault as tags, and that
+** the APY field is omitted when unknown.
+**************************************************************************************************/
+func TestEncodeLineProtocol(t *testing.T) {
+	apy := 0.0525
+	points := []TVaultPoint{
+		{ChainID: 1, Vault: `0xA0b8`, Timestamp: 1700000000, APY: &apy, TVL: 1234.5, PricePerShare: 1.01},
+		{ChainID: 10, Vault: `0xB1c9`, Timestamp: 1700000000, TVL: 0, PricePerShare: 1},
+	}
+	assert.Equal(t,
+		"vault_metrics,chainID=1,vault=0xA0b8 tvl=1234.5,pricePerShare=1.01,apy=0.0525 1700000000\n"+
+			"vault_metrics,chainID=10,vault=0xB1c9 tvl=0,pricePerShare=1 1700000000\n",
+		string(encodeLineProtocol(points)),
+	)
+}
+
+/**************************************************************************************************
+** TestInfluxBackendWrite tests that the points are posted to the write API of the bucket with the
+** token, and that a rejected write is an error.
+**************************************************************************************************/
+func TestInfluxBackendWrite(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, `/api/v2/write`, r.URL.Path)
+		assert.Equal(t, `yearn`, r.URL.Query().Get(`org`))
+		assert.Equal(t, `ydaemon`, r.URL.Query().Get(`bucket`))
+		assert.Equal(t, `s`, r.URL.Query().Get(`precision`))
+		if r.Header.Get(`Authorization`) != `Token secret` {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	points := []TVaultPoint{{ChainID: 1, Vault: `0xA0b8`, Timestamp: 1700000000, TVL: 1, PricePerShare: 1}}
+
+	backend, err := newInfluxBackend(server.URL+`/`, `secret`, `yearn`, `ydaemon`)
+	assert.NoError(t, err)
+	assert.NoError(t, backend.Write(points))
+	assert.Equal(t, string(encodeLineProtocol(points)), received)
+
+	backend, err = newInfluxBackend(server.URL, `wrong`, `yearn`, `ydaemon`)
+	assert.NoError(t, err)
+	assert.Error(t, backend.Write(points))
+
+	_, err = newInfluxBackend(``, `secret`, `yearn`, `ydaemon`)
+	assert.Error(t, err)
+}
+
+/**************************************************************************************************
+** TestGetTimeseriesBackendRetries tests that a backend failing to open is opened again once
+** TIMESERIES_OPEN_RETRY_DELAY has passed, rather than disabling the export for good.
+**************************************************************************************************/
+func TestGetTimeseriesBackendRetries(t *testing.T) {
+	defer func(exporter, url string) {
+		env.TIMESERIES_EXPORTER, env.TIMESERIES_INFLUXDB_URL = exporter, url
+		_timeseriesBackend, _timeseriesNextOpen = nil, time.Time{}
+	}(env.TIMESERIES_EXPORTER, env.TIMESERIES_INFLUXDB_URL)
+
+	env.TIMESERIES_EXPORTER = TIMESERIES_EXPORTER_INFLUXDB
+	env.TIMESERIES_INFLUXDB_URL = ``
+	assert.Nil(t, getTimeseriesBackend())
+
+	// Fixed before the retry delay: still not opened
+	env.TIMESERIES_INFLUXDB_URL = `http://localhost:8086`
+	assert.Nil(t, getTimeseriesBackend())
+
+	_timeseriesNextOpen = time.Now().Add(-time.Second)
+	assert.NotNil(t, getTimeseriesBackend())
+}
@@ -160,6 +160,8 @@ func BuildVaultSymbol(t models.TVault, vaultSymbol string) (string, string, stri
 ** - TotalAssets: The raw amount of assets in the vault (in token base units)
 ** - TVL: The total value locked in USD
 ** - Price: The price of the underlying token in USD
+** - Breakdown: The valuation of each constituent, for the LP tokens
+** - IsPriceStale: Whether the price is the last known one, carried over as no source priced it
 **
 ** @param t models.TVault - The vault to calculate TVL for
 ** @return models.TTVL - A structure containing the TVL and related financial metrics
@@ -195,9 +197,95 @@ func BuildVaultTVL(t models.TVault) models.TTVL {
 		TVL:         float64(kongTVL),
 		Price:       fHumanizedPrice,
 	}
+	if price, ok := storage.GetPrice(t.ChainID, t.AssetAddress); ok && price.IsStale {
+		tvl.IsPriceStale = true
+		tvl.PriceStaleSince = price.StaleSince
+	}
+
+	/**********************************************************************************************
+	** Between the snapshots, the total assets read on the new heads bring the TVL up to date, in
+	** proportion of the total assets the TVL of Kong was computed with.
+	**********************************************************************************************/
+	if live, ok := storage.GetLiveVaultState(t.ChainID, t.Address); ok && live.TotalAssets != nil {
+		if !kongTotalAssets.IsZero() && !live.TotalAssets.IsZero() {
+			ratio, _ := bigNumber.NewFloat(0).Quo(bigNumber.NewFloat(0).SetInt(live.TotalAssets), bigNumber.NewFloat(0).SetInt(kongTotalAssets)).Float64()
+			tvl.TVL = tvl.TVL * ratio
+			tvl.TotalAssets = live.TotalAssets
+		}
+	}
+
+	/**********************************************************************************************
+	** For the LP tokens, the TVL is the sum of the constituents valued at their own price, which
+	** is more robust than a single LP spot price. The components follow the total assets of the
+	** vault and the prices of the constituents until the next decomposition.
+	**********************************************************************************************/
+	if breakdown, ok := storage.GetTVLBreakdown(t.ChainID, t.Address); ok && len(breakdown.Components) > 0 {
+		ratio := 1.0
+		if breakdown.TotalAssets != nil && !breakdown.TotalAssets.IsZero() && tvl.TotalAssets != nil && !tvl.TotalAssets.IsZero() {
+			ratio, _ = bigNumber.NewFloat(0).Quo(bigNumber.NewFloat(0).SetInt(tvl.TotalAssets), bigNumber.NewFloat(0).SetInt(breakdown.TotalAssets)).Float64()
+		}
+		tvl.TVL = 0
+		tvl.Breakdown = []models.TTVLComponent{}
+		for _, component := range breakdown.Components {
+			component.Amount = component.Amount * ratio
+			if !addresses.Equals(component.Address, t.AssetAddress) {
+				if _, price := getHumanizedTokenPrice(t.ChainID, component.Address); price > 0 {
+					component.Price = price
+				}
+			}
+			component.Value = component.Amount * component.Price
+			tvl.TVL += component.Value
+			tvl.Breakdown = append(tvl.Breakdown, component)
+		}
+	}
 	return tvl
 }
 
+/**************************************************************************************************
+** STAGE_ENDORSED_MIN_TVL is the TVL, in USD, an endorsed vault must reach to leave the
+** experimental stage. STAGE_ENDORSED_EXIT_TVL is the TVL, in USD, an endorsed vault must fall
+** below to go back to it, so a vault hovering around the threshold doesn't flip between the two.
+**************************************************************************************************/
+const STAGE_ENDORSED_MIN_TVL = 10_000.0
+const STAGE_ENDORSED_EXIT_TVL = 2_500.0
+
+/**************************************************************************************************
+** BuildVaultStage determines the lifecycle stage of a vault.
+**
+** The stage is resolved in the following order:
+** 1. The operator override from the metadata, if it is a known stage
+** 2. Retired if the vault is marked as retired
+** 3. Deprecated if a migration is available or the vault is in emergency shutdown
+** 4. Endorsed if the vault was endorsed by a registry and its TVL is above the threshold, or if
+**    it was already endorsed and its TVL is still above the exit threshold
+** 5. Experimental otherwise
+**
+** @param t models.TVault - The vault to get the stage for
+** @param tvl float64 - The TVL of the vault, in USD
+** @param previous models.TVaultStage - The stage of the vault on the previous refresh, if any
+** @return models.TVaultStage - The lifecycle stage of the vault
+**************************************************************************************************/
+func BuildVaultStage(t models.TVault, tvl float64, previous models.TVaultStage) models.TVaultStage {
+	switch t.Metadata.StageOverride {
+	case models.VaultStageExperimental, models.VaultStageEndorsed, models.VaultStageDeprecated, models.VaultStageRetired:
+		return t.Metadata.StageOverride
+	}
+
+	if t.Metadata.IsRetired {
+		return models.VaultStageRetired
+	}
+	if t.Metadata.Migration.Available || t.EmergencyShutdown {
+		return models.VaultStageDeprecated
+	}
+	if t.Endorsed && tvl >= STAGE_ENDORSED_MIN_TVL {
+		return models.VaultStageEndorsed
+	}
+	if t.Endorsed && previous == models.VaultStageEndorsed && tvl >= STAGE_ENDORSED_EXIT_TVL {
+		return models.VaultStageEndorsed
+	}
+	return models.VaultStageExperimental
+}
+
 /**************************************************************************************************
 ** BuildVaultCategory determines the appropriate category for a vault based on multiple factors.
 **
@@ -0,0 +1,44 @@
+package fetcher
+
+import (
+	"testing"
+
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** TestBuildVaultStage checks the resolution order of the stage of a vault, and that an endorsed
+** vault only goes back to experimental once its TVL falls below the exit threshold.
+**************************************************************************************************/
+func TestBuildVaultStage(t *testing.T) {
+	endorsed := models.TVault{Endorsed: true}
+	retired := models.TVault{Endorsed: true, Metadata: models.TVaultMetadata{IsRetired: true}}
+	overridden := models.TVault{Endorsed: true, Metadata: models.TVaultMetadata{IsRetired: true, StageOverride: models.VaultStageEndorsed}}
+	shutdown := models.TVault{Endorsed: true, EmergencyShutdown: true}
+	unknownOverride := models.TVault{Endorsed: true, Metadata: models.TVaultMetadata{StageOverride: `sunset`}}
+
+	testCases := []struct {
+		name     string
+		vault    models.TVault
+		tvl      float64
+		previous models.TVaultStage
+		expected models.TVaultStage
+	}{
+		{`not endorsed`, models.TVault{}, 1_000_000, ``, models.VaultStageExperimental},
+		{`endorsed below the threshold`, endorsed, STAGE_ENDORSED_MIN_TVL - 1, ``, models.VaultStageExperimental},
+		{`endorsed at the threshold`, endorsed, STAGE_ENDORSED_MIN_TVL, ``, models.VaultStageEndorsed},
+		{`endorsed dipping between the thresholds`, endorsed, STAGE_ENDORSED_EXIT_TVL, models.VaultStageEndorsed, models.VaultStageEndorsed},
+		{`endorsed falling below the exit threshold`, endorsed, STAGE_ENDORSED_EXIT_TVL - 1, models.VaultStageEndorsed, models.VaultStageExperimental},
+		{`experimental rising between the thresholds`, endorsed, STAGE_ENDORSED_MIN_TVL - 1, models.VaultStageExperimental, models.VaultStageExperimental},
+		{`retired`, retired, 1_000_000, models.VaultStageEndorsed, models.VaultStageRetired},
+		{`emergency shutdown`, shutdown, 1_000_000, models.VaultStageEndorsed, models.VaultStageDeprecated},
+		{`operator override`, overridden, 0, models.VaultStageRetired, models.VaultStageEndorsed},
+		{`unknown override`, unknownOverride, 1_000_000, ``, models.VaultStageEndorsed},
+	}
+
+	for _, tc := range testCases {
+		if stage := BuildVaultStage(tc.vault, tc.tvl, tc.previous); stage != tc.expected {
+			t.Errorf("%s: expected the stage %s, got %s", tc.name, tc.expected, stage)
+		}
+	}
+}
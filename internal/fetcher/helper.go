@@ -73,6 +73,7 @@ func getHumanizedValue(balanceToken *bigNumber.Int, decimals int, humanizedPrice
 **    - Price per share (with convertToAssets fallback for proper decimal handling)
 **    - Decimals (for accurate value calculations)
 **    - Total assets (for TVL calculations)
+**    - Total idle (for the assets withdrawable without unwinding a strategy)
 **    - Default queue (list of active strategies)
 **    - API version (for compatibility checks)
 **    - Shutdown status (V3 equivalent of emergency shutdown)
@@ -106,6 +107,7 @@ func getV3VaultCalls(vault models.TVault) []ethereum.Call {
 	calls = append(calls, multicalls.GetConvertPricePerShare(vault.Address.Hex(), vault.Address, asEthers))
 	calls = append(calls, multicalls.GetDecimals(vault.Address.Hex(), vault.Address))
 	calls = append(calls, multicalls.GetTotalAssets(vault.Address.Hex(), vault.Address))
+	calls = append(calls, multicalls.GetTotalIdle(vault.Address.Hex(), vault.Address))
 	calls = append(calls, multicalls.GetDefaultQueue(vault.Address.Hex(), vault.Address))
 	calls = append(calls, multicalls.GetAPIVersion(vault.Address.Hex(), vault.Address))
 	calls = append(calls, multicalls.GetIsShutdown(vault.Address.Hex(), vault.Address, ``))
@@ -257,6 +259,8 @@ func getV2StrategyCalls(strat models.TStrategy) []ethereum.Call {
 **    - Gets performance fee
 **    - Fetches total assets from the vault
 **    - Checks if the strategy is shut down
+**    - Gets the assets the vault can withdraw from the strategy (maxWithdraw), and the share of
+**      its last known debt the vault would lose withdrawing it (assess_share_of_unrealised_losses)
 **
 ** 2. Hourly updates (if more than 1 hour since last update or forced refresh):
 **    - Retrieves CRV-related settings (keepCRV, keepCRVPercent)
@@ -281,6 +285,11 @@ func getV3StrategyCalls(strat models.TStrategy) []ethereum.Call {
 	calls = append(calls, multicalls.GetPerformanceFee(strategyKey, strat.Address))
 	calls = append(calls, multicalls.GetTotalAssets(strat.VaultAddress.Hex(), strat.VaultAddress))
 	calls = append(calls, multicalls.GetIsShutdown(strategyKey, strat.Address, strat.VaultVersion))
+	calls = append(calls, multicalls.GetStrategyMaxWithdraw(strategyKey, strat.Address, strat.VaultAddress, strat.VaultVersion))
+	if strat.LastTotalDebt != nil && !strat.LastTotalDebt.IsZero() {
+		// Reverts, and is then ignored, if the debt of the strategy decreased since the last refresh
+		calls = append(calls, multicalls.GetAssessShareOfUnrealisedLosses(strategyKey, strat.VaultAddress, strat.Address, bigNumber.ToInt(strat.LastTotalDebt), strat.VaultVersion))
+	}
 	if time.Since(lastUpdate).Hours() > 1 || shouldRefresh {
 		// If the last strat update was more than 1 hour ago, we will do a partial update
 		calls = append(calls, multicalls.GetStategyKeepCRV(strategyKey, strat.Address, strat.VaultVersion))
@@ -376,8 +385,8 @@ func handleV2VaultCalls(vault models.TVault, response map[string][]interface{})
 **    - Falls back to converted asset value with proper decimal adjustment if needed
 **
 ** 2. Extracting core vault metrics:
-**    - Total assets (for TVL calculations)
-**    - Active strategies list (from the default queue)
+**    - Total assets (for TVL calculations) and total idle
+**    - Default queue, and active strategies list (the default queue and the manual strategies)
 **    - Emergency shutdown status (as isShutdown in V3)
 **    - Underlying asset address
 **    - API version
@@ -399,6 +408,7 @@ func handleV3VaultCalls(vault models.TVault, response map[string][]interface{})
 	rawConvertPricePerShare := response[vault.Address.Hex()+`convertToAssets`]
 	rawTotalAssets := response[vault.Address.Hex()+`totalAssets`]
 	rawDefaultQueue := response[vault.Address.Hex()+`get_default_queue`]
+	rawTotalIdle := response[vault.Address.Hex()+`totalIdle`]
 	rawShutdown := response[vault.Address.Hex()+`isShutdown`]
 	rawUnderlying := response[vault.Address.Hex()+`asset`]
 	rawApiVersion := response[vault.Address.Hex()+`apiVersion`]
@@ -431,7 +441,11 @@ func handleV3VaultCalls(vault models.TVault, response map[string][]interface{})
 	}
 
 	vault.LastTotalAssets = helpers.DecodeBigInt(rawTotalAssets)
-	vault.LastActiveStrategies = helpers.DecodeAddresses(rawDefaultQueue)
+	vault.DefaultQueue = helpers.DecodeAddresses(rawDefaultQueue)
+	vault.LastActiveStrategies = append([]common.Address{}, vault.DefaultQueue...)
+	if len(rawTotalIdle) > 0 {
+		vault.LastTotalIdle = helpers.DecodeBigInt(rawTotalIdle)
+	}
 
 	// Append manual strategies if they exist for this vault (avoid duplicates)
 	manualStrategies := storage.GetManualStrategiesForVault(vault.ChainID, vault.Address)
@@ -575,11 +589,12 @@ func handleV2StrategyCalls(strat models.TStrategy, response map[string][]interfa
 ** and behavior compared to earlier versions.
 **
 ** Key operations:
-** 1. Processes core strategy data (debt, activation time, last report)
+** 1. Processes core strategy data (current and max debt, activation time, last report)
 ** 2. Sets total gain and loss fields (note: these are not directly available in V3)
 ** 3. Calculates debt ratio as a percentage of vault's total assets
 ** 4. Processes operational settings (keepCRV, keepCRVPercent, keepCVX)
-** 5. Updates status flags (isActive, isRetired) based on the shutdown state
+** 5. Processes the withdrawal limits (maxWithdraw, unrealised loss of the whole debt)
+** 6. Updates status flags (isActive, isRetired) based on the shutdown state
 **
 ** The debt ratio calculation is particularly important as it:
 ** - Converts raw debt values to a percentage (0-10000, where 10000 = 100%)
@@ -608,6 +623,8 @@ func handleV3StrategyCalls(strat models.TStrategy, response map[string][]interfa
 	rawDoHealthCheck := response[strategyKey+`doHealthCheck`]
 	rawIsShutdown := response[strategyKey+`isShutdown`]
 	rawPerformanceFee := response[strategyKey+`performanceFee`]
+	rawMaxWithdraw := response[strategyKey+`maxWithdraw`]
+	rawUnrealisedLoss := response[strategyKey+`assess_share_of_unrealised_losses`]
 
 	if (len(rawPerformanceFee) > 0) && (len(rawStrategies) > 0) {
 		strat.LastPerformanceFee = helpers.DecodeBigInt(rawPerformanceFee)
@@ -618,9 +635,14 @@ func handleV3StrategyCalls(strat models.TStrategy, response map[string][]interfa
 		strat.LastTotalDebt = bigNumber.SetInt(rawStrategies[0].(typeOfRawStrategies).CurrentDebt)
 		strat.TimeActivated = bigNumber.SetInt(rawStrategies[0].(typeOfRawStrategies).Activation)
 		strat.LastReport = bigNumber.SetInt(rawStrategies[0].(typeOfRawStrategies).LastReport)
+		strat.LastMaxDebt = bigNumber.SetInt(rawStrategies[0].(typeOfRawStrategies).MaxDebt)
 	}
 	strat.LastTotalGain = bigNumber.NewInt(0) //Not available in V3
 	strat.LastTotalLoss = bigNumber.NewInt(0) //Not available in V3
+	if len(rawMaxWithdraw) > 0 {
+		strat.LastMaxWithdraw = helpers.DecodeBigInt(rawMaxWithdraw)
+	}
+	strat.LastUnrealisedLoss = helpers.DecodeBigInt(rawUnrealisedLoss)
 	vaultTotalAssets := helpers.DecodeBigInt(rawVaultTotalAssets)
 
 	// Debt ratio should be a int between 0 and 10000, 10000 being 100%
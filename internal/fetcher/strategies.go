@@ -64,7 +64,7 @@ func trimIgnoredStrategies(strategiesMap map[string]models.TStrategy) map[string
 			addresses.Equals(vault.Address, "0x5B977577Eb8a480f63e11FC615D6753adB8652Ae") ||
 			addresses.Equals(vault.Address, "0x65343F414FFD6c97b0f6add33d16F6845Ac22BAc") ||
 			addresses.Equals(vault.Address, "0xFaee21D0f0Af88EE72BB6d68E54a90E6EC2616de")
-		if vault.Metadata.IsRetired && !isException {
+		if models.IsVaultRetired(vault) && !isException {
 			continue
 		}
 		relevantStrategies[key] = strat
@@ -319,6 +319,12 @@ func fetchStrategiesBasicInformations(
 	return updatedStrategiesMap
 }
 
+/**************************************************************************************************
+** OnNewStrategy is called with the strategies added to the vaults of a chain since the last
+** refresh. The strategies loaded on the first refresh of a chain are not new.
+**************************************************************************************************/
+var OnNewStrategy func(chainID uint64, strategy models.TStrategy)
+
 /**************************************************************************************************
 ** The base of Yearn are the vaults. They are the smart contracts that are used to manage the
 ** deposits and the withdrawals of the users.
@@ -336,6 +342,7 @@ func RetrieveAllStrategies(
 ) map[string]models.TStrategy {
 	strategyCount := len(strategies)
 	logs.Info(`Fetching details for ` + strconv.Itoa(strategyCount) + ` strategies on chain ` + strconv.FormatUint(chainID, 10))
+	knownStrategies, _ := storage.ListStrategies(chainID)
 	fetchStrategiesBasicInformations(chainID, strategies)
 
 	// Clean up stale strategies: remove ones not in the provided map (from Kong)
@@ -350,5 +357,12 @@ func RetrieveAllStrategies(
 	// Get fresh list after cleanup and write to JSON
 	strategyMap, _ = storage.ListStrategies(chainID)
 	storage.StoreStrategiesToJson(chainID, strategyMap)
+	if OnNewStrategy != nil && len(knownStrategies) > 0 {
+		for key, strategy := range strategyMap {
+			if _, ok := knownStrategies[key]; !ok {
+				OnNewStrategy(chainID, strategy)
+			}
+		}
+	}
 	return strategyMap
 }
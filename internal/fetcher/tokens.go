@@ -58,7 +58,7 @@ func fetchTokensBasicInformations(
 		** preparing the array of calls to send. All calls for all tokens will be send in a single
 		** multicall and will later be accessible via a concatened string `tokenAddress + methodName`.
 		**********************************************************************************************/
-		caller := ethereum.MulticallClientForChainID[chainID]
+		caller := ethereum.GetMulticall(chainID)
 		calls := []ethereum.Call{}
 		for _, tokenAddress := range chunk {
 			if helpers.Contains(toSkip, tokenAddress) {
@@ -64,7 +64,7 @@ func fetchVaultsBasicInformations(
 				addresses.Equals(vault.Address, "0x5B977577Eb8a480f63e11FC615D6753adB8652Ae") ||
 				addresses.Equals(vault.Address, "0x65343F414FFD6c97b0f6add33d16F6845Ac22BAc") ||
 				addresses.Equals(vault.Address, "0xFaee21D0f0Af88EE72BB6d68E54a90E6EC2616de")
-			if vault.Metadata.IsRetired && !isException {
+			if models.IsVaultRetired(vault) && !isException {
 				continue
 			}
 			versionMajor := strings.Split(vault.Version, `.`)[0]
@@ -86,7 +86,7 @@ func fetchVaultsBasicInformations(
 				addresses.Equals(vault.Address, "0x5B977577Eb8a480f63e11FC615D6753adB8652Ae") ||
 				addresses.Equals(vault.Address, "0x65343F414FFD6c97b0f6add33d16F6845Ac22BAc") ||
 				addresses.Equals(vault.Address, "0xFaee21D0f0Af88EE72BB6d68E54a90E6EC2616de")
-			if vault.Metadata.IsRetired && !isException {
+			if models.IsVaultRetired(vault) && !isException {
 				continue
 			}
 			// Preserve debts and other Kong data before processing
@@ -141,6 +141,10 @@ func RetrieveAllVaults(
 	**********************************************************************************************/
 	vaultMap, _ := storage.ListVaults(chainID)
 	metadata := storage.GetVaultsJsonMetadata(chainID)
+	previousStages := map[common.Address]models.TVaultStage{}
+	for address, vault := range vaultMap {
+		previousStages[address] = vault.Stage
+	}
 	shouldRefresh := metadata.ShouldRefresh
 	updatedVaultMap := vaultMap
 	if method == ProcessNewVaultMethodAppend {
@@ -293,6 +297,12 @@ func RetrieveAllVaults(
 			vault.Metadata.Category = models.VaultCategoryAutomatic
 		}
 
+		/******************************************************************************************
+		** The stage depends on the one of the previous refresh, so a TVL dip around the endorsed
+		** threshold doesn't move the vault back to experimental.
+		******************************************************************************************/
+		vault.Stage = BuildVaultStage(vault, BuildVaultTVL(vault).TVL, previousStages[vault.Address])
+
 		storage.StoreVault(chainID, vault)
 	}
 
@@ -0,0 +1,224 @@
+package fetcher
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** STABLE_POOL_PEG_TOLERANCE is the maximum spread between the prices of the constituents of a pool
+** for it to be considered a pool of pegged coins, valued at its virtual price.
+**************************************************************************************************/
+const STABLE_POOL_PEG_TOLERANCE = 0.02
+
+/**************************************************************************************************
+** CURVE_POOL_MAX_COINS is the maximum number of coins of a Curve pool. The coins are read from the
+** pool up to the first empty index.
+**************************************************************************************************/
+const CURVE_POOL_MAX_COINS = 8
+
+/**************************************************************************************************
+** tPoolReserve is the balance the pool holds of one of its coins, both read at the same index.
+**************************************************************************************************/
+type tPoolReserve struct {
+	Coin    common.Address
+	Balance *bigNumber.Int
+}
+
+/**************************************************************************************************
+** RetrieveVaultsTVLBreakdown decomposes the TVL of the vaults whose underlying asset is a Curve LP
+** token. Instead of valuing the LP tokens at a single spot price, the share of each pool reserve
+** owned by the vault is valued at the price of the constituent.
+**
+** The process is split in two multicalls:
+** 1. The pool (minter) and the total supply of each LP token
+** 2. The coins of the pool with their reserve, read at the same index, and its virtual price
+**
+** The coins are read from the pool rather than taken from the underlying tokens of the LP token,
+** which may list a coin twice or the coins of the base pool of a metapool, and would then be
+** paired with the wrong reserve.
+**
+** When a constituent has no price, a pool of pegged coins is valued at its virtual price times the
+** lowest price of its constituents, the LP token being the only component. The breakdown of the
+** vaults that can't be decomposed is removed, so their TVL falls back on Kong.
+**
+** The result is stored on every run and later used by BuildVaultTVL.
+**
+** @param chainID uint64 - The blockchain network ID
+**************************************************************************************************/
+func RetrieveVaultsTVLBreakdown(chainID uint64) {
+	vaults, _ := storage.ListVaults(chainID)
+	lpTokens := map[common.Address]models.TERC20Token{}
+	calls := []ethereum.Call{}
+	for _, vault := range vaults {
+		asset, ok := storage.GetERC20(chainID, vault.AssetAddress)
+		if !ok || asset.Type != models.TokenTypeCurveLP || len(asset.UnderlyingTokensAddresses) == 0 {
+			storage.DeleteTVLBreakdown(chainID, vault.Address)
+			continue
+		}
+		if _, ok := lpTokens[asset.Address]; ok {
+			continue
+		}
+		lpTokens[asset.Address] = asset
+		calls = append(calls, multicalls.GetCurveMinter(asset.Address.Hex(), asset.Address))
+		calls = append(calls, multicalls.GetTotalSupply(asset.Address.Hex(), asset.Address))
+	}
+	if len(calls) == 0 {
+		return
+	}
+
+	/**********************************************************************************************
+	** Old Curve pools use a dedicated LP token with a `minter` pointing to the pool. For the newer
+	** pools, the LP token is the pool itself.
+	**********************************************************************************************/
+	response := multicalls.Perform(chainID, calls, nil)
+	supplyForLP := map[common.Address]*bigNumber.Int{}
+	calls = []ethereum.Call{}
+	for _, asset := range lpTokens {
+		pool := helpers.DecodeAddress(response[asset.Address.Hex()+`minter`])
+		if (pool == common.Address{}) {
+			pool = asset.Address
+		}
+		supplyForLP[asset.Address] = helpers.DecodeBigInt(response[asset.Address.Hex()+`totalSupply`])
+		calls = append(calls, multicalls.GetCurveVirtualPrice(asset.Address.Hex(), pool))
+		for index := 0; index < CURVE_POOL_MAX_COINS; index++ {
+			name := asset.Address.Hex() + `_` + strconv.Itoa(index)
+			calls = append(calls, multicalls.GetCurveCoin(name, pool, big.NewInt(int64(index))))
+			calls = append(calls, multicalls.GetCurveBalance(name, pool, big.NewInt(int64(index))))
+		}
+	}
+	response = multicalls.Perform(chainID, calls, nil)
+
+	for _, vault := range vaults {
+		asset, ok := lpTokens[vault.AssetAddress]
+		if !ok {
+			continue
+		}
+		totalSupply := supplyForLP[asset.Address]
+		totalAssets, ok := storage.GetKongTotalAssets(chainID, vault.Address)
+		if !ok || totalAssets == nil || totalSupply == nil || totalSupply.IsZero() {
+			storage.DeleteTVLBreakdown(chainID, vault.Address)
+			continue
+		}
+
+		virtualPrice := 0.0
+		if rawVirtualPrice := response[asset.Address.Hex()+`get_virtual_price`]; len(rawVirtualPrice) > 0 {
+			virtualPrice, _ = helpers.ToNormalizedAmount(helpers.DecodeBigInt(rawVirtualPrice), 18).Float64()
+		}
+		reserves := decodePoolReserves(response, asset.Address)
+		breakdown, ok := computeTVLBreakdown(chainID, asset, totalAssets, totalSupply, virtualPrice, reserves)
+		if !ok {
+			storage.DeleteTVLBreakdown(chainID, vault.Address)
+			continue
+		}
+		storage.StoreTVLBreakdown(chainID, vault.Address, breakdown)
+	}
+}
+
+/**************************************************************************************************
+** decodePoolReserves pairs each coin read from the pool of an LP token with the balance read at
+** the same index, up to the first index without a coin. A pool whose balances can't be read has no
+** reserve.
+**************************************************************************************************/
+func decodePoolReserves(response map[string][]interface{}, lpToken common.Address) []tPoolReserve {
+	reserves := []tPoolReserve{}
+	for index := 0; index < CURVE_POOL_MAX_COINS; index++ {
+		name := lpToken.Hex() + `_` + strconv.Itoa(index)
+		rawCoin := response[name+`coins`]
+		if len(rawCoin) == 0 || helpers.DecodeAddress(rawCoin) == (common.Address{}) {
+			break
+		}
+		rawBalance := response[name+`balances`]
+		if len(rawBalance) == 0 {
+			return []tPoolReserve{}
+		}
+		reserves = append(reserves, tPoolReserve{
+			Coin:    helpers.DecodeAddress(rawCoin),
+			Balance: helpers.DecodeBigInt(rawBalance),
+		})
+	}
+	return reserves
+}
+
+/**************************************************************************************************
+** computeTVLBreakdown values the share of each reserve of the pool owned by a vault. The vault owns
+** `totalAssets / totalSupply` of the pool, so it owns the same share of each reserve. If a reserve
+** or a price is missing, the decomposition is incomplete and a pool of pegged coins is valued at
+** its virtual price instead, the prices of the underlying tokens of the LP token being used when
+** the coins can't be read. False when the vault can't be decomposed.
+**************************************************************************************************/
+func computeTVLBreakdown(
+	chainID uint64,
+	asset models.TERC20Token,
+	totalAssets *bigNumber.Int,
+	totalSupply *bigNumber.Int,
+	virtualPrice float64,
+	reserves []tPoolReserve,
+) (models.TTVLBreakdown, bool) {
+	breakdown := models.TTVLBreakdown{
+		TotalAssets:  totalAssets,
+		VirtualPrice: virtualPrice,
+	}
+	share := bigNumber.NewFloat(0).Quo(bigNumber.NewFloat(0).SetInt(totalAssets), bigNumber.NewFloat(0).SetInt(totalSupply))
+	isComplete := len(reserves) > 0
+	minPrice, maxPrice := 0.0, 0.0
+	trackPrice := func(price float64) {
+		if minPrice == 0 || price < minPrice {
+			minPrice = price
+		}
+		if price > maxPrice {
+			maxPrice = price
+		}
+	}
+	for _, reserve := range reserves {
+		coin, okCoin := storage.GetERC20(chainID, reserve.Coin)
+		price, okPrice := storage.GetPrice(chainID, reserve.Coin)
+		if !okCoin || !okPrice || price.HumanizedPrice == nil {
+			isComplete = false
+			continue
+		}
+		amount, _ := bigNumber.NewFloat(0).Mul(helpers.ToNormalizedAmount(reserve.Balance, coin.Decimals), share).Float64()
+		humanizedPrice, _ := price.HumanizedPrice.Float64()
+		trackPrice(humanizedPrice)
+		breakdown.Components = append(breakdown.Components, models.TTVLComponent{
+			Address: reserve.Coin,
+			Symbol:  coin.Symbol,
+			Amount:  amount,
+			Price:   humanizedPrice,
+			Value:   amount * humanizedPrice,
+		})
+	}
+	if isComplete {
+		return breakdown, true
+	}
+
+	if len(reserves) == 0 {
+		for _, coinAddress := range asset.UnderlyingTokensAddresses {
+			if price, ok := storage.GetPrice(chainID, coinAddress); ok && price.HumanizedPrice != nil {
+				humanizedPrice, _ := price.HumanizedPrice.Float64()
+				trackPrice(humanizedPrice)
+			}
+		}
+	}
+	isPegged := minPrice > 0 && maxPrice/minPrice-1 <= STABLE_POOL_PEG_TOLERANCE
+	if virtualPrice == 0 || !isPegged {
+		return models.TTVLBreakdown{}, false
+	}
+	amount, _ := helpers.ToNormalizedAmount(totalAssets, asset.Decimals).Float64()
+	breakdown.Components = []models.TTVLComponent{{
+		Address: asset.Address,
+		Symbol:  asset.Symbol,
+		Amount:  amount,
+		Price:   virtualPrice * minPrice,
+		Value:   amount * virtualPrice * minPrice,
+	}}
+	return breakdown, true
+}
@@ -0,0 +1,122 @@
+package fetcher
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** poolResponse builds the multicall response of the coins and balances of the pool of an LP token.
+**************************************************************************************************/
+func poolResponse(lpToken common.Address, coins []common.Address, balances []*big.Int) map[string][]interface{} {
+	response := make(map[string][]interface{})
+	for index, coin := range coins {
+		name := lpToken.Hex() + `_` + strconv.Itoa(index)
+		response[name+`coins`] = []interface{}{coin}
+		if index < len(balances) {
+			response[name+`balances`] = []interface{}{balances[index]}
+		}
+	}
+	return response
+}
+
+/**************************************************************************************************
+** TestComputeTVLBreakdownMetapool checks that the reserves of a metapool are paired with the coins
+** read from the pool, not with the underlying tokens of its LP token, which list a coin twice and
+** the coins of the base pool.
+**************************************************************************************************/
+func TestComputeTVLBreakdownMetapool(t *testing.T) {
+	chainID := uint64(1)
+	lpToken := common.HexToAddress(`0x5a6A4D54456819380173272A5E8E9B9904BdF41B`)
+	mim := common.HexToAddress(`0x99D8a9C45b2ecA8864373A26D1459e3Dff1e17F3`)
+	crv3 := common.HexToAddress(`0x6c3F90f043a72FA612cbac8115EE7e52BDe6E490`)
+	dai := common.HexToAddress(`0x6B175474E89094C44Da98b954EedeAC495271d0F`)
+
+	storage.StoreERC20(chainID, models.TERC20Token{Address: mim, Symbol: `MIM`, Decimals: 18})
+	storage.StoreERC20(chainID, models.TERC20Token{Address: crv3, Symbol: `3Crv`, Decimals: 18})
+	storage.StoreERC20(chainID, models.TERC20Token{Address: dai, Symbol: `DAI`, Decimals: 18})
+	storage.StorePrice(chainID, models.TPrices{Address: mim, HumanizedPrice: bigNumber.NewFloat(0.99)})
+	storage.StorePrice(chainID, models.TPrices{Address: crv3, HumanizedPrice: bigNumber.NewFloat(1.03)})
+	storage.StorePrice(chainID, models.TPrices{Address: dai, HumanizedPrice: bigNumber.NewFloat(1)})
+
+	asset := models.TERC20Token{
+		Address:                   lpToken,
+		Symbol:                    `MIM-3LP3CRV-f`,
+		Decimals:                  18,
+		Type:                      models.TokenTypeCurveLP,
+		UnderlyingTokensAddresses: []common.Address{mim, crv3, mim, dai},
+	}
+	e18 := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	reserveMIM := new(big.Int).Mul(big.NewInt(600), e18)
+	reserve3Crv := new(big.Int).Mul(big.NewInt(400), e18)
+	response := poolResponse(lpToken, []common.Address{mim, crv3}, []*big.Int{reserveMIM, reserve3Crv})
+
+	reserves := decodePoolReserves(response, lpToken)
+	if len(reserves) != 2 || reserves[0].Coin != mim || reserves[1].Coin != crv3 {
+		t.Fatalf("expected the two coins of the pool, got %+v", reserves)
+	}
+
+	totalSupply := bigNumber.SetInt(new(big.Int).Mul(big.NewInt(1000), e18))
+	totalAssets := bigNumber.SetInt(new(big.Int).Mul(big.NewInt(100), e18))
+	breakdown, ok := computeTVLBreakdown(chainID, asset, totalAssets, totalSupply, 1.01, reserves)
+	if !ok {
+		t.Fatal("expected the metapool to be decomposed")
+	}
+	expected := []models.TTVLComponent{
+		{Address: mim, Symbol: `MIM`, Amount: 60, Price: 0.99, Value: 59.4},
+		{Address: crv3, Symbol: `3Crv`, Amount: 40, Price: 1.03, Value: 41.2},
+	}
+	if len(breakdown.Components) != len(expected) {
+		t.Fatalf("expected %d components, got %+v", len(expected), breakdown.Components)
+	}
+	for i, component := range breakdown.Components {
+		if component.Address != expected[i].Address || component.Symbol != expected[i].Symbol ||
+			math.Abs(component.Amount-expected[i].Amount) > 1e-9 || math.Abs(component.Value-expected[i].Value) > 1e-9 {
+			t.Errorf("component %d: expected %+v, got %+v", i, expected[i], component)
+		}
+	}
+}
+
+/**************************************************************************************************
+** TestComputeTVLBreakdownFallback checks that a pool whose coins can't be read is valued at its
+** virtual price when the underlying tokens are pegged, and not decomposed otherwise.
+**************************************************************************************************/
+func TestComputeTVLBreakdownFallback(t *testing.T) {
+	chainID := uint64(1)
+	lpToken := common.HexToAddress(`0x0000000000000000000000000000000000001001`)
+	usdA := common.HexToAddress(`0x0000000000000000000000000000000000001002`)
+	usdB := common.HexToAddress(`0x0000000000000000000000000000000000001003`)
+	eth := common.HexToAddress(`0x0000000000000000000000000000000000001004`)
+	storage.StorePrice(chainID, models.TPrices{Address: usdA, HumanizedPrice: bigNumber.NewFloat(1)})
+	storage.StorePrice(chainID, models.TPrices{Address: usdB, HumanizedPrice: bigNumber.NewFloat(1.01)})
+	storage.StorePrice(chainID, models.TPrices{Address: eth, HumanizedPrice: bigNumber.NewFloat(2500)})
+
+	e18 := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	totalSupply := bigNumber.SetInt(new(big.Int).Mul(big.NewInt(1000), e18))
+	totalAssets := bigNumber.SetInt(new(big.Int).Mul(big.NewInt(100), e18))
+
+	if reserves := decodePoolReserves(poolResponse(lpToken, []common.Address{usdA, usdB}, []*big.Int{e18}), lpToken); len(reserves) != 0 {
+		t.Errorf("expected no reserve when a balance is missing, got %+v", reserves)
+	}
+
+	pegged := models.TERC20Token{Address: lpToken, Symbol: `LP`, Decimals: 18, UnderlyingTokensAddresses: []common.Address{usdA, usdB, usdA}}
+	breakdown, ok := computeTVLBreakdown(chainID, pegged, totalAssets, totalSupply, 1.02, nil)
+	if !ok || len(breakdown.Components) != 1 || breakdown.Components[0].Address != lpToken {
+		t.Fatalf("expected the pegged pool to be valued at its virtual price, got %+v", breakdown)
+	}
+	if math.Abs(breakdown.Components[0].Value-100*1.02) > 1e-9 {
+		t.Errorf("expected a value of 102, got %v", breakdown.Components[0].Value)
+	}
+
+	unpegged := models.TERC20Token{Address: lpToken, Symbol: `LP`, Decimals: 18, UnderlyingTokensAddresses: []common.Address{usdA, eth}}
+	if _, ok := computeTVLBreakdown(chainID, unpegged, totalAssets, totalSupply, 1.02, nil); ok {
+		t.Error("expected a pool of unpegged coins not to be decomposed")
+	}
+}
@@ -0,0 +1,206 @@
+package fetcher
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The verification job re-reads the on-chain state of a random sample of vaults and compares it
+** with the stored state. The values moving with time (price per share, total assets, debts) are
+** allowed to drift by VERIFICATION_TOLERANCE, as they may have changed since the last refresh.
+** The fees must match exactly.
+**************************************************************************************************/
+const VERIFICATION_SAMPLE_SIZE = 20
+const VERIFICATION_TOLERANCE = 0.01
+
+/**************************************************************************************************
+** TStateDrift describes a stored value that does not match the on-chain value. The deviation is
+** relative to the on-chain value (0.05 = 5%).
+**************************************************************************************************/
+type TStateDrift struct {
+	ChainID   uint64
+	Address   string
+	Field     string
+	Stored    string
+	OnChain   string
+	Deviation float64
+}
+
+/**************************************************************************************************
+** OnStateDrift is called with the drifts detected by the verification job, if any. It's set by the
+** daemon to forward the drifts to the alerting channel.
+**************************************************************************************************/
+var OnStateDrift func(chainID uint64, drifts []TStateDrift)
+
+/**************************************************************************************************
+** computeDeviation returns the relative deviation between the stored and the on-chain values.
+**************************************************************************************************/
+func computeDeviation(stored *bigNumber.Int, onChain *bigNumber.Int) float64 {
+	if stored == nil {
+		stored = bigNumber.NewInt(0)
+	}
+	if onChain == nil {
+		onChain = bigNumber.NewInt(0)
+	}
+	if onChain.IsZero() {
+		if stored.IsZero() {
+			return 0
+		}
+		return 1
+	}
+	storedFloat, _ := bigNumber.NewFloat().SetInt(stored).Float64()
+	onChainFloat, _ := bigNumber.NewFloat().SetInt(onChain).Float64()
+	return math.Abs(storedFloat-onChainFloat) / math.Abs(onChainFloat)
+}
+
+/**************************************************************************************************
+** checkValue appends a drift to the list if the deviation is above the tolerance.
+**************************************************************************************************/
+func checkValue(drifts []TStateDrift, chainID uint64, address string, field string, stored *bigNumber.Int, onChain *bigNumber.Int, tolerance float64) []TStateDrift {
+	deviation := computeDeviation(stored, onChain)
+	if deviation <= tolerance {
+		return drifts
+	}
+	if stored == nil {
+		stored = bigNumber.NewInt(0)
+	}
+	return append(drifts, TStateDrift{
+		ChainID:   chainID,
+		Address:   address,
+		Field:     field,
+		Stored:    stored.String(),
+		OnChain:   onChain.String(),
+		Deviation: deviation,
+	})
+}
+
+/**************************************************************************************************
+** isV3Version tells if a vault or strategy API version is a v3 one, the `~3` versions included.
+**************************************************************************************************/
+func isV3Version(version string) bool {
+	versionMajor := strings.Split(version, `.`)[0]
+	return versionMajor == `3` || versionMajor == `~3`
+}
+
+/**************************************************************************************************
+** sampleVaults returns up to `size` random active vaults of the chain.
+**************************************************************************************************/
+func sampleVaults(chainID uint64, size int) []models.TVault {
+	_, allVaults := storage.ListVaults(chainID)
+	activeVaults := []models.TVault{}
+	for _, vault := range allVaults {
+		if !models.IsVaultRetired(vault) {
+			activeVaults = append(activeVaults, vault)
+		}
+	}
+	rand.Shuffle(len(activeVaults), func(i, j int) {
+		activeVaults[i], activeVaults[j] = activeVaults[j], activeVaults[i]
+	})
+	if len(activeVaults) > size {
+		activeVaults = activeVaults[:size]
+	}
+	return activeVaults
+}
+
+/**************************************************************************************************
+** getVerificationCalls prepares the calls to re-read the fees, price per share, total assets and
+** strategies debts of a vault. The calls use the same keys as the regular refresh so the responses
+** can be decoded with the same handlers.
+**************************************************************************************************/
+func getVerificationCalls(vault models.TVault, strategies []models.TStrategy) []ethereum.Call {
+	calls := []ethereum.Call{}
+	calls = append(calls, multicalls.GetPricePerShare(vault.Address.Hex(), vault.Address))
+	calls = append(calls, multicalls.GetTotalAssets(vault.Address.Hex(), vault.Address))
+
+	isV3 := isV3Version(vault.Version)
+	if !isV3 {
+		calls = append(calls, multicalls.GetPerformanceFee(vault.Address.Hex(), vault.Address))
+		calls = append(calls, multicalls.GetManagementFee(vault.Address.Hex(), vault.Address))
+	} else if vault.Kind == models.VaultKindSingle {
+		calls = append(calls, multicalls.GetPerformanceFee(vault.Address.Hex(), vault.Address))
+	} else if vault.Kind == models.VaultKindMultiple && vault.Accountant != nil {
+		calls = append(calls, multicalls.GetDefaultFeeConfig(vault.Address.Hex(), *vault.Accountant))
+	}
+
+	for _, strategy := range strategies {
+		strategyKey := strategy.Address.Hex() + `_` + strategy.VaultAddress.Hex()
+		if isV3 {
+			calls = append(calls, multicalls.GetV3Strategies(strategyKey, strategy.VaultAddress, strategy.Address, strategy.VaultVersion))
+		} else {
+			calls = append(calls, multicalls.GetStrategies(strategyKey, strategy.VaultAddress, strategy.Address, strategy.VaultVersion))
+		}
+	}
+	return calls
+}
+
+/**************************************************************************************************
+** VerifyChainState re-reads the on-chain state of a random sample of vaults of a chain and
+** compares it with the stored state: fees, price per share, total assets and strategies debts.
+** The drifts beyond the tolerance are logged and forwarded to OnStateDrift. This is meant to catch
+** silent hydration bugs before the users notice wrong numbers.
+**************************************************************************************************/
+func VerifyChainState(chainID uint64) []TStateDrift {
+	drifts := []TStateDrift{}
+	for _, vault := range sampleVaults(chainID, VERIFICATION_SAMPLE_SIZE) {
+		_, strategies := storage.ListStrategiesForVault(chainID, vault.Address)
+		activeStrategies := []models.TStrategy{}
+		for _, strategy := range strategies {
+			if !strategy.IsRetired {
+				activeStrategies = append(activeStrategies, strategy)
+			}
+		}
+
+		response := multicalls.Perform(chainID, getVerificationCalls(vault, activeStrategies), nil)
+		if len(response[vault.Address.Hex()+`totalAssets`]) == 0 {
+			continue // The vault could not be read, nothing to compare with
+		}
+
+		onChainVault := vault
+		if isV3Version(vault.Version) {
+			onChainVault = handleV3VaultCalls(vault, response)
+		} else {
+			onChainVault = handleV2VaultCalls(vault, response)
+		}
+
+		address := vault.Address.Hex()
+		drifts = checkValue(drifts, chainID, address, `pricePerShare`, vault.LastPricePerShare, onChainVault.LastPricePerShare, VERIFICATION_TOLERANCE)
+		if kongTotalAssets, ok := storage.GetKongTotalAssets(chainID, vault.Address); ok {
+			drifts = checkValue(drifts, chainID, address, `totalAssets`, kongTotalAssets, onChainVault.LastTotalAssets, VERIFICATION_TOLERANCE)
+		}
+		drifts = checkValue(drifts, chainID, address, `performanceFee`, bigNumber.NewUint64(vault.PerformanceFee), bigNumber.NewUint64(onChainVault.PerformanceFee), 0)
+		drifts = checkValue(drifts, chainID, address, `managementFee`, bigNumber.NewUint64(vault.ManagementFee), bigNumber.NewUint64(onChainVault.ManagementFee), 0)
+
+		for _, strategy := range activeStrategies {
+			strategyKey := strategy.Address.Hex() + `_` + strategy.VaultAddress.Hex()
+			if len(response[strategyKey+`strategies`]) == 0 {
+				continue
+			}
+			onChainStrategy := strategy
+			if isV3Version(vault.Version) {
+				onChainStrategy = handleV3StrategyCalls(strategy, response)
+			} else {
+				onChainStrategy = handleV2StrategyCalls(strategy, response)
+			}
+			drifts = checkValue(drifts, chainID, strategy.Address.Hex(), `totalDebt`, strategy.LastTotalDebt, onChainStrategy.LastTotalDebt, VERIFICATION_TOLERANCE)
+		}
+	}
+
+	for _, drift := range drifts {
+		logs.Warning(fmt.Sprintf("🔍 [VERIFY] drift chain=%d address=%s field=%s stored=%s onchain=%s deviation=%.4f",
+			drift.ChainID, drift.Address, drift.Field, drift.Stored, drift.OnChain, drift.Deviation))
+	}
+	if len(drifts) > 0 && OnStateDrift != nil {
+		OnStateDrift(chainID, drifts)
+	}
+	return drifts
+}
@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The data of a chain lags behind the head of its RPC by the time since its data processes last
+** started. The responses of a chain lagging more than DATA_LAG_THRESHOLD are labeled with their
+** freshness, and an alert is raised. The lag is measured every FRESHNESS_CHECK_INTERVAL, on the
+** processes the vault responses are built from.
+**************************************************************************************************/
+const (
+	DATA_LAG_THRESHOLD       = time.Hour
+	FRESHNESS_CHECK_INTERVAL = 5 * time.Minute
+)
+
+var FRESHNESS_PROCESSES = []string{`hydration.vaults`, `pricing`, `tvl`, `apr`}
+
+/**************************************************************************************************
+** OnChainLagging is called when the data of a chain starts lagging more than DATA_LAG_THRESHOLD,
+** and OnChainCaughtUp when it is back under it.
+**************************************************************************************************/
+var OnChainLagging func(chainID uint64, freshness storage.TDataFreshness)
+var OnChainCaughtUp func(chainID uint64, freshness storage.TDataFreshness)
+
+/**************************************************************************************************
+** recordProcessBlock records the block a data process of a chain started from, once it is done.
+** The block is read before the process runs: the data it reads is at least as recent.
+**************************************************************************************************/
+func recordProcessBlock(chainID uint64, process string, run func()) {
+	blockNumber, err := ethereum.GetConfirmedBlockNumber(chainID)
+	run()
+	if err != nil {
+		return
+	}
+	storage.StoreProcessBlock(chainID, process, blockNumber, ethereum.GetBlockTime(chainID, blockNumber))
+}
+
+/**************************************************************************************************
+** measureChainFreshness compares the oldest block of the FRESHNESS_PROCESSES of a chain with the
+** head of its RPC, stores the freshness, and calls the hooks when the chain starts or stops
+** lagging. Nothing is measured until all the processes ran once.
+**************************************************************************************************/
+func measureChainFreshness(chainID uint64) {
+	var oldest storage.TProcessBlock
+	for i, process := range FRESHNESS_PROCESSES {
+		processBlock, ok := storage.GetProcessBlock(chainID, process)
+		if !ok || processBlock.Timestamp == 0 {
+			return
+		}
+		if i == 0 || processBlock.Block < oldest.Block {
+			oldest = processBlock
+		}
+	}
+
+	headBlock, err := ethereum.GetConfirmedBlockNumber(chainID)
+	if err != nil {
+		logs.Warning(fmt.Sprintf("⏱️ [FRESHNESS] failed to read the head chain=%d: %v", chainID, err))
+		return
+	}
+	headTime := ethereum.GetBlockTime(chainID, headBlock)
+	if headTime == 0 {
+		return
+	}
+
+	freshness := storage.TDataFreshness{Block: oldest.Block, Timestamp: oldest.Timestamp}
+	if headTime > oldest.Timestamp {
+		freshness.LagSeconds = headTime - oldest.Timestamp
+	}
+	lagThreshold := DATA_LAG_THRESHOLD
+	if env.IsSunsetChain(chainID) {
+		lagThreshold += SUNSET_REFRESH_INTERVAL // Refreshed hourly, its data is expected to be older
+	}
+	isLagging := time.Duration(freshness.LagSeconds)*time.Second > lagThreshold
+	_, wasLagging := storage.GetChainFreshness(chainID)
+	storage.StoreChainFreshness(chainID, freshness, isLagging)
+
+	if isLagging && !wasLagging {
+		logs.Warning(fmt.Sprintf("⏱️ [FRESHNESS] data lagging chain=%d block=%d lag=%ds", chainID, freshness.Block, freshness.LagSeconds))
+		if OnChainLagging != nil {
+			OnChainLagging(chainID, freshness)
+		}
+	} else if !isLagging && wasLagging {
+		logs.Info(fmt.Sprintf("⏱️ [FRESHNESS] data caught up chain=%d block=%d lag=%ds", chainID, freshness.Block, freshness.LagSeconds))
+		if OnChainCaughtUp != nil {
+			OnChainCaughtUp(chainID, freshness)
+		}
+	}
+}
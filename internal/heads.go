@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** Between the snapshots, the price per share and the total assets of the vaults are read again
+** every HEAD_REFRESH_BLOCKS new heads of the chain, with a single multicall, for the share prices
+** and the TVLs to track the chain within seconds. The heavy jobs (events, APRs) stay on their
+** schedule. A refresh still running when the next one is due is not doubled, and the refreshes
+** are skipped while the chain is paused, its sequencer is down or its RPC endpoints are dead.
+**
+** IsHeadRefreshEnabled returns true if the vaults of a chain are refreshed on its new heads: it
+** must be enabled with HEAD_REFRESH_BLOCKS and the chain able to use websockets.
+**************************************************************************************************/
+func IsHeadRefreshEnabled(chainID uint64) bool {
+	chain, ok := env.GetChain(chainID)
+	return env.HEAD_REFRESH_BLOCKS > 0 && ok && chain.CanUseWebsocket
+}
+
+/**************************************************************************************************
+** WatchHeads subscribes to the new heads of a chain and refreshes its vaults every
+** HEAD_REFRESH_BLOCKS blocks. The subscription is opened again after a failure, the websocket
+** client being reconnected. It never returns, and is meant to run in its own goroutine.
+**************************************************************************************************/
+func WatchHeads(chainID uint64) {
+	if !IsHeadRefreshEnabled(chainID) {
+		return
+	}
+	running := &atomic.Bool{}
+
+	chainIDStr := strconv.FormatUint(chainID, 10)
+	retryDelay := time.Second
+	for {
+		client, err := ethereum.GetWSClient(chainID, true)
+		if err != nil {
+			time.Sleep(retryDelay)
+			retryDelay = min(retryDelay*2, time.Minute)
+			continue
+		}
+
+		heads := make(chan *types.Header, 16)
+		subscription, err := client.SubscribeNewHead(context.Background(), heads)
+		if err != nil {
+			logs.Error(`Failed to watch the heads of chain ` + chainIDStr + `: ` + err.Error())
+			// The websocket may be closed: the client is closed and dropped for GetWSClient to open a new one
+			ethereum.ResetWSClient(chainID)
+			time.Sleep(retryDelay)
+			retryDelay = min(retryDelay*2, time.Minute)
+			continue
+		}
+		logs.Info(`Watching the heads of chain ` + chainIDStr)
+		retryDelay = time.Second
+
+		func() {
+			defer subscription.Unsubscribe()
+			for {
+				select {
+				case err := <-subscription.Err():
+					if err != nil {
+						logs.Warning(`Heads subscription of chain ` + chainIDStr + ` closed: ` + err.Error())
+					}
+					return
+				case head := <-heads:
+					if head == nil || head.Number == nil || head.Number.Uint64()%env.HEAD_REFRESH_BLOCKS != 0 {
+						continue
+					}
+					if IsChainPaused(chainID) || storage.IsSequencerDown(chainID) || storage.IsRPCDown(chainID) {
+						continue
+					}
+					if running.CompareAndSwap(false, true) {
+						go func(blockNumber uint64) {
+							defer running.Store(false)
+							refreshVaultsOnHead(chainID, blockNumber)
+						}(head.Number.Uint64())
+					}
+				}
+			}
+		}()
+	}
+}
+
+/**************************************************************************************************
+** refreshVaultsOnHead reads the price per share and the total assets of the vaults of a chain at
+** a block. The price per share is stored on the vault, the total assets as its live state, used to
+** bring its TVL up to date. A vault whose calls failed keeps its values. The price per share is
+** updated on the current vault, serialized with the stores of the fetcher (see storage.UpdateVault).
+**************************************************************************************************/
+func refreshVaultsOnHead(chainID uint64, blockNumber uint64) int {
+	_, vaults := storage.ListVaults(chainID)
+	calls := []ethereum.Call{}
+	for _, vault := range vaults {
+		calls = append(calls, multicalls.GetPricePerShare(vault.Address.Hex(), vault.Address))
+		calls = append(calls, multicalls.GetTotalAssets(vault.Address.Hex(), vault.Address))
+	}
+	if len(calls) == 0 {
+		return 0
+	}
+	response := multicalls.Perform(chainID, calls, big.NewInt(int64(blockNumber)))
+
+	refreshed := 0
+	now := time.Now()
+	for _, vault := range vaults {
+		key := vault.Address.Hex()
+		if rawTotalAssets := response[key+`totalAssets`]; len(rawTotalAssets) > 0 {
+			storage.StoreLiveVaultState(chainID, vault.Address, storage.TLiveVaultState{
+				TotalAssets: helpers.DecodeBigInt(rawTotalAssets),
+				BlockNumber: blockNumber,
+				UpdatedAt:   now,
+			})
+			refreshed++
+		}
+		if rawPricePerShare := response[key+`pricePerShare`]; len(rawPricePerShare) > 0 {
+			pricePerShare := helpers.DecodeBigInt(rawPricePerShare)
+			if pricePerShare.IsZero() {
+				continue
+			}
+			storage.UpdateVault(chainID, vault.Address, func(current *models.TVault) bool {
+				if current.LastPricePerShare != nil && current.LastPricePerShare.Eq(pricePerShare) {
+					return false
+				}
+				current.LastPricePerShare = pricePerShare
+				return true
+			})
+		}
+	}
+	logs.Debug(`⛓️ [HEADS] refreshed chain=` + strconv.FormatUint(chainID, 10) + ` block=` + strconv.FormatUint(blockNumber, 10) + ` vaults=` + strconv.Itoa(refreshed))
+	return refreshed
+}
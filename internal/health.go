@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The RPC endpoints of every chain are checked every RPC_HEALTH_CHECK_INTERVAL. When none of them
+** answers for env.RPC_DEAD_WINDOW, the chain is degraded: its refreshes are skipped instead of
+** failing and logging errors against a dead node, and its responses are labeled as potentially
+** stale. The chain is re-enabled as soon as one of its endpoints answers again.
+**************************************************************************************************/
+const RPC_HEALTH_CHECK_INTERVAL = time.Minute
+
+/**************************************************************************************************
+** OnChainDegraded is called when a chain is degraded because its RPC endpoints are dead, and
+** OnChainRecovered when one of them is back.
+**************************************************************************************************/
+var OnChainDegraded func(chainID uint64, health storage.TRPCHealth)
+var OnChainRecovered func(chainID uint64, health storage.TRPCHealth)
+
+/**************************************************************************************************
+** checkRPCHealth checks the RPC endpoints of a chain, stores their health, and degrades the chain
+** or re-enables it, calling the hooks, when they have been failing for the dead window or one of
+** them answers again.
+**************************************************************************************************/
+func checkRPCHealth(chainID uint64) {
+	now := uint64(time.Now().Unix())
+	health, _ := storage.GetRPCHealth(chainID)
+	wasDegraded := health.IsDegraded
+	health.CheckedAt = now
+
+	if ethereum.IsAnyRPCHealthy(chainID) {
+		if health.FailingSince != 0 && !wasDegraded {
+			logs.Info(fmt.Sprintf("🩺 [RPC] endpoints answering again chain=%d", chainID))
+		}
+		health.IsDegraded = false
+		health.FailingSince = 0
+		health.LastHealthyAt = now
+	} else {
+		if health.FailingSince == 0 {
+			health.FailingSince = now
+			logs.Warning(fmt.Sprintf("🩺 [RPC] all endpoints failing chain=%d", chainID))
+		}
+		health.IsDegraded = time.Duration(now-health.FailingSince)*time.Second >= env.RPC_DEAD_WINDOW
+	}
+	storage.StoreRPCHealth(chainID, health)
+
+	if health.IsDegraded && !wasDegraded {
+		logs.Error(fmt.Sprintf("🩺 [RPC] chain=%d degraded: endpoints dead since %d", chainID, health.FailingSince))
+		if OnChainDegraded != nil {
+			OnChainDegraded(chainID, health)
+		}
+	} else if !health.IsDegraded && wasDegraded {
+		logs.Info(fmt.Sprintf("🩺 [RPC] chain=%d re-enabled: endpoints back", chainID))
+		if OnChainRecovered != nil {
+			OnChainRecovered(chainID, health)
+		}
+	}
+}
+
+/**************************************************************************************************
+** skipWhileRPCDown returns true, logging it, when a job of a chain must be skipped because the
+** chain is degraded.
+**************************************************************************************************/
+func skipWhileRPCDown(chainID uint64, name string) bool {
+	if !storage.IsRPCDown(chainID) {
+		return false
+	}
+	logs.Info(fmt.Sprintf("🩺 [RPC] job=%s skipped chain=%d: RPC endpoints dead", name, chainID))
+	return true
+}
@@ -111,74 +111,27 @@ func handleV02ExperimentalVault(chainID uint64, value *contracts.YRegistryV2NewE
 }
 
 /**************************************************************************************************
-** The different versions of the strategies have different events. We need to handle them
-** differently.
-** The following functions are helpers used to handle that.
+** The strategy events of the different versions of the vaults are mapped to a TEventBlock by the
+** vault bindings (see vaults.bindings.go). The following functions are helpers used to turn them
+** into the stored models.
 **************************************************************************************************/
-func handleV02Strategies(chainID uint64, vaultVersion string, value *contracts.Yvault022StrategyAdded) models.TStrategy {
-	newStrategy := models.TStrategy{
-		Address:      value.Strategy,
-		ChainID:      chainID,
-		VaultVersion: vaultVersion,
-		VaultAddress: value.Raw.Address,
-		Activation:   value.Raw.BlockNumber,
-	}
-	return newStrategy
-}
-func handleV03Strategies(chainID uint64, vaultVersion string, value *contracts.Yvault030StrategyAdded) models.TStrategy {
-	newStrategy := models.TStrategy{
-		Address:      value.Strategy,
-		ChainID:      chainID,
-		VaultVersion: vaultVersion,
-		VaultAddress: value.Raw.Address,
-		Activation:   value.Raw.BlockNumber,
-	}
-	return newStrategy
-}
-func handleV04Strategies(chainID uint64, vaultVersion string, value *contracts.Yvault043StrategyAdded) models.TStrategy {
-	newStrategy := models.TStrategy{
-		Address:      value.Strategy,
-		ChainID:      chainID,
-		VaultVersion: vaultVersion,
-		VaultAddress: value.Raw.Address,
-		Activation:   value.Raw.BlockNumber,
-	}
-	return newStrategy
-}
-func handleV300Strategies(chainID uint64, vaultVersion string, value *contracts.Yvault300StrategyChanged) models.TStrategy {
+func handleStrategyAddedEvent(event models.TEventBlock) models.TStrategy {
 	newStrategy := models.TStrategy{
-		Address:      value.Strategy,
-		ChainID:      chainID,
-		VaultVersion: vaultVersion,
-		VaultAddress: value.Raw.Address,
-		Activation:   value.Raw.BlockNumber,
-	}
-	return newStrategy
-}
-
-/**************************************************************************************************
-** The different versions of the strategies have different events. We need to handle them
-** differently. This is also valid for the migration.
-** V2 strategies have no migration.
-** The following functions are helpers used to handle that.
-**************************************************************************************************/
-func handleV03StrategiesMigration(chainID uint64, value *contracts.Yvault030StrategyMigrated) models.TStrategyMigrated {
-	newStrategy := models.TStrategyMigrated{
-		ChainID:            chainID,
-		VaultAddress:       value.Raw.Address,
-		OldStrategyAddress: value.OldVersion,
-		NewStrategyAddress: value.NewVersion,
-		BlockNumber:        value.Raw.BlockNumber,
+		Address:      event.StrategyAddress,
+		ChainID:      event.ChainID,
+		VaultVersion: event.VaultVersion,
+		VaultAddress: event.VaultAddress,
+		Activation:   event.BlockNumber,
 	}
 	return newStrategy
 }
-func handleV04StrategiesMigration(chainID uint64, value *contracts.Yvault043StrategyMigrated) models.TStrategyMigrated {
+func handleStrategyMigratedEvent(event models.TEventBlock) models.TStrategyMigrated {
 	newStrategy := models.TStrategyMigrated{
-		ChainID:            chainID,
-		VaultAddress:       value.Raw.Address,
-		OldStrategyAddress: value.OldVersion,
-		NewStrategyAddress: value.NewVersion,
-		BlockNumber:        value.Raw.BlockNumber,
+		ChainID:            event.ChainID,
+		VaultAddress:       event.VaultAddress,
+		OldStrategyAddress: event.StrategyAddress,
+		NewStrategyAddress: event.NewStrategyAddress,
+		BlockNumber:        event.BlockNumber,
 	}
 	return newStrategy
 }
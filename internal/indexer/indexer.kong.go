@@ -41,46 +41,12 @@ func IndexNewVaults(chainID uint64) map[common.Address]models.TVaultsFromRegistr
 		storage.StoreNewVaultToRegistry(chainID, vault)
 
 		// Store Kong APY data from GraphQL API (single source of truth for APY calculations)
-		// Convert KongDebt to TKongDebt
-		var debts []models.TKongDebt
-		for _, debt := range data.Debts {
-			debts = append(debts, models.TKongDebt{
-				Strategy:           debt.Strategy,
-				PerformanceFee:     debt.PerformanceFee,
-				Activation:         debt.Activation,
-				DebtRatio:          debt.DebtRatio,
-				MinDebtPerHarvest:  debt.MinDebtPerHarvest,
-				MaxDebtPerHarvest:  debt.MaxDebtPerHarvest,
-				LastReport:         debt.LastReport,
-				TotalDebt:          debt.TotalDebt,
-				TotalDebtUsd:       debt.TotalDebtUsd,
-				TotalGain:          debt.TotalGain,
-				TotalGainUsd:        debt.TotalGainUsd,
-				TotalLoss:          debt.TotalLoss,
-				TotalLossUsd:       debt.TotalLossUsd,
-				CurrentDebt:        debt.CurrentDebt,
-				CurrentDebtUsd:     debt.CurrentDebtUsd,
-				MaxDebt:            debt.MaxDebt,
-				MaxDebtUsd:         debt.MaxDebtUsd,
-				TargetDebtRatio:    debt.TargetDebtRatio,
-				MaxDebtRatio:       debt.MaxDebtRatio,
-			})
-		}
-		
-		kongSchema := models.TKongVaultSchema{
-			ManagementFee:  data.Vault.GetManagementFee(),
-			PerformanceFee: data.Vault.GetPerformanceFee(),
-			APY: data.APY,
-			Debts: debts,
-			TVL: data.Vault.GetTVL(),
-			TotalAssets: data.TotalAssets,
-			StrategyAddresses: data.Vault.GetStrategies(),
-		}
+		kongSchema := data.ToVaultSchema()
 		storage.StoreKongVaultData(chainID, vaultAddr, kongSchema)
 		
 		// Log if debts were found for debugging
-		if len(debts) > 0 {
-			logs.Info(chainID, `-`, `Stored %d debts for vault %s`, len(debts), vaultAddr.Hex())
+		if len(kongSchema.Debts) > 0 {
+			logs.Info(chainID, `-`, `Stored %d debts for vault %s`, len(kongSchema.Debts), vaultAddr.Hex())
 		}
 	}
 	
@@ -43,19 +43,24 @@ func filterNewVault(
 
 	/**********************************************************************************************
 	** First, we need to know when to stop our log fetching. By default, we will fetch until the
-	** current block number, aka nil.
-	** As using nil may cause some issues, we will specify the current block number instead.
+	** last confirmed block, aka the current block number minus the chain confirmations.
+	** The unconfirmed blocks are left out and will be scanned again on the next pass, preventing
+	** us from indexing events that may later be reorged.
 	**********************************************************************************************/
 	if end == nil {
-		blockEnd, _ := client.BlockNumber(context.Background())
+		blockEnd, err := ethereum.GetConfirmedBlockNumber(chainID)
+		if err != nil {
+			blockEnd, _ = client.BlockNumber(context.Background())
+		}
 		end = &blockEnd
 	}
 	
 	blockRange := *end - start
 	logs.Info(`Scanning registry ` + registry.Address.Hex() + ` from block ` + strconv.FormatUint(start, 10) + ` to ` + strconv.FormatUint(*end, 10) + ` (` + strconv.FormatUint(blockRange, 10) + ` blocks)`)
 
-	for chunkStart := start; chunkStart < *end; chunkStart += chain.MaxBlockRange {
-		chunkEnd := chunkStart + chain.MaxBlockRange
+	logsRange := chain.GetLogsRange()
+	for chunkStart := start; chunkStart < *end; chunkStart += logsRange {
+		chunkEnd := chunkStart + logsRange
 		if chunkEnd > *end {
 			chunkEnd = *end
 			lastBlock = chunkEnd
@@ -212,6 +217,9 @@ func watchNewVaults(
 			return 0, false, err
 		}
 		defer sub.Unsubscribe()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream, history = ethereum.ConfirmLogs(ctx, chainID, stream, history)
 
 		/** 🔵 - Yearn *************************************************************************************
 		** Handle historical events
@@ -281,6 +289,9 @@ func watchNewVaults(
 			return 0, false, err
 		}
 		defer sub.Unsubscribe()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream, history = ethereum.ConfirmLogs(ctx, chainID, stream, history)
 
 		/** 🔵 - Yearn *************************************************************************************
 		** Handle historical events
@@ -337,6 +348,9 @@ func watchNewVaults(
 			return 0, false, err
 		}
 		defer sub.Unsubscribe()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream, history = ethereum.ConfirmLogs(ctx, chainID, stream, history)
 
 		/** 🔵 - Yearn *************************************************************************************
 		** Handle historical events
@@ -394,6 +408,9 @@ func watchNewVaults(
 			return 0, false, err
 		}
 		defer sub.Unsubscribe()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream, history = ethereum.ConfirmLogs(ctx, chainID, stream, history)
 
 		/** 🔵 - Yearn *************************************************************************************
 		** Handle historical events
@@ -450,6 +467,9 @@ func watchNewVaults(
 			return 0, false, err
 		}
 		defer sub.Unsubscribe()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream, history = ethereum.ConfirmLogs(ctx, chainID, stream, history)
 
 		/** 🔵 - Yearn *************************************************************************************
 		** Handle historical events
@@ -595,7 +615,7 @@ func IndexNewVaultsFromRegistries(chainID uint64) (vaultsFromRegistry map[common
 		vaultsFromRegistry, _ = storage.ListVaultsFromRegistries(chainID)
 		return vaultsFromRegistry
 	}
-	if chainID == 100 {
+	if chain.Capabilities.SkipsRegistryIndexing {
 		vaultsFromRegistry, _ = storage.ListVaultsFromRegistries(chainID)
 		return vaultsFromRegistry
 	}
@@ -8,15 +8,13 @@ import (
 	"time"
 
 	goEth "github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/yearn/ydaemon/common/addresses"
-	"github.com/yearn/ydaemon/common/contracts"
 	"github.com/yearn/ydaemon/common/env"
 	"github.com/yearn/ydaemon/common/ethereum"
 	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/backfill"
 	"github.com/yearn/ydaemon/internal/fetcher"
 	"github.com/yearn/ydaemon/internal/models"
 	"github.com/yearn/ydaemon/internal/storage"
@@ -25,7 +23,42 @@ import (
 var _strategiesAlreadyIndexingForVaults = make(map[uint64]*sync.Map)
 
 /**************************************************************************************************
-** listStrategiesForVault...
+** handleVaultEvent stores the strategy added or migrated by a vault event. When shouldRefresh is
+** true, the new strategy is also fetched right away, otherwise it will be with the next batch.
+**************************************************************************************************/
+func handleVaultEvent(chainID uint64, event models.TEventBlock, shouldRefresh bool) {
+	switch event.EventType {
+	case models.VaultEventStrategyAdded:
+		newStrategy := handleStrategyAddedEvent(event)
+		if storage.StoreStrategyIfMissing(chainID, newStrategy) && shouldRefresh {
+			strategyKey := newStrategy.Address.Hex() + `_` + newStrategy.VaultAddress.Hex()
+			fetcher.RetrieveAllStrategies(chainID, map[string]models.TStrategy{
+				strategyKey: newStrategy,
+			})
+		}
+	case models.VaultEventStrategyMigrated:
+		newMigratedStrategy := handleStrategyMigratedEvent(event)
+		storage.StoreStrategyMigrated(chainID, newMigratedStrategy)
+		if !shouldRefresh {
+			return
+		}
+		processMigrations(chainID)
+		if newStrategy, ok := storage.GetStrategy(
+			chainID,
+			newMigratedStrategy.NewStrategyAddress,
+			newMigratedStrategy.VaultAddress,
+		); ok {
+			strategyKey := newStrategy.Address.Hex() + `_` + newStrategy.VaultAddress.Hex()
+			fetcher.RetrieveAllStrategies(chainID, map[string]models.TStrategy{
+				strategyKey: newStrategy,
+			})
+		}
+	}
+}
+
+/**************************************************************************************************
+** listStrategiesForVault reads the strategies in the queue of the vault: the withdrawal queue for
+** the v2 vaults, the default queue for the v3 ones.
 **************************************************************************************************/
 func listStrategiesForVault(
 	chainID uint64,
@@ -49,64 +82,31 @@ func listStrategiesForVault(
 	case `0.2.2`, `0.3.0`, `0.3.1`, `0.3.2`, `0.3.3`, `0.3.4`, `0.3.5`, `0.4.2`, `0.4.3`:
 		/******************************************************************************************
 		** Vaults versions from 0.2.2 to 0.4.3 are now deprecated and should not be used anymore.
-		** This is only for historical purposes.
-		** The strategies indexer will not run for this version.
+		** The strategies indexer will not run for these versions.
 		******************************************************************************************/
-		break // UNBREAK THIS TO RUN THE INDEXER FOR THESE VERSIONS
-		currentVault, _ := contracts.NewYvault043(vault.Address, client)
-		for i := 0; i < 10; i++ {
-			indexedStrategy, err := currentVault.WithdrawalQueue(nil, big.NewInt(int64(i)))
-			if addresses.Equals(indexedStrategy, common.Address{}) {
-				break
-			}
-			if err != nil {
-				continue
-			}
-			strategies = append(strategies, models.TStrategy{
-				Address:      indexedStrategy,
-				ChainID:      chainID,
-				VaultVersion: vault.Version,
-				VaultAddress: vault.Address,
-				Activation:   vault.Activation,
-			})
-		}
-	case `0.4.4`, `0.4.5`, `0.4.6`, `0.4.7`:
-		currentVault, _ := contracts.NewYvault043(vault.Address, client)
-		for i := 0; i < 10; i++ {
-			indexedStrategy, err := currentVault.WithdrawalQueue(nil, big.NewInt(int64(i)))
-			if addresses.Equals(indexedStrategy, common.Address{}) {
-				break
-			}
-			if err != nil {
-				continue
-			}
-			strategies = append(strategies, models.TStrategy{
-				Address:      indexedStrategy,
-				ChainID:      chainID,
-				VaultVersion: vault.Version,
-				VaultAddress: vault.Address,
-				Activation:   vault.Activation,
-			})
+		return strategies
+	}
+
+	binding, err := getVaultBinding(chainID, vault, client)
+	if err != nil {
+		logs.Error(`impossible to bind vault ` + vault.Address.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+		return strategies
+	}
+	for i := int64(0); i < 10; i++ {
+		indexedStrategy, err := binding.Queue(i)
+		if addresses.Equals(indexedStrategy, common.Address{}) {
+			break
 		}
-	default:
-		// case `3.0.0`, `3.0.1`, `3.0.2`:
-		currentVault, _ := contracts.NewYvault300(vault.Address, client)
-		for i := 0; i < 10; i++ {
-			indexedStrategy, err := currentVault.DefaultQueue(nil, big.NewInt(int64(i)))
-			if addresses.Equals(indexedStrategy, common.Address{}) {
-				break
-			}
-			if err != nil {
-				continue
-			}
-			strategies = append(strategies, models.TStrategy{
-				Address:      indexedStrategy,
-				ChainID:      chainID,
-				VaultVersion: vault.Version,
-				VaultAddress: vault.Address,
-				Activation:   vault.Activation,
-			})
+		if err != nil {
+			continue
 		}
+		strategies = append(strategies, models.TStrategy{
+			Address:      indexedStrategy,
+			ChainID:      chainID,
+			VaultVersion: vault.Version,
+			VaultAddress: vault.Address,
+			Activation:   vault.Activation,
+		})
 	}
 	return strategies
 }
@@ -132,19 +132,31 @@ func filterNewStrategies(
 	if !ok {
 		return 0
 	}
+	binding, err := getVaultBinding(chainID, vault, client)
+	if err != nil {
+		logs.Error(`impossible to bind vault ` + vault.Address.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+		return 0
+	}
 
 	/**********************************************************************************************
 	** First, we need to know when to stop our log fetching. By default, we will fetch until the
-	** current block number, aka nil.
-	** As using nil may cause some issues, we will specify the current block number instead.
+	** last confirmed block, aka the current block number minus the chain confirmations.
+	** The unconfirmed blocks are left out and will be scanned again on the next pass, preventing
+	** us from indexing events that may later be reorged.
 	**********************************************************************************************/
 	if end == nil {
-		blockEnd, _ := client.BlockNumber(context.Background())
+		blockEnd, err := ethereum.GetConfirmedBlockNumber(chainID)
+		if err != nil {
+			blockEnd, _ = client.BlockNumber(context.Background())
+		}
 		end = &blockEnd
 	}
 
-	for chunkStart := start; chunkStart < *end; chunkStart += chain.MaxBlockRange {
-		chunkEnd := chunkStart + chain.MaxBlockRange
+	logsRange := chain.GetLogsRange()
+	scan := backfill.StartScan(chainID, `strategies`, start, *end, logsRange)
+	defer scan.Finish()
+	for chunkStart := start; chunkStart < *end; chunkStart += logsRange {
+		chunkEnd := chunkStart + logsRange
 		if chunkEnd > *end {
 			chunkEnd = *end
 			lastBlock = chunkEnd
@@ -153,149 +165,30 @@ func filterNewStrategies(
 		if chunkEnd >= *end && !isDone && wg != nil {
 			wg.Done()
 		}
-		opts := &bind.FilterOpts{Start: chunkStart, End: &chunkEnd}
-
-		switch vault.Version {
-		case `0.2.2`:
-			currentVault, _ := contracts.NewYvault022(vault.Address, client)
-			if log, err := currentVault.FilterStrategyAdded(opts, nil); err == nil {
-				for log.Next() {
-					if log.Error() != nil {
-						continue
-					}
-					newStrategy := handleV02Strategies(chainID, vault.Version, log.Event)
-					if storage.StoreStrategyIfMissing(chainID, newStrategy) {
-						strategyKey := newStrategy.Address.Hex() + `_` + newStrategy.VaultAddress.Hex()
-						fetcher.RetrieveAllStrategies(chainID, map[string]models.TStrategy{
-							strategyKey: newStrategy,
-						})
-					}
-				}
-			} else {
-				logs.Error(`impossible to FilterStrategyAdded for NewYvault022 ` + vault.Address.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
-			}
-		case `0.3.0`, `0.3.1`:
-			currentVault, _ := contracts.NewYvault030(vault.Address, client)
-			if log, err := currentVault.FilterStrategyAdded(opts, nil); err == nil {
-				for log.Next() {
-					if log.Error() != nil {
-						continue
-					}
-					newStrategy := handleV03Strategies(chainID, vault.Version, log.Event)
-					if storage.StoreStrategyIfMissing(chainID, newStrategy) {
-						strategyKey := newStrategy.Address.Hex() + `_` + newStrategy.VaultAddress.Hex()
-						fetcher.RetrieveAllStrategies(chainID, map[string]models.TStrategy{
-							strategyKey: newStrategy,
-						})
-					}
-				}
-			} else {
-				logs.Error(`impossible to FilterStrategyAdded for NewYvault030 ` + vault.Address.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
-			}
 
-			if log, err := currentVault.FilterStrategyMigrated(opts, nil, nil); err == nil {
-				for log.Next() {
-					if log.Error() != nil {
-						continue
-					}
-					newMigratedStrategy := handleV03StrategiesMigration(chainID, log.Event)
-					storage.StoreStrategyMigrated(chainID, newMigratedStrategy)
-					processMigrations(chainID)
-					if newStrategy, ok := storage.GetStrategy(
-						chainID,
-						newMigratedStrategy.NewStrategyAddress,
-						newMigratedStrategy.VaultAddress,
-					); ok {
-						strategyKey := newStrategy.Address.Hex() + `_` + newStrategy.VaultAddress.Hex()
-						fetcher.RetrieveAllStrategies(chainID, map[string]models.TStrategy{
-							strategyKey: newStrategy,
-						})
-					}
-				}
-			} else {
-				logs.Error(`impossible to FilterStrategyMigrated for NewYvault030 ` + vault.Address.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
-			}
-		case `0.3.2`, `0.3.3`, `0.3.4`, `0.3.5`, `0.4.2`, `0.4.3`, `0.4.4`, `0.4.5`, `0.4.6`, `0.4.7`:
-			currentVault, _ := contracts.NewYvault043(vault.Address, client)
-			if log, err := currentVault.FilterStrategyAdded(opts, nil); err == nil {
-				for log.Next() {
-					if log.Error() != nil {
-						continue
-					}
-					newStrategy := handleV04Strategies(chainID, vault.Version, log.Event)
-					if storage.StoreStrategyIfMissing(chainID, newStrategy) {
-						strategyKey := newStrategy.Address.Hex() + `_` + newStrategy.VaultAddress.Hex()
-						fetcher.RetrieveAllStrategies(chainID, map[string]models.TStrategy{
-							strategyKey: newStrategy,
-						})
-					}
-				}
-			} else {
-				logs.Error(`impossible to FilterStrategyAdded for NewYvault043 ` + vault.Address.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
-			}
-
-			if log, err := currentVault.FilterStrategyMigrated(opts, nil, nil); err == nil {
-				for log.Next() {
-					if log.Error() != nil {
-						continue
-					}
-					newMigratedStrategy := handleV04StrategiesMigration(chainID, log.Event)
-					storage.StoreStrategyMigrated(chainID, newMigratedStrategy)
-					processMigrations(chainID)
-					if newStrategy, ok := storage.GetStrategy(
-						chainID,
-						newMigratedStrategy.NewStrategyAddress,
-						newMigratedStrategy.VaultAddress,
-					); ok {
-						strategyKey := newStrategy.Address.Hex() + `_` + newStrategy.VaultAddress.Hex()
-						fetcher.RetrieveAllStrategies(chainID, map[string]models.TStrategy{
-							strategyKey: newStrategy,
-						})
-					}
-				}
-			} else {
-				logs.Error(`impossible to FilterStrategyMigrated for NewYvault030 ` + vault.Address.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
-			}
-		default:
-			// case `3.0.0`, `3.0.1`, `3.0.2`:
-			currentVault, _ := contracts.NewYvault300(vault.Address, client)
-			if log, err := currentVault.FilterStrategyChanged(opts, nil, nil); err == nil {
-				for log.Next() {
-					if log.Error() != nil {
-						continue
-					}
-					newStrategy := handleV300Strategies(chainID, vault.Version, log.Event)
-					if storage.StoreStrategyIfMissing(chainID, newStrategy) {
-						strategyKey := newStrategy.Address.Hex() + `_` + newStrategy.VaultAddress.Hex()
-						fetcher.RetrieveAllStrategies(chainID, map[string]models.TStrategy{
-							strategyKey: newStrategy,
-						})
-					}
-				}
-			} else {
-				logs.Error(`impossible to FilterStrategyAdded for NewYvault043 ` + vault.Address.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+		query := goEth.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(chunkStart),
+			ToBlock:   new(big.Int).SetUint64(chunkEnd),
+			Addresses: []common.Address{vault.Address},
+			Topics:    [][]common.Hash{binding.Topics()},
+		}
+		if !scan.Acquire() {
+			if chunkEnd < *end && !isDone && wg != nil {
+				wg.Done()
 			}
-
-			if log, err := currentVault.FilterStrategyChanged(opts, nil, nil); err == nil {
-				for log.Next() {
-					if log.Error() != nil {
-						continue
-					}
-					if log.Event.ChangeType.Uint64() == 1 {
-						historicalStrategy := handleV300Strategies(chainID, vault.Version, log.Event)
-						if storage.StoreStrategyIfMissing(chainID, historicalStrategy) {
-							strategyKey := historicalStrategy.Address.Hex() + `_` + historicalStrategy.VaultAddress.Hex()
-							fetcher.RetrieveAllStrategies(chainID, map[string]models.TStrategy{
-								strategyKey: historicalStrategy,
-							})
-						}
-					}
-				}
-			} else {
-				logs.Error(`impossible to FilterStrategyMigrated for NewYvault030 ` + vault.Address.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+			return chunkStart // Backfills paused, resumed from this chunk on the next pass
+		}
+		history, err := client.FilterLogs(context.Background(), query)
+		scan.Release(err)
+		if err != nil {
+			logs.Error(`impossible to filter the strategy events with ` + binding.Name() + ` for ` + vault.Address.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+			continue
+		}
+		for _, log := range history {
+			if event, ok := binding.Parse(log); ok {
+				handleVaultEvent(chainID, event, true)
 			}
 		}
-
 	}
 	return lastBlock
 }
@@ -311,265 +204,50 @@ func watchNewStrategies(
 	wg *sync.WaitGroup,
 	isDone bool,
 ) (uint64, bool, error) {
-	switch vault.Version {
-	case `0.2.2`:
-		currentVault, _ := contracts.NewYvault022(vault.Address, client)
-		etherReader := ethereum.Reader{Backend: client, ChainID: chainID}
-		contractABI, _ := contracts.Yvault022MetaData.GetAbi()
-		topics, _ := abi.MakeTopics([][]interface{}{{contractABI.Events[`StrategyAdded`].ID}}...)
-		query := goEth.FilterQuery{
-			FromBlock: big.NewInt(int64(lastSyncedBlock)),
-			Addresses: []common.Address{vault.Address},
-			Topics:    topics,
-		}
-		stream, sub, history, err := etherReader.QueryWithHistory(context.Background(), &query)
-		if err != nil {
-			if wg != nil && !isDone {
-				wg.Done()
-			}
-			return 0, false, err
-		}
-		defer sub.Unsubscribe()
-
-		/** 🔵 - Yearn *************************************************************************************
-		** Handle historical events. It's only a storing action as the rest will be performed as a batch,
-		** all the one in the history in one go.
-		**************************************************************************************************/
-		for _, log := range history {
-			if value, err := currentVault.ParseStrategyAdded(log); err == nil {
-				historicalStrategy := handleV02Strategies(chainID, vault.Version, value)
-				storage.StoreStrategyIfMissing(chainID, historicalStrategy)
-			}
-		}
-		if wg != nil && !isDone {
-			wg.Done()
-		}
-
-		/**********************************************************************************************
-		** Listen and handle new events
-		**********************************************************************************************/
-		for {
-			select {
-			case log := <-stream:
-				value, err := currentVault.ParseStrategyAdded(log)
-				if err != nil {
-					continue
-				}
-				lastSyncedBlock = value.Raw.BlockNumber
-				newStrategy := handleV02Strategies(chainID, vault.Version, value)
-				if storage.StoreStrategyIfMissing(chainID, newStrategy) {
-					strategyKey := newStrategy.Address.Hex() + `_` + newStrategy.VaultAddress.Hex()
-					fetcher.RetrieveAllStrategies(chainID, map[string]models.TStrategy{
-						strategyKey: newStrategy,
-					})
-				}
-			case err := <-sub.Err():
-				return lastSyncedBlock, true, err
-			}
-		}
-	case `0.3.0`, `0.3.1`:
-		currentVault, _ := contracts.NewYvault030(vault.Address, client)
-		etherReader := ethereum.Reader{Backend: client, ChainID: chainID}
-		contractABI, _ := contracts.Yvault030MetaData.GetAbi()
-		topics, _ := abi.MakeTopics([][]interface{}{{
-			contractABI.Events[`StrategyAdded`].ID,
-			contractABI.Events[`StrategyMigrated`].ID,
-		}}...)
-		query := goEth.FilterQuery{
-			FromBlock: big.NewInt(int64(vault.Activation)),
-			Addresses: []common.Address{vault.Address},
-			Topics:    topics,
-		}
-		stream, sub, history, err := etherReader.QueryWithHistory(context.Background(), &query)
-		if err != nil {
-			if wg != nil && !isDone {
-				wg.Done()
-			}
-			return 0, false, err
-		}
-		defer sub.Unsubscribe()
-
-		/** 🔵 - Yearn *************************************************************************************
-		** Handle historical events. It's only a storing action as the rest will be performed as a batch,
-		** all the one in the history in one go.
-		**************************************************************************************************/
-		for _, log := range history {
-			if value, err := currentVault.ParseStrategyAdded(log); err == nil {
-				historicalStrategy := handleV03Strategies(chainID, vault.Version, value)
-				storage.StoreStrategyIfMissing(chainID, historicalStrategy)
-				continue
-			}
-			if value, err := currentVault.ParseStrategyMigrated(log); err == nil {
-				historicalStrategy := handleV03StrategiesMigration(chainID, value)
-				storage.StoreStrategyMigrated(chainID, historicalStrategy)
-				continue
-			}
-		}
+	binding, err := getVaultBinding(chainID, vault, client)
+	if err != nil {
 		if wg != nil && !isDone {
 			wg.Done()
 		}
-
-		/**********************************************************************************************
-		** Listen and handle new events
-		**********************************************************************************************/
-		for {
-			select {
-			case log := <-stream:
-				if value, err := currentVault.ParseStrategyAdded(log); err == nil {
-					lastSyncedBlock = value.Raw.BlockNumber
-					newStrategy := handleV03Strategies(chainID, vault.Version, value)
-					if storage.StoreStrategyIfMissing(chainID, newStrategy) {
-						strategyKey := newStrategy.Address.Hex() + `_` + newStrategy.VaultAddress.Hex()
-						fetcher.RetrieveAllStrategies(chainID, map[string]models.TStrategy{
-							strategyKey: newStrategy,
-						})
-					}
-					continue
-				}
-
-				if value, err := currentVault.ParseStrategyMigrated(log); err == nil {
-					lastSyncedBlock = value.Raw.BlockNumber
-					newMigratedStrategy := handleV03StrategiesMigration(chainID, value)
-					storage.StoreStrategyMigrated(chainID, newMigratedStrategy)
-					processMigrations(chainID)
-					if newStrategy, ok := storage.GetStrategy(
-						chainID,
-						newMigratedStrategy.NewStrategyAddress,
-						newMigratedStrategy.VaultAddress,
-					); ok {
-						strategyKey := newStrategy.Address.Hex() + `_` + newStrategy.VaultAddress.Hex()
-						fetcher.RetrieveAllStrategies(chainID, map[string]models.TStrategy{
-							strategyKey: newStrategy,
-						})
-					}
-					continue
-				}
-			case err := <-sub.Err():
-				return lastSyncedBlock, true, err
-			}
-		}
-	case `0.3.2`, `0.3.3`, `0.3.4`, `0.3.5`, `0.4.2`, `0.4.3`, `0.4.4`, `0.4.5`, `0.4.6`, `0.4.7`:
-		currentVault, _ := contracts.NewYvault043(vault.Address, client)
-		etherReader := ethereum.Reader{Backend: client, ChainID: chainID}
-		contractABI, _ := contracts.Yvault043MetaData.GetAbi()
-		topics, _ := abi.MakeTopics([][]interface{}{{
-			contractABI.Events[`StrategyAdded`].ID,
-			contractABI.Events[`StrategyMigrated`].ID,
-		}}...)
-		query := goEth.FilterQuery{
-			FromBlock: big.NewInt(int64(vault.Activation)),
-			Addresses: []common.Address{vault.Address},
-			Topics:    topics,
-		}
-		stream, sub, history, err := etherReader.QueryWithHistory(context.Background(), &query)
-		if err != nil {
-			if wg != nil && !isDone {
-				wg.Done()
-			}
-			return 0, false, err
-		}
-		defer sub.Unsubscribe()
-
-		/** 🔵 - Yearn *************************************************************************************
-		** Handle historical events. It's only a storing action as the rest will be performed as a batch,
-		** all the one in the history in one go.
-		**************************************************************************************************/
-		for _, log := range history {
-			if value, err := currentVault.ParseStrategyAdded(log); err == nil {
-				historicalStrategy := handleV04Strategies(chainID, vault.Version, value)
-				storage.StoreStrategyIfMissing(chainID, historicalStrategy)
-				continue
-			}
-			if value, err := currentVault.ParseStrategyMigrated(log); err == nil {
-				historicalStrategy := handleV04StrategiesMigration(chainID, value)
-				storage.StoreStrategyMigrated(chainID, historicalStrategy)
-				continue
-			}
-		}
+		return 0, false, err
+	}
+	etherReader := ethereum.Reader{Backend: client, ChainID: chainID}
+	query := goEth.FilterQuery{
+		FromBlock: big.NewInt(int64(vault.Activation)),
+		Addresses: []common.Address{vault.Address},
+		Topics:    [][]common.Hash{binding.Topics()},
+	}
+	stream, sub, history, err := etherReader.QueryWithHistory(context.Background(), &query)
+	if err != nil {
 		if wg != nil && !isDone {
 			wg.Done()
 		}
-
-		/**********************************************************************************************
-		** Listen and handle new events
-		**********************************************************************************************/
-		for {
-			select {
-			case log := <-stream:
-				if value, err := currentVault.ParseStrategyAdded(log); err == nil {
-					lastSyncedBlock = value.Raw.BlockNumber
-					newStrategy := handleV04Strategies(chainID, vault.Version, value)
-					if storage.StoreStrategyIfMissing(chainID, newStrategy) {
-						strategyKey := newStrategy.Address.Hex() + `_` + newStrategy.VaultAddress.Hex()
-						fetcher.RetrieveAllStrategies(chainID, map[string]models.TStrategy{
-							strategyKey: newStrategy,
-						})
-					}
-					continue
-				}
-
-				if value, err := currentVault.ParseStrategyMigrated(log); err == nil {
-					lastSyncedBlock = value.Raw.BlockNumber
-					newMigratedStrategy := handleV04StrategiesMigration(chainID, value)
-					storage.StoreStrategyMigrated(chainID, newMigratedStrategy)
-					processMigrations(chainID)
-					if newStrategy, ok := storage.GetStrategy(
-						chainID,
-						newMigratedStrategy.NewStrategyAddress,
-						newMigratedStrategy.VaultAddress,
-					); ok {
-						strategyKey := newStrategy.Address.Hex() + `_` + newStrategy.VaultAddress.Hex()
-						fetcher.RetrieveAllStrategies(chainID, map[string]models.TStrategy{
-							strategyKey: newStrategy,
-						})
-					}
-				}
-			case err := <-sub.Err():
-				return lastSyncedBlock, true, err
-			}
-		}
-	default:
-		// case `3.0.0`, `3.0.1`, `3.0.2`:
-		currentVault, _ := contracts.NewYvault300(vault.Address, client)
-		etherReader := ethereum.Reader{Backend: client, ChainID: chainID}
-		contractABI, _ := contracts.Yvault300MetaData.GetAbi()
-		topics, _ := abi.MakeTopics([][]interface{}{{contractABI.Events[`StrategyChanged`].ID}}...)
-		query := goEth.FilterQuery{
-			FromBlock: big.NewInt(int64(vault.Activation)),
-			Addresses: []common.Address{vault.Address},
-			Topics:    topics,
-		}
-		stream, sub, history, err := etherReader.QueryWithHistory(context.Background(), &query)
-		if err != nil {
-			if wg != nil && !isDone {
-				wg.Done()
-			}
-			return 0, false, err
-		}
-		defer sub.Unsubscribe()
-
-		/** 🔵 - Yearn *************************************************************************************
-		** Handle historical events. It's only a storing action as the rest will be performed as a batch,
-		** all the one in the history in one go.
-		**************************************************************************************************/
-		for _, log := range history {
-			if value, err := currentVault.ParseStrategyChanged(log); err == nil {
-				if value.ChangeType.Uint64() == 1 {
-					historicalStrategy := handleV300Strategies(chainID, vault.Version, value)
-					storage.StoreStrategyIfMissing(chainID, historicalStrategy)
-				}
-				continue
-			}
-		}
-		if wg != nil && !isDone {
-			wg.Done()
+		return 0, false, err
+	}
+	defer sub.Unsubscribe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, history = ethereum.ConfirmLogs(ctx, chainID, stream, history)
+
+	/** 🔵 - Yearn *************************************************************************************
+	** Handle historical events. It's only a storing action as the rest will be performed as a batch,
+	** all the one in the history in one go.
+	**************************************************************************************************/
+	for _, log := range history {
+		if event, ok := binding.Parse(log); ok {
+			handleVaultEvent(chainID, event, false)
 		}
+	}
+	if wg != nil && !isDone {
+		wg.Done()
+	}
 
-		/**********************************************************************************************
-		** Because now some stategies are not added via an event but directly in the contract, we need
-		** to fetch them directly from the contract.
-		** Ex: https://etherscan.io/address/0x92545bCE636E6eE91D88D2D017182cD0bd2fC22e#events
-		**********************************************************************************************/
+	/**********************************************************************************************
+	** Because now some stategies are not added via an event but directly in the contract, we need
+	** to fetch them directly from the contract.
+	** Ex: https://etherscan.io/address/0x92545bCE636E6eE91D88D2D017182cD0bd2fC22e#events
+	**********************************************************************************************/
+	if _, isV3 := binding.(*tVault300Binding); isV3 {
 		for _, lastActiveStrategy := range vault.LastActiveStrategies {
 			newStrategy := models.TStrategy{
 				Address:      lastActiveStrategy,
@@ -580,27 +258,20 @@ func watchNewStrategies(
 			}
 			storage.StoreStrategyIfMissing(chainID, newStrategy)
 		}
+	}
 
-		/**********************************************************************************************
-		** Listen and handle new events
-		**********************************************************************************************/
-		for {
-			select {
-			case log := <-stream:
-				if value, err := currentVault.ParseStrategyChanged(log); err == nil {
-					lastSyncedBlock = value.Raw.BlockNumber
-					newStrategy := handleV300Strategies(chainID, vault.Version, value)
-					if storage.StoreStrategyIfMissing(chainID, newStrategy) {
-						strategyKey := newStrategy.Address.Hex() + `_` + newStrategy.VaultAddress.Hex()
-						fetcher.RetrieveAllStrategies(chainID, map[string]models.TStrategy{
-							strategyKey: newStrategy,
-						})
-					}
-					continue
-				}
-			case err := <-sub.Err():
-				return lastSyncedBlock, true, err
+	/**********************************************************************************************
+	** Listen and handle new events
+	**********************************************************************************************/
+	for {
+		select {
+		case log := <-stream:
+			if event, ok := binding.Parse(log); ok {
+				lastSyncedBlock = event.BlockNumber
+				handleVaultEvent(chainID, event, true)
 			}
+		case err := <-sub.Err():
+			return lastSyncedBlock, true, err
 		}
 	}
 }
@@ -705,7 +376,7 @@ func IndexNewStrategiesFromContracts(
 		}
 		_strategiesAlreadyIndexingForVaults[chainID].Store(vault.Address, true)
 
-		if vault.Metadata.IsRetired || vault.Metadata.Migration.Available {
+		if models.IsVaultRetired(vault) || vault.Metadata.Migration.Available {
 			continue
 		}
 
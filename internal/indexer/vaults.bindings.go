@@ -0,0 +1,208 @@
+package indexer
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yearn/ydaemon/common/contracts"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** The events and the methods used to track the strategies of a vault differ from one version of
+** the vault to another: the StrategyAdded event has different arguments in v0.2.2, v0.3.0/v0.3.1
+** and v0.3.2+, there is no migration before v0.3.0, and the v3 vaults emit StrategyChanged and
+** expose a default queue instead of a withdrawal queue.
+** A TVaultBinding hides those differences behind the contract binding of the version of the vault
+** and maps its events to the common models.TEventBlock.
+**************************************************************************************************/
+type TVaultBinding interface {
+	// Name returns the name of the contract binding used, for the logs
+	Name() string
+	// Topics returns the signatures of the strategy lifecycle events of the version
+	Topics() []common.Hash
+	// Parse maps a log of the vault to a TEventBlock. It returns false for any other event
+	Parse(log types.Log) (models.TEventBlock, bool)
+	// Queue returns the strategy at the given index of the withdrawal (v2) or default (v3) queue
+	Queue(index int64) (common.Address, error)
+}
+
+/**************************************************************************************************
+** getVaultBinding returns the binding matching the version of the vault. Unknown versions are
+** considered as v3, as all the new vaults are.
+**************************************************************************************************/
+func getVaultBinding(chainID uint64, vault models.TVault, client *ethclient.Client) (TVaultBinding, error) {
+	base := tVaultBindingBase{chainID: chainID, vault: vault}
+	switch vault.Version {
+	case `0.2.2`:
+		contract, err := contracts.NewYvault022(vault.Address, client)
+		return &tVault022Binding{base, contract}, err
+	case `0.3.0`, `0.3.1`:
+		contract, err := contracts.NewYvault030(vault.Address, client)
+		return &tVault030Binding{base, contract}, err
+	case `0.3.2`, `0.3.3`, `0.3.4`, `0.3.5`, `0.4.2`, `0.4.3`, `0.4.4`, `0.4.5`, `0.4.6`, `0.4.7`:
+		contract, err := contracts.NewYvault043(vault.Address, client)
+		return &tVault043Binding{base, contract}, err
+	default:
+		// case `3.0.0`, `3.0.1`, `3.0.2`...:
+		contract, err := contracts.NewYvault300(vault.Address, client)
+		return &tVault300Binding{base, contract}, err
+	}
+}
+
+type tVaultBindingBase struct {
+	chainID uint64
+	vault   models.TVault
+}
+
+func (b tVaultBindingBase) newEvent(eventType models.TVaultEventType, strategy common.Address, log types.Log) models.TEventBlock {
+	return models.TEventBlock{
+		EventType:       eventType,
+		ChainID:         b.chainID,
+		VaultAddress:    log.Address,
+		VaultVersion:    b.vault.Version,
+		StrategyAddress: strategy,
+		TxHash:          log.TxHash,
+		BlockNumber:     log.BlockNumber,
+		TxIndex:         log.TxIndex,
+		LogIndex:        log.Index,
+	}
+}
+
+func eventTopic(metaData interface{ GetAbi() (*abi.ABI, error) }, name string) common.Hash {
+	contractABI, err := metaData.GetAbi()
+	if err != nil || contractABI == nil {
+		return common.Hash{}
+	}
+	return contractABI.Events[name].ID
+}
+
+/**************************************************************************************************
+** v0.2.2: StrategyAdded(strategy, debtLimit, rateLimit, performanceFee), no migration.
+**************************************************************************************************/
+type tVault022Binding struct {
+	tVaultBindingBase
+	contract *contracts.Yvault022
+}
+
+func (b *tVault022Binding) Name() string {
+	return `Yvault022`
+}
+
+func (b *tVault022Binding) Topics() []common.Hash {
+	return []common.Hash{eventTopic(contracts.Yvault022MetaData, `StrategyAdded`)}
+}
+
+func (b *tVault022Binding) Parse(log types.Log) (models.TEventBlock, bool) {
+	if value, err := b.contract.ParseStrategyAdded(log); err == nil {
+		return b.newEvent(models.VaultEventStrategyAdded, value.Strategy, log), true
+	}
+	return models.TEventBlock{}, false
+}
+
+func (b *tVault022Binding) Queue(index int64) (common.Address, error) {
+	return b.contract.WithdrawalQueue(nil, big.NewInt(index))
+}
+
+/**************************************************************************************************
+** v0.3.0 and v0.3.1: StrategyAdded(strategy, debtRatio, rateLimit, performanceFee) and
+** StrategyMigrated(oldVersion, newVersion).
+**************************************************************************************************/
+type tVault030Binding struct {
+	tVaultBindingBase
+	contract *contracts.Yvault030
+}
+
+func (b *tVault030Binding) Name() string {
+	return `Yvault030`
+}
+
+func (b *tVault030Binding) Topics() []common.Hash {
+	return []common.Hash{
+		eventTopic(contracts.Yvault030MetaData, `StrategyAdded`),
+		eventTopic(contracts.Yvault030MetaData, `StrategyMigrated`),
+	}
+}
+
+func (b *tVault030Binding) Parse(log types.Log) (models.TEventBlock, bool) {
+	if value, err := b.contract.ParseStrategyAdded(log); err == nil {
+		return b.newEvent(models.VaultEventStrategyAdded, value.Strategy, log), true
+	}
+	if value, err := b.contract.ParseStrategyMigrated(log); err == nil {
+		event := b.newEvent(models.VaultEventStrategyMigrated, value.OldVersion, log)
+		event.NewStrategyAddress = value.NewVersion
+		return event, true
+	}
+	return models.TEventBlock{}, false
+}
+
+func (b *tVault030Binding) Queue(index int64) (common.Address, error) {
+	return b.contract.WithdrawalQueue(nil, big.NewInt(index))
+}
+
+/**************************************************************************************************
+** v0.3.2 to v0.4.x: StrategyAdded(strategy, debtRatio, minDebtPerHarvest, maxDebtPerHarvest,
+** performanceFee) and StrategyMigrated(oldVersion, newVersion).
+**************************************************************************************************/
+type tVault043Binding struct {
+	tVaultBindingBase
+	contract *contracts.Yvault043
+}
+
+func (b *tVault043Binding) Name() string {
+	return `Yvault043`
+}
+
+func (b *tVault043Binding) Topics() []common.Hash {
+	return []common.Hash{
+		eventTopic(contracts.Yvault043MetaData, `StrategyAdded`),
+		eventTopic(contracts.Yvault043MetaData, `StrategyMigrated`),
+	}
+}
+
+func (b *tVault043Binding) Parse(log types.Log) (models.TEventBlock, bool) {
+	if value, err := b.contract.ParseStrategyAdded(log); err == nil {
+		return b.newEvent(models.VaultEventStrategyAdded, value.Strategy, log), true
+	}
+	if value, err := b.contract.ParseStrategyMigrated(log); err == nil {
+		event := b.newEvent(models.VaultEventStrategyMigrated, value.OldVersion, log)
+		event.NewStrategyAddress = value.NewVersion
+		return event, true
+	}
+	return models.TEventBlock{}, false
+}
+
+func (b *tVault043Binding) Queue(index int64) (common.Address, error) {
+	return b.contract.WithdrawalQueue(nil, big.NewInt(index))
+}
+
+/**************************************************************************************************
+** v3: StrategyChanged(strategy, changeType), a changeType of 1 being an addition. The revocations
+** are not tracked.
+**************************************************************************************************/
+type tVault300Binding struct {
+	tVaultBindingBase
+	contract *contracts.Yvault300
+}
+
+func (b *tVault300Binding) Name() string {
+	return `Yvault300`
+}
+
+func (b *tVault300Binding) Topics() []common.Hash {
+	return []common.Hash{eventTopic(contracts.Yvault300MetaData, `StrategyChanged`)}
+}
+
+func (b *tVault300Binding) Parse(log types.Log) (models.TEventBlock, bool) {
+	if value, err := b.contract.ParseStrategyChanged(log); err == nil && value.ChangeType.Uint64() == 1 {
+		return b.newEvent(models.VaultEventStrategyAdded, value.Strategy, log), true
+	}
+	return models.TEventBlock{}, false
+}
+
+func (b *tVault300Binding) Queue(index int64) (common.Address, error) {
+	return b.contract.DefaultQueue(nil, big.NewInt(index))
+}
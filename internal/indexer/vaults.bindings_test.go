@@ -0,0 +1,185 @@
+package indexer
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yearn/ydaemon/common/contracts"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+var (
+	testVault       = common.HexToAddress(`0x00000000000000000000000000000000000000a1`)
+	testStrategy    = common.HexToAddress(`0x00000000000000000000000000000000000000b1`)
+	testNewStrategy = common.HexToAddress(`0x00000000000000000000000000000000000000b2`)
+)
+
+/**************************************************************************************************
+** buildVaultLog encodes an event of a vault like a node returns it: the signature and the indexed
+** arguments as topics, the other arguments ABI encoded as data.
+**************************************************************************************************/
+func buildVaultLog(t *testing.T, metaData interface{ GetAbi() (*abi.ABI, error) }, name string, args ...interface{}) types.Log {
+	contractABI, err := metaData.GetAbi()
+	if err != nil {
+		t.Fatalf("Failed to parse the ABI: %v", err)
+	}
+	event := contractABI.Events[name]
+	topics := []common.Hash{event.ID}
+	data := []interface{}{}
+	for i, input := range event.Inputs {
+		if !input.Indexed {
+			data = append(data, args[i])
+			continue
+		}
+		switch value := args[i].(type) {
+		case common.Address:
+			topics = append(topics, common.BytesToHash(value.Bytes()))
+		case *big.Int:
+			topics = append(topics, common.BigToHash(value))
+		}
+	}
+	packed, err := event.Inputs.NonIndexed().Pack(data...)
+	if err != nil {
+		t.Fatalf("Failed to encode the %s event: %v", name, err)
+	}
+	return types.Log{
+		Address:     testVault,
+		Topics:      topics,
+		Data:        packed,
+		BlockNumber: 19_000_000,
+		TxHash:      common.HexToHash(`0x01`),
+		TxIndex:     2,
+		Index:       3,
+	}
+}
+
+/**************************************************************************************************
+** TestGetVaultBinding checks that each vault version is dispatched to the binding of its ABI, the
+** unknown versions being handled as v3.
+**************************************************************************************************/
+func TestGetVaultBinding(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{version: `0.2.2`, expected: `Yvault022`},
+		{version: `0.3.0`, expected: `Yvault030`},
+		{version: `0.3.1`, expected: `Yvault030`},
+		{version: `0.3.2`, expected: `Yvault043`},
+		{version: `0.3.5`, expected: `Yvault043`},
+		{version: `0.4.3`, expected: `Yvault043`},
+		{version: `0.4.7`, expected: `Yvault043`},
+		{version: `3.0.0`, expected: `Yvault300`},
+		{version: `3.0.4`, expected: `Yvault300`},
+		{version: ``, expected: `Yvault300`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			binding, err := getVaultBinding(1, models.TVault{Address: testVault, Version: tt.version}, nil)
+			if err != nil {
+				t.Fatalf("Failed to build the binding: %v", err)
+			}
+			if binding.Name() != tt.expected {
+				t.Errorf("expected the %s binding for the version %q, got %s", tt.expected, tt.version, binding.Name())
+			}
+		})
+	}
+}
+
+/**************************************************************************************************
+** TestVaultBindingTopics checks that each binding filters on the signatures of the lifecycle
+** events of its version, which differ between the versions.
+**************************************************************************************************/
+func TestVaultBindingTopics(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected []string
+	}{
+		{version: `0.2.2`, expected: []string{`StrategyAdded(address,uint256,uint256,uint256)`}},
+		{version: `0.3.1`, expected: []string{`StrategyAdded(address,uint256,uint256,uint256)`, `StrategyMigrated(address,address)`}},
+		{version: `0.4.3`, expected: []string{`StrategyAdded(address,uint256,uint256,uint256,uint256)`, `StrategyMigrated(address,address)`}},
+		{version: `3.0.2`, expected: []string{`StrategyChanged(address,uint256)`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			binding, _ := getVaultBinding(1, models.TVault{Address: testVault, Version: tt.version}, nil)
+			topics := binding.Topics()
+			if len(topics) != len(tt.expected) {
+				t.Fatalf("expected %d topics, got %d", len(tt.expected), len(topics))
+			}
+			for i, signature := range tt.expected {
+				if expected := crypto.Keccak256Hash([]byte(signature)); topics[i] != expected {
+					t.Errorf("expected the topic of %s, got %s", signature, topics[i].Hex())
+				}
+			}
+		})
+	}
+}
+
+/**************************************************************************************************
+** TestVaultBindingParse dispatches the events of every version to the binding of every version:
+** an event is only mapped by the binding of a version emitting it, with its strategy, and the
+** revocations of the v3 strategies are ignored.
+**************************************************************************************************/
+func TestVaultBindingParse(t *testing.T) {
+	one := big.NewInt(1)
+	added022 := buildVaultLog(t, contracts.Yvault022MetaData, `StrategyAdded`, testStrategy, one, one, big.NewInt(1000))
+	added030 := buildVaultLog(t, contracts.Yvault030MetaData, `StrategyAdded`, testStrategy, one, one, big.NewInt(1000))
+	migrated030 := buildVaultLog(t, contracts.Yvault030MetaData, `StrategyMigrated`, testStrategy, testNewStrategy)
+	added043 := buildVaultLog(t, contracts.Yvault043MetaData, `StrategyAdded`, testStrategy, one, one, one, big.NewInt(1000))
+	migrated043 := buildVaultLog(t, contracts.Yvault043MetaData, `StrategyMigrated`, testStrategy, testNewStrategy)
+	added300 := buildVaultLog(t, contracts.Yvault300MetaData, `StrategyChanged`, testStrategy, big.NewInt(1))
+	revoked300 := buildVaultLog(t, contracts.Yvault300MetaData, `StrategyChanged`, testStrategy, big.NewInt(2))
+
+	tests := []struct {
+		name        string
+		version     string
+		log         types.Log
+		expectedOK  bool
+		eventType   models.TVaultEventType
+		newStrategy common.Address
+	}{
+		{name: "v0.2.2 added", version: `0.2.2`, log: added022, expectedOK: true, eventType: models.VaultEventStrategyAdded},
+		{name: "v0.2.2 ignores the v0.4.3 added", version: `0.2.2`, log: added043},
+		{name: "v0.2.2 ignores the migrations", version: `0.2.2`, log: migrated030},
+		{name: "v0.3.0 added", version: `0.3.0`, log: added030, expectedOK: true, eventType: models.VaultEventStrategyAdded},
+		{name: "v0.3.0 migrated", version: `0.3.0`, log: migrated030, expectedOK: true, eventType: models.VaultEventStrategyMigrated, newStrategy: testNewStrategy},
+		{name: "v0.3.0 ignores the v0.4.3 added", version: `0.3.0`, log: added043},
+		{name: "v0.4.3 added", version: `0.4.3`, log: added043, expectedOK: true, eventType: models.VaultEventStrategyAdded},
+		{name: "v0.4.3 migrated", version: `0.4.3`, log: migrated043, expectedOK: true, eventType: models.VaultEventStrategyMigrated, newStrategy: testNewStrategy},
+		{name: "v0.4.3 ignores the v0.3.0 added", version: `0.4.3`, log: added030},
+		{name: "v0.4.3 ignores the v3 changes", version: `0.4.3`, log: added300},
+		{name: "v3 added", version: `3.0.2`, log: added300, expectedOK: true, eventType: models.VaultEventStrategyAdded},
+		{name: "v3 ignores the revocations", version: `3.0.2`, log: revoked300},
+		{name: "v3 ignores the v0.4.3 added", version: `3.0.2`, log: added043},
+		{name: "v3 ignores the migrations", version: `3.0.2`, log: migrated043},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding, _ := getVaultBinding(1, models.TVault{Address: testVault, Version: tt.version}, nil)
+			event, ok := binding.Parse(tt.log)
+			if ok != tt.expectedOK {
+				t.Fatalf("expected the event to be parsed: %v, got %v", tt.expectedOK, ok)
+			}
+			if !ok {
+				return
+			}
+			if event.EventType != tt.eventType {
+				t.Errorf("expected a %s event, got %s", tt.eventType, event.EventType)
+			}
+			if event.StrategyAddress != testStrategy || event.NewStrategyAddress != tt.newStrategy {
+				t.Errorf("expected the strategies %s and %s, got %s and %s", testStrategy.Hex(), tt.newStrategy.Hex(), event.StrategyAddress.Hex(), event.NewStrategyAddress.Hex())
+			}
+			if event.ChainID != 1 || event.VaultAddress != testVault || event.VaultVersion != tt.version {
+				t.Errorf("expected the event of the vault %s v%s on chain 1, got %+v", testVault.Hex(), tt.version, event)
+			}
+			if event.BlockNumber != 19_000_000 || event.TxIndex != 2 || event.LogIndex != 3 {
+				t.Errorf("expected the position of the log to be kept, got %+v", event)
+			}
+		})
+	}
+}
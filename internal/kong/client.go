@@ -59,7 +59,7 @@ type KongDebt struct {
 	CurrentDebtUsd     *float64 `json:"currentDebtUsd"`     // Float or null
 	MaxDebt            *string  `json:"maxDebt"`            // BigInt (string) or null
 	MaxDebtUsd         *float64 `json:"maxDebtUsd"`         // Float or null
-	TargetDebtRatio    *float64 `json:"targetDebtRatio"`    // Float or null
+	TargetDebtRatio    *float64 `json:"targetDebtRatio"`    // Float (basis points) or null
 	MaxDebtRatio       *float64 `json:"maxDebtRatio"`       // Float or null
 }
 
@@ -487,3 +487,43 @@ func FetchVaultsFromKong(chainID uint64) (map[common.Address]KongVaultData, erro
 	logs.Success(chainID, `-`, `Fetched %d vaults from Kong`, len(vaults))
 	return vaultData, nil
 }
+
+/**************************************************************************************************
+** ToVaultSchema converts the Kong data of a vault to the schema stored for the APY computations.
+**************************************************************************************************/
+func (data KongVaultData) ToVaultSchema() models.TKongVaultSchema {
+	var debts []models.TKongDebt
+	for _, debt := range data.Debts {
+		debts = append(debts, models.TKongDebt{
+			Strategy:          debt.Strategy,
+			PerformanceFee:    debt.PerformanceFee,
+			Activation:        debt.Activation,
+			DebtRatio:         debt.DebtRatio,
+			MinDebtPerHarvest: debt.MinDebtPerHarvest,
+			MaxDebtPerHarvest: debt.MaxDebtPerHarvest,
+			LastReport:        debt.LastReport,
+			TotalDebt:         debt.TotalDebt,
+			TotalDebtUsd:      debt.TotalDebtUsd,
+			TotalGain:         debt.TotalGain,
+			TotalGainUsd:      debt.TotalGainUsd,
+			TotalLoss:         debt.TotalLoss,
+			TotalLossUsd:      debt.TotalLossUsd,
+			CurrentDebt:       debt.CurrentDebt,
+			CurrentDebtUsd:    debt.CurrentDebtUsd,
+			MaxDebt:           debt.MaxDebt,
+			MaxDebtUsd:        debt.MaxDebtUsd,
+			TargetDebtRatio:   debt.TargetDebtRatio,
+			MaxDebtRatio:      debt.MaxDebtRatio,
+		})
+	}
+
+	return models.TKongVaultSchema{
+		ManagementFee:     data.Vault.GetManagementFee(),
+		PerformanceFee:    data.Vault.GetPerformanceFee(),
+		APY:               data.APY,
+		Debts:             debts,
+		TVL:               data.Vault.GetTVL(),
+		TotalAssets:       data.TotalAssets,
+		StrategyAddresses: data.Vault.GetStrategies(),
+	}
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -9,13 +10,28 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/go-co-op/gocron/v2"
 	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/common/tracing"
 	"github.com/yearn/ydaemon/internal/fetcher"
 	"github.com/yearn/ydaemon/internal/indexer"
 	"github.com/yearn/ydaemon/internal/models"
 	"github.com/yearn/ydaemon/internal/storage"
 	"github.com/yearn/ydaemon/processes/apr"
+	"github.com/yearn/ydaemon/processes/assets"
+	"github.com/yearn/ydaemon/processes/entries"
+	"github.com/yearn/ydaemon/processes/fees"
+	"github.com/yearn/ydaemon/processes/governance"
+	"github.com/yearn/ydaemon/processes/holders"
+	"github.com/yearn/ydaemon/processes/inception"
+	"github.com/yearn/ydaemon/processes/keepers"
+	"github.com/yearn/ydaemon/processes/losses"
+	"github.com/yearn/ydaemon/processes/migrations"
 	"github.com/yearn/ydaemon/processes/prices"
+	"github.com/yearn/ydaemon/processes/protocols"
 	"github.com/yearn/ydaemon/processes/risks"
+	"github.com/yearn/ydaemon/processes/sharePrice"
+	"github.com/yearn/ydaemon/processes/simulations"
+	"github.com/yearn/ydaemon/processes/tokenlist"
+	"github.com/yearn/ydaemon/processes/treasury"
 )
 
 var STRATLIST = []models.TStrategy{}
@@ -49,6 +65,99 @@ func endJob(chainID uint64, name string, id uint64, started time.Time) {
 	logs.Success(fmt.Sprintf("✅ [JOB DONE] job=%s chain=%d jobID=%d took=%s", name, chainID, id, took))
 }
 
+/**************************************************************************************************
+** SCHEDULER_STOP_TIMEOUT is the time given to the running jobs of a chain to complete once its
+** scheduler is stopped, for the in-flight RPC batches not to be cut in the middle of a refresh.
+**************************************************************************************************/
+const SCHEDULER_STOP_TIMEOUT = 45 * time.Second
+
+var schedulers = []gocron.Scheduler{}
+var schedulersMtx sync.Mutex
+
+func registerScheduler(scheduler gocron.Scheduler) {
+	schedulersMtx.Lock()
+	defer schedulersMtx.Unlock()
+	schedulers = append(schedulers, scheduler)
+}
+
+/**************************************************************************************************
+** StopSchedulers stops the schedulers of all the chains: no new job is started and the running
+** ones are given SCHEDULER_STOP_TIMEOUT to complete. It returns once they are all done, or when
+** the context expires, with the names of the jobs still running.
+**************************************************************************************************/
+func StopSchedulers(ctx context.Context) []string {
+	schedulersMtx.Lock()
+	toStop := schedulers
+	schedulers = []gocron.Scheduler{}
+	schedulersMtx.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		wg := sync.WaitGroup{}
+		for _, scheduler := range toStop {
+			wg.Add(1)
+			go func(scheduler gocron.Scheduler) {
+				defer wg.Done()
+				if err := scheduler.Shutdown(); err != nil {
+					logs.Warning(`Failed to stop a scheduler: ` + err.Error())
+				}
+			}(scheduler)
+		}
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	running := []string{}
+	jobInProgress.Range(func(key, value interface{}) bool {
+		running = append(running, key.(string))
+		return true
+	})
+	return running
+}
+
+/**************************************************************************************************
+** SNAPSHOT_STAGES_CONCURRENCY is the maximum number of independent stages of the snapshot of a
+** chain running at the same time, for a chain not to flood its RPC with all its stages at once.
+**************************************************************************************************/
+const SNAPSHOT_STAGES_CONCURRENCY = 3
+
+/**************************************************************************************************
+** runConcurrently runs the tasks with at most `limit` of them at the same time and returns once
+** they are all done.
+**************************************************************************************************/
+func runConcurrently(limit int, tasks ...func()) {
+	semaphore := make(chan struct{}, limit)
+	wg := sync.WaitGroup{}
+	for _, task := range tasks {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(task func()) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			task()
+		}(task)
+	}
+	wg.Wait()
+}
+
+/**************************************************************************************************
+** traceStage runs a stage of the refresh pipeline of a chain within its own span, child of the
+** span of the job carried by the context, for the slow refreshes to be attributed to a stage. The
+** block the stage started from is recorded to measure the freshness of the data of the chain.
+**************************************************************************************************/
+func traceStage(ctx context.Context, chainID uint64, stage string, run func(ctx context.Context)) {
+	ctx, span := tracing.StartStage(ctx, chainID, stage)
+	defer span.End()
+	recordProcessBlock(chainID, stage, func() { run(ctx) })
+}
+
 func initStakingPools(chainID uint64) {
 	/**********************************************************************************************
 	** Start the Staking Indexing process and schedule it to run every hour. This indexer
@@ -62,7 +171,7 @@ func initStakingPools(chainID uint64) {
 	logs.Success(chainID, `-`, `InitStakingPools ✅`)
 }
 
-func initVaults(chainID uint64) (
+func initVaults(ctx context.Context, chainID uint64) (
 	map[common.Address]models.TVaultsFromRegistry,
 	map[string]models.TStrategy,
 	map[common.Address]models.TVault,
@@ -74,15 +183,28 @@ func initVaults(chainID uint64) (
 	** - The strategies (from Kong, complete replacement for contract querying)
 	** - The tokens
 	**************************************************************************************************/
-	indexer.IndexYearnXPoolTogetherVaults(chainID)
-	indexer.IndexYearnXCoveVaults(chainID)
-	// Use Kong as complete replacement for registry discovery
-	registries := indexer.IndexNewVaults(chainID)
-	logs.Success(chainID, `-`, `InitVaults (Kong) ✅`, len(registries))
-	vaultMap, strategiesMap := indexer.ProcessNewVault(chainID, registries, fetcher.ProcessNewVaultMethodReplace)
-	logs.Success(chainID, `-`, `InitVaults ✅`, len(vaultMap))
-	tokenMap := fetcher.RetrieveAllTokens(chainID, vaultMap)
-	logs.Success(chainID, `-`, `InitTokens ✅`, len(tokenMap))
+	var registries map[common.Address]models.TVaultsFromRegistry
+	var strategiesMap map[string]models.TStrategy
+	var vaultMap map[common.Address]models.TVault
+	var tokenMap map[common.Address]models.TERC20Token
+
+	traceStage(ctx, chainID, `filtering`, func(ctx context.Context) {
+		indexer.IndexYearnXPoolTogetherVaults(chainID)
+		indexer.IndexYearnXCoveVaults(chainID)
+		// Use Kong as complete replacement for registry discovery
+		registries = indexer.IndexNewVaults(chainID)
+		logs.Success(chainID, `-`, `InitVaults (Kong) ✅`, len(registries))
+	})
+	traceStage(ctx, chainID, `hydration.vaults`, func(ctx context.Context) {
+		trackInitStage(chainID, INIT_STAGE_VAULTS, func() {
+			vaultMap, strategiesMap = indexer.ProcessNewVault(chainID, registries, fetcher.ProcessNewVaultMethodReplace)
+			logs.Success(chainID, `-`, `InitVaults ✅`, len(vaultMap))
+		})
+		trackInitStage(chainID, INIT_STAGE_TOKENS, func() {
+			tokenMap = fetcher.RetrieveAllTokens(chainID, vaultMap)
+			logs.Success(chainID, `-`, `InitTokens ✅`, len(tokenMap))
+		})
+	})
 	return registries, strategiesMap, vaultMap, tokenMap
 }
 
@@ -104,19 +226,55 @@ func InitializeV2(chainID uint64, wg *sync.WaitGroup) {
 	var vaultMap map[common.Address]models.TVault
 	var tokenMap map[common.Address]models.TERC20Token
 
-	scheduler, err := gocron.NewScheduler()
+	scheduler, err := gocron.NewScheduler(gocron.WithStopTimeout(SCHEDULER_STOP_TIMEOUT))
 	if err != nil {
 		logs.Error(chainID, `-`, `Failed to create scheduler: %v`, err)
 		return
 	}
+	registerScheduler(scheduler)
+	registerInitProgress(chainID)
+
+	/**********************************************************************************************
+	** The sequencer is checked before the first refresh, for it to be skipped if already down.
+	**********************************************************************************************/
+	if hasSequencerFeed(chainID) {
+		checkSequencerStatus(chainID)
+		scheduler.NewJob(
+			gocron.DurationJob(
+				SEQUENCER_CHECK_INTERVAL,
+			),
+			gocron.NewTask(
+				func() {
+					checkSequencerStatus(chainID)
+				},
+			),
+		)
+	}
+
+	/**********************************************************************************************
+	** The RPC endpoints are checked every minute, the chain being degraded while they are dead.
+	**********************************************************************************************/
+	scheduler.NewJob(
+		gocron.DurationJob(
+			RPC_HEALTH_CHECK_INTERVAL,
+		),
+		gocron.NewTask(
+			func() {
+				checkRPCHealth(chainID)
+			},
+		),
+	)
 
-	// Schedule metadata refresh every 5 minutes
+	// Schedule metadata refresh every 5 minutes, hourly on the chains in sunset mode
 	scheduler.NewJob(
 		gocron.DurationJob(
-			time.Minute*5,
+			refreshInterval(chainID, time.Minute*5),
 		),
 		gocron.NewTask(
 			func() {
+				if skipWhilePaused(chainID, "META5M") || skipWhileSequencerDown(chainID, "META5M") || skipWhileRPCDown(chainID, "META5M") {
+					return
+				}
 				id, started, _ := beginJob(chainID, "META5M")
 				defer endJob(chainID, "META5M", id, started)
 
@@ -136,47 +294,240 @@ func InitializeV2(chainID uint64, wg *sync.WaitGroup) {
 		gocron.WithStartAt(gocron.WithStartImmediately()),
 	)
 
-	// Schedule snapshot refresh every 30 minutes
+	// Schedule snapshot refresh every 30 minutes, hourly on the chains in sunset mode
 	scheduler.NewJob(
 		gocron.DurationJob(
-			time.Minute*30,
+			refreshInterval(chainID, time.Minute*30),
 		),
 		gocron.NewTask(
 			func() {
+				if skipWhilePaused(chainID, "SNAPSHOT30M") || skipWhileSequencerDown(chainID, "SNAPSHOT30M") || skipWhileRPCDown(chainID, "SNAPSHOT30M") {
+					return
+				}
 				id, started, _ := beginJob(chainID, "SNAPSHOT30M")
 				defer endJob(chainID, "SNAPSHOT30M", id, started)
+				ctx, span := tracing.StartStage(context.Background(), chainID, `refresh`)
+				defer span.End()
 
 				logs.Warning(fmt.Sprintf("🧩 [SNAPSHOT] initVaults start chain=%d", chainID))
-				_, _, vaultMap, tokenMap = initVaults(chainID)
+				_, _, vaultMap, tokenMap = initVaults(ctx, chainID)
 				logs.Success(fmt.Sprintf("🧩 [SNAPSHOT] initVaults done chain=%d vaults=%d tokens=%d", chainID, len(vaultMap), len(tokenMap)))
+				recordInput(chainID, `vaults`, fingerprintVaults(chainID))
 
-				tRisk := time.Now()
-				risks.RetrieveAvailableRiskScores(chainID)
-				logs.Info(fmt.Sprintf("🧩 [SNAPSHOT] risks loaded chain=%d took=%s", chainID, time.Since(tRisk)))
-
-				tStake := time.Now()
-				initStakingPools(chainID)
-				logs.Info(fmt.Sprintf("🧩 [SNAPSHOT] staking init chain=%d took=%s", chainID, time.Since(tStake)))
-				tStrats := time.Now()
-				initStrategies(chainID, vaultMap)
-				logs.Info(fmt.Sprintf("🧩 [SNAPSHOT] strategies init chain=%d took=%s", chainID, time.Since(tStrats)))
 				/**********************************************************************************************
-				** Retrieving prices and strategies for all the given token and strategies on that chain.
-				** This is done in parallel to speed up the process and reduce the time it takes to complete.
-				** The scheduler is used to retrieve the strategies every 15 minutes.
-				** Computing APRS
+				** The stages only depending on the vaults and the tokens run concurrently, bounded by
+				** SNAPSHOT_STAGES_CONCURRENCY, for the hydration of a chain to be as short as possible.
+				** The protocols and the share price checks need the strategies and run after them.
+				** The derived stages only run when the inputs they depend on changed (see
+				** HYDRATION_DEPENDENCIES).
 				**********************************************************************************************/
-				logs.Warning(fmt.Sprintf("💰 [PRICES] start chain=%d tokens=%d", chainID, len(tokenMap)))
-				prices.RetrieveAllPrices(chainID, tokenMap)
-				logs.Success(fmt.Sprintf("💰 [PRICES] done chain=%d", chainID))
+				runConcurrently(SNAPSHOT_STAGES_CONCURRENCY,
+					func() {
+						traceStage(ctx, chainID, `risks`, func(ctx context.Context) {
+							tRisk := time.Now()
+							risks.RetrieveAvailableRiskScores(chainID)
+							logs.Info(fmt.Sprintf("🧩 [SNAPSHOT] risks loaded chain=%d took=%s", chainID, time.Since(tRisk)))
+						})
+					},
+					func() {
+						if skipOnSunsetChain(chainID, `holders`) {
+							return
+						}
+						traceStage(ctx, chainID, `holders`, func(ctx context.Context) {
+							tHolders := time.Now()
+							holders.RefreshHolders(chainID)
+							logs.Info(fmt.Sprintf("🧩 [SNAPSHOT] holders indexed chain=%d took=%s", chainID, time.Since(tHolders)))
+						})
+					},
+					func() {
+						traceStage(ctx, chainID, `locales`, func(ctx context.Context) {
+							storage.LoadLocales(chainID)
+						})
+					},
+					func() {
+						traceStage(ctx, chainID, `tokenlist`, func(ctx context.Context) {
+							tokenlist.RefreshTokenList()
+						})
+					},
+					func() {
+						traceStage(ctx, chainID, `staking`, func(ctx context.Context) {
+							tStake := time.Now()
+							initStakingPools(chainID)
+							logs.Info(fmt.Sprintf("🧩 [SNAPSHOT] staking init chain=%d took=%s", chainID, time.Since(tStake)))
+						})
+					},
+					func() {
+						traceStage(ctx, chainID, `hydration.strategies`, func(ctx context.Context) {
+							tStrats := time.Now()
+							initStrategies(chainID, vaultMap)
+							logs.Info(fmt.Sprintf("🧩 [SNAPSHOT] strategies init chain=%d took=%s", chainID, time.Since(tStrats)))
+						})
+						recordInput(chainID, `strategies`, fingerprintStrategies(chainID))
+						traceDerivedStage(ctx, chainID, `protocols`, func(ctx context.Context) {
+							tProtocols := time.Now()
+							protocols.RetrieveStrategiesProtocols(chainID)
+							logs.Info(fmt.Sprintf("🏷️ [PROTOCOLS] strategies labelled chain=%d took=%s", chainID, time.Since(tProtocols)))
+						})
+						traceDerivedStage(ctx, chainID, `sharePrice`, func(ctx context.Context) {
+							sharePriceAnomalies := sharePrice.DetectSharePriceAnomalies(chainID)
+							logs.Info(fmt.Sprintf("🚨 [SHARE PRICE] checked chain=%d anomalies=%d", chainID, len(sharePriceAnomalies)))
+						})
+					},
+					func() {
+						traceStage(ctx, chainID, `pricing`, func(ctx context.Context) {
+							trackInitStage(chainID, INIT_STAGE_PRICES, func() {
+								logs.Warning(fmt.Sprintf("💰 [PRICES] start chain=%d tokens=%d", chainID, len(tokenMap)))
+								prices.RetrieveAllPrices(chainID, tokenMap)
+								logs.Success(fmt.Sprintf("💰 [PRICES] done chain=%d", chainID))
+							})
+						})
+					},
+				)
+				recordPricesInput(chainID)
+
+				traceDerivedStage(ctx, chainID, `tvl`, func(ctx context.Context) {
+					tTVL := time.Now()
+					fetcher.RetrieveVaultsTVLBreakdown(chainID)
+					logs.Info(fmt.Sprintf("🧮 [TVL] breakdown done chain=%d took=%s", chainID, time.Since(tTVL)))
+				})
+
+				traceDerivedStage(ctx, chainID, `apr`, func(ctx context.Context) {
+					logs.Warning(fmt.Sprintf("📈 [APY] start chain=%d vaults=%d", chainID, len(vaultMap)))
+					trackInitStage(chainID, INIT_STAGE_APY, func() {
+						apr.ComputeChainAPY(chainID)
+					})
+					logs.Success(fmt.Sprintf("📈 [APY] done chain=%d", chainID))
+				})
+
+				if !skipOnSunsetChain(chainID, `entries`) {
+					traceStage(ctx, chainID, `entries`, func(ctx context.Context) {
+						tEntries := time.Now()
+						entries.RefreshDepositorEntries(chainID)
+						logs.Info(fmt.Sprintf("🎟️ [ENTRIES] depositor entries done chain=%d took=%s", chainID, time.Since(tEntries)))
+					})
+				}
+
+				traceDerivedStage(ctx, chainID, `migrations`, func(ctx context.Context) {
+					tMigrations := time.Now()
+					migrations.ResolveMigrations(chainID)
+					logs.Info(fmt.Sprintf("🚚 [MIGRATIONS] resolved chain=%d took=%s", chainID, time.Since(tMigrations)))
+				})
+
+				traceStage(ctx, chainID, `publish`, func(ctx context.Context) {
+					version := recordVaultsVersion(chainID)
+					logs.Info(fmt.Sprintf("🏷️ [VERSION] store version chain=%d version=%d", chainID, version))
+
+					count := recordVaultsMetrics(chainID)
+					logs.Info(fmt.Sprintf("📊 [METRICS] recorded chain=%d vaults=%d", chainID, count))
+
+					storage.StoreAuditTrails(chainID)
+				})
+
+				if simulations.IsEnabled() {
+					traceStage(ctx, chainID, `simulations`, func(ctx context.Context) {
+						tSim := time.Now()
+						simulations.RetrievePendingReports(chainID)
+						logs.Info(fmt.Sprintf("🔮 [SIMULATION] pending reports done chain=%d took=%s", chainID, time.Since(tSim)))
+					})
+				}
 
-				logs.Warning(fmt.Sprintf("📈 [APY] start chain=%d vaults=%d", chainID, len(vaultMap)))
-				apr.ComputeChainAPY(chainID)
-				logs.Success(fmt.Sprintf("📈 [APY] done chain=%d", chainID))
+				if assets.IsEnabled() {
+					traceStage(ctx, chainID, `assets`, func(ctx context.Context) {
+						tAssets := time.Now()
+						assets.PinChainAssets(chainID)
+						logs.Info(fmt.Sprintf("📌 [ASSETS] ipfs pins done chain=%d took=%s", chainID, time.Since(tAssets)))
+					})
+				}
+
+				traceStage(ctx, chainID, `keepers`, func(ctx context.Context) {
+					tKeepers := time.Now()
+					keepers.RetrieveKeeperStatuses(chainID)
+					logs.Info(fmt.Sprintf("🤖 [KEEPERS] statuses done chain=%d took=%s", chainID, time.Since(tKeepers)))
+				})
+
+				if !skipOnSunsetChain(chainID, `tends`) {
+					traceStage(ctx, chainID, `tends`, func(ctx context.Context) {
+						tTends := time.Now()
+						keepers.RefreshStrategiesTends(chainID)
+						logs.Info(fmt.Sprintf("🔁 [TENDS] strategy tends done chain=%d took=%s", chainID, time.Since(tTends)))
+					})
+				}
+
+				if !skipOnSunsetChain(chainID, `governance`) {
+					traceStage(ctx, chainID, `governance`, func(ctx context.Context) {
+						tGovernance := time.Now()
+						governance.RefreshVaultsGovernance(chainID)
+						logs.Info(fmt.Sprintf("🔐 [GOVERNANCE] roles done chain=%d took=%s", chainID, time.Since(tGovernance)))
+					})
+				}
+
+				if !skipOnSunsetChain(chainID, `fees`) {
+					traceStage(ctx, chainID, `fees`, func(ctx context.Context) {
+						tFees := time.Now()
+						fees.RefreshVaultsFees(chainID)
+						logs.Info(fmt.Sprintf("💸 [FEES] fee changes and accountant configs done chain=%d took=%s", chainID, time.Since(tFees)))
+					})
+				}
+
+				if !skipOnSunsetChain(chainID, `losses`) {
+					traceStage(ctx, chainID, `losses`, func(ctx context.Context) {
+						tLosses := time.Now()
+						losses.RefreshVaultsLosses(chainID)
+						logs.Info(fmt.Sprintf("📉 [LOSSES] reported losses done chain=%d took=%s", chainID, time.Since(tLosses)))
+					})
+				}
+
+				if treasury.IsTracked(chainID) && !skipOnSunsetChain(chainID, `treasury`) {
+					traceStage(ctx, chainID, `treasury`, func(ctx context.Context) {
+						tTreasury := time.Now()
+						treasury.RefreshTreasury(chainID)
+						logs.Info(fmt.Sprintf("🏦 [TREASURY] holdings and transfers done chain=%d took=%s", chainID, time.Since(tTreasury)))
+					})
+				}
+
+				traceStage(ctx, chainID, `inception`, func(ctx context.Context) {
+					tInception := time.Now()
+					inception.RefreshVaultsInception(chainID)
+					logs.Info(fmt.Sprintf("🐣 [INCEPTION] backfill done chain=%d took=%s", chainID, time.Since(tInception)))
+				})
 			},
 		),
 		gocron.WithStartAt(gocron.WithStartImmediately()),
 	)
+
+	// Schedule the measure of the lag of the data behind the head of the chain every 5 minutes
+	scheduler.NewJob(
+		gocron.DurationJob(
+			FRESHNESS_CHECK_INTERVAL,
+		),
+		gocron.NewTask(
+			func() {
+				if skipWhileRPCDown(chainID, "FRESHNESS") {
+					return
+				}
+				measureChainFreshness(chainID)
+			},
+		),
+	)
+
+	// Schedule the verification of the stored state against the chain every 24 hours
+	scheduler.NewJob(
+		gocron.DurationJob(
+			time.Hour*24,
+		),
+		gocron.NewTask(
+			func() {
+				if skipWhilePaused(chainID, "VERIFY24H") || skipWhileSequencerDown(chainID, "VERIFY24H") || skipWhileRPCDown(chainID, "VERIFY24H") {
+					return
+				}
+				id, started, _ := beginJob(chainID, "VERIFY24H")
+				defer endJob(chainID, "VERIFY24H", id, started)
+
+				drifts := fetcher.VerifyChainState(chainID)
+				logs.Info(fmt.Sprintf("🔍 [VERIFY] done chain=%d drifts=%d", chainID, len(drifts)))
+			},
+		),
+	)
 	scheduler.Start()
 
 	// Load persisted APY data on initialization
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"time"
+
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/exporter"
+	"github.com/yearn/ydaemon/internal/fetcher"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/apr"
+)
+
+/**************************************************************************************************
+** recordVaultsMetrics appends the current APY and TVL of every vault of the chain to their rolling
+** history and persists it. The APY is the forward net APY when known, the historical net APY
+** otherwise. This powers the APY and TVL deltas of the list endpoints and the movers endpoint.
+** The APY is also averaged into the daily APY history used for the APY statistics of the vaults,
+** and the points are exported to the time-series database, if any, with the price per share.
+**************************************************************************************************/
+func recordVaultsMetrics(chainID uint64) int {
+	now := uint64(time.Now().Unix())
+	_, allVaults := storage.ListVaults(chainID)
+	points := make([]exporter.TVaultPoint, 0, len(allVaults))
+	for _, vault := range allVaults {
+		snapshot := models.TVaultMetricsSnapshot{
+			Timestamp: now,
+			TVL:       fetcher.BuildVaultTVL(vault).TVL,
+		}
+		hasAPY := false
+		if computedAPY, ok := apr.GetComputedAPY(chainID, vault.Address); ok {
+			if vaultAPY, ok := computedAPY.(apr.TVaultAPY); ok {
+				if vaultAPY.ForwardAPY.NetAPY != nil {
+					snapshot.APY, _ = vaultAPY.ForwardAPY.NetAPY.Float64()
+					hasAPY = true
+				} else if vaultAPY.NetAPY != nil {
+					snapshot.APY, _ = vaultAPY.NetAPY.Float64()
+					hasAPY = true
+				}
+			}
+		}
+		storage.StoreVaultMetricsSnapshot(chainID, vault.Address, snapshot)
+		if hasAPY {
+			storage.StoreVaultDailyAPY(chainID, vault.Address, now, snapshot.APY)
+		}
+
+		point := exporter.TVaultPoint{
+			ChainID:   chainID,
+			Vault:     vault.Address.Hex(),
+			Timestamp: now,
+			TVL:       snapshot.TVL,
+		}
+		if hasAPY {
+			point.APY = &snapshot.APY
+		}
+		if asset, ok := storage.GetERC20(chainID, vault.AssetAddress); ok && vault.LastPricePerShare != nil {
+			point.PricePerShare, _ = helpers.ToNormalizedAmount(vault.LastPricePerShare, asset.Decimals).Float64()
+		}
+		points = append(points, point)
+	}
+	exporter.ExportVaultPoints(chainID, points)
+	storage.StoreMetricsToJson(chainID)
+	storage.StoreAPYHistoryToJson(chainID)
+	return len(allVaults)
+}
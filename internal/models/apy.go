@@ -1,6 +1,26 @@
 package models
 
-import "github.com/yearn/ydaemon/common/bigNumber"
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+)
+
+/**************************************************************************************************
+** TAPRPrimarySource tells which APR is used as the forward net APY of a v3 vault:
+** - oracle: the APR returned by the APR oracle for the vault
+** - debtRatio: the APRs of the strategies weighted by their current debt ratio (the "v2" APR)
+** - metaVault: like debtRatio, with the strategies being other yVaults replaced by the forward
+**   APY computed for these vaults
+** - override: the APR of the vault is overridden by an operator (see processes/apr)
+**************************************************************************************************/
+type TAPRPrimarySource string
+
+const (
+	APRPrimarySourceOracle    TAPRPrimarySource = "oracle"
+	APRPrimarySourceDebtRatio TAPRPrimarySource = "debtRatio"
+	APRPrimarySourceMetaVault TAPRPrimarySource = "metaVault"
+	APRPrimarySourceOverride  TAPRPrimarySource = "override"
+)
 
 type TFees struct {
 	Performance *bigNumber.Float `json:"performance"`
@@ -18,11 +38,53 @@ type TCompositeData struct {
 	V3OracleStratRatioAPR *bigNumber.Float `json:"v3OracleStratRatioAPR,omitempty"`
 	KeepCRV               *bigNumber.Float `json:"keepCRV,omitempty"`
 	KeepVelo              *bigNumber.Float `json:"keepVELO,omitempty"`
+	EmissionsMinBoostAPR  *bigNumber.Float `json:"emissionsMinBoostAPR,omitempty"` // dYFI emitted by the veYFI gauge, without veYFI
+	EmissionsMaxBoostAPR  *bigNumber.Float `json:"emissionsMaxBoostAPR,omitempty"` // dYFI emitted by the veYFI gauge, with the max boost
+	APROverrides          []TAPROverride   `json:"aprOverrides,omitempty"`         // APRs used in place of the oracle ones
+	Rewards               []TRewardAPR     `json:"rewards,omitempty"`              // Part of the net APY paid in each reward token
+}
+
+/**************************************************************************************************
+** TRewardAPR is the part of a net APY paid in a reward token (CRV, CVX, VELO, ...), after the
+** performance fee and weighted by the debt ratio of the strategy.
+**************************************************************************************************/
+type TRewardAPR struct {
+	Token common.Address   `json:"token"`
+	APR   *bigNumber.Float `json:"apr"`
+}
+
+/**************************************************************************************************
+** TRewardContribution is the part of the headline APY of a vault (forward net APY plus staking
+** rewards) paid in a reward token. Source is `forward` for the rewards harvested by the strategies
+** and `staking` for the ones of the staking contract of the vault. Share is the fraction of the
+** headline APY and Sensitivity the change of the headline APY for a 10% move of the price of the
+** token, the rewards being valued at its current price.
+**************************************************************************************************/
+type TRewardContribution struct {
+	Token       common.Address `json:"token"`
+	Symbol      string         `json:"symbol"`
+	Source      string         `json:"source"`
+	APY         float64        `json:"apy"`
+	Share       float64        `json:"share"`
+	Sensitivity float64        `json:"sensitivity"`
+}
+
+/**************************************************************************************************
+** TAPROverride is an APR used in place of the oracle one for a strategy, or a whole vault, with
+** where it comes from: `config` for the APRs pinned by an operator, `plugin:<name>` for the ones
+** computed by a registered function.
+**************************************************************************************************/
+type TAPROverride struct {
+	Address common.Address `json:"address"`
+	APR     float64        `json:"apr"`
+	Source  string         `json:"source"`
+	Reason  string         `json:"reason,omitempty"`
 }
 
 type TExtraRewards struct {
-	StakingRewardsAPY *bigNumber.Float `json:"stakingRewardsAPY"`
-	GammaRewardAPY    *bigNumber.Float `json:"gammaRewardAPY"`
+	StakingRewardsAPY  *bigNumber.Float `json:"stakingRewardsAPY"`
+	GammaRewardAPY     *bigNumber.Float `json:"gammaRewardAPY"`
+	UnderlyingAssetAPR *bigNumber.Float `json:"underlyingAssetAPR,omitempty"` // Intrinsic APR of a yield-bearing asset (sDAI, wstETH, ...)
 }
 
 type THistoricalPoints struct {
@@ -38,9 +100,27 @@ type TPricePerShare struct {
 }
 
 type TForwardAPY struct {
-	Type      string           `json:"type"`
-	NetAPY    *bigNumber.Float `json:"netAPY"`
-	Composite TCompositeData   `json:"composite"`
+	Type               string            `json:"type"`
+	NetAPY             *bigNumber.Float  `json:"netAPY"`
+	NetAPR             *bigNumber.Float  `json:"netAPR,omitempty"`             // NetAPY before its compounding, as a simple annualized rate
+	NetAPYDeployedOnly *bigNumber.Float  `json:"netAPYDeployedOnly,omitempty"` // APY earned by the assets allocated to the strategies
+	IdleRatio          *bigNumber.Float  `json:"idleRatio,omitempty"`          // Fraction of the total assets not allocated to any strategy
+	PrimarySource      TAPRPrimarySource `json:"primarySource,omitempty"`      // Source of the NetAPY for the v3 vaults
+	TotalAPY           *bigNumber.Float  `json:"totalAPY,omitempty"`           // NetAPY combined with the APY of a yield-bearing asset
+	CompoundingPeriods float64           `json:"compoundingPeriods,omitempty"` // Compounding periods per year of NetAPY, when refined by the tends of the strategies
+	DivergenceWarning  *TAPYDivergence   `json:"divergenceWarning,omitempty"`  // Set when NetAPY diverges from the APY realized over the last 7 days
+	Composite          TCompositeData    `json:"composite"`
+}
+
+/**************************************************************************************************
+** TAPYDivergence flags a forward APY diverging from the APY realized by the vault over the last 7
+** days, from its price per share. Ratio is the largest of the two divided by the smallest, 0 when
+** one of them is not positive.
+**************************************************************************************************/
+type TAPYDivergence struct {
+	ForwardAPY  float64 `json:"forwardAPY"`
+	RealizedAPY float64 `json:"realizedAPY"`
+	Ratio       float64 `json:"ratio,omitempty"`
 }
 
 type TVaultAPY struct {
@@ -51,6 +131,12 @@ type TVaultAPY struct {
 	PricePerShare TPricePerShare    `json:"pricePerShare"`
 	Extra         TExtraRewards     `json:"extra"`
 	ForwardAPY    TForwardAPY       `json:"forwardAPY"`
+	FeeImpact     TFeeImpact        `json:"feeImpact"`
+	GasImpact     *TGasImpact       `json:"gasImpact,omitempty"` // Only for the small vaults of the chains with a gas policy
+
+	EntryExitFeeBps uint64 `json:"entryExitFeeBps,omitempty"` // Entry + exit fees of the external vaults used by the strategies
+
+	RewardContributions []TRewardContribution `json:"rewardContributions,omitempty"` // Part of the headline APY paid in each reward token
 }
 
 type TStrategyAPY struct {
@@ -59,3 +145,59 @@ type TStrategyAPY struct {
 	NetAPY    *bigNumber.Float `json:"netAPY"`
 	Composite TCompositeData   `json:"composite"`
 }
+
+/**************************************************************************************************
+** TFeeSnapshot is a point in the fee history of a vault, built from the fee events indexed for it.
+** It holds the fees effective from the block of the change until the next one (or now).
+**************************************************************************************************/
+type TFeeSnapshot struct {
+	BlockNumber    uint64 `json:"blockNumber"`
+	Timestamp      uint64 `json:"timestamp"`
+	PerformanceFee uint64 `json:"performanceFee"` // In basis points
+	ManagementFee  uint64 `json:"managementFee"`  // In basis points
+}
+
+/**************************************************************************************************
+** TFeeImpact splits the net yield of a vault into the gross yield generated by its strategies and
+** the part eaten by the fees. All values are expressed as APR fractions (0.05 = 5%).
+**************************************************************************************************/
+type TFeeImpact struct {
+	GrossAPR   *bigNumber.Float `json:"grossAPR"`
+	NetAPR     *bigNumber.Float `json:"netAPR"`
+	FeeDragAPR *bigNumber.Float `json:"feeDragAPR"`
+}
+
+/**************************************************************************************************
+** TGasImpact splits the forward APY of a small vault into the gross APY and the APY left once the
+** amortized cost of the harvests is paid, L1 data fees included on the rollups. The APYs are
+** fractions (0.05 = 5%), the harvest cost is in USD.
+**************************************************************************************************/
+type TGasImpact struct {
+	GrossAPY        *bigNumber.Float `json:"grossAPY"`
+	NetAPY          *bigNumber.Float `json:"netAPY"`
+	GasDragAPY      *bigNumber.Float `json:"gasDragAPY"`
+	HarvestCostUSD  float64          `json:"harvestCostUSD"`
+	HarvestsPerYear uint64           `json:"harvestsPerYear"`
+}
+
+/**************************************************************************************************
+** TVaultMetricsSnapshot is a point in the rolling history of the APY and TVL of a vault, recorded
+** at every snapshot of the daemon. The APY is the forward net APY when known, the historical net
+** APY otherwise, expressed as a fraction (0.05 = 5%). The TVL is in USD.
+**************************************************************************************************/
+type TVaultMetricsSnapshot struct {
+	Timestamp uint64  `json:"timestamp"`
+	APY       float64 `json:"apy"`
+	TVL       float64 `json:"tvl"`
+}
+
+/**************************************************************************************************
+** TVaultDailyAPY is a point in the long term APY history of a vault: the average of the APYs
+** recorded during a UTC day. The timestamp is the start of the day and Samples the number of
+** snapshots averaged so far.
+**************************************************************************************************/
+type TVaultDailyAPY struct {
+	Timestamp uint64  `json:"timestamp"`
+	APY       float64 `json:"apy"`
+	Samples   uint64  `json:"samples"`
+}
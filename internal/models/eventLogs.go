@@ -17,3 +17,28 @@ type TEventRewardAdded struct {
 	TxIndex     uint           `json:"-"`
 	LogIndex    uint           `json:"-"`
 }
+
+// TVaultEventType is the type of a strategy lifecycle event emitted by a vault
+type TVaultEventType string
+
+const (
+	VaultEventStrategyAdded    TVaultEventType = `StrategyAdded`
+	VaultEventStrategyMigrated TVaultEventType = `StrategyMigrated`
+)
+
+// TEventBlock is the version agnostic form of the strategy lifecycle events of a vault. Each vault
+// version emits its own events (StrategyAdded with different arguments for v0.2.x, v0.3.x and
+// v0.4.x, StrategyChanged for v3) which are all mapped to this model. NewStrategyAddress is only
+// set for a migration.
+type TEventBlock struct {
+	EventType          TVaultEventType `json:"eventType"`
+	ChainID            uint64          `json:"chainID"`
+	VaultAddress       common.Address  `json:"vaultAddress"`
+	VaultVersion       string          `json:"vaultVersion"`
+	StrategyAddress    common.Address  `json:"strategyAddress"`
+	NewStrategyAddress common.Address  `json:"newStrategyAddress"`
+	TxHash             common.Hash     `json:"txHash"`
+	BlockNumber        uint64          `json:"blockNumber"`
+	TxIndex            uint            `json:"-"`
+	LogIndex           uint            `json:"-"`
+}
@@ -10,4 +10,6 @@ type TPrices struct {
 	Price          *bigNumber.Int   `json:"price"`
 	HumanizedPrice *bigNumber.Float `json:"humanizedPrice"`
 	Source         string           `json:"source"`
+	IsStale        bool             `json:"isStale,omitempty"`    // Last known price carried over, no source priced the token
+	StaleSince     int64            `json:"staleSince,omitempty"` // Unix time of the first refresh without a price
 }
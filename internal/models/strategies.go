@@ -65,15 +65,18 @@ type TStrategy struct {
 	KeepCRV            *bigNumber.Int   `json:"keepCRV"`
 	KeepCRVPercent     *bigNumber.Int   `json:"keepCRVPercent"`
 	KeepCVX            *bigNumber.Int   `json:"keepCVX"`
-	LastTotalDebt      *bigNumber.Int   `json:"lastTotalDebt"`           // Used to filter strategies and by the FE
-	LastTotalLoss      *bigNumber.Int   `json:"lastTotalLoss"`           // Used by the FE
-	LastTotalGain      *bigNumber.Int   `json:"lastTotalGain"`           // Used by the FE
-	LastPerformanceFee *bigNumber.Int   `json:"lastPerformanceFee"`      // Used for APR calculation and by the FE
-	LastReport         *bigNumber.Int   `json:"lastReport"`              // Used by the FE
-	LastDebtRatio      *bigNumber.Int   `json:"lastDebtRatio,omitempty"` // Only > 0.2.2 | Used by the APY process
-	NetAPR             float64          `json:"netAPR"`                  // The net APR of the strategy
-	APRType            TStrategyAPRType `json:"aprType"`                 // The type of APR of the strategy
-	Protocols          []string         `json:"protocols"`               // The protocols used by the strategy
+	LastTotalDebt      *bigNumber.Int   `json:"lastTotalDebt"`                // Used to filter strategies and by the FE
+	LastTotalLoss      *bigNumber.Int   `json:"lastTotalLoss"`                // Used by the FE
+	LastTotalGain      *bigNumber.Int   `json:"lastTotalGain"`                // Used by the FE
+	LastPerformanceFee *bigNumber.Int   `json:"lastPerformanceFee"`           // Used for APR calculation and by the FE
+	LastReport         *bigNumber.Int   `json:"lastReport"`                   // Used by the FE
+	LastDebtRatio      *bigNumber.Int   `json:"lastDebtRatio,omitempty"`      // Only > 0.2.2 | Used by the APY process
+	LastMaxDebt        *bigNumber.Int   `json:"lastMaxDebt,omitempty"`        // Only v3 | The max_debt of the strategy in its vault
+	LastMaxWithdraw    *bigNumber.Int   `json:"lastMaxWithdraw,omitempty"`    // Only v3 | The assets the vault can withdraw from the strategy right now
+	LastUnrealisedLoss *bigNumber.Int   `json:"lastUnrealisedLoss,omitempty"` // Only v3 | The share of the debt the vault would lose withdrawing it all
+	NetAPR             float64          `json:"netAPR"`                       // The net APR of the strategy
+	APRType            TStrategyAPRType `json:"aprType"`                      // The type of APR of the strategy
+	Protocols          []string         `json:"protocols"`                    // The protocols used by the strategy
 }
 
 /**************************************************************************************************
@@ -32,6 +32,55 @@ const (
 	VaultCategoryAutomatic TVaultCategoryType = "auto"
 )
 
+/**************************************************************************************************
+** TZeroAssetsAPRPolicy defines how the forward APR of a v3 vault is estimated when the vault has
+** no assets yet, and the oracle therefore returns 0%.
+** - targetDebtRatio: the strategies APRs are weighted by their target debt ratio (default)
+** - average: the strategies APRs are averaged, each queued strategy having the same weight
+** - none: no estimation, the forward APR stays at 0%
+**************************************************************************************************/
+type TZeroAssetsAPRPolicy string
+
+const (
+	ZeroAssetsAPRPolicyTargetDebtRatio TZeroAssetsAPRPolicy = "targetDebtRatio"
+	ZeroAssetsAPRPolicyAverage         TZeroAssetsAPRPolicy = "average"
+	ZeroAssetsAPRPolicyNone            TZeroAssetsAPRPolicy = "none"
+)
+
+/**************************************************************************************************
+** TVaultStage describes where a vault is in its lifecycle. A vault progresses through the stages
+** in order: experimental -> endorsed -> deprecated -> retired.
+** - experimental: the vault is not endorsed yet, or its TVL is below the endorsed threshold
+** - endorsed: the vault is endorsed by a registry and holds a meaningful TVL
+** - deprecated: a migration is available or the vault is in emergency shutdown
+** - retired: the vault is retired and should not receive new deposits
+**************************************************************************************************/
+type TVaultStage string
+
+const (
+	VaultStageExperimental TVaultStage = "experimental"
+	VaultStageEndorsed     TVaultStage = "endorsed"
+	VaultStageDeprecated   TVaultStage = "deprecated"
+	VaultStageRetired      TVaultStage = "retired"
+)
+
+/**************************************************************************************************
+** IsVaultRetired checks if a vault is in the retired stage. The operator override of the stage
+** applies as soon as it is set, the stage computed on the last refresh otherwise, and the retired
+** flag of the metadata is only used until the vault got its first stage. It is the one check of
+** the retirement of a vault, for the API and the processes to agree on it.
+**************************************************************************************************/
+func IsVaultRetired(vault TVault) bool {
+	switch vault.Metadata.StageOverride {
+	case VaultStageExperimental, VaultStageEndorsed, VaultStageDeprecated, VaultStageRetired:
+		return vault.Metadata.StageOverride == VaultStageRetired
+	}
+	if vault.Stage == `` {
+		return vault.Metadata.IsRetired
+	}
+	return vault.Stage == VaultStageRetired
+}
+
 type TExtraProperties struct {
 	YieldVaultAddress       string `json:"yieldVaultAddress,omitempty"`
 	YearnVaultAsset         string `json:"yearnVaultAsset,omitempty"`
@@ -57,9 +106,29 @@ type TVaultsFromRegistry struct {
 
 // TTVL holds the info about the value locked in a vault
 type TTVL struct {
-	TotalAssets *bigNumber.Int `json:"totalAssets"`
-	TVL         float64        `json:"tvl"`
-	Price       float64        `json:"price"`
+	TotalAssets     *bigNumber.Int  `json:"totalAssets"`
+	TVL             float64         `json:"tvl"`
+	Price           float64         `json:"price"`
+	Breakdown       []TTVLComponent `json:"breakdown,omitempty"`       // Valuation of each constituent, for LP tokens
+	IsPriceStale    bool            `json:"isPriceStale,omitempty"`    // Price is the last known one, no source priced the asset
+	PriceStaleSince int64           `json:"priceStaleSince,omitempty"` // Unix time of the first refresh without a price
+}
+
+// TTVLBreakdown is the decomposition of the TVL of a vault, with the total assets it was computed
+// with so it can follow the total assets of the vault until the next decomposition.
+type TTVLBreakdown struct {
+	TotalAssets  *bigNumber.Int  `json:"totalAssets"`
+	VirtualPrice float64         `json:"virtualPrice"`
+	Components   []TTVLComponent `json:"components"`
+}
+
+// TTVLComponent is the share of a constituent of an LP token held by a vault, valued at its own price.
+type TTVLComponent struct {
+	Address common.Address `json:"address"`
+	Symbol  string         `json:"symbol"`
+	Amount  float64        `json:"amount"`
+	Price   float64        `json:"price"`
+	Value   float64        `json:"value"`
 }
 
 // TMigration helps us to know if a vault is in the process of being migrated.
@@ -131,6 +200,10 @@ type TVaultMetadata struct {
 	Inclusion      TInclusion         `json:"inclusion"`      // Inclusion is a special field to know "where" the vault should be displayed.
 	RiskLevel      int8               `json:"riskLevel"`      // The risk level of the vault (1 to 5, -1 if not set)
 	RiskScore      TRiskScore         `json:"riskScore"`      // The risk score of the vault
+
+	ZeroAssetsAPRPolicy TZeroAssetsAPRPolicy `json:"zeroAssetsAPRPolicy,omitempty"` // How to estimate the forward APR when the vault has no assets
+	StageOverride       TVaultStage          `json:"stageOverride,omitempty"`       // Operator override of the computed lifecycle stage
+	V2APROverride       *bool                `json:"v2APROverride,omitempty"`       // Forces the V2 APR on or off in place of the category and chain defaults (only for V3 vaults)
 }
 
 // TVault is the main structure returned by the API when trying to get all the vaults for a specific network
@@ -153,9 +226,12 @@ type TVault struct {
 	EmergencyShutdown bool   `json:"emergencyShutdown"` // If the vault is in emergency shutdown
 
 	// Mutable elements. They will often change
-	LastActiveStrategies []common.Address `json:"lastActiveStrategies"` // The list of "active" strategies via their withdrawal queue
-	LastPricePerShare    *bigNumber.Int   `json:"lastPricePerShare"`    // Price per share of the vault
-	LastTotalAssets      *bigNumber.Int   `json:"lastTotalAssets"`      // Total assets locked in the vault (from blockchain or Kong)
+	LastActiveStrategies []common.Address `json:"lastActiveStrategies"`    // The list of "active" strategies via their withdrawal queue
+	LastPricePerShare    *bigNumber.Int   `json:"lastPricePerShare"`       // Price per share of the vault
+	LastTotalAssets      *bigNumber.Int   `json:"lastTotalAssets"`         // Total assets locked in the vault (from blockchain or Kong)
+	DefaultQueue         []common.Address `json:"defaultQueue,omitempty"`  // Only v3 | The strategies withdrawn from, in order, without the manual ones
+	LastTotalIdle        *bigNumber.Int   `json:"lastTotalIdle,omitempty"` // Only v3 | The assets of the vault not deposited in a strategy
+	Stage                TVaultStage      `json:"stage,omitempty"`         // The lifecycle stage of the vault, as of the last refresh
 
 	// Kong-sourced data (single source of truth for TVL and debts)
 	KongTVL   string `json:"kongTvl,omitempty"`   // TVL from Kong API (tvl.close field)
@@ -165,6 +241,25 @@ type TVault struct {
 	Metadata TVaultMetadata `json:"metadata"` // The metadata of the vault
 }
 
+/**************************************************************************************************
+** TVaultInception is the creation of a vault, backfilled from its creation transaction: the block
+** and the transaction it was deployed in, its deployer (the sender of the transaction, a factory
+** being called by it), its price per share and the price of its asset at that block, and the
+** parameters it was deployed with, nil when the vault doesn't expose them (the fees of the v3
+** vaults being set by their accountant).
+**************************************************************************************************/
+type TVaultInception struct {
+	Block          uint64         `json:"block"`
+	Timestamp      uint64         `json:"timestamp"`
+	TxHash         common.Hash    `json:"txHash"`
+	Deployer       common.Address `json:"deployer"`
+	PricePerShare  *bigNumber.Int `json:"pricePerShare"`
+	AssetPriceUSD  *float64       `json:"assetPriceUSD,omitempty"`
+	ManagementFee  *uint64        `json:"managementFee,omitempty"`
+	PerformanceFee *uint64        `json:"performanceFee,omitempty"`
+	DepositLimit   *bigNumber.Int `json:"depositLimit,omitempty"`
+}
+
 type TLegacyAPIAPY struct {
 	Type              string  `json:"type"`
 	GrossAPR          float64 `json:"gross_apr"`
@@ -239,6 +334,7 @@ type TVaultCmsMetadataSchema struct {
 	IsHighlighted  bool               `json:"isHighlighted"`
 	IsPool         bool               `json:"isPool"`
 	ShouldUseV2APR bool               `json:"shouldUseV2APR"`
+	V2APROverride  *bool              `json:"v2APROverride,omitempty"`
 	Migration      TMigration         `json:"migration"`
 	Stability      TStability         `json:"stability"`
 	Category       *string            `json:"category,omitempty"`
@@ -249,6 +345,9 @@ type TVaultCmsMetadataSchema struct {
 	UINotice       *string            `json:"uiNotice,omitempty"`
 	Protocols      []TCmsProtocolType `json:"protocols"`
 	Inclusion      TInclusion         `json:"inclusion"`
+
+	ZeroAssetsAPRPolicy *string `json:"zeroAssetsAPRPolicy,omitempty"`
+	Stage               *string `json:"stage,omitempty"`
 }
 
 type CoercibleUint64 struct {
@@ -0,0 +1,29 @@
+package models
+
+import "testing"
+
+/**************************************************************************************************
+** TestIsVaultRetired checks that the operator override of the stage applies before the stage of
+** the last refresh, and that the retired flag is only used for the vaults without a stage yet.
+**************************************************************************************************/
+func TestIsVaultRetired(t *testing.T) {
+	testCases := []struct {
+		name     string
+		vault    TVault
+		expected bool
+	}{
+		{`no stage, not retired`, TVault{}, false},
+		{`no stage, retired flag`, TVault{Metadata: TVaultMetadata{IsRetired: true}}, true},
+		{`retired stage`, TVault{Stage: VaultStageRetired}, true},
+		{`endorsed stage with a stale retired flag`, TVault{Stage: VaultStageEndorsed, Metadata: TVaultMetadata{IsRetired: true}}, false},
+		{`retired override before the refresh`, TVault{Stage: VaultStageEndorsed, Metadata: TVaultMetadata{StageOverride: VaultStageRetired}}, true},
+		{`endorsed override of a retired vault`, TVault{Stage: VaultStageRetired, Metadata: TVaultMetadata{StageOverride: VaultStageEndorsed}}, false},
+		{`unknown override`, TVault{Stage: VaultStageRetired, Metadata: TVaultMetadata{StageOverride: `sunset`}}, true},
+	}
+
+	for _, tc := range testCases {
+		if retired := IsVaultRetired(tc.vault); retired != tc.expected {
+			t.Errorf("%s: expected retired to be %v, got %v", tc.name, tc.expected, retired)
+		}
+	}
+}
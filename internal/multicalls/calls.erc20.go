@@ -64,3 +64,31 @@ func GetTotalSupply(name string, contractAddress common.Address) ethereum.Call {
 		Name:     name,
 	}
 }
+
+func GetBalanceOf(name string, contractAddress common.Address, owner common.Address) ethereum.Call {
+	parsedData, err := ERC20ABI.Pack("balanceOf", owner)
+	if err != nil {
+		logs.Error("Error packing ERC20ABI balanceOf", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      ERC20ABI,
+		Method:   `balanceOf`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetAllowance(name string, contractAddress common.Address, owner common.Address, spender common.Address) ethereum.Call {
+	parsedData, err := ERC20ABI.Pack("allowance", owner, spender)
+	if err != nil {
+		logs.Error("Error packing ERC20ABI allowance", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      ERC20ABI,
+		Method:   `allowance`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
@@ -0,0 +1,47 @@
+package multicalls
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+var OPGasPriceOracleABI = parseABI(helpers.OP_GAS_PRICE_ORACLE_ABI)
+var ArbGasInfoABI = parseABI(helpers.ARB_GAS_INFO_ABI)
+
+/**************************************************************************************************
+** GetOPStackL1Fee returns the L1 data fee, in wei, the OP Stack GasPriceOracle predeploy would
+** charge for a transaction with the given calldata.
+**************************************************************************************************/
+func GetOPStackL1Fee(name string, contractAddress common.Address, data []byte) ethereum.Call {
+	parsedData, err := OPGasPriceOracleABI.Pack("getL1Fee", data)
+	if err != nil {
+		logs.Error("Error packing OPGasPriceOracleABI getL1Fee", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      OPGasPriceOracleABI,
+		Method:   `getL1Fee`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+/**************************************************************************************************
+** GetArbitrumPricesInWei returns the prices of the ArbGasInfo precompile. The second value is the
+** price, in wei, of a byte of L1 calldata.
+**************************************************************************************************/
+func GetArbitrumPricesInWei(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := ArbGasInfoABI.Pack("getPricesInWei")
+	if err != nil {
+		logs.Error("Error packing ArbGasInfoABI getPricesInWei", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      ArbGasInfoABI,
+		Method:   `getPricesInWei`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
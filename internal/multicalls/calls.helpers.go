@@ -172,6 +172,34 @@ func GetCurveCoin(name string, contractAddress common.Address, index *big.Int) e
 	}
 }
 
+func GetCurveBalance(name string, contractAddress common.Address, index *big.Int) ethereum.Call {
+	parsedData, err := CurveCoinABI.Pack("balances", index)
+	if err != nil {
+		logs.Error("Error packing CurveCoinABI balances", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      CurveCoinABI,
+		Method:   `balances`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetCurveVirtualPrice(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := CurveCoinABI.Pack("get_virtual_price")
+	if err != nil {
+		logs.Error("Error packing CurveCoinABI get_virtual_price", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      CurveCoinABI,
+		Method:   `get_virtual_price`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
 func GetConvexLockIncentive(name string, contractAddress common.Address) ethereum.Call {
 	parsedData, err := CVXBoosterABI.Pack("lockIncentive")
 	if err != nil {
@@ -0,0 +1,334 @@
+package multicalls
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+var AaveV3DataProviderABI = parseABI(helpers.AAVE_V3_DATA_PROVIDER_ABI)
+var AaveV3ATokenABI = parseABI(helpers.AAVE_V3_ATOKEN_ABI)
+var AaveV3RewardsControllerABI = parseABI(helpers.AAVE_V3_REWARDS_CONTROLLER_ABI)
+var MorphoBlueABI = parseABI(helpers.MORPHO_BLUE_ABI)
+var MorphoIRMABI = parseABI(helpers.MORPHO_IRM_ABI)
+var EulerEVaultABI = parseABI(helpers.EULER_EVAULT_ABI)
+var LlamaLendVaultABI = parseABI(helpers.LLAMALEND_VAULT_ABI)
+var CurveGaugeControllerABI = parseABI(helpers.CURVE_GAUGE_CONTROLLER_ABI)
+var LlamaLendFactoryABI = parseABI(helpers.LLAMALEND_FACTORY_ABI)
+
+/**************************************************************************************************
+** TMorphoMarketParams and TMorphoMarket mirror the structs of Morpho Blue. They are used to pack
+** the arguments of the `borrowRateView` function of the interest rate models.
+**************************************************************************************************/
+type TMorphoMarketParams struct {
+	LoanToken       common.Address
+	CollateralToken common.Address
+	Oracle          common.Address
+	Irm             common.Address
+	Lltv            *big.Int
+}
+type TMorphoMarket struct {
+	TotalSupplyAssets *big.Int
+	TotalSupplyShares *big.Int
+	TotalBorrowAssets *big.Int
+	TotalBorrowShares *big.Int
+	LastUpdate        *big.Int
+	Fee               *big.Int
+}
+
+func GetAaveV3ReserveData(name string, contractAddress common.Address, asset common.Address) ethereum.Call {
+	parsedData, err := AaveV3DataProviderABI.Pack("getReserveData", asset)
+	if err != nil {
+		logs.Error("Error packing AaveV3DataProviderABI getReserveData", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      AaveV3DataProviderABI,
+		Method:   `getReserveData`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetAaveV3ReserveTokensAddresses(name string, contractAddress common.Address, asset common.Address) ethereum.Call {
+	parsedData, err := AaveV3DataProviderABI.Pack("getReserveTokensAddresses", asset)
+	if err != nil {
+		logs.Error("Error packing AaveV3DataProviderABI getReserveTokensAddresses", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      AaveV3DataProviderABI,
+		Method:   `getReserveTokensAddresses`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetAaveV3IncentivesController(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := AaveV3ATokenABI.Pack("getIncentivesController")
+	if err != nil {
+		logs.Error("Error packing AaveV3ATokenABI getIncentivesController", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      AaveV3ATokenABI,
+		Method:   `getIncentivesController`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetAaveV3ATokenTotalSupply(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := AaveV3ATokenABI.Pack("totalSupply")
+	if err != nil {
+		logs.Error("Error packing AaveV3ATokenABI totalSupply", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      AaveV3ATokenABI,
+		Method:   `totalSupply`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetAaveV3RewardsByAsset(name string, contractAddress common.Address, asset common.Address) ethereum.Call {
+	parsedData, err := AaveV3RewardsControllerABI.Pack("getRewardsByAsset", asset)
+	if err != nil {
+		logs.Error("Error packing AaveV3RewardsControllerABI getRewardsByAsset", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      AaveV3RewardsControllerABI,
+		Method:   `getRewardsByAsset`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetAaveV3RewardsData(name string, contractAddress common.Address, asset common.Address, reward common.Address) ethereum.Call {
+	parsedData, err := AaveV3RewardsControllerABI.Pack("getRewardsData", asset, reward)
+	if err != nil {
+		logs.Error("Error packing AaveV3RewardsControllerABI getRewardsData", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      AaveV3RewardsControllerABI,
+		Method:   `getRewardsData`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetMorphoMarket(name string, contractAddress common.Address, marketID common.Hash) ethereum.Call {
+	parsedData, err := MorphoBlueABI.Pack("market", marketID)
+	if err != nil {
+		logs.Error("Error packing MorphoBlueABI market", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      MorphoBlueABI,
+		Method:   `market`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetMorphoMarketParams(name string, contractAddress common.Address, marketID common.Hash) ethereum.Call {
+	parsedData, err := MorphoBlueABI.Pack("idToMarketParams", marketID)
+	if err != nil {
+		logs.Error("Error packing MorphoBlueABI idToMarketParams", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      MorphoBlueABI,
+		Method:   `idToMarketParams`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetMorphoBorrowRateView(name string, irmAddress common.Address, marketParams TMorphoMarketParams, market TMorphoMarket) ethereum.Call {
+	parsedData, err := MorphoIRMABI.Pack("borrowRateView", marketParams, market)
+	if err != nil {
+		logs.Error("Error packing MorphoIRMABI borrowRateView", err)
+	}
+	return ethereum.Call{
+		Target:   irmAddress,
+		Abi:      MorphoIRMABI,
+		Method:   `borrowRateView`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetEulerInterestRate(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := EulerEVaultABI.Pack("interestRate")
+	if err != nil {
+		logs.Error("Error packing EulerEVaultABI interestRate", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      EulerEVaultABI,
+		Method:   `interestRate`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetEulerInterestFee(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := EulerEVaultABI.Pack("interestFee")
+	if err != nil {
+		logs.Error("Error packing EulerEVaultABI interestFee", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      EulerEVaultABI,
+		Method:   `interestFee`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetEulerTotalBorrows(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := EulerEVaultABI.Pack("totalBorrows")
+	if err != nil {
+		logs.Error("Error packing EulerEVaultABI totalBorrows", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      EulerEVaultABI,
+		Method:   `totalBorrows`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetEulerTotalAssets(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := EulerEVaultABI.Pack("totalAssets")
+	if err != nil {
+		logs.Error("Error packing EulerEVaultABI totalAssets", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      EulerEVaultABI,
+		Method:   `totalAssets`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetLlamaLendAPR(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := LlamaLendVaultABI.Pack("lend_apr")
+	if err != nil {
+		logs.Error("Error packing LlamaLendVaultABI lend_apr", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      LlamaLendVaultABI,
+		Method:   `lend_apr`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetCurveGaugeInflationRate(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := CurveGaugeABI.Pack("inflation_rate")
+	if err != nil {
+		logs.Error("Error packing CurveGaugeABI inflation_rate", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      CurveGaugeABI,
+		Method:   `inflation_rate`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetCurveGaugeWorkingSupply(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := CurveGaugeABI.Pack("working_supply")
+	if err != nil {
+		logs.Error("Error packing CurveGaugeABI working_supply", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      CurveGaugeABI,
+		Method:   `working_supply`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetCurveGaugeRelativeWeight(name string, contractAddress common.Address, gauge common.Address) ethereum.Call {
+	parsedData, err := CurveGaugeControllerABI.Pack("gauge_relative_weight", gauge)
+	if err != nil {
+		logs.Error("Error packing CurveGaugeControllerABI gauge_relative_weight", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      CurveGaugeControllerABI,
+		Method:   `gauge_relative_weight`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetLlamaLendMarketCount(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := LlamaLendFactoryABI.Pack("market_count")
+	if err != nil {
+		logs.Error("Error packing LlamaLendFactoryABI market_count", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      LlamaLendFactoryABI,
+		Method:   `market_count`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetLlamaLendVault(name string, contractAddress common.Address, index *big.Int) ethereum.Call {
+	parsedData, err := LlamaLendFactoryABI.Pack("vaults", index)
+	if err != nil {
+		logs.Error("Error packing LlamaLendFactoryABI vaults", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      LlamaLendFactoryABI,
+		Method:   `vaults`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetLlamaLendGauge(name string, contractAddress common.Address, index *big.Int) ethereum.Call {
+	parsedData, err := LlamaLendFactoryABI.Pack("gauges", index)
+	if err != nil {
+		logs.Error("Error packing LlamaLendFactoryABI gauges", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      LlamaLendFactoryABI,
+		Method:   `gauges`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetLlamaLendBorrowedToken(name string, contractAddress common.Address, index *big.Int) ethereum.Call {
+	parsedData, err := LlamaLendFactoryABI.Pack("borrowed_tokens", index)
+	if err != nil {
+		logs.Error("Error packing LlamaLendFactoryABI borrowed_tokens", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      LlamaLendFactoryABI,
+		Method:   `borrowed_tokens`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
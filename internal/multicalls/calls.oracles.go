@@ -0,0 +1,30 @@
+package multicalls
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/contracts"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+var APROracleABI, _ = contracts.YVaultsV3APROracleMetaData.GetAbi()
+
+/**************************************************************************************************
+** GetAPROracleStrategyApr reads the APR the oracle expects for a v3 vault or strategy, without any
+** change of its debt.
+**************************************************************************************************/
+func GetAPROracleStrategyApr(name string, contractAddress common.Address, strategyAddress common.Address) ethereum.Call {
+	parsedData, err := APROracleABI.Pack("getStrategyApr", strategyAddress, big.NewInt(0))
+	if err != nil {
+		logs.Error("Error packing APROracleABI getStrategyApr", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      APROracleABI,
+		Method:   `getStrategyApr`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
@@ -0,0 +1,76 @@
+package multicalls
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+var PermitABI = parseABI(helpers.PERMIT_ABI)
+var Permit2ABI = parseABI(helpers.PERMIT2_ABI)
+
+/**************************************************************************************************
+** GetDomainSeparator, GetPermitNonce and GetPermitVersion read the EIP-712 domain separator, the
+** permit nonce of an owner and the version of the domain of an EIP-2612 token. The version is not
+** exposed by every token.
+**************************************************************************************************/
+func GetDomainSeparator(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := PermitABI.Pack("DOMAIN_SEPARATOR")
+	if err != nil {
+		logs.Error("Error packing PermitABI DOMAIN_SEPARATOR", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      PermitABI,
+		Method:   `DOMAIN_SEPARATOR`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetPermitNonce(name string, contractAddress common.Address, owner common.Address) ethereum.Call {
+	parsedData, err := PermitABI.Pack("nonces", owner)
+	if err != nil {
+		logs.Error("Error packing PermitABI nonces", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      PermitABI,
+		Method:   `nonces`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetPermitVersion(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := PermitABI.Pack("version")
+	if err != nil {
+		logs.Error("Error packing PermitABI version", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      PermitABI,
+		Method:   `version`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+/**************************************************************************************************
+** GetPermit2Allowance reads the allowance given by an owner on a token to a spender through the
+** Permit2 contract: its amount, its expiration and the nonce of the next permit.
+**************************************************************************************************/
+func GetPermit2Allowance(name string, permit2Address common.Address, owner common.Address, token common.Address, spender common.Address) ethereum.Call {
+	parsedData, err := Permit2ABI.Pack("allowance", owner, token, spender)
+	if err != nil {
+		logs.Error("Error packing Permit2ABI allowance", err)
+	}
+	return ethereum.Call{
+		Target:   permit2Address,
+		Abi:      Permit2ABI,
+		Method:   `allowance`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
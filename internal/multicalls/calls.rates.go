@@ -0,0 +1,36 @@
+package multicalls
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+var RateProviderABI = parseABI(helpers.RATE_PROVIDER_ABI)
+
+/**************************************************************************************************
+** GetRateProviderRate reads the exchange rate of a yield-bearing token from its rate provider. The
+** method is one of the env.RATE_PROVIDER_* values, the ERC4626 vaults being read with the assets of
+** 1e18 shares.
+**************************************************************************************************/
+func GetRateProviderRate(name string, contractAddress common.Address, method string) ethereum.Call {
+	args := []interface{}{}
+	if method == env.RATE_PROVIDER_ERC4626 {
+		args = append(args, new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+	}
+	parsedData, err := RateProviderABI.Pack(method, args...)
+	if err != nil {
+		logs.Error("Error packing RateProviderABI "+method, err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      RateProviderABI,
+		Method:   method,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
@@ -0,0 +1,28 @@
+package multicalls
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+var SequencerUptimeFeedABI = parseABI(helpers.SEQUENCER_UPTIME_FEED_ABI)
+
+/**************************************************************************************************
+** GetSequencerStatus returns the latest round of a Chainlink L2 sequencer uptime feed. The answer
+** is 0 when the sequencer is up and 1 when it is down, startedAt being when the status changed.
+**************************************************************************************************/
+func GetSequencerStatus(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := SequencerUptimeFeedABI.Pack("latestRoundData")
+	if err != nil {
+		logs.Error("Error packing SequencerUptimeFeedABI latestRoundData", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      SequencerUptimeFeedABI,
+		Method:   `latestRoundData`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
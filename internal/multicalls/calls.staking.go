@@ -6,12 +6,14 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/yearn/ydaemon/common/contracts"
 	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
 	"github.com/yearn/ydaemon/common/logs"
 )
 
 var StakingABI, _ = contracts.YOptimismStakingRewardMetaData.GetAbi()
 var JuicedStakingABI, _ = contracts.JuicedStakingRewardsMetaData.GetAbi()
 var V3StakingABI, _ = contracts.V3StakingRewardsMetaData.GetAbi()
+var DYFIRedemptionABI = parseABI(helpers.DYFI_REDEMPTION_ABI)
 
 func GetPeriodFinish(name string, contractAddress common.Address) ethereum.Call {
 	parsedData, err := StakingABI.Pack("periodFinish")
@@ -124,3 +126,17 @@ func GetRewardData(name string, contractAddress common.Address, rewardTokenAddre
 		Name:     name,
 	}
 }
+
+func GetDYFIDiscount(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := DYFIRedemptionABI.Pack("discount")
+	if err != nil {
+		logs.Error("Error packing DYFIRedemptionABI discount", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      DYFIRedemptionABI,
+		Method:   `discount`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
@@ -1,9 +1,14 @@
 package multicalls
 
 import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/yearn/ydaemon/common/contracts"
 	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
 	"github.com/yearn/ydaemon/common/logs"
 )
 
@@ -15,6 +20,17 @@ import (
 var YearnStrategyABI, _ = contracts.StrategyBaseMetaData.GetAbi()
 var YearnStrategyV3ABI, _ = contracts.YStrategyV3MetaData.GetAbi()
 var YearnStrategyVeloABI, _ = contracts.YStrategyVeloMetaData.GetAbi()
+var CommonReportTriggerABI = parseABI(helpers.YEARN_COMMON_REPORT_TRIGGER_ABI)
+var Keep3rJobABI = parseABI(helpers.KEEP3R_JOB_ABI)
+var ConvexStrategyABI = parseABI(helpers.CONVEX_STRATEGY_ABI)
+
+func parseABI(rawABI string) *abi.ABI {
+	parsedABI, err := abi.JSON(strings.NewReader(rawABI))
+	if err != nil {
+		logs.Error("Error parsing ABI", err)
+	}
+	return &parsedABI
+}
 
 func GetStategyIsActive(name string, contractAddress common.Address, version string) ethereum.Call {
 	parsedData, err := YearnStrategyABI.Pack("isActive")
@@ -91,6 +107,21 @@ func GetStategyDelegatedAssets(name string, contractAddress common.Address, vers
 	}
 }
 
+func GetStrategyMaxWithdraw(name string, contractAddress common.Address, owner common.Address, version string) ethereum.Call {
+	parsedData, err := YearnStrategyV3ABI.Pack("maxWithdraw", owner)
+	if err != nil {
+		logs.Error("Error packing YearnStrategyV3ABI maxWithdraw", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      YearnStrategyV3ABI,
+		Method:   `maxWithdraw`,
+		CallData: parsedData,
+		Name:     name,
+		Version:  version,
+	}
+}
+
 func GetStrategyName(name string, contractAddress common.Address, version string) ethereum.Call {
 	parsedData, err := YearnStrategyABI.Pack("name")
 	if err != nil {
@@ -269,3 +300,90 @@ func GetLastReport(name string, contractAddress common.Address, version string)
 		Version:  version,
 	}
 }
+
+func GetHarvestTrigger(name string, contractAddress common.Address, version string) ethereum.Call {
+	parsedData, err := YearnStrategyABI.Pack("harvestTrigger", big.NewInt(0))
+	if err != nil {
+		logs.Error("Error packing YearnStrategyABI harvestTrigger", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      YearnStrategyABI,
+		Method:   `harvestTrigger`,
+		CallData: parsedData,
+		Name:     name,
+		Version:  version,
+	}
+}
+
+func GetMaxReportDelay(name string, contractAddress common.Address, version string) ethereum.Call {
+	parsedData, err := YearnStrategyABI.Pack("maxReportDelay")
+	if err != nil {
+		logs.Error("Error packing YearnStrategyABI maxReportDelay", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      YearnStrategyABI,
+		Method:   `maxReportDelay`,
+		CallData: parsedData,
+		Name:     name,
+		Version:  version,
+	}
+}
+
+func GetProfitMaxUnlockTime(name string, contractAddress common.Address, version string) ethereum.Call {
+	parsedData, err := YearnStrategyV3ABI.Pack("profitMaxUnlockTime")
+	if err != nil {
+		logs.Error("Error packing YearnStrategyV3ABI profitMaxUnlockTime", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      YearnStrategyV3ABI,
+		Method:   `profitMaxUnlockTime`,
+		CallData: parsedData,
+		Name:     name,
+		Version:  version,
+	}
+}
+
+func GetStrategyReportTrigger(name string, contractAddress common.Address, strategyAddress common.Address) ethereum.Call {
+	parsedData, err := CommonReportTriggerABI.Pack("strategyReportTrigger", strategyAddress)
+	if err != nil {
+		logs.Error("Error packing CommonReportTriggerABI strategyReportTrigger", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      CommonReportTriggerABI,
+		Method:   `strategyReportTrigger`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetKeep3r(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := Keep3rJobABI.Pack("keep3r")
+	if err != nil {
+		logs.Error("Error packing Keep3rJobABI keep3r", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      Keep3rJobABI,
+		Method:   `keep3r`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetConvexDepositContract(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := ConvexStrategyABI.Pack("depositContract")
+	if err != nil {
+		logs.Error("Error packing ConvexStrategyABI depositContract", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      ConvexStrategyABI,
+		Method:   `depositContract`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
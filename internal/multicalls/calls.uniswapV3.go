@@ -0,0 +1,209 @@
+package multicalls
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+var UniswapV3PoolABI = parseABI(helpers.UNISWAP_V3_POOL_ABI)
+var UniswapV3PositionManagerABI = parseABI(helpers.UNISWAP_V3_POSITION_MANAGER_ABI)
+var UniswapV3FactoryABI = parseABI(helpers.UNISWAP_V3_FACTORY_ABI)
+var GammaHypervisorABI = parseABI(helpers.GAMMA_HYPERVISOR_ABI)
+
+/**************************************************************************************************
+** GetUniV3PoolToken0 and GetUniV3PoolToken1 read the two tokens of a Uniswap v3 pool.
+**************************************************************************************************/
+func GetUniV3PoolToken0(name string, contractAddress common.Address) ethereum.Call {
+	return getUniV3PoolCall(name, contractAddress, `token0`)
+}
+
+func GetUniV3PoolToken1(name string, contractAddress common.Address) ethereum.Call {
+	return getUniV3PoolCall(name, contractAddress, `token1`)
+}
+
+/**************************************************************************************************
+** GetUniV3FeeGrowthGlobal0 and GetUniV3FeeGrowthGlobal1 read the cumulated trading fees of a
+** Uniswap v3 pool per unit of active liquidity, in each of its tokens, as Q128.128 numbers.
+**************************************************************************************************/
+func GetUniV3FeeGrowthGlobal0(name string, contractAddress common.Address) ethereum.Call {
+	return getUniV3PoolCall(name, contractAddress, `feeGrowthGlobal0X128`)
+}
+
+func GetUniV3FeeGrowthGlobal1(name string, contractAddress common.Address) ethereum.Call {
+	return getUniV3PoolCall(name, contractAddress, `feeGrowthGlobal1X128`)
+}
+
+/**************************************************************************************************
+** GetUniV3Slot0 reads the state of a Uniswap v3 pool, the current tick being its second value.
+**************************************************************************************************/
+func GetUniV3Slot0(name string, contractAddress common.Address) ethereum.Call {
+	return getUniV3PoolCall(name, contractAddress, `slot0`)
+}
+
+/**************************************************************************************************
+** GetUniV3Ticks reads the state of an initialized tick of a Uniswap v3 pool, the growth of the fees
+** outside of the tick being its third (token0) and fourth (token1) values.
+**************************************************************************************************/
+func GetUniV3Ticks(name string, contractAddress common.Address, tick int64) ethereum.Call {
+	parsedData, err := UniswapV3PoolABI.Pack(`ticks`, big.NewInt(tick))
+	if err != nil {
+		logs.Error("Error packing UniswapV3PoolABI ticks", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      UniswapV3PoolABI,
+		Method:   `ticks`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func getUniV3PoolCall(name string, contractAddress common.Address, method string) ethereum.Call {
+	parsedData, err := UniswapV3PoolABI.Pack(method)
+	if err != nil {
+		logs.Error("Error packing UniswapV3PoolABI "+method, err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      UniswapV3PoolABI,
+		Method:   method,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+/**************************************************************************************************
+** GetUniV3PositionManagerFactory reads the factory of the pools of a Uniswap v3 position manager.
+**************************************************************************************************/
+func GetUniV3PositionManagerFactory(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := UniswapV3PositionManagerABI.Pack(`factory`)
+	if err != nil {
+		logs.Error("Error packing UniswapV3PositionManagerABI factory", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      UniswapV3PositionManagerABI,
+		Method:   `factory`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+/**************************************************************************************************
+** GetUniV3TokenOfOwnerByIndex reads the id of a position of a Uniswap v3 position manager owned by
+** an address, by its index among the positions of the owner.
+**************************************************************************************************/
+func GetUniV3TokenOfOwnerByIndex(name string, contractAddress common.Address, owner common.Address, index int64) ethereum.Call {
+	parsedData, err := UniswapV3PositionManagerABI.Pack(`tokenOfOwnerByIndex`, owner, big.NewInt(index))
+	if err != nil {
+		logs.Error("Error packing UniswapV3PositionManagerABI tokenOfOwnerByIndex", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      UniswapV3PositionManagerABI,
+		Method:   `tokenOfOwnerByIndex`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+/**************************************************************************************************
+** GetUniV3Position reads a position of a Uniswap v3 position manager: its tokens and fee tier (the
+** third to fifth values), its ticks (the sixth and seventh) and its liquidity (the eighth).
+**************************************************************************************************/
+func GetUniV3Position(name string, contractAddress common.Address, tokenID *big.Int) ethereum.Call {
+	parsedData, err := UniswapV3PositionManagerABI.Pack(`positions`, tokenID)
+	if err != nil {
+		logs.Error("Error packing UniswapV3PositionManagerABI positions", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      UniswapV3PositionManagerABI,
+		Method:   `positions`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+/**************************************************************************************************
+** GetUniV3FactoryPool reads the Uniswap v3 pool of two tokens for a fee tier.
+**************************************************************************************************/
+func GetUniV3FactoryPool(name string, contractAddress common.Address, token0 common.Address, token1 common.Address, fee *big.Int) ethereum.Call {
+	parsedData, err := UniswapV3FactoryABI.Pack(`getPool`, token0, token1, fee)
+	if err != nil {
+		logs.Error("Error packing UniswapV3FactoryABI getPool", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      UniswapV3FactoryABI,
+		Method:   `getPool`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+/**************************************************************************************************
+** GetHypervisorPool reads the Uniswap v3 pool a Gamma hypervisor provides liquidity to.
+**************************************************************************************************/
+func GetHypervisorPool(name string, contractAddress common.Address) ethereum.Call {
+	return getHypervisorCall(name, contractAddress, `pool`)
+}
+
+/**************************************************************************************************
+** GetHypervisorBaseLower, GetHypervisorBaseUpper, GetHypervisorLimitLower and
+** GetHypervisorLimitUpper read the ticks bounding the base and the limit positions of a Gamma
+** hypervisor.
+**************************************************************************************************/
+func GetHypervisorBaseLower(name string, contractAddress common.Address) ethereum.Call {
+	return getHypervisorCall(name, contractAddress, `baseLower`)
+}
+
+func GetHypervisorBaseUpper(name string, contractAddress common.Address) ethereum.Call {
+	return getHypervisorCall(name, contractAddress, `baseUpper`)
+}
+
+func GetHypervisorLimitLower(name string, contractAddress common.Address) ethereum.Call {
+	return getHypervisorCall(name, contractAddress, `limitLower`)
+}
+
+func GetHypervisorLimitUpper(name string, contractAddress common.Address) ethereum.Call {
+	return getHypervisorCall(name, contractAddress, `limitUpper`)
+}
+
+/**************************************************************************************************
+** GetHypervisorBasePosition and GetHypervisorLimitPosition read the liquidity and the amounts of
+** the base and the limit positions of a Gamma hypervisor.
+**************************************************************************************************/
+func GetHypervisorBasePosition(name string, contractAddress common.Address) ethereum.Call {
+	return getHypervisorCall(name, contractAddress, `getBasePosition`)
+}
+
+func GetHypervisorLimitPosition(name string, contractAddress common.Address) ethereum.Call {
+	return getHypervisorCall(name, contractAddress, `getLimitPosition`)
+}
+
+/**************************************************************************************************
+** GetHypervisorTotalAmounts reads the amounts of the two tokens held by a Gamma hypervisor, in its
+** positions and idle.
+**************************************************************************************************/
+func GetHypervisorTotalAmounts(name string, contractAddress common.Address) ethereum.Call {
+	return getHypervisorCall(name, contractAddress, `getTotalAmounts`)
+}
+
+func getHypervisorCall(name string, contractAddress common.Address, method string) ethereum.Call {
+	parsedData, err := GammaHypervisorABI.Pack(method)
+	if err != nil {
+		logs.Error("Error packing GammaHypervisorABI "+method, err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      GammaHypervisorABI,
+		Method:   method,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
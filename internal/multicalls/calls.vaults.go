@@ -16,6 +16,7 @@ import (
 var YearnVaultABI, _ = contracts.Yvault043MetaData.GetAbi()
 var YearnVaultV3ABI, _ = contracts.Yvault300MetaData.GetAbi()
 var AccountantABI, _ = contracts.AccountantMetaData.GetAbi()
+var YearnVaultFactoryGetterABI = parseABI(helpers.YEARN_VAULT_FACTORY_GETTER_ABI)
 
 func GetToken(name string, contractAddress common.Address) ethereum.Call {
 	parsedData, err := YearnVaultABI.Pack("token")
@@ -30,6 +31,29 @@ func GetToken(name string, contractAddress common.Address) ethereum.Call {
 		Name:     name,
 	}
 }
+
+/**************************************************************************************************
+** GetVaultFactory reads the factory which deployed a v3 vault, exposed as `factory` by the recent
+** versions and as `FACTORY` by the first ones: both are read, one of them failing.
+**************************************************************************************************/
+func GetVaultFactory(name string, contractAddress common.Address) []ethereum.Call {
+	calls := []ethereum.Call{}
+	for _, method := range []string{`factory`, `FACTORY`} {
+		parsedData, err := YearnVaultFactoryGetterABI.Pack(method)
+		if err != nil {
+			logs.Error("Error packing YearnVaultFactoryGetterABI "+method, err)
+		}
+		calls = append(calls, ethereum.Call{
+			Target:   contractAddress,
+			Abi:      YearnVaultFactoryGetterABI,
+			Method:   method,
+			CallData: parsedData,
+			Name:     name,
+		})
+	}
+	return calls
+}
+
 func GetPricePerShare(name string, contractAddress common.Address) ethereum.Call {
 	parsedData, err := YearnVaultABI.Pack("pricePerShare")
 	if err != nil {
@@ -174,6 +198,32 @@ func GetDefaultFeeConfig(name string, contractAddress common.Address) ethereum.C
 		Name:     name,
 	}
 }
+func GetUseCustomFeeConfig(name string, contractAddress common.Address, vault common.Address, strategy common.Address) ethereum.Call {
+	parsedData, err := AccountantABI.Pack("useCustomConfig", vault, strategy)
+	if err != nil {
+		logs.Error("Error packing AccountantABI useCustomConfig", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      AccountantABI,
+		Method:   `useCustomConfig`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+func GetCustomFeeConfig(name string, contractAddress common.Address, vault common.Address, strategy common.Address) ethereum.Call {
+	parsedData, err := AccountantABI.Pack("customConfig", vault, strategy)
+	if err != nil {
+		logs.Error("Error packing AccountantABI customConfig", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      AccountantABI,
+		Method:   `customConfig`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
 func GetGuardian(name string, contractAddress common.Address) ethereum.Call {
 	parsedData, err := YearnVaultABI.Pack("guardian")
 	if err != nil {
@@ -278,6 +328,33 @@ func GetDefaultQueue(name string, contractAddress common.Address) ethereum.Call
 		Name:     name,
 	}
 }
+func GetTotalIdle(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := YearnVaultV3ABI.Pack("totalIdle")
+	if err != nil {
+		logs.Error("Error packing YearnVaultV3ABI totalIdle", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      YearnVaultV3ABI,
+		Method:   `totalIdle`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+func GetAssessShareOfUnrealisedLosses(name string, contractAddress common.Address, strategyAddress common.Address, assetsNeeded *big.Int, version string) ethereum.Call {
+	parsedData, err := YearnVaultV3ABI.Pack("assess_share_of_unrealised_losses", strategyAddress, assetsNeeded)
+	if err != nil {
+		logs.Error("Error packing YearnVaultV3ABI assess_share_of_unrealised_losses", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      YearnVaultV3ABI,
+		Method:   `assess_share_of_unrealised_losses`,
+		CallData: parsedData,
+		Name:     name,
+		Version:  version,
+	}
+}
 func GetCreditAvailable(name string, contractAddress common.Address, strategyAddress common.Address, version string) ethereum.Call {
 	parsedData, err := YearnVaultABI.Pack("creditAvailable0", strategyAddress)
 	if err != nil {
@@ -378,3 +455,31 @@ func GetDebtRatio(name string, contractAddress common.Address) ethereum.Call {
 		Name:     name,
 	}
 }
+
+func GetV3Roles(name string, contractAddress common.Address, account common.Address) ethereum.Call {
+	parsedData, err := YearnVaultV3ABI.Pack("roles", account)
+	if err != nil {
+		logs.Error("Error packing YearnVaultV3ABI roles", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      YearnVaultV3ABI,
+		Method:   `roles`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
+
+func GetV3RoleManager(name string, contractAddress common.Address) ethereum.Call {
+	parsedData, err := YearnVaultV3ABI.Pack("role_manager")
+	if err != nil {
+		logs.Error("Error packing YearnVaultV3ABI role_manager", err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      YearnVaultV3ABI,
+		Method:   `role_manager`,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
@@ -0,0 +1,47 @@
+package multicalls
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+var VelodromePoolABI = parseABI(helpers.VELODROME_POOL_ABI)
+
+/**************************************************************************************************
+** GetVeloPoolToken0 and GetVeloPoolToken1 read the two tokens of a Velodrome v2 or Aerodrome pool.
+**************************************************************************************************/
+func GetVeloPoolToken0(name string, contractAddress common.Address) ethereum.Call {
+	return getVeloPoolCall(name, contractAddress, `token0`)
+}
+
+func GetVeloPoolToken1(name string, contractAddress common.Address) ethereum.Call {
+	return getVeloPoolCall(name, contractAddress, `token1`)
+}
+
+/**************************************************************************************************
+** GetVeloPoolIndex0 and GetVeloPoolIndex1 read the cumulated trading fees of a Velodrome v2 or
+** Aerodrome pool per unit of liquidity, in each of its tokens, scaled by 1e18.
+**************************************************************************************************/
+func GetVeloPoolIndex0(name string, contractAddress common.Address) ethereum.Call {
+	return getVeloPoolCall(name, contractAddress, `index0`)
+}
+
+func GetVeloPoolIndex1(name string, contractAddress common.Address) ethereum.Call {
+	return getVeloPoolCall(name, contractAddress, `index1`)
+}
+
+func getVeloPoolCall(name string, contractAddress common.Address, method string) ethereum.Call {
+	parsedData, err := VelodromePoolABI.Pack(method)
+	if err != nil {
+		logs.Error("Error packing VelodromePoolABI "+method, err)
+	}
+	return ethereum.Call{
+		Target:   contractAddress,
+		Abi:      VelodromePoolABI,
+		Method:   method,
+		CallData: parsedData,
+		Name:     name,
+	}
+}
@@ -10,7 +10,20 @@ import (
 )
 
 func Perform(chainID uint64, calls []ethereum.Call, blockNumber *big.Int) map[string][]interface{} {
-	caller := ethereum.MulticallClientForChainID[chainID]
+	return perform(ethereum.GetMulticall(chainID), chainID, calls, blockNumber)
+}
+
+/**************************************************************************************************
+** PerformAtPastBlock performs the calls at a past block on the archive node of the chain when one
+** is configured, on the regular node otherwise (which may not serve old states). The result is nil
+** when the node fails to serve the state of the block.
+**************************************************************************************************/
+func PerformAtPastBlock(chainID uint64, calls []ethereum.Call, blockNumber *big.Int) map[string][]interface{} {
+	caller, _ := ethereum.GetArchiveMulticall(chainID)
+	return perform(caller, chainID, calls, blockNumber)
+}
+
+func perform(caller ethereum.TEthMultiCaller, chainID uint64, calls []ethereum.Call, blockNumber *big.Int) map[string][]interface{} {
 	chain, ok := env.GetChain(chainID)
 	if !ok {
 		return nil
@@ -21,8 +34,12 @@ func Perform(chainID uint64, calls []ethereum.Call, blockNumber *big.Int) map[st
 		logs.Warning("🧮 [MULTICALL START]", "chain", chainID, "calls", callCount)
 		start := time.Now()
 
-		batchSize := chain.MaxBatchSize
-		result := caller.ExecuteByBatch(calls, batchSize, blockNumber)
+		var result map[string][]interface{}
+		if chain.Capabilities.HasMulticall {
+			result = caller.ExecuteByBatch(calls, chain.MaxBatchSize, blockNumber)
+		} else {
+			result = caller.ExecuteSequentially(calls, blockNumber)
+		}
 
 		elapsed := time.Since(start)
 		logs.Success("🧮 [MULTICALL DONE]", "chain", chainID, "took", elapsed)
@@ -0,0 +1,273 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+/**************************************************************************************************
+** Each alert of the daemon belongs to a rule, with a severity. The critical alerts are sent right
+** away, the others are batched per chain and sent as a digest every NOTIFICATION_DIGEST_INTERVAL,
+** for the routine events (fee changes, new strategies, APY drifts) not to drown the ones needing
+** an action. The alerts of a rule whose severity is SEVERITY_OFF, or muted, are dropped.
+**************************************************************************************************/
+const (
+	SEVERITY_CRITICAL = `critical`
+	SEVERITY_WARNING  = `warning`
+	SEVERITY_INFO     = `info`
+	SEVERITY_OFF      = `off`
+)
+
+const (
+	RULE_STATE_DRIFT         = `state_drift`
+	RULE_SHARE_PRICE_ANOMALY = `share_price_anomaly`
+	RULE_PRICE_LOST          = `price_lost`
+	RULE_CHAIN_LAGGING       = `chain_lagging`
+	RULE_CHAIN_CAUGHT_UP     = `chain_caught_up`
+	RULE_SEQUENCER_DOWN      = `sequencer_down`
+	RULE_SEQUENCER_UP        = `sequencer_up`
+	RULE_CHAIN_DEGRADED      = `chain_degraded`
+	RULE_CHAIN_RECOVERED     = `chain_recovered`
+	RULE_FEE_CHANGE          = `fee_change`
+	RULE_NEW_STRATEGY        = `new_strategy`
+	RULE_APY_DRIFT           = `apy_drift`
+	RULE_APY_DIVERGENCE      = `apy_divergence`
+	RULE_STRATEGY_LOSS       = `strategy_loss`
+	RULE_METADATA_SUGGESTION = `metadata_suggestion`
+)
+
+/**************************************************************************************************
+** DEFAULT_SEVERITIES are the severities of the rules not overridden by NOTIFICATION_SEVERITIES. A
+** rule missing from both is a warning.
+**************************************************************************************************/
+var DEFAULT_SEVERITIES = map[string]string{
+	RULE_SHARE_PRICE_ANOMALY: SEVERITY_CRITICAL,
+	RULE_SEQUENCER_DOWN:      SEVERITY_CRITICAL,
+	RULE_CHAIN_DEGRADED:      SEVERITY_CRITICAL,
+	RULE_CHAIN_LAGGING:       SEVERITY_CRITICAL,
+	RULE_STRATEGY_LOSS:       SEVERITY_CRITICAL,
+	RULE_STATE_DRIFT:         SEVERITY_WARNING,
+	RULE_PRICE_LOST:          SEVERITY_WARNING,
+	RULE_FEE_CHANGE:          SEVERITY_WARNING,
+	RULE_APY_DRIFT:           SEVERITY_WARNING,
+	RULE_APY_DIVERGENCE:      SEVERITY_WARNING,
+	RULE_NEW_STRATEGY:        SEVERITY_INFO,
+	RULE_CHAIN_CAUGHT_UP:     SEVERITY_INFO,
+	RULE_SEQUENCER_UP:        SEVERITY_INFO,
+	RULE_CHAIN_RECOVERED:     SEVERITY_INFO,
+	RULE_METADATA_SUGGESTION: SEVERITY_INFO,
+}
+
+/**************************************************************************************************
+** DIGEST_MAX_ITEMS_PER_RULE caps the alerts listed per rule in a digest, the others being counted.
+**************************************************************************************************/
+const DIGEST_MAX_ITEMS_PER_RULE = 10
+
+/**************************************************************************************************
+** TNotification is an alert, and TWebhookPayload the body posted to NOTIFICATION_WEBHOOK_URL: a
+** single critical alert (`alert`) or the digest of the alerts of a chain (`digest`).
+**************************************************************************************************/
+type TNotification struct {
+	ChainID  uint64 `json:"chainID"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	At       int64  `json:"at"`
+}
+
+type TWebhookPayload struct {
+	Type          string          `json:"type"`
+	ChainID       uint64          `json:"chainID"`
+	Notifications []TNotification `json:"notifications"`
+}
+
+/**************************************************************************************************
+** Send sends a message on the chat of the daemon. It's set by the daemon to its Telegram bot.
+**************************************************************************************************/
+var Send func(message string)
+
+var (
+	pending     = make(map[uint64][]TNotification)
+	mutedUntil  = make(map[string]time.Time)
+	pendingMtx  sync.Mutex
+	digestOnce  sync.Once
+	webhookHTTP = &http.Client{Timeout: 10 * time.Second}
+)
+
+/**************************************************************************************************
+** GetSeverity returns the severity of a rule, from NOTIFICATION_SEVERITIES or DEFAULT_SEVERITIES.
+**************************************************************************************************/
+func GetSeverity(rule string) string {
+	if severity, ok := env.NOTIFICATION_SEVERITIES[rule]; ok {
+		return severity
+	}
+	if severity, ok := DEFAULT_SEVERITIES[rule]; ok {
+		return severity
+	}
+	return SEVERITY_WARNING
+}
+
+/**************************************************************************************************
+** IsRule returns true for the rules of the alerts of the daemon.
+**************************************************************************************************/
+func IsRule(rule string) bool {
+	_, ok := DEFAULT_SEVERITIES[rule]
+	return ok
+}
+
+/**************************************************************************************************
+** Mute drops the alerts of a rule for a duration, while an incident is being handled, and returns
+** the time they are sent again at. A duration of zero unmutes the rule.
+**************************************************************************************************/
+func Mute(rule string, duration time.Duration) time.Time {
+	pendingMtx.Lock()
+	defer pendingMtx.Unlock()
+	if duration <= 0 {
+		delete(mutedUntil, rule)
+		return time.Now()
+	}
+	mutedUntil[rule] = time.Now().Add(duration)
+	return mutedUntil[rule]
+}
+
+/**************************************************************************************************
+** IsMuted returns true when the alerts of a rule are muted.
+**************************************************************************************************/
+func IsMuted(rule string) bool {
+	pendingMtx.Lock()
+	defer pendingMtx.Unlock()
+	return time.Now().Before(mutedUntil[rule])
+}
+
+/**************************************************************************************************
+** Notify sends an alert of a chain right away when its rule is critical or when the digests are
+** disabled, and adds it to the next digest of the chain otherwise.
+**************************************************************************************************/
+func Notify(chainID uint64, rule string, message string) {
+	notification := TNotification{
+		ChainID:  chainID,
+		Rule:     rule,
+		Severity: GetSeverity(rule),
+		Message:  message,
+		At:       time.Now().Unix(),
+	}
+	switch {
+	case notification.Severity == SEVERITY_OFF || IsMuted(rule):
+		return
+	case notification.Severity == SEVERITY_CRITICAL || env.NOTIFICATION_DIGEST_INTERVAL <= 0:
+		send(message)
+		postWebhook(TWebhookPayload{Type: `alert`, ChainID: chainID, Notifications: []TNotification{notification}})
+	default:
+		pendingMtx.Lock()
+		pending[chainID] = append(pending[chainID], notification)
+		pendingMtx.Unlock()
+	}
+}
+
+/**************************************************************************************************
+** StartDigest sends the digests of the chains every NOTIFICATION_DIGEST_INTERVAL. This is a no-op
+** when the digests are disabled, and only the first call starts them.
+**************************************************************************************************/
+func StartDigest() {
+	if env.NOTIFICATION_DIGEST_INTERVAL <= 0 {
+		return
+	}
+	digestOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(env.NOTIFICATION_DIGEST_INTERVAL)
+			defer ticker.Stop()
+			for range ticker.C {
+				Flush()
+			}
+		}()
+	})
+}
+
+/**************************************************************************************************
+** Flush sends the digest of each chain with pending alerts, and empties them. It's also called on
+** shutdown, for the pending alerts not to be lost.
+**************************************************************************************************/
+func Flush() {
+	pendingMtx.Lock()
+	digests := pending
+	pending = make(map[uint64][]TNotification)
+	pendingMtx.Unlock()
+
+	chainIDs := []uint64{}
+	for chainID := range digests {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Slice(chainIDs, func(i, j int) bool { return chainIDs[i] < chainIDs[j] })
+	for _, chainID := range chainIDs {
+		send(formatDigest(chainID, digests[chainID]))
+		postWebhook(TWebhookPayload{Type: `digest`, ChainID: chainID, Notifications: digests[chainID]})
+	}
+}
+
+/**************************************************************************************************
+** formatDigest formats the digest of the alerts of a chain, grouped by rule, the rules with the
+** most alerts first.
+**************************************************************************************************/
+func formatDigest(chainID uint64, notifications []TNotification) string {
+	byRule := make(map[string][]TNotification)
+	rules := []string{}
+	for _, notification := range notifications {
+		if _, ok := byRule[notification.Rule]; !ok {
+			rules = append(rules, notification.Rule)
+		}
+		byRule[notification.Rule] = append(byRule[notification.Rule], notification)
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		if len(byRule[rules[i]]) != len(byRule[rules[j]]) {
+			return len(byRule[rules[i]]) > len(byRule[rules[j]])
+		}
+		return rules[i] < rules[j]
+	})
+
+	message := `📋 - yDaemon digest of chain ` + strconv.FormatUint(chainID, 10) + `: ` + strconv.Itoa(len(notifications)) + ` alert(s)`
+	for _, rule := range rules {
+		message += "\n\n" + rule + ` (` + strconv.Itoa(len(byRule[rule])) + `)`
+		for i, notification := range byRule[rule] {
+			if i == DIGEST_MAX_ITEMS_PER_RULE {
+				message += "\n- ... and " + strconv.Itoa(len(byRule[rule])-i) + ` more`
+				break
+			}
+			message += "\n- " + notification.Message
+		}
+	}
+	return message
+}
+
+func send(message string) {
+	if Send != nil {
+		Send(message)
+	}
+}
+
+/**************************************************************************************************
+** postWebhook posts an alert or a digest to the NOTIFICATION_WEBHOOK_URL, if any.
+**************************************************************************************************/
+func postWebhook(payload TWebhookPayload) {
+	if env.NOTIFICATION_WEBHOOK_URL == `` {
+		return
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		logs.Error(`Failed to encode the notification: ` + err.Error())
+		return
+	}
+	resp, err := webhookHTTP.Post(env.NOTIFICATION_WEBHOOK_URL, `application/json`, bytes.NewReader(body))
+	if err != nil {
+		logs.Error(`Failed to call the notification webhook: ` + err.Error())
+		return
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,103 @@
+package notifications
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yearn/ydaemon/common/env"
+)
+
+func setupNotifications(t *testing.T, interval time.Duration, severities map[string]string) *[]string {
+	sent := []string{}
+	previousInterval, previousSeverities, previousSend := env.NOTIFICATION_DIGEST_INTERVAL, env.NOTIFICATION_SEVERITIES, Send
+	env.NOTIFICATION_DIGEST_INTERVAL = interval
+	env.NOTIFICATION_SEVERITIES = severities
+	Send = func(message string) { sent = append(sent, message) }
+	pending = make(map[uint64][]TNotification)
+	mutedUntil = make(map[string]time.Time)
+	t.Cleanup(func() {
+		env.NOTIFICATION_DIGEST_INTERVAL, env.NOTIFICATION_SEVERITIES, Send = previousInterval, previousSeverities, previousSend
+		pending = make(map[uint64][]TNotification)
+		mutedUntil = make(map[string]time.Time)
+	})
+	return &sent
+}
+
+func TestNotifyBatchesNonCriticalAlerts(t *testing.T) {
+	sent := setupNotifications(t, time.Hour, map[string]string{})
+
+	Notify(1, RULE_SHARE_PRICE_ANOMALY, `anomaly`)
+	Notify(1, RULE_FEE_CHANGE, `fee 1`)
+	Notify(1, RULE_FEE_CHANGE, `fee 2`)
+	Notify(10, RULE_NEW_STRATEGY, `strategy`)
+	if len(*sent) != 1 || (*sent)[0] != `anomaly` {
+		t.Fatalf("expected only the critical alert to be sent, got %v", *sent)
+	}
+
+	Flush()
+	if len(*sent) != 3 {
+		t.Fatalf("expected one digest per chain, got %v", *sent)
+	}
+	if !strings.Contains((*sent)[1], `fee_change (2)`) || !strings.Contains((*sent)[1], `- fee 2`) {
+		t.Errorf("unexpected digest of chain 1: %s", (*sent)[1])
+	}
+	if !strings.Contains((*sent)[2], `chain 10`) || !strings.Contains((*sent)[2], `- strategy`) {
+		t.Errorf("unexpected digest of chain 10: %s", (*sent)[2])
+	}
+
+	Flush()
+	if len(*sent) != 3 {
+		t.Errorf("expected the digests to be emptied once sent, got %v", *sent)
+	}
+}
+
+func TestNotifySeverityOverrides(t *testing.T) {
+	sent := setupNotifications(t, time.Hour, map[string]string{
+		RULE_FEE_CHANGE:          SEVERITY_CRITICAL,
+		RULE_SHARE_PRICE_ANOMALY: SEVERITY_OFF,
+	})
+
+	Notify(1, RULE_SHARE_PRICE_ANOMALY, `anomaly`)
+	Notify(1, RULE_FEE_CHANGE, `fee`)
+	Flush()
+	if len(*sent) != 1 || (*sent)[0] != `fee` {
+		t.Errorf("expected only the overridden fee change to be sent, got %v", *sent)
+	}
+}
+
+func TestNotifyMutedRule(t *testing.T) {
+	sent := setupNotifications(t, time.Hour, map[string]string{})
+
+	Mute(RULE_SEQUENCER_DOWN, time.Hour)
+	Notify(1, RULE_SEQUENCER_DOWN, `down`)
+	if len(*sent) != 0 {
+		t.Errorf("expected the muted alert to be dropped, got %v", *sent)
+	}
+
+	Mute(RULE_SEQUENCER_DOWN, 0)
+	Notify(1, RULE_SEQUENCER_DOWN, `down`)
+	if len(*sent) != 1 {
+		t.Errorf("expected the unmuted alert to be sent, got %v", *sent)
+	}
+}
+
+func TestNotifyWithoutDigest(t *testing.T) {
+	sent := setupNotifications(t, 0, map[string]string{})
+
+	Notify(1, RULE_APY_DRIFT, `drift`)
+	if len(*sent) != 1 {
+		t.Errorf("expected the alert to be sent right away, got %v", *sent)
+	}
+}
+
+func TestDigestCapsItemsPerRule(t *testing.T) {
+	notifications := []TNotification{}
+	for i := 0; i < DIGEST_MAX_ITEMS_PER_RULE+3; i++ {
+		notifications = append(notifications, TNotification{Rule: RULE_APY_DRIFT, Message: `drift`})
+	}
+	digest := formatDigest(1, notifications)
+	if strings.Count(digest, `- drift`) != DIGEST_MAX_ITEMS_PER_RULE || !strings.Contains(digest, `... and 3 more`) {
+		t.Errorf("unexpected digest: %s", digest)
+	}
+}
@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/addresses"
+	"github.com/yearn/ydaemon/common/contracts"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/fetcher"
+	"github.com/yearn/ydaemon/internal/indexer"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** A vault not indexed yet can be indexed on demand, for a new deployment to be served right away
+** rather than after the registry events are indexed. The address must be a Yearn vault: it has an
+** API version, and an asset (v3, ERC4626) or a token (v2), and it must be listed by one of the
+** registries of the chain or deployed by one of its factories (see isDeployedVault). It is
+** hydrated like the vaults of the registries, with its strategies and its tokens, and stored with
+** them, so it is refreshed and served by the next snapshots. It is not endorsed until the registry
+** events are indexed.
+**************************************************************************************************/
+var ErrNotAYearnVault = errors.New(`not a Yearn vault`)
+var ErrNotDeployedVault = errors.New(`not listed by a registry nor deployed by a factory of the chain`)
+var ErrHydrationFailed = errors.New(`failed to hydrate the vault`)
+
+var onDemandMtx sync.Mutex
+
+/**************************************************************************************************
+** IndexVaultOnDemand validates and hydrates a vault of a chain, and returns it with true when it
+** was not tracked yet. The vaults are indexed one at a time.
+**************************************************************************************************/
+func IndexVaultOnDemand(chainID uint64, vaultAddress common.Address) (models.TVault, bool, error) {
+	onDemandMtx.Lock()
+	defer onDemandMtx.Unlock()
+
+	if vault, ok := storage.GetVault(chainID, vaultAddress); ok {
+		return vault, false, nil
+	}
+
+	key := vaultAddress.Hex()
+	calls := []ethereum.Call{
+		multicalls.GetAPIVersion(key, vaultAddress),
+		multicalls.GetAsset(key, vaultAddress),
+		multicalls.GetToken(key, vaultAddress),
+	}
+	calls = append(calls, multicalls.GetVaultFactory(key, vaultAddress)...)
+	response := multicalls.Perform(chainID, calls, nil)
+	apiVersion := helpers.DecodeString(response[key+`apiVersion`])
+	if apiVersion == `` {
+		return models.TVault{}, false, ErrNotAYearnVault
+	}
+	vaultFromRegistry := models.TVaultsFromRegistry{
+		ChainID:    chainID,
+		Address:    vaultAddress,
+		APIVersion: apiVersion,
+		Type:       models.TokenTypeExperimentalVault,
+		Kind:       models.VaultKindLegacy,
+	}
+	if strings.HasPrefix(apiVersion, `3`) {
+		vaultFromRegistry.TokenAddress = helpers.DecodeAddress(response[key+`asset`])
+		vaultFromRegistry.Kind = models.VaultKindMultiple
+	} else {
+		vaultFromRegistry.TokenAddress = helpers.DecodeAddress(response[key+`token`])
+	}
+	if (vaultFromRegistry.TokenAddress == common.Address{}) {
+		return models.TVault{}, false, ErrNotAYearnVault
+	}
+	factory := helpers.DecodeAddress(response[key+`factory`])
+	if (factory == common.Address{}) {
+		factory = helpers.DecodeAddress(response[key+`FACTORY`])
+	}
+	if !isDeployedVault(chainID, vaultAddress, vaultFromRegistry.TokenAddress, factory) {
+		return models.TVault{}, false, ErrNotDeployedVault
+	}
+
+	/**********************************************************************************************
+	** The deployment block is unknown without the registry, so the vault is considered active
+	** from the current block: the events indexed from its activation are only the new ones.
+	**********************************************************************************************/
+	if client := ethereum.GetRPC(chainID); client != nil {
+		if blockNumber, err := client.BlockNumber(context.Background()); err == nil {
+			vaultFromRegistry.BlockNumber = blockNumber
+		}
+	}
+
+	vaultMap, strategiesMap := indexer.ProcessNewVault(
+		chainID,
+		map[common.Address]models.TVaultsFromRegistry{vaultAddress: vaultFromRegistry},
+		fetcher.ProcessNewVaultMethodAppend,
+	)
+	vault, ok := vaultMap[vaultAddress]
+	if !ok || (vault.Address == common.Address{}) {
+		return models.TVault{}, false, ErrHydrationFailed
+	}
+	fetcher.RetrieveAllTokens(chainID, vaultMap)
+	logs.Info(fmt.Sprintf("🆕 [ON DEMAND] vault=%s indexed chain=%d version=%s strategies=%d", vaultAddress.Hex(), chainID, apiVersion, len(strategiesMap)))
+	return vault, true, nil
+}
+
+/**************************************************************************************************
+** isDeployedVault returns true if a vault is listed by one of the registries of the chain, or was
+** deployed by one of its factories, the disabled ones included. The v2 registries list the vaults
+** of each token, the v3 registry has the info of its endorsed vaults, the v3 vaults know the
+** factory which deployed them, and the Gamma registry its deployed compounders.
+**************************************************************************************************/
+func isDeployedVault(chainID uint64, vaultAddress common.Address, tokenAddress common.Address, factory common.Address) bool {
+	chain, ok := env.GetChain(chainID)
+	if !ok {
+		return false
+	}
+	client := ethereum.GetRPC(chainID)
+	if client == nil {
+		return false
+	}
+
+	for _, registry := range chain.Registries {
+		switch registry.Version {
+		case 1, 2:
+			currentRegistry, err := contracts.NewYRegistryV2Caller(registry.Address, client)
+			if err != nil {
+				continue
+			}
+			numVaults, err := currentRegistry.NumVaults(nil, tokenAddress)
+			if err != nil {
+				continue
+			}
+			for index := int64(0); index < numVaults.Int64(); index++ {
+				if vault, err := currentRegistry.Vaults(nil, tokenAddress, big.NewInt(index)); err == nil && addresses.Equals(vault, vaultAddress) {
+					return true
+				}
+			}
+		case 3:
+			currentRegistry, err := contracts.NewYRegistryV3Caller(registry.Address, client)
+			if err != nil {
+				continue
+			}
+			numVaults, err := currentRegistry.NumVaults(nil, tokenAddress)
+			if err != nil {
+				continue
+			}
+			for index := int64(0); index < numVaults.Int64(); index++ {
+				if vault, err := currentRegistry.Vaults(nil, tokenAddress, big.NewInt(index)); err == nil && addresses.Equals(vault, vaultAddress) {
+					return true
+				}
+			}
+		case 4:
+			currentRegistry, err := contracts.NewYRegistryV4Caller(registry.Address, client)
+			if err != nil {
+				continue
+			}
+			if info, err := currentRegistry.VaultInfo(nil, vaultAddress); err == nil && (info.Asset != common.Address{}) {
+				return true
+			}
+		case 5:
+			if (factory != common.Address{}) && addresses.Equals(factory, registry.Address) {
+				return true
+			}
+		case 6:
+			currentRegistry, err := contracts.NewYRegistryGammaCaller(registry.Address, client)
+			if err != nil {
+				continue
+			}
+			if isDeployed, err := currentRegistry.IsDeployedStrategy(nil, vaultAddress); err == nil && isDeployed {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+/**************************************************************************************************
+** A chain can be paused by the operators, from the Telegram bot, while its RPC or one of its
+** sources misbehaves. The refreshes of a paused chain are skipped, its data being served as it
+** was, until it is resumed. The pauses are not persisted, a restart resuming every chain.
+**************************************************************************************************/
+var pausedChains = make(map[uint64]bool)
+var pausedChainsMtx sync.RWMutex
+
+/**************************************************************************************************
+** PauseChain and ResumeChain pause and resume the refreshes of a chain. They return false when the
+** chain was already in that state.
+**************************************************************************************************/
+func PauseChain(chainID uint64) bool {
+	pausedChainsMtx.Lock()
+	defer pausedChainsMtx.Unlock()
+	if pausedChains[chainID] {
+		return false
+	}
+	pausedChains[chainID] = true
+	return true
+}
+
+func ResumeChain(chainID uint64) bool {
+	pausedChainsMtx.Lock()
+	defer pausedChainsMtx.Unlock()
+	if !pausedChains[chainID] {
+		return false
+	}
+	delete(pausedChains, chainID)
+	return true
+}
+
+/**************************************************************************************************
+** IsChainPaused returns true when the refreshes of a chain are paused.
+**************************************************************************************************/
+func IsChainPaused(chainID uint64) bool {
+	pausedChainsMtx.RLock()
+	defer pausedChainsMtx.RUnlock()
+	return pausedChains[chainID]
+}
+
+/**************************************************************************************************
+** skipWhilePaused returns true, logging it, when a job of a chain must be skipped because the
+** chain is paused.
+**************************************************************************************************/
+func skipWhilePaused(chainID uint64, name string) bool {
+	if !IsChainPaused(chainID) {
+		return false
+	}
+	logs.Info(fmt.Sprintf("⏸️ [PAUSE] job=%s skipped chain=%d: chain paused", name, chainID))
+	return true
+}
@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+/**************************************************************************************************
+** The stages of the initialization of a chain reported by the init progress endpoint. A chain is
+** initialized once all of them completed for the first time, each chain on its own: a slow chain
+** does not delay the others.
+**************************************************************************************************/
+const (
+	INIT_STAGE_VAULTS = `vaults`
+	INIT_STAGE_TOKENS = `tokens`
+	INIT_STAGE_PRICES = `prices`
+	INIT_STAGE_APY    = `apy`
+)
+
+var INIT_STAGES = []string{INIT_STAGE_VAULTS, INIT_STAGE_TOKENS, INIT_STAGE_PRICES, INIT_STAGE_APY}
+
+const (
+	INIT_STATUS_PENDING = `pending`
+	INIT_STATUS_RUNNING = `running`
+	INIT_STATUS_DONE    = `done`
+)
+
+/**************************************************************************************************
+** TInitStage is the progress of one stage of the initialization of a chain.
+**************************************************************************************************/
+type TInitStage struct {
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	DurationMs  int64      `json:"durationMs"`
+}
+
+/**************************************************************************************************
+** TChainInitProgress is the progress of the initialization of a chain. Completion is the share of
+** its stages completed, from 0 to 1.
+**************************************************************************************************/
+type TChainInitProgress struct {
+	ChainID     uint64       `json:"chainID"`
+	Status      string       `json:"status"`
+	Completion  float64      `json:"completion"`
+	StartedAt   time.Time    `json:"startedAt"`
+	CompletedAt *time.Time   `json:"completedAt,omitempty"`
+	Stages      []TInitStage `json:"stages"`
+}
+
+/**************************************************************************************************
+** OnChainInitialized is called once per chain, when all its init stages completed.
+**************************************************************************************************/
+var OnChainInitialized func(chainID uint64)
+
+var initProgress = make(map[uint64]*TChainInitProgress)
+var initProgressMtx sync.RWMutex
+
+func registerInitProgress(chainID uint64) {
+	initProgressMtx.Lock()
+	defer initProgressMtx.Unlock()
+	if _, ok := initProgress[chainID]; ok {
+		return
+	}
+	progress := &TChainInitProgress{
+		ChainID:   chainID,
+		Status:    INIT_STATUS_PENDING,
+		StartedAt: time.Now(),
+		Stages:    []TInitStage{},
+	}
+	for _, stage := range INIT_STAGES {
+		progress.Stages = append(progress.Stages, TInitStage{Name: stage, Status: INIT_STATUS_PENDING})
+	}
+	initProgress[chainID] = progress
+}
+
+/**************************************************************************************************
+** trackInitStage runs a stage of a refresh and records it as an init stage of the chain. Only the
+** first run of a stage is recorded: the next refreshes are not part of the initialization.
+**************************************************************************************************/
+func trackInitStage(chainID uint64, stage string, run func()) {
+	if !updateInitStage(chainID, stage, INIT_STATUS_RUNNING) {
+		run()
+		return
+	}
+	run()
+	updateInitStage(chainID, stage, INIT_STATUS_DONE)
+}
+
+/**************************************************************************************************
+** updateInitStage moves a stage of a chain to the given status. It returns false when the stage is
+** unknown or already done. Once the last stage is done, the chain is initialized and
+** OnChainInitialized is called.
+**************************************************************************************************/
+func updateInitStage(chainID uint64, stage string, status string) bool {
+	initProgressMtx.Lock()
+	progress, ok := initProgress[chainID]
+	if !ok {
+		initProgressMtx.Unlock()
+		return false
+	}
+
+	now := time.Now()
+	completed := 0
+	updated := false
+	for i := range progress.Stages {
+		current := &progress.Stages[i]
+		if current.Name == stage && current.Status != INIT_STATUS_DONE {
+			current.Status = status
+			if status == INIT_STATUS_RUNNING {
+				current.StartedAt = &now
+			} else if status == INIT_STATUS_DONE && current.StartedAt != nil {
+				current.CompletedAt = &now
+				current.DurationMs = now.Sub(*current.StartedAt).Milliseconds()
+			}
+			updated = true
+		}
+		if current.Status == INIT_STATUS_DONE {
+			completed++
+		}
+	}
+	progress.Completion = float64(completed) / float64(len(progress.Stages))
+
+	justInitialized := false
+	if completed == len(progress.Stages) && progress.Status != INIT_STATUS_DONE {
+		progress.Status = INIT_STATUS_DONE
+		progress.CompletedAt = &now
+		justInitialized = true
+	} else if updated && progress.Status == INIT_STATUS_PENDING {
+		progress.Status = INIT_STATUS_RUNNING
+	}
+	initProgressMtx.Unlock()
+
+	if justInitialized && OnChainInitialized != nil {
+		OnChainInitialized(chainID)
+	}
+	return updated
+}
+
+/**************************************************************************************************
+** GetInitProgress returns the initialization progress of the chains being indexed, sorted by
+** chain ID.
+**************************************************************************************************/
+func GetInitProgress() []TChainInitProgress {
+	initProgressMtx.RLock()
+	defer initProgressMtx.RUnlock()
+	progresses := []TChainInitProgress{}
+	for _, progress := range initProgress {
+		copied := *progress
+		copied.Stages = append([]TInitStage{}, progress.Stages...)
+		progresses = append(progresses, copied)
+	}
+	sort.Slice(progresses, func(i, j int) bool {
+		return progresses[i].ChainID < progresses[j].ChainID
+	})
+	return progresses
+}
@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The sequencer of the L2 chains with a SequencerUptimeFeed is checked every
+** SEQUENCER_CHECK_INTERVAL. While it is down, the refreshes of the chain are skipped instead of
+** failing against a stalled chain, and its responses are labeled as potentially stale.
+**************************************************************************************************/
+const SEQUENCER_CHECK_INTERVAL = time.Minute
+
+/**************************************************************************************************
+** OnSequencerDown is called when the sequencer of a chain goes down, and OnSequencerUp when it is
+** back up.
+**************************************************************************************************/
+var OnSequencerDown func(chainID uint64, status storage.TSequencerStatus)
+var OnSequencerUp func(chainID uint64, status storage.TSequencerStatus)
+
+/**************************************************************************************************
+** hasSequencerFeed returns true if the sequencer of a chain can be checked.
+**************************************************************************************************/
+func hasSequencerFeed(chainID uint64) bool {
+	chain, ok := env.GetChain(chainID)
+	return ok && (chain.SequencerUptimeFeed != common.Address{})
+}
+
+/**************************************************************************************************
+** checkSequencerStatus reads the sequencer uptime feed of a chain, stores the status, and calls
+** the hooks when the sequencer goes down or is back up. The previous status is kept when the
+** feed can't be read.
+**************************************************************************************************/
+func checkSequencerStatus(chainID uint64) {
+	chain, _ := env.GetChain(chainID)
+	calls := []ethereum.Call{multicalls.GetSequencerStatus(`sequencer`, chain.SequencerUptimeFeed)}
+	response := multicalls.Perform(chainID, calls, nil)
+	values := response[`sequencer`+`latestRoundData`]
+	if len(values) < 3 {
+		logs.Warning(fmt.Sprintf("🚦 [SEQUENCER] failed to read the uptime feed chain=%d", chainID))
+		return
+	}
+	answer, okAnswer := values[1].(*big.Int)
+	startedAt, okStartedAt := values[2].(*big.Int)
+	if !okAnswer || !okStartedAt {
+		return
+	}
+
+	status := storage.TSequencerStatus{
+		IsDown:    answer.Sign() != 0,
+		Since:     startedAt.Uint64(),
+		CheckedAt: uint64(time.Now().Unix()),
+	}
+	wasDown := storage.IsSequencerDown(chainID)
+	storage.StoreSequencerStatus(chainID, status)
+
+	if status.IsDown && !wasDown {
+		logs.Warning(fmt.Sprintf("🚦 [SEQUENCER] down chain=%d since=%d", chainID, status.Since))
+		if OnSequencerDown != nil {
+			OnSequencerDown(chainID, status)
+		}
+	} else if !status.IsDown && wasDown {
+		logs.Info(fmt.Sprintf("🚦 [SEQUENCER] back up chain=%d since=%d", chainID, status.Since))
+		if OnSequencerUp != nil {
+			OnSequencerUp(chainID, status)
+		}
+	}
+}
+
+/**************************************************************************************************
+** skipWhileSequencerDown returns true, logging it, when a job of a chain must be skipped because
+** its sequencer is down.
+**************************************************************************************************/
+func skipWhileSequencerDown(chainID uint64, name string) bool {
+	if !storage.IsSequencerDown(chainID) {
+		return false
+	}
+	logs.Info(fmt.Sprintf("🚦 [SEQUENCER] job=%s skipped chain=%d: sequencer down", name, chainID))
+	return true
+}
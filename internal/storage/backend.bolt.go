@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/yearn/ydaemon/common/env"
+	bolt "go.etcd.io/bbolt"
+)
+
+/**************************************************************************************************
+** tBoltBackend persists the documents in an embedded BoltDB file: one bucket per element, keyed
+** by chain ID. A single file is easier to back up and move than the JSON files, without running
+** a database server.
+**************************************************************************************************/
+type tBoltBackend struct {
+	db *bolt.DB
+}
+
+/**************************************************************************************************
+** newBoltBackend opens (or creates) the BoltDB file at the given path, BASE_DATA_PATH/ydaemon.db
+** by default.
+**************************************************************************************************/
+func newBoltBackend(path string) (*tBoltBackend, error) {
+	if path == `` {
+		path = env.BASE_DATA_PATH + `/ydaemon.db`
+	}
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	return &tBoltBackend{db: db}, nil
+}
+
+func (b *tBoltBackend) Name() string {
+	return STORAGE_BACKEND_BOLT
+}
+
+func (b *tBoltBackend) Open(element string, chainID uint64) (io.ReadCloser, error) {
+	var data []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(element))
+		if bucket == nil {
+			return os.ErrNotExist
+		}
+		value := bucket.Get([]byte(strconv.FormatUint(chainID, 10)))
+		if value == nil {
+			return os.ErrNotExist
+		}
+		data = append([]byte{}, value...) // The value is only valid during the transaction
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (b *tBoltBackend) Write(element string, chainID uint64, data []byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(element))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(strconv.FormatUint(chainID, 10)), data)
+	})
+}
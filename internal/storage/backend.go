@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+/**************************************************************************************************
+** The storage layer serves everything from the in-memory maps of each element. The backend is
+** where the elements are persisted between restarts: each element (`vaults`, `apy`, `metrics`...)
+** is saved as one JSON document per chain and reloaded on start.
+** The backend is selected with the STORAGE_BACKEND environment variable, see env.STORAGE_BACKEND.
+**************************************************************************************************/
+type TStorageBackend interface {
+	// Name returns the name of the backend, as set in STORAGE_BACKEND
+	Name() string
+	// Open returns the document of an element for a chain, or an error if there is none
+	Open(element string, chainID uint64) (io.ReadCloser, error)
+	// Write replaces the document of an element for a chain
+	Write(element string, chainID uint64, data []byte) error
+}
+
+const (
+	STORAGE_BACKEND_FILES    = `files`
+	STORAGE_BACKEND_MEMORY   = `memory`
+	STORAGE_BACKEND_BOLT     = `bolt`
+	STORAGE_BACKEND_POSTGRES = `postgres`
+)
+
+var _storageBackend TStorageBackend
+var _storageBackendOnce sync.Once
+
+/**************************************************************************************************
+** getStorageBackend returns the backend selected by STORAGE_BACKEND, created on first use. If the
+** selected backend cannot be opened, the JSON files are used instead for the daemon to still
+** persist its data.
+**************************************************************************************************/
+func getStorageBackend() TStorageBackend {
+	_storageBackendOnce.Do(func() {
+		var err error
+		switch env.STORAGE_BACKEND {
+		case STORAGE_BACKEND_MEMORY:
+			_storageBackend = newMemoryBackend()
+		case STORAGE_BACKEND_BOLT:
+			_storageBackend, err = newBoltBackend(env.STORAGE_BOLT_PATH)
+		case STORAGE_BACKEND_POSTGRES:
+			_storageBackend, err = newPostgresBackend(env.STORAGE_POSTGRES_DSN)
+		case STORAGE_BACKEND_FILES:
+			_storageBackend = newFilesBackend(env.BASE_DATA_PATH + `/meta`)
+		default:
+			logs.Warning(`Unknown storage backend ` + env.STORAGE_BACKEND + `, using ` + STORAGE_BACKEND_FILES)
+			_storageBackend = newFilesBackend(env.BASE_DATA_PATH + `/meta`)
+		}
+		if err != nil {
+			logs.Error(`Failed to open the ` + env.STORAGE_BACKEND + ` storage backend, using ` + STORAGE_BACKEND_FILES + `: ` + err.Error())
+			_storageBackend = newFilesBackend(env.BASE_DATA_PATH + `/meta`)
+		}
+		logs.Info(`Using the ` + _storageBackend.Name() + ` storage backend`)
+	})
+	return _storageBackend
+}
+
+/**************************************************************************************************
+** The elements are read and written through readElement and writeElement, which decode and encode
+** their JSON document with the backend, each element keeping its own document type. The writes of
+** an element for a chain are serialized with the mutex returned by getElementMutex.
+**************************************************************************************************/
+var _elementMutexes = make(map[string]*sync.RWMutex)
+var _elementMutexesLock sync.Mutex // Protects access to _elementMutexes map
+
+/**************************************************************************************************
+** getElementMutex safely gets or creates the mutex of an element for a specific chainID
+**************************************************************************************************/
+func getElementMutex(element string, chainID uint64) *sync.RWMutex {
+	_elementMutexesLock.Lock()
+	defer _elementMutexesLock.Unlock()
+
+	key := element + `:` + strconv.FormatUint(chainID, 10)
+	if mutex, exists := _elementMutexes[key]; exists {
+		return mutex
+	}
+	_elementMutexes[key] = &sync.RWMutex{}
+	return _elementMutexes[key]
+}
+
+/**************************************************************************************************
+** readElement decodes the document of an element for a chain into data. It returns false if the
+** document was never written or cannot be decoded, the latter being logged.
+**************************************************************************************************/
+func readElement(element string, chainID uint64, data interface{}) bool {
+	file, err := getStorageBackend().Open(element, chainID)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(data); err != nil {
+		logs.Error(`Failed to decode the ` + element + ` of chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+		return false
+	}
+	return true
+}
+
+/**************************************************************************************************
+** writeElement encodes data as the document of an element for a chain and writes it, the errors
+** being logged.
+**************************************************************************************************/
+func writeElement(element string, chainID uint64, data interface{}) {
+	file, err := json.Marshal(data)
+	if err != nil {
+		logs.Error(`Failed to encode the ` + element + ` of chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+		return
+	}
+	if err := getStorageBackend().Write(element, chainID, file); err != nil {
+		logs.Error(`Failed to write the ` + element + ` of chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+	}
+}
+
+/**************************************************************************************************
+** tFilesBackend persists each element of each chain in `<root>/<element>/<chainID>.json`. This is
+** the default backend, without any dependency.
+**************************************************************************************************/
+type tFilesBackend struct {
+	root string
+}
+
+func newFilesBackend(root string) *tFilesBackend {
+	return &tFilesBackend{root: root}
+}
+
+func (b *tFilesBackend) Name() string {
+	return STORAGE_BACKEND_FILES
+}
+
+func (b *tFilesBackend) Open(element string, chainID uint64) (io.ReadCloser, error) {
+	return os.Open(b.root + `/` + element + `/` + strconv.FormatUint(chainID, 10) + `.json`)
+}
+
+func (b *tFilesBackend) Write(element string, chainID uint64, data []byte) error {
+	if _, err := os.Stat(b.root + `/` + element); os.IsNotExist(err) {
+		os.MkdirAll(b.root+`/`+element, 0755)
+	}
+	return os.WriteFile(b.root+`/`+element+`/`+strconv.FormatUint(chainID, 10)+`.json`, data, 0644)
+}
+
+/**************************************************************************************************
+** tMemoryBackend keeps the documents in memory only: nothing survives a restart. This suits the
+** ephemeral deployments and the tests.
+**************************************************************************************************/
+type tMemoryBackend struct {
+	documents sync.Map // key: element + `:` + chainID -> []byte
+}
+
+func newMemoryBackend() *tMemoryBackend {
+	return &tMemoryBackend{}
+}
+
+func (b *tMemoryBackend) Name() string {
+	return STORAGE_BACKEND_MEMORY
+}
+
+func (b *tMemoryBackend) Open(element string, chainID uint64) (io.ReadCloser, error) {
+	data, ok := b.documents.Load(element + `:` + strconv.FormatUint(chainID, 10))
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data.([]byte))), nil
+}
+
+func (b *tMemoryBackend) Write(element string, chainID uint64, data []byte) error {
+	b.documents.Store(element+`:`+strconv.FormatUint(chainID, 10), append([]byte{}, data...))
+	return nil
+}
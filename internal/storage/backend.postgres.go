@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+/**************************************************************************************************
+** TStorageDocument is a row of the `ydaemon_storage` table of the Postgres backend. The document
+** is stored as JSONB, so the history kept by the daemon can be queried with SQL, e.g. the daily
+** APY of a vault:
+**
+**   SELECT point->>'timestamp', point->>'apy'
+**   FROM ydaemon_storage, jsonb_array_elements(data->'history'->'0x...') AS point
+**   WHERE element = 'apyHistory' AND chain_id = 1;
+**************************************************************************************************/
+type TStorageDocument struct {
+	Element   string    `gorm:"primaryKey"`
+	ChainID   uint64    `gorm:"primaryKey"`
+	Data      []byte    `gorm:"type:jsonb"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (TStorageDocument) TableName() string {
+	return `ydaemon_storage`
+}
+
+/**************************************************************************************************
+** tPostgresBackend persists the documents in a Postgres database, distinct from the Kong one.
+**************************************************************************************************/
+type tPostgresBackend struct {
+	db *gorm.DB
+}
+
+/**************************************************************************************************
+** newPostgresBackend connects to the database and creates the `ydaemon_storage` table if needed.
+**************************************************************************************************/
+func newPostgresBackend(dsn string) (*tPostgresBackend, error) {
+	if dsn == `` {
+		return nil, errors.New(`STORAGE_POSTGRES_DSN is not set`)
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.New(&gormLogger{}, logger.Config{
+			SlowThreshold:             time.Second,
+			LogLevel:                  logger.Warn,
+			IgnoreRecordNotFoundError: true,
+		}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(&TStorageDocument{}); err != nil {
+		return nil, err
+	}
+	return &tPostgresBackend{db: db}, nil
+}
+
+func (b *tPostgresBackend) Name() string {
+	return STORAGE_BACKEND_POSTGRES
+}
+
+func (b *tPostgresBackend) Open(element string, chainID uint64) (io.ReadCloser, error) {
+	var document TStorageDocument
+	err := b.db.Where(`element = ? AND chain_id = ?`, element, chainID).Take(&document).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, os.ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(document.Data)), nil
+}
+
+func (b *tPostgresBackend) Write(element string, chainID uint64, data []byte) error {
+	return b.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&TStorageDocument{
+		Element: element,
+		ChainID: chainID,
+		Data:    data,
+	}).Error
+}
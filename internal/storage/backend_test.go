@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"io"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** TestStorageBackends tests that the dependency-free backends return the last document written for
+** an element and a chain, and an error for a document never written.
+**************************************************************************************************/
+func TestStorageBackends(t *testing.T) {
+	boltBackend, err := newBoltBackend(t.TempDir() + `/ydaemon.db`)
+	assert.NoError(t, err)
+
+	backends := []TStorageBackend{
+		newFilesBackend(t.TempDir()),
+		newMemoryBackend(),
+		boltBackend,
+	}
+	for _, backend := range backends {
+		t.Run(backend.Name(), func(t *testing.T) {
+			_, err := backend.Open(`apy`, 1)
+			assert.Error(t, err)
+
+			assert.NoError(t, backend.Write(`apy`, 1, []byte(`{"version":1}`)))
+			assert.NoError(t, backend.Write(`apy`, 1, []byte(`{"version":2}`)))
+			assert.NoError(t, backend.Write(`apy`, 10, []byte(`{"version":3}`)))
+
+			file, err := backend.Open(`apy`, 1)
+			assert.NoError(t, err)
+			data, err := io.ReadAll(file)
+			file.Close()
+			assert.NoError(t, err)
+			assert.Equal(t, `{"version":2}`, string(data))
+
+			_, err = backend.Open(`fees`, 1)
+			assert.Error(t, err)
+		})
+	}
+}
+
+/**************************************************************************************************
+** TestElementRoundTrip tests that an element written with writeElement is read back as written,
+** and that reading an element never written reports it.
+**************************************************************************************************/
+func TestElementRoundTrip(t *testing.T) {
+	_storageBackendOnce.Do(func() {
+		_storageBackend = newMemoryBackend()
+	})
+	written := TJsonFeesStorage{Fees: map[common.Address][]models.TFeeSnapshot{
+		common.HexToAddress(`0x1`): {{Timestamp: 1, PerformanceFee: 1000, ManagementFee: 0}},
+	}}
+	writeElement(`roundTrip`, 1337, written)
+
+	read := TJsonFeesStorage{}
+	assert.True(t, readElement(`roundTrip`, 1337, &read))
+	assert.Equal(t, written.Fees, read.Fees)
+	assert.False(t, readElement(`roundTrip`, 1338, &TJsonFeesStorage{}))
+}
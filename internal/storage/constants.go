@@ -44,6 +44,10 @@ var CRV_TOKEN_ADDRESS = map[uint64]common.Address{
 	42161: common.HexToAddress(`0x11cDb42B0EB46D95f990BeDD4695A6e3fA034978`),
 }
 
+var CURVE_GAUGE_CONTROLLER_ADDRESS = map[uint64]common.Address{
+	1: common.HexToAddress(`0x2F50D538606Fa9EDD2B11E2446BEb18C9D5846bB`),
+}
+
 var CVX_TOKEN_ADDRESS = map[uint64]common.Address{
 	1:     common.HexToAddress(`0x4e3FBD56CD56c3e72c1403e103b45Db9da5B9D2B`),
 	10:    {},
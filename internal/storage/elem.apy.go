@@ -2,13 +2,10 @@ package storage
 
 import (
 	"encoding/json"
-	"os"
-	"strconv"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/yearn/ydaemon/common/env"
 	"github.com/yearn/ydaemon/common/logs"
 	"github.com/yearn/ydaemon/internal/models"
 )
@@ -42,10 +39,9 @@ func getAPYMutex(chainID uint64) *sync.RWMutex {
 **************************************************************************************************/
 func loadAPYFromJson(chainID uint64) TJsonAPYStorage {
 	var apyData TJsonAPYStorage
-	chainIDStr := strconv.FormatUint(chainID, 10)
 
 	// Load the JSON file
-	file, err := os.Open(env.BASE_DATA_PATH + "/meta/apy/" + chainIDStr + ".json")
+	file, err := getStorageBackend().Open(`apy`, chainID)
 	if err != nil {
 		return TJsonAPYStorage{}
 	}
@@ -70,9 +66,13 @@ func StoreAPYToJson(chainID uint64, apyData map[common.Address]models.TVaultAPY)
 	mutex.Lock()
 	defer mutex.Unlock()
 
-	chainIDStr := strconv.FormatUint(chainID, 10)
 	previousAPY := loadAPYFromJson(chainID)
 	version := detectVersionUpdate(chainID, previousAPY.Version, previousAPY.APY, apyData)
+	for address, apy := range apyData {
+		if previous, ok := previousAPY.APY[address]; ok {
+			auditAPYMutations(chainID, address, previous, apy)
+		}
+	}
 
 	data := TJsonAPYStorage{
 		TJsonMetadata: TJsonMetadata{
@@ -92,10 +92,7 @@ func StoreAPYToJson(chainID uint64, apyData map[common.Address]models.TVaultAPY)
 		logs.Error("Failed to marshal APY JSON file: " + err.Error())
 		return
 	}
-	if _, err := os.Stat(env.BASE_DATA_PATH + "/meta/apy"); os.IsNotExist(err) {
-		os.MkdirAll(env.BASE_DATA_PATH+"/meta/apy", 0755)
-	}
-	err = os.WriteFile(env.BASE_DATA_PATH+"/meta/apy/"+chainIDStr+".json", file, 0644)
+	err = getStorageBackend().Write(`apy`, chainID, file)
 	if err != nil {
 		logs.Error("Failed to write APY JSON file: " + err.Error())
 	}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** The APY history keeps one point per UTC day for each vault, the average of the APYs recorded
+** that day, for APY_HISTORY_RETENTION. This is enough to compute the statistics over the last
+** year, while the metrics history, with every snapshot, only covers the last days.
+**************************************************************************************************/
+const APY_HISTORY_RETENTION = 366 * 24 * time.Hour
+
+var _apyHistorySyncMap = make(map[uint64]*sync.Map)
+
+type TJsonAPYHistoryStorage struct {
+	TJsonMetadata
+	History map[common.Address][]models.TVaultDailyAPY `json:"history"`
+}
+
+/** 🔵 - Yearn *************************************************************************************
+** The function `StoreAPYHistoryToJson` is responsible for storing the APY histories to a JSON
+** file.
+**************************************************************************************************/
+func StoreAPYHistoryToJson(chainID uint64) {
+	mutex := getElementMutex(`apyHistory`, chainID)
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	data := TJsonAPYHistoryStorage{
+		TJsonMetadata: TJsonMetadata{
+			LastUpdate: time.Now(),
+		},
+		History: make(map[common.Address][]models.TVaultDailyAPY),
+	}
+	safeSyncMap(_apyHistorySyncMap, chainID).Range(func(key, value interface{}) bool {
+		data.History[key.(common.Address)] = value.([]models.TVaultDailyAPY)
+		return true
+	})
+
+	writeElement(`apyHistory`, chainID, data)
+}
+
+/**************************************************************************************************
+** LoadAPYHistory will retrieve the APY histories from the JSON file and store them in the
+** _apyHistorySyncMap for fast access during that same execution.
+**************************************************************************************************/
+func LoadAPYHistory(chainID uint64, wg *sync.WaitGroup) {
+	if wg != nil {
+		defer wg.Done()
+	}
+	mutex := getElementMutex(`apyHistory`, chainID)
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	file := TJsonAPYHistoryStorage{}
+	readElement(`apyHistory`, chainID, &file)
+	for address, history := range file.History {
+		safeSyncMap(_apyHistorySyncMap, chainID).Store(address, history)
+	}
+}
+
+/**************************************************************************************************
+** StoreVaultDailyAPY adds an APY to the history of a vault: it is averaged with the APYs already
+** recorded for the same UTC day, or starts a new day. The days older than APY_HISTORY_RETENTION
+** are dropped.
+**************************************************************************************************/
+func StoreVaultDailyAPY(chainID uint64, vaultAddress common.Address, timestamp uint64, apy float64) {
+	day := timestamp - timestamp%86400
+	oldestTimestamp := uint64(time.Now().Add(-APY_HISTORY_RETENTION).Unix())
+	history := []models.TVaultDailyAPY{}
+	for _, previous := range ListVaultDailyAPY(chainID, vaultAddress) {
+		if previous.Timestamp >= oldestTimestamp {
+			history = append(history, previous)
+		}
+	}
+
+	if last := len(history) - 1; last >= 0 && history[last].Timestamp == day {
+		samples := history[last].Samples
+		history[last].APY = (history[last].APY*float64(samples) + apy) / float64(samples+1)
+		history[last].Samples = samples + 1
+	} else {
+		history = append(history, models.TVaultDailyAPY{Timestamp: day, APY: apy, Samples: 1})
+	}
+	safeSyncMap(_apyHistorySyncMap, chainID).Store(vaultAddress, history)
+}
+
+/**************************************************************************************************
+** ListVaultDailyAPY returns the daily APY history of a vault, sorted from the oldest to the newest
+** day.
+**************************************************************************************************/
+func ListVaultDailyAPY(chainID uint64, vaultAddress common.Address) []models.TVaultDailyAPY {
+	history, ok := safeSyncMap(_apyHistorySyncMap, chainID).Load(vaultAddress)
+	if !ok {
+		return []models.TVaultDailyAPY{}
+	}
+	return append([]models.TVaultDailyAPY{}, history.([]models.TVaultDailyAPY)...)
+}
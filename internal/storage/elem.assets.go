@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+/**************************************************************************************************
+** TPinnedAsset holds the IPFS pins of the assets of a token: its icon, with the URL it was fetched
+** from, and its metadata document (name, symbol, description and icon). The hashes are the
+** sha256 of the pinned content, for an unchanged content not to be pinned again.
+**************************************************************************************************/
+type TPinnedAsset struct {
+	IconSource   string `json:"iconSource"`
+	IconHash     string `json:"iconHash"`
+	IconCID      string `json:"iconCID"`
+	MetadataHash string `json:"metadataHash"`
+	MetadataCID  string `json:"metadataCID"`
+	PinnedAt     int64  `json:"pinnedAt"`
+}
+
+var _pinnedAssetsSyncMap = sync.Map{}
+var _pinnedAssetsLock sync.Mutex
+
+/**************************************************************************************************
+** LoadPinnedAssets loads the pins of the assets of the tokens of a chain, persisted as the `assets`
+** element of the chain, in memory.
+**************************************************************************************************/
+func LoadPinnedAssets(chainID uint64) {
+	assets := make(map[common.Address]TPinnedAsset)
+	file, err := getStorageBackend().Open(`assets`, chainID)
+	if err == nil {
+		defer file.Close()
+		if err := json.NewDecoder(file).Decode(&assets); err != nil {
+			logs.Error(`Failed to decode the pinned assets of chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+			assets = make(map[common.Address]TPinnedAsset)
+		}
+	}
+	_pinnedAssetsSyncMap.Store(chainID, assets)
+}
+
+/**************************************************************************************************
+** StorePinnedAsset stores the pins of the assets of a token. The pins of the chain are persisted
+** by StorePinnedAssetsToJson, once a refresh is complete.
+**************************************************************************************************/
+func StorePinnedAsset(chainID uint64, tokenAddress common.Address, asset TPinnedAsset) {
+	_pinnedAssetsLock.Lock()
+	defer _pinnedAssetsLock.Unlock()
+
+	assets := make(map[common.Address]TPinnedAsset)
+	if current, ok := _pinnedAssetsSyncMap.Load(chainID); ok {
+		for address, pinned := range current.(map[common.Address]TPinnedAsset) {
+			assets[address] = pinned
+		}
+	}
+	assets[tokenAddress] = asset
+	_pinnedAssetsSyncMap.Store(chainID, assets)
+}
+
+/**************************************************************************************************
+** StorePinnedAssetsToJson persists the pins of the assets of the tokens of a chain.
+**************************************************************************************************/
+func StorePinnedAssetsToJson(chainID uint64) {
+	_pinnedAssetsLock.Lock()
+	defer _pinnedAssetsLock.Unlock()
+
+	assets := make(map[common.Address]TPinnedAsset)
+	if current, ok := _pinnedAssetsSyncMap.Load(chainID); ok {
+		assets = current.(map[common.Address]TPinnedAsset)
+	}
+	file, _ := json.Marshal(assets)
+	if err := getStorageBackend().Write(`assets`, chainID, file); err != nil {
+		logs.Error(`Failed to write the pinned assets of chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+	}
+}
+
+/**************************************************************************************************
+** GetPinnedAsset returns the pins of the assets of a token, false if none of them is pinned.
+**************************************************************************************************/
+func GetPinnedAsset(chainID uint64, tokenAddress common.Address) (TPinnedAsset, bool) {
+	current, ok := _pinnedAssetsSyncMap.Load(chainID)
+	if !ok {
+		return TPinnedAsset{}, false
+	}
+	asset, ok := current.(map[common.Address]TPinnedAsset)[tokenAddress]
+	return asset, ok
+}
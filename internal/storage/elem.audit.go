@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** The mutations of the key fields of the vaults (APY, TVL, fees and flags) are kept in a ring
+** buffer of AUDIT_TRAIL_SIZE entries per vault, for the post-mortems when a wrong number was
+** briefly published. The first value of a field, when the vault is loaded or discovered, is not a
+** mutation and isn't recorded. The TVL moving with every deposit, its changes are only recorded
+** past AUDIT_TVL_MIN_CHANGE, for them not to push the other mutations out of the trail. The trails
+** are persisted as the `audit` element of the chain, for them to survive a restart.
+**************************************************************************************************/
+const AUDIT_TRAIL_SIZE = 200
+const AUDIT_TVL_MIN_CHANGE = 0.1
+
+const (
+	AUDIT_SOURCE_FETCHER = `fetcher`
+	AUDIT_SOURCE_KONG    = `kong`
+	AUDIT_SOURCE_APR     = `apr`
+)
+
+type TAuditEntry struct {
+	Field     string `json:"field"`
+	OldValue  string `json:"oldValue"`
+	NewValue  string `json:"newValue"`
+	Source    string `json:"source"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type tAuditTrail struct {
+	entries []TAuditEntry
+	next    int
+}
+
+/**************************************************************************************************
+** TJsonAuditStorage holds the audit trails of the vaults of a chain, each from the oldest to the
+** newest entry.
+**************************************************************************************************/
+type TJsonAuditStorage struct {
+	Trails map[common.Address][]TAuditEntry `json:"trails"`
+}
+
+var (
+	_auditTrails    = make(map[uint64]map[common.Address]*tAuditTrail)
+	_auditTrailsMtx sync.RWMutex
+)
+
+/**************************************************************************************************
+** recordMutation appends a mutation to the audit trail of a vault, overwriting the oldest entry
+** once the trail is full. Nothing is recorded when the value didn't change.
+**************************************************************************************************/
+func recordMutation(chainID uint64, vaultAddress common.Address, source string, field string, oldValue string, newValue string) {
+	if oldValue == newValue {
+		return
+	}
+	entry := TAuditEntry{
+		Field:     field,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+		Source:    source,
+		Timestamp: time.Now().Unix(),
+	}
+
+	_auditTrailsMtx.Lock()
+	defer _auditTrailsMtx.Unlock()
+	loadAuditTrails(chainID)
+	trail, ok := _auditTrails[chainID][vaultAddress]
+	if !ok {
+		trail = &tAuditTrail{}
+		_auditTrails[chainID][vaultAddress] = trail
+	}
+	if len(trail.entries) < AUDIT_TRAIL_SIZE {
+		trail.entries = append(trail.entries, entry)
+		return
+	}
+	trail.entries[trail.next] = entry
+	trail.next = (trail.next + 1) % AUDIT_TRAIL_SIZE
+}
+
+/**************************************************************************************************
+** ListAuditTrail returns the recorded mutations of a vault, from the oldest to the newest.
+**************************************************************************************************/
+func ListAuditTrail(chainID uint64, vaultAddress common.Address) []TAuditEntry {
+	_auditTrailsMtx.Lock()
+	defer _auditTrailsMtx.Unlock()
+	loadAuditTrails(chainID)
+
+	trail, ok := _auditTrails[chainID][vaultAddress]
+	if !ok {
+		return []TAuditEntry{}
+	}
+	return trail.list()
+}
+
+func (trail *tAuditTrail) list() []TAuditEntry {
+	entries := append([]TAuditEntry{}, trail.entries[trail.next:]...)
+	return append(entries, trail.entries[:trail.next]...)
+}
+
+/**************************************************************************************************
+** loadAuditTrails loads the trails stored for a chain the first time the chain is audited. The
+** caller must hold _auditTrailsMtx.
+**************************************************************************************************/
+func loadAuditTrails(chainID uint64) {
+	if _, ok := _auditTrails[chainID]; ok {
+		return
+	}
+	_auditTrails[chainID] = make(map[common.Address]*tAuditTrail)
+
+	stored := TJsonAuditStorage{}
+	if !readElement(`audit`, chainID, &stored) {
+		return
+	}
+	for vaultAddress, entries := range stored.Trails {
+		if len(entries) > AUDIT_TRAIL_SIZE {
+			entries = entries[len(entries)-AUDIT_TRAIL_SIZE:]
+		}
+		_auditTrails[chainID][vaultAddress] = &tAuditTrail{entries: entries}
+	}
+}
+
+/**************************************************************************************************
+** StoreAuditTrails persists the audit trails of the vaults of a chain.
+**************************************************************************************************/
+func StoreAuditTrails(chainID uint64) {
+	_auditTrailsMtx.Lock()
+	defer _auditTrailsMtx.Unlock()
+	loadAuditTrails(chainID)
+
+	stored := TJsonAuditStorage{Trails: make(map[common.Address][]TAuditEntry)}
+	for vaultAddress, trail := range _auditTrails[chainID] {
+		stored.Trails[vaultAddress] = trail.list()
+	}
+	writeElement(`audit`, chainID, stored)
+}
+
+/**************************************************************************************************
+** auditVaultMutations records the changes of the fees and of the flags of a vault refreshed by
+** the fetcher.
+**************************************************************************************************/
+func auditVaultMutations(chainID uint64, previous models.TVault, vault models.TVault) {
+	record := func(field string, oldValue string, newValue string) {
+		recordMutation(chainID, vault.Address, AUDIT_SOURCE_FETCHER, field, oldValue, newValue)
+	}
+	record(`performanceFee`, strconv.FormatUint(previous.PerformanceFee, 10), strconv.FormatUint(vault.PerformanceFee, 10))
+	record(`managementFee`, strconv.FormatUint(previous.ManagementFee, 10), strconv.FormatUint(vault.ManagementFee, 10))
+	record(`emergencyShutdown`, strconv.FormatBool(previous.EmergencyShutdown), strconv.FormatBool(vault.EmergencyShutdown))
+	record(`endorsed`, strconv.FormatBool(previous.Endorsed), strconv.FormatBool(vault.Endorsed))
+	record(`metadata.isRetired`, strconv.FormatBool(previous.Metadata.IsRetired), strconv.FormatBool(vault.Metadata.IsRetired))
+	record(`metadata.isHidden`, strconv.FormatBool(previous.Metadata.IsHidden), strconv.FormatBool(vault.Metadata.IsHidden))
+	record(`metadata.stageOverride`, string(previous.Metadata.StageOverride), string(vault.Metadata.StageOverride))
+}
+
+/**************************************************************************************************
+** auditKongMutations records the changes of the TVL and of the fees of a vault indexed from Kong,
+** the TVL only when it moved by AUDIT_TVL_MIN_CHANGE at least.
+**************************************************************************************************/
+func auditKongMutations(chainID uint64, vaultAddress common.Address, previous models.TKongVaultSchema, kongData models.TKongVaultSchema) {
+	record := func(field string, oldValue string, newValue string) {
+		recordMutation(chainID, vaultAddress, AUDIT_SOURCE_KONG, field, oldValue, newValue)
+	}
+	if isSignificantTVLChange(previous.TVL, kongData.TVL) {
+		record(`tvl`, strconv.FormatFloat(previous.TVL, 'f', -1, 64), strconv.FormatFloat(kongData.TVL, 'f', -1, 64))
+	}
+	record(`kong.performanceFee`, strconv.FormatUint(previous.PerformanceFee, 10), strconv.FormatUint(kongData.PerformanceFee, 10))
+	record(`kong.managementFee`, strconv.FormatUint(previous.ManagementFee, 10), strconv.FormatUint(kongData.ManagementFee, 10))
+}
+
+/**************************************************************************************************
+** auditAPYMutations records the changes of the published APYs of a vault computed by the APR
+** process.
+**************************************************************************************************/
+func auditAPYMutations(chainID uint64, vaultAddress common.Address, previous models.TVaultAPY, apy models.TVaultAPY) {
+	record := func(field string, oldValue *bigNumber.Float, newValue *bigNumber.Float) {
+		recordMutation(chainID, vaultAddress, AUDIT_SOURCE_APR, field, formatAuditFloat(oldValue), formatAuditFloat(newValue))
+	}
+	record(`apy.netAPY`, previous.NetAPY, apy.NetAPY)
+	record(`apy.forwardAPY.netAPY`, previous.ForwardAPY.NetAPY, apy.ForwardAPY.NetAPY)
+	record(`apy.extra.stakingRewardsAPY`, previous.Extra.StakingRewardsAPY, apy.Extra.StakingRewardsAPY)
+	recordMutation(chainID, vaultAddress, AUDIT_SOURCE_APR, `apy.forwardAPY.type`, previous.ForwardAPY.Type, apy.ForwardAPY.Type)
+}
+
+/**************************************************************************************************
+** isSignificantTVLChange returns true when the TVL moved by AUDIT_TVL_MIN_CHANGE at least, or
+** went from or to zero.
+**************************************************************************************************/
+func isSignificantTVLChange(previous float64, current float64) bool {
+	if previous == current {
+		return false
+	}
+	if previous == 0 || current == 0 {
+		return true
+	}
+	return math.Abs(current-previous)/math.Abs(previous) >= AUDIT_TVL_MIN_CHANGE
+}
+
+func formatAuditFloat(value *bigNumber.Float) string {
+	if value == nil {
+		return ``
+	}
+	asFloat, _ := value.Float64()
+	return strconv.FormatFloat(asFloat, 'f', -1, 64)
+}
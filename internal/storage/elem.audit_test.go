@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** TestAuditTrails tests that the small moves of the TVL are not recorded, that the trail keeps the
+** last AUDIT_TRAIL_SIZE mutations in order, and that it is reloaded from the backend after a
+** restart.
+**************************************************************************************************/
+func TestAuditTrails(t *testing.T) {
+	_storageBackendOnce.Do(func() {
+		_storageBackend = newMemoryBackend()
+	})
+	chainID := uint64(31337)
+	vault := common.HexToAddress(`0x182863131F9a4630fF9E27830d945B1413e347E8`)
+
+	auditKongMutations(chainID, vault, models.TKongVaultSchema{TVL: 1000}, models.TKongVaultSchema{TVL: 1050})
+	assert.Empty(t, ListAuditTrail(chainID, vault))
+	auditKongMutations(chainID, vault, models.TKongVaultSchema{TVL: 1000}, models.TKongVaultSchema{TVL: 500})
+	assert.Len(t, ListAuditTrail(chainID, vault), 1)
+
+	for i := 0; i < AUDIT_TRAIL_SIZE+5; i++ {
+		recordMutation(chainID, vault, AUDIT_SOURCE_APR, `apy.netAPY`, `0`, string(rune('a'+i%26)))
+	}
+	entries := ListAuditTrail(chainID, vault)
+	assert.Len(t, entries, AUDIT_TRAIL_SIZE)
+	assert.Equal(t, `apy.netAPY`, entries[0].Field)
+	last := entries[len(entries)-1]
+
+	StoreAuditTrails(chainID)
+	_auditTrailsMtx.Lock()
+	delete(_auditTrails, chainID)
+	_auditTrailsMtx.Unlock()
+	reloaded := ListAuditTrail(chainID, vault)
+	assert.Equal(t, entries, reloaded)
+
+	recordMutation(chainID, vault, AUDIT_SOURCE_FETCHER, `endorsed`, `false`, `true`)
+	reloaded = ListAuditTrail(chainID, vault)
+	assert.Len(t, reloaded, AUDIT_TRAIL_SIZE)
+	assert.Equal(t, last, reloaded[len(reloaded)-2])
+	assert.Equal(t, `endorsed`, reloaded[len(reloaded)-1].Field)
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/yearn/ydaemon/common/bigNumber"
+)
+
+/**************************************************************************************************
+** TDepositorEntry is the entry of a depositor in a vault, from the deposits indexed since its
+** first one, on an average cost basis: the shares deposited and not withdrawn yet, the assets paid
+** for them, and the forward net APY of the vault and the time at the deposits, weighted by their
+** shares, APYShares being the shares of the deposits made while the APY was known. A withdrawal
+** takes the same part of the shares and of the assets, the entry price per share and APY being
+** unchanged.
+**************************************************************************************************/
+type TDepositorEntry struct {
+	Shares        *bigNumber.Int `json:"shares"`
+	Assets        *bigNumber.Int `json:"assets"`
+	APY           float64        `json:"apy"`
+	APYShares     float64        `json:"apyShares"`
+	EntryTime     uint64         `json:"entryTime"`
+	LastDepositAt uint64         `json:"lastDepositAt"`
+}
+
+/**************************************************************************************************
+** TJsonEntriesStorage holds the entries of the depositors of the vaults of a chain, by depositor
+** address by vault address, and the block the vaults were scanned up to (included). It is
+** persisted as the `entries` element of the chain, for the scan to resume after a restart.
+**************************************************************************************************/
+type TJsonEntriesStorage struct {
+	ScannedUpTo uint64                                `json:"scannedUpTo"`
+	Entries     map[string]map[string]TDepositorEntry `json:"entries"`
+}
+
+var _entriesLock sync.Mutex
+
+/**************************************************************************************************
+** LoadDepositorEntries returns the entries last stored for a chain, or empty ones.
+**************************************************************************************************/
+func LoadDepositorEntries(chainID uint64) TJsonEntriesStorage {
+	_entriesLock.Lock()
+	defer _entriesLock.Unlock()
+
+	entries := TJsonEntriesStorage{}
+	if !readElement(`entries`, chainID, &entries) {
+		entries = TJsonEntriesStorage{}
+	}
+	if entries.Entries == nil {
+		entries.Entries = make(map[string]map[string]TDepositorEntry)
+	}
+	return entries
+}
+
+/**************************************************************************************************
+** StoreDepositorEntries persists the entries of the depositors of the vaults of a chain.
+**************************************************************************************************/
+func StoreDepositorEntries(chainID uint64, entries TJsonEntriesStorage) {
+	_entriesLock.Lock()
+	defer _entriesLock.Unlock()
+
+	writeElement(`entries`, chainID, entries)
+}
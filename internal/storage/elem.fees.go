@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+var _feesSyncMap = make(map[uint64]*sync.Map)
+
+type TJsonFeesStorage struct {
+	TJsonMetadata
+	Fees map[common.Address][]models.TFeeSnapshot `json:"fees"`
+}
+
+/** 🔵 - Yearn *************************************************************************************
+** The function `StoreFeesToJson` is responsible for storing the fee histories to a JSON file.
+**************************************************************************************************/
+func StoreFeesToJson(chainID uint64) {
+	mutex := getElementMutex(`fees`, chainID)
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	data := TJsonFeesStorage{
+		TJsonMetadata: TJsonMetadata{
+			LastUpdate: time.Now(),
+		},
+		Fees: make(map[common.Address][]models.TFeeSnapshot),
+	}
+	safeSyncMap(_feesSyncMap, chainID).Range(func(key, value interface{}) bool {
+		data.Fees[key.(common.Address)] = value.([]models.TFeeSnapshot)
+		return true
+	})
+
+	writeElement(`fees`, chainID, data)
+}
+
+/**************************************************************************************************
+** LoadFees will retrieve the fee histories from the JSON file and store them in the _feesSyncMap
+** for fast access during that same execution.
+**************************************************************************************************/
+func LoadFees(chainID uint64, wg *sync.WaitGroup) {
+	if wg != nil {
+		defer wg.Done()
+	}
+	mutex := getElementMutex(`fees`, chainID)
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	file := TJsonFeesStorage{}
+	readElement(`fees`, chainID, &file)
+	for address, history := range file.Fees {
+		safeSyncMap(_feesSyncMap, chainID).Store(address, history)
+	}
+}
+
+/**************************************************************************************************
+** StoreFeeChange inserts a fee change indexed from the events of a vault in its fee history, kept
+** sorted by block. A change at an already known block replaces it, the events being scanned again
+** from the activation of the vault after a restart.
+**************************************************************************************************/
+func StoreFeeChange(chainID uint64, vaultAddress common.Address, change models.TFeeSnapshot) {
+	history := ListFeeHistory(chainID, vaultAddress)
+	index := sort.Search(len(history), func(i int) bool {
+		return history[i].BlockNumber >= change.BlockNumber
+	})
+	if index < len(history) && history[index].BlockNumber == change.BlockNumber {
+		history[index] = change
+	} else {
+		history = append(history, models.TFeeSnapshot{})
+		copy(history[index+1:], history[index:])
+		history[index] = change
+	}
+	safeSyncMap(_feesSyncMap, chainID).Store(vaultAddress, history)
+}
+
+/**************************************************************************************************
+** ListFeeHistory returns the fee history of a vault, sorted from the oldest to the newest change.
+**************************************************************************************************/
+func ListFeeHistory(chainID uint64, vaultAddress common.Address) []models.TFeeSnapshot {
+	history, ok := safeSyncMap(_feesSyncMap, chainID).Load(vaultAddress)
+	if !ok {
+		return []models.TFeeSnapshot{}
+	}
+	return append([]models.TFeeSnapshot{}, history.([]models.TFeeSnapshot)...)
+}
@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** TestStoreFeeChange tests that the fee changes are kept sorted by block whatever the order they
+** are indexed in, and that a change at an already known block replaces it.
+**************************************************************************************************/
+func TestStoreFeeChange(t *testing.T) {
+	vault := common.HexToAddress(`0x1`)
+	StoreFeeChange(1337, vault, models.TFeeSnapshot{BlockNumber: 20, Timestamp: 200, PerformanceFee: 2000})
+	StoreFeeChange(1337, vault, models.TFeeSnapshot{BlockNumber: 10, Timestamp: 100, PerformanceFee: 1000})
+	StoreFeeChange(1337, vault, models.TFeeSnapshot{BlockNumber: 30, Timestamp: 300, PerformanceFee: 3000})
+	StoreFeeChange(1337, vault, models.TFeeSnapshot{BlockNumber: 20, Timestamp: 200, PerformanceFee: 1500, ManagementFee: 100})
+
+	assert.Equal(t, []models.TFeeSnapshot{
+		{BlockNumber: 10, Timestamp: 100, PerformanceFee: 1000},
+		{BlockNumber: 20, Timestamp: 200, PerformanceFee: 1500, ManagementFee: 100},
+		{BlockNumber: 30, Timestamp: 300, PerformanceFee: 3000},
+	}, ListFeeHistory(1337, vault))
+}
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+)
+
+/**************************************************************************************************
+** TDataFreshness is the freshness of the data of a chain: the block the oldest of its data
+** processes started from, the timestamp of this block, and how far behind the head of the RPC it
+** is, in seconds.
+**************************************************************************************************/
+type TDataFreshness struct {
+	Block         uint64 `json:"block"`
+	Timestamp     uint64 `json:"timestamp"`
+	LagSeconds    uint64 `json:"lagSeconds"`
+	SequencerDown bool   `json:"sequencerDown,omitempty"`
+	RPCDown       bool   `json:"rpcDown,omitempty"`
+}
+
+/**************************************************************************************************
+** TSequencerStatus is the last status read from the sequencer uptime feed of a L2 chain: whether
+** the sequencer is down, since when (the start of the round of the feed) and when it was read.
+**************************************************************************************************/
+type TSequencerStatus struct {
+	IsDown    bool   `json:"isDown"`
+	Since     uint64 `json:"since"`
+	CheckedAt uint64 `json:"checkedAt"`
+}
+
+/**************************************************************************************************
+** TRPCHealth is the last health check of the RPC endpoints of a chain: whether the chain is
+** degraded because none of them answered for the whole dead window, since when they all fail (0
+** while one answers), when one last answered and when they were checked.
+**************************************************************************************************/
+type TRPCHealth struct {
+	IsDegraded    bool   `json:"isDegraded"`
+	FailingSince  uint64 `json:"failingSince,omitempty"`
+	LastHealthyAt uint64 `json:"lastHealthyAt,omitempty"`
+	CheckedAt     uint64 `json:"checkedAt"`
+}
+
+/**************************************************************************************************
+** TProcessBlock is the block the last run of a data process of a chain started from.
+**************************************************************************************************/
+type TProcessBlock struct {
+	Process   string `json:"process"`
+	Block     uint64 `json:"block"`
+	Timestamp uint64 `json:"timestamp"`
+}
+
+var _processBlocks = make(map[uint64]map[string]TProcessBlock)
+var _chainFreshness = make(map[uint64]TDataFreshness)
+var _laggingChains = make(map[uint64]bool)
+var _sequencerStatuses = make(map[uint64]TSequencerStatus)
+var _rpcHealths = make(map[uint64]TRPCHealth)
+var _freshnessLock sync.RWMutex
+
+/**************************************************************************************************
+** StoreProcessBlock records the block the last run of a data process of a chain started from.
+**************************************************************************************************/
+func StoreProcessBlock(chainID uint64, process string, blockNumber uint64, timestamp uint64) {
+	_freshnessLock.Lock()
+	defer _freshnessLock.Unlock()
+	if _, ok := _processBlocks[chainID]; !ok {
+		_processBlocks[chainID] = make(map[string]TProcessBlock)
+	}
+	_processBlocks[chainID][process] = TProcessBlock{Process: process, Block: blockNumber, Timestamp: timestamp}
+}
+
+/**************************************************************************************************
+** GetProcessBlock returns the block the last run of a data process of a chain started from.
+**************************************************************************************************/
+func GetProcessBlock(chainID uint64, process string) (TProcessBlock, bool) {
+	_freshnessLock.RLock()
+	defer _freshnessLock.RUnlock()
+	processBlock, ok := _processBlocks[chainID][process]
+	return processBlock, ok
+}
+
+/**************************************************************************************************
+** ListProcessBlocks returns the blocks of the data processes of a chain, sorted by process name.
+**************************************************************************************************/
+func ListProcessBlocks(chainID uint64) []TProcessBlock {
+	_freshnessLock.RLock()
+	defer _freshnessLock.RUnlock()
+	processBlocks := []TProcessBlock{}
+	for _, processBlock := range _processBlocks[chainID] {
+		processBlocks = append(processBlocks, processBlock)
+	}
+	sort.Slice(processBlocks, func(i, j int) bool {
+		return processBlocks[i].Process < processBlocks[j].Process
+	})
+	return processBlocks
+}
+
+/**************************************************************************************************
+** StoreChainFreshness records the freshness of the data of a chain, and whether it lags too far
+** behind the head of the RPC for its responses to be labeled.
+**************************************************************************************************/
+func StoreChainFreshness(chainID uint64, freshness TDataFreshness, isLagging bool) {
+	_freshnessLock.Lock()
+	defer _freshnessLock.Unlock()
+	_chainFreshness[chainID] = freshness
+	_laggingChains[chainID] = isLagging
+}
+
+/**************************************************************************************************
+** GetChainFreshness returns the last freshness measured for a chain, and whether it is lagging.
+** It returns a zero freshness when it was never measured.
+**************************************************************************************************/
+func GetChainFreshness(chainID uint64) (TDataFreshness, bool) {
+	_freshnessLock.RLock()
+	defer _freshnessLock.RUnlock()
+	return _chainFreshness[chainID], _laggingChains[chainID]
+}
+
+/**************************************************************************************************
+** GetLaggingChainFreshness returns the freshness of a chain only when it is lagging, to label the
+** responses built from its data, and nil otherwise.
+**************************************************************************************************/
+func GetLaggingChainFreshness(chainID uint64) *TDataFreshness {
+	freshness, isLagging := GetChainFreshness(chainID)
+	if IsSequencerDown(chainID) || IsRPCDown(chainID) {
+		freshness.SequencerDown = IsSequencerDown(chainID)
+		freshness.RPCDown = IsRPCDown(chainID)
+		return &freshness
+	}
+	if !isLagging {
+		return nil
+	}
+	return &freshness
+}
+
+/**************************************************************************************************
+** StoreSequencerStatus records the last status read from the sequencer uptime feed of a chain.
+**************************************************************************************************/
+func StoreSequencerStatus(chainID uint64, status TSequencerStatus) {
+	_freshnessLock.Lock()
+	defer _freshnessLock.Unlock()
+	_sequencerStatuses[chainID] = status
+}
+
+/**************************************************************************************************
+** GetSequencerStatus returns the last status read from the sequencer uptime feed of a chain. The
+** boolean is false for the chains without feed, or when it was never read.
+**************************************************************************************************/
+func GetSequencerStatus(chainID uint64) (TSequencerStatus, bool) {
+	_freshnessLock.RLock()
+	defer _freshnessLock.RUnlock()
+	status, ok := _sequencerStatuses[chainID]
+	return status, ok
+}
+
+/**************************************************************************************************
+** IsSequencerDown returns true when the last status read for the sequencer of a chain is down,
+** the data of the chain being potentially stale until it is back up.
+**************************************************************************************************/
+func IsSequencerDown(chainID uint64) bool {
+	status, _ := GetSequencerStatus(chainID)
+	return status.IsDown
+}
+
+/**************************************************************************************************
+** StoreRPCHealth records the last health check of the RPC endpoints of a chain.
+**************************************************************************************************/
+func StoreRPCHealth(chainID uint64, health TRPCHealth) {
+	_freshnessLock.Lock()
+	defer _freshnessLock.Unlock()
+	_rpcHealths[chainID] = health
+}
+
+/**************************************************************************************************
+** GetRPCHealth returns the last health check of the RPC endpoints of a chain. The boolean is false
+** when they were never checked.
+**************************************************************************************************/
+func GetRPCHealth(chainID uint64) (TRPCHealth, bool) {
+	_freshnessLock.RLock()
+	defer _freshnessLock.RUnlock()
+	health, ok := _rpcHealths[chainID]
+	return health, ok
+}
+
+/**************************************************************************************************
+** IsRPCDown returns true while a chain is degraded because none of its RPC endpoints answers, its
+** last known data being served as potentially stale until one of them is back.
+**************************************************************************************************/
+func IsRPCDown(chainID uint64) bool {
+	health, _ := GetRPCHealth(chainID)
+	return health.IsDegraded
+}
@@ -0,0 +1,26 @@
+package storage
+
+import "testing"
+
+/**************************************************************************************************
+** TestLaggingChainFreshnessWhileRPCDown checks that the freshness of a degraded chain labels its
+** responses whatever its lag, and stops once the chain is re-enabled.
+**************************************************************************************************/
+func TestLaggingChainFreshnessWhileRPCDown(t *testing.T) {
+	chainID := uint64(1337)
+	StoreChainFreshness(chainID, TDataFreshness{Block: 10, LagSeconds: 60}, false)
+	if freshness := GetLaggingChainFreshness(chainID); freshness != nil {
+		t.Fatalf("expected no freshness label on a fresh chain, got %+v", freshness)
+	}
+
+	StoreRPCHealth(chainID, TRPCHealth{IsDegraded: true, FailingSince: 100, CheckedAt: 1000})
+	freshness := GetLaggingChainFreshness(chainID)
+	if freshness == nil || !freshness.RPCDown || freshness.SequencerDown || freshness.Block != 10 {
+		t.Fatalf("expected the freshness to be labeled rpcDown, got %+v", freshness)
+	}
+
+	StoreRPCHealth(chainID, TRPCHealth{LastHealthyAt: 1060, CheckedAt: 1060})
+	if freshness := GetLaggingChainFreshness(chainID); freshness != nil {
+		t.Errorf("expected no freshness label once the chain is re-enabled, got %+v", freshness)
+	}
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+/**************************************************************************************************
+** TRoleHolder is an account holding some roles on a v3 vault.
+**************************************************************************************************/
+type TRoleHolder struct {
+	Account common.Address `json:"account"`
+	Roles   uint64         `json:"roles"`
+	Names   []string       `json:"names"`
+}
+
+/**************************************************************************************************
+** TRoleChange is a change of the roles of an account, or of the role manager, of a v3 vault. For a
+** RoleSet event, Roles is the whole bitmap of the account after the change. For a change of the
+** role manager, Type is `roleManager` and Account the new role manager.
+**************************************************************************************************/
+type TRoleChange struct {
+	Type        string         `json:"type"`
+	Account     common.Address `json:"account"`
+	Roles       uint64         `json:"roles"`
+	Names       []string       `json:"names"`
+	TxHash      common.Hash    `json:"txHash"`
+	BlockNumber uint64         `json:"blockNumber"`
+	Timestamp   uint64         `json:"timestamp"`
+}
+
+/**************************************************************************************************
+** TVaultGovernance is the access control of a v3 vault: its role manager, the accounts holding a
+** role, and the history of the changes of roles, oldest first.
+**************************************************************************************************/
+type TVaultGovernance struct {
+	RoleManager common.Address `json:"roleManager"`
+	Holders     []TRoleHolder  `json:"holders"`
+	History     []TRoleChange  `json:"history"`
+}
+
+/**************************************************************************************************
+** TJsonGovernanceStorage holds the access control of the v3 vaults of a chain and the next block
+** to scan for each of them. It is persisted as the `governance` element of the chain, for the
+** scan to resume after a restart.
+**************************************************************************************************/
+type TJsonGovernanceStorage struct {
+	NextBlocks  map[common.Address]uint64            `json:"nextBlocks"`
+	Governances map[common.Address]*TVaultGovernance `json:"governances"`
+}
+
+var _governanceLock sync.Mutex
+
+/**************************************************************************************************
+** LoadVaultsGovernance returns the access control last stored for a chain, or an empty one.
+**************************************************************************************************/
+func LoadVaultsGovernance(chainID uint64) TJsonGovernanceStorage {
+	_governanceLock.Lock()
+	defer _governanceLock.Unlock()
+
+	stored := TJsonGovernanceStorage{}
+	if !readElement(`governance`, chainID, &stored) {
+		stored = TJsonGovernanceStorage{}
+	}
+	if stored.NextBlocks == nil {
+		stored.NextBlocks = make(map[common.Address]uint64)
+	}
+	if stored.Governances == nil {
+		stored.Governances = make(map[common.Address]*TVaultGovernance)
+	}
+	return stored
+}
+
+/**************************************************************************************************
+** StoreVaultsGovernance persists the access control of the v3 vaults of a chain.
+**************************************************************************************************/
+func StoreVaultsGovernance(chainID uint64, stored TJsonGovernanceStorage) {
+	_governanceLock.Lock()
+	defer _governanceLock.Unlock()
+
+	writeElement(`governance`, chainID, stored)
+}
@@ -0,0 +1,46 @@
+package storage
+
+import "sync"
+
+/**************************************************************************************************
+** TJsonHoldersStorage holds the share balances of the holders of the vaults of a chain, rebuilt
+** from the Transfer events of the vaults: the balances by holder address by vault address, as
+** decimal strings, and the block the vaults were scanned up to (included), by vault address. It
+** is persisted as the `holders` element of the chain, for the scan to resume after a restart.
+**************************************************************************************************/
+type TJsonHoldersStorage struct {
+	ScannedUpTo map[string]uint64            `json:"scannedUpTo"`
+	Balances    map[string]map[string]string `json:"balances"`
+}
+
+var _holdersLock sync.Mutex
+
+/**************************************************************************************************
+** LoadHolderBalances returns the share balances last stored for a chain, or empty ones.
+**************************************************************************************************/
+func LoadHolderBalances(chainID uint64) TJsonHoldersStorage {
+	_holdersLock.Lock()
+	defer _holdersLock.Unlock()
+
+	holders := TJsonHoldersStorage{}
+	if !readElement(`holders`, chainID, &holders) {
+		holders = TJsonHoldersStorage{}
+	}
+	if holders.ScannedUpTo == nil {
+		holders.ScannedUpTo = make(map[string]uint64)
+	}
+	if holders.Balances == nil {
+		holders.Balances = make(map[string]map[string]string)
+	}
+	return holders
+}
+
+/**************************************************************************************************
+** StoreHolderBalances persists the share balances of the holders of the vaults of a chain.
+**************************************************************************************************/
+func StoreHolderBalances(chainID uint64, holders TJsonHoldersStorage) {
+	_holdersLock.Lock()
+	defer _holdersLock.Unlock()
+
+	writeElement(`holders`, chainID, holders)
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+var _inceptionLock sync.Mutex
+
+/**************************************************************************************************
+** LoadVaultInceptions returns the inceptions of the vaults of a chain last stored, by vault
+** address. They are persisted as the `inception` element of the chain, the creation of a vault
+** never changing once backfilled.
+**************************************************************************************************/
+func LoadVaultInceptions(chainID uint64) map[common.Address]models.TVaultInception {
+	_inceptionLock.Lock()
+	defer _inceptionLock.Unlock()
+
+	inceptions := make(map[common.Address]models.TVaultInception)
+	if !readElement(`inception`, chainID, &inceptions) {
+		return make(map[common.Address]models.TVaultInception)
+	}
+	return inceptions
+}
+
+/**************************************************************************************************
+** StoreVaultInceptions persists the inceptions of the vaults of a chain.
+**************************************************************************************************/
+func StoreVaultInceptions(chainID uint64, inceptions map[common.Address]models.TVaultInception) {
+	_inceptionLock.Lock()
+	defer _inceptionLock.Unlock()
+
+	writeElement(`inception`, chainID, inceptions)
+}
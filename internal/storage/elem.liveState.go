@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+)
+
+/**************************************************************************************************
+** The live state of a vault is its total assets read on chain by the refreshes on the new heads,
+** between the snapshots. It is only kept in memory: it is read again after a restart.
+**************************************************************************************************/
+type TLiveVaultState struct {
+	TotalAssets *bigNumber.Int
+	BlockNumber uint64
+	UpdatedAt   time.Time
+}
+
+var _liveVaultStateSyncMap = make(map[uint64]*sync.Map)
+
+/**************************************************************************************************
+** StoreLiveVaultState stores the live state of a vault, unless a state of a later block is known.
+**************************************************************************************************/
+func StoreLiveVaultState(chainID uint64, vaultAddress common.Address, state TLiveVaultState) {
+	if previous, ok := GetLiveVaultState(chainID, vaultAddress); ok && previous.BlockNumber > state.BlockNumber {
+		return
+	}
+	safeSyncMap(_liveVaultStateSyncMap, chainID).Store(vaultAddress, state)
+}
+
+/**************************************************************************************************
+** GetLiveVaultState returns the live state of a vault, if it was read on a new head.
+**************************************************************************************************/
+func GetLiveVaultState(chainID uint64, vaultAddress common.Address) (TLiveVaultState, bool) {
+	state, ok := safeSyncMap(_liveVaultStateSyncMap, chainID).Load(vaultAddress)
+	if !ok {
+		return TLiveVaultState{}, false
+	}
+	return state.(TLiveVaultState), true
+}
+
+func init() {
+	for _, chain := range env.GetChains() {
+		_liveVaultStateSyncMap[chain.ID] = &sync.Map{}
+	}
+}
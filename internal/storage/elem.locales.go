@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+/**************************************************************************************************
+** The names and descriptions of the vaults and strategies are in English. Their translations are
+** set in BASE_DATA_PATH/meta/locales/<chainID>.<locale>.json, one file per chain and locale,
+** keyed by the address of the vault or strategy:
+** { "<address>": { "name": "Bóveda USDC", "description": "..." } }
+** The locales are lowercase BCP 47 tags (`fr`, `pt-br`). A missing string falls back to English.
+**************************************************************************************************/
+const DEFAULT_LOCALE = `en`
+
+type TLocalizedStrings struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+var (
+	_localizedStrings    = make(map[uint64]map[string]map[common.Address]TLocalizedStrings)
+	_localizedStringsMtx sync.RWMutex
+)
+
+/**************************************************************************************************
+** LoadLocales reads the translation files of a chain, replacing the ones loaded before. The files
+** are optional, and a file that cannot be decoded is ignored.
+**************************************************************************************************/
+func LoadLocales(chainID uint64) {
+	chainIDStr := strconv.FormatUint(chainID, 10)
+	filePaths, _ := filepath.Glob(env.BASE_DATA_PATH + `/meta/locales/` + chainIDStr + `.*.json`)
+
+	locales := make(map[string]map[common.Address]TLocalizedStrings)
+	for _, filePath := range filePaths {
+		locale := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(filePath), chainIDStr+`.`), `.json`)
+		locale = strings.ToLower(locale)
+		if locale == `` || locale == DEFAULT_LOCALE {
+			continue
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			continue
+		}
+		fileStrings := make(map[string]TLocalizedStrings)
+		if err := json.Unmarshal(content, &fileStrings); err != nil {
+			logs.Error(`Failed to decode the ` + locale + ` translations of chain ` + chainIDStr + `: ` + err.Error())
+			continue
+		}
+		locales[locale] = make(map[common.Address]TLocalizedStrings)
+		for address, localized := range fileStrings {
+			locales[locale][common.HexToAddress(address)] = localized
+		}
+	}
+
+	_localizedStringsMtx.Lock()
+	_localizedStrings[chainID] = locales
+	_localizedStringsMtx.Unlock()
+}
+
+/**************************************************************************************************
+** ListLocales returns the locales available on at least one chain, English included, sorted.
+**************************************************************************************************/
+func ListLocales() []string {
+	_localizedStringsMtx.RLock()
+	defer _localizedStringsMtx.RUnlock()
+
+	available := map[string]bool{DEFAULT_LOCALE: true}
+	for _, locales := range _localizedStrings {
+		for locale := range locales {
+			available[locale] = true
+		}
+	}
+	locales := []string{}
+	for locale := range available {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+/**************************************************************************************************
+** GetLocalizedStrings returns the translations of the name and description of a vault or strategy
+** in a locale. The boolean is false when it has none.
+**************************************************************************************************/
+func GetLocalizedStrings(chainID uint64, address common.Address, locale string) (TLocalizedStrings, bool) {
+	_localizedStringsMtx.RLock()
+	defer _localizedStringsMtx.RUnlock()
+	localized, ok := _localizedStrings[chainID][locale][address]
+	return localized, ok
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** The metrics history keeps the APY and TVL of each vault for METRICS_HISTORY_RETENTION, enough to
+** compute the changes over the last 7 days. Older snapshots are dropped when a new one is added.
+**************************************************************************************************/
+const METRICS_HISTORY_RETENTION = 8 * 24 * time.Hour
+
+var _metricsSyncMap = make(map[uint64]*sync.Map)
+
+type TJsonMetricsStorage struct {
+	TJsonMetadata
+	Metrics map[common.Address][]models.TVaultMetricsSnapshot `json:"metrics"`
+}
+
+/** 🔵 - Yearn *************************************************************************************
+** The function `StoreMetricsToJson` is responsible for storing the metrics histories to a JSON
+** file.
+**************************************************************************************************/
+func StoreMetricsToJson(chainID uint64) {
+	mutex := getElementMutex(`metrics`, chainID)
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	data := TJsonMetricsStorage{
+		TJsonMetadata: TJsonMetadata{
+			LastUpdate: time.Now(),
+		},
+		Metrics: make(map[common.Address][]models.TVaultMetricsSnapshot),
+	}
+	safeSyncMap(_metricsSyncMap, chainID).Range(func(key, value interface{}) bool {
+		data.Metrics[key.(common.Address)] = value.([]models.TVaultMetricsSnapshot)
+		return true
+	})
+
+	writeElement(`metrics`, chainID, data)
+}
+
+/**************************************************************************************************
+** LoadMetrics will retrieve the metrics histories from the JSON file and store them in the
+** _metricsSyncMap for fast access during that same execution.
+**************************************************************************************************/
+func LoadMetrics(chainID uint64, wg *sync.WaitGroup) {
+	if wg != nil {
+		defer wg.Done()
+	}
+	mutex := getElementMutex(`metrics`, chainID)
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	file := TJsonMetricsStorage{}
+	readElement(`metrics`, chainID, &file)
+	for address, history := range file.Metrics {
+		safeSyncMap(_metricsSyncMap, chainID).Store(address, history)
+	}
+}
+
+/**************************************************************************************************
+** StoreVaultMetricsSnapshot appends a snapshot to the metrics history of a vault and drops the
+** snapshots older than METRICS_HISTORY_RETENTION.
+**************************************************************************************************/
+func StoreVaultMetricsSnapshot(chainID uint64, vaultAddress common.Address, snapshot models.TVaultMetricsSnapshot) {
+	oldestTimestamp := uint64(time.Now().Add(-METRICS_HISTORY_RETENTION).Unix())
+	history := []models.TVaultMetricsSnapshot{}
+	for _, previous := range ListVaultMetricsHistory(chainID, vaultAddress) {
+		if previous.Timestamp >= oldestTimestamp {
+			history = append(history, previous)
+		}
+	}
+	history = append(history, snapshot)
+	safeSyncMap(_metricsSyncMap, chainID).Store(vaultAddress, history)
+}
+
+/**************************************************************************************************
+** ListVaultMetricsHistory returns the metrics history of a vault, sorted from the oldest to the
+** newest snapshot.
+**************************************************************************************************/
+func ListVaultMetricsHistory(chainID uint64, vaultAddress common.Address) []models.TVaultMetricsSnapshot {
+	history, ok := safeSyncMap(_metricsSyncMap, chainID).Load(vaultAddress)
+	if !ok {
+		return []models.TVaultMetricsSnapshot{}
+	}
+	return append([]models.TVaultMetricsSnapshot{}, history.([]models.TVaultMetricsSnapshot)...)
+}
+
+/**************************************************************************************************
+** GetVaultMetricsAt returns the most recent snapshot of a vault taken at or before the timestamp.
+** The boolean is false if the history does not go back that far.
+**************************************************************************************************/
+func GetVaultMetricsAt(chainID uint64, vaultAddress common.Address, timestamp uint64) (models.TVaultMetricsSnapshot, bool) {
+	history := ListVaultMetricsHistory(chainID, vaultAddress)
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Timestamp <= timestamp {
+			return history[i], true
+		}
+	}
+	return models.TVaultMetricsSnapshot{}, false
+}
+
+/**************************************************************************************************
+** GetLastVaultMetrics returns the most recent snapshot of a vault, if any.
+**************************************************************************************************/
+func GetLastVaultMetrics(chainID uint64, vaultAddress common.Address) (models.TVaultMetricsSnapshot, bool) {
+	history := ListVaultMetricsHistory(chainID, vaultAddress)
+	if len(history) == 0 {
+		return models.TVaultMetricsSnapshot{}, false
+	}
+	return history[len(history)-1], true
+}
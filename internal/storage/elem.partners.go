@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/addresses"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** The partner views are the subsets of vaults approved by a partner, served under
+** /partners/<partner>/vaults with the fields of the partner. They are set in
+** BASE_DATA_PATH/meta/partners.json, keyed by the lowercase name of the partner used in the URL:
+** {
+**   "ledger": {
+**     "name": "Ledger",
+**     "referralCode": "ledger",
+**     "depositContracts": { "1": "0x..." },
+**     "categories": ["Curve"],
+**     "chainIDs": [1],
+**     "vaults": [{ "chainID": 1, "address": "0x...", "depositContract": "0x...", "referralCode": "..." }]
+**   }
+** }
+** A vault is in the view when it's listed in vaults, or when it's a Yearn vault of one of the
+** categories, on one of the chainIDs (all the chains when empty). The deposit contract and the
+** referral code of a vault default to the ones of its chain and of the partner.
+**************************************************************************************************/
+type TPartnerVault struct {
+	ChainID         uint64 `json:"chainID"`
+	Address         string `json:"address"`
+	DepositContract string `json:"depositContract,omitempty"`
+	ReferralCode    string `json:"referralCode,omitempty"`
+}
+
+type TPartnerView struct {
+	Name             string            `json:"name"`
+	ReferralCode     string            `json:"referralCode,omitempty"`
+	DepositContracts map[string]string `json:"depositContracts,omitempty"`
+	Categories       []string          `json:"categories,omitempty"`
+	ChainIDs         []uint64          `json:"chainIDs,omitempty"`
+	Vaults           []TPartnerVault   `json:"vaults,omitempty"`
+}
+
+/**************************************************************************************************
+** TPartnerFields are the fields of a partner added to the vaults of its view.
+**************************************************************************************************/
+type TPartnerFields struct {
+	Partner         string `json:"partner"`
+	DepositContract string `json:"depositContract,omitempty"`
+	ReferralCode    string `json:"referralCode,omitempty"`
+}
+
+var (
+	_partnerViews    = make(map[string]TPartnerView)
+	_partnerViewsMtx sync.RWMutex
+)
+
+/**************************************************************************************************
+** LoadPartnerViews reads the partner views, replacing the ones loaded before. The file is
+** optional, and the views are kept as they are when it cannot be decoded.
+**************************************************************************************************/
+func LoadPartnerViews() {
+	views := make(map[string]TPartnerView)
+	content, err := os.ReadFile(env.BASE_DATA_PATH + `/meta/partners.json`)
+	if err == nil {
+		fileViews := make(map[string]TPartnerView)
+		if err := json.Unmarshal(content, &fileViews); err != nil {
+			logs.Error(`Failed to decode the partner views: ` + err.Error())
+			return
+		}
+		for partner, view := range fileViews {
+			views[strings.ToLower(partner)] = view
+		}
+	}
+
+	_partnerViewsMtx.Lock()
+	_partnerViews = views
+	_partnerViewsMtx.Unlock()
+}
+
+/**************************************************************************************************
+** GetPartnerView returns the view of a partner, by its name in the URL.
+**************************************************************************************************/
+func GetPartnerView(partner string) (TPartnerView, bool) {
+	_partnerViewsMtx.RLock()
+	defer _partnerViewsMtx.RUnlock()
+	view, ok := _partnerViews[strings.ToLower(partner)]
+	return view, ok
+}
+
+/**************************************************************************************************
+** ListPartners returns the names in the URL of the partners with a view, sorted.
+**************************************************************************************************/
+func ListPartners() []string {
+	_partnerViewsMtx.RLock()
+	defer _partnerViewsMtx.RUnlock()
+	partners := []string{}
+	for partner := range _partnerViews {
+		partners = append(partners, partner)
+	}
+	sort.Strings(partners)
+	return partners
+}
+
+/**************************************************************************************************
+** GetPartnerFields returns the fields of a partner for a vault, and false if the vault is not in
+** the view of the partner.
+**************************************************************************************************/
+func (view TPartnerView) GetPartnerFields(vault models.TVault) (TPartnerFields, bool) {
+	fields := TPartnerFields{
+		Partner:         view.Name,
+		DepositContract: view.DepositContracts[strconv.FormatUint(vault.ChainID, 10)],
+		ReferralCode:    view.ReferralCode,
+	}
+	for _, partnerVault := range view.Vaults {
+		if partnerVault.ChainID != vault.ChainID || !addresses.Equals(common.HexToAddress(partnerVault.Address), vault.Address) {
+			continue
+		}
+		if partnerVault.DepositContract != `` {
+			fields.DepositContract = partnerVault.DepositContract
+		}
+		if partnerVault.ReferralCode != `` {
+			fields.ReferralCode = partnerVault.ReferralCode
+		}
+		return fields, true
+	}
+
+	if len(view.Categories) == 0 || !vault.Metadata.Inclusion.IsYearn {
+		return fields, false
+	}
+	if len(view.ChainIDs) > 0 && !containsChainID(view.ChainIDs, vault.ChainID) {
+		return fields, false
+	}
+	for _, category := range view.Categories {
+		if strings.EqualFold(category, string(vault.Metadata.Category)) {
+			return fields, true
+		}
+	}
+	return fields, false
+}
+
+func containsChainID(chainIDs []uint64, chainID uint64) bool {
+	for _, id := range chainIDs {
+		if id == chainID {
+			return true
+		}
+	}
+	return false
+}
@@ -2,13 +2,10 @@ package storage
 
 import (
 	"encoding/json"
-	"os"
-	"strconv"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/yearn/ydaemon/common/env"
 	"github.com/yearn/ydaemon/common/logs"
 	"github.com/yearn/ydaemon/internal/models"
 )
@@ -41,10 +38,9 @@ func getPricesMutex(chainID uint64) *sync.RWMutex {
 **************************************************************************************************/
 func loadPricesFromJson(chainID uint64) TJsonPricesStorage {
 	var pricesData TJsonPricesStorage
-	chainIDStr := strconv.FormatUint(chainID, 10)
 
 	// Load the JSON file
-	file, err := os.Open(env.BASE_DATA_PATH + "/meta/prices/" + chainIDStr + ".json")
+	file, err := getStorageBackend().Open(`prices`, chainID)
 	if err != nil {
 		return TJsonPricesStorage{}
 	}
@@ -69,7 +65,6 @@ func StorePricesToJson(chainID uint64, pricesData map[common.Address]models.TPri
 	mutex.Lock()
 	defer mutex.Unlock()
 
-	chainIDStr := strconv.FormatUint(chainID, 10)
 	previousPrices := loadPricesFromJson(chainID)
 	version := detectVersionUpdate(chainID, previousPrices.Version, previousPrices.Prices, pricesData)
 
@@ -91,10 +86,7 @@ func StorePricesToJson(chainID uint64, pricesData map[common.Address]models.TPri
 		logs.Error("Failed to marshal prices JSON file: " + err.Error())
 		return
 	}
-	if _, err := os.Stat(env.BASE_DATA_PATH + "/meta/prices"); os.IsNotExist(err) {
-		os.MkdirAll(env.BASE_DATA_PATH+"/meta/prices", 0755)
-	}
-	err = os.WriteFile(env.BASE_DATA_PATH+"/meta/prices/"+chainIDStr+".json", file, 0644)
+	err = getStorageBackend().Write(`prices`, chainID, file)
 	if err != nil {
 		logs.Error("Failed to write prices JSON file: " + err.Error())
 	}
@@ -2,8 +2,6 @@ package storage
 
 import (
 	"encoding/json"
-	"os"
-	"strconv"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -24,10 +22,9 @@ var _newVaultsFromRegistrySyncMap = make(map[uint64]*sync.Map)
 func loadRegistriesFromJson(chainID uint64) (map[common.Address]models.TVaultsFromRegistry, uint64) {
 	var historicalVaults map[common.Address]models.TVaultsFromRegistry
 	var highestBlockNumber uint64
-	chainIDStr := strconv.FormatUint(chainID, 10)
 
 	// Load the JSON file
-	file, err := os.Open(env.BASE_DATA_PATH + "/meta/registries/" + chainIDStr + ".json")
+	file, err := getStorageBackend().Open(`registries`, chainID)
 	if err != nil {
 		return nil, 0
 	}
@@ -57,13 +54,8 @@ func loadRegistriesFromJson(chainID uint64) (map[common.Address]models.TVaultsFr
 ** map to a JSON file. This function is used to save the state of the vaults for later use.
 **************************************************************************************************/
 func StoreRegistriesToJson(chainID uint64, registries map[common.Address]models.TVaultsFromRegistry) {
-	chainIDStr := strconv.FormatUint(chainID, 10)
-
 	file, _ := json.MarshalIndent(registries, "", "\t")
-	if _, err := os.Stat(env.BASE_DATA_PATH + "/meta/registries"); os.IsNotExist(err) {
-		os.MkdirAll(env.BASE_DATA_PATH+"/meta/registries", 0755)
-	}
-	err := os.WriteFile(env.BASE_DATA_PATH+"/meta/registries/"+chainIDStr+".json", file, 0644)
+	err := getStorageBackend().Write(`registries`, chainID, file)
 	if err != nil {
 		logs.Error("Failed to write vaults JSON file: " + err.Error())
 	}
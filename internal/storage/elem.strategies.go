@@ -44,7 +44,7 @@ func loadStrategiesFromJson(chainID uint64) TJsonStrategyStorage {
 	chainIDStr := strconv.FormatUint(chainID, 10)
 
 	// Load the JSON file
-	file, err := os.Open(env.BASE_DATA_PATH + "/meta/strategies/" + chainIDStr + ".json")
+	file, err := getStorageBackend().Open(`strategies`, chainID)
 	if err != nil {
 		return TJsonStrategyStorage{}
 	}
@@ -129,7 +129,6 @@ func StoreStrategiesToJson(chainID uint64, strategies map[string]models.TStrateg
 	mutex.Lock()
 	defer mutex.Unlock()
 
-	chainIDStr := strconv.FormatUint(chainID, 10)
 	previousStrategies := loadStrategiesFromJson(chainID)
 	version := detectStrVersionUpdate(chainID, previousStrategies.Version, previousStrategies.Strategies, strategies)
 
@@ -150,10 +149,7 @@ func StoreStrategiesToJson(chainID uint64, strategies map[string]models.TStrateg
 	if err != nil {
 		logs.Error("Failed to marshal strategies JSON file: " + err.Error())
 	}
-	if _, err := os.Stat(env.BASE_DATA_PATH + "/meta/strategies"); os.IsNotExist(err) {
-		os.MkdirAll(env.BASE_DATA_PATH+"/meta/strategies", 0755)
-	}
-	if err := os.WriteFile(env.BASE_DATA_PATH+"/meta/strategies/"+chainIDStr+".json", file, 0644); err != nil {
+	if err := getStorageBackend().Write(`strategies`, chainID, file); err != nil {
 		logs.Error("Failed to write strategies JSON file: " + err.Error())
 	}
 }
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+/**************************************************************************************************
+** The community suggests corrections of the metadata of the vaults and of the strategies (a typo
+** in a description, a missing protocol tag) through the API instead of a PR on the meta repo.
+** The suggestions wait in a moderation queue per chain, persisted on every change, until an
+** operator accepts or rejects them. At most MAX_PENDING_SUGGESTIONS wait per submitter (a hash
+** of the client IP) and MAX_PENDING_SUGGESTIONS_PER_CHAIN per chain, and the reviewed ones are
+** dropped after SUGGESTIONS_RETENTION.
+**************************************************************************************************/
+const MAX_PENDING_SUGGESTIONS = 500
+const MAX_PENDING_SUGGESTIONS_PER_CHAIN = 5000
+const SUGGESTIONS_RETENTION = 90 * 24 * time.Hour
+
+var (
+	ErrTooManyPendingSuggestions = errors.New(`too many suggestions of this submitter are pending`)
+	ErrSuggestionsQueueFull      = errors.New(`the moderation queue of the chain is full`)
+)
+
+const (
+	SUGGESTION_PENDING  = `pending`
+	SUGGESTION_ACCEPTED = `accepted`
+	SUGGESTION_REJECTED = `rejected`
+)
+
+type TSuggestion struct {
+	ID          uint64         `json:"id"`
+	ChainID     uint64         `json:"chainID"`
+	Address     common.Address `json:"address"`
+	Field       string         `json:"field"`
+	Value       string         `json:"value"`
+	Comment     string         `json:"comment,omitempty"`
+	Contact     string         `json:"contact,omitempty"`
+	Submitter   string         `json:"submitter,omitempty"`
+	Status      string         `json:"status"`
+	SubmittedAt int64          `json:"submittedAt"`
+	ReviewedAt  int64          `json:"reviewedAt,omitempty"`
+}
+
+type TJsonSuggestionsStorage struct {
+	TJsonMetadata
+	NextID      uint64        `json:"nextID"`
+	Suggestions []TSuggestion `json:"suggestions"`
+}
+
+var (
+	_suggestions       = make(map[uint64]*TJsonSuggestionsStorage)
+	_suggestionsMtx    sync.RWMutex
+	_suggestionsLoaded = make(map[uint64]bool)
+)
+
+/**************************************************************************************************
+** getSuggestions returns the queue of a chain, loaded from the backend on first use. The caller
+** holds _suggestionsMtx.
+**************************************************************************************************/
+func getSuggestions(chainID uint64) *TJsonSuggestionsStorage {
+	if !_suggestionsLoaded[chainID] {
+		_suggestionsLoaded[chainID] = true
+		queue := &TJsonSuggestionsStorage{NextID: 1}
+		readElement(`suggestions`, chainID, queue)
+		_suggestions[chainID] = queue
+	}
+	return _suggestions[chainID]
+}
+
+/**************************************************************************************************
+** storeSuggestions persists the queue of a chain, dropping the suggestions reviewed before the
+** retention. The caller holds _suggestionsMtx.
+**************************************************************************************************/
+func storeSuggestions(chainID uint64) {
+	queue := getSuggestions(chainID)
+	oldestReview := time.Now().Add(-SUGGESTIONS_RETENTION).Unix()
+	kept := []TSuggestion{}
+	for _, suggestion := range queue.Suggestions {
+		if suggestion.Status == SUGGESTION_PENDING || suggestion.ReviewedAt >= oldestReview {
+			kept = append(kept, suggestion)
+		}
+	}
+	queue.Suggestions = kept
+	queue.LastUpdate = time.Now()
+
+	writeElement(`suggestions`, chainID, queue)
+}
+
+/**************************************************************************************************
+** AddSuggestion queues a suggestion of a chain as pending, and returns it with its ID. It returns
+** ErrTooManyPendingSuggestions when its submitter already has MAX_PENDING_SUGGESTIONS pending, and
+** ErrSuggestionsQueueFull when the queue of the chain is full.
+**************************************************************************************************/
+func AddSuggestion(suggestion TSuggestion) (TSuggestion, error) {
+	_suggestionsMtx.Lock()
+	defer _suggestionsMtx.Unlock()
+
+	queue := getSuggestions(suggestion.ChainID)
+	pendingCount := 0
+	submitterPendingCount := 0
+	for _, queued := range queue.Suggestions {
+		if queued.Status != SUGGESTION_PENDING {
+			continue
+		}
+		pendingCount++
+		if queued.Submitter == suggestion.Submitter {
+			submitterPendingCount++
+		}
+	}
+	if submitterPendingCount >= MAX_PENDING_SUGGESTIONS {
+		return TSuggestion{}, ErrTooManyPendingSuggestions
+	}
+	if pendingCount >= MAX_PENDING_SUGGESTIONS_PER_CHAIN {
+		return TSuggestion{}, ErrSuggestionsQueueFull
+	}
+
+	suggestion.ID = queue.NextID
+	suggestion.Status = SUGGESTION_PENDING
+	suggestion.SubmittedAt = time.Now().Unix()
+	suggestion.ReviewedAt = 0
+	queue.NextID++
+	queue.Suggestions = append(queue.Suggestions, suggestion)
+	storeSuggestions(suggestion.ChainID)
+	return suggestion, nil
+}
+
+/**************************************************************************************************
+** ReviewSuggestion sets the status of a suggestion of a chain, accepted or rejected. It returns
+** false when the suggestion does not exist.
+**************************************************************************************************/
+func ReviewSuggestion(chainID uint64, id uint64, status string) (TSuggestion, bool) {
+	_suggestionsMtx.Lock()
+	defer _suggestionsMtx.Unlock()
+
+	queue := getSuggestions(chainID)
+	for i, suggestion := range queue.Suggestions {
+		if suggestion.ID != id {
+			continue
+		}
+		queue.Suggestions[i].Status = status
+		queue.Suggestions[i].ReviewedAt = time.Now().Unix()
+		reviewed := queue.Suggestions[i]
+		storeSuggestions(chainID)
+		return reviewed, true
+	}
+	return TSuggestion{}, false
+}
+
+/**************************************************************************************************
+** ListSuggestions returns the suggestions of a chain with a status, all of them when the status is
+** empty, from the oldest to the newest.
+**************************************************************************************************/
+func ListSuggestions(chainID uint64, status string) []TSuggestion {
+	_suggestionsMtx.Lock()
+	defer _suggestionsMtx.Unlock()
+
+	suggestions := []TSuggestion{}
+	for _, suggestion := range getSuggestions(chainID).Suggestions {
+		if status == `` || suggestion.Status == status {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	return suggestions
+}
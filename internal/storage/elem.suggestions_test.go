@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+/**************************************************************************************************
+** TestSuggestions tests that the suggestions are queued as pending with their own ID, reviewed,
+** listed by status, and reloaded from the backend after a restart.
+**************************************************************************************************/
+func TestSuggestions(t *testing.T) {
+	_storageBackendOnce.Do(func() {
+		_storageBackend = newMemoryBackend()
+	})
+	vault := common.HexToAddress(`0x182863131F9a4630fF9E27830d945B1413e347E8`)
+
+	first, err := AddSuggestion(TSuggestion{ChainID: 1, Address: vault, Field: `description`, Value: `Fixed typo`})
+	assert.NoError(t, err)
+	assert.Equal(t, SUGGESTION_PENDING, first.Status)
+	second, err := AddSuggestion(TSuggestion{ChainID: 1, Address: vault, Field: `protocols`, Value: `Morpho`})
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID+1, second.ID)
+
+	reviewed, ok := ReviewSuggestion(1, first.ID, SUGGESTION_ACCEPTED)
+	assert.True(t, ok)
+	assert.Equal(t, SUGGESTION_ACCEPTED, reviewed.Status)
+	_, ok = ReviewSuggestion(1, 999, SUGGESTION_REJECTED)
+	assert.False(t, ok)
+
+	_suggestionsMtx.Lock()
+	_suggestionsLoaded = make(map[uint64]bool)
+	_suggestionsMtx.Unlock()
+	assert.Len(t, ListSuggestions(1, ``), 2)
+	pending := ListSuggestions(1, SUGGESTION_PENDING)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, `Morpho`, pending[0].Value)
+	assert.Empty(t, ListSuggestions(10, ``))
+}
+
+/**************************************************************************************************
+** TestSuggestionsPendingPerSubmitter tests that a submitter cannot queue more than
+** MAX_PENDING_SUGGESTIONS pending suggestions, the other submitters still being able to.
+**************************************************************************************************/
+func TestSuggestionsPendingPerSubmitter(t *testing.T) {
+	_storageBackendOnce.Do(func() {
+		_storageBackend = newMemoryBackend()
+	})
+	vault := common.HexToAddress(`0x182863131F9a4630fF9E27830d945B1413e347E8`)
+
+	for i := 0; i < MAX_PENDING_SUGGESTIONS; i++ {
+		_, err := AddSuggestion(TSuggestion{ChainID: 250, Address: vault, Field: `name`, Value: `Spam`, Submitter: `a`})
+		assert.NoError(t, err)
+	}
+	_, err := AddSuggestion(TSuggestion{ChainID: 250, Address: vault, Field: `name`, Value: `Spam`, Submitter: `a`})
+	assert.ErrorIs(t, err, ErrTooManyPendingSuggestions)
+	_, err = AddSuggestion(TSuggestion{ChainID: 250, Address: vault, Field: `name`, Value: `Fix`, Submitter: `b`})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+/**************************************************************************************************
+** TTokenListToken is a share token in the Uniswap tokenlist schema. The tokenlist spans all the
+** chains: it is persisted as the `tokenlist` element of the chain 0.
+**************************************************************************************************/
+type TTokenListToken struct {
+	ChainID  uint64 `json:"chainId"`
+	Address  string `json:"address"`
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Decimals uint64 `json:"decimals"`
+	LogoURI  string `json:"logoURI,omitempty"`
+}
+
+type TJsonTokenListStorage struct {
+	TJsonMetadata
+	Tokens map[string]TTokenListToken `json:"tokens"`
+}
+
+var _tokenList *TJsonTokenListStorage
+var _tokenListLock sync.Mutex
+
+/**************************************************************************************************
+** StoreTokenList compares the tokens of the tokenlist with the last published ones, keyed by
+** `<chainID>:<address>`, and bumps the version as the schema asks: the major version when a token
+** is removed, the minor one when a token is added, the patch one when a token changed. The list
+** is only persisted when it changed, LastUpdate being the time of the change. The first list is
+** the version 1.0.0. The metadata of the current list is returned.
+**************************************************************************************************/
+func StoreTokenList(tokens map[string]TTokenListToken) TJsonMetadata {
+	_tokenListLock.Lock()
+	defer _tokenListLock.Unlock()
+
+	if _tokenList == nil {
+		_tokenList = loadTokenList()
+	}
+	if _tokenList.Tokens != nil && reflect.DeepEqual(_tokenList.Tokens, tokens) {
+		return _tokenList.TJsonMetadata
+	}
+
+	version := TVersion{Major: 1}
+	if _tokenList.Tokens != nil {
+		version = detectStrVersionUpdate(0, _tokenList.Version, _tokenList.Tokens, tokens)
+		for key := range _tokenList.Tokens {
+			if _, ok := tokens[key]; !ok {
+				// A token was replaced by another one, the count alone does not tell it
+				version = TVersion{Major: _tokenList.Version.Major + 1}
+				break
+			}
+		}
+	}
+	_tokenList = &TJsonTokenListStorage{
+		TJsonMetadata: TJsonMetadata{LastUpdate: time.Now().UTC(), Version: version},
+		Tokens:        tokens,
+	}
+
+	writeElement(`tokenlist`, 0, _tokenList)
+	return _tokenList.TJsonMetadata
+}
+
+/**************************************************************************************************
+** GetTokenList returns the metadata and the tokens of the last stored tokenlist, without changing
+** it. The tokens are nil before the first refresh.
+**************************************************************************************************/
+func GetTokenList() (TJsonMetadata, map[string]TTokenListToken) {
+	_tokenListLock.Lock()
+	defer _tokenListLock.Unlock()
+
+	if _tokenList == nil {
+		_tokenList = loadTokenList()
+	}
+	return _tokenList.TJsonMetadata, _tokenList.Tokens
+}
+
+func loadTokenList() *TJsonTokenListStorage {
+	tokenList := &TJsonTokenListStorage{}
+	if !readElement(`tokenlist`, 0, tokenList) {
+		return &TJsonTokenListStorage{}
+	}
+	return tokenList
+}
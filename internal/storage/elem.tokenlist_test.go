@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/**************************************************************************************************
+** TestStoreTokenList tests that the version of the tokenlist starts at 1.0.0 and is bumped as the
+** tokenlist schema asks: patch for a changed token, minor for an added one, major for a removed
+** or replaced one, and not at all when nothing changed.
+**************************************************************************************************/
+func TestStoreTokenList(t *testing.T) {
+	_storageBackendOnce.Do(func() {
+		_storageBackend = newMemoryBackend()
+	})
+	_tokenList = &TJsonTokenListStorage{}
+
+	yvUSDC := TTokenListToken{ChainID: 1, Address: `0x1`, Name: `USDC yVault`, Symbol: `yvUSDC`, Decimals: 6}
+	yvDAI := TTokenListToken{ChainID: 1, Address: `0x2`, Name: `DAI yVault`, Symbol: `yvDAI`, Decimals: 18}
+	yvWETH := TTokenListToken{ChainID: 1, Address: `0x3`, Name: `WETH yVault`, Symbol: `yvWETH`, Decimals: 18}
+
+	metadata := StoreTokenList(map[string]TTokenListToken{`1:0x1`: yvUSDC})
+	assert.Equal(t, TVersion{Major: 1}, metadata.Version)
+
+	metadata = StoreTokenList(map[string]TTokenListToken{`1:0x1`: yvUSDC})
+	assert.Equal(t, TVersion{Major: 1}, metadata.Version)
+
+	yvUSDC.Name = `USDC yVault v3`
+	metadata = StoreTokenList(map[string]TTokenListToken{`1:0x1`: yvUSDC})
+	assert.Equal(t, TVersion{Major: 1, Patch: 1}, metadata.Version)
+
+	metadata = StoreTokenList(map[string]TTokenListToken{`1:0x1`: yvUSDC, `1:0x2`: yvDAI})
+	assert.Equal(t, TVersion{Major: 1, Minor: 1}, metadata.Version)
+
+	metadata = StoreTokenList(map[string]TTokenListToken{`1:0x1`: yvUSDC, `1:0x3`: yvWETH})
+	assert.Equal(t, TVersion{Major: 2}, metadata.Version)
+
+	metadata = StoreTokenList(map[string]TTokenListToken{`1:0x1`: yvUSDC})
+	assert.Equal(t, TVersion{Major: 3}, metadata.Version)
+}
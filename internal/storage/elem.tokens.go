@@ -42,8 +42,7 @@ func LoadTokensFromJson(chainID uint64) TJsonERC20Storage {
 	chainIDStr := strconv.FormatUint(chainID, 10)
 
 	// Load the JSON file
-	fileName := env.BASE_DATA_PATH + "/meta/tokens/" + chainIDStr + ".json"
-	file, err := os.Open(env.BASE_DATA_PATH + "/meta/tokens/" + chainIDStr + ".json")
+	file, err := getStorageBackend().Open(`tokens`, chainID)
 	if err != nil {
 		return TJsonERC20Storage{}
 	}
@@ -53,7 +52,7 @@ func LoadTokensFromJson(chainID uint64) TJsonERC20Storage {
 	decoder := json.NewDecoder(file)
 	err = decoder.Decode(&tokens)
 	if err != nil {
-		logs.Error("Failed to decode tokens JSON file " + fileName + ": " + err.Error())
+		logs.Error("Failed to decode tokens JSON file for chain " + chainIDStr + ": " + err.Error())
 		return TJsonERC20Storage{}
 	}
 
@@ -83,7 +82,6 @@ func StoreTokensToJson(chainID uint64, tokens map[common.Address]models.TERC20To
 	mutex.Lock()
 	defer mutex.Unlock()
 
-	chainIDStr := strconv.FormatUint(chainID, 10)
 	previousTokens := LoadTokensFromJson(chainID)
 	version := detectVersionUpdate(chainID, previousTokens.Version, previousTokens.Tokens, tokens)
 
@@ -101,10 +99,7 @@ func StoreTokensToJson(chainID uint64, tokens map[common.Address]models.TERC20To
 	})
 
 	file, _ := json.MarshalIndent(data, "", "\t")
-	if _, err := os.Stat(env.BASE_DATA_PATH + "/meta/tokens"); os.IsNotExist(err) {
-		os.MkdirAll(env.BASE_DATA_PATH+"/meta/tokens", 0755)
-	}
-	err := os.WriteFile(env.BASE_DATA_PATH+"/meta/tokens/"+chainIDStr+".json", file, 0644)
+	err := getStorageBackend().Write(`tokens`, chainID, file)
 	if err != nil {
 		logs.Error("Failed to write vaults JSON file: " + err.Error())
 	}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+/**************************************************************************************************
+** TTreasuryTransfer is a transfer of tokens received by a treasury address: the fees of a vault or
+** a YFI buyback. The value is the one at the price of the token when the transfer was indexed.
+**************************************************************************************************/
+type TTreasuryTransfer struct {
+	ChainID     uint64         `json:"chainID"`
+	Token       common.Address `json:"token"`
+	Symbol      string         `json:"symbol"`
+	From        common.Address `json:"from"`
+	To          common.Address `json:"to"`
+	Balance     string         `json:"balance"`
+	Amount      float64        `json:"amount"`
+	Value       float64        `json:"value"`
+	TxHash      common.Hash    `json:"txHash"`
+	LogIndex    uint           `json:"logIndex"`
+	BlockNumber uint64         `json:"blockNumber"`
+	Timestamp   uint64         `json:"timestamp"`
+}
+
+/**************************************************************************************************
+** TJsonTreasuryStorage holds the transfers received by the treasury of a chain and the next block
+** to scan. It is persisted as the `treasury` element of the chain, for the scan to resume after a
+** restart.
+**************************************************************************************************/
+type TJsonTreasuryStorage struct {
+	NextBlock  uint64              `json:"nextBlock"`
+	FeeInflows []TTreasuryTransfer `json:"feeInflows"`
+	Buybacks   []TTreasuryTransfer `json:"buybacks"`
+}
+
+var _treasuryLock sync.Mutex
+
+/**************************************************************************************************
+** LoadTreasuryTransfers returns the transfers last stored for a chain, or empty ones.
+**************************************************************************************************/
+func LoadTreasuryTransfers(chainID uint64) TJsonTreasuryStorage {
+	_treasuryLock.Lock()
+	defer _treasuryLock.Unlock()
+
+	stored := TJsonTreasuryStorage{}
+	if !readElement(`treasury`, chainID, &stored) {
+		stored = TJsonTreasuryStorage{}
+	}
+	return stored
+}
+
+/**************************************************************************************************
+** StoreTreasuryTransfers persists the transfers received by the treasury of a chain.
+**************************************************************************************************/
+func StoreTreasuryTransfers(chainID uint64, stored TJsonTreasuryStorage) {
+	_treasuryLock.Lock()
+	defer _treasuryLock.Unlock()
+
+	writeElement(`treasury`, chainID, stored)
+}
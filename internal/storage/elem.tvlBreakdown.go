@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+var _tvlBreakdownSyncMap = make(map[uint64]*sync.Map)
+
+/**************************************************************************************************
+** StoreTVLBreakdown stores the decomposition of the TVL of a vault whose underlying asset is an
+** LP token. Each component is the share of a pool constituent held by the vault.
+**************************************************************************************************/
+func StoreTVLBreakdown(chainID uint64, vaultAddress common.Address, breakdown models.TTVLBreakdown) {
+	safeSyncMap(_tvlBreakdownSyncMap, chainID).Store(vaultAddress, breakdown)
+}
+
+/**************************************************************************************************
+** DeleteTVLBreakdown removes the decomposition of the TVL of a vault, for the vaults that can no
+** longer be decomposed, so a stale one is not served.
+**************************************************************************************************/
+func DeleteTVLBreakdown(chainID uint64, vaultAddress common.Address) {
+	safeSyncMap(_tvlBreakdownSyncMap, chainID).Delete(vaultAddress)
+}
+
+/**************************************************************************************************
+** GetTVLBreakdown returns the decomposition of the TVL of a vault, if any.
+**************************************************************************************************/
+func GetTVLBreakdown(chainID uint64, vaultAddress common.Address) (models.TTVLBreakdown, bool) {
+	breakdown, ok := safeSyncMap(_tvlBreakdownSyncMap, chainID).Load(vaultAddress)
+	if !ok {
+		return models.TTVLBreakdown{}, false
+	}
+	return breakdown.(models.TTVLBreakdown), true
+}
@@ -20,6 +20,7 @@ var _vaultsSyncMap = make(map[uint64]*sync.Map)
 var _vaultJSONMetadataSyncMap = sync.Map{}
 var _vaultJSONMutexes = make(map[uint64]*sync.RWMutex)
 var _vaultJSONMutexesLock sync.Mutex // Protects access to _vaultJSONMutexes map
+var _vaultsWriteLock sync.Mutex      // Serializes the writes of the vaults with the read-modify-writes
 
 /** 🔵 - Yearn *************************************************************************************
 ** getVaultMutex safely gets or creates a mutex for a specific chainID
@@ -43,7 +44,7 @@ func loadVaultsFromJson(chainID uint64) TJsonVaultStorage {
 	chainIDStr := strconv.FormatUint(chainID, 10)
 
 	// Load the JSON file
-	file, err := os.Open(env.BASE_DATA_PATH + "/meta/vaults/" + chainIDStr + ".json")
+	file, err := getStorageBackend().Open(`vaults`, chainID)
 	if err != nil {
 		return TJsonVaultStorage{}
 	}
@@ -80,7 +81,6 @@ func StoreVaultsToJson(chainID uint64, vaults map[common.Address]models.TVault)
 	mutex.Lock()
 	defer mutex.Unlock()
 
-	chainIDStr := strconv.FormatUint(chainID, 10)
 	previousVaults := loadVaultsFromJson(chainID)
 	version := detectVersionUpdate(chainID, previousVaults.Version, previousVaults.Vaults, vaults)
 
@@ -108,10 +108,7 @@ func StoreVaultsToJson(chainID uint64, vaults map[common.Address]models.TVault)
 		logs.Error("Failed to marshal vaults JSON file: " + err.Error())
 		return
 	}
-	if _, err := os.Stat(env.BASE_DATA_PATH + "/meta/vaults"); os.IsNotExist(err) {
-		os.MkdirAll(env.BASE_DATA_PATH+"/meta/vaults", 0755)
-	}
-	err = os.WriteFile(env.BASE_DATA_PATH+"/meta/vaults/"+chainIDStr+".json", file, 0644)
+	err = getStorageBackend().Write(`vaults`, chainID, file)
 	if err != nil {
 		logs.Error("Failed to write vaults JSON file: " + err.Error())
 	}
@@ -147,6 +144,7 @@ func ApplyCmsVaultMeta(vaultMeta models.TVaultCmsMetadataSchema, vault *models.T
 	vault.Metadata.IsHighlighted = vaultMeta.IsHighlighted
 	vault.Metadata.IsPool = vaultMeta.IsPool
 	vault.Metadata.ShouldUseV2APR = vaultMeta.ShouldUseV2APR
+	vault.Metadata.V2APROverride = vaultMeta.V2APROverride
 
 	// Apply struct fields
 	vault.Metadata.Migration = vaultMeta.Migration
@@ -181,6 +179,12 @@ func ApplyCmsVaultMeta(vaultMeta models.TVaultCmsMetadataSchema, vault *models.T
 	if vaultMeta.UINotice != nil {
 		vault.Metadata.UINotice = *vaultMeta.UINotice
 	}
+	if vaultMeta.ZeroAssetsAPRPolicy != nil {
+		vault.Metadata.ZeroAssetsAPRPolicy = models.TZeroAssetsAPRPolicy(*vaultMeta.ZeroAssetsAPRPolicy)
+	}
+	if vaultMeta.Stage != nil {
+		vault.Metadata.StageOverride = models.TVaultStage(*vaultMeta.Stage)
+	}
 
 	// Apply protocols array (convert TCmsProtocolType to string)
 	if vaultMeta.Protocols != nil {
@@ -289,6 +293,30 @@ func LoadVaults(chainID uint64, wg *sync.WaitGroup) {
 ** StoreVault will add a new vault in the _vaultsSyncMap
 **************************************************************************************************/
 func StoreVault(chainID uint64, vault models.TVault) {
+	_vaultsWriteLock.Lock()
+	defer _vaultsWriteLock.Unlock()
+	storeVault(chainID, vault)
+}
+
+/**************************************************************************************************
+** UpdateVault reads a vault, changes it with update and stores it back, serialized with StoreVault
+** so a concurrent store is not overwritten by a stale copy. The vault is only stored when update
+** returns true. It returns false if the vault is unknown.
+**************************************************************************************************/
+func UpdateVault(chainID uint64, vaultAddress common.Address, update func(vault *models.TVault) bool) bool {
+	_vaultsWriteLock.Lock()
+	defer _vaultsWriteLock.Unlock()
+	vault, ok := GetVault(chainID, vaultAddress)
+	if !ok {
+		return false
+	}
+	if update(&vault) {
+		storeVault(chainID, vault)
+	}
+	return true
+}
+
+func storeVault(chainID uint64, vault models.TVault) {
 	chain, ok := env.GetChain(chainID)
 	if !ok {
 		return
@@ -296,6 +324,9 @@ func StoreVault(chainID uint64, vault models.TVault) {
 	if helpers.Contains(chain.BlacklistedVaults, vault.Address) {
 		return
 	}
+	if previous, ok := safeSyncMap(_vaultsSyncMap, chainID).Load(vault.Address); ok {
+		auditVaultMutations(chainID, previous.(models.TVault), vault)
+	}
 	safeSyncMap(_vaultsSyncMap, chainID).Store(vault.Address, vault)
 }
 
@@ -403,6 +434,9 @@ func GetKongVaultData(chainID uint64, address common.Address) (models.TKongVault
 func StoreKongVaultData(chainID uint64, address common.Address, kongData models.TKongVaultSchema) {
 	// Normalize address to ensure consistent storage (case-insensitive)
 	normalizedAddress := common.HexToAddress(address.Hex())
+	if previous, ok := GetKongVaultData(chainID, normalizedAddress); ok {
+		auditKongMutations(chainID, normalizedAddress, previous, kongData)
+	}
 	safeKongSyncMap(_kongVaultDataSyncMap, chainID).Store(normalizedAddress, kongData)
 }
 
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+/**************************************************************************************************
+** The store version of a chain is bumped every time a snapshot changes the APY, TVL or price of
+** at least one vault. The version is the unix timestamp of the snapshot, so it keeps increasing
+** across restarts. Each vault remembers the version of its last change and the fingerprint of the
+** values it was computed from.
+**************************************************************************************************/
+type tVaultVersion struct {
+	Version     uint64
+	Fingerprint string
+}
+
+var _vaultVersionsSyncMap = make(map[uint64]*sync.Map)
+var _chainVersions = make(map[uint64]uint64)
+var _chainLastSnapshots = make(map[uint64]time.Time)
+var _chainSnapshotBlocks = make(map[uint64]uint64)
+var _chainVersionsLock sync.RWMutex
+
+/**************************************************************************************************
+** StoreVaultsFingerprints compares the fingerprints of the vaults of a chain with the previous
+** ones. If at least one vault changed, the chain version is bumped and assigned to the changed
+** vaults. The time of the snapshot is recorded either way. The current chain version is returned.
+**************************************************************************************************/
+func StoreVaultsFingerprints(chainID uint64, fingerprints map[common.Address]string) uint64 {
+	_chainVersionsLock.Lock()
+	defer _chainVersionsLock.Unlock()
+
+	newVersion := uint64(time.Now().Unix())
+	if newVersion <= _chainVersions[chainID] {
+		newVersion = _chainVersions[chainID] + 1
+	}
+
+	hasChanged := false
+	for address, fingerprint := range fingerprints {
+		previous, ok := safeSyncMap(_vaultVersionsSyncMap, chainID).Load(address)
+		if ok && previous.(tVaultVersion).Fingerprint == fingerprint {
+			continue
+		}
+		safeSyncMap(_vaultVersionsSyncMap, chainID).Store(address, tVaultVersion{
+			Version:     newVersion,
+			Fingerprint: fingerprint,
+		})
+		hasChanged = true
+	}
+	if hasChanged {
+		_chainVersions[chainID] = newVersion
+	}
+	_chainLastSnapshots[chainID] = time.Now()
+	return _chainVersions[chainID]
+}
+
+/**************************************************************************************************
+** GetChainVersion returns the current store version of a chain, 0 if no snapshot was recorded.
+**************************************************************************************************/
+func GetChainVersion(chainID uint64) uint64 {
+	_chainVersionsLock.RLock()
+	defer _chainVersionsLock.RUnlock()
+	return _chainVersions[chainID]
+}
+
+/**************************************************************************************************
+** GetStoreVersion returns the store version of all the chains, the highest of their versions. As
+** every chain version is a timestamp, it increases every time one of them is bumped.
+**************************************************************************************************/
+func GetStoreVersion() uint64 {
+	_chainVersionsLock.RLock()
+	defer _chainVersionsLock.RUnlock()
+	storeVersion := uint64(0)
+	for _, version := range _chainVersions {
+		storeVersion = max(storeVersion, version)
+	}
+	return storeVersion
+}
+
+/**************************************************************************************************
+** ListVaultsChangedSince returns the address of the vaults of a chain changed after a version.
+**************************************************************************************************/
+func ListVaultsChangedSince(chainID uint64, since uint64) []common.Address {
+	changed := []common.Address{}
+	safeSyncMap(_vaultVersionsSyncMap, chainID).Range(func(key, value any) bool {
+		if value.(tVaultVersion).Version > since {
+			changed = append(changed, key.(common.Address))
+		}
+		return true
+	})
+	return changed
+}
+
+/**************************************************************************************************
+** GetVaultVersion returns the store version of the last change of a vault.
+**************************************************************************************************/
+func GetVaultVersion(chainID uint64, vaultAddress common.Address) (uint64, bool) {
+	version, ok := safeSyncMap(_vaultVersionsSyncMap, chainID).Load(vaultAddress)
+	if !ok {
+		return 0, false
+	}
+	return version.(tVaultVersion).Version, true
+}
+
+/**************************************************************************************************
+** GetChainLastSnapshot returns the time of the last snapshot of a chain, the zero time if none was
+** recorded since the start of the daemon.
+**************************************************************************************************/
+func GetChainLastSnapshot(chainID uint64) time.Time {
+	_chainVersionsLock.RLock()
+	defer _chainVersionsLock.RUnlock()
+	return _chainLastSnapshots[chainID]
+}
+
+/**************************************************************************************************
+** StoreChainSnapshotBlock records the block the last snapshot of a chain was published at.
+**************************************************************************************************/
+func StoreChainSnapshotBlock(chainID uint64, blockNumber uint64) {
+	_chainVersionsLock.Lock()
+	defer _chainVersionsLock.Unlock()
+	_chainSnapshotBlocks[chainID] = blockNumber
+}
+
+/**************************************************************************************************
+** GetChainSnapshotBlock returns the block of the last snapshot of a chain, 0 if none was recorded
+** since the start of the daemon.
+**************************************************************************************************/
+func GetChainSnapshotBlock(chainID uint64) uint64 {
+	_chainVersionsLock.RLock()
+	defer _chainVersionsLock.RUnlock()
+	return _chainSnapshotBlocks[chainID]
+}
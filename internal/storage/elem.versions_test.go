@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+/**************************************************************************************************
+** TestStoreVaultsFingerprints replays successive snapshots of a chain and checks that the chain
+** version is only bumped when a fingerprint changed, and that only the changed vaults take it.
+**************************************************************************************************/
+func TestStoreVaultsFingerprints(t *testing.T) {
+	chainID := uint64(31337)
+	first := common.HexToAddress(`0xA1`)
+	second := common.HexToAddress(`0xA2`)
+	third := common.HexToAddress(`0xA3`)
+
+	steps := []struct {
+		name            string
+		fingerprints    map[common.Address]string
+		expectedBump    bool
+		expectedChanged []common.Address
+	}{
+		{
+			name:            "first snapshot",
+			fingerprints:    map[common.Address]string{first: `0.05|0.04|100.00|1`, second: `0.1|-|50.00|2`},
+			expectedBump:    true,
+			expectedChanged: []common.Address{first, second},
+		},
+		{
+			name:         "same snapshot",
+			fingerprints: map[common.Address]string{first: `0.05|0.04|100.00|1`, second: `0.1|-|50.00|2`},
+			expectedBump: false,
+		},
+		{
+			name:            "one vault changed",
+			fingerprints:    map[common.Address]string{first: `0.05|0.04|120.00|1`, second: `0.1|-|50.00|2`},
+			expectedBump:    true,
+			expectedChanged: []common.Address{first},
+		},
+		{
+			name:            "new vault",
+			fingerprints:    map[common.Address]string{first: `0.05|0.04|120.00|1`, second: `0.1|-|50.00|2`, third: `-|-|0.00|1`},
+			expectedBump:    true,
+			expectedChanged: []common.Address{third},
+		},
+		{
+			name:         "empty snapshot",
+			fingerprints: map[common.Address]string{},
+			expectedBump: false,
+		},
+	}
+
+	for _, step := range steps {
+		previousVersion := GetChainVersion(chainID)
+		version := StoreVaultsFingerprints(chainID, step.fingerprints)
+		if version != GetChainVersion(chainID) {
+			t.Errorf("%s: expected the returned version %d to be the chain version %d", step.name, version, GetChainVersion(chainID))
+		}
+		if step.expectedBump && version <= previousVersion {
+			t.Errorf("%s: expected the version to be bumped from %d, got %d", step.name, previousVersion, version)
+		}
+		if !step.expectedBump && version != previousVersion {
+			t.Errorf("%s: expected the version to stay %d, got %d", step.name, previousVersion, version)
+		}
+
+		changed := ListVaultsChangedSince(chainID, previousVersion)
+		if len(changed) != len(step.expectedChanged) {
+			t.Errorf("%s: expected %d changed vaults, got %v", step.name, len(step.expectedChanged), changed)
+		}
+		for _, address := range step.expectedChanged {
+			if vaultVersion, ok := GetVaultVersion(chainID, address); !ok || vaultVersion != version {
+				t.Errorf("%s: expected the vault %s at version %d, got %d", step.name, address.Hex(), version, vaultVersion)
+			}
+		}
+	}
+
+	if _, ok := GetVaultVersion(chainID, common.HexToAddress(`0xA4`)); ok {
+		t.Error("expected no version for an unknown vault")
+	}
+}
@@ -17,7 +17,40 @@ func InitializeStorage() {
 		LoadStrategies(chainID, nil)
 		LoadERC20(chainID, nil)
 		LoadAPY(chainID, nil)
+		LoadFees(chainID, nil)
+		LoadMetrics(chainID, nil)
+		LoadAPYHistory(chainID, nil)
 		LoadPrices(chainID, nil)
+		LoadPinnedAssets(chainID)
 	}
 	logs.Success(`Initialized the store`)
 }
+
+/**************************************************************************************************
+** FlushStorage writes the current state of the store of every chain to its backend. It is called
+** before the process exits for the next start to load the latest state, including the data only
+** persisted at the end of a refresh.
+***************************************************************************************************/
+func FlushStorage() {
+	for chainID := range env.GetChains() {
+		vaults, _ := ListVaults(chainID)
+		if len(vaults) == 0 {
+			continue // Nothing loaded for this chain, keep what the backend has
+		}
+		registries, _ := ListVaultsFromRegistries(chainID)
+		StoreRegistriesToJson(chainID, registries)
+		StoreVaultsToJson(chainID, vaults)
+		strategies, _ := ListStrategies(chainID)
+		StoreStrategiesToJson(chainID, strategies)
+		tokens, _ := ListERC20(chainID)
+		StoreTokensToJson(chainID, tokens)
+		apy, _ := ListAPY(chainID)
+		StoreAPYToJson(chainID, apy)
+		StoreFeesToJson(chainID)
+		StoreMetricsToJson(chainID)
+		StoreAPYHistoryToJson(chainID)
+		prices, _ := ListPrices(chainID)
+		StorePricesToJson(chainID, prices)
+	}
+	logs.Success(`Flushed the store`)
+}
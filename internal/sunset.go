@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+/**************************************************************************************************
+** The chains in sunset mode (see env.IsSunsetChain) are only kept queryable for the withdrawals:
+** their refreshes run every SUNSET_REFRESH_INTERVAL at most, and the stages indexing events since
+** the last run (SUNSET_SKIPPED_STAGES) are skipped, their last data being served as it is. The
+** interval is read when the scheduler of the chain starts.
+**************************************************************************************************/
+const SUNSET_REFRESH_INTERVAL = time.Hour
+
+var SUNSET_SKIPPED_STAGES = []string{`holders`, `entries`, `governance`, `fees`, `losses`, `treasury`}
+
+/**************************************************************************************************
+** refreshInterval returns the interval of a refresh job of a chain, slowed down to
+** SUNSET_REFRESH_INTERVAL on the chains in sunset mode.
+**************************************************************************************************/
+func refreshInterval(chainID uint64, interval time.Duration) time.Duration {
+	if env.IsSunsetChain(chainID) {
+		return max(interval, SUNSET_REFRESH_INTERVAL)
+	}
+	return interval
+}
+
+/**************************************************************************************************
+** skipOnSunsetChain returns true, logging it, when a stage of a chain must be skipped because the
+** chain is in sunset mode.
+**************************************************************************************************/
+func skipOnSunsetChain(chainID uint64, stage string) bool {
+	if !env.IsSunsetChain(chainID) {
+		return false
+	}
+	for _, skipped := range SUNSET_SKIPPED_STAGES {
+		if skipped == stage {
+			logs.Info(fmt.Sprintf("🌅 [SUNSET] stage=%s skipped chain=%d", stage, chainID))
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/internal/fetcher"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/apr"
+)
+
+/**************************************************************************************************
+** OnStoreVersionChanged is called when a snapshot bumps the store version of a chain, with the
+** vaults changed by it, for the caches in front of the daemon to drop their responses.
+**************************************************************************************************/
+var OnStoreVersionChanged func(chainID uint64, version uint64, changedVaults []common.Address)
+
+/**************************************************************************************************
+** formatFingerprintValue formats a value with a limited precision, so the noise of the float
+** computations does not mark a vault as changed.
+**************************************************************************************************/
+func formatFingerprintValue(value *bigNumber.Float) string {
+	if value == nil {
+		return `-`
+	}
+	valueAsFloat, _ := value.Float64()
+	return strconv.FormatFloat(valueAsFloat, 'g', 8, 64)
+}
+
+/**************************************************************************************************
+** recordVaultsVersion computes the fingerprint of the APY, TVL and price of every vault of the
+** chain and stores it, bumping the store version of the vaults that changed since the last
+** snapshot. This powers the diff endpoint used by the clients refreshing often. The block of the
+** snapshot is recorded for the attestations of the responses. When the version is bumped,
+** OnStoreVersionChanged is called with the vaults that changed.
+**************************************************************************************************/
+func recordVaultsVersion(chainID uint64) uint64 {
+	fingerprints := make(map[common.Address]string)
+	_, allVaults := storage.ListVaults(chainID)
+	for _, vault := range allVaults {
+		netAPY, forwardAPY := `-`, `-`
+		if computedAPY, ok := apr.GetComputedAPY(chainID, vault.Address); ok {
+			if vaultAPY, ok := computedAPY.(apr.TVaultAPY); ok {
+				netAPY = formatFingerprintValue(vaultAPY.NetAPY)
+				forwardAPY = formatFingerprintValue(vaultAPY.ForwardAPY.NetAPY)
+			}
+		}
+		tvl := fetcher.BuildVaultTVL(vault)
+		fingerprints[vault.Address] = netAPY + `|` + forwardAPY + `|` +
+			strconv.FormatFloat(tvl.TVL, 'f', 2, 64) + `|` +
+			strconv.FormatFloat(tvl.Price, 'g', 8, 64)
+	}
+	if blockNumber, err := ethereum.GetConfirmedBlockNumber(chainID); err == nil {
+		storage.StoreChainSnapshotBlock(chainID, blockNumber)
+	}
+	previousVersion := storage.GetChainVersion(chainID)
+	version := storage.StoreVaultsFingerprints(chainID, fingerprints)
+	if version != previousVersion && OnStoreVersionChanged != nil {
+		go OnStoreVersionChanged(chainID, version, storage.ListVaultsChangedSince(chainID, previousVersion))
+	}
+	return version
+}
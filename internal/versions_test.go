@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/yearn/ydaemon/common/bigNumber"
+)
+
+/**************************************************************************************************
+** TestFormatFingerprintValue checks that the noise of the float computations does not change the
+** fingerprint of a vault, while a real change does.
+**************************************************************************************************/
+func TestFormatFingerprintValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		first    *bigNumber.Float
+		second   *bigNumber.Float
+		expected bool
+	}{
+		{name: "both unset", first: nil, second: nil, expected: true},
+		{name: "unset and zero", first: nil, second: bigNumber.NewFloat(0), expected: false},
+		{name: "float noise", first: bigNumber.NewFloat(0.1 + 0.2), second: bigNumber.NewFloat(0.3), expected: true},
+		{name: "small apy change", first: bigNumber.NewFloat(0.0512), second: bigNumber.NewFloat(0.0513), expected: false},
+		{name: "large tvl noise", first: bigNumber.NewFloat(123456789.000001), second: bigNumber.NewFloat(123456789.000002), expected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, second := formatFingerprintValue(tt.first), formatFingerprintValue(tt.second)
+			if (first == second) != tt.expected {
+				t.Errorf("expected the fingerprints %q and %q to be equal: %v", first, second, tt.expected)
+			}
+		})
+	}
+}
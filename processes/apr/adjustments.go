@@ -0,0 +1,303 @@
+package apr
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The published APYs are not always the raw output of the oracle. An adjustment is a rule, set in
+** the code, in the config of a chain or in the metadata of a vault, changing them:
+** - Kind is what the rule does (`aprOverride`, `zeroAssetsAPR`, `v2APR`, ...).
+** - Scope is what the rule applies to: `global` (all the vaults, the address is empty), `chain`,
+**   `vault` or `strategy`.
+** - Source is where the rule is set: `code`, `chainConfig`, `vaultMetadata`, `config` (an override
+**   file), `plugin:<name>` or `guard`.
+** - Value is the value applied, when there is one.
+**************************************************************************************************/
+const (
+	ADJUSTMENT_SCOPE_GLOBAL   = `global`
+	ADJUSTMENT_SCOPE_CHAIN    = `chain`
+	ADJUSTMENT_SCOPE_VAULT    = `vault`
+	ADJUSTMENT_SCOPE_STRATEGY = `strategy`
+
+	ADJUSTMENT_SOURCE_CODE           = `code`
+	ADJUSTMENT_SOURCE_CHAIN_CONFIG   = `chainConfig`
+	ADJUSTMENT_SOURCE_VAULT_METADATA = `vaultMetadata`
+	ADJUSTMENT_SOURCE_GUARD          = `guard`
+)
+
+type TAdjustment struct {
+	Kind      string `json:"kind"`
+	ChainID   uint64 `json:"chainID"`
+	Scope     string `json:"scope"`
+	Address   string `json:"address,omitempty"`
+	Source    string `json:"source"`
+	Value     any    `json:"value,omitempty"`
+	Rationale string `json:"rationale"`
+}
+
+/**************************************************************************************************
+** ListAdjustments returns the adjustments currently applied to the APYs published for a chain:
+** the rules of the code applying to all the vaults first, then the ones of the chain, then the
+** ones of its vaults and strategies, sorted by address and kind. The adjustments of the vaults are
+** read from their last computed APY, so only the ones actually applied are listed.
+**************************************************************************************************/
+func ListAdjustments(chainID uint64) []TAdjustment {
+	adjustments := []TAdjustment{
+		{
+			Kind:      `apyGuard`,
+			ChainID:   chainID,
+			Scope:     ADJUSTMENT_SCOPE_GLOBAL,
+			Source:    ADJUSTMENT_SOURCE_CODE,
+			Value:     []float64{MIN_PLAUSIBLE_APY, MAX_PLAUSIBLE_APY},
+			Rationale: `An APY outside of this range is held back and replaced by the last plausible one, being almost always a decimals error`,
+		},
+	}
+
+	chain, ok := env.GetChain(chainID)
+	if ok {
+		adjustments = append(adjustments, TAdjustment{
+			Kind:      `v2APR`,
+			ChainID:   chainID,
+			Scope:     ADJUSTMENT_SCOPE_CHAIN,
+			Source:    ADJUSTMENT_SOURCE_CHAIN_CONFIG,
+			Value:     chain.APRPolicy.ShouldUseV2APR,
+			Rationale: `Whether the APR of the v3 vaults of the chain is derived from their past harvests instead of the oracle`,
+		})
+		for category, shouldUse := range chain.APRPolicy.ShouldUseV2APRByCategory {
+			adjustments = append(adjustments, TAdjustment{
+				Kind:      `v2APR:` + string(category),
+				ChainID:   chainID,
+				Scope:     ADJUSTMENT_SCOPE_CHAIN,
+				Source:    ADJUSTMENT_SOURCE_CHAIN_CONFIG,
+				Value:     shouldUse,
+				Rationale: `Whether the APR of the v3 vaults of the ` + string(category) + ` category is derived from their past harvests instead of the oracle`,
+			})
+		}
+		if chain.Capabilities.ComputesRetiredVaultsAPY {
+			adjustments = append(adjustments, TAdjustment{
+				Kind:      `retiredVaultAPY`,
+				ChainID:   chainID,
+				Scope:     ADJUSTMENT_SCOPE_CHAIN,
+				Source:    ADJUSTMENT_SOURCE_CHAIN_CONFIG,
+				Rationale: `The APY of the retired vaults of the chain is still computed`,
+			})
+		}
+		for _, market := range chain.LendingMarkets {
+			adjustments = append(adjustments, TAdjustment{
+				Kind:      `lendingMarketFallback`,
+				ChainID:   chainID,
+				Scope:     ADJUSTMENT_SCOPE_STRATEGY,
+				Address:   market.StrategyAddress.Hex(),
+				Source:    ADJUSTMENT_SOURCE_CHAIN_CONFIG,
+				Value:     market.Protocol,
+				Rationale: `When the oracle has no APR for the strategy, the APR of its ` + market.Protocol + ` market is used`,
+			})
+		}
+	}
+
+	entityAdjustments := []TAdjustment{}
+	_, allVaults := storage.ListVaults(chainID)
+	for _, vault := range allVaults {
+		entityAdjustments = append(entityAdjustments, listVaultAdjustments(vault)...)
+		if computed, ok := safeSyncMap(COMPUTED_APY, chainID).Load(vault.Address); ok {
+			entityAdjustments = append(entityAdjustments, listAppliedAdjustments(vault, computed.(TVaultAPY))...)
+		}
+	}
+	entityAdjustments = append(entityAdjustments, listAPROverrideAdjustments(chainID)...)
+	for _, quarantined := range ListQuarantinedAPY(chainID) {
+		entityAdjustments = append(entityAdjustments, TAdjustment{
+			Kind:      `quarantinedAPY:` + quarantined.Field,
+			ChainID:   chainID,
+			Scope:     ADJUSTMENT_SCOPE_VAULT,
+			Address:   quarantined.Vault.Hex(),
+			Source:    ADJUSTMENT_SOURCE_GUARD,
+			Value:     quarantined.Value,
+			Rationale: `The computed APY is implausible, the last plausible one is published instead (since ` + quarantined.QuarantinedAt.UTC().Format(`2006-01-02 15:04:05`) + `)`,
+		})
+	}
+	sort.SliceStable(entityAdjustments, func(i, j int) bool {
+		if entityAdjustments[i].Address != entityAdjustments[j].Address {
+			return entityAdjustments[i].Address < entityAdjustments[j].Address
+		}
+		return entityAdjustments[i].Kind < entityAdjustments[j].Kind
+	})
+	return append(adjustments, entityAdjustments...)
+}
+
+/**************************************************************************************************
+** listVaultAdjustments returns the adjustments set for a vault, in the code or in its metadata.
+** The zero-assets policy is only listed while the vault has no assets, being unused otherwise.
+**************************************************************************************************/
+func listVaultAdjustments(vault models.TVault) []TAdjustment {
+	adjustments := []TAdjustment{}
+	newAdjustment := func(kind string, source string, value any, rationale string) TAdjustment {
+		return TAdjustment{
+			Kind:      kind,
+			ChainID:   vault.ChainID,
+			Scope:     ADJUSTMENT_SCOPE_VAULT,
+			Address:   vault.Address.Hex(),
+			Source:    source,
+			Value:     value,
+			Rationale: rationale,
+		}
+	}
+
+	if models.IsVaultRetired(vault) && helpers.Contains(RETIRED_VAULTS_WITH_APY, vault.Address) {
+		adjustments = append(adjustments, newAdjustment(`retiredVaultAPY`, ADJUSTMENT_SOURCE_CODE, nil,
+			`The vault is retired but its APY is still computed, the vault being used by Alchemix`))
+	}
+	if isV3Vault(vault) && vault.Metadata.V2APROverride != nil {
+		adjustments = append(adjustments, newAdjustment(`v2APR`, ADJUSTMENT_SOURCE_VAULT_METADATA, *vault.Metadata.V2APROverride,
+			`Whether the APR of the vault is derived from its past harvests instead of the oracle, in place of the defaults of the chain`))
+	} else if isV3Vault(vault) && vault.Metadata.ShouldUseV2APR {
+		adjustments = append(adjustments, newAdjustment(`v2APR`, ADJUSTMENT_SOURCE_VAULT_METADATA, true,
+			`The APR of the vault is derived from its past harvests instead of the oracle`))
+	}
+	if isV3Vault(vault) && (vault.LastTotalAssets == nil || vault.LastTotalAssets.IsZero()) {
+		policy := getZeroAssetsAPRPolicy(vault)
+		rationale := `The vault has no assets, the oracle returns 0%: the APR of its queued strategies is displayed, `
+		switch policy {
+		case models.ZeroAssetsAPRPolicyAverage:
+			rationale += `averaged`
+		case models.ZeroAssetsAPRPolicyNone:
+			rationale = `The vault has no assets, the 0% of the oracle is displayed`
+		default:
+			rationale += `weighted by their target debt ratio`
+		}
+		adjustments = append(adjustments, newAdjustment(`zeroAssetsAPR`, ADJUSTMENT_SOURCE_VAULT_METADATA, string(policy), rationale))
+	}
+	return adjustments
+}
+
+/**************************************************************************************************
+** listAppliedAdjustments returns the adjustments applied to the last computed APY of a vault, on
+** top of the APR of its sources: the idle funds, the composition of a meta-vault, the fees of the
+** external vaults, the amortized harvest costs and the dYFI emissions of its veYFI gauge.
+**************************************************************************************************/
+func listAppliedAdjustments(vault models.TVault, vaultAPY TVaultAPY) []TAdjustment {
+	adjustments := []TAdjustment{}
+	newAdjustment := func(kind string, value any, rationale string) TAdjustment {
+		return TAdjustment{
+			Kind:      kind,
+			ChainID:   vault.ChainID,
+			Scope:     ADJUSTMENT_SCOPE_VAULT,
+			Address:   vault.Address.Hex(),
+			Source:    ADJUSTMENT_SOURCE_CODE,
+			Value:     value,
+			Rationale: rationale,
+		}
+	}
+
+	forwardAPY := vaultAPY.ForwardAPY
+	if forwardAPY.IdleRatio != nil && !forwardAPY.IdleRatio.IsZero() && !forwardAPYAccountsForIdle(forwardAPY) {
+		idleRatio, _ := forwardAPY.IdleRatio.Float64()
+		adjustments = append(adjustments, newAdjustment(`idleRatio`, idleRatio,
+			`The APR of the strategies only applies to the allocated funds, the APY is scaled down by the idle part of the vault`))
+	}
+	if forwardAPY.PrimarySource == models.APRPrimarySourceMetaVault {
+		adjustments = append(adjustments, newAdjustment(`metaVault`, nil,
+			`The vault allocates to other vaults, its APR is composed from the forward APRs of the nested vaults`))
+	}
+	if vaultAPY.EntryExitFeeBps > 0 {
+		adjustments = append(adjustments, newAdjustment(`entryExitFees`, vaultAPY.EntryExitFeeBps,
+			`The entry and exit fees of the external vaults used by the strategies are amortized over a year`))
+	}
+	if vaultAPY.GasImpact != nil && vaultAPY.GasImpact.GasDragAPY != nil {
+		gasDragAPY, _ := vaultAPY.GasImpact.GasDragAPY.Float64()
+		adjustments = append(adjustments, newAdjustment(`gasImpact`, gasDragAPY,
+			`The cost of the harvests is a meaningful part of the yield of the vault, the APY net of it is published along with the gross one`))
+	}
+	if forwardAPY.Composite.EmissionsMinBoostAPR != nil && forwardAPY.Composite.EmissionsMaxBoostAPR != nil {
+		minAPR, _ := forwardAPY.Composite.EmissionsMinBoostAPR.Float64()
+		maxAPR, _ := forwardAPY.Composite.EmissionsMaxBoostAPR.Float64()
+		adjustments = append(adjustments, newAdjustment(`dYFIEmissions`, []float64{minAPR, maxAPR},
+			`The vault earns the dYFI emitted by its veYFI gauge, from no boost to the max boost`))
+	}
+	return adjustments
+}
+
+/**************************************************************************************************
+** listAPROverrideAdjustments returns the APR overrides of a chain: the ones pinned in its override
+** file, then the plugins registered for it. The APR of a plugin is only known once computed, so it
+** has no value.
+**************************************************************************************************/
+func listAPROverrideAdjustments(chainID uint64) []TAdjustment {
+	adjustments := []TAdjustment{}
+	strategyAPROverridesMtx.RLock()
+	defer strategyAPROverridesMtx.RUnlock()
+
+	for address, override := range strategyAPROverrides[chainID] {
+		rationale := override.Reason
+		if rationale == `` {
+			rationale = `APR pinned by an operator`
+		}
+		adjustments = append(adjustments, TAdjustment{
+			Kind:      `aprOverride`,
+			ChainID:   chainID,
+			Scope:     getOverrideScope(chainID, address),
+			Address:   common.HexToAddress(address).Hex(),
+			Source:    APR_OVERRIDE_SOURCE_CONFIG,
+			Value:     override.APR,
+			Rationale: rationale,
+		})
+	}
+	for address, plugin := range strategyAPRPlugins[chainID] {
+		if _, ok := strategyAPROverrides[chainID][address]; ok {
+			continue
+		}
+		adjustments = append(adjustments, TAdjustment{
+			Kind:      `aprOverride`,
+			ChainID:   chainID,
+			Scope:     getOverrideScope(chainID, address),
+			Address:   common.HexToAddress(address).Hex(),
+			Source:    APR_OVERRIDE_SOURCE_PLUGIN + `:` + plugin.name,
+			Rationale: `APR computed by the ` + plugin.name + ` plugin in place of the oracle`,
+		})
+	}
+	return adjustments
+}
+
+/**************************************************************************************************
+** getOverrideScope tells whether an overridden address of a chain is a vault or a strategy.
+**************************************************************************************************/
+func getOverrideScope(chainID uint64, address string) string {
+	if _, ok := storage.GetVault(chainID, common.HexToAddress(address)); ok {
+		return ADJUSTMENT_SCOPE_VAULT
+	}
+	return ADJUSTMENT_SCOPE_STRATEGY
+}
+
+/**************************************************************************************************
+** ListAllAdjustments returns the adjustments of all the supported chains, sorted by chain.
+**************************************************************************************************/
+func ListAllAdjustments() []TAdjustment {
+	adjustments := []TAdjustment{}
+	chainIDs := append([]uint64{}, env.SUPPORTED_CHAIN_IDS...)
+	sort.Slice(chainIDs, func(i, j int) bool { return chainIDs[i] < chainIDs[j] })
+	for _, chainID := range chainIDs {
+		adjustments = append(adjustments, ListAdjustments(chainID)...)
+	}
+	return adjustments
+}
+
+/**************************************************************************************************
+** FilterAdjustments keeps the adjustments applying to an address, the global and chain ones
+** included as they apply to every vault.
+**************************************************************************************************/
+func FilterAdjustments(adjustments []TAdjustment, address string) []TAdjustment {
+	filtered := []TAdjustment{}
+	for _, adjustment := range adjustments {
+		if adjustment.Address == `` || strings.EqualFold(adjustment.Address, address) {
+			filtered = append(filtered, adjustment)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,126 @@
+package apr
+
+import (
+	"math"
+	"time"
+
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The net APY exposed for a vault is based on the monthly net yield from Kong. The fee impact
+** is computed over the same window so the gross and net values describe the same period.
+**************************************************************************************************/
+const feeImpactWindow = 30 * 24 * time.Hour
+
+/**************************************************************************************************
+** computeTimeWeightedFees returns the performance and management fees (as fractions) that were
+** effective on average over the last `window`, based on the fee history of the vault.
+** Each snapshot is effective from its timestamp until the next one, the last one until now. If the
+** history starts after the beginning of the window, the fees of the oldest snapshot are used for
+** the part before it. The current fees are only used when the history is empty.
+**************************************************************************************************/
+func computeTimeWeightedFees(
+	history []models.TFeeSnapshot,
+	currentPerformanceFee uint64,
+	currentManagementFee uint64,
+	window time.Duration,
+	now time.Time,
+) (float64, float64) {
+	windowStart := uint64(now.Add(-window).Unix())
+	windowEnd := uint64(now.Unix())
+	if len(history) == 0 || windowEnd <= windowStart {
+		return float64(currentPerformanceFee) / 10000, float64(currentManagementFee) / 10000
+	}
+
+	weightedPerformance := 0.0
+	weightedManagement := 0.0
+	covered := uint64(0)
+	for i, snapshot := range history {
+		from := snapshot.Timestamp
+		to := windowEnd
+		if i+1 < len(history) {
+			to = history[i+1].Timestamp
+		}
+		if from < windowStart {
+			from = windowStart
+		}
+		if to > windowEnd {
+			to = windowEnd
+		}
+		if to <= from {
+			continue
+		}
+		duration := to - from
+		covered += duration
+		weightedPerformance += float64(snapshot.PerformanceFee) * float64(duration)
+		weightedManagement += float64(snapshot.ManagementFee) * float64(duration)
+	}
+
+	/**********************************************************************************************
+	** The history starts at the first indexed fee event of the vault. The part of the window before
+	** the first change is assumed to have used the oldest known fees.
+	**********************************************************************************************/
+	total := windowEnd - windowStart
+	if covered < total {
+		uncovered := total - covered
+		weightedPerformance += float64(history[0].PerformanceFee) * float64(uncovered)
+		weightedManagement += float64(history[0].ManagementFee) * float64(uncovered)
+	}
+
+	return weightedPerformance / float64(total) / 10000, weightedManagement / float64(total) / 10000
+}
+
+/**************************************************************************************************
+** convertFloatAPYToAPR converts an APY expressed as a fraction (0.05 = 5%) to the equivalent APR
+** compounded `periodsPerYear` times a year.
+**************************************************************************************************/
+func convertFloatAPYToAPR(apy float64, periodsPerYear float64) float64 {
+	if apy <= -1 {
+		return 0
+	}
+	return periodsPerYear * (math.Pow(1+apy, 1/periodsPerYear) - 1)
+}
+
+/**************************************************************************************************
+** computeFeeImpact derives the gross APR of a vault from its net APY and its time-weighted fees.
+** Performance fees are taken on the gross gains and management fees on the assets, so:
+**   net = gross * (1 - performanceFee) - managementFee
+**   gross = (net + managementFee) / (1 - performanceFee)
+** The fee drag is the difference between the gross and the net APR.
+**************************************************************************************************/
+func computeFeeImpact(vault models.TVault, netAPY *bigNumber.Float) TFeeImpact {
+	if netAPY == nil {
+		return TFeeImpact{
+			GrossAPR:   bigNumber.NewFloat(0),
+			NetAPR:     bigNumber.NewFloat(0),
+			FeeDragAPR: bigNumber.NewFloat(0),
+		}
+	}
+
+	performanceFee, managementFee := computeTimeWeightedFees(
+		storage.ListFeeHistory(vault.ChainID, vault.Address),
+		vault.PerformanceFee,
+		vault.ManagementFee,
+		feeImpactWindow,
+		timeNow(),
+	)
+
+	netAPYFloat, _ := netAPY.Float64()
+	netAPR := convertFloatAPYToAPR(netAPYFloat, 52)
+	grossAPR := netAPR
+	if performanceFee < 1 {
+		grossAPR = (netAPR + managementFee) / (1 - performanceFee)
+	}
+	if grossAPR < netAPR {
+		grossAPR = netAPR
+	}
+
+	return TFeeImpact{
+		GrossAPR:   bigNumber.NewFloat(grossAPR),
+		NetAPR:     bigNumber.NewFloat(netAPR),
+		FeeDragAPR: bigNumber.NewFloat(grossAPR - netAPR),
+	}
+}
@@ -0,0 +1,70 @@
+package apr
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** TestComputeTimeWeightedFees checks that each fee snapshot is weighted by the time it was effective
+** within the window, the oldest snapshot covering the part of the window before the history starts
+** and the current fees being used only without history.
+**************************************************************************************************/
+func TestComputeTimeWeightedFees(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	day := uint64(24 * time.Hour / time.Second)
+	daysAgo := func(days uint64) uint64 {
+		return uint64(now.Unix()) - days*day
+	}
+
+	tests := []struct {
+		name                string
+		history             []models.TFeeSnapshot
+		expectedPerformance float64
+		expectedManagement  float64
+	}{
+		{
+			name:                "no history",
+			expectedPerformance: 0.05,
+			expectedManagement:  0.01,
+		},
+		{
+			name:                "history before the window",
+			history:             []models.TFeeSnapshot{{Timestamp: daysAgo(60), PerformanceFee: 1000, ManagementFee: 200}},
+			expectedPerformance: 0.1,
+			expectedManagement:  0.02,
+		},
+		{
+			name: "change within the window",
+			history: []models.TFeeSnapshot{
+				{Timestamp: daysAgo(60), PerformanceFee: 2000, ManagementFee: 0},
+				{Timestamp: daysAgo(15), PerformanceFee: 1000, ManagementFee: 200},
+			},
+			expectedPerformance: 0.15,
+			expectedManagement:  0.01,
+		},
+		{
+			name: "window starting before the first snapshot",
+			history: []models.TFeeSnapshot{
+				{Timestamp: daysAgo(10), PerformanceFee: 2000, ManagementFee: 300},
+				{Timestamp: daysAgo(5), PerformanceFee: 1000, ManagementFee: 0},
+			},
+			expectedPerformance: (25*0.2 + 5*0.1) / 30,
+			expectedManagement:  25 * 0.03 / 30,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			performance, management := computeTimeWeightedFees(tt.history, 500, 100, 30*24*time.Hour, now)
+			if math.Abs(performance-tt.expectedPerformance) > 1e-12 {
+				t.Errorf("expected a performance fee of %v, got %v", tt.expectedPerformance, performance)
+			}
+			if math.Abs(management-tt.expectedManagement) > 1e-12 {
+				t.Errorf("expected a management fee of %v, got %v", tt.expectedManagement, management)
+			}
+		})
+	}
+}
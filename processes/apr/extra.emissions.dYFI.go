@@ -0,0 +1,102 @@
+package apr
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+var DYFI_ADDRESS = common.HexToAddress(`0x41252E8691e964f7DE35156B68493bAb6797a275`)
+var YFI_ADDRESS = common.HexToAddress(`0x0bc529c00C6401aEF6D220BE8C6Ea1667F6Ad93e`)
+var DYFI_REDEMPTION_ADDRESS = common.HexToAddress(`0x7dC3A74F0684fc026f9163C6D5c3C99fda2cf60a`)
+
+/**************************************************************************************************
+** The veYFI gauges boost the balance of a depositor up to its full balance with the veYFI it holds.
+** Without any veYFI, only DYFI_MIN_BOOST_RATIO of the balance earns the emissions, the rest being
+** redistributed to the veYFI holders.
+**************************************************************************************************/
+const DYFI_MIN_BOOST_RATIO = 0.1
+
+/**************************************************************************************************
+** retrieveDYFIPrice returns the value of a dYFI, which is an option to buy a YFI at the redemption
+** discount: the price of YFI times the discount returned by the redemption contract (`discount()`,
+** 18 decimals). The stored dYFI price is used when the discount cannot be read. The dYFI emissions
+** only exist on Ethereum.
+**************************************************************************************************/
+func retrieveDYFIPrice(chainID uint64) (*bigNumber.Float, bool) {
+	if chainID != 1 {
+		return nil, false
+	}
+
+	calls := []ethereum.Call{multicalls.GetDYFIDiscount(DYFI_REDEMPTION_ADDRESS.Hex(), DYFI_REDEMPTION_ADDRESS)}
+	response := multicalls.Perform(chainID, calls, nil)
+	rawDiscount := response[DYFI_REDEMPTION_ADDRESS.Hex()+`discount`]
+
+	if len(rawDiscount) == 0 {
+		recordAPRSourceError(chainID, APR_SOURCE_DYFI, APR_SOURCE_ERROR_NO_RESULT+` for discount`)
+	} else {
+		discount, _ := helpers.ToNormalizedAmount(helpers.DecodeBigInt(rawDiscount), 18).Float64()
+		recordAPRSourceSuccess(chainID, APR_SOURCE_DYFI, `discount`, discount)
+	}
+
+	yfiPrice, hasYFIPrice := storage.GetPrice(chainID, YFI_ADDRESS)
+	if len(rawDiscount) == 0 || !hasYFIPrice {
+		if dYFIPrice, ok := storage.GetPrice(chainID, DYFI_ADDRESS); ok {
+			return dYFIPrice.HumanizedPrice, true
+		}
+		return nil, false
+	}
+
+	discount := helpers.ToNormalizedAmount(helpers.DecodeBigInt(rawDiscount), 18)
+	return bigNumber.NewFloat(0).Mul(yfiPrice.HumanizedPrice, discount), true
+}
+
+/**************************************************************************************************
+** computeDYFIGaugeEmissionsAPR returns the APR of the dYFI emitted by the veYFI gauge of a vault,
+** for a depositor without any veYFI (min boost) and for a depositor with the max boost:
+**     maxAPR = rewardRate / totalSupply * secondsPerYear * dYFIPrice / vaultPrice
+**     minAPR = maxAPR * DYFI_MIN_BOOST_RATIO
+** It returns false when the vault has no gauge or the gauge is not emitting.
+**************************************************************************************************/
+func computeDYFIGaugeEmissionsAPR(chainID uint64, vault models.TVault, dYFIPrice *bigNumber.Float) (*bigNumber.Float, *bigNumber.Float, bool) {
+	gauge, ok := storage.GetVeYFIStakingForVault(chainID, vault.Address)
+	if !ok {
+		return nil, nil, false
+	}
+	vaultToken, ok := storage.GetERC20(chainID, vault.Address)
+	if !ok {
+		return nil, nil, false
+	}
+	vaultPrice, ok := storage.GetPrice(chainID, vault.Address)
+	if !ok || vaultPrice.HumanizedPrice.IsZero() {
+		return nil, nil, false
+	}
+
+	calls := []ethereum.Call{
+		multicalls.GetPeriodFinish(gauge.StakingAddress.Hex(), gauge.StakingAddress),
+		multicalls.GetRewardRate(gauge.StakingAddress.Hex(), gauge.StakingAddress),
+		multicalls.GetTotalSupply(gauge.StakingAddress.Hex(), gauge.StakingAddress),
+	}
+	response := multicalls.Perform(chainID, calls, nil)
+	periodFinish := helpers.DecodeBigInt(response[gauge.StakingAddress.Hex()+`periodFinish`])
+	rewardRateRaw := helpers.DecodeBigInt(response[gauge.StakingAddress.Hex()+`rewardRate`])
+	totalSupplyRaw := helpers.DecodeBigInt(response[gauge.StakingAddress.Hex()+`totalSupply`])
+	if periodFinish.Int64() < timeNow().Unix() || totalSupplyRaw.IsZero() {
+		return nil, nil, false
+	}
+
+	rewardRate := helpers.ToNormalizedAmount(rewardRateRaw, veYFIGaugeRewardRateDecimals(gauge.StakingAddress, vault))
+	totalSupply := helpers.ToNormalizedAmount(totalSupplyRaw, vaultToken.Decimals)
+	secondsPerYear := bigNumber.NewFloat(31_556_952)
+
+	maxAPR := bigNumber.NewFloat(0).Div(rewardRate, totalSupply)
+	maxAPR = bigNumber.NewFloat(0).Mul(maxAPR, secondsPerYear)
+	maxAPR = bigNumber.NewFloat(0).Mul(maxAPR, dYFIPrice)
+	maxAPR = bigNumber.NewFloat(0).Div(maxAPR, vaultPrice.HumanizedPrice)
+	minAPR := bigNumber.NewFloat(0).Mul(maxAPR, bigNumber.NewFloat(DYFI_MIN_BOOST_RATIO))
+	return minAPR, maxAPR, true
+}
@@ -1,8 +1,6 @@
 package apr
 
 import (
-	"time"
-
 	"github.com/yearn/ydaemon/common/bigNumber"
 	"github.com/yearn/ydaemon/common/ethereum"
 	"github.com/yearn/ydaemon/common/helpers"
@@ -42,7 +40,7 @@ func computeOPBoostStakingRewardsAPY(chainID uint64, vault models.TVault) (*bigN
 	/**********************************************************************************************
 	** If periodFinish is before now, aka rewards are over, we can stop here
 	**********************************************************************************************/
-	now := time.Now().Unix()
+	now := timeNow().Unix()
 	if rewardPeriodFinish < uint64(now) {
 		storage.AssignOPStakingRewardAPY(chainID, vault.Address, rewardsToken, bigNumber.NewFloat(0))
 		return bigNumber.NewFloat(0), bigNumber.NewFloat(0), false
@@ -1,8 +1,6 @@
 package apr
 
 import (
-	"time"
-
 	"github.com/yearn/ydaemon/common/bigNumber"
 	"github.com/yearn/ydaemon/common/ethereum"
 	"github.com/yearn/ydaemon/common/helpers"
@@ -58,7 +56,7 @@ func computeV3StakingRewardsAPY(chainID uint64, vault models.TVault) (*bigNumber
 		/**********************************************************************************************
 		** If periodFinish is before now, aka rewards are over, we can stop here
 		**********************************************************************************************/
-		now := time.Now().Unix()
+		now := timeNow().Unix()
 		if rewardPeriodFinish < uint64(now) {
 			storage.AssignV3StakingRewardAPY(chainID, vault.Address, rewardToken.Address, bigNumber.NewFloat(0))
 			continue
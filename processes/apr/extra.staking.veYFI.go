@@ -2,7 +2,6 @@ package apr
 
 import (
 	"strings"
-	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/yearn/ydaemon/common/addresses"
@@ -42,11 +41,15 @@ func computeVeYFIGaugeStakingRewardsAPY(chainID uint64, vault models.TVault) (*b
 	rewardRateRaw := helpers.DecodeBigInt(response[stakingContract.StakingAddress.Hex()+`rewardRate`])
 	totalSupplyRaw := helpers.DecodeBigInt(response[stakingContract.StakingAddress.Hex()+`totalSupply`])
 	rewardToken := common.HexToAddress(`0x41252E8691e964f7DE35156B68493bAb6797a275`) // DYFI
+	if len(response[stakingContract.StakingAddress.Hex()+`periodFinish`]) == 0 {
+		recordAPRSourceError(chainID, APR_SOURCE_VEYFI_GAUGES, APR_SOURCE_ERROR_NO_RESULT+` for `+stakingContract.StakingAddress.Hex())
+		return bigNumber.NewFloat(0), bigNumber.NewFloat(0), false
+	}
 
 	/**********************************************************************************************
 	** If periodFinish is before now, aka rewards are over, we can stop here
 	**********************************************************************************************/
-	now := time.Now().Unix()
+	now := timeNow().Unix()
 	if periodFinish.Int64() < now {
 		return bigNumber.NewFloat(0), bigNumber.NewFloat(0), false
 	}
@@ -99,32 +102,7 @@ func computeVeYFIGaugeStakingRewardsAPY(chainID uint64, vault models.TVault) (*b
 		rewardsPrice = tokenPrice.HumanizedPrice
 	}
 
-	/**********************************************************************************************
-	** Then, we need to scale the decimals of the rewardRate and the totalSupply to match the
-	** decimals of the vault.
-	** Two special cases:
-	** - For all v3 vaults, we should use 36 decimals
-	**********************************************************************************************/
-	vaultVersionMajor := strings.Split(vault.Version, `.`)[0]
-	if addresses.Equals(stakingContract.StakingAddress, `0x622fA41799406B120f9a40dA843D358b7b2CFEE3`) {
-		rewardsTokenDecimals = 36
-	} else if vaultVersionMajor == `3` {
-		rewardsTokenDecimals = 36
-	} else if addresses.Equals(stakingContract.StakingAddress, `0x7Fd8Af959B54A677a1D8F92265Bd0714274C56a3`) {
-		rewardsTokenDecimals = 18
-	} else if addresses.Equals(stakingContract.StakingAddress, `0x81d93531720d86f0491DeE7D03f30b3b5aC24e59`) {
-		rewardsTokenDecimals = 18
-	} else if addresses.Equals(stakingContract.StakingAddress, `0xB61F8fff8Dd8C438E0d61C07b5536cE3d728f660`) {
-		rewardsTokenDecimals = 36
-	} else if addresses.Equals(stakingContract.StakingAddress, `0x28da6dE3e804bDdF0aD237CFA6048f2930D0b4Dc`) {
-		rewardsTokenDecimals = 18
-	} else if addresses.Equals(stakingContract.StakingAddress, `0x6130E6cD924a40b24703407F246966D7435D4998`) {
-		rewardsTokenDecimals = 18
-	} else if addresses.Equals(stakingContract.StakingAddress, `0x107717C98C8125A94D3d2Cc82b86a1b705f3A27C`) {
-		rewardsTokenDecimals = 18
-	} else {
-		rewardsTokenDecimals = 36
-	}
+	rewardsTokenDecimals = veYFIGaugeRewardRateDecimals(stakingContract.StakingAddress, vault)
 
 	rewardRate := helpers.ToNormalizedAmount(rewardRateRaw, rewardsTokenDecimals)
 	totalSupply := helpers.ToNormalizedAmount(totalSupplyRaw, vaultToken.Decimals)
@@ -141,5 +119,33 @@ func computeVeYFIGaugeStakingRewardsAPY(chainID uint64, vault models.TVault) (*b
 	stakingRewardAPY := bigNumber.NewFloat(0).SetFloat64(convertFloatAPRToAPY(stakingRewardAPRFloat64, 365/15))
 
 	storage.AssignVEYFIStakingRewardAPY(chainID, vault.Address, rewardToken, stakingRewardAPY)
+	recordAPRSourceSuccess(chainID, APR_SOURCE_VEYFI_GAUGES, stakingContract.StakingAddress.Hex(), stakingRewardAPRFloat64)
 	return stakingRewardAPR, stakingRewardAPY, true
 }
+
+/**************************************************************************************************
+** veYFIGaugeRewardRateDecimals returns the decimals of the rewardRate of a veYFI gauge. The rate is
+** scaled by 1e18 on top of the dYFI decimals on all the v3 vaults and most of the gauges, except a
+** few v2 gauges with an unscaled rate.
+**************************************************************************************************/
+func veYFIGaugeRewardRateDecimals(stakingAddress common.Address, vault models.TVault) uint64 {
+	vaultVersionMajor := strings.Split(vault.Version, `.`)[0]
+	if addresses.Equals(stakingAddress, `0x622fA41799406B120f9a40dA843D358b7b2CFEE3`) {
+		return 36
+	} else if vaultVersionMajor == `3` {
+		return 36
+	} else if addresses.Equals(stakingAddress, `0x7Fd8Af959B54A677a1D8F92265Bd0714274C56a3`) {
+		return 18
+	} else if addresses.Equals(stakingAddress, `0x81d93531720d86f0491DeE7D03f30b3b5aC24e59`) {
+		return 18
+	} else if addresses.Equals(stakingAddress, `0xB61F8fff8Dd8C438E0d61C07b5536cE3d728f660`) {
+		return 36
+	} else if addresses.Equals(stakingAddress, `0x28da6dE3e804bDdF0aD237CFA6048f2930D0b4Dc`) {
+		return 18
+	} else if addresses.Equals(stakingAddress, `0x6130E6cD924a40b24703407F246966D7435D4998`) {
+		return 18
+	} else if addresses.Equals(stakingAddress, `0x107717C98C8125A94D3d2Cc82b86a1b705f3A27C`) {
+		return 18
+	}
+	return 36
+}
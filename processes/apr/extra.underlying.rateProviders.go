@@ -0,0 +1,111 @@
+package apr
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/multicalls"
+)
+
+/**************************************************************************************************
+** The APR oracle and the strategies only measure the yield earned in units of the asset of a
+** vault. When this asset is itself yield-bearing (sDAI, sUSDe, wstETH, ...), the depositors also
+** earn its intrinsic yield, computed from the growth of the rate of its rate provider over the
+** last UNDERLYING_ASSET_APR_PERIOD_DAYS days.
+**************************************************************************************************/
+const UNDERLYING_ASSET_APR_PERIOD_DAYS = 7
+
+var (
+	underlyingAssetAPRs    = make(map[uint64]map[common.Address]float64)
+	underlyingAssetAPRsMtx sync.RWMutex
+)
+
+/**************************************************************************************************
+** computeRateAPR annualizes the growth of a rate between two reads `elapsed` seconds apart. It
+** returns false when one of the rates is missing.
+**************************************************************************************************/
+func computeRateAPR(pastRate *big.Int, currentRate *big.Int, elapsed uint64) (float64, bool) {
+	if pastRate == nil || currentRate == nil || pastRate.Sign() <= 0 || currentRate.Sign() <= 0 || elapsed == 0 {
+		return 0, false
+	}
+	growth, _ := new(big.Float).Quo(new(big.Float).SetInt(currentRate), new(big.Float).SetInt(pastRate)).Float64()
+	return (growth - 1) * float64(lendingMarketSecondsPerYear) / float64(elapsed), true
+}
+
+/**************************************************************************************************
+** retrieveUnderlyingAssetAPRs reads the rate of the yield-bearing tokens of a chain now and
+** UNDERLYING_ASSET_APR_PERIOD_DAYS days ago, in one multicall each, and stores the APR implied by
+** their growth for the vaults using them.
+**************************************************************************************************/
+func retrieveUnderlyingAssetAPRs(chainID uint64) {
+	chain, ok := env.GetChain(chainID)
+	if !ok || len(chain.RateProviders) == 0 {
+		return
+	}
+	pastBlock := ethereum.GetBlockNumberByPeriod(chainID, UNDERLYING_ASSET_APR_PERIOD_DAYS)
+	pastTime := ethereum.GetBlockTime(chainID, pastBlock)
+	if pastBlock == 0 || pastTime == 0 || uint64(timeNow().Unix()) <= pastTime {
+		logs.Warning(`Skipping the underlying asset APRs of chain ` + strconv.FormatUint(chainID, 10) + `: unknown past block`)
+		return
+	}
+
+	calls := []ethereum.Call{}
+	for _, provider := range chain.RateProviders {
+		calls = append(calls, multicalls.GetRateProviderRate(provider.Token.Hex(), provider.Provider, provider.Method))
+	}
+	currentResponse := multicalls.Perform(chainID, calls, nil)
+	pastResponse := multicalls.Perform(chainID, calls, new(big.Int).SetUint64(pastBlock))
+	elapsed := uint64(timeNow().Unix()) - pastTime
+
+	aprs := make(map[common.Address]float64)
+	for _, provider := range chain.RateProviders {
+		key := provider.Token.Hex() + provider.Method
+		currentRate, _ := toBigInt(currentResponse[key])
+		pastRate, _ := toBigInt(pastResponse[key])
+		if rateAPR, ok := computeRateAPR(pastRate, currentRate, elapsed); ok {
+			aprs[provider.Token] = rateAPR
+		}
+	}
+
+	underlyingAssetAPRsMtx.Lock()
+	underlyingAssetAPRs[chainID] = aprs
+	underlyingAssetAPRsMtx.Unlock()
+}
+
+func toBigInt(response []interface{}) (*big.Int, bool) {
+	if len(response) == 0 {
+		return nil, false
+	}
+	value, ok := response[0].(*big.Int)
+	return value, ok
+}
+
+/**************************************************************************************************
+** getUnderlyingAssetAPR returns the intrinsic APR of the asset of a vault, if it is yield-bearing.
+**************************************************************************************************/
+func getUnderlyingAssetAPR(chainID uint64, assetAddress common.Address) (float64, bool) {
+	underlyingAssetAPRsMtx.RLock()
+	defer underlyingAssetAPRsMtx.RUnlock()
+	underlyingAPR, ok := underlyingAssetAPRs[chainID][assetAddress]
+	return underlyingAPR, ok
+}
+
+/**************************************************************************************************
+** computeTotalAPY combines the forward net APY of a vault, earned in units of its asset, with the
+** APY of the asset itself (its APR compounded daily): (1 + netAPY) * (1 + assetAPY) - 1.
+**************************************************************************************************/
+func computeTotalAPY(netAPY *bigNumber.Float, underlyingAPR float64) *bigNumber.Float {
+	if netAPY == nil {
+		return nil
+	}
+	net, _ := netAPY.Float64()
+	underlyingAPY := math.Pow(1+underlyingAPR/365, 365) - 1
+	return bigNumber.NewFloat((1+net)*(1+underlyingAPY) - 1)
+}
@@ -0,0 +1,35 @@
+package apr
+
+import (
+	"github.com/yearn/ydaemon/common/contracts"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/processes/fees"
+)
+
+/**************************************************************************************************
+** getStrategyPerformanceFee returns the performance fee charged by the accountant of a v3 vault on
+** the gains of one of its strategies, as a fraction: the one of the custom config of the strategy
+** when it has one, or the fee of the vault, which is the default config of its accountant.
+**************************************************************************************************/
+func getStrategyPerformanceFee(vault models.TVault, strategy models.TStrategy) float64 {
+	if performanceFee, ok := fees.GetStrategyPerformanceFee(vault.ChainID, vault.Address, strategy.Address); ok {
+		return float64(performanceFee) / 10000
+	}
+	return float64(vault.PerformanceFee) / 10000
+}
+
+/**************************************************************************************************
+** getStrategyNetAPR returns the APR a v3 vault earns from one of its strategies, net of all the
+** fees: the oracle APR, net of the own fee of the strategy, minus the performance fee the vault
+** charges on its gains. An overridden APR is already net of all the fees and is used as it is.
+**************************************************************************************************/
+func getStrategyNetAPR(oracle *contracts.YVaultsV3APROracleCaller, vault models.TVault, strategy models.TStrategy) (float64, bool) {
+	if override, ok := resolveAPROverride(strategy); ok {
+		return override.APR, true
+	}
+	strategyAPR, ok := getStrategyOracleAPR(oracle, strategy)
+	if !ok {
+		return 0, false
+	}
+	return strategyAPR * (1 - getStrategyPerformanceFee(vault, strategy)), true
+}
@@ -0,0 +1,88 @@
+package apr
+
+import (
+	"strings"
+
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/processes/keepers"
+)
+
+/**************************************************************************************************
+** computeForwardCompoundingPeriods refines the FORWARD_APY_COMPOUNDING_PERIODS assumption for the
+** vaults whose strategies are tended between their harvests: a tend reinvests the yield of the
+** strategy, which compounds as often as it is tended. Each strategy counts for its debt ratio,
+** with the tends per year it had over the last 30 days, and the rest of the vault for a weekly
+** harvest. It returns FORWARD_APY_COMPOUNDING_PERIODS when no strategy with debt is tended.
+**************************************************************************************************/
+func computeForwardCompoundingPeriods(vault models.TVault, allStrategiesForVault map[string]models.TStrategy) float64 {
+	periods := 0.0
+	weights := 0.0
+	isTended := false
+	for _, strategy := range allStrategiesForVault {
+		if strategy.IsRetired || strategy.LastDebtRatio == nil || strategy.LastDebtRatio.IsZero() {
+			continue
+		}
+		debtRatio, _ := strategy.LastDebtRatio.Float64()
+		weight := debtRatio / 10000
+		strategyPeriods := float64(FORWARD_APY_COMPOUNDING_PERIODS)
+		if activity, ok := keepers.GetTendActivity(vault.ChainID, strategy.Address); ok {
+			if tendsPerYear := activity.TendsPerDay * 365; tendsPerYear > strategyPeriods {
+				strategyPeriods = tendsPerYear
+				isTended = true
+			}
+		}
+		periods += weight * strategyPeriods
+		weights += weight
+	}
+	if !isTended {
+		return FORWARD_APY_COMPOUNDING_PERIODS
+	}
+	if weights < 1 {
+		periods += (1 - weights) * FORWARD_APY_COMPOUNDING_PERIODS
+	}
+	return periods
+}
+
+/**************************************************************************************************
+** The curve-like sources (curve, convex, frax, prisma, velodrome, aerodrome and gamma) compound
+** their APR every 15 days, CURVE_LIKE_APY_COMPOUNDING_PERIODS times a year. The pendle APY is the
+** implied APY of the market, accruing daily.
+**************************************************************************************************/
+const (
+	CURVE_LIKE_APY_COMPOUNDING_PERIODS = 365 / 15
+	PENDLE_APY_COMPOUNDING_PERIODS     = 365
+)
+
+/**************************************************************************************************
+** getForwardCompoundingPeriods returns the compounding periods per year of a forward APY: the ones
+** set on it, else the ones of its source.
+**************************************************************************************************/
+func getForwardCompoundingPeriods(forwardAPY TForwardAPY) float64 {
+	if forwardAPY.CompoundingPeriods > 0 {
+		return forwardAPY.CompoundingPeriods
+	}
+	sourceType := strings.Fields(forwardAPY.Type)
+	if len(sourceType) == 0 {
+		return FORWARD_APY_COMPOUNDING_PERIODS
+	}
+	switch {
+	case sourceType[0] == `crv`, sourceType[0] == `convex`, sourceType[0] == `frax`, sourceType[0] == `prisma`, sourceType[0] == `gamma`:
+		return CURVE_LIKE_APY_COMPOUNDING_PERIODS
+	case strings.HasPrefix(sourceType[0], `v2:velo`):
+		return CURVE_LIKE_APY_COMPOUNDING_PERIODS
+	case sourceType[0] == `pendle`:
+		return PENDLE_APY_COMPOUNDING_PERIODS
+	}
+	return FORWARD_APY_COMPOUNDING_PERIODS
+}
+
+/**************************************************************************************************
+** exposedCompoundingPeriods returns the periods to set on a forward APY: 0, omitted, for the
+** default FORWARD_APY_COMPOUNDING_PERIODS.
+**************************************************************************************************/
+func exposedCompoundingPeriods(periods float64) float64 {
+	if periods == FORWARD_APY_COMPOUNDING_PERIODS {
+		return 0
+	}
+	return periods
+}
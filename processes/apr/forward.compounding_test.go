@@ -0,0 +1,32 @@
+package apr
+
+import (
+	"math"
+	"testing"
+
+	"github.com/yearn/ydaemon/common/bigNumber"
+)
+
+/**************************************************************************************************
+** TestToForwardNetAPR checks that the net APR is the APR the source compounded into its net APY,
+** with the compounding periods of the source.
+**************************************************************************************************/
+func TestToForwardNetAPR(t *testing.T) {
+	tests := []struct {
+		forwardType string
+		periods     float64
+	}{
+		{`v3:onchainOracle`, FORWARD_APY_COMPOUNDING_PERIODS},
+		{`crv convex`, 365 / 15},
+		{`v2:velo_unpopular`, 365 / 15},
+		{`gamma`, 365 / 15},
+		{`pendle`, 365},
+	}
+	for _, test := range tests {
+		netAPY := convertFloatAPRToAPY(8, test.periods)
+		netAPR, _ := ToForwardNetAPR(TForwardAPY{Type: test.forwardType, NetAPY: bigNumber.NewFloat(netAPY)}).Float64()
+		if math.Abs(netAPR-8) > 1e-9 {
+			t.Errorf("expected a net APR of 8 for %s, got %v", test.forwardType, netAPR)
+		}
+	}
+}
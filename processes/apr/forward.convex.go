@@ -88,6 +88,10 @@ func calculateConvexForwardAPY(args TCalculateConvexAPYDataStruct) TStrategyAPY
 			CvxAPR:     bigNumber.NewFloat(0).Mul(cvxAPR, debtRatio),
 			RewardsAPY: bigNumber.NewFloat(0).Mul(args.rewardAPY, debtRatio),
 			KeepCRV:    keepCrv,
+			Rewards: []TRewardAPR{
+				newRewardAPR(storage.CRV_TOKEN_ADDRESS[chainID], crvAPY, keepCRVRatio, oneMinusPerfFee, debtRatio),
+				newRewardAPR(storage.CVX_TOKEN_ADDRESS[chainID], cvxAPY, oneMinusPerfFee, debtRatio),
+			},
 		},
 	}
 	return apyStruct
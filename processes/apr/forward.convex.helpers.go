@@ -1,10 +1,10 @@
 package apr
 
 import (
+	"errors"
 	"math/big"
 	"os"
 	"strings"
-	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/yearn/ydaemon/common/bigNumber"
@@ -51,7 +51,7 @@ func getConvexRewardAPY(
 	crvRewardContract, _ := contracts.NewCrvRewards(rewardContract.CrvRewards, client)
 	rewardsLength, _ := crvRewardContract.ExtraRewardsLength(nil)
 
-	now := time.Now().Unix()
+	now := timeNow().Unix()
 	totalRewardsAPR := bigNumber.NewFloat(0)
 	if rewardsLength != nil {
 		for i := 0; i < int(rewardsLength.Int64()); i++ {
@@ -78,8 +78,16 @@ func getConvexRewardAPY(
 			totalSupplyInt, _ := virtualRewardsPoolContract.TotalSupply(nil)
 
 			tokenPrice := rewardTokenPrice.HumanizedPrice
-			rewardRate := helpers.ToNormalizedAmount(bigNumber.NewInt(0).Set(rewardRateInt), 18)
-			totalSupply := helpers.ToNormalizedAmount(bigNumber.NewInt(0).Set(totalSupplyInt), 18)
+			normalizedRewardRate, ok := normalizeTokenAmount(chainID, rewardToken, bigNumber.NewInt(0).Set(rewardRateInt), `extra reward rate`)
+			if !ok {
+				continue
+			}
+			rewardRate := normalizedRewardRate.Float()
+			normalizedTotalSupply, ok := normalizeTokenAmount(chainID, rewardContract.Token, bigNumber.NewInt(0).Set(totalSupplyInt), `extra reward supply`)
+			if !ok {
+				continue
+			}
+			totalSupply := normalizedTotalSupply.Float()
 			secondPerYear := bigNumber.NewFloat(0).SetFloat64(31556952)
 
 			rewardAPRTop := bigNumber.NewFloat(0).Mul(rewardRate, secondPerYear)
@@ -178,6 +186,7 @@ func getCVXPoolAPY(
 	}
 	poolInfo, err := cvxBoosterContract.PoolInfo(nil, rewardPID)
 	if err != nil {
+		recordAPRSourceError(chainID, APR_SOURCE_CONVEX, err.Error())
 		return crvAPR, cvxAPR, crvAPY, cvxAPY
 	}
 
@@ -192,14 +201,21 @@ func getCVXPoolAPY(
 	rateResult, err1 := rewardContract.RewardRate(nil)
 	supplyResult, err2 := rewardContract.TotalSupply(nil)
 	if err1 != nil || err2 != nil {
+		recordAPRSourceError(chainID, APR_SOURCE_CONVEX, errors.Join(err1, err2).Error())
 		return crvAPR, cvxAPR, crvAPY, cvxAPY
 	}
 
 	/**********************************************************************************************
 	** Then we should be able to calculate the cvxAPR just like it's done on the CVX subgraph
 	***********************************************************************************************/
-	rate := helpers.ToNormalizedAmount(bigNumber.NewInt(0).Set(rateResult), 18)
-	supply := helpers.ToNormalizedAmount(bigNumber.NewInt(0).Set(supplyResult), 18)
+	normalizedRate, okRate := normalizeTokenAmount(chainID, storage.CRV_TOKEN_ADDRESS[chainID], bigNumber.NewInt(0).Set(rateResult), `CRV reward rate`)
+	normalizedSupply, okSupply := normalizeTokenAmount(chainID, poolInfo.Token, bigNumber.NewInt(0).Set(supplyResult), `reward supply`)
+	if !okRate || !okSupply {
+		recordAPRSourceError(chainID, APR_SOURCE_CONVEX, `unknown decimals for the reward rate or supply`)
+		return crvAPR, cvxAPR, crvAPY, cvxAPY
+	}
+	rate := normalizedRate.Float()
+	supply := normalizedSupply.Float()
 	crvPerUnderlying := bigNumber.NewFloat(0)
 	virtualSupply := bigNumber.NewFloat(0).Mul(supply, virtualPoolPrice)
 
@@ -224,6 +240,7 @@ func getCVXPoolAPY(
 	cvxAPRFloat64, _ := cvxAPR.Float64()
 	crvAPY = bigNumber.NewFloat(0).SetFloat64(convertFloatAPRToAPY(crvAPRFloat64, 365/15))
 	cvxAPY = bigNumber.NewFloat(0).SetFloat64(convertFloatAPRToAPY(cvxAPRFloat64, 365/15))
+	recordAPRSourceSuccess(chainID, APR_SOURCE_CONVEX, strategyAddress.Hex(), crvAPRFloat64+cvxAPRFloat64)
 
 	return crvAPR, cvxAPR, crvAPY, cvxAPY
 }
@@ -75,6 +75,9 @@ func calculateCurveForwardAPY(args TCalculateCurveAPYDataStruct) TStrategyAPY {
 			BaseAPR:    bigNumber.NewFloat(0).Mul(args.baseAPY, debtRatio),
 			RewardsAPY: bigNumber.NewFloat(0).Mul(args.rewardAPY, debtRatio),
 			KeepCRV:    keepCrv,
+			Rewards: []TRewardAPR{
+				newRewardAPR(storage.CRV_TOKEN_ADDRESS[chainID], bigNumber.NewFloat(0).Mul(args.baseAPY, yBoost), keepCRVRatio, oneMinusPerfFee, debtRatio),
+			},
 		},
 	}
 	return apyStruct
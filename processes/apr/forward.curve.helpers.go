@@ -336,6 +336,7 @@ func computeCurveLikeForwardAPY(
 	rewardsAPY := bigNumber.NewFloat(0)
 	keepCRV := bigNumber.NewFloat(0)
 	keepVelo := bigNumber.NewFloat(0)
+	rewards := []TRewardAPR{}
 	for _, strategy := range allStrategiesForVault {
 		if strategy.LastDebtRatio == nil || strategy.LastDebtRatio.IsZero() {
 			continue
@@ -359,6 +360,7 @@ func computeCurveLikeForwardAPY(
 		rewardsAPY = bigNumber.NewFloat(0).Add(rewardsAPY, strategyAPR.Composite.RewardsAPY)
 		keepCRV = bigNumber.NewFloat(0).Add(keepCRV, strategyAPR.Composite.KeepCRV)
 		keepVelo = bigNumber.NewFloat(0).Add(keepVelo, strategyAPR.Composite.KeepVelo)
+		rewards = mergeRewardAPRs(rewards, strategyAPR.Composite.Rewards)
 	}
 
 	return TForwardAPY{
@@ -373,6 +375,7 @@ func computeCurveLikeForwardAPY(
 			RewardsAPY: rewardsAPY,
 			KeepCRV:    keepCRV,
 			KeepVelo:   keepVelo,
+			Rewards:    rewards,
 		},
 	}
 }
@@ -0,0 +1,239 @@
+package apr
+
+import (
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/addresses"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/contracts"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** The entry and exit fees are paid once, when the funds enter and leave the external vault. They
+** are amortized over the expected holding period of the depositors, the yield compounding on the
+** funds left after the fees: over a year, a 50 bps round trip turns a 5% APY into
+** 1.05 * 0.995 - 1 = 4.475%. The holding period is configured per chain, one year by default.
+** The strategies of the v3 vaults are ERC4626 vaults themselves, so their fees are read onchain
+** without configuration, once per entryExitFeesProbeTTL, the fees of a vault rarely changing.
+**************************************************************************************************/
+const defaultEntryExitFeesHoldingPeriod = 365 * 24 * time.Hour
+const entryExitFeesProbeTTL = 24 * time.Hour
+
+type tEntryExitFeesProbe struct {
+	entryFee uint64
+	exitFee  uint64
+	ok       bool
+	probedAt time.Time
+}
+
+var (
+	entryExitFeesProbes    = make(map[uint64]map[common.Address]tEntryExitFeesProbe)
+	entryExitFeesProbesMtx sync.Mutex
+)
+
+/**************************************************************************************************
+** tERC4626Previews are the views of an ERC4626 vault comparing the amounts with and without fees.
+**************************************************************************************************/
+type tERC4626Previews interface {
+	ConvertToShares(opts *bind.CallOpts, assets *big.Int) (*big.Int, error)
+	PreviewDeposit(opts *bind.CallOpts, assets *big.Int) (*big.Int, error)
+	ConvertToAssets(opts *bind.CallOpts, shares *big.Int) (*big.Int, error)
+	PreviewRedeem(opts *bind.CallOpts, shares *big.Int) (*big.Int, error)
+}
+
+/**************************************************************************************************
+** readOnChainEntryExitFeesBps reads the entry and exit fees of an ERC4626 vault. The probed amount
+** is an amount of assets, so it is scaled with the decimals of the asset of the vault, which may
+** differ from the ones of its shares.
+**************************************************************************************************/
+func readOnChainEntryExitFeesBps(chainID uint64, externalVault common.Address) (uint64, uint64, bool) {
+	client := ethereum.GetRPC(chainID)
+	caller, err := contracts.NewERC4626Caller(externalVault, client)
+	if err != nil {
+		return 0, 0, false
+	}
+	asset, err := caller.Asset(nil)
+	if err != nil {
+		return 0, 0, false
+	}
+	assetCaller, err := contracts.NewERC20Caller(asset, client)
+	if err != nil {
+		return 0, 0, false
+	}
+	assetDecimals, err := assetCaller.Decimals(nil)
+	if err != nil {
+		return 0, 0, false
+	}
+	return computeEntryExitFeesBps(caller, assetDecimals)
+}
+
+/**************************************************************************************************
+** computeEntryExitFeesBps compares the previews (fees included) of an ERC4626 vault with its
+** conversions (fees excluded) for 1000 units of its asset.
+**************************************************************************************************/
+func computeEntryExitFeesBps(vault tERC4626Previews, assetDecimals uint8) (uint64, uint64, bool) {
+	amount := new(big.Int).Mul(big.NewInt(1000), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(assetDecimals)), nil))
+
+	toBps := func(withoutFees *big.Int, withFees *big.Int) uint64 {
+		if withoutFees == nil || withFees == nil || withoutFees.Sign() <= 0 || withFees.Cmp(withoutFees) >= 0 {
+			return 0
+		}
+		diff := new(big.Int).Sub(withoutFees, withFees)
+		return new(big.Int).Div(new(big.Int).Mul(diff, big.NewInt(10000)), withoutFees).Uint64()
+	}
+
+	sharesWithoutFees, err := vault.ConvertToShares(nil, amount)
+	if err != nil {
+		return 0, 0, false
+	}
+	sharesWithFees, err := vault.PreviewDeposit(nil, amount)
+	if err != nil {
+		return 0, 0, false
+	}
+	assetsWithoutFees, err := vault.ConvertToAssets(nil, sharesWithoutFees)
+	if err != nil {
+		return 0, 0, false
+	}
+	assetsWithFees, err := vault.PreviewRedeem(nil, sharesWithoutFees)
+	if err != nil {
+		return 0, 0, false
+	}
+	return toBps(sharesWithoutFees, sharesWithFees), toBps(assetsWithoutFees, assetsWithFees), true
+}
+
+/**************************************************************************************************
+** probeEntryExitFeesBps returns the entry and exit fees read onchain for an ERC4626 vault, cached
+** for entryExitFeesProbeTTL, the failures included.
+**************************************************************************************************/
+func probeEntryExitFeesBps(chainID uint64, externalVault common.Address) (uint64, uint64, bool) {
+	entryExitFeesProbesMtx.Lock()
+	probe, ok := entryExitFeesProbes[chainID][externalVault]
+	entryExitFeesProbesMtx.Unlock()
+	if ok && timeNow().Sub(probe.probedAt) < entryExitFeesProbeTTL {
+		return probe.entryFee, probe.exitFee, probe.ok
+	}
+
+	probe = tEntryExitFeesProbe{probedAt: timeNow()}
+	probe.entryFee, probe.exitFee, probe.ok = readOnChainEntryExitFeesBps(chainID, externalVault)
+	entryExitFeesProbesMtx.Lock()
+	if _, ok := entryExitFeesProbes[chainID]; !ok {
+		entryExitFeesProbes[chainID] = make(map[common.Address]tEntryExitFeesProbe)
+	}
+	entryExitFeesProbes[chainID][externalVault] = probe
+	entryExitFeesProbesMtx.Unlock()
+	return probe.entryFee, probe.exitFee, probe.ok
+}
+
+/**************************************************************************************************
+** getStrategyEntryExitFeeBps returns the entry + exit fees, in basis points, of the external
+** vault used by a strategy. The fees registered in the chain configuration are used when the
+** strategy is listed, along with the onchain values of its external vault if it is known, the
+** highest values being kept. The strategies of the v3 vaults being ERC4626 vaults, their own
+** previews are read otherwise, which also covers the external vaults added as strategies. For the
+** other strategies, the ERC4626 vault of their lending market is read when one is known.
+**************************************************************************************************/
+func getStrategyEntryExitFeeBps(chainID uint64, strategyAddress common.Address, isV3 bool) (uint64, bool) {
+	chain, ok := env.GetChain(chainID)
+	if !ok {
+		return 0, false
+	}
+	for _, fee := range chain.ExternalVaultFees {
+		if !addresses.Equals(fee.StrategyAddress, strategyAddress) {
+			continue
+		}
+		entryFee, exitFee := fee.EntryFeeBps, fee.ExitFeeBps
+		if (fee.ExternalVault != common.Address{}) {
+			if onChainEntryFee, onChainExitFee, ok := probeEntryExitFeesBps(chainID, fee.ExternalVault); ok {
+				entryFee = max(entryFee, onChainEntryFee)
+				exitFee = max(exitFee, onChainExitFee)
+			}
+		}
+		return entryFee + exitFee, true
+	}
+	probedVault := strategyAddress
+	if !isV3 {
+		marketAPR, ok := GetLendingMarketAPR(chainID, strategyAddress)
+		if !ok || (marketAPR.Vault == common.Address{}) {
+			return 0, false
+		}
+		probedVault = marketAPR.Vault
+	}
+	entryFee, exitFee, ok := probeEntryExitFeesBps(chainID, probedVault)
+	if !ok || entryFee+exitFee == 0 {
+		return 0, false
+	}
+	return entryFee + exitFee, true
+}
+
+/**************************************************************************************************
+** computeVaultEntryExitFeeBps computes the entry + exit fees, in basis points, paid by a vault
+** through its strategies. Each strategy fee is weighted by its share of the vault debt.
+**************************************************************************************************/
+func computeVaultEntryExitFeeBps(vault models.TVault, allStrategiesForVault map[string]models.TStrategy) uint64 {
+	isV3 := isV3Vault(vault)
+	weightedFees := 0.0
+	totalDebt := 0.0
+	hasFees := false
+	for _, strategy := range allStrategiesForVault {
+		if strategy.LastTotalDebt == nil || strategy.LastTotalDebt.IsZero() {
+			continue
+		}
+		debt, _ := bigNumber.NewFloat(0).SetInt(strategy.LastTotalDebt).Float64()
+		totalDebt += debt
+		if feeBps, ok := getStrategyEntryExitFeeBps(vault.ChainID, strategy.Address, isV3); ok {
+			weightedFees += float64(feeBps) * debt
+			hasFees = true
+		}
+	}
+	if !hasFees || totalDebt == 0 {
+		return 0
+	}
+	return uint64(weightedFees / totalDebt)
+}
+
+/**************************************************************************************************
+** getEntryExitFeesHoldingYears returns the expected holding period of the depositors of a chain,
+** in years, over which the entry and exit fees are amortized.
+**************************************************************************************************/
+func getEntryExitFeesHoldingYears(chainID uint64) float64 {
+	holdingPeriod := defaultEntryExitFeesHoldingPeriod
+	if chain, ok := env.GetChain(chainID); ok && chain.APRPolicy.EntryExitFeesHoldingPeriod > 0 {
+		holdingPeriod = chain.APRPolicy.EntryExitFeesHoldingPeriod
+	}
+	return holdingPeriod.Hours() / (365 * 24)
+}
+
+/**************************************************************************************************
+** applyEntryExitFees removes the entry and exit fees, amortized over the expected holding period
+** of the depositors, from the forward net APY. Over the holding period, the funds compound at the
+** APY once the fees are paid:
+**   net = ((1 + apy)^years * (1 - fee))^(1 / years) - 1
+**************************************************************************************************/
+func applyEntryExitFees(chainID uint64, forwardAPY TForwardAPY, feeBps uint64) TForwardAPY {
+	if feeBps == 0 || forwardAPY.NetAPY == nil {
+		return forwardAPY
+	}
+	netAPY, _ := forwardAPY.NetAPY.Float64()
+	netAPY = amortizeEntryExitFees(netAPY, float64(feeBps)/10000, getEntryExitFeesHoldingYears(chainID))
+	forwardAPY.NetAPY = bigNumber.NewFloat(netAPY)
+	return forwardAPY
+}
+
+/**************************************************************************************************
+** amortizeEntryExitFees returns the yearly yield of the funds compounding at apy for years, once
+** a fee of the funds is paid.
+**************************************************************************************************/
+func amortizeEntryExitFees(apy float64, fee float64, years float64) float64 {
+	if fee >= 1 || apy <= -1 {
+		return -1
+	}
+	return math.Pow(math.Pow(1+apy, years)*(1-fee), 1/years) - 1
+}
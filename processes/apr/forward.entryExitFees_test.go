@@ -0,0 +1,89 @@
+package apr
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+)
+
+func TestAmortizeEntryExitFees(t *testing.T) {
+	if net := amortizeEntryExitFees(0.05, 0.005, 1); math.Abs(net-(1.05*0.995-1)) > 1e-12 {
+		t.Errorf("expected the yield to compound on the funds left after the fees, got %v", net)
+	}
+	if net := amortizeEntryExitFees(0.05, 0, 2); math.Abs(net-0.05) > 1e-12 {
+		t.Errorf("expected no change without fees, got %v", net)
+	}
+	if short, long := amortizeEntryExitFees(0.05, 0.01, 1), amortizeEntryExitFees(0.05, 0.01, 2); long <= short {
+		t.Errorf("expected a longer holding period to lower the yearly cost, got %v and %v", short, long)
+	}
+}
+
+/**************************************************************************************************
+** tFakeERC4626 is an ERC4626 vault whose shares have 6 decimals and whose asset has 18, charging
+** entryFeeBps on the deposits and exitFeeBps on the redeems, rounding down like onchain.
+**************************************************************************************************/
+type tFakeERC4626 struct {
+	entryFeeBps int64
+	exitFeeBps  int64
+}
+
+var sharesToAssets = new(big.Int).Exp(big.NewInt(10), big.NewInt(12), nil)
+
+func (v tFakeERC4626) ConvertToShares(_ *bind.CallOpts, assets *big.Int) (*big.Int, error) {
+	return new(big.Int).Div(assets, sharesToAssets), nil
+}
+func (v tFakeERC4626) PreviewDeposit(opts *bind.CallOpts, assets *big.Int) (*big.Int, error) {
+	shares, _ := v.ConvertToShares(opts, assets)
+	return new(big.Int).Div(new(big.Int).Mul(shares, big.NewInt(10000-v.entryFeeBps)), big.NewInt(10000)), nil
+}
+func (v tFakeERC4626) ConvertToAssets(_ *bind.CallOpts, shares *big.Int) (*big.Int, error) {
+	return new(big.Int).Mul(shares, sharesToAssets), nil
+}
+func (v tFakeERC4626) PreviewRedeem(opts *bind.CallOpts, shares *big.Int) (*big.Int, error) {
+	assets, _ := v.ConvertToAssets(opts, shares)
+	return new(big.Int).Div(new(big.Int).Mul(assets, big.NewInt(10000-v.exitFeeBps)), big.NewInt(10000)), nil
+}
+
+/**************************************************************************************************
+** TestComputeEntryExitFeesBps checks that the fees of a vault whose shares and asset have
+** different decimals are read with an amount of assets scaled by the asset decimals: scaled by
+** the share decimals, the amount rounds to no share and the fees are missed.
+**************************************************************************************************/
+func TestComputeEntryExitFeesBps(t *testing.T) {
+	vault := tFakeERC4626{entryFeeBps: 10, exitFeeBps: 20}
+
+	entryFee, exitFee, ok := computeEntryExitFeesBps(vault, 18)
+	if !ok || entryFee != 10 || exitFee != 20 {
+		t.Errorf("expected fees of 10 and 20 bps with the asset decimals, got %d and %d (%v)", entryFee, exitFee, ok)
+	}
+	if entryFee, exitFee, _ := computeEntryExitFeesBps(vault, 6); entryFee != 0 || exitFee != 0 {
+		t.Errorf("expected the share decimals to miss the fees, got %d and %d", entryFee, exitFee)
+	}
+}
+
+/**************************************************************************************************
+** TestApplyEntryExitFeesHoldingPeriod checks that the fees are amortized over the holding period
+** configured for the chain, one year when none is.
+**************************************************************************************************/
+func TestApplyEntryExitFeesHoldingPeriod(t *testing.T) {
+	const chainID = 1337
+	env.CHAINS[chainID] = env.TChain{ID: chainID}
+	defer delete(env.CHAINS, chainID)
+
+	forwardAPY := TForwardAPY{NetAPY: bigNumber.NewFloat(0.05)}
+	oneYear, _ := applyEntryExitFees(chainID, forwardAPY, 50).NetAPY.Float64()
+	if math.Abs(oneYear-(1.05*0.995-1)) > 1e-9 {
+		t.Errorf("expected the fees to be amortized over one year by default, got %v", oneYear)
+	}
+
+	env.CHAINS[chainID] = env.TChain{ID: chainID, APRPolicy: env.TChainAPRPolicy{EntryExitFeesHoldingPeriod: 2 * 365 * 24 * time.Hour}}
+	twoYears, _ := applyEntryExitFees(chainID, forwardAPY, 50).NetAPY.Float64()
+	if expected := amortizeEntryExitFees(0.05, 0.005, 2); math.Abs(twoYears-expected) > 1e-9 {
+		t.Errorf("expected the fees to be amortized over the configured two years, got %v instead of %v", twoYears, expected)
+	}
+}
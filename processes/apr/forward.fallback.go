@@ -0,0 +1,96 @@
+package apr
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/addresses"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/contracts"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** errNoAPROracle is the error of the strategies read on a chain without APR oracle nor fallback
+** lens: only their overridden or lending market APRs are known.
+**************************************************************************************************/
+var errNoAPROracle = errors.New(`no APR oracle nor fallback lens on this chain`)
+
+/**************************************************************************************************
+** getChainAPROracle returns the contract reading the APRs of the v3 vaults and strategies of a
+** chain: the APR oracle when it is deployed, the fallback lens of the chain otherwise. isFallback
+** is true when the oracle is absent, the vaults then taking the debt ratio path. Without oracle and
+** lens, the returned caller is nil and the strategies only get their overridden or lending market
+** APRs. The boolean ok is false if the chain is unknown or the caller cannot be built.
+**************************************************************************************************/
+func getChainAPROracle(chainID uint64) (oracle *contracts.YVaultsV3APROracleCaller, isFallback bool, ok bool) {
+	chain, ok := env.GetChain(chainID)
+	if !ok {
+		return nil, false, false
+	}
+	oracleContract := chain.APROracleContract.Address
+	if oracleContract == (common.Address{}) {
+		oracleContract = chain.APRFallbackLens.Address
+		isFallback = true
+	}
+	if oracleContract == (common.Address{}) {
+		return nil, true, true
+	}
+	oracle, err := contracts.NewYVaultsV3APROracleCaller(oracleContract, ethereum.GetRPC(chainID))
+	if err != nil {
+		logs.Error(err)
+		return nil, isFallback, false
+	}
+	return oracle, isFallback, true
+}
+
+/**************************************************************************************************
+** computeVaultV3FallbackForwardAPY computes the forward APY of a v3 vault on a chain without APR
+** oracle: the APRs of its strategies, read from the fallback lens of the chain (or from their
+** lending market and overrides), weighted by their debt ratio. A vault without assets gets the
+** potential APR of its strategies, and an override of the vault replaces its APR.
+**************************************************************************************************/
+func computeVaultV3FallbackForwardAPY(
+	oracle *contracts.YVaultsV3APROracleCaller,
+	vault models.TVault,
+	allStrategiesForVault map[string]models.TStrategy,
+) TForwardAPY {
+	compoundingPeriods := computeForwardCompoundingPeriods(vault, allStrategiesForVault)
+	aprOverrides := listVaultAPROverrides(vault, allStrategiesForVault)
+	if len(aprOverrides) > 0 && addresses.Equals(aprOverrides[0].Address, vault.Address) {
+		composite, _ := computeLendingMarketComposite(vault, allStrategiesForVault)
+		composite.APROverrides = aprOverrides
+		return TForwardAPY{
+			Type:               `v3:override`,
+			NetAPY:             bigNumber.NewFloat(0).SetFloat64(convertFloatAPRToAPY(aprOverrides[0].APR, compoundingPeriods)),
+			PrimarySource:      models.APRPrimarySourceOverride,
+			CompoundingPeriods: exposedCompoundingPeriods(compoundingPeriods),
+			Composite:          composite,
+		}
+	}
+
+	debtRatioAPR, ok := computeDebtRatioAPR(oracle, vault, allStrategiesForVault)
+	if !ok && (vault.LastTotalAssets == nil || vault.LastTotalAssets.IsZero()) {
+		debtRatioAPR, ok = computeZeroAssetsPotentialAPR(oracle, vault, allStrategiesForVault)
+	}
+	if !ok {
+		return TForwardAPY{}
+	}
+	debtRatioAPRFloat64, _ := debtRatioAPR.Float64()
+	debtRatioAPY := bigNumber.NewFloat(0).SetFloat64(convertFloatAPRToAPY(debtRatioAPRFloat64, compoundingPeriods))
+
+	composite, _ := computeLendingMarketComposite(vault, allStrategiesForVault)
+	composite.V3OracleStratRatioAPR = debtRatioAPY
+	composite.APROverrides = aprOverrides
+
+	return TForwardAPY{
+		Type:               `v3:debtRatioFallback`,
+		NetAPY:             debtRatioAPY,
+		PrimarySource:      models.APRPrimarySourceDebtRatio,
+		CompoundingPeriods: exposedCompoundingPeriods(compoundingPeriods),
+		Composite:          composite,
+	}
+}
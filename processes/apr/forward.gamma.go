@@ -26,31 +26,45 @@ func isGammaVault(chainID uint64, vault models.TVault) bool {
 }
 
 /**************************************************************************************************
-** For a given gamma strategy, we will calculate the APR based on the cached data we have.
+** getGammaFeesAPR returns the gross APR of the trading fees of the hypervisor of a gamma vault,
+** computed from its positions in its Uniswap v3 pool, or the monthly one of the Gamma API when
+** the pool can't be read. The boolean is false when neither is available.
 **************************************************************************************************/
-func calculateGammaStrategyAPY(
-	vault models.TVault,
-	strategy models.TStrategy,
-) (*bigNumber.Float, *bigNumber.Float) {
+func getGammaFeesAPR(vault models.TVault) (*bigNumber.Float, bool) {
+	feesAPR, ok := computeGammaHypervisorFeesAPR(vault.ChainID, vault.AssetAddress)
+	recordAPRSourceValue(vault.ChainID, APR_SOURCE_GAMMA_FEES, vault.AssetAddress.Hex(), feesAPR)
+	if ok {
+		return feesAPR, true
+	}
+
 	if _, ok := storage.GetCachedGammaMerkl(vault.ChainID); !ok {
 		storage.RefreshGammaCalls(vault.ChainID)
 	}
-
-	debtRatio := helpers.ToNormalizedAmount(strategy.LastDebtRatio, 4)
-	vaultPerformanceFee := helpers.ToNormalizedAmount(bigNumber.NewInt(int64(vault.PerformanceFee)), 4)
-	vaultManagementFee := helpers.ToNormalizedAmount(bigNumber.NewInt(int64(vault.ManagementFee)), 4)
-	oneMinusPerfFee := bigNumber.NewFloat(0).Sub(bigNumber.NewFloat(1), vaultPerformanceFee)
-
 	if _, ok := storage.GetCachedGammaMerkl(vault.ChainID); !ok {
-		return bigNumber.NewFloat(0), bigNumber.NewFloat(0)
+		return bigNumber.NewFloat(0), false
 	}
-
 	gammaAllData, _ := storage.GetCachedGammaAllData(vault.ChainID)
 	data, ok := gammaAllData[vault.AssetAddress.Hex()]
 	if !ok {
-		return bigNumber.NewFloat(0), bigNumber.NewFloat(0)
+		return bigNumber.NewFloat(0), false
 	}
-	grossAPR := bigNumber.NewFloat(data.Returns.Monthly.APR)
+	return bigNumber.NewFloat(data.Returns.Monthly.APR), true
+}
+
+/**************************************************************************************************
+** For a given gamma strategy, we will calculate the APR based on the gross fees APR of the
+** hypervisor.
+**************************************************************************************************/
+func calculateGammaStrategyAPY(
+	vault models.TVault,
+	strategy models.TStrategy,
+	grossAPR *bigNumber.Float,
+) (*bigNumber.Float, *bigNumber.Float) {
+	debtRatio := helpers.ToNormalizedAmount(strategy.LastDebtRatio, 4)
+	vaultPerformanceFee := helpers.ToNormalizedAmount(bigNumber.NewInt(int64(vault.PerformanceFee)), 4)
+	vaultManagementFee := helpers.ToNormalizedAmount(bigNumber.NewInt(int64(vault.ManagementFee)), 4)
+	oneMinusPerfFee := bigNumber.NewFloat(0).Sub(bigNumber.NewFloat(1), vaultPerformanceFee)
+
 	netAPR := bigNumber.NewFloat(0).Mul(grossAPR, oneMinusPerfFee) // grossAPR * (1 - perfFee)
 	if netAPR.Gt(vaultManagementFee) {
 		netAPR = bigNumber.NewFloat(0).Sub(netAPR, vaultManagementFee) // (grossAPR * (1 - perfFee)) - managementFee
@@ -75,7 +89,8 @@ func calculateGammaStrategyAPY(
 }
 
 /**************************************************************************************************
-** For a given gamma vault, we will calculate the APR based on the cached data we have.
+** For a given gamma vault, we will calculate the APR based on the fees APR of its hypervisor. The
+** gross fees APR is detailed as the PoolAPY of the composite.
 **************************************************************************************************/
 func computeGammaForwardAPY(
 	vault models.TVault,
@@ -92,11 +107,16 @@ func computeGammaForwardAPY(
 	keepCRV := bigNumber.NewFloat(0)
 	keepVelo := bigNumber.NewFloat(0)
 
+	grossAPR, ok := getGammaFeesAPR(vault)
+	if ok {
+		poolAPY = grossAPR
+	}
+
 	if len(allStrategiesForVault) == 0 {
 		vaultAsStrategy := models.TStrategy{
 			LastDebtRatio: bigNumber.NewUint64(10000),
 		}
-		_, strategyAPY := calculateGammaStrategyAPY(vault, vaultAsStrategy)
+		_, strategyAPY := calculateGammaStrategyAPY(vault, vaultAsStrategy, grossAPR)
 		TypeOf = `gamma`
 		netAPY = strategyAPY
 	} else {
@@ -105,7 +125,7 @@ func computeGammaForwardAPY(
 				continue
 			}
 
-			_, strategyAPY := calculateGammaStrategyAPY(vault, strategy)
+			_, strategyAPY := calculateGammaStrategyAPY(vault, strategy, grossAPR)
 			TypeOf += strings.TrimSpace(` ` + `gamma`)
 			netAPY = bigNumber.NewFloat(0).Add(netAPY, strategyAPY)
 		}
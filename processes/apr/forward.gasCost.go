@@ -0,0 +1,130 @@
+package apr
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** retrieveL1DataFee returns the L1 data fee, in wei, of a harvest on a rollup, from the fee oracle
+** of the chain. It returns 0 for the chains without L1 data fee.
+**************************************************************************************************/
+func retrieveL1DataFee(chainID uint64, policy env.TChainGasPolicy) *big.Int {
+	switch policy.L1FeeOracle {
+	case env.L1_FEE_ORACLE_OP_STACK:
+		/******************************************************************************************
+		** The calldata is made of non-zero bytes, the most expensive ones, for an upper bound.
+		******************************************************************************************/
+		data := bytes.Repeat([]byte{0xff}, int(policy.L1DataBytes))
+		calls := []ethereum.Call{multicalls.GetOPStackL1Fee(`l1Fee`, policy.L1FeeOracleContract, data)}
+		response := multicalls.Perform(chainID, calls, nil)
+		if values := response[`l1Fee`+`getL1Fee`]; len(values) > 0 {
+			if fee, ok := values[0].(*big.Int); ok {
+				return fee
+			}
+		}
+	case env.L1_FEE_ORACLE_ARBITRUM:
+		calls := []ethereum.Call{multicalls.GetArbitrumPricesInWei(`l1Fee`, policy.L1FeeOracleContract)}
+		response := multicalls.Perform(chainID, calls, nil)
+		if values := response[`l1Fee`+`getPricesInWei`]; len(values) > 1 {
+			if pricePerByte, ok := values[1].(*big.Int); ok {
+				return new(big.Int).Mul(pricePerByte, new(big.Int).SetUint64(policy.L1DataBytes))
+			}
+		}
+	}
+	return big.NewInt(0)
+}
+
+/**************************************************************************************************
+** retrieveHarvestCostUSD estimates the cost, in USD, of the harvest of a strategy on a chain: the
+** execution gas at the current gas price plus the L1 data fee on the rollups, valued at the price
+** of the gas token. The boolean is false if the chain has no gas policy or the cost is unknown.
+**************************************************************************************************/
+func retrieveHarvestCostUSD(chainID uint64) (float64, bool) {
+	chain, ok := env.GetChain(chainID)
+	if !ok || chain.GasPolicy.HarvestGasUnits == 0 {
+		return 0, false
+	}
+	policy := chain.GasPolicy
+
+	gasPrice, err := ethereum.GetRPC(chainID).SuggestGasPrice(context.Background())
+	if err != nil {
+		logs.Warning(`Failed to get the gas price of chain`, chainID, `:`, err)
+		return 0, false
+	}
+	coinPrice, ok := storage.GetPrice(chainID, policy.WrappedCoin)
+	if !ok || coinPrice.HumanizedPrice == nil || coinPrice.HumanizedPrice.IsZero() {
+		return 0, false
+	}
+
+	costInWei := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(policy.HarvestGasUnits))
+	costInWei.Add(costInWei, retrieveL1DataFee(chainID, policy))
+	costInCoin := toNormalizedFloat(costInWei, 18)
+	humanizedPrice, _ := coinPrice.HumanizedPrice.Float64()
+	return costInCoin * humanizedPrice, true
+}
+
+/**************************************************************************************************
+** getVaultTVLUSD returns the TVL of a vault, from its breakdown for the LP tokens and from Kong
+** otherwise.
+**************************************************************************************************/
+func getVaultTVLUSD(vault models.TVault) float64 {
+	if breakdown, ok := storage.GetTVLBreakdown(vault.ChainID, vault.Address); ok && len(breakdown.Components) > 0 {
+		tvl := 0.0
+		for _, component := range breakdown.Components {
+			tvl += component.Value
+		}
+		return tvl
+	}
+	tvl, _ := storage.GetKongTVL(vault.ChainID, vault.Address)
+	return tvl
+}
+
+/**************************************************************************************************
+** computeGasImpact removes the amortized cost of the harvests from the forward APY of a vault with
+** a TVL below the threshold of its chain. Every strategy with debt is expected to be harvested
+** HarvestsPerYear times, a vault without any (e.g. a tokenized strategy) being harvested itself.
+** It returns nil for the vaults above the threshold or without forward APY.
+**************************************************************************************************/
+func computeGasImpact(
+	vault models.TVault,
+	allStrategiesForVault map[string]models.TStrategy,
+	forwardAPY TForwardAPY,
+	harvestCostUSD float64,
+) *TGasImpact {
+	chain, ok := env.GetChain(vault.ChainID)
+	if !ok || forwardAPY.NetAPY == nil {
+		return nil
+	}
+	tvl := getVaultTVLUSD(vault)
+	if tvl <= 0 || tvl >= chain.GasPolicy.TVLThresholdUSD {
+		return nil
+	}
+
+	harvestedStrategies := uint64(0)
+	for _, strategy := range allStrategiesForVault {
+		if strategy.LastTotalDebt != nil && !strategy.LastTotalDebt.IsZero() {
+			harvestedStrategies++
+		}
+	}
+	harvestedStrategies = max(harvestedStrategies, 1)
+
+	harvestsPerYear := harvestedStrategies * chain.GasPolicy.HarvestsPerYear
+	gasDrag := bigNumber.NewFloat(float64(harvestsPerYear) * harvestCostUSD / tvl)
+	return &TGasImpact{
+		GrossAPY:        forwardAPY.NetAPY,
+		NetAPY:          bigNumber.NewFloat(0).Sub(forwardAPY.NetAPY, gasDrag),
+		GasDragAPY:      gasDrag,
+		HarvestCostUSD:  harvestCostUSD,
+		HarvestsPerYear: harvestsPerYear,
+	}
+}
@@ -0,0 +1,191 @@
+package apr
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/patrickmn/go-cache"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+)
+
+var ErrHistoricalAPYNotSupported = errors.New(`the historical forward APY is only available for v3 vaults on chains with an APR oracle`)
+
+/**************************************************************************************************
+** The state at a past block does not change, so the historical forward APY computed for a vault
+** and a block is cached for HISTORICAL_FORWARD_APY_TTL, sparing the archive node the repeated
+** requests. The failures are cached for HISTORICAL_FORWARD_APY_ERROR_TTL only, as they can come
+** from a node temporarily unavailable.
+**************************************************************************************************/
+const HISTORICAL_FORWARD_APY_TTL = 24 * time.Hour
+const HISTORICAL_FORWARD_APY_ERROR_TTL = time.Minute
+
+type tHistoricalForwardAPYResult struct {
+	apy THistoricalForwardAPY
+	err error
+}
+
+var historicalForwardAPYCache = cache.New(HISTORICAL_FORWARD_APY_TTL, time.Hour)
+
+/**************************************************************************************************
+** THistoricalForwardAPY is the forward APY of a v3 vault as implied by the APR oracle and the debt
+** allocation at a past block.
+**************************************************************************************************/
+type THistoricalForwardAPY struct {
+	TForwardAPY
+	BlockNumber uint64 `json:"blockNumber"`
+	IsArchive   bool   `json:"isArchive"`
+}
+
+/**************************************************************************************************
+** ComputeHistoricalForwardAPY re-runs the forward APY computation of a v3 vault at a past block:
+** the APR returned by the oracle for the vault and for each strategy, the default queue of the
+** vault and the debt ratio of each strategy of that queue, from its current debt and the total
+** assets of the vault, are all read at that block, on the archive node of the chain when
+** configured. The fees are the current configs of the accountant. Nothing is stored.
+** The lending market fallback and the zero assets policies rely on current data and are not
+** applied. The results are cached per vault and block.
+**************************************************************************************************/
+func ComputeHistoricalForwardAPY(vault models.TVault, blockNumber uint64) (THistoricalForwardAPY, error) {
+	cacheKey := strconv.FormatUint(vault.ChainID, 10) + `:` + vault.Address.Hex() + `:` + strconv.FormatUint(blockNumber, 10)
+	if cached, ok := historicalForwardAPYCache.Get(cacheKey); ok {
+		result := cached.(tHistoricalForwardAPYResult)
+		return result.apy, result.err
+	}
+	historicalAPY, err := computeHistoricalForwardAPY(vault, blockNumber)
+	ttl := HISTORICAL_FORWARD_APY_TTL
+	if err != nil {
+		ttl = HISTORICAL_FORWARD_APY_ERROR_TTL
+	}
+	historicalForwardAPYCache.Set(cacheKey, tHistoricalForwardAPYResult{historicalAPY, err}, ttl)
+	return historicalAPY, err
+}
+
+/**************************************************************************************************
+** performHistoricalCalls performs the multicalls at the past block. It is a variable so the tests
+** can replay recorded responses instead of reaching a node.
+**************************************************************************************************/
+var performHistoricalCalls = func(chainID uint64, calls []ethereum.Call, blockNumber *big.Int) map[string][]interface{} {
+	if caller, _ := ethereum.GetArchiveMulticall(chainID); caller.Client == nil {
+		return nil
+	}
+	return multicalls.PerformAtPastBlock(chainID, calls, blockNumber)
+}
+
+/**************************************************************************************************
+** computeHistoricalForwardAPY reads the state of the vault at the block and computes its forward
+** APY, see ComputeHistoricalForwardAPY. The state is read in two multicalls: the oracle APR, the
+** total assets and the default queue of the vault first, then the params and the oracle APR of
+** each strategy of that queue.
+**************************************************************************************************/
+func computeHistoricalForwardAPY(vault models.TVault, blockNumber uint64) (THistoricalForwardAPY, error) {
+	chain, ok := env.GetChain(vault.ChainID)
+	if !ok || !isV3Vault(vault) || (chain.APROracleContract.Address == common.Address{}) {
+		return THistoricalForwardAPY{}, ErrHistoricalAPYNotSupported
+	}
+	_, isArchive := ethereum.GetArchiveRPC(vault.ChainID)
+	oracleAddress := chain.APROracleContract.Address
+	block := new(big.Int).SetUint64(blockNumber)
+	vaultKey := vault.Address.Hex()
+
+	/**********************************************************************************************
+	** The oracle APR of the vault at the block. A failure here usually means the node does not
+	** serve that state, or the vault or the oracle did not exist yet.
+	**********************************************************************************************/
+	response := performHistoricalCalls(vault.ChainID, []ethereum.Call{
+		multicalls.GetAPROracleStrategyApr(vaultKey, oracleAddress, vault.Address),
+		multicalls.GetTotalAssets(vaultKey, vault.Address),
+		multicalls.GetDefaultQueue(vaultKey, vault.Address),
+	}, block)
+	if response == nil {
+		return THistoricalForwardAPY{}, errors.New(`the node did not serve the state at this block`)
+	}
+	rawExpected := response[vaultKey+`getStrategyApr`]
+	if len(rawExpected) == 0 {
+		return THistoricalForwardAPY{}, errors.New(`the APR oracle did not return the APR of the vault at this block`)
+	}
+	oracleAPR, _ := helpers.ToNormalizedAmount(helpers.DecodeBigInt(rawExpected), 18).Float64()
+	oracleAPY := bigNumber.NewFloat(convertFloatAPRToAPY(oracleAPR, FORWARD_APY_COMPOUNDING_PERIODS))
+
+	/**********************************************************************************************
+	** The APR of the strategies in the default queue at the block, weighted by their debt ratio
+	** at the block. Like for the current forward APY, the oracle APR of a strategy is already net
+	** of its own fees, only the fee of the vault is charged.
+	**********************************************************************************************/
+	debtRatioAPY := bigNumber.NewFloat(0)
+	totalAssets := helpers.DecodeBigInt(response[vaultKey+`totalAssets`])
+	queue := helpers.DecodeAddresses(response[vaultKey+`get_default_queue`])
+	if totalAssets.Gt(bigNumber.NewInt(0)) && len(queue) > 0 {
+		strategyCalls := []ethereum.Call{}
+		for _, strategyAddress := range queue {
+			strategyKey := strategyAddress.Hex() + `_` + vaultKey
+			strategyCalls = append(strategyCalls, multicalls.GetV3Strategies(strategyKey, vault.Address, strategyAddress, vault.Version))
+			strategyCalls = append(strategyCalls, multicalls.GetAPROracleStrategyApr(strategyKey, oracleAddress, strategyAddress))
+		}
+		strategyResponse := performHistoricalCalls(vault.ChainID, strategyCalls, block)
+
+		weightedAPR := 0.0
+		for _, strategyAddress := range queue {
+			strategyKey := strategyAddress.Hex() + `_` + vaultKey
+			currentDebt := decodeHistoricalCurrentDebt(strategyResponse[strategyKey+`strategies`])
+			strategyExpected := strategyResponse[strategyKey+`getStrategyApr`]
+			if currentDebt == nil || currentDebt.Sign() == 0 || len(strategyExpected) == 0 {
+				continue
+			}
+			strategyAPR, _ := helpers.ToNormalizedAmount(helpers.DecodeBigInt(strategyExpected), 18).Float64()
+			debtRatio, _ := new(big.Float).Quo(new(big.Float).SetInt(currentDebt), new(big.Float).SetInt(&totalAssets.Int)).Float64()
+			strategy := models.TStrategy{ChainID: vault.ChainID, Address: strategyAddress, VaultAddress: vault.Address}
+			weightedAPR += strategyAPR * (1 - getStrategyPerformanceFee(vault, strategy)) * debtRatio
+		}
+		debtRatioAPY = bigNumber.NewFloat(convertFloatAPRToAPY(weightedAPR, FORWARD_APY_COMPOUNDING_PERIODS))
+	}
+
+	primaryAPY := oracleAPY
+	primarySource := models.APRPrimarySourceOracle
+	if shouldUseV2APR(vault) {
+		primaryAPY = debtRatioAPY
+		primarySource = models.APRPrimarySourceDebtRatio
+	}
+	return THistoricalForwardAPY{
+		TForwardAPY: TForwardAPY{
+			Type:          `v3:onchainOracle`,
+			NetAPY:        primaryAPY,
+			NetAPR:        ToForwardNetAPR(TForwardAPY{NetAPY: primaryAPY}),
+			PrimarySource: primarySource,
+			Composite: TCompositeData{
+				V3OracleCurrentAPR:    oracleAPY,
+				V3OracleStratRatioAPR: debtRatioAPY,
+			},
+		},
+		BlockNumber: blockNumber,
+		IsArchive:   isArchive,
+	}, nil
+}
+
+/**************************************************************************************************
+** decodeHistoricalCurrentDebt returns the current debt from the params of a strategy returned by
+** the strategies method of a v3 vault, nil when the call failed.
+**************************************************************************************************/
+func decodeHistoricalCurrentDebt(rawStrategies []interface{}) *big.Int {
+	type typeOfRawStrategies = struct {
+		Activation  *big.Int "json:\"activation\""
+		LastReport  *big.Int "json:\"last_report\""
+		CurrentDebt *big.Int "json:\"current_debt\""
+		MaxDebt     *big.Int "json:\"max_debt\""
+	}
+	if len(rawStrategies) == 0 {
+		return nil
+	}
+	params, ok := rawStrategies[0].(typeOfRawStrategies)
+	if !ok {
+		return nil
+	}
+	return params.CurrentDebt
+}
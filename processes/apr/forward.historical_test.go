@@ -0,0 +1,171 @@
+package apr
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+type tRecordedStrategyParams = struct {
+	Activation  *big.Int "json:\"activation\""
+	LastReport  *big.Int "json:\"last_report\""
+	CurrentDebt *big.Int "json:\"current_debt\""
+	MaxDebt     *big.Int "json:\"max_debt\""
+}
+
+/**************************************************************************************************
+** replayHistoricalCalls returns a performer serving the recorded outputs of the calls, keyed like
+** the multicall responses. The outputs are ABI encoded and decoded again, like the node answers
+** are, and the block of each batch is recorded.
+**************************************************************************************************/
+func replayHistoricalCalls(t *testing.T, recorded map[string][]interface{}, blocks *[]uint64, batches *[][]string) func(uint64, []ethereum.Call, *big.Int) map[string][]interface{} {
+	return func(chainID uint64, calls []ethereum.Call, blockNumber *big.Int) map[string][]interface{} {
+		*blocks = append(*blocks, blockNumber.Uint64())
+		keys := []string{}
+		response := map[string][]interface{}{}
+		for _, call := range calls {
+			key := call.Name + call.Method
+			keys = append(keys, key)
+			values, ok := recorded[key]
+			if !ok {
+				response[key] = nil
+				continue
+			}
+			outputs := call.Abi.Methods[call.Method].Outputs
+			packed, err := outputs.Pack(values...)
+			if err != nil {
+				t.Fatalf("Failed to encode the recorded output of %s: %v", key, err)
+			}
+			unpacked, err := outputs.Unpack(packed)
+			if err != nil {
+				t.Fatalf("Failed to decode the recorded output of %s: %v", key, err)
+			}
+			response[key] = unpacked
+		}
+		*batches = append(*batches, keys)
+		return response
+	}
+}
+
+func withDecimals(value float64) *big.Int {
+	amount, _ := new(big.Float).Mul(big.NewFloat(value), big.NewFloat(1e18)).Int(nil)
+	return amount
+}
+
+/**************************************************************************************************
+** TestComputeHistoricalForwardAPY replays the calls of a vault at a past block: the strategies are
+** the ones of the default queue at that block, not the ones stored today, and every call is made
+** at the requested block.
+**************************************************************************************************/
+func TestComputeHistoricalForwardAPY(t *testing.T) {
+	const chainID = 1337
+	const blockNumber = 19_000_000
+	oracle := common.HexToAddress(`0x00000000000000000000000000000000000000a0`)
+	env.CHAINS[chainID] = env.TChain{ID: chainID, APROracleContract: env.TContractData{Address: oracle}}
+	defer delete(env.CHAINS, chainID)
+
+	vault := models.TVault{
+		ChainID:        chainID,
+		Address:        common.HexToAddress(`0x00000000000000000000000000000000000000b1`),
+		Version:        `3.0.2`,
+		Kind:           models.VaultKindMultiple,
+		PerformanceFee: 1000,
+	}
+	first := common.HexToAddress(`0x00000000000000000000000000000000000000c1`)
+	second := common.HexToAddress(`0x00000000000000000000000000000000000000c2`)
+	idle := common.HexToAddress(`0x00000000000000000000000000000000000000c3`)
+	vaultKey := vault.Address.Hex()
+	strategyKey := func(strategy common.Address) string {
+		return strategy.Hex() + `_` + vaultKey
+	}
+	params := func(currentDebt int64) []interface{} {
+		return []interface{}{tRecordedStrategyParams{
+			Activation:  big.NewInt(1_700_000_000),
+			LastReport:  big.NewInt(1_700_100_000),
+			CurrentDebt: big.NewInt(currentDebt),
+			MaxDebt:     big.NewInt(1_000_000_000_000),
+		}}
+	}
+	recorded := map[string][]interface{}{
+		vaultKey + `getStrategyApr`:            {withDecimals(0.05)},
+		vaultKey + `totalAssets`:               {big.NewInt(1_000_000_000)},
+		vaultKey + `get_default_queue`:         {[]common.Address{first, second, idle}},
+		strategyKey(first) + `strategies`:      params(600_000_000),
+		strategyKey(first) + `getStrategyApr`:  {withDecimals(0.04)},
+		strategyKey(second) + `strategies`:     params(400_000_000),
+		strategyKey(second) + `getStrategyApr`: {withDecimals(0.06)},
+		strategyKey(idle) + `strategies`:       params(0),
+		strategyKey(idle) + `getStrategyApr`:   {withDecimals(0.5)},
+	}
+
+	blocks := []uint64{}
+	batches := [][]string{}
+	originalPerformer := performHistoricalCalls
+	defer func() { performHistoricalCalls = originalPerformer }()
+	performHistoricalCalls = replayHistoricalCalls(t, recorded, &blocks, &batches)
+
+	historicalAPY, err := computeHistoricalForwardAPY(vault, blockNumber)
+	if err != nil {
+		t.Fatalf("Failed to compute the historical forward APY: %v", err)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("Expected the state to be read in 2 multicalls, got %d", len(blocks))
+	}
+	for _, block := range blocks {
+		if block != blockNumber {
+			t.Errorf("Expected the calls to be made at block %d, got %d", blockNumber, block)
+		}
+	}
+	if len(batches[0]) != 3 || batches[0][2] != vaultKey+`get_default_queue` {
+		t.Errorf("Expected the default queue to be read with the vault state, got %v", batches[0])
+	}
+	if len(batches[1]) != 6 {
+		t.Errorf("Expected 2 calls for each of the 3 strategies of the queue, got %v", batches[1])
+	}
+
+	oracleAPY, _ := historicalAPY.Composite.V3OracleCurrentAPR.Float64()
+	if expected := convertFloatAPRToAPY(0.05, FORWARD_APY_COMPOUNDING_PERIODS); math.Abs(oracleAPY-expected) > 1e-9 {
+		t.Errorf("Expected an oracle APY of %v, got %v", expected, oracleAPY)
+	}
+	debtRatioAPY, _ := historicalAPY.Composite.V3OracleStratRatioAPR.Float64()
+	expectedAPR := (0.04*0.6 + 0.06*0.4) * 0.9
+	if expected := convertFloatAPRToAPY(expectedAPR, FORWARD_APY_COMPOUNDING_PERIODS); math.Abs(debtRatioAPY-expected) > 1e-9 {
+		t.Errorf("Expected a debt ratio APY of %v, got %v", expected, debtRatioAPY)
+	}
+	if historicalAPY.BlockNumber != blockNumber {
+		t.Errorf("Expected the block %d, got %d", blockNumber, historicalAPY.BlockNumber)
+	}
+}
+
+/**************************************************************************************************
+** TestComputeHistoricalForwardAPYUnservedBlock checks that a block the node cannot serve, or at
+** which the oracle did not answer for the vault, is an error rather than a zero APY.
+**************************************************************************************************/
+func TestComputeHistoricalForwardAPYUnservedBlock(t *testing.T) {
+	const chainID = 1337
+	oracle := common.HexToAddress(`0x00000000000000000000000000000000000000a0`)
+	env.CHAINS[chainID] = env.TChain{ID: chainID, APROracleContract: env.TContractData{Address: oracle}}
+	defer delete(env.CHAINS, chainID)
+	originalPerformer := performHistoricalCalls
+	defer func() { performHistoricalCalls = originalPerformer }()
+
+	vault := models.TVault{ChainID: chainID, Address: common.HexToAddress(`0xb1`), Version: `3.0.2`}
+	performHistoricalCalls = func(uint64, []ethereum.Call, *big.Int) map[string][]interface{} {
+		return nil
+	}
+	if _, err := computeHistoricalForwardAPY(vault, 1); err == nil {
+		t.Error("Expected an error when the node does not serve the block")
+	}
+
+	blocks := []uint64{}
+	batches := [][]string{}
+	performHistoricalCalls = replayHistoricalCalls(t, map[string][]interface{}{}, &blocks, &batches)
+	if _, err := computeHistoricalForwardAPY(vault, 1); err == nil {
+		t.Error("Expected an error when the oracle does not return the APR of the vault")
+	}
+}
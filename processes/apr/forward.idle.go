@@ -0,0 +1,73 @@
+package apr
+
+import (
+	"strings"
+
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** computeIdleRatio returns the fraction of the total assets of a vault that is not allocated to
+** any strategy. The allocated assets are the sum of the last reported debts of the strategies.
+** The total assets are read from Kong, the source of truth for the vault data.
+** Returns false if the total assets are unknown.
+**************************************************************************************************/
+func computeIdleRatio(vault models.TVault, allStrategiesForVault map[string]models.TStrategy) (float64, bool) {
+	vaultTotalAssets, ok := storage.GetKongTotalAssets(vault.ChainID, vault.Address)
+	if !ok || vaultTotalAssets == nil || vaultTotalAssets.IsZero() {
+		return 0, false
+	}
+	totalDebt := bigNumber.NewInt(0)
+	for _, strategy := range allStrategiesForVault {
+		if strategy.LastTotalDebt == nil {
+			continue
+		}
+		totalDebt = bigNumber.NewInt(0).Add(totalDebt, strategy.LastTotalDebt)
+	}
+
+	totalAssets, _ := bigNumber.NewFloat(0).SetInt(vaultTotalAssets).Float64()
+	deployed, _ := bigNumber.NewFloat(0).SetInt(totalDebt).Float64()
+	if deployed >= totalAssets {
+		return 0, true
+	}
+	return (totalAssets - deployed) / totalAssets, true
+}
+
+/**************************************************************************************************
+** forwardAPYAccountsForIdle tells if a forward APY is already the effective APY of the vault, with
+** its idle funds. The v3 APYs are weighted by the debt of the strategies over the total assets of
+** the vault (the oracle and the debt ratio ones, the fallback included), are the rate of the market
+** the vault lends in, or the APR set for the vault by an override.
+**************************************************************************************************/
+func forwardAPYAccountsForIdle(forwardAPY TForwardAPY) bool {
+	return strings.HasPrefix(forwardAPY.Type, `v3:`)
+}
+
+/**************************************************************************************************
+** applyIdleRatio adjusts the forward APY to the idle funds of the vault. The strategies APRs only
+** apply to the deployed debt:
+** - The APYs already accounting for the idle funds are the effective ones, and the deployed-only
+**   APY is derived from them.
+** - The other estimations are based on the strategies allocations, so they describe the deployed
+**   funds only, and the effective APY is scaled down by the idle ratio.
+**************************************************************************************************/
+func applyIdleRatio(forwardAPY TForwardAPY, idleRatio float64) TForwardAPY {
+	if forwardAPY.NetAPY == nil {
+		return forwardAPY
+	}
+	forwardAPY.IdleRatio = bigNumber.NewFloat(idleRatio)
+
+	if forwardAPYAccountsForIdle(forwardAPY) {
+		forwardAPY.NetAPYDeployedOnly = forwardAPY.NetAPY
+		if idleRatio < 1 {
+			forwardAPY.NetAPYDeployedOnly = bigNumber.NewFloat(0).Quo(forwardAPY.NetAPY, bigNumber.NewFloat(1-idleRatio))
+		}
+		return forwardAPY
+	}
+
+	forwardAPY.NetAPYDeployedOnly = forwardAPY.NetAPY
+	forwardAPY.NetAPY = bigNumber.NewFloat(0).Mul(forwardAPY.NetAPY, bigNumber.NewFloat(1-idleRatio))
+	return forwardAPY
+}
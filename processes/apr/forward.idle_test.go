@@ -0,0 +1,30 @@
+package apr
+
+import (
+	"math"
+	"testing"
+
+	"github.com/yearn/ydaemon/common/bigNumber"
+)
+
+func TestApplyIdleRatio(t *testing.T) {
+	tests := []struct {
+		aprType          string
+		expectedNet      float64
+		expectedDeployed float64
+	}{
+		{aprType: `v3:onchainOracle`, expectedNet: 0.08, expectedDeployed: 0.10},
+		{aprType: `v3:debtRatioFallback`, expectedNet: 0.08, expectedDeployed: 0.10},
+		{aprType: `v3:lendingMarket`, expectedNet: 0.08, expectedDeployed: 0.10},
+		{aprType: `v3:override`, expectedNet: 0.08, expectedDeployed: 0.10},
+		{aprType: `crv`, expectedNet: 0.064, expectedDeployed: 0.08},
+	}
+	for _, test := range tests {
+		forwardAPY := applyIdleRatio(TForwardAPY{Type: test.aprType, NetAPY: bigNumber.NewFloat(0.08)}, 0.2)
+		net, _ := forwardAPY.NetAPY.Float64()
+		deployed, _ := forwardAPY.NetAPYDeployedOnly.Float64()
+		if math.Abs(net-test.expectedNet) > 1e-9 || math.Abs(deployed-test.expectedDeployed) > 1e-9 {
+			t.Errorf("%s: expected %v net and %v deployed, got %v and %v", test.aprType, test.expectedNet, test.expectedDeployed, net, deployed)
+		}
+	}
+}
@@ -0,0 +1,805 @@
+package apr
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/contracts"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The lending market APR sources compute the supply APR of the markets in which some strategies
+** lend their funds (Aave v3, Morpho Blue, Euler v2, Curve LlamaLend) directly from the rate model of the protocol.
+** This APR is compared with the APR oracle, and used in place of the oracle when the oracle has no
+** adapter for the strategy (error or 0%).
+** The rewards distributed off-chain (Merkl, Morpho URD) are not included: only the Aave v3
+** incentives and the CRV rewards of the LlamaLend gauges, readable on-chain, are.
+**************************************************************************************************/
+const lendingMarketSecondsPerYear = 31536000
+const lendingMarketAPRTolerance = 0.25 // Relative deviation from the oracle before a warning
+
+/**************************************************************************************************
+** TLendingMarketAPR is the gross APR of a lending market, before the fees of the strategy. The
+** APRs are expressed as fractions (0.05 = 5%). RewardsAPR contains all the rewards, including the
+** boosted CRV rewards of the LlamaLend gauges. CRVAPR is the unboosted part of these CRV rewards,
+** and Boost the boost of the strategy in the gauge. Vault is the ERC4626 vault of the market for
+** the protocols using one (Euler v2, LlamaLend), zero otherwise.
+**************************************************************************************************/
+type TLendingMarketAPR struct {
+	Protocol   string
+	Vault      common.Address
+	SupplyAPR  float64
+	RewardsAPR float64
+	CRVAPR     float64
+	Boost      float64
+}
+
+var (
+	lendingMarketAPRs    = make(map[uint64]map[common.Address]TLendingMarketAPR)
+	lendingMarketAPRsMtx sync.RWMutex
+)
+
+/**************************************************************************************************
+** toNormalizedFloat converts a raw value returned by a multicall to a float, 0 if the value is
+** not an integer.
+**************************************************************************************************/
+func toNormalizedFloat(value interface{}, decimals uint64) float64 {
+	rawValue, ok := value.(*big.Int)
+	if !ok || rawValue == nil {
+		return 0
+	}
+	normalized, _ := helpers.ToNormalizedAmount(bigNumber.SetInt(rawValue), decimals).Float64()
+	return normalized
+}
+
+/**************************************************************************************************
+** getTokenPrice returns the USD price of a token from the price storage, 0 if unknown.
+**************************************************************************************************/
+func getTokenPrice(chainID uint64, tokenAddress common.Address) float64 {
+	price, ok := storage.GetPrice(chainID, tokenAddress)
+	if !ok || price.HumanizedPrice == nil {
+		return 0
+	}
+	priceFloat, _ := price.HumanizedPrice.Float64()
+	return priceFloat
+}
+
+/**************************************************************************************************
+** computeAaveV3MarketAPRs computes the supply APR of the Aave v3 reserves from their liquidity
+** rate (ray, already annualized) and the APR of the incentives distributed to the aToken holders
+** by the rewards controller.
+**************************************************************************************************/
+func computeAaveV3MarketAPRs(chainID uint64, markets []env.TLendingMarket) map[common.Address]TLendingMarketAPR {
+	result := make(map[common.Address]TLendingMarketAPR)
+	calls := []ethereum.Call{}
+	for _, market := range markets {
+		key := market.StrategyAddress.Hex()
+		calls = append(calls, multicalls.GetAaveV3ReserveData(key, market.Market, market.Asset))
+		calls = append(calls, multicalls.GetAaveV3ReserveTokensAddresses(key, market.Market, market.Asset))
+	}
+	response := multicalls.Perform(chainID, calls, nil)
+
+	aTokens := make(map[common.Address]common.Address)
+	calls = []ethereum.Call{}
+	for _, market := range markets {
+		key := market.StrategyAddress.Hex()
+		reserveData := response[key+`getReserveData`]
+		reserveTokens := response[key+`getReserveTokensAddresses`]
+		if len(reserveData) < 6 || len(reserveTokens) == 0 {
+			continue
+		}
+		result[market.StrategyAddress] = TLendingMarketAPR{
+			Protocol:  env.LENDING_PROTOCOL_AAVE_V3,
+			SupplyAPR: toNormalizedFloat(reserveData[5], 27),
+		}
+		aToken := helpers.DecodeAddress(reserveTokens)
+		aTokens[market.StrategyAddress] = aToken
+		calls = append(calls, multicalls.GetAaveV3IncentivesController(key, aToken))
+		calls = append(calls, multicalls.GetAaveV3ATokenTotalSupply(key, aToken))
+	}
+	if len(calls) == 0 {
+		return result
+	}
+	response = multicalls.Perform(chainID, calls, nil)
+
+	controllers := make(map[common.Address]common.Address)
+	calls = []ethereum.Call{}
+	for strategyAddress, aToken := range aTokens {
+		key := strategyAddress.Hex()
+		controller := helpers.DecodeAddress(response[key+`getIncentivesController`])
+		if (controller == common.Address{}) {
+			continue
+		}
+		controllers[strategyAddress] = controller
+		calls = append(calls, multicalls.GetAaveV3RewardsByAsset(key, controller, aToken))
+	}
+	if len(calls) == 0 {
+		return result
+	}
+	totalSupplies := response
+	response = multicalls.Perform(chainID, calls, nil)
+
+	rewardsByStrategy := make(map[common.Address][]common.Address)
+	calls = []ethereum.Call{}
+	for strategyAddress, controller := range controllers {
+		rewards := helpers.DecodeAddresses(response[strategyAddress.Hex()+`getRewardsByAsset`])
+		rewardsByStrategy[strategyAddress] = rewards
+		for _, reward := range rewards {
+			calls = append(calls, multicalls.GetAaveV3RewardsData(strategyAddress.Hex()+reward.Hex(), controller, aTokens[strategyAddress], reward))
+		}
+	}
+	if len(calls) == 0 {
+		return result
+	}
+	response = multicalls.Perform(chainID, calls, nil)
+
+	now := float64(timeNow().Unix())
+	for _, market := range markets {
+		marketAPR, ok := result[market.StrategyAddress]
+		if !ok {
+			continue
+		}
+		asset, ok := storage.GetERC20(chainID, market.Asset)
+		if !ok {
+			continue
+		}
+		rawTotalSupply := totalSupplies[market.StrategyAddress.Hex()+`totalSupply`]
+		if len(rawTotalSupply) == 0 {
+			continue
+		}
+		suppliedUSD := toNormalizedFloat(rawTotalSupply[0], asset.Decimals) * getTokenPrice(chainID, market.Asset)
+		if suppliedUSD == 0 {
+			continue
+		}
+		for _, reward := range rewardsByStrategy[market.StrategyAddress] {
+			rewardsData := response[market.StrategyAddress.Hex()+reward.Hex()+`getRewardsData`]
+			if len(rewardsData) < 4 || toNormalizedFloat(rewardsData[3], 0) < now {
+				continue // Unknown or finished distribution
+			}
+			rewardToken, ok := storage.GetERC20(chainID, reward)
+			if !ok {
+				continue
+			}
+			emissionPerYear := toNormalizedFloat(rewardsData[1], rewardToken.Decimals) * lendingMarketSecondsPerYear
+			marketAPR.RewardsAPR += emissionPerYear * getTokenPrice(chainID, reward) / suppliedUSD
+		}
+		result[market.StrategyAddress] = marketAPR
+	}
+	return result
+}
+
+/**************************************************************************************************
+** computeMorphoBlueMarketAPRs computes the supply APR of the Morpho Blue markets. The interest
+** rate model gives the borrow rate per second, the suppliers earn it on the borrowed part of the
+** market, minus the market fee:
+** supplyAPR = borrowRate * secondsPerYear * utilization * (1 - fee)
+**************************************************************************************************/
+func computeMorphoBlueMarketAPRs(chainID uint64, markets []env.TLendingMarket) map[common.Address]TLendingMarketAPR {
+	result := make(map[common.Address]TLendingMarketAPR)
+	calls := []ethereum.Call{}
+	for _, market := range markets {
+		key := market.StrategyAddress.Hex()
+		calls = append(calls, multicalls.GetMorphoMarket(key, market.Market, market.MarketID))
+		calls = append(calls, multicalls.GetMorphoMarketParams(key, market.Market, market.MarketID))
+	}
+	response := multicalls.Perform(chainID, calls, nil)
+
+	marketStates := make(map[common.Address]multicalls.TMorphoMarket)
+	calls = []ethereum.Call{}
+	for _, market := range markets {
+		key := market.StrategyAddress.Hex()
+		rawMarket := response[key+`market`]
+		rawParams := response[key+`idToMarketParams`]
+		if len(rawMarket) < 6 || len(rawParams) < 5 {
+			continue
+		}
+		marketState := multicalls.TMorphoMarket{
+			TotalSupplyAssets: rawMarket[0].(*big.Int),
+			TotalSupplyShares: rawMarket[1].(*big.Int),
+			TotalBorrowAssets: rawMarket[2].(*big.Int),
+			TotalBorrowShares: rawMarket[3].(*big.Int),
+			LastUpdate:        rawMarket[4].(*big.Int),
+			Fee:               rawMarket[5].(*big.Int),
+		}
+		marketParams := multicalls.TMorphoMarketParams{
+			LoanToken:       rawParams[0].(common.Address),
+			CollateralToken: rawParams[1].(common.Address),
+			Oracle:          rawParams[2].(common.Address),
+			Irm:             rawParams[3].(common.Address),
+			Lltv:            rawParams[4].(*big.Int),
+		}
+		if (marketParams.Irm == common.Address{}) || marketState.TotalSupplyAssets.Sign() == 0 {
+			continue
+		}
+		marketStates[market.StrategyAddress] = marketState
+		calls = append(calls, multicalls.GetMorphoBorrowRateView(key, marketParams.Irm, marketParams, marketState))
+	}
+	if len(calls) == 0 {
+		return result
+	}
+	response = multicalls.Perform(chainID, calls, nil)
+
+	for strategyAddress, marketState := range marketStates {
+		rawBorrowRate := response[strategyAddress.Hex()+`borrowRateView`]
+		if len(rawBorrowRate) == 0 {
+			continue
+		}
+		borrowAPR := toNormalizedFloat(rawBorrowRate[0], 18) * lendingMarketSecondsPerYear
+		totalBorrow, _ := new(big.Float).SetInt(marketState.TotalBorrowAssets).Float64()
+		totalSupply, _ := new(big.Float).SetInt(marketState.TotalSupplyAssets).Float64()
+		fee := toNormalizedFloat(marketState.Fee, 18)
+		result[strategyAddress] = TLendingMarketAPR{
+			Protocol:  env.LENDING_PROTOCOL_MORPHO_BLUE,
+			SupplyAPR: borrowAPR * (totalBorrow / totalSupply) * (1 - fee),
+		}
+	}
+	return result
+}
+
+/**************************************************************************************************
+** computeEulerV2MarketAPRs computes the supply APR of the Euler v2 vaults. The vault gives the
+** borrow rate per second (ray), the suppliers earn it on the borrowed part of the vault, minus the
+** interest fee (basis points):
+** supplyAPR = interestRate * secondsPerYear * totalBorrows / totalAssets * (1 - interestFee)
+**************************************************************************************************/
+func computeEulerV2MarketAPRs(chainID uint64, markets []env.TLendingMarket) map[common.Address]TLendingMarketAPR {
+	result := make(map[common.Address]TLendingMarketAPR)
+	calls := []ethereum.Call{}
+	for _, market := range markets {
+		key := market.StrategyAddress.Hex()
+		calls = append(calls, multicalls.GetEulerInterestRate(key, market.Market))
+		calls = append(calls, multicalls.GetEulerInterestFee(key, market.Market))
+		calls = append(calls, multicalls.GetEulerTotalBorrows(key, market.Market))
+		calls = append(calls, multicalls.GetEulerTotalAssets(key, market.Market))
+	}
+	response := multicalls.Perform(chainID, calls, nil)
+
+	for _, market := range markets {
+		key := market.StrategyAddress.Hex()
+		rawInterestRate := response[key+`interestRate`]
+		rawInterestFee := response[key+`interestFee`]
+		rawTotalBorrows := response[key+`totalBorrows`]
+		rawTotalAssets := response[key+`totalAssets`]
+		if len(rawInterestRate) == 0 || len(rawInterestFee) == 0 || len(rawTotalBorrows) == 0 || len(rawTotalAssets) == 0 {
+			continue
+		}
+		interestFee, ok := rawInterestFee[0].(uint16)
+		if !ok {
+			continue
+		}
+		totalBorrows := toNormalizedFloat(rawTotalBorrows[0], 0)
+		totalAssets := toNormalizedFloat(rawTotalAssets[0], 0)
+		if totalAssets == 0 {
+			continue
+		}
+		borrowAPR := toNormalizedFloat(rawInterestRate[0], 27) * lendingMarketSecondsPerYear
+		result[market.StrategyAddress] = TLendingMarketAPR{
+			Protocol:  env.LENDING_PROTOCOL_EULER_V2,
+			Vault:     market.Market,
+			SupplyAPR: borrowAPR * (totalBorrows / totalAssets) * (1 - float64(interestFee)/10000),
+		}
+	}
+	return result
+}
+
+/**************************************************************************************************
+** computeLlamaLendMarketAPRs computes the APR of the Curve LlamaLend vaults. The supply APR is
+** given by the vault (`lend_apr`). When the vault shares are staked in a gauge, the CRV rewards
+** are computed like for the Curve gauges:
+** crvAPR = 0.4 * inflationRate * relativeWeight * secondsPerYear * crvPrice
+**          / (workingSupply * sharePrice * assetPrice)
+** and multiplied by the boost of the strategy in the gauge. The gauge weight is only available on
+** the chains with a gauge controller.
+**************************************************************************************************/
+func computeLlamaLendMarketAPRs(chainID uint64, markets []env.TLendingMarket) map[common.Address]TLendingMarketAPR {
+	result := make(map[common.Address]TLendingMarketAPR)
+	gaugeController, hasGaugeController := storage.CURVE_GAUGE_CONTROLLER_ADDRESS[chainID]
+	calls := []ethereum.Call{}
+	for _, market := range markets {
+		key := market.StrategyAddress.Hex()
+		calls = append(calls, multicalls.GetLlamaLendAPR(key, market.Market))
+		calls = append(calls, multicalls.GetAsset(key, market.Market))
+		calls = append(calls, multicalls.GetConvertToAssets(key, market.Market, bigNumber.NewUint64(1e18)))
+		if (market.Gauge != common.Address{}) && hasGaugeController {
+			calls = append(calls, multicalls.GetCurveGaugeInflationRate(key, market.Gauge))
+			calls = append(calls, multicalls.GetCurveGaugeWorkingSupply(key, market.Gauge))
+			calls = append(calls, multicalls.GetCurveGaugeRelativeWeight(key, gaugeController, market.Gauge))
+		}
+	}
+	response := multicalls.Perform(chainID, calls, nil)
+
+	crvPrice := getTokenPrice(chainID, storage.CRV_TOKEN_ADDRESS[chainID])
+	for _, market := range markets {
+		key := market.StrategyAddress.Hex()
+		rawLendAPR := response[key+`lend_apr`]
+		if len(rawLendAPR) == 0 {
+			continue
+		}
+		marketAPR := TLendingMarketAPR{
+			Protocol:  env.LENDING_PROTOCOL_LLAMALEND,
+			Vault:     market.Market,
+			SupplyAPR: toNormalizedFloat(rawLendAPR[0], 18),
+		}
+
+		rawInflationRate := response[key+`inflation_rate`]
+		rawWorkingSupply := response[key+`working_supply`]
+		rawRelativeWeight := response[key+`gauge_relative_weight`]
+		if len(rawInflationRate) > 0 && len(rawWorkingSupply) > 0 && len(rawRelativeWeight) > 0 && crvPrice > 0 {
+			asset, ok := storage.GetERC20(chainID, helpers.DecodeAddress(response[key+`asset`]))
+			rawSharePrice := response[key+`convertToAssets`]
+			workingSupply := toNormalizedFloat(rawWorkingSupply[0], 18)
+			if ok && len(rawSharePrice) > 0 && workingSupply > 0 {
+				sharePrice := toNormalizedFloat(rawSharePrice[0], asset.Decimals)
+				stakedUSD := workingSupply * sharePrice * getTokenPrice(chainID, asset.Address)
+				crvPerYear := toNormalizedFloat(rawInflationRate[0], 18) * toNormalizedFloat(rawRelativeWeight[0], 18) * lendingMarketSecondsPerYear
+				if stakedUSD > 0 {
+					boost, _ := getCurveBoost(chainID, market.StrategyAddress, market.Gauge).Float64()
+					marketAPR.CRVAPR = 0.4 * crvPerYear * crvPrice / stakedUSD
+					marketAPR.Boost = boost
+					marketAPR.RewardsAPR = marketAPR.CRVAPR * boost
+				}
+			}
+		}
+		result[market.StrategyAddress] = marketAPR
+	}
+	return result
+}
+
+/**************************************************************************************************
+** tAaveV3Candidate is a v3 strategy (or a tokenized strategy listed as a vault) that may lend its
+** asset on Aave v3, with the aToken of this asset.
+**************************************************************************************************/
+type tAaveV3Candidate struct {
+	strategy common.Address
+	asset    common.Address
+	aToken   common.Address
+}
+
+/**************************************************************************************************
+** selectAaveV3Markets keeps the candidates holding some aTokens of their asset, as Aave v3 lending
+** markets read through the data provider.
+**************************************************************************************************/
+func selectAaveV3Markets(dataProvider common.Address, candidates []tAaveV3Candidate, balances map[common.Address]*bigNumber.Int) []env.TLendingMarket {
+	markets := []env.TLendingMarket{}
+	for _, candidate := range candidates {
+		if balance, ok := balances[candidate.strategy]; !ok || balance == nil || balance.IsZero() {
+			continue
+		}
+		markets = append(markets, env.TLendingMarket{
+			StrategyAddress: candidate.strategy,
+			Protocol:        env.LENDING_PROTOCOL_AAVE_V3,
+			Market:          dataProvider,
+			Asset:           candidate.asset,
+		})
+	}
+	return markets
+}
+
+/**************************************************************************************************
+** discoverAaveV3LendingMarkets finds the v3 strategies of a chain lending on Aave v3: the aToken of
+** the asset of each v3 vault is read from the data provider of the chain, and the strategies of the
+** vault holding some of it are registered as Aave v3 lending markets.
+**************************************************************************************************/
+func discoverAaveV3LendingMarkets(chainID uint64, dataProvider common.Address) []env.TLendingMarket {
+	_, vaults := storage.ListVaults(chainID)
+	calls := []ethereum.Call{}
+	assets := make(map[common.Address]bool)
+	for _, vault := range vaults {
+		if !isV3Vault(vault) || assets[vault.AssetAddress] {
+			continue
+		}
+		assets[vault.AssetAddress] = true
+		calls = append(calls, multicalls.GetAaveV3ReserveTokensAddresses(vault.AssetAddress.Hex(), dataProvider, vault.AssetAddress))
+	}
+	if len(calls) == 0 {
+		return nil
+	}
+	response := multicalls.Perform(chainID, calls, nil)
+
+	candidates := []tAaveV3Candidate{}
+	seen := make(map[common.Address]bool)
+	addCandidate := func(strategy common.Address, asset common.Address) {
+		aToken := helpers.DecodeAddress(response[asset.Hex()+`getReserveTokensAddresses`])
+		if (aToken == common.Address{}) || seen[strategy] {
+			return
+		}
+		seen[strategy] = true
+		candidates = append(candidates, tAaveV3Candidate{strategy: strategy, asset: asset, aToken: aToken})
+	}
+	for _, vault := range vaults {
+		if !isV3Vault(vault) {
+			continue
+		}
+		if vault.Kind == models.VaultKindSingle {
+			addCandidate(vault.Address, vault.AssetAddress)
+		}
+		_, strategies := storage.ListStrategiesForVault(chainID, vault.Address)
+		for _, strategy := range strategies {
+			if !strategy.IsRetired {
+				addCandidate(strategy.Address, vault.AssetAddress)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	calls = []ethereum.Call{}
+	for _, candidate := range candidates {
+		calls = append(calls, multicalls.GetBalanceOf(candidate.strategy.Hex(), candidate.aToken, candidate.strategy))
+	}
+	response = multicalls.Perform(chainID, calls, nil)
+	balances := make(map[common.Address]*bigNumber.Int)
+	for _, candidate := range candidates {
+		if rawBalance := response[candidate.strategy.Hex()+`balanceOf`]; len(rawBalance) > 0 {
+			balances[candidate.strategy] = helpers.DecodeBigInt(rawBalance)
+		}
+	}
+	return selectAaveV3Markets(dataProvider, candidates, balances)
+}
+
+/**************************************************************************************************
+** tLlamaLendCandidate is a strategy whose asset is lent by a LlamaLend vault, with its balances of
+** vault shares, held directly or staked in the gauge of the vault.
+**************************************************************************************************/
+type tLlamaLendCandidate struct {
+	strategy     common.Address
+	vault        common.Address
+	gauge        common.Address
+	vaultBalance *bigNumber.Int
+	gaugeBalance *bigNumber.Int
+}
+
+/**************************************************************************************************
+** selectLlamaLendMarkets keeps, for each strategy, the LlamaLend vault in which it holds the most
+** shares, directly or staked. The gauge is only set when the strategy stakes in it, the CRV
+** rewards being earned by the staked shares only.
+**************************************************************************************************/
+func selectLlamaLendMarkets(candidates []tLlamaLendCandidate) []env.TLendingMarket {
+	toInt := func(value *bigNumber.Int) *bigNumber.Int {
+		if value == nil {
+			return bigNumber.NewInt(0)
+		}
+		return value
+	}
+	best := make(map[common.Address]tLlamaLendCandidate)
+	order := []common.Address{}
+	for _, candidate := range candidates {
+		total := bigNumber.NewInt(0).Add(toInt(candidate.vaultBalance), toInt(candidate.gaugeBalance))
+		if total.IsZero() {
+			continue
+		}
+		previous, ok := best[candidate.strategy]
+		if !ok {
+			order = append(order, candidate.strategy)
+		} else if bigNumber.NewInt(0).Add(toInt(previous.vaultBalance), toInt(previous.gaugeBalance)).Gte(total) {
+			continue
+		}
+		best[candidate.strategy] = candidate
+	}
+
+	markets := []env.TLendingMarket{}
+	for _, strategy := range order {
+		candidate := best[strategy]
+		market := env.TLendingMarket{
+			StrategyAddress: strategy,
+			Protocol:        env.LENDING_PROTOCOL_LLAMALEND,
+			Market:          candidate.vault,
+		}
+		if !toInt(candidate.gaugeBalance).IsZero() {
+			market.Gauge = candidate.gauge
+		}
+		markets = append(markets, market)
+	}
+	return markets
+}
+
+/**************************************************************************************************
+** discoverLlamaLendLendingMarkets finds the strategies of a chain lending on Curve LlamaLend: the
+** vaults and gauges of the markets are listed from the factory, and the strategies whose asset is
+** lent by one of these vaults and holding some of its shares, directly or staked in its gauge, are
+** registered as LlamaLend lending markets.
+**************************************************************************************************/
+func discoverLlamaLendLendingMarkets(chainID uint64, factory common.Address) []env.TLendingMarket {
+	response := multicalls.Perform(chainID, []ethereum.Call{multicalls.GetLlamaLendMarketCount(`factory`, factory)}, nil)
+	marketCount := helpers.DecodeBigInt(response[`factorymarket_count`])
+	if marketCount == nil || marketCount.IsZero() {
+		return nil
+	}
+
+	calls := []ethereum.Call{}
+	for i := uint64(0); i < marketCount.Uint64(); i++ {
+		key := strconv.FormatUint(i, 10)
+		index := big.NewInt(int64(i))
+		calls = append(calls, multicalls.GetLlamaLendVault(key, factory, index))
+		calls = append(calls, multicalls.GetLlamaLendGauge(key, factory, index))
+		calls = append(calls, multicalls.GetLlamaLendBorrowedToken(key, factory, index))
+	}
+	response = multicalls.Perform(chainID, calls, nil)
+	type tLlamaLendVault struct {
+		vault common.Address
+		gauge common.Address
+	}
+	vaultsForAsset := make(map[common.Address][]tLlamaLendVault)
+	for i := uint64(0); i < marketCount.Uint64(); i++ {
+		key := strconv.FormatUint(i, 10)
+		vault := helpers.DecodeAddress(response[key+`vaults`])
+		asset := helpers.DecodeAddress(response[key+`borrowed_tokens`])
+		if (vault == common.Address{}) || (asset == common.Address{}) {
+			continue
+		}
+		vaultsForAsset[asset] = append(vaultsForAsset[asset], tLlamaLendVault{
+			vault: vault,
+			gauge: helpers.DecodeAddress(response[key+`gauges`]),
+		})
+	}
+
+	candidates := []tLlamaLendCandidate{}
+	seen := make(map[common.Address]bool)
+	addCandidates := func(strategy common.Address, asset common.Address) {
+		if seen[strategy] {
+			return
+		}
+		seen[strategy] = true
+		for _, llamaLendVault := range vaultsForAsset[asset] {
+			candidates = append(candidates, tLlamaLendCandidate{
+				strategy: strategy,
+				vault:    llamaLendVault.vault,
+				gauge:    llamaLendVault.gauge,
+			})
+		}
+	}
+	_, vaults := storage.ListVaults(chainID)
+	for _, vault := range vaults {
+		if len(vaultsForAsset[vault.AssetAddress]) == 0 {
+			continue
+		}
+		if vault.Kind == models.VaultKindSingle {
+			addCandidates(vault.Address, vault.AssetAddress)
+		}
+		_, strategies := storage.ListStrategiesForVault(chainID, vault.Address)
+		for _, strategy := range strategies {
+			if !strategy.IsRetired {
+				addCandidates(strategy.Address, vault.AssetAddress)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	calls = []ethereum.Call{}
+	for _, candidate := range candidates {
+		key := candidate.strategy.Hex() + candidate.vault.Hex()
+		calls = append(calls, multicalls.GetBalanceOf(key+`vault`, candidate.vault, candidate.strategy))
+		if (candidate.gauge != common.Address{}) {
+			calls = append(calls, multicalls.GetBalanceOf(key+`gauge`, candidate.gauge, candidate.strategy))
+		}
+	}
+	response = multicalls.Perform(chainID, calls, nil)
+	for i, candidate := range candidates {
+		key := candidate.strategy.Hex() + candidate.vault.Hex()
+		if rawBalance := response[key+`vaultbalanceOf`]; len(rawBalance) > 0 {
+			candidates[i].vaultBalance = helpers.DecodeBigInt(rawBalance)
+		}
+		if rawBalance := response[key+`gaugebalanceOf`]; len(rawBalance) > 0 {
+			candidates[i].gaugeBalance = helpers.DecodeBigInt(rawBalance)
+		}
+	}
+	return selectLlamaLendMarkets(candidates)
+}
+
+/**************************************************************************************************
+** mergeLendingMarkets adds the discovered lending markets to the ones registered in the config of
+** the chain, the registered market of a strategy taking precedence.
+**************************************************************************************************/
+func mergeLendingMarkets(registered []env.TLendingMarket, discovered []env.TLendingMarket) []env.TLendingMarket {
+	markets := append([]env.TLendingMarket{}, registered...)
+	known := make(map[common.Address]bool)
+	for _, market := range registered {
+		known[market.StrategyAddress] = true
+	}
+	for _, market := range discovered {
+		if !known[market.StrategyAddress] {
+			known[market.StrategyAddress] = true
+			markets = append(markets, market)
+		}
+	}
+	return markets
+}
+
+/**************************************************************************************************
+** retrieveLendingMarketAPRs computes the APR of all the lending markets of a chain, registered in
+** its config or discovered onchain, and caches them for the APY computation.
+**************************************************************************************************/
+func retrieveLendingMarketAPRs(chainID uint64) {
+	chain, ok := env.GetChain(chainID)
+	if !ok {
+		return
+	}
+	lendingMarkets := chain.LendingMarkets
+	if (chain.AaveV3DataProvider != common.Address{}) {
+		lendingMarkets = mergeLendingMarkets(lendingMarkets, discoverAaveV3LendingMarkets(chainID, chain.AaveV3DataProvider))
+	}
+	if (chain.LlamaLendFactory != common.Address{}) {
+		lendingMarkets = mergeLendingMarkets(lendingMarkets, discoverLlamaLendLendingMarkets(chainID, chain.LlamaLendFactory))
+	}
+	if len(lendingMarkets) == 0 {
+		return
+	}
+
+	marketsByProtocol := make(map[string][]env.TLendingMarket)
+	for _, market := range lendingMarkets {
+		marketsByProtocol[market.Protocol] = append(marketsByProtocol[market.Protocol], market)
+	}
+
+	result := make(map[common.Address]TLendingMarketAPR)
+	for protocol, markets := range marketsByProtocol {
+		var protocolAPRs map[common.Address]TLendingMarketAPR
+		switch protocol {
+		case env.LENDING_PROTOCOL_AAVE_V3:
+			protocolAPRs = computeAaveV3MarketAPRs(chainID, markets)
+		case env.LENDING_PROTOCOL_MORPHO_BLUE:
+			protocolAPRs = computeMorphoBlueMarketAPRs(chainID, markets)
+		case env.LENDING_PROTOCOL_EULER_V2:
+			protocolAPRs = computeEulerV2MarketAPRs(chainID, markets)
+		case env.LENDING_PROTOCOL_LLAMALEND:
+			protocolAPRs = computeLlamaLendMarketAPRs(chainID, markets)
+		default:
+			logs.Warning(`Unknown lending protocol ` + protocol + ` on chain ` + strconv.FormatUint(chainID, 10))
+			continue
+		}
+		if len(protocolAPRs) == 0 {
+			recordAPRSourceError(chainID, APR_SOURCE_LENDING_PREFIX+protocol, APR_SOURCE_ERROR_NO_RESULT)
+		}
+		for strategyAddress, marketAPR := range protocolAPRs {
+			result[strategyAddress] = marketAPR
+			recordAPRSourceSuccess(chainID, APR_SOURCE_LENDING_PREFIX+protocol, strategyAddress.Hex(), marketAPR.SupplyAPR+marketAPR.RewardsAPR+marketAPR.CRVAPR)
+		}
+	}
+
+	lendingMarketAPRsMtx.Lock()
+	lendingMarketAPRs[chainID] = result
+	lendingMarketAPRsMtx.Unlock()
+}
+
+/**************************************************************************************************
+** GetLendingMarketAPR returns the last computed APR of the lending market used by a strategy.
+**************************************************************************************************/
+func GetLendingMarketAPR(chainID uint64, strategyAddress common.Address) (TLendingMarketAPR, bool) {
+	lendingMarketAPRsMtx.RLock()
+	defer lendingMarketAPRsMtx.RUnlock()
+
+	if _, ok := lendingMarketAPRs[chainID]; !ok {
+		return TLendingMarketAPR{}, false
+	}
+	marketAPR, ok := lendingMarketAPRs[chainID][strategyAddress]
+	return marketAPR, ok
+}
+
+/**************************************************************************************************
+** resolveLendingMarketAPR checks the APR returned by the oracle for a strategy against the APR of
+** its lending market, if any:
+** - if the oracle could not provide an APR, or provided 0%, the gross lending market APR is
+**   returned, with `true`, the fees being charged by the caller like on the other gross APRs.
+** - otherwise the oracle APR is kept, and a warning is logged if it deviates from the lending
+**   market APR net of the performance fee by more than lendingMarketAPRTolerance.
+**************************************************************************************************/
+func resolveLendingMarketAPR(
+	chainID uint64,
+	strategyAddress common.Address,
+	oracleAPR float64,
+	hasOracleAPR bool,
+	performanceFee float64,
+) (float64, bool) {
+	marketAPR, ok := GetLendingMarketAPR(chainID, strategyAddress)
+	if !ok {
+		return oracleAPR, false
+	}
+	grossAPR := marketAPR.SupplyAPR + marketAPR.RewardsAPR
+	if !hasOracleAPR || oracleAPR == 0 {
+		return grossAPR, true
+	}
+	if deviation := math.Abs(oracleAPR-grossAPR*(1-performanceFee)) / oracleAPR; deviation > lendingMarketAPRTolerance {
+		logs.Warning(`APR oracle and ` + marketAPR.Protocol + ` market APR deviate for strategy ` + strategyAddress.Hex())
+	}
+	return oracleAPR, false
+}
+
+/**************************************************************************************************
+** getStrategyOracleAPR returns the APR of a strategy from the oracle, checked against (or replaced
+** by) the APR of its lending market. Like the oracle APR, the lending market APR is net of the own
+** performance fee of the strategy, the fee of the vault being charged by the caller. An overridden
+** APR replaces both. The oracle may be nil, on the
+** chains without oracle nor fallback lens. The boolean is false if no APR is available at all.
+**************************************************************************************************/
+func getStrategyOracleAPR(oracle *contracts.YVaultsV3APROracleCaller, strategy models.TStrategy) (float64, bool) {
+	if override, ok := resolveAPROverride(strategy); ok {
+		return override.APR, true
+	}
+	oracleAPR := 0.0
+	err := errNoAPROracle
+	if oracle != nil {
+		var expected *big.Int
+		expected, err = oracle.GetStrategyApr(nil, strategy.Address, big.NewInt(0))
+		if err == nil {
+			oracleAPR, _ = helpers.ToNormalizedAmount(bigNumber.SetInt(expected), 18).Float64()
+			recordAPRSourceSuccess(strategy.ChainID, APR_SOURCE_APR_ORACLE, strategy.Address.Hex(), oracleAPR)
+		} else {
+			recordAPRSourceError(strategy.ChainID, APR_SOURCE_APR_ORACLE, err.Error())
+		}
+	}
+	performanceFee := 0.0
+	if strategy.LastPerformanceFee != nil {
+		performanceFee, _ = helpers.ToNormalizedAmount(strategy.LastPerformanceFee, 4).Float64()
+	}
+	strategyAPR, isFallback := resolveLendingMarketAPR(strategy.ChainID, strategy.Address, oracleAPR, err == nil, performanceFee)
+	if err != nil && !isFallback {
+		return 0, false
+	}
+	if isFallback {
+		strategyAPR = strategyAPR * (1 - performanceFee)
+	}
+	return strategyAPR, true
+}
+
+/**************************************************************************************************
+** computeLendingMarketComposite breaks down the forward APR of a v3 vault lending through its
+** strategies (or directly, for a tokenized strategy) into the supply APY (PoolAPY), the unboosted
+** and boosted CRV rewards APR (BaseAPR, BoostedAPR, Boost) and the other rewards APY (RewardsAPY).
+** Each strategy is weighted by its debt ratio, and the APY are compounded like the forward APY of
+** the vault. The boolean is false if no lending market is known.
+**************************************************************************************************/
+func computeLendingMarketComposite(vault models.TVault, allStrategiesForVault map[string]models.TStrategy) (TCompositeData, bool) {
+	type tWeightedMarket struct {
+		marketAPR TLendingMarketAPR
+		weight    float64
+	}
+	weightedMarkets := []tWeightedMarket{}
+	if marketAPR, ok := GetLendingMarketAPR(vault.ChainID, vault.Address); ok {
+		weightedMarkets = append(weightedMarkets, tWeightedMarket{marketAPR, 1})
+	} else {
+		for _, strategy := range allStrategiesForVault {
+			if strategy.IsRetired || strategy.LastDebtRatio == nil || strategy.LastDebtRatio.IsZero() {
+				continue
+			}
+			if marketAPR, ok := GetLendingMarketAPR(vault.ChainID, strategy.Address); ok {
+				debtRatio, _ := strategy.LastDebtRatio.Float64()
+				weightedMarkets = append(weightedMarkets, tWeightedMarket{marketAPR, debtRatio / 10000})
+			}
+		}
+	}
+	if len(weightedMarkets) == 0 {
+		return TCompositeData{}, false
+	}
+
+	supplyAPR, crvAPR, boostedCRVAPR, otherRewardsAPR := 0.0, 0.0, 0.0, 0.0
+	for _, market := range weightedMarkets {
+		supplyAPR += market.marketAPR.SupplyAPR * market.weight
+		crvAPR += market.marketAPR.CRVAPR * market.weight
+		boostedCRVAPR += market.marketAPR.CRVAPR * market.marketAPR.Boost * market.weight
+		otherRewardsAPR += (market.marketAPR.RewardsAPR - market.marketAPR.CRVAPR*market.marketAPR.Boost) * market.weight
+	}
+	boost := 0.0
+	if crvAPR > 0 {
+		boost = boostedCRVAPR / crvAPR
+	}
+	return TCompositeData{
+		PoolAPY:    bigNumber.NewFloat(convertFloatAPRToAPY(supplyAPR, FORWARD_APY_COMPOUNDING_PERIODS)),
+		BaseAPR:    bigNumber.NewFloat(crvAPR),
+		BoostedAPR: bigNumber.NewFloat(boostedCRVAPR),
+		Boost:      bigNumber.NewFloat(boost),
+		RewardsAPY: bigNumber.NewFloat(convertFloatAPRToAPY(otherRewardsAPR, FORWARD_APY_COMPOUNDING_PERIODS)),
+	}, true
+}
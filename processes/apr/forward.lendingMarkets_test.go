@@ -0,0 +1,96 @@
+package apr
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+)
+
+func TestResolveLendingMarketAPR(t *testing.T) {
+	chainID := uint64(1)
+	strategy := common.HexToAddress(`0x1`)
+	lendingMarketAPRsMtx.Lock()
+	previous := lendingMarketAPRs[chainID]
+	lendingMarketAPRs[chainID] = map[common.Address]TLendingMarketAPR{
+		strategy: {Protocol: env.LENDING_PROTOCOL_AAVE_V3, SupplyAPR: 0.04, RewardsAPR: 0.01},
+	}
+	lendingMarketAPRsMtx.Unlock()
+	defer func() {
+		lendingMarketAPRsMtx.Lock()
+		lendingMarketAPRs[chainID] = previous
+		lendingMarketAPRsMtx.Unlock()
+	}()
+
+	if apr, isFallback := resolveLendingMarketAPR(chainID, strategy, 0, false, 0.1); !isFallback || math.Abs(apr-0.05) > 1e-9 {
+		t.Errorf("expected the gross market APR 0.05 as fallback, got %v (fallback %v)", apr, isFallback)
+	}
+	if apr, isFallback := resolveLendingMarketAPR(chainID, strategy, 0.045, true, 0.1); isFallback || apr != 0.045 {
+		t.Errorf("expected the oracle APR to be kept, got %v (fallback %v)", apr, isFallback)
+	}
+	if apr, isFallback := resolveLendingMarketAPR(chainID, common.HexToAddress(`0x2`), 0.03, true, 0.1); isFallback || apr != 0.03 {
+		t.Errorf("expected the oracle APR without market, got %v (fallback %v)", apr, isFallback)
+	}
+}
+
+func TestDiscoveredAaveV3LendingMarkets(t *testing.T) {
+	if (env.ETHEREUM.AaveV3DataProvider == common.Address{}) {
+		t.Fatal("expected the Aave v3 data provider to be configured on Ethereum")
+	}
+	dataProvider := env.ETHEREUM.AaveV3DataProvider
+	asset := common.HexToAddress(`0xa0`)
+	lending := tAaveV3Candidate{strategy: common.HexToAddress(`0x1`), asset: asset, aToken: common.HexToAddress(`0xa1`)}
+	idle := tAaveV3Candidate{strategy: common.HexToAddress(`0x2`), asset: asset, aToken: common.HexToAddress(`0xa1`)}
+	unread := tAaveV3Candidate{strategy: common.HexToAddress(`0x3`), asset: asset, aToken: common.HexToAddress(`0xa1`)}
+	balances := map[common.Address]*bigNumber.Int{
+		lending.strategy: bigNumber.NewInt(1000),
+		idle.strategy:    bigNumber.NewInt(0),
+	}
+
+	discovered := selectAaveV3Markets(dataProvider, []tAaveV3Candidate{lending, idle, unread}, balances)
+	if len(discovered) != 1 || discovered[0].StrategyAddress != lending.strategy {
+		t.Fatalf("expected only the strategy holding aTokens to be discovered, got %+v", discovered)
+	}
+	if discovered[0].Protocol != env.LENDING_PROTOCOL_AAVE_V3 || discovered[0].Market != dataProvider || discovered[0].Asset != asset {
+		t.Errorf("expected an Aave v3 market read through the data provider, got %+v", discovered[0])
+	}
+
+	registered := []env.TLendingMarket{{StrategyAddress: lending.strategy, Protocol: env.LENDING_PROTOCOL_MORPHO_BLUE}}
+	merged := mergeLendingMarkets(registered, append(discovered, env.TLendingMarket{StrategyAddress: idle.strategy}))
+	if len(merged) != 2 || merged[0].Protocol != env.LENDING_PROTOCOL_MORPHO_BLUE || merged[1].StrategyAddress != idle.strategy {
+		t.Errorf("expected the registered market to take precedence, got %+v", merged)
+	}
+}
+
+func TestDiscoveredLlamaLendLendingMarkets(t *testing.T) {
+	strategy := common.HexToAddress(`0x1`)
+	smallVault := tLlamaLendCandidate{
+		strategy:     strategy,
+		vault:        common.HexToAddress(`0xb1`),
+		gauge:        common.HexToAddress(`0xc1`),
+		vaultBalance: bigNumber.NewInt(10),
+	}
+	stakedVault := tLlamaLendCandidate{
+		strategy:     strategy,
+		vault:        common.HexToAddress(`0xb2`),
+		gauge:        common.HexToAddress(`0xc2`),
+		vaultBalance: bigNumber.NewInt(5),
+		gaugeBalance: bigNumber.NewInt(1000),
+	}
+	idle := tLlamaLendCandidate{strategy: common.HexToAddress(`0x2`), vault: common.HexToAddress(`0xb1`), gauge: common.HexToAddress(`0xc1`)}
+
+	discovered := selectLlamaLendMarkets([]tLlamaLendCandidate{smallVault, idle, stakedVault})
+	if len(discovered) != 1 || discovered[0].StrategyAddress != strategy {
+		t.Fatalf("expected only the strategy holding shares to be discovered, got %+v", discovered)
+	}
+	if discovered[0].Protocol != env.LENDING_PROTOCOL_LLAMALEND || discovered[0].Market != stakedVault.vault || discovered[0].Gauge != stakedVault.gauge {
+		t.Errorf("expected the LlamaLend vault with the most shares and its gauge, got %+v", discovered[0])
+	}
+
+	unstaked := selectLlamaLendMarkets([]tLlamaLendCandidate{smallVault})
+	if len(unstaked) != 1 || (unstaked[0].Gauge != common.Address{}) {
+		t.Errorf("expected no gauge when the shares are not staked, got %+v", unstaked)
+	}
+}
@@ -0,0 +1,151 @@
+package apr
+
+import (
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/contracts"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** Some v3 allocator vaults (meta-vaults) use other yVaults as strategies. The APR oracle only sees
+** the nested vaults through their own adapters, if any, so the forward APY of a meta-vault is
+** composed from the forward APY computed for each nested vault instead, weighted by the debt
+** ratio of the matching strategy. The other strategies keep their oracle APR.
+** The composition is recursive (a nested vault can itself be a meta-vault) and runs once all the
+** vaults of the chain have their own forward APY.
+**************************************************************************************************/
+type tMetaVaultResolver struct {
+	chainID         uint64
+	oracle          *contracts.YVaultsV3APROracleCaller
+	computedAPYData map[common.Address]TVaultAPY
+	resolved        map[common.Address]float64
+	visiting        map[common.Address]bool
+}
+
+/**************************************************************************************************
+** getNestedVaults returns the active strategies of a vault that are other tracked vaults.
+**************************************************************************************************/
+func getNestedVaults(vault models.TVault, allStrategiesForVault map[string]models.TStrategy) map[common.Address]models.TVault {
+	nestedVaults := make(map[common.Address]models.TVault)
+	for _, strategy := range allStrategiesForVault {
+		if strategy.IsRetired || strategy.LastDebtRatio == nil || strategy.LastDebtRatio.IsZero() {
+			continue
+		}
+		if nestedVault, ok := storage.GetVault(vault.ChainID, strategy.Address); ok && isV3Vault(nestedVault) {
+			nestedVaults[strategy.Address] = nestedVault
+		}
+	}
+	return nestedVaults
+}
+
+/**************************************************************************************************
+** resolveVaultAPR returns the forward net APR of a vault. For a regular vault, this is the APR
+** behind the forward APY computed for it during this run, with the periods of its source. For a meta-vault, this is the sum of the APRs of its
+** strategies, nested vaults resolved recursively, weighted by their debt ratio and each minus the
+** performance fee charged on its gains. A vault already being resolved higher in the chain of
+** nested vaults is a cycle: it is reported and its strategy falls back to the oracle APR.
+**************************************************************************************************/
+func (r *tMetaVaultResolver) resolveVaultAPR(vault models.TVault) (float64, bool) {
+	if apr, ok := r.resolved[vault.Address]; ok {
+		return apr, true
+	}
+	if r.visiting[vault.Address] {
+		logs.Warning(`Meta-vault cycle detected on chain ` + strconv.FormatUint(r.chainID, 10) + ` for vault ` + vault.Address.Hex())
+		return 0, false
+	}
+
+	allStrategiesForVault, _ := storage.ListStrategiesForVault(r.chainID, vault.Address)
+	nestedVaults := getNestedVaults(vault, allStrategiesForVault)
+	if len(nestedVaults) == 0 {
+		vaultAPY, ok := r.computedAPYData[vault.Address]
+		if !ok || vaultAPY.ForwardAPY.NetAPY == nil {
+			return 0, false
+		}
+		r.resolved[vault.Address], _ = ToForwardNetAPR(vaultAPY.ForwardAPY).Float64()
+		return r.resolved[vault.Address], true
+	}
+
+	r.visiting[vault.Address] = true
+	defer delete(r.visiting, vault.Address)
+
+	weightedAPR := 0.0
+	hasDebt := false
+	for _, strategy := range allStrategiesForVault {
+		if strategy.IsRetired || strategy.LastDebtRatio == nil || strategy.LastDebtRatio.IsZero() {
+			continue
+		}
+		strategyAPR, ok := 0.0, false
+		if nestedVault, isNested := nestedVaults[strategy.Address]; isNested {
+			if strategyAPR, ok = r.resolveVaultAPR(nestedVault); ok {
+				strategyAPR = strategyAPR * (1 - getStrategyPerformanceFee(vault, strategy))
+			}
+		}
+		if !ok && r.oracle != nil {
+			strategyAPR, ok = getStrategyNetAPR(r.oracle, vault, strategy)
+		}
+		if !ok {
+			continue
+		}
+		debtRatio, _ := strategy.LastDebtRatio.Float64()
+		weightedAPR += strategyAPR * debtRatio / 10000
+		hasDebt = true
+	}
+	if !hasDebt {
+		return 0, false
+	}
+	r.resolved[vault.Address] = weightedAPR
+	return r.resolved[vault.Address], true
+}
+
+/**************************************************************************************************
+** applyMetaVaultComposition replaces the forward APY of the meta-vaults of the chain with the APY
+** composed from their nested vaults. The oracle APY stays available in the composite data. The
+** debt ratios already account for the idle funds, and the entry/exit fees are applied again. The
+** meta-vaults with an overridden APR are left as they are. It runs before the gas impact, the
+** guard and the derived APY (total, net APR), which are then computed from the composed APY.
+**************************************************************************************************/
+func applyMetaVaultComposition(chainID uint64, computedAPYData map[common.Address]TVaultAPY) {
+	resolver := &tMetaVaultResolver{
+		chainID:         chainID,
+		computedAPYData: computedAPYData,
+		resolved:        make(map[common.Address]float64),
+		visiting:        make(map[common.Address]bool),
+	}
+	if oracle, _, ok := getChainAPROracle(chainID); ok {
+		resolver.oracle = oracle
+	}
+
+	for vaultAddress, vaultAPY := range computedAPYData {
+		vault, ok := storage.GetVault(chainID, vaultAddress)
+		if !ok || !isV3Vault(vault) || vaultAPY.ForwardAPY.PrimarySource == models.APRPrimarySourceOverride {
+			continue
+		}
+		allStrategiesForVault, _ := storage.ListStrategiesForVault(chainID, vault.Address)
+		if len(getNestedVaults(vault, allStrategiesForVault)) == 0 {
+			continue
+		}
+		metaVaultAPR, ok := resolver.resolveVaultAPR(vault)
+		if !ok {
+			continue
+		}
+
+		metaVaultAPY := bigNumber.NewFloat(convertFloatAPRToAPY(metaVaultAPR, FORWARD_APY_COMPOUNDING_PERIODS))
+		vaultAPY.ForwardAPY.Type = `v3:metaVault`
+		vaultAPY.ForwardAPY.PrimarySource = models.APRPrimarySourceMetaVault
+		vaultAPY.ForwardAPY.NetAPY = metaVaultAPY
+		vaultAPY.ForwardAPY.CompoundingPeriods = 0
+		vaultAPY.ForwardAPY.NetAPYDeployedOnly = metaVaultAPY
+		if idleRatio := vaultAPY.ForwardAPY.IdleRatio; idleRatio != nil {
+			if idleRatioFloat, _ := idleRatio.Float64(); idleRatioFloat < 1 {
+				vaultAPY.ForwardAPY.NetAPYDeployedOnly = bigNumber.NewFloat(0).Quo(metaVaultAPY, bigNumber.NewFloat(1-idleRatioFloat))
+			}
+		}
+		vaultAPY.ForwardAPY = applyEntryExitFees(chainID, vaultAPY.ForwardAPY, vaultAPY.EntryExitFeeBps)
+		computedAPYData[vault.Address] = vaultAPY
+	}
+}
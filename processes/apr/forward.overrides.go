@@ -0,0 +1,146 @@
+package apr
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** Some strategies are mishandled by the APR oracle (nascent strategies, off-chain yield, ...). Their
+** APR can be overridden, by decreasing priority:
+** - config: pinned by an operator in BASE_DATA_PATH/meta/strategies/<chainID>.aprOverrides.json,
+**   reloaded on every APY computation:
+**   { "<strategy>": { "apr": 0.042, "reason": "Off-chain yield, see the monthly report" } }
+** - plugin: computed by a function registered with RegisterStrategyAPRComputer.
+** The APR is a fraction (0.042 for 4.2%), net of all the fees: the performance fee of the strategy
+** and the one the vault charges on its gains are not charged again. An override set for a vault
+** address replaces the APR of the whole vault.
+**************************************************************************************************/
+const (
+	APR_OVERRIDE_SOURCE_CONFIG = `config`
+	APR_OVERRIDE_SOURCE_PLUGIN = `plugin`
+)
+
+type TStrategyAPROverride struct {
+	APR    float64 `json:"apr"`
+	Reason string  `json:"reason"`
+}
+
+/**************************************************************************************************
+** TStrategyAPRComputer computes the APR of a strategy, as a fraction net of all the fees.
+** The boolean is false when it cannot, the oracle APR being used instead. When registered for a
+** vault, only the ChainID and the Address of the strategy are set.
+**************************************************************************************************/
+type TStrategyAPRComputer func(strategy models.TStrategy) (float64, bool)
+
+type tStrategyAPRPlugin struct {
+	name    string
+	compute TStrategyAPRComputer
+}
+
+var (
+	strategyAPROverrides    = make(map[uint64]map[string]TStrategyAPROverride)
+	strategyAPRPlugins      = make(map[uint64]map[string]tStrategyAPRPlugin)
+	strategyAPROverridesMtx sync.RWMutex
+)
+
+/**************************************************************************************************
+** RegisterStrategyAPRComputer registers the function computing the APR of a strategy (or of a
+** vault) of a chain, in place of the oracle. The name is reported in the composite data of the
+** vaults using it. Registering again for the same address replaces the previous function.
+**************************************************************************************************/
+func RegisterStrategyAPRComputer(chainID uint64, strategyAddress common.Address, name string, compute TStrategyAPRComputer) {
+	strategyAPROverridesMtx.Lock()
+	defer strategyAPROverridesMtx.Unlock()
+	if _, ok := strategyAPRPlugins[chainID]; !ok {
+		strategyAPRPlugins[chainID] = make(map[string]tStrategyAPRPlugin)
+	}
+	strategyAPRPlugins[chainID][strings.ToLower(strategyAddress.Hex())] = tStrategyAPRPlugin{name: name, compute: compute}
+}
+
+/**************************************************************************************************
+** loadStrategyAPROverrides reads the override file of a chain. The file is optional: without it,
+** or if it cannot be decoded, no APR is pinned.
+**************************************************************************************************/
+func loadStrategyAPROverrides(chainID uint64) {
+	overrides := make(map[string]TStrategyAPROverride)
+	filePath := env.BASE_DATA_PATH + `/meta/strategies/` + strconv.FormatUint(chainID, 10) + `.aprOverrides.json`
+	if content, err := os.ReadFile(filePath); err == nil {
+		fileOverrides := make(map[string]TStrategyAPROverride)
+		if err := json.Unmarshal(content, &fileOverrides); err != nil {
+			logs.Error(`Failed to decode the APR overrides of chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+		}
+		for strategy, override := range fileOverrides {
+			overrides[strings.ToLower(strategy)] = override
+		}
+	}
+
+	strategyAPROverridesMtx.Lock()
+	strategyAPROverrides[chainID] = overrides
+	strategyAPROverridesMtx.Unlock()
+}
+
+/**************************************************************************************************
+** resolveAPROverride returns the override of the APR of a strategy or a vault, from the config
+** first, then from the plugins. The boolean is false when its APR is not overridden.
+**************************************************************************************************/
+func resolveAPROverride(strategy models.TStrategy) (models.TAPROverride, bool) {
+	key := strings.ToLower(strategy.Address.Hex())
+	strategyAPROverridesMtx.RLock()
+	override, hasOverride := strategyAPROverrides[strategy.ChainID][key]
+	plugin, hasPlugin := strategyAPRPlugins[strategy.ChainID][key]
+	strategyAPROverridesMtx.RUnlock()
+
+	if hasOverride {
+		return models.TAPROverride{
+			Address: strategy.Address,
+			APR:     override.APR,
+			Source:  APR_OVERRIDE_SOURCE_CONFIG,
+			Reason:  override.Reason,
+		}, true
+	}
+	if hasPlugin {
+		if pluginAPR, ok := plugin.compute(strategy); ok {
+			return models.TAPROverride{
+				Address: strategy.Address,
+				APR:     pluginAPR,
+				Source:  APR_OVERRIDE_SOURCE_PLUGIN + `:` + plugin.name,
+			}, true
+		}
+	}
+	return models.TAPROverride{}, false
+}
+
+/**************************************************************************************************
+** listVaultAPROverrides returns the overrides of a vault and of its strategies with some debt, for
+** the provenance in the composite data. The override of the vault comes first, then the ones of
+** the strategies sorted by address.
+**************************************************************************************************/
+func listVaultAPROverrides(vault models.TVault, allStrategiesForVault map[string]models.TStrategy) []models.TAPROverride {
+	overrides := []models.TAPROverride{}
+	if override, ok := resolveAPROverride(models.TStrategy{ChainID: vault.ChainID, Address: vault.Address}); ok {
+		overrides = append(overrides, override)
+	}
+	strategyOverrides := []models.TAPROverride{}
+	for _, strategy := range allStrategiesForVault {
+		if strategy.IsRetired || strategy.LastDebtRatio == nil || strategy.LastDebtRatio.IsZero() {
+			continue
+		}
+		if override, ok := resolveAPROverride(strategy); ok {
+			strategyOverrides = append(strategyOverrides, override)
+		}
+	}
+	sort.Slice(strategyOverrides, func(i, j int) bool {
+		return strategyOverrides[i].Address.Hex() < strategyOverrides[j].Address.Hex()
+	})
+	return append(overrides, strategyOverrides...)
+}
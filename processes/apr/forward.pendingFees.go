@@ -0,0 +1,27 @@
+package apr
+
+import (
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/processes/fees"
+)
+
+/**************************************************************************************************
+** withPendingFees returns the vault used to compute the forward APY. With the
+** FORWARD_APY_USE_PENDING_FEES option, the fees its accountant charges from the next reports of
+** its strategies replace the current ones, the forward APY being the expected yield of the upcoming
+** period. The current APY and the fee
+** impact keep the current fees.
+**************************************************************************************************/
+func withPendingFees(vault models.TVault) models.TVault {
+	if !env.FORWARD_APY_USE_PENDING_FEES {
+		return vault
+	}
+	managementFee, performanceFee, ok := fees.GetPendingFeesBps(vault.ChainID, vault.Address)
+	if !ok {
+		return vault
+	}
+	vault.ManagementFee = managementFee
+	vault.PerformanceFee = performanceFee
+	return vault
+}
@@ -43,6 +43,7 @@ func calculatePendleStrategyAPY(
 
 	pendleMarkets, ok := storage.GetCachedPendleMarkets(vault.ChainID)
 	if !ok {
+		recordAPRSourceError(vault.ChainID, APR_SOURCE_PENDLE, `no market returned`)
 		return TStrategyAPY{
 			Type:      `pendle`,
 			DebtRatio: debtRatio,
@@ -52,6 +53,7 @@ func calculatePendleStrategyAPY(
 	}
 	data, ok := pendleMarkets[vault.AssetAddress.Hex()]
 	if !ok {
+		recordAPRSourceError(vault.ChainID, APR_SOURCE_PENDLE, `no market for `+vault.AssetAddress.Hex())
 		return TStrategyAPY{
 			Type:      `pendle`,
 			DebtRatio: debtRatio,
@@ -60,7 +62,8 @@ func calculatePendleStrategyAPY(
 		}
 	}
 
-	grossAPY := bigNumber.NewFloat(data.AggretatedAPY)             // Using aggregatedApy
+	grossAPY := bigNumber.NewFloat(data.AggretatedAPY) // Using aggregatedApy
+	recordAPRSourceSuccess(vault.ChainID, APR_SOURCE_PENDLE, vault.AssetAddress.Hex(), data.AggretatedAPY)
 	netAPY := bigNumber.NewFloat(0).Mul(grossAPY, oneMinusPerfFee) // grossAPY * (1 - perfFee)
 	if netAPY.Gt(vaultManagementFee) {                             // Management fee can never induce a negative APR
 		netAPY = bigNumber.NewFloat(0).Sub(netAPY, vaultManagementFee) // (grossAPY * (1 - perfFee)) - managementFee
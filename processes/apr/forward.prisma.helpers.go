@@ -8,7 +8,6 @@ import (
 	"github.com/yearn/ydaemon/common/bigNumber"
 	"github.com/yearn/ydaemon/common/contracts"
 	"github.com/yearn/ydaemon/common/ethereum"
-	"github.com/yearn/ydaemon/common/helpers"
 	"github.com/yearn/ydaemon/internal/models"
 	"github.com/yearn/ydaemon/internal/storage"
 )
@@ -52,10 +51,15 @@ func getPrismaAPY(chainID uint64, prismaReceiver common.Address) (*bigNumber.Flo
 	if err != nil {
 		return bigNumber.NewFloat(0), bigNumber.NewFloat(0)
 	}
-	rate := helpers.ToNormalizedAmount(bigNumber.NewInt(0).Set(rewardRate), 18)
-	supply := helpers.ToNormalizedAmount(bigNumber.NewInt(0).Set(totalSupply), 18)
-	prismaPrice := bigNumber.NewFloat(0)
 	prismaTokenAddress := common.HexToAddress(`0xdA47862a83dac0c112BA89c6abC2159b95afd71C`)
+	normalizedRate, okRate := normalizeTokenAmount(chainID, prismaTokenAddress, bigNumber.NewInt(0).Set(rewardRate), `prisma reward rate`)
+	normalizedSupply, okSupply := normalizeTokenAmount(chainID, lpToken, bigNumber.NewInt(0).Set(totalSupply), `prisma receiver supply`)
+	if !okRate || !okSupply {
+		return bigNumber.NewFloat(0), bigNumber.NewFloat(0)
+	}
+	rate := normalizedRate.Float()
+	supply := normalizedSupply.Float()
+	prismaPrice := bigNumber.NewFloat(0)
 	if tokenPrice, ok := storage.GetPrice(chainID, prismaTokenAddress); ok {
 		prismaPrice = tokenPrice.HumanizedPrice
 	}
@@ -0,0 +1,160 @@
+package apr
+
+import (
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/addresses"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The rewards are valued at the current price of their token, so a move of the price moves their
+** APY in the same proportion. The sensitivity is given for a REWARD_PRICE_MOVE move.
+**************************************************************************************************/
+const REWARD_PRICE_MOVE = 0.1
+
+const (
+	REWARD_SOURCE_FORWARD = `forward`
+	REWARD_SOURCE_STAKING = `staking`
+)
+
+/**************************************************************************************************
+** The staking contracts a vault can have, in the order ComputeChainAPY reads them: the staking
+** rewards APY is the one of the last contract found.
+**************************************************************************************************/
+const (
+	STAKING_SOURCE_OP     = `op`
+	STAKING_SOURCE_VEYFI  = `veYFI`
+	STAKING_SOURCE_JUICED = `juiced`
+	STAKING_SOURCE_V3     = `v3`
+)
+
+/**************************************************************************************************
+** newRewardAPR returns the part of a net APY paid in a reward token: the APR of the token
+** multiplied by the factors applied to it (1 - keep, 1 - performance fee, debt ratio, ...).
+**************************************************************************************************/
+func newRewardAPR(token common.Address, apr *bigNumber.Float, factors ...*bigNumber.Float) TRewardAPR {
+	value := bigNumber.NewFloat(0).Clone(apr)
+	for _, factor := range factors {
+		value = bigNumber.NewFloat(0).Mul(value, factor)
+	}
+	return TRewardAPR{Token: token, APR: value}
+}
+
+/**************************************************************************************************
+** mergeRewardAPRs adds the reward APRs of a strategy to the ones of its vault, token by token.
+** The rewards without token (chains without CRV or CVX) or without APR are ignored.
+**************************************************************************************************/
+func mergeRewardAPRs(rewards []TRewardAPR, strategyRewards []TRewardAPR) []TRewardAPR {
+	for _, reward := range strategyRewards {
+		if (reward.Token == common.Address{}) || reward.APR == nil || reward.APR.IsZero() {
+			continue
+		}
+		merged := false
+		for i, existing := range rewards {
+			if addresses.Equals(existing.Token, reward.Token) {
+				rewards[i].APR = bigNumber.NewFloat(0).Add(existing.APR, reward.APR)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			rewards = append(rewards, TRewardAPR{Token: reward.Token, APR: bigNumber.NewFloat(0).Clone(reward.APR)})
+		}
+	}
+	return rewards
+}
+
+/**************************************************************************************************
+** getStakingRewardTokens returns the reward tokens of the staking contract of a vault the staking
+** rewards APY was computed from, with their APY.
+**************************************************************************************************/
+func getStakingRewardTokens(chainID uint64, vault models.TVault, stakingSource string) []storage.TRewardToken {
+	var stakingData storage.TStakingData
+	var ok bool
+	switch stakingSource {
+	case STAKING_SOURCE_OP:
+		stakingData, ok = storage.GetOPStakingForVault(chainID, vault.Address)
+	case STAKING_SOURCE_VEYFI:
+		stakingData, ok = storage.GetVeYFIStakingForVault(chainID, vault.Address)
+	case STAKING_SOURCE_JUICED:
+		stakingData, ok = storage.GetJuicedStakingDataForVault(chainID, vault.Address)
+	case STAKING_SOURCE_V3:
+		stakingData, ok = storage.GetV3StakingDataForVault(chainID, vault.Address)
+	}
+	if !ok {
+		return nil
+	}
+	return stakingData.RewardTokens
+}
+
+/**************************************************************************************************
+** computeRewardContributions attributes the headline APY of a vault, its forward net APY plus its
+** staking rewards APY, to the reward tokens paying it: the rewards harvested by the strategies
+** (CRV, CVX, VELO, AERO, ...) and the ones of the staking contract (OP, ARB, dYFI, ...). The
+** forward rewards are reduced like the forward APY for the idle assets. The contributions are
+** sorted by APY, highest first, and nil when the vault has no reward.
+**************************************************************************************************/
+func computeRewardContributions(chainID uint64, vault models.TVault, vaultAPY TVaultAPY, stakingSource string) []TRewardContribution {
+	type tReward struct {
+		token  common.Address
+		symbol string
+		source string
+		apy    float64
+	}
+	rewards := []tReward{}
+
+	forwardScale := 1.0
+	if vaultAPY.ForwardAPY.IdleRatio != nil && !forwardAPYAccountsForIdle(vaultAPY.ForwardAPY) {
+		idleRatio, _ := vaultAPY.ForwardAPY.IdleRatio.Float64()
+		forwardScale = 1 - idleRatio
+	}
+	for _, reward := range vaultAPY.ForwardAPY.Composite.Rewards {
+		apy, _ := reward.APR.Float64()
+		rewards = append(rewards, tReward{token: reward.Token, source: REWARD_SOURCE_FORWARD, apy: apy * forwardScale})
+	}
+	for _, rewardToken := range getStakingRewardTokens(chainID, vault, stakingSource) {
+		if rewardToken.APR == nil {
+			continue
+		}
+		apy, _ := rewardToken.APR.Float64()
+		rewards = append(rewards, tReward{token: rewardToken.Address, symbol: rewardToken.Symbol, source: REWARD_SOURCE_STAKING, apy: apy})
+	}
+
+	headlineAPY := 0.0
+	if vaultAPY.ForwardAPY.NetAPY != nil {
+		headlineAPY, _ = vaultAPY.ForwardAPY.NetAPY.Float64()
+	}
+	if vaultAPY.Extra.StakingRewardsAPY != nil {
+		stakingAPY, _ := vaultAPY.Extra.StakingRewardsAPY.Float64()
+		headlineAPY += stakingAPY
+	}
+
+	var contributions []TRewardContribution
+	for _, reward := range rewards {
+		if reward.apy <= 0 {
+			continue
+		}
+		if token, ok := storage.GetERC20(chainID, reward.token); ok && token.Symbol != `` {
+			reward.symbol = token.Symbol
+		}
+		contribution := TRewardContribution{
+			Token:       reward.token,
+			Symbol:      reward.symbol,
+			Source:      reward.source,
+			APY:         reward.apy,
+			Sensitivity: reward.apy * REWARD_PRICE_MOVE,
+		}
+		if headlineAPY > 0 {
+			contribution.Share = min(reward.apy/headlineAPY, 1)
+		}
+		contributions = append(contributions, contribution)
+	}
+	sort.SliceStable(contributions, func(i, j int) bool {
+		return contributions[i].APY > contributions[j].APY
+	})
+	return contributions
+}
@@ -0,0 +1,535 @@
+package apr
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The trading fees of a Uniswap v3 pool are measured over the last UNIV3_FEES_APR_PERIOD_DAYS days,
+** from the growth of its fees per unit of liquidity inside the ranges of the positions.
+**************************************************************************************************/
+const UNIV3_FEES_APR_PERIOD_DAYS = 7
+
+/**************************************************************************************************
+** UNIV3_MAX_POSITIONS_PER_STRATEGY caps the positions of the position manager read for a strategy.
+**************************************************************************************************/
+const UNIV3_MAX_POSITIONS_PER_STRATEGY = 10
+
+/**************************************************************************************************
+** tUniV3Position is a range of liquidity in a Uniswap v3 pool, between two ticks.
+**************************************************************************************************/
+type tUniV3Position struct {
+	Lower     int64
+	Upper     int64
+	Liquidity *big.Int
+}
+
+/**************************************************************************************************
+** tUniV3Range is a position of the Uniswap v3 position manager held by a strategy, in its pool.
+**************************************************************************************************/
+type tUniV3Range struct {
+	Pool     common.Address
+	Position tUniV3Position
+}
+
+/**************************************************************************************************
+** tUniV3PoolState is the state of a Uniswap v3 pool at a block: its current tick, the growth of its
+** fees per unit of active liquidity and the growth of its fees outside of the ticks of the
+** positions, in each of its tokens.
+**************************************************************************************************/
+type tUniV3PoolState struct {
+	Tick             int64
+	SqrtPriceX96     *big.Int
+	FeeGrowthGlobal  [2]*big.Int
+	FeeGrowthOutside map[int64][2]*big.Int
+}
+
+var q96 = new(big.Int).Lsh(big.NewInt(1), 96)
+var q128 = new(big.Int).Lsh(big.NewInt(1), 128)
+var q256 = new(big.Int).Lsh(big.NewInt(1), 256)
+
+/**************************************************************************************************
+** computeFeeGrowthDelta returns the growth of a fee growth counter of a Uniswap v3 pool between two
+** blocks. The counters are meant to overflow, so the difference is taken modulo 2^256.
+**************************************************************************************************/
+func computeFeeGrowthDelta(current *big.Int, past *big.Int) *big.Int {
+	delta := new(big.Int).Sub(current, past)
+	return delta.Mod(delta, q256)
+}
+
+/**************************************************************************************************
+** computeFeeGrowthInside returns the growth of the fees of a token per unit of liquidity inside the
+** range of a position, as computed by the pool: the global growth minus the growth below the lower
+** tick and above the upper one, modulo 2^256.
+**************************************************************************************************/
+func computeFeeGrowthInside(state tUniV3PoolState, position tUniV3Position, side int) *big.Int {
+	global := state.FeeGrowthGlobal[side]
+	below := state.FeeGrowthOutside[position.Lower][side]
+	if state.Tick < position.Lower {
+		below = computeFeeGrowthDelta(global, below)
+	}
+	above := state.FeeGrowthOutside[position.Upper][side]
+	if state.Tick >= position.Upper {
+		above = computeFeeGrowthDelta(global, above)
+	}
+	inside := new(big.Int).Sub(global, below)
+	return computeFeeGrowthDelta(inside, above)
+}
+
+/**************************************************************************************************
+** computeEarnedFees returns the raw amount of a token earned by some positions between two states
+** of their pool: liquidity * (insideCurrent - insidePast) / 2^128. The positions are assumed to
+** have kept their current range and liquidity over the period.
+**************************************************************************************************/
+func computeEarnedFees(positions []tUniV3Position, current tUniV3PoolState, past tUniV3PoolState, side int) *big.Int {
+	earned := big.NewInt(0)
+	for _, position := range positions {
+		if position.Liquidity == nil {
+			continue
+		}
+		delta := computeFeeGrowthDelta(computeFeeGrowthInside(current, position, side), computeFeeGrowthInside(past, position, side))
+		earned.Add(earned, new(big.Int).Mul(position.Liquidity, delta))
+	}
+	return earned.Div(earned, q128)
+}
+
+/**************************************************************************************************
+** computePositionAmounts returns the raw amounts of the two tokens of a position at the current
+** price of its pool, from its liquidity and the square roots of the prices of its ticks.
+**************************************************************************************************/
+func computePositionAmounts(position tUniV3Position, sqrtPriceX96 *big.Int) [2]*big.Float {
+	amounts := [2]*big.Float{big.NewFloat(0), big.NewFloat(0)}
+	if position.Liquidity == nil || sqrtPriceX96 == nil || sqrtPriceX96.Sign() == 0 {
+		return amounts
+	}
+	liquidity := new(big.Float).SetInt(position.Liquidity)
+	sqrtPrice := new(big.Float).Quo(new(big.Float).SetInt(sqrtPriceX96), new(big.Float).SetInt(q96))
+	sqrtLower := big.NewFloat(math.Pow(1.0001, float64(position.Lower)/2))
+	sqrtUpper := big.NewFloat(math.Pow(1.0001, float64(position.Upper)/2))
+	if sqrtPrice.Cmp(sqrtLower) < 0 {
+		sqrtPrice = sqrtLower
+	}
+	if sqrtPrice.Cmp(sqrtUpper) > 0 {
+		sqrtPrice = sqrtUpper
+	}
+
+	// amount0 = L * (sqrtUpper - sqrtPrice) / (sqrtPrice * sqrtUpper)
+	amounts[0] = new(big.Float).Sub(sqrtUpper, sqrtPrice)
+	amounts[0].Mul(amounts[0], liquidity)
+	amounts[0].Quo(amounts[0], new(big.Float).Mul(sqrtPrice, sqrtUpper))
+	// amount1 = L * (sqrtPrice - sqrtLower)
+	amounts[1] = new(big.Float).Sub(sqrtPrice, sqrtLower)
+	amounts[1].Mul(amounts[1], liquidity)
+	return amounts
+}
+
+/**************************************************************************************************
+** listPositionsTicks returns the ticks bounding some positions, without duplicates.
+**************************************************************************************************/
+func listPositionsTicks(positions []tUniV3Position) []int64 {
+	ticks := []int64{}
+	seen := make(map[int64]bool)
+	for _, position := range positions {
+		for _, tick := range []int64{position.Lower, position.Upper} {
+			if !seen[tick] {
+				seen[tick] = true
+				ticks = append(ticks, tick)
+			}
+		}
+	}
+	return ticks
+}
+
+/**************************************************************************************************
+** getUniV3PoolStateCalls returns the calls reading the state of a Uniswap v3 pool for some ticks.
+**************************************************************************************************/
+func getUniV3PoolStateCalls(poolAddress common.Address, ticks []int64) []ethereum.Call {
+	key := poolAddress.Hex()
+	calls := []ethereum.Call{
+		multicalls.GetUniV3Slot0(key, poolAddress),
+		multicalls.GetUniV3FeeGrowthGlobal0(key, poolAddress),
+		multicalls.GetUniV3FeeGrowthGlobal1(key, poolAddress),
+	}
+	for _, tick := range ticks {
+		calls = append(calls, multicalls.GetUniV3Ticks(key+strconv.FormatInt(tick, 10), poolAddress, tick))
+	}
+	return calls
+}
+
+/**************************************************************************************************
+** decodeUniV3PoolState decodes the state of a Uniswap v3 pool read by getUniV3PoolStateCalls. The
+** boolean is false when any of the values is missing.
+**************************************************************************************************/
+func decodeUniV3PoolState(poolAddress common.Address, ticks []int64, response map[string][]interface{}) (tUniV3PoolState, bool) {
+	key := poolAddress.Hex()
+	state := tUniV3PoolState{FeeGrowthOutside: make(map[int64][2]*big.Int)}
+
+	slot0 := response[key+`slot0`]
+	if len(slot0) < 2 {
+		return state, false
+	}
+	sqrtPriceX96, okPrice := slot0[0].(*big.Int)
+	tick, okTick := slot0[1].(*big.Int)
+	if !okPrice || !okTick {
+		return state, false
+	}
+	state.SqrtPriceX96 = sqrtPriceX96
+	state.Tick = tick.Int64()
+
+	for side, method := range []string{`feeGrowthGlobal0X128`, `feeGrowthGlobal1X128`} {
+		growth := response[key+method]
+		if len(growth) == 0 {
+			return state, false
+		}
+		state.FeeGrowthGlobal[side] = bigNumber.ToInt(helpers.DecodeBigInt(growth))
+	}
+
+	for _, tick := range ticks {
+		tickState := response[key+strconv.FormatInt(tick, 10)+`ticks`]
+		if len(tickState) < 4 {
+			return state, false
+		}
+		outside0, ok0 := tickState[2].(*big.Int)
+		outside1, ok1 := tickState[3].(*big.Int)
+		if !ok0 || !ok1 {
+			return state, false
+		}
+		state.FeeGrowthOutside[tick] = [2]*big.Int{outside0, outside1}
+	}
+	return state, true
+}
+
+/**************************************************************************************************
+** computeUniV3PositionsFees computes the trading fees earned by some positions in a Uniswap v3 pool
+** over the last UNIV3_FEES_APR_PERIOD_DAYS days, annualized, and the value they are earned on: the
+** raw amounts of the two tokens of the pool held, or the amounts of the positions when nil. Both
+** are in USD. The past state of the pool is read on the archive node of the chain. The boolean is
+** false when the past block, a value of the pool (current or past) or a price is missing.
+**************************************************************************************************/
+func computeUniV3PositionsFees(
+	chainID uint64,
+	poolAddress common.Address,
+	positions []tUniV3Position,
+	amounts *[2]*bigNumber.Int,
+) (*bigNumber.Float, *bigNumber.Float, bool) {
+	pastBlock := ethereum.GetBlockNumberByPeriod(chainID, UNIV3_FEES_APR_PERIOD_DAYS)
+	pastTime := ethereum.GetBlockTime(chainID, pastBlock)
+	if pastBlock == 0 || pastTime == 0 || uint64(timeNow().Unix()) <= pastTime {
+		return nil, nil, false
+	}
+	elapsed := uint64(timeNow().Unix()) - pastTime
+
+	key := poolAddress.Hex()
+	ticks := listPositionsTicks(positions)
+	stateCalls := getUniV3PoolStateCalls(poolAddress, ticks)
+	calls := append([]ethereum.Call{
+		multicalls.GetUniV3PoolToken0(key, poolAddress),
+		multicalls.GetUniV3PoolToken1(key, poolAddress),
+	}, stateCalls...)
+	currentResponse := multicalls.Perform(chainID, calls, nil)
+	pastResponse := multicalls.PerformAtPastBlock(chainID, stateCalls, new(big.Int).SetUint64(pastBlock))
+
+	current, ok := decodeUniV3PoolState(poolAddress, ticks, currentResponse)
+	if !ok {
+		return nil, nil, false
+	}
+	past, ok := decodeUniV3PoolState(poolAddress, ticks, pastResponse)
+	if !ok {
+		return nil, nil, false
+	}
+
+	positionsAmounts := [2]*big.Float{big.NewFloat(0), big.NewFloat(0)}
+	for _, position := range positions {
+		positionAmounts := computePositionAmounts(position, current.SqrtPriceX96)
+		positionsAmounts[0].Add(positionsAmounts[0], positionAmounts[0])
+		positionsAmounts[1].Add(positionsAmounts[1], positionAmounts[1])
+	}
+
+	feesValue := bigNumber.NewFloat(0)
+	positionsValue := bigNumber.NewFloat(0)
+	for side, method := range []string{`token0`, `token1`} {
+		token := helpers.DecodeAddress(currentResponse[key+method])
+		erc20, ok := storage.GetERC20(chainID, token)
+		if !ok {
+			return nil, nil, false
+		}
+		tokenPrice, ok := storage.GetPrice(chainID, token)
+		if !ok || tokenPrice.HumanizedPrice == nil {
+			return nil, nil, false
+		}
+
+		amount := bigNumber.NewFloat(0)
+		if amounts == nil {
+			rawAmount, _ := positionsAmounts[side].Int(nil)
+			amount = helpers.ToNormalizedAmount(bigNumber.SetInt(rawAmount), erc20.Decimals)
+		} else if amounts[side] != nil {
+			amount = helpers.ToNormalizedAmount(amounts[side], erc20.Decimals)
+		}
+		positionsValue = bigNumber.NewFloat(0).Add(positionsValue, bigNumber.NewFloat(0).Mul(amount, tokenPrice.HumanizedPrice))
+
+		earned := helpers.ToNormalizedAmount(bigNumber.SetInt(computeEarnedFees(positions, current, past, side)), erc20.Decimals)
+		feesValue = bigNumber.NewFloat(0).Add(feesValue, bigNumber.NewFloat(0).Mul(earned, tokenPrice.HumanizedPrice))
+	}
+	feesValue = bigNumber.NewFloat(0).Mul(feesValue, bigNumber.NewFloat(float64(lendingMarketSecondsPerYear)/float64(elapsed)))
+	return feesValue, positionsValue, true
+}
+
+/**************************************************************************************************
+** computeUniV3PositionsFeesAPR computes the APR of the trading fees earned by some positions in a
+** Uniswap v3 pool, for a holder of these positions whose assets are the raw amounts of the two
+** tokens of the pool (see computeUniV3PositionsFees). The positions out of range earn nothing.
+**************************************************************************************************/
+func computeUniV3PositionsFeesAPR(
+	chainID uint64,
+	poolAddress common.Address,
+	positions []tUniV3Position,
+	amounts *[2]*bigNumber.Int,
+) (*bigNumber.Float, bool) {
+	feesValue, positionsValue, ok := computeUniV3PositionsFees(chainID, poolAddress, positions, amounts)
+	if !ok || positionsValue.IsZero() {
+		return nil, false
+	}
+	return bigNumber.NewFloat(0).Div(feesValue, positionsValue), true
+}
+
+/**************************************************************************************************
+** computeGammaHypervisorFeesAPR computes the APR of the trading fees earned by a Gamma hypervisor
+** from its base and limit positions in its Uniswap v3 pool, over all the assets it holds, idle
+** ones included. It is a gross APR, before the fees of the vault.
+**************************************************************************************************/
+func computeGammaHypervisorFeesAPR(chainID uint64, hypervisor common.Address) (*bigNumber.Float, bool) {
+	key := hypervisor.Hex()
+	calls := []ethereum.Call{
+		multicalls.GetHypervisorPool(key, hypervisor),
+		multicalls.GetHypervisorBaseLower(key, hypervisor),
+		multicalls.GetHypervisorBaseUpper(key, hypervisor),
+		multicalls.GetHypervisorLimitLower(key, hypervisor),
+		multicalls.GetHypervisorLimitUpper(key, hypervisor),
+		multicalls.GetHypervisorBasePosition(key, hypervisor),
+		multicalls.GetHypervisorLimitPosition(key, hypervisor),
+		multicalls.GetHypervisorTotalAmounts(key, hypervisor),
+	}
+	response := multicalls.Perform(chainID, calls, nil)
+	poolAddress := helpers.DecodeAddress(response[key+`pool`])
+	totalAmounts := response[key+`getTotalAmounts`]
+	if poolAddress == (common.Address{}) || len(totalAmounts) < 2 {
+		return nil, false
+	}
+
+	positions := []tUniV3Position{
+		{
+			Lower:     helpers.DecodeBigInt(response[key+`baseLower`]).Int64(),
+			Upper:     helpers.DecodeBigInt(response[key+`baseUpper`]).Int64(),
+			Liquidity: bigNumber.ToInt(helpers.DecodeBigInt(response[key+`getBasePosition`])),
+		},
+		{
+			Lower:     helpers.DecodeBigInt(response[key+`limitLower`]).Int64(),
+			Upper:     helpers.DecodeBigInt(response[key+`limitUpper`]).Int64(),
+			Liquidity: bigNumber.ToInt(helpers.DecodeBigInt(response[key+`getLimitPosition`])),
+		},
+	}
+	amounts := [2]*bigNumber.Int{
+		helpers.DecodeBigInt(totalAmounts[0:1]),
+		helpers.DecodeBigInt(totalAmounts[1:2]),
+	}
+	return computeUniV3PositionsFeesAPR(chainID, poolAddress, positions, &amounts)
+}
+
+/**************************************************************************************************
+** discoverUniV3Ranges finds the strategies of a chain holding positions of the Uniswap v3 position
+** manager of the chain, with the pool of each position. The positions without liquidity are
+** skipped, and at most UNIV3_MAX_POSITIONS_PER_STRATEGY positions are read per strategy.
+**************************************************************************************************/
+func discoverUniV3Ranges(chainID uint64, positionManager common.Address) map[common.Address][]tUniV3Range {
+	strategies := []common.Address{}
+	_, allStrategies := storage.ListStrategies(chainID)
+	for _, strategy := range allStrategies {
+		if !strategy.IsRetired {
+			strategies = append(strategies, strategy.Address)
+		}
+	}
+	if len(strategies) == 0 {
+		return nil
+	}
+
+	managerKey := positionManager.Hex()
+	calls := []ethereum.Call{multicalls.GetUniV3PositionManagerFactory(managerKey, positionManager)}
+	for _, strategy := range strategies {
+		calls = append(calls, multicalls.GetBalanceOf(strategy.Hex(), positionManager, strategy))
+	}
+	response := multicalls.Perform(chainID, calls, nil)
+	factory := helpers.DecodeAddress(response[managerKey+`factory`])
+	if (factory == common.Address{}) {
+		return nil
+	}
+
+	calls = []ethereum.Call{}
+	for _, strategy := range strategies {
+		balance := helpers.DecodeBigInt(response[strategy.Hex()+`balanceOf`]).Int64()
+		for index := int64(0); index < balance && index < UNIV3_MAX_POSITIONS_PER_STRATEGY; index++ {
+			calls = append(calls, multicalls.GetUniV3TokenOfOwnerByIndex(strategy.Hex()+strconv.FormatInt(index, 10), positionManager, strategy, index))
+		}
+	}
+	if len(calls) == 0 {
+		return nil
+	}
+	response = multicalls.Perform(chainID, calls, nil)
+
+	type tPositionID struct {
+		strategy common.Address
+		tokenID  *big.Int
+	}
+	positionIDs := []tPositionID{}
+	calls = []ethereum.Call{}
+	for _, strategy := range strategies {
+		for index := int64(0); index < UNIV3_MAX_POSITIONS_PER_STRATEGY; index++ {
+			rawTokenID := response[strategy.Hex()+strconv.FormatInt(index, 10)+`tokenOfOwnerByIndex`]
+			if len(rawTokenID) == 0 {
+				break
+			}
+			tokenID := bigNumber.ToInt(helpers.DecodeBigInt(rawTokenID))
+			positionIDs = append(positionIDs, tPositionID{strategy: strategy, tokenID: tokenID})
+			calls = append(calls, multicalls.GetUniV3Position(tokenID.String(), positionManager, tokenID))
+		}
+	}
+	response = multicalls.Perform(chainID, calls, nil)
+
+	type tPositionData struct {
+		strategy common.Address
+		poolKey  string
+		position tUniV3Position
+	}
+	positionsData := []tPositionData{}
+	calls = []ethereum.Call{}
+	pools := make(map[string]bool)
+	for _, positionID := range positionIDs {
+		position := response[positionID.tokenID.String()+`positions`]
+		if len(position) < 8 {
+			continue
+		}
+		token0, ok0 := position[2].(common.Address)
+		token1, ok1 := position[3].(common.Address)
+		fee, okFee := position[4].(*big.Int)
+		lower, okLower := position[5].(*big.Int)
+		upper, okUpper := position[6].(*big.Int)
+		liquidity, okLiquidity := position[7].(*big.Int)
+		if !ok0 || !ok1 || !okFee || !okLower || !okUpper || !okLiquidity || liquidity.Sign() == 0 {
+			continue
+		}
+		poolKey := token0.Hex() + token1.Hex() + fee.String()
+		if !pools[poolKey] {
+			pools[poolKey] = true
+			calls = append(calls, multicalls.GetUniV3FactoryPool(poolKey, factory, token0, token1, fee))
+		}
+		positionsData = append(positionsData, tPositionData{
+			strategy: positionID.strategy,
+			poolKey:  poolKey,
+			position: tUniV3Position{Lower: lower.Int64(), Upper: upper.Int64(), Liquidity: liquidity},
+		})
+	}
+	if len(calls) == 0 {
+		return nil
+	}
+	response = multicalls.Perform(chainID, calls, nil)
+
+	ranges := make(map[common.Address][]tUniV3Range)
+	for _, positionData := range positionsData {
+		pool := helpers.DecodeAddress(response[positionData.poolKey+`getPool`])
+		if (pool == common.Address{}) {
+			continue
+		}
+		ranges[positionData.strategy] = append(ranges[positionData.strategy], tUniV3Range{Pool: pool, Position: positionData.position})
+	}
+	return ranges
+}
+
+/**************************************************************************************************
+** computeUniV3RangesFeesAPR computes the gross APR of the trading fees earned by the positions of
+** a strategy, over the value of these positions, pool by pool.
+**************************************************************************************************/
+func computeUniV3RangesFeesAPR(chainID uint64, ranges []tUniV3Range) (*bigNumber.Float, bool) {
+	positionsByPool := make(map[common.Address][]tUniV3Position)
+	for _, uniV3Range := range ranges {
+		positionsByPool[uniV3Range.Pool] = append(positionsByPool[uniV3Range.Pool], uniV3Range.Position)
+	}
+
+	feesValue := bigNumber.NewFloat(0)
+	positionsValue := bigNumber.NewFloat(0)
+	for pool, positions := range positionsByPool {
+		poolFeesValue, poolPositionsValue, ok := computeUniV3PositionsFees(chainID, pool, positions, nil)
+		if !ok {
+			return nil, false
+		}
+		feesValue = bigNumber.NewFloat(0).Add(feesValue, poolFeesValue)
+		positionsValue = bigNumber.NewFloat(0).Add(positionsValue, poolPositionsValue)
+	}
+	if positionsValue.IsZero() {
+		return nil, false
+	}
+	return bigNumber.NewFloat(0).Div(feesValue, positionsValue), true
+}
+
+/**************************************************************************************************
+** For a given vault whose strategies provide liquidity in Uniswap v3 ranges, we will calculate the
+** APR of each strategy from the fees APR of its positions, weighted by its debt ratio, as for the
+** Gamma strategies. The gross fees APR is detailed as the PoolAPY of the composite, and the
+** incentives of the staking contract of the vault as its RewardsAPY.
+**************************************************************************************************/
+func computeUniV3ForwardAPY(
+	vault models.TVault,
+	allStrategiesForVault map[string]models.TStrategy,
+	ranges map[common.Address][]tUniV3Range,
+	stakingRewardsAPY *bigNumber.Float,
+) (TForwardAPY, bool) {
+	netAPY := bigNumber.NewFloat(0)
+	poolAPY := bigNumber.NewFloat(0)
+	hasRanges := false
+	for _, strategy := range allStrategiesForVault {
+		strategyRanges, ok := ranges[strategy.Address]
+		if !ok || strategy.LastDebtRatio == nil || strategy.LastDebtRatio.IsZero() {
+			continue
+		}
+		grossAPR, ok := computeUniV3RangesFeesAPR(vault.ChainID, strategyRanges)
+		recordAPRSourceValue(vault.ChainID, APR_SOURCE_UNIV3_FEES, strategy.Address.Hex(), grossAPR)
+		if !ok {
+			continue
+		}
+		hasRanges = true
+		debtRatio := helpers.ToNormalizedAmount(strategy.LastDebtRatio, 4)
+		_, strategyAPY := calculateGammaStrategyAPY(vault, strategy, grossAPR)
+		netAPY = bigNumber.NewFloat(0).Add(netAPY, strategyAPY)
+		poolAPY = bigNumber.NewFloat(0).Add(poolAPY, bigNumber.NewFloat(0).Mul(grossAPR, debtRatio))
+	}
+	if !hasRanges {
+		return TForwardAPY{}, false
+	}
+
+	rewardsAPY := bigNumber.NewFloat(0)
+	if stakingRewardsAPY != nil {
+		rewardsAPY = stakingRewardsAPY
+	}
+	return TForwardAPY{
+		Type:   `univ3`,
+		NetAPY: netAPY,
+		Composite: TCompositeData{
+			Boost:      bigNumber.NewFloat(0),
+			PoolAPY:    poolAPY,
+			BoostedAPR: bigNumber.NewFloat(0),
+			BaseAPR:    bigNumber.NewFloat(0),
+			CvxAPR:     bigNumber.NewFloat(0),
+			RewardsAPY: rewardsAPY,
+			KeepCRV:    bigNumber.NewFloat(0),
+			KeepVelo:   bigNumber.NewFloat(0),
+		},
+	}, true
+}
@@ -0,0 +1,103 @@
+package apr
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestComputeFeeGrowthDelta(t *testing.T) {
+	if delta := computeFeeGrowthDelta(big.NewInt(150), big.NewInt(100)); delta.Cmp(big.NewInt(50)) != 0 {
+		t.Errorf("expected a delta of 50, got %s", delta)
+	}
+
+	// The counter overflowed between the two blocks
+	past := new(big.Int).Sub(q256, big.NewInt(10))
+	if delta := computeFeeGrowthDelta(big.NewInt(5), past); delta.Cmp(big.NewInt(15)) != 0 {
+		t.Errorf("expected a delta of 15 across the overflow, got %s", delta)
+	}
+}
+
+func TestComputeFeeGrowthInside(t *testing.T) {
+	position := tUniV3Position{Lower: -100, Upper: 100}
+	state := tUniV3PoolState{
+		FeeGrowthGlobal: [2]*big.Int{big.NewInt(1000), big.NewInt(0)},
+		FeeGrowthOutside: map[int64][2]*big.Int{
+			-100: {big.NewInt(100), big.NewInt(0)},
+			100:  {big.NewInt(300), big.NewInt(0)},
+		},
+	}
+
+	// In range: global - outside below the lower tick - outside above the upper tick
+	state.Tick = 0
+	if inside := computeFeeGrowthInside(state, position, 0); inside.Cmp(big.NewInt(600)) != 0 {
+		t.Errorf("expected a growth inside of 600 in range, got %s", inside)
+	}
+
+	// Below the range, the growth below the lower tick is the global one minus its outside one,
+	// and the growth inside is taken modulo 2^256: 1000 - 900 - 300
+	state.Tick = -200
+	expected := new(big.Int).Sub(q256, big.NewInt(200))
+	if inside := computeFeeGrowthInside(state, position, 0); inside.Cmp(expected) != 0 {
+		t.Errorf("expected a growth inside of 2^256 - 200 below the range, got %s", inside)
+	}
+}
+
+func TestComputeEarnedFees(t *testing.T) {
+	positions := []tUniV3Position{
+		{Lower: -100, Upper: 100, Liquidity: big.NewInt(1000)},
+		{Lower: 100, Upper: 200, Liquidity: big.NewInt(500)},
+		{Lower: -50, Upper: 50},
+	}
+	past := tUniV3PoolState{
+		Tick:            0,
+		FeeGrowthGlobal: [2]*big.Int{big.NewInt(0), big.NewInt(0)},
+		FeeGrowthOutside: map[int64][2]*big.Int{
+			-100: {big.NewInt(0), big.NewInt(0)},
+			100:  {big.NewInt(0), big.NewInt(0)},
+			200:  {big.NewInt(0), big.NewInt(0)},
+		},
+	}
+	current := tUniV3PoolState{
+		Tick:            0,
+		FeeGrowthGlobal: [2]*big.Int{new(big.Int).Mul(big.NewInt(3), q128), big.NewInt(0)},
+		FeeGrowthOutside: map[int64][2]*big.Int{
+			-100: {big.NewInt(0), big.NewInt(0)},
+			100:  {big.NewInt(0), big.NewInt(0)},
+			200:  {big.NewInt(0), big.NewInt(0)},
+		},
+	}
+
+	// All the fees were earned at the current tick, inside the first position only
+	if earned := computeEarnedFees(positions, current, past, 0); earned.Cmp(big.NewInt(3000)) != 0 {
+		t.Errorf("expected only the first position to earn 3000, got %s", earned)
+	}
+	if earned := computeEarnedFees(positions, current, past, 1); earned.Sign() != 0 {
+		t.Errorf("expected nothing earned in token1, got %s", earned)
+	}
+
+	// The fees were earned above the first position, inside the second one
+	current.Tick = 150
+	current.FeeGrowthOutside[100] = [2]*big.Int{big.NewInt(0), big.NewInt(0)}
+	if earned := computeEarnedFees(positions, current, past, 0); earned.Cmp(big.NewInt(1500)) != 0 {
+		t.Errorf("expected only the second position to earn 1500, got %s", earned)
+	}
+}
+
+func TestComputePositionAmounts(t *testing.T) {
+	position := tUniV3Position{Lower: -1000, Upper: 1000, Liquidity: big.NewInt(1e18)}
+
+	// At a price of 1, in the middle of a symmetric range, the position holds both tokens equally
+	amounts := computePositionAmounts(position, q96)
+	amount0, _ := amounts[0].Float64()
+	amount1, _ := amounts[1].Float64()
+	if amount0 <= 0 || math.Abs(amount0-amount1)/amount1 > 1e-9 {
+		t.Errorf("expected equal amounts, got %f and %f", amount0, amount1)
+	}
+
+	// Below the range, the position only holds token0
+	amounts = computePositionAmounts(position, new(big.Int).Div(q96, big.NewInt(2)))
+	if amounts[0].Sign() <= 0 || amounts[1].Sign() != 0 {
+		t.Errorf("expected only token0 below the range, got %s and %s", amounts[0], amounts[1])
+	}
+}
@@ -2,13 +2,14 @@ package apr
 
 import (
 	"math/big"
+	"sort"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/addresses"
 	"github.com/yearn/ydaemon/common/bigNumber"
 	"github.com/yearn/ydaemon/common/contracts"
 	"github.com/yearn/ydaemon/common/env"
-	"github.com/yearn/ydaemon/common/ethereum"
 	"github.com/yearn/ydaemon/common/helpers"
 	"github.com/yearn/ydaemon/common/logs"
 	"github.com/yearn/ydaemon/internal/models"
@@ -24,18 +25,17 @@ func computeVaultV3ForwardAPY(
 	allStrategiesForVault map[string]models.TStrategy,
 ) TForwardAPY {
 	oracleAPR := bigNumber.NewFloat(0)
-	chain, ok := env.GetChain(vault.ChainID)
+	oracle, isFallback, ok := getChainAPROracle(vault.ChainID)
 	if !ok {
 		return TForwardAPY{}
 	}
-	oracleContract := chain.APROracleContract.Address
-	if oracleContract == common.HexToAddress(``) {
-		return TForwardAPY{}
-	}
-	oracle, err := contracts.NewYVaultsV3APROracleCaller(oracleContract, ethereum.GetRPC(vault.ChainID))
-	if err != nil {
-		logs.Error(err)
-		return TForwardAPY{}
+
+	/**********************************************************************************************
+	** Without APR oracle on the chain, the APR of the vault is the one of its strategies weighted
+	** by their debt ratio, the strategies being read from the fallback lens of the chain.
+	**********************************************************************************************/
+	if isFallback {
+		return computeVaultV3FallbackForwardAPY(oracle, vault, allStrategiesForVault)
 	}
 
 	/**********************************************************************************************
@@ -44,26 +44,229 @@ func computeVaultV3ForwardAPY(
 	** - Multi-strategy vaults: Returns weighted average with performance fees applied
 	**********************************************************************************************/
 	expected, err := oracle.GetStrategyApr(nil, vault.Address, big.NewInt(0))
-	if err != nil {
+	if err == nil {
+		oracleAPR = helpers.ToNormalizedAmount(bigNumber.SetInt(expected), 18)
+	}
+	aprType := `v3:onchainOracle`
+
+	/**********************************************************************************************
+	** A single strategy vault lending in a known market can still get an APR when the oracle has
+	** no adapter for it, from the rate model of the market, net of the fee of the vault like the
+	** oracle APR.
+	**********************************************************************************************/
+	oracleAPRFloat64, _ := oracleAPR.Float64()
+	performanceFee := float64(vault.PerformanceFee) / 10000
+	if lendingAPR, isFallback := resolveLendingMarketAPR(vault.ChainID, vault.Address, oracleAPRFloat64, err == nil, performanceFee); isFallback {
+		oracleAPR = bigNumber.NewFloat(lendingAPR * (1 - performanceFee))
+		aprType = `v3:lendingMarket`
+	} else if err != nil {
 		logs.Error(`GetStrategyApr failed for vault ` + vault.Address.Hex() + `: ` + err.Error())
 		return TForwardAPY{}
 	}
-	oracleAPR = helpers.ToNormalizedAmount(bigNumber.SetInt(expected), 18)
 
 	/**********************************************************************************************
-	** Use the oracle APR as the primary APR (no manual calculation needed)
+	** A vault without any assets has a 0% APR from the oracle. To still display the APR the
+	** depositors can expect, the potential APR of the strategies is used, following the policy
+	** set in the vault metadata.
 	**********************************************************************************************/
-	primaryAPR := oracleAPR
+	if oracleAPR.IsZero() && (vault.LastTotalAssets == nil || vault.LastTotalAssets.IsZero()) {
+		if potentialAPR, ok := computeZeroAssetsPotentialAPR(oracle, vault, allStrategiesForVault); ok {
+			oracleAPR = potentialAPR
+			aprType = `v3:onchainOracle:` + string(getZeroAssetsAPRPolicy(vault))
+		}
+	}
 
-	primaryAPRFloat64, _ := primaryAPR.Float64()
-	primaryAPY := bigNumber.NewFloat(0).SetFloat64(convertFloatAPRToAPY(primaryAPRFloat64, 52))
+	/**********************************************************************************************
+	** The oracle APR is the primary APR, unless the vault, its category or its chain asks for the
+	** APR of the strategies weighted by their debt ratio. The APRs compound weekly, or as often
+	** as the strategies are tended.
+	**********************************************************************************************/
+	compoundingPeriods := computeForwardCompoundingPeriods(vault, allStrategiesForVault)
+	oracleAPRFloat64, _ = oracleAPR.Float64()
+	oracleAPY := bigNumber.NewFloat(0).SetFloat64(convertFloatAPRToAPY(oracleAPRFloat64, compoundingPeriods))
+	debtRatioAPY := bigNumber.NewFloat(0)
+	if debtRatioAPR, ok := computeDebtRatioAPR(oracle, vault, allStrategiesForVault); ok {
+		debtRatioAPRFloat64, _ := debtRatioAPR.Float64()
+		debtRatioAPY = bigNumber.NewFloat(0).SetFloat64(convertFloatAPRToAPY(debtRatioAPRFloat64, compoundingPeriods))
+	}
+
+	primaryAPY := oracleAPY
+	primarySource := models.APRPrimarySourceOracle
+	if shouldUseV2APR(vault) {
+		primaryAPY = debtRatioAPY
+		primarySource = models.APRPrimarySourceDebtRatio
+	}
+
+	/**********************************************************************************************
+	** The oracle does not know about the overridden APRs. An override of the vault replaces its
+	** APR, and the overrides of its strategies are only accounted for by the debt ratio APR.
+	**********************************************************************************************/
+	aprOverrides := listVaultAPROverrides(vault, allStrategiesForVault)
+	if len(aprOverrides) > 0 && addresses.Equals(aprOverrides[0].Address, vault.Address) {
+		primaryAPY = bigNumber.NewFloat(0).SetFloat64(convertFloatAPRToAPY(aprOverrides[0].APR, compoundingPeriods))
+		primarySource = models.APRPrimarySourceOverride
+		aprType = `v3:override`
+	} else if len(aprOverrides) > 0 && !debtRatioAPY.IsZero() {
+		primaryAPY = debtRatioAPY
+		primarySource = models.APRPrimarySourceDebtRatio
+	}
+
+	/**********************************************************************************************
+	** For the vaults lending in known markets, the composite data details the supply APR and the
+	** rewards of the markets.
+	**********************************************************************************************/
+	composite, _ := computeLendingMarketComposite(vault, allStrategiesForVault)
+	composite.V3OracleCurrentAPR = oracleAPY
+	composite.V3OracleStratRatioAPR = debtRatioAPY
+	composite.APROverrides = aprOverrides
 
 	return TForwardAPY{
-		Type:   `v3:onchainOracle`,
-		NetAPY: primaryAPY,
-		Composite: TCompositeData{
-			V3OracleCurrentAPR:    primaryAPY,
-			V3OracleStratRatioAPR: bigNumber.NewFloat(0),
-		},
+		Type:               aprType,
+		NetAPY:             primaryAPY,
+		PrimarySource:      primarySource,
+		CompoundingPeriods: exposedCompoundingPeriods(compoundingPeriods),
+		Composite:          composite,
+	}
+}
+
+/**************************************************************************************************
+** shouldUseV2APR resolves whether the APR of the strategies weighted by their debt ratio should be
+** the primary APR of a v3 vault. The override of the vault takes precedence over its flag, which
+** can only opt the vault in, then over the category default and the chain default.
+**************************************************************************************************/
+func shouldUseV2APR(vault models.TVault) bool {
+	if vault.Metadata.V2APROverride != nil {
+		return *vault.Metadata.V2APROverride
+	}
+	if vault.Metadata.ShouldUseV2APR {
+		return true
+	}
+	chain, ok := env.GetChain(vault.ChainID)
+	if !ok {
+		return false
+	}
+	if shouldUse, ok := chain.APRPolicy.ShouldUseV2APRByCategory[vault.Metadata.Category]; ok {
+		return shouldUse
+	}
+	return chain.APRPolicy.ShouldUseV2APR
+}
+
+/**************************************************************************************************
+** computeDebtRatioAPR computes the APR of a vault as the sum of the APRs of its active strategies
+** weighted by their current debt ratio, each minus the performance fee charged by the accountant
+** of the vault on its gains, unless overridden (see getStrategyNetAPR). The funds not allocated to
+** any strategy do not earn anything.
+**************************************************************************************************/
+func computeDebtRatioAPR(
+	oracle *contracts.YVaultsV3APROracleCaller,
+	vault models.TVault,
+	allStrategiesForVault map[string]models.TStrategy,
+) (*bigNumber.Float, bool) {
+	weightedAPR := 0.0
+	hasDebt := false
+	for _, strategy := range allStrategiesForVault {
+		if strategy.IsRetired || strategy.LastDebtRatio == nil || strategy.LastDebtRatio.IsZero() {
+			continue
+		}
+		strategyAPR, ok := getStrategyNetAPR(oracle, vault, strategy)
+		if !ok {
+			continue
+		}
+		debtRatio, _ := strategy.LastDebtRatio.Float64()
+		weightedAPR += strategyAPR * debtRatio / 10000
+		hasDebt = true
+	}
+	if !hasDebt {
+		return nil, false
+	}
+	return bigNumber.NewFloat(weightedAPR), true
+}
+
+/**************************************************************************************************
+** getZeroAssetsAPRPolicy returns the policy to use for a vault without assets, defaulting to the
+** target debt ratio weighting.
+**************************************************************************************************/
+func getZeroAssetsAPRPolicy(vault models.TVault) models.TZeroAssetsAPRPolicy {
+	switch vault.Metadata.ZeroAssetsAPRPolicy {
+	case models.ZeroAssetsAPRPolicyAverage, models.ZeroAssetsAPRPolicyNone:
+		return vault.Metadata.ZeroAssetsAPRPolicy
+	default:
+		return models.ZeroAssetsAPRPolicyTargetDebtRatio
+	}
+}
+
+/**************************************************************************************************
+** getStrategyTargetDebtRatio returns the target debt ratio of a strategy in basis points, from the
+** allocator data provided by Kong when available, or from the last known debt ratio otherwise.
+** Both are weights of the same sum, so they must share the unit: Kong exposes the target ratio of
+** the debt allocator (getStrategyTargetRatio) as is, in basis points like the debt ratio, so it is
+** never rescaled, a 1 bps target staying 1 bps.
+**************************************************************************************************/
+func getStrategyTargetDebtRatio(vault models.TVault, strategy models.TStrategy) float64 {
+	for _, debt := range vault.Debts {
+		if addresses.Equals(common.HexToAddress(debt.Strategy), strategy.Address) && debt.TargetDebtRatio != nil {
+			return *debt.TargetDebtRatio
+		}
+	}
+	if strategy.LastDebtRatio != nil {
+		debtRatio, _ := strategy.LastDebtRatio.Float64()
+		return debtRatio
+	}
+	return 0
+}
+
+/**************************************************************************************************
+** computeZeroAssetsPotentialAPR computes the APR a vault without assets would get, based on the
+** APR of its strategies, net of the fees like the one of a vault with assets. The strategies are
+** processed in a deterministic order (by address) and only the queued ones are considered. Depending on the policy, the APRs are either weighted by
+** the target debt ratio of each strategy or simply averaged. If no strategy has a target debt
+** ratio, the average is used.
+**************************************************************************************************/
+func computeZeroAssetsPotentialAPR(
+	oracle *contracts.YVaultsV3APROracleCaller,
+	vault models.TVault,
+	allStrategiesForVault map[string]models.TStrategy,
+) (*bigNumber.Float, bool) {
+	policy := getZeroAssetsAPRPolicy(vault)
+	if policy == models.ZeroAssetsAPRPolicyNone {
+		return nil, false
+	}
+
+	strategies := []models.TStrategy{}
+	for _, strategy := range allStrategiesForVault {
+		if strategy.IsRetired || !strategy.IsInQueue {
+			continue
+		}
+		strategies = append(strategies, strategy)
+	}
+	if len(strategies) == 0 {
+		return nil, false
+	}
+	sort.Slice(strategies, func(i, j int) bool {
+		return strategies[i].Address.Hex() < strategies[j].Address.Hex()
+	})
+
+	sumAPR := 0.0
+	sumWeightedAPR := 0.0
+	sumWeights := 0.0
+	count := 0.0
+	for _, strategy := range strategies {
+		strategyAPR, ok := getStrategyNetAPR(oracle, vault, strategy)
+		if !ok {
+			continue
+		}
+		weight := getStrategyTargetDebtRatio(vault, strategy)
+		sumAPR += strategyAPR
+		sumWeightedAPR += strategyAPR * weight
+		sumWeights += weight
+		count++
+	}
+	if count == 0 {
+		return nil, false
+	}
+
+	if policy == models.ZeroAssetsAPRPolicyTargetDebtRatio && sumWeights > 0 {
+		return bigNumber.NewFloat(sumWeightedAPR / sumWeights), true
 	}
+	return bigNumber.NewFloat(sumAPR / count), true
 }
@@ -0,0 +1,146 @@
+package apr
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	goethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/contracts"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** TestGetStrategyTargetDebtRatio checks that the target debt ratios from Kong are read in basis
+** points, the small ones included, like the last debt ratio used when there is no target.
+**************************************************************************************************/
+func TestGetStrategyTargetDebtRatio(t *testing.T) {
+	tests := []struct {
+		name            string
+		targetDebtRatio *float64
+		lastDebtRatio   *bigNumber.Int
+		expected        float64
+	}{
+		{name: "regular target", targetDebtRatio: floatOf(7500), expected: 7500},
+		{name: "1 bps target", targetDebtRatio: floatOf(1), expected: 1},
+		{name: "fractional bps target", targetDebtRatio: floatOf(0.5), expected: 0.5},
+		{name: "zero target", targetDebtRatio: floatOf(0), lastDebtRatio: bigNumber.NewInt(5000), expected: 0},
+		{name: "no target", lastDebtRatio: bigNumber.NewInt(5000), expected: 5000},
+		{name: "nothing known", expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := models.TStrategy{Address: common.HexToAddress(`0x1`), LastDebtRatio: tt.lastDebtRatio}
+			vault := models.TVault{Debts: []models.TKongDebt{
+				{Strategy: `0x0000000000000000000000000000000000000001`, TargetDebtRatio: tt.targetDebtRatio},
+			}}
+			if ratio := getStrategyTargetDebtRatio(vault, strategy); ratio != tt.expected {
+				t.Errorf("expected a target debt ratio of %v bps, got %v", tt.expected, ratio)
+			}
+		})
+	}
+}
+
+func floatOf(value float64) *float64 {
+	return &value
+}
+
+/**************************************************************************************************
+** TestComputeDebtRatioAPRWithOverride checks that the overridden APR of a strategy, already net of
+** all the fees, is not charged the performance fee of the vault again.
+**************************************************************************************************/
+func TestComputeDebtRatioAPRWithOverride(t *testing.T) {
+	strategy := models.TStrategy{ChainID: 1, Address: common.HexToAddress(`0x01`), LastDebtRatio: bigNumber.NewInt(5000)}
+	RegisterStrategyAPRComputer(1, strategy.Address, `test`, func(models.TStrategy) (float64, bool) {
+		return 0.04, true
+	})
+	defer func() {
+		strategyAPROverridesMtx.Lock()
+		delete(strategyAPRPlugins[1], strings.ToLower(strategy.Address.Hex()))
+		strategyAPROverridesMtx.Unlock()
+	}()
+
+	vault := models.TVault{ChainID: 1, Address: common.HexToAddress(`0x02`), PerformanceFee: 1000}
+	apr, ok := computeDebtRatioAPR(nil, vault, map[string]models.TStrategy{strategy.Address.Hex(): strategy})
+	if !ok {
+		t.Fatalf("expected a debt ratio APR")
+	}
+	if aprFloat, _ := apr.Float64(); math.Abs(aprFloat-0.02) > 1e-12 {
+		t.Errorf("expected the overridden APR weighted by the debt ratio, 0.02, got %v", aprFloat)
+	}
+}
+
+/**************************************************************************************************
+** fakeAPROracle answers the getStrategyApr calls of the APR oracle with the APR of each strategy,
+** scaled to 1e18 like the oracle does.
+**************************************************************************************************/
+type fakeAPROracle struct {
+	aprs map[common.Address]float64
+}
+
+func (f fakeAPROracle) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x01}, nil
+}
+
+func (f fakeAPROracle) CallContract(ctx context.Context, call goethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	strategy := common.BytesToAddress(call.Data[4:36])
+	apr, ok := f.aprs[strategy]
+	if !ok {
+		return nil, errors.New(`execution reverted`)
+	}
+	scaled, _ := new(big.Float).Mul(big.NewFloat(apr), big.NewFloat(1e18)).Int(nil)
+	return common.LeftPadBytes(scaled.Bytes(), 32), nil
+}
+
+/**************************************************************************************************
+** TestComputeZeroAssetsPotentialAPR checks that the potential APR of a vault without assets is
+** net of the performance fee of the vault, like the APR of a vault with assets, for each policy.
+**************************************************************************************************/
+func TestComputeZeroAssetsPotentialAPR(t *testing.T) {
+	first := models.TStrategy{ChainID: 1, Address: common.HexToAddress(`0x11`), IsInQueue: true, LastDebtRatio: bigNumber.NewInt(7500)}
+	second := models.TStrategy{ChainID: 1, Address: common.HexToAddress(`0x12`), IsInQueue: true, LastDebtRatio: bigNumber.NewInt(2500)}
+	retired := models.TStrategy{ChainID: 1, Address: common.HexToAddress(`0x13`), IsInQueue: true, IsRetired: true, LastDebtRatio: bigNumber.NewInt(5000)}
+	strategies := map[string]models.TStrategy{first.Address.Hex(): first, second.Address.Hex(): second, retired.Address.Hex(): retired}
+	oracle, err := contracts.NewYVaultsV3APROracleCaller(common.HexToAddress(`0x10`), fakeAPROracle{aprs: map[common.Address]float64{
+		first.Address:   0.10,
+		second.Address:  0.20,
+		retired.Address: 0.50,
+	}})
+	if err != nil {
+		t.Fatalf("Failed to bind the APR oracle: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		policy         models.TZeroAssetsAPRPolicy
+		performanceFee uint64
+		expectedOK     bool
+		expected       float64
+	}{
+		{name: "target debt ratio without fee", policy: models.ZeroAssetsAPRPolicyTargetDebtRatio, expectedOK: true, expected: 0.125},
+		{name: "target debt ratio with a 10% fee", policy: models.ZeroAssetsAPRPolicyTargetDebtRatio, performanceFee: 1000, expectedOK: true, expected: 0.1125},
+		{name: "average with a 10% fee", policy: models.ZeroAssetsAPRPolicyAverage, performanceFee: 1000, expectedOK: true, expected: 0.135},
+		{name: "none", policy: models.ZeroAssetsAPRPolicyNone, performanceFee: 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vault := models.TVault{ChainID: 1, Address: common.HexToAddress(`0x14`), PerformanceFee: tt.performanceFee}
+			vault.Metadata.ZeroAssetsAPRPolicy = tt.policy
+			apr, ok := computeZeroAssetsPotentialAPR(oracle, vault, strategies)
+			if ok != tt.expectedOK {
+				t.Fatalf("expected a potential APR: %v, got %v", tt.expectedOK, ok)
+			}
+			if !ok {
+				return
+			}
+			if aprFloat, _ := apr.Float64(); math.Abs(aprFloat-tt.expected) > 1e-12 {
+				t.Errorf("expected a potential APR of %v, got %v", tt.expected, aprFloat)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package apr
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The trading fees of a Velodrome v2 or Aerodrome pool are measured over the last
+** VELO_FEES_APR_PERIOD_DAYS days, one epoch of the gauges.
+**************************************************************************************************/
+const VELO_FEES_APR_PERIOD_DAYS = 7
+
+/**************************************************************************************************
+** computeVeloGaugeEmissionsAPR computes the APR of the VELO/AERO emitted by the gauge of a pool to
+** the liquidity staked in it: rewardRate * rewardPrice * SECONDS_PER_YEAR / (staked * poolPrice).
+** It is a gross APR, before the fees of the vault. The boolean is false when a price is missing.
+**************************************************************************************************/
+func computeVeloGaugeEmissionsAPR(chainID uint64, poolAddress common.Address, gaugeAddress common.Address) (*bigNumber.Float, bool) {
+	calls := []ethereum.Call{
+		multicalls.GetPeriodFinish(gaugeAddress.Hex(), gaugeAddress),
+		multicalls.GetRewardRate(gaugeAddress.Hex(), gaugeAddress),
+		multicalls.GetTotalSupply(gaugeAddress.Hex(), gaugeAddress),
+		multicalls.GetRewardToken(gaugeAddress.Hex(), gaugeAddress),
+	}
+	response := multicalls.Perform(chainID, calls, nil)
+	periodFinish := helpers.DecodeBigInt(response[gaugeAddress.Hex()+`periodFinish`])
+	rewardRateRaw := helpers.DecodeBigInt(response[gaugeAddress.Hex()+`rewardRate`])
+	stakedRaw := helpers.DecodeBigInt(response[gaugeAddress.Hex()+`totalSupply`])
+	rewardToken := helpers.DecodeAddress(response[gaugeAddress.Hex()+`rewardToken`])
+
+	if periodFinish.Int64() < timeNow().Unix() || rewardRateRaw.IsZero() || stakedRaw.IsZero() {
+		return bigNumber.NewFloat(0), true
+	}
+	normalizedRewardRate, okRate := normalizeTokenAmount(chainID, rewardToken, rewardRateRaw, `gauge reward rate`)
+	normalizedStaked, okStaked := normalizeTokenAmount(chainID, poolAddress, stakedRaw, `gauge supply`)
+	if !okRate || !okStaked {
+		return nil, false
+	}
+	rewardRate, staked := normalizedRewardRate.Float(), normalizedStaked.Float()
+	poolPrice, ok := storage.GetPrice(chainID, poolAddress)
+	if !ok || poolPrice.HumanizedPrice == nil || poolPrice.HumanizedPrice.IsZero() {
+		return nil, false
+	}
+	rewardPrice, ok := storage.GetPrice(chainID, rewardToken)
+	if !ok || rewardPrice.HumanizedPrice == nil {
+		return nil, false
+	}
+
+	yearlyRewards := bigNumber.NewFloat(0).Mul(rewardRate, rewardPrice.HumanizedPrice)
+	yearlyRewards = bigNumber.NewFloat(0).Mul(yearlyRewards, bigNumber.NewFloat(float64(lendingMarketSecondsPerYear)))
+	stakedValue := bigNumber.NewFloat(0).Mul(staked, poolPrice.HumanizedPrice)
+	return bigNumber.NewFloat(0).Div(yearlyRewards, stakedValue), true
+}
+
+/**************************************************************************************************
+** computeVeloPoolFeesAPR computes the APR of the trading fees of a Velodrome v2 or Aerodrome pool,
+** from the growth of its fee indexes (the fees per unit of liquidity, in each token) over the last
+** VELO_FEES_APR_PERIOD_DAYS days. The boolean is false when the past block or a price is missing.
+**************************************************************************************************/
+func computeVeloPoolFeesAPR(chainID uint64, poolAddress common.Address) (*bigNumber.Float, bool) {
+	pastBlock := ethereum.GetBlockNumberByPeriod(chainID, VELO_FEES_APR_PERIOD_DAYS)
+	pastTime := ethereum.GetBlockTime(chainID, pastBlock)
+	if pastBlock == 0 || pastTime == 0 || uint64(timeNow().Unix()) <= pastTime {
+		return nil, false
+	}
+	elapsed := uint64(timeNow().Unix()) - pastTime
+
+	key := poolAddress.Hex()
+	calls := []ethereum.Call{
+		multicalls.GetVeloPoolToken0(key, poolAddress),
+		multicalls.GetVeloPoolToken1(key, poolAddress),
+		multicalls.GetVeloPoolIndex0(key, poolAddress),
+		multicalls.GetVeloPoolIndex1(key, poolAddress),
+	}
+	currentResponse := multicalls.Perform(chainID, calls, nil)
+	pastResponse := multicalls.Perform(chainID, calls[2:], new(big.Int).SetUint64(pastBlock))
+
+	poolPrice, ok := storage.GetPrice(chainID, poolAddress)
+	if !ok || poolPrice.HumanizedPrice == nil || poolPrice.HumanizedPrice.IsZero() {
+		return nil, false
+	}
+
+	// The growth of an index is the amount of the token earned by one pool token (18 decimals)
+	feesValue := bigNumber.NewFloat(0)
+	for _, side := range []struct{ token, index string }{{`token0`, `index0`}, {`token1`, `index1`}} {
+		token := helpers.DecodeAddress(currentResponse[key+side.token])
+		currentIndex := helpers.DecodeBigInt(currentResponse[key+side.index])
+		pastIndex := helpers.DecodeBigInt(pastResponse[key+side.index])
+		erc20, ok := storage.GetERC20(chainID, token)
+		if !ok {
+			return nil, false
+		}
+		tokenPrice, ok := storage.GetPrice(chainID, token)
+		if !ok || tokenPrice.HumanizedPrice == nil {
+			return nil, false
+		}
+		if currentIndex.Lte(pastIndex) {
+			continue
+		}
+		earned := helpers.ToNormalizedAmount(bigNumber.NewInt(0).Sub(currentIndex, pastIndex), erc20.Decimals)
+		feesValue = bigNumber.NewFloat(0).Add(feesValue, bigNumber.NewFloat(0).Mul(earned, tokenPrice.HumanizedPrice))
+	}
+
+	feesAPR := bigNumber.NewFloat(0).Div(feesValue, poolPrice.HumanizedPrice)
+	feesAPR = bigNumber.NewFloat(0).Mul(feesAPR, bigNumber.NewFloat(float64(lendingMarketSecondsPerYear)/float64(elapsed)))
+	return feesAPR, true
+}
+
+/**************************************************************************************************
+** applyVeloGaugeComposite details the yield of a vault whose asset is a Velodrome v2 or Aerodrome
+** pool in the composite of its forward APY: BaseAPR is the APR of the emissions of the gauge, and
+** PoolAPY the APR of the trading fees of the pool. The staked liquidity gives its trading fees to
+** the voters, so PoolAPY is only earned by the liquidity left unstaked. The net APY is unchanged.
+**************************************************************************************************/
+func applyVeloGaugeComposite(vault models.TVault, gaugeAddress common.Address, forwardAPY TForwardAPY) TForwardAPY {
+	emissionsAPR, ok := computeVeloGaugeEmissionsAPR(vault.ChainID, vault.AssetAddress, gaugeAddress)
+	if ok {
+		forwardAPY.Composite.BaseAPR = emissionsAPR
+	}
+	recordAPRSourceValue(vault.ChainID, APR_SOURCE_VELO_GAUGES, gaugeAddress.Hex(), emissionsAPR)
+
+	feesAPR, ok := computeVeloPoolFeesAPR(vault.ChainID, vault.AssetAddress)
+	if ok {
+		forwardAPY.Composite.PoolAPY = feesAPR
+	}
+	recordAPRSourceValue(vault.ChainID, APR_SOURCE_VELO_FEES, vault.AssetAddress.Hex(), feesAPR)
+	return forwardAPY
+}
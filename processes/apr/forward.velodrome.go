@@ -2,7 +2,6 @@ package apr
 
 import (
 	"strings"
-	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/yearn/ydaemon/common/bigNumber"
@@ -70,7 +69,7 @@ func calculateVeloLikeStrategyAPY(
 	/**********************************************************************************************
 	** If periodFinish is before now, aka rewards are over, we can stop here
 	**********************************************************************************************/
-	now := time.Now().Unix()
+	now := timeNow().Unix()
 	if periodFinish.Int64() < now {
 		return TStrategyAPY{
 			Type: `v2:velo_unpopular`,
@@ -102,8 +101,18 @@ func calculateVeloLikeStrategyAPY(
 	vaultManagementFee := helpers.ToNormalizedAmount(bigNumber.NewInt(int64(vault.ManagementFee)), 4)
 	oneMinusKeepVelo := bigNumber.NewFloat(0).Sub(bigNumber.NewFloat(1), localKeepVelo)
 	oneMinusPerfFee := bigNumber.NewFloat(0).Sub(bigNumber.NewFloat(1), vaultPerformanceFee)
-	rewardRate := helpers.ToNormalizedAmount(rewardRateRaw, 18)
-	totalSupply := helpers.ToNormalizedAmount(totalSupplyRaw, 18)
+	normalizedRewardRate, okRate := normalizeTokenAmount(vault.ChainID, rewardTokenRaw, rewardRateRaw, `velo reward rate`)
+	normalizedTotalSupply, okSupply := normalizeTokenAmount(vault.ChainID, vault.AssetAddress, totalSupplyRaw, `velo gauge supply`)
+	if !okRate || !okSupply {
+		return TStrategyAPY{
+			Type: `v2:velo_unpopular`,
+			Composite: TCompositeData{
+				KeepVelo: localKeepVelo,
+			},
+		}
+	}
+	rewardRate := normalizedRewardRate.Float()
+	totalSupply := normalizedTotalSupply.Float()
 	secondsPerYear := bigNumber.NewFloat(31_556_952)
 
 	/**********************************************************************************************
@@ -171,6 +180,7 @@ func calculateVeloLikeStrategyAPY(
 		NetAPY:    bigNumber.NewFloat(0).Mul(netAPY, debtRatio),
 		Composite: TCompositeData{
 			KeepVelo: localKeepVelo,
+			Rewards:  []TRewardAPR{newRewardAPR(rewardTokenRaw, netAPY, debtRatio)},
 		},
 	}
 	return apyStruct
@@ -195,6 +205,7 @@ func computeVeloLikeForwardAPY(
 	rewardsAPY := bigNumber.NewFloat(0)
 	keepCRV := bigNumber.NewFloat(0)
 	keepVelo := bigNumber.NewFloat(0)
+	rewards := []TRewardAPR{}
 	for _, strategy := range allStrategiesForVault {
 		if strategy.LastDebtRatio == nil || strategy.LastDebtRatio.IsZero() {
 			continue
@@ -211,6 +222,7 @@ func computeVeloLikeForwardAPY(
 		rewardsAPY = bigNumber.NewFloat(0).Add(rewardsAPY, strategyAPY.Composite.RewardsAPY)
 		keepCRV = bigNumber.NewFloat(0).Add(keepCRV, strategyAPY.Composite.KeepCRV)
 		keepVelo = bigNumber.NewFloat(0).Add(keepVelo, strategyAPY.Composite.KeepVelo)
+		rewards = mergeRewardAPRs(rewards, strategyAPY.Composite.Rewards)
 	}
 
 	return TForwardAPY{
@@ -225,6 +237,7 @@ func computeVeloLikeForwardAPY(
 			RewardsAPY: rewardsAPY,
 			KeepCRV:    keepCRV,
 			KeepVelo:   keepVelo,
+			Rewards:    rewards,
 		},
 	}
 }
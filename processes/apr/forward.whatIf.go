@@ -0,0 +1,143 @@
+package apr
+
+import (
+	"math"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** A fee proposal changes the net APY of a vault for its depositors. The what-if simulation
+** previews it: the gross APR of the vault is derived from its forward net APY and the fees it was
+** computed with, like the fee impact, then charged the simulated fees instead:
+**   gross = (net + managementFee) / (1 - performanceFee)
+**   simulated = gross * (1 - simulatedPerformanceFee) - simulatedManagementFee
+** The forward APYs of the v3 vaults (the oracle, the debt ratio, the lending markets, ...) are not
+** charged the management fee: it is neither added back to their gross APR nor charged to their
+** simulated one, only the performance fee changing them.
+** The simulated fees apply to the whole vault, the custom fee configs of its strategies included.
+**************************************************************************************************/
+const MAX_SIMULATED_FEE_BPS = 10000
+
+/**************************************************************************************************
+** TFeeSimulation is the forward APY of a vault with its current fees and with the simulated ones.
+** The fees are in basis points and the APYs and APRs are fractions (0.05 = 5%).
+**************************************************************************************************/
+type TFeeSimulation struct {
+	ChainID                 uint64  `json:"chainID"`
+	Address                 string  `json:"address"`
+	ForwardAPYType          string  `json:"forwardAPYType"`
+	CurrentPerformanceFee   uint64  `json:"currentPerformanceFee"`
+	CurrentManagementFee    uint64  `json:"currentManagementFee"`
+	SimulatedPerformanceFee uint64  `json:"simulatedPerformanceFee"`
+	SimulatedManagementFee  uint64  `json:"simulatedManagementFee"`
+	ChargesManagementFee    bool    `json:"chargesManagementFee"`
+	GrossAPR                float64 `json:"grossAPR"`
+	CurrentNetAPY           float64 `json:"currentNetAPY"`
+	SimulatedNetAPY         float64 `json:"simulatedNetAPY"`
+	NetAPYDelta             float64 `json:"netAPYDelta"`
+}
+
+/**************************************************************************************************
+** convertForwardAPYToAPR is the inverse of convertFloatAPRToAPY, for the APR found back from a
+** forward APY to give the same APY again.
+**************************************************************************************************/
+func convertForwardAPYToAPR(apy float64, periodsPerYear float64) float64 {
+	if apy <= -100 {
+		return 0
+	}
+	return 100 * periodsPerYear * (math.Pow(1+apy/100, 1/periodsPerYear) - 1)
+}
+
+/**************************************************************************************************
+** forwardAPYChargesManagementFee returns true if the management fee of the vault was charged to
+** its forward APY, as done by the protocol sources (Curve, Velodrome, Gamma, Pendle, ...) but not
+** by the v3 forward APYs.
+**************************************************************************************************/
+func forwardAPYChargesManagementFee(forwardAPY TForwardAPY) bool {
+	return !strings.HasPrefix(forwardAPY.Type, `v3:`)
+}
+
+/**************************************************************************************************
+** simulateNetAPY recomputes a forward net APY with other fees, the fees being fractions.
+** It returns the gross APR and the simulated net APY.
+**************************************************************************************************/
+func simulateNetAPY(
+	netAPY float64,
+	periodsPerYear float64,
+	performanceFee float64,
+	managementFee float64,
+	simulatedPerformanceFee float64,
+	simulatedManagementFee float64,
+) (float64, float64) {
+	netAPR := convertForwardAPYToAPR(netAPY, periodsPerYear)
+	grossAPR := netAPR
+	if performanceFee < 1 {
+		grossAPR = (netAPR + managementFee) / (1 - performanceFee)
+	}
+	simulatedNetAPR := grossAPR*(1-simulatedPerformanceFee) - simulatedManagementFee
+	return grossAPR, convertFloatAPRToAPY(simulatedNetAPR, periodsPerYear)
+}
+
+/**************************************************************************************************
+** SimulateForwardAPYWithFees returns the forward net APY of a vault recomputed with a performance
+** fee and a management fee, in basis points. A nil fee keeps the current one. It returns false
+** when the vault is unknown or has no forward APY yet.
+**************************************************************************************************/
+func SimulateForwardAPYWithFees(
+	chainID uint64,
+	vaultAddress common.Address,
+	performanceFee *uint64,
+	managementFee *uint64,
+) (TFeeSimulation, bool) {
+	vault, ok := storage.GetVault(chainID, vaultAddress)
+	if !ok {
+		return TFeeSimulation{}, false
+	}
+	computedAPY, ok := GetComputedAPY(chainID, vaultAddress)
+	if !ok {
+		return TFeeSimulation{}, false
+	}
+	vaultAPY, ok := computedAPY.(TVaultAPY)
+	if !ok || vaultAPY.ForwardAPY.NetAPY == nil {
+		return TFeeSimulation{}, false
+	}
+
+	currentVault := withPendingFees(vault)
+	simulation := TFeeSimulation{
+		ChainID:                 chainID,
+		Address:                 vaultAddress.Hex(),
+		ForwardAPYType:          vaultAPY.ForwardAPY.Type,
+		CurrentPerformanceFee:   currentVault.PerformanceFee,
+		CurrentManagementFee:    currentVault.ManagementFee,
+		SimulatedPerformanceFee: currentVault.PerformanceFee,
+		SimulatedManagementFee:  currentVault.ManagementFee,
+	}
+	if performanceFee != nil {
+		simulation.SimulatedPerformanceFee = *performanceFee
+	}
+	if managementFee != nil {
+		simulation.SimulatedManagementFee = *managementFee
+	}
+
+	currentManagementFee := float64(simulation.CurrentManagementFee) / 10000
+	simulatedManagementFee := float64(simulation.SimulatedManagementFee) / 10000
+	simulation.ChargesManagementFee = forwardAPYChargesManagementFee(vaultAPY.ForwardAPY)
+	if !simulation.ChargesManagementFee {
+		currentManagementFee, simulatedManagementFee = 0, 0
+	}
+
+	simulation.CurrentNetAPY, _ = vaultAPY.ForwardAPY.NetAPY.Float64()
+	simulation.GrossAPR, simulation.SimulatedNetAPY = simulateNetAPY(
+		simulation.CurrentNetAPY,
+		getForwardCompoundingPeriods(vaultAPY.ForwardAPY),
+		float64(simulation.CurrentPerformanceFee)/10000,
+		currentManagementFee,
+		float64(simulation.SimulatedPerformanceFee)/10000,
+		simulatedManagementFee,
+	)
+	simulation.NetAPYDelta = simulation.SimulatedNetAPY - simulation.CurrentNetAPY
+	return simulation, true
+}
@@ -0,0 +1,35 @@
+package apr
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimulateNetAPY(t *testing.T) {
+	if _, simulated := simulateNetAPY(0.08, 52, 0.10, 0, 0.10, 0); math.Abs(simulated-0.08) > 1e-12 {
+		t.Errorf("expected the current fees to give the current APY back, got %v", simulated)
+	}
+	grossAPR, simulated := simulateNetAPY(0.09, 52, 0.10, 0, 0.20, 0)
+	if math.Abs(grossAPR*0.9-convertForwardAPYToAPR(0.09, 52)) > 1e-12 {
+		t.Errorf("expected the gross APR to be the net APR before the performance fee, got %v", grossAPR)
+	}
+	if math.Abs(simulated-convertFloatAPRToAPY(grossAPR*0.8, 52)) > 1e-12 || simulated >= 0.09 {
+		t.Errorf("expected a higher performance fee to lower the APY, got %v", simulated)
+	}
+	if _, simulated := simulateNetAPY(0.05, 52, 0.10, 0.01, 0.10, 0); simulated <= 0.05 {
+		t.Errorf("expected removing the management fee to raise the APY, got %v", simulated)
+	}
+}
+
+func TestForwardAPYChargesManagementFee(t *testing.T) {
+	for _, forwardAPY := range []TForwardAPY{{Type: `v3:onchainOracle`}, {Type: `v3:lendingMarket`}, {Type: `v3:override`}} {
+		if forwardAPYChargesManagementFee(forwardAPY) {
+			t.Errorf("expected the %s forward APY not to be charged the management fee", forwardAPY.Type)
+		}
+	}
+	for _, forwardAPY := range []TForwardAPY{{Type: `crv`}, {Type: `pendle`}, {Type: `v2:velo`}} {
+		if !forwardAPYChargesManagementFee(forwardAPY) {
+			t.Errorf("expected the %s forward APY to be charged the management fee", forwardAPY.Type)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package apr
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/contracts"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+var TOKEN_DECIMALS = make(map[uint64]*sync.Map)
+var tokenDecimalsMtx sync.Mutex
+
+/**************************************************************************************************
+** getTokenDecimals returns the decimals of a token: the ones of the store when the token is known,
+** read from the token contract otherwise. The decimals read on-chain are cached for the next APY
+** computations. It returns false when the decimals cannot be known, in which case no amount of
+** this token should be normalized.
+**************************************************************************************************/
+func getTokenDecimals(chainID uint64, tokenAddress common.Address) (uint64, bool) {
+	if token, ok := storage.GetERC20(chainID, tokenAddress); ok && token.Decimals > 0 {
+		return token.Decimals, true
+	}
+
+	tokenDecimalsMtx.Lock()
+	cache := safeSyncMap(TOKEN_DECIMALS, chainID)
+	tokenDecimalsMtx.Unlock()
+	if decimals, ok := cache.Load(tokenAddress); ok {
+		return decimals.(uint64), true
+	}
+
+	erc20Contract, err := contracts.NewERC20(tokenAddress, ethereum.GetRPC(chainID))
+	if err != nil {
+		return 0, false
+	}
+	decimals, err := erc20Contract.Decimals(nil)
+	if err != nil {
+		logs.Error(`Failed to retrieve decimals for ` + tokenAddress.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10))
+		return 0, false
+	}
+	cache.Store(tokenAddress, uint64(decimals))
+	return uint64(decimals), true
+}
+
+/**************************************************************************************************
+** normalizeTokenAmount normalizes a raw amount of a token with the decimals of this token. It
+** returns false, after logging it, when the decimals are unknown or when the normalized amount is
+** too big to be right, for the caller to skip the value instead of computing an absurd APY from it.
+**************************************************************************************************/
+func normalizeTokenAmount(chainID uint64, tokenAddress common.Address, amount *bigNumber.Int, source string) (helpers.TNormalized, bool) {
+	decimals, ok := getTokenDecimals(chainID, tokenAddress)
+	if !ok {
+		logs.Warning(`Skipping the ` + source + ` of ` + tokenAddress.Hex() + `: unknown decimals`)
+		return helpers.TNormalized{}, false
+	}
+	normalized := helpers.Normalize(amount, decimals)
+	if normalized.IsSuspicious() {
+		logs.Warning(`Skipping the ` + source + ` of ` + tokenAddress.Hex() + `: ` + normalized.Float().String() +
+			` with ` + strconv.FormatUint(decimals, 10) + ` decimals suggests a decimals mismatch`)
+		return helpers.TNormalized{}, false
+	}
+	return normalized, true
+}
@@ -0,0 +1,65 @@
+package apr
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/env"
+)
+
+/**************************************************************************************************
+** The forward APY of a v3 vault is cross-checked with the APY it realized over the last 7 days,
+** from its price per share: the profits of the v3 vaults being unlocked over time, both should be
+** close, and a forward APY APY_DIVERGENCE_FACTOR times above or below the realized one usually
+** comes from a bug in the adapter feeding the oracle. The gaps below APY_DIVERGENCE_MIN_GAP (1
+** point) are ignored, the ratios between small APYs being meaningless.
+**************************************************************************************************/
+const APY_DIVERGENCE_MIN_GAP = 0.01
+
+/**************************************************************************************************
+** OnAPYDivergence is called with the divergence of a vault when its forward APY starts diverging
+** from its realized APY. It's set by the daemon to forward it to the alerting channel.
+**************************************************************************************************/
+var OnAPYDivergence func(chainID uint64, vaultAddress common.Address, divergence TAPYDivergence)
+
+/**************************************************************************************************
+** computeAPYDivergence returns the divergence between the forward APY of a vault and the APY it
+** realized over the last 7 days, false when they don't diverge or can't be compared: the vaults
+** other than v3 and the ones without a price per share a week ago are not checked.
+**************************************************************************************************/
+func computeAPYDivergence(vaultAPY TVaultAPY, factor float64) (TAPYDivergence, bool) {
+	if factor <= 1 || !strings.HasPrefix(vaultAPY.ForwardAPY.Type, `v3:`) || vaultAPY.ForwardAPY.NetAPY == nil {
+		return TAPYDivergence{}, false
+	}
+	if vaultAPY.Points.WeekAgo == nil || vaultAPY.PricePerShare.WeekAgo == nil || vaultAPY.PricePerShare.WeekAgo.IsZero() {
+		return TAPYDivergence{}, false
+	}
+	forward, _ := vaultAPY.ForwardAPY.NetAPY.Float64()
+	realized, _ := vaultAPY.Points.WeekAgo.Float64()
+	if gap := forward - realized; gap < APY_DIVERGENCE_MIN_GAP && gap > -APY_DIVERGENCE_MIN_GAP {
+		return TAPYDivergence{}, false
+	}
+
+	divergence := TAPYDivergence{ForwardAPY: forward, RealizedAPY: realized}
+	if forward <= 0 || realized <= 0 {
+		return divergence, true
+	}
+	divergence.Ratio = max(forward, realized) / min(forward, realized)
+	return divergence, divergence.Ratio > factor
+}
+
+/**************************************************************************************************
+** checkAPYDivergence flags the forward APY of a vault diverging from its realized APY, and calls
+** OnAPYDivergence when it was not diverging on the previous computation.
+**************************************************************************************************/
+func checkAPYDivergence(chainID uint64, vaultAddress common.Address, previous *TVaultAPY, vaultAPY *TVaultAPY) {
+	divergence, ok := computeAPYDivergence(*vaultAPY, env.APY_DIVERGENCE_FACTOR)
+	if !ok {
+		vaultAPY.ForwardAPY.DivergenceWarning = nil
+		return
+	}
+	vaultAPY.ForwardAPY.DivergenceWarning = &divergence
+	if OnAPYDivergence != nil && (previous == nil || previous.ForwardAPY.DivergenceWarning == nil) {
+		OnAPYDivergence(chainID, vaultAddress, divergence)
+	}
+}
@@ -0,0 +1,42 @@
+package apr
+
+import (
+	"testing"
+
+	"github.com/yearn/ydaemon/common/bigNumber"
+)
+
+func newDivergenceTestAPY(forwardType string, forward float64, realized float64) TVaultAPY {
+	return TVaultAPY{
+		Points:        THistoricalPoints{WeekAgo: bigNumber.NewFloat(realized)},
+		PricePerShare: TPricePerShare{WeekAgo: bigNumber.NewFloat(1.01)},
+		ForwardAPY:    TForwardAPY{Type: forwardType, NetAPY: bigNumber.NewFloat(forward)},
+	}
+}
+
+func TestComputeAPYDivergence(t *testing.T) {
+	if divergence, ok := computeAPYDivergence(newDivergenceTestAPY(`v3:onchainOracle`, 0.40, 0.05), 3); !ok || divergence.Ratio != 8 {
+		t.Errorf("expected a forward APY 8 times the realized one to diverge, got %v %v", ok, divergence)
+	}
+	if _, ok := computeAPYDivergence(newDivergenceTestAPY(`v3:onchainOracle`, 0.08, 0.05), 3); ok {
+		t.Error("expected a forward APY close to the realized one not to diverge")
+	}
+	if _, ok := computeAPYDivergence(newDivergenceTestAPY(`v3:onchainOracle`, 0.006, 0.001), 3); ok {
+		t.Error("expected the gaps below one point to be ignored")
+	}
+	if divergence, ok := computeAPYDivergence(newDivergenceTestAPY(`v3:onchainOracle`, 0.10, 0), 3); !ok || divergence.Ratio != 0 {
+		t.Errorf("expected a forward APY without realized yield to diverge without ratio, got %v %v", ok, divergence)
+	}
+	if _, ok := computeAPYDivergence(newDivergenceTestAPY(`crv`, 0.40, 0.05), 3); ok {
+		t.Error("expected the vaults other than v3 not to be checked")
+	}
+	if _, ok := computeAPYDivergence(newDivergenceTestAPY(`v3:onchainOracle`, 0.40, 0.05), 0); ok {
+		t.Error("expected the check to be disabled with a factor of 0")
+	}
+
+	young := newDivergenceTestAPY(`v3:onchainOracle`, 0.40, 0)
+	young.PricePerShare.WeekAgo = bigNumber.NewFloat(0)
+	if _, ok := computeAPYDivergence(young, 3); ok {
+		t.Error("expected the vaults without a price per share a week ago not to be checked")
+	}
+}
@@ -0,0 +1,34 @@
+package apr
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+)
+
+/**************************************************************************************************
+** The forward net APY of a vault moving by more than APY_DRIFT_THRESHOLD (5 points) between two
+** computations is reported to OnAPYDrift, for a source failing or returning a new value to be
+** noticed before the users do.
+**************************************************************************************************/
+const APY_DRIFT_THRESHOLD = 0.05
+
+/**************************************************************************************************
+** OnAPYDrift is called with the previous and the new forward net APY of a vault drifting beyond
+** APY_DRIFT_THRESHOLD. It's set by the daemon to forward the drifts to the alerting channel.
+**************************************************************************************************/
+var OnAPYDrift func(chainID uint64, vaultAddress common.Address, previous float64, current float64)
+
+/**************************************************************************************************
+** checkAPYDrift compares the forward net APY computed for a vault with the previous one. There is
+** no drift when one of them is unknown, the first computation of a vault included.
+**************************************************************************************************/
+func checkAPYDrift(chainID uint64, vaultAddress common.Address, previousAPY *bigNumber.Float, currentAPY *bigNumber.Float) {
+	if OnAPYDrift == nil || previousAPY == nil || currentAPY == nil {
+		return
+	}
+	previous, _ := previousAPY.Float64()
+	current, _ := currentAPY.Float64()
+	if drift := current - previous; drift > APY_DRIFT_THRESHOLD || drift < -APY_DRIFT_THRESHOLD {
+		OnAPYDrift(chainID, vaultAddress, previous, current)
+	}
+}
@@ -26,17 +26,20 @@ func retrieveCurveGetPools(chainID uint64) []models.CurvePool {
 		resp, err := http.Get(uri)
 		if err != nil {
 			logs.Error(err)
+			recordAPRSourceError(chainID, APR_SOURCE_CURVE_POOLS, err.Error())
 			continue
 		}
 		defer resp.Body.Close()
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
 			logs.Error(err)
+			recordAPRSourceError(chainID, APR_SOURCE_CURVE_POOLS, err.Error())
 			continue
 		}
 		var getPools models.TCurvePools
 		if err := json.Unmarshal(body, &getPools); err != nil {
 			logs.Error(err)
+			recordAPRSourceError(chainID, APR_SOURCE_CURVE_POOLS, err.Error())
 			continue
 		}
 		pools = append(pools, getPools.Data.PoolData...)
@@ -54,17 +57,20 @@ func retrieveCurveSubgraphData(chainID uint64) []models.CurveSubgraphData {
 	resp, err := http.Get(storage.CURVE_SUBGRAPHDATA_URI[chainID])
 	if err != nil {
 		logs.Error(err)
+		recordAPRSourceError(chainID, APR_SOURCE_CURVE_SUBGRAPH, err.Error())
 		return []models.CurveSubgraphData{}
 	}
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		logs.Error(err)
+		recordAPRSourceError(chainID, APR_SOURCE_CURVE_SUBGRAPH, err.Error())
 		return []models.CurveSubgraphData{}
 	}
 	var subgraphData models.TCurveSubgraphData
 	if err := json.Unmarshal(body, &subgraphData); err != nil {
 		logs.Error(err)
+		recordAPRSourceError(chainID, APR_SOURCE_CURVE_SUBGRAPH, err.Error())
 		return []models.CurveSubgraphData{}
 	}
 	data := []models.CurveSubgraphData{}
@@ -72,6 +78,37 @@ func retrieveCurveSubgraphData(chainID uint64) []models.CurveSubgraphData {
 	return data
 }
 
+/**************************************************************************
+** recordCurveSourcesHealth records the number of gauges, pools and
+** subgraph items returned by the Curve sources of a chain, an empty list
+** from a source the chain uses being an error.
+**************************************************************************/
+func recordCurveSourcesHealth(chainID uint64, gaugesCount int, poolsCount int, subgraphCount int) {
+	chain, ok := env.GetChain(chainID)
+	if !ok {
+		return
+	}
+	sources := []struct {
+		source string
+		isUsed bool
+		count  int
+	}{
+		{APR_SOURCE_CURVE_GAUGES, chain.Curve.GaugesURI != ``, gaugesCount},
+		{APR_SOURCE_CURVE_POOLS, len(chain.Curve.PoolsURIs) > 0, poolsCount},
+		{APR_SOURCE_CURVE_SUBGRAPH, storage.CURVE_SUBGRAPHDATA_URI[chainID] != ``, subgraphCount},
+	}
+	for _, source := range sources {
+		if !source.isUsed {
+			continue
+		}
+		if source.count == 0 {
+			recordAPRSourceError(chainID, source.source, APR_SOURCE_ERROR_NO_RESULT)
+			continue
+		}
+		recordAPRSourceSuccess(chainID, source.source, APR_SOURCE_SAMPLE_COUNT, float64(source.count))
+	}
+}
+
 func findGaugeForVault(tokenAddress common.Address, pools []models.CurveGauge) models.CurveGauge {
 	for _, pool := range pools {
 		if common.HexToAddress(pool.SwapToken) == tokenAddress {
@@ -3,11 +3,23 @@ package apr
 import (
 	"math"
 	"strconv"
+	"time"
 
 	"github.com/yearn/ydaemon/common/bigNumber"
 	"github.com/yearn/ydaemon/common/logs"
 )
 
+/**************************************************************************************************
+** timeNow is the clock of the computations of the APRs, frozen by the regression tests at the
+** time their fixtures were recorded.
+**************************************************************************************************/
+var timeNow = time.Now
+
+/**************************************************************************************************
+** The forward APRs are compounded over FORWARD_APY_COMPOUNDING_PERIODS periods per year (a weekly
+** harvest) into the forward APYs.
+**************************************************************************************************/
+const FORWARD_APY_COMPOUNDING_PERIODS = 52
 
 func convertFloatAPRToAPY(apr float64, periodsPerYear float64) float64 {
 
@@ -22,6 +34,30 @@ func convertFloatAPRToAPY(apr float64, periodsPerYear float64) float64 {
 	return apy * 100
 }
 
+/**************************************************************************************************
+** convertFloatAPYToAPRInverse is the exact inverse of convertFloatAPRToAPY: it returns the APR
+** which compounds into the given APY with the same formula.
+**************************************************************************************************/
+func convertFloatAPYToAPRInverse(apy float64, periodsPerYear float64) float64 {
+	if apy <= -100 {
+		return 0
+	}
+	return periodsPerYear * (math.Pow(1+apy/100, 1/periodsPerYear) - 1) * 100
+}
+
+/**************************************************************************************************
+** ToForwardNetAPR returns the simple annualized rate behind a forward net APY, before its
+** compounding over the periods of its source (see getForwardCompoundingPeriods). It returns nil
+** for a nil APY.
+**************************************************************************************************/
+func ToForwardNetAPR(forwardAPY TForwardAPY) *bigNumber.Float {
+	if forwardAPY.NetAPY == nil {
+		return nil
+	}
+	apy, _ := forwardAPY.NetAPY.Float64()
+	return bigNumber.NewFloat(convertFloatAPYToAPRInverse(apy, getForwardCompoundingPeriods(forwardAPY)))
+}
+
 /**************************************************************************************************
 ** parseKongFloatAPY safely parses Kong float APY values (weeklyNet, monthlyNet, inceptionNet)
 ** to bigNumber.Float. Returns zero on nil or error.
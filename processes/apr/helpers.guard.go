@@ -0,0 +1,115 @@
+package apr
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+/**************************************************************************************************
+** An APY outside of [MIN_PLAUSIBLE_APY, MAX_PLAUSIBLE_APY] (-100% to 10,000%) is not published: it
+** is almost always an amount normalized with the wrong decimals somewhere in its computation.
+**************************************************************************************************/
+const MIN_PLAUSIBLE_APY = -1.0
+const MAX_PLAUSIBLE_APY = 100.0
+
+/**************************************************************************************************
+** TQuarantinedAPY is an APY of a vault held back because of its magnitude, with the value that was
+** computed, for it to be investigated.
+**************************************************************************************************/
+type TQuarantinedAPY struct {
+	ChainID       uint64           `json:"chainID"`
+	Vault         common.Address   `json:"vault"`
+	Field         string           `json:"field"`
+	Value         *bigNumber.Float `json:"value"`
+	QuarantinedAt time.Time        `json:"quarantinedAt"`
+}
+
+var QUARANTINED_APY = make(map[uint64]*sync.Map)
+
+func isPlausibleAPY(value *bigNumber.Float) bool {
+	if value == nil {
+		return true
+	}
+	return value.Gte(bigNumber.NewFloat(MIN_PLAUSIBLE_APY)) && value.Lte(bigNumber.NewFloat(MAX_PLAUSIBLE_APY))
+}
+
+/**************************************************************************************************
+** guardVaultAPY checks the magnitude of the APYs computed for a vault. An implausible one is logged
+** and quarantined, and replaced by the last value published for the vault if it was plausible, or
+** by nil otherwise. A quarantined APY is released as soon as a plausible value is computed again.
+** It runs once the meta-vaults are composed and the gas impact is computed, so these APYs are
+** checked too.
+**************************************************************************************************/
+func guardVaultAPY(chainID uint64, vaultAddress common.Address, vaultAPY TVaultAPY) TVaultAPY {
+	previous := TVaultAPY{}
+	if stored, ok := safeSyncMap(COMPUTED_APY, chainID).Load(vaultAddress); ok {
+		previous = stored.(TVaultAPY)
+	}
+
+	guard := func(field string, value *bigNumber.Float, previousValue *bigNumber.Float) *bigNumber.Float {
+		key := vaultAddress.Hex() + `:` + field
+		if isPlausibleAPY(value) {
+			safeSyncMap(QUARANTINED_APY, chainID).Delete(key)
+			return value
+		}
+		logs.Warning(`Quarantined the ` + field + ` of ` + vaultAddress.Hex() + ` on chain ` +
+			strconv.FormatUint(chainID, 10) + `: ` + value.String() + ` is implausible, check the decimals of its sources`)
+		safeSyncMap(QUARANTINED_APY, chainID).Store(key, TQuarantinedAPY{
+			ChainID:       chainID,
+			Vault:         vaultAddress,
+			Field:         field,
+			Value:         value,
+			QuarantinedAt: time.Now(),
+		})
+		if previousValue != nil && isPlausibleAPY(previousValue) {
+			return previousValue
+		}
+		return nil
+	}
+
+	vaultAPY.NetAPY = guard(`netAPY`, vaultAPY.NetAPY, previous.NetAPY)
+	vaultAPY.ForwardAPY.NetAPY = guard(`forwardAPY.netAPY`, vaultAPY.ForwardAPY.NetAPY, previous.ForwardAPY.NetAPY)
+	vaultAPY.ForwardAPY.NetAPYDeployedOnly = guard(`forwardAPY.netAPYDeployedOnly`, vaultAPY.ForwardAPY.NetAPYDeployedOnly, previous.ForwardAPY.NetAPYDeployedOnly)
+	vaultAPY.ForwardAPY.TotalAPY = guard(`forwardAPY.totalAPY`, vaultAPY.ForwardAPY.TotalAPY, previous.ForwardAPY.TotalAPY)
+	vaultAPY.ForwardAPY.Composite.RewardsAPY = guard(`forwardAPY.composite.rewardsAPY`, vaultAPY.ForwardAPY.Composite.RewardsAPY, previous.ForwardAPY.Composite.RewardsAPY)
+	vaultAPY.ForwardAPY.Composite.EmissionsMinBoostAPR = guard(`forwardAPY.composite.emissionsMinBoostAPR`, vaultAPY.ForwardAPY.Composite.EmissionsMinBoostAPR, previous.ForwardAPY.Composite.EmissionsMinBoostAPR)
+	vaultAPY.ForwardAPY.Composite.EmissionsMaxBoostAPR = guard(`forwardAPY.composite.emissionsMaxBoostAPR`, vaultAPY.ForwardAPY.Composite.EmissionsMaxBoostAPR, previous.ForwardAPY.Composite.EmissionsMaxBoostAPR)
+	vaultAPY.Extra.StakingRewardsAPY = guard(`extra.stakingRewardsAPY`, vaultAPY.Extra.StakingRewardsAPY, previous.Extra.StakingRewardsAPY)
+	vaultAPY.Extra.UnderlyingAssetAPR = guard(`extra.underlyingAssetAPR`, vaultAPY.Extra.UnderlyingAssetAPR, previous.Extra.UnderlyingAssetAPR)
+	vaultAPY.Extra.GammaRewardAPY = guard(`extra.gammaRewardAPY`, vaultAPY.Extra.GammaRewardAPY, previous.Extra.GammaRewardAPY)
+	if vaultAPY.GasImpact != nil {
+		previousGasImpact := TGasImpact{}
+		if previous.GasImpact != nil {
+			previousGasImpact = *previous.GasImpact
+		}
+		gasImpact := *vaultAPY.GasImpact
+		gasImpact.GrossAPY = guard(`gasImpact.grossAPY`, gasImpact.GrossAPY, previousGasImpact.GrossAPY)
+		gasImpact.NetAPY = guard(`gasImpact.netAPY`, gasImpact.NetAPY, previousGasImpact.NetAPY)
+		vaultAPY.GasImpact = &gasImpact
+	}
+	return vaultAPY
+}
+
+/**************************************************************************************************
+** ListQuarantinedAPY returns the APYs currently quarantined on a chain, sorted by vault and field.
+**************************************************************************************************/
+func ListQuarantinedAPY(chainID uint64) []TQuarantinedAPY {
+	quarantined := []TQuarantinedAPY{}
+	safeSyncMap(QUARANTINED_APY, chainID).Range(func(_, value interface{}) bool {
+		quarantined = append(quarantined, value.(TQuarantinedAPY))
+		return true
+	})
+	sort.Slice(quarantined, func(i, j int) bool {
+		if quarantined[i].Vault != quarantined[j].Vault {
+			return quarantined[i].Vault.Hex() < quarantined[j].Vault.Hex()
+		}
+		return quarantined[i].Field < quarantined[j].Field
+	})
+	return quarantined
+}
@@ -5,9 +5,11 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/yearn/ydaemon/common/addresses"
+	"github.com/yearn/ydaemon/common/bigNumber"
 	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
 	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
 	"github.com/yearn/ydaemon/internal/storage"
 )
 
@@ -47,6 +49,17 @@ func GetComputedAPY(chainID uint64, vaultAddress common.Address) (any, bool) {
 	return safeSyncMap(COMPUTED_APY, chainID).Load(vaultAddress)
 }
 
+/**************************************************************************************************
+** The APY of the retired vaults is not computed, except on Gnosis and for these vaults, still used
+** by Alchemix.
+**************************************************************************************************/
+var RETIRED_VAULTS_WITH_APY = []common.Address{
+	common.HexToAddress(`0xaD17A225074191d5c8a37B50FdA1AE278a2EE6A2`),
+	common.HexToAddress(`0x5B977577Eb8a480f63e11FC615D6753adB8652Ae`),
+	common.HexToAddress(`0x65343F414FFD6c97b0f6add33d16F6845Ac22BAc`),
+	common.HexToAddress(`0xFaee21D0f0Af88EE72BB6d68E54a90E6EC2616de`),
+}
+
 /**************************************************************************
 ** Function to calculate the APY for all the vaults in a chain.
 **************************************************************************/
@@ -54,25 +67,28 @@ func ComputeChainAPY(chainID uint64) {
 	start := time.Now()
 	logs.Warning("📈 [APY START]", "chain", chainID)
 	allVaults, _ := storage.ListVaults(chainID)
-	gauges := storage.FetchCurveGauges(chainID)
-	pools := retrieveCurveGetPools(chainID)
-	subgraphData := retrieveCurveSubgraphData(chainID)
-	fraxPools := retrieveFraxPools()
-	storage.RefreshGammaCalls(chainID)
+	sources := prepareAPRSources(chainID)
+	retrieveLendingMarketAPRs(chainID)
+	loadStrategyAPROverrides(chainID)
+	harvestCostUSD, hasHarvestCost := retrieveHarvestCostUSD(chainID)
+	dYFIPrice, hasDYFIPrice := retrieveDYFIPrice(chainID)
+	retrieveUnderlyingAssetAPRs(chainID)
 
-	isOnGnosis := (chainID == 100)
+	chain, _ := env.GetChain(chainID)
 	computedAPYData := make(map[common.Address]TVaultAPY)
+	type tComputedVault struct {
+		vault         models.TVault
+		strategies    map[string]models.TStrategy
+		stakingSource string
+	}
+	computedVaults := []tComputedVault{}
 
 	for _, vault := range allVaults {
-		// Adding an exception for the vault that is retired but we still want to compute. Alchemix related
-		isException := addresses.Equals(vault.Address, "0xaD17A225074191d5c8a37B50FdA1AE278a2EE6A2") ||
-			addresses.Equals(vault.Address, "0x5B977577Eb8a480f63e11FC615D6753adB8652Ae") ||
-			addresses.Equals(vault.Address, "0x65343F414FFD6c97b0f6add33d16F6845Ac22BAc") ||
-			addresses.Equals(vault.Address, "0xFaee21D0f0Af88EE72BB6d68E54a90E6EC2616de")
+		isException := helpers.Contains(RETIRED_VAULTS_WITH_APY, vault.Address)
 		shouldSkip := false
-		if vault.Metadata.IsRetired {
+		if models.IsVaultRetired(vault) {
 			shouldSkip = true
-			if isOnGnosis {
+			if chain.Capabilities.ComputesRetiredVaultsAPY {
 				shouldSkip = false
 			}
 			if isException {
@@ -84,112 +100,159 @@ func ComputeChainAPY(chainID uint64) {
 		}
 		
 		allStrategiesForVault, _ := storage.ListStrategiesForVault(chainID, vault.Address)
+		forwardVault := withPendingFees(vault)
 		vaultAPY := TVaultAPY{}
 		if isV3Vault(vault) {
-			if vault.Metadata.ShouldUseV2APR {
+			if shouldUseV2APR(vault) {
 				vaultAPY = computeCurrentV2VaultAPY(vault)
 			} else {
 				vaultAPY = computeCurrentV3VaultAPY(vault)
 			}
-			vaultAPY.ForwardAPY = computeVaultV3ForwardAPY(
-				vault,
-				allStrategiesForVault,
-			)
 		} else {
 			vaultAPY = computeCurrentV2VaultAPY(vault)
 		}
 
+		/**********************************************************************************************
+		** Split the net APY into the gross APR and the fee drag, based on the fees that were
+		** effective over the period covered by the net APY.
+		**********************************************************************************************/
+		vaultAPY.FeeImpact = computeFeeImpact(vault, vaultAPY.NetAPY)
+
 		/**********************************************************************************************
 		** Some vaults may have a staking rewards system. If so, we need to calculate the APY for
 		** this staking rewards system and add it to the netAPY.
 		**********************************************************************************************/
+		stakingSource := ``
 		_, stakingRewardAPY, hasExtraAPR := computeOPBoostStakingRewardsAPY(chainID, vault)
 		if hasExtraAPR {
 			vaultAPY.Extra.StakingRewardsAPY = stakingRewardAPY
+			stakingSource = STAKING_SOURCE_OP
 		}
 
 		_, veYFIGaugeStakingAPY, hasExtraAPR := computeVeYFIGaugeStakingRewardsAPY(chainID, vault)
 		if hasExtraAPR {
 			vaultAPY.Extra.StakingRewardsAPY = veYFIGaugeStakingAPY
+			stakingSource = STAKING_SOURCE_VEYFI
 		}
 
 		_, juicedStakingAPY, hasExtraAPR := computeJuicedStakingRewardsAPY(chainID, vault)
 		if hasExtraAPR {
 			vaultAPY.Extra.StakingRewardsAPY = juicedStakingAPY
+			stakingSource = STAKING_SOURCE_JUICED
 		}
 
 		_, v3StakingAPY, hasExtraAPR := computeV3StakingRewardsAPY(chainID, vault)
 		if hasExtraAPR {
 			vaultAPY.Extra.StakingRewardsAPY = v3StakingAPY
+			stakingSource = STAKING_SOURCE_V3
 		}
 
 		/**********************************************************************************************
-		** If it's a Curve Vault (has a Curve, Convex or Frax strategy), we can estimate the forward
-		** APY, aka the expected APY we will get for the upcoming period.
-		** We need to compute it and store it in our ForwardAPY structure.
+		** The forward APY, aka the expected APY we will get for the upcoming period, is computed by
+		** the first APR source matching the vault: the protocol it farms (Curve, Velodrome, Gamma,
+		** Pendle, ...) when one is known, the APR oracle for the other v3 vaults.
 		**********************************************************************************************/
-		if isCurveVault(allStrategiesForVault) {
-			forwardAPY := computeCurveLikeForwardAPY(
-				vault,
-				allStrategiesForVault,
-				gauges,
-				pools,
-				subgraphData,
-				fraxPools,
-			)
-			if forwardAPY.NetAPY != nil {
-				vaultAPY.ForwardAPY = forwardAPY
-			}
+		sourceContext := &TAPRSourceContext{
+			ChainID:    chainID,
+			Vault:      forwardVault,
+			Strategies: allStrategiesForVault,
+			VaultAPY:   &vaultAPY,
+		}
+		if forwardAPY, ok := computeSourcesForwardAPY(sources, sourceContext); ok {
+			vaultAPY.ForwardAPY = forwardAPY
 		}
 
 		/**********************************************************************************************
-		** If it's a Velo Vault (has a Velo or Aero strategy), we can estimate the forward APY, aka
-		** the expected APY we will get for the upcoming period.
-		** We need to compute it and store it in our ForwardAPY structure.
+		** The strategies APRs only apply to the funds allocated to them. The funds sitting idle in
+		** the vault do not earn anything, so the forward APY is adjusted to the idle ratio.
 		**********************************************************************************************/
-		if veloPool, ok := isVeloVault(chainID, vault); ok {
-			vaultAPY.ForwardAPY = computeVeloLikeForwardAPY(
-				vault,
-				allStrategiesForVault,
-				veloPool,
-			)
+		if idleRatio, ok := computeIdleRatio(vault, allStrategiesForVault); ok {
+			vaultAPY.ForwardAPY = applyIdleRatio(vaultAPY.ForwardAPY, idleRatio)
 		}
-		if aeroPool, ok := isAeroVault(chainID, vault); ok {
-			vaultAPY.ForwardAPY = computeVeloLikeForwardAPY(
-				vault,
-				allStrategiesForVault,
-				aeroPool,
-			)
+
+		/**********************************************************************************************
+		** Some strategies deposit into external vaults charging entry/exit fees. These fees are
+		** not part of the forward APY estimations, so we remove their amortized cost here.
+		**********************************************************************************************/
+		vaultAPY.EntryExitFeeBps = computeVaultEntryExitFeeBps(vault, allStrategiesForVault)
+		vaultAPY.ForwardAPY = applyEntryExitFees(chainID, vaultAPY.ForwardAPY, vaultAPY.EntryExitFeeBps)
+
+		computedAPYData[vault.Address] = vaultAPY
+		computedVaults = append(computedVaults, tComputedVault{vault, allStrategiesForVault, stakingSource})
+	}
+
+	/**********************************************************************************************
+	** The meta-vaults are composed from the forward APY of their nested vaults, which are only
+	** all known once every vault of the chain has been processed. The adjustments below then
+	** apply to the composed APY like to any other.
+	**********************************************************************************************/
+	applyMetaVaultComposition(chainID, computedAPYData)
+
+	for _, computedVault := range computedVaults {
+		vault, allStrategiesForVault, stakingSource := computedVault.vault, computedVault.strategies, computedVault.stakingSource
+		vaultAPY := computedAPYData[vault.Address]
+
+		/**********************************************************************************************
+		** For the small vaults, the harvests cost a meaningful part of the yield. The forward APY
+		** net of the amortized harvest costs is exposed along with the gross one.
+		**********************************************************************************************/
+		if hasHarvestCost {
+			vaultAPY.GasImpact = computeGasImpact(vault, allStrategiesForVault, vaultAPY.ForwardAPY, harvestCostUSD)
 		}
 
 		/**********************************************************************************************
-		** If it's a Gamma Vault, we can get the feeAPR as an estimate for the upcoming period, and we
-		** can retrieve the extraReward APRs.
+		** The vaults with a veYFI gauge also earn the dYFI it emits, depending on the boost of the
+		** depositor. These emissions are the staking rewards of the vault, counted at the average
+		** boost; the range of their APR, from no boost to the max boost, is added to the composite
+		** of the forward APY. It is skipped when the staking rewards come from another contract.
 		**********************************************************************************************/
-		if isGammaVault(chainID, vault) {
-			if _, extaRewardAPY, ok := calculateGammaExtraRewards(chainID, vault.AssetAddress); ok {
-				vaultAPY.Extra.GammaRewardAPY = extaRewardAPY
+		if hasDYFIPrice && stakingSource == STAKING_SOURCE_VEYFI {
+			if minAPR, maxAPR, ok := computeDYFIGaugeEmissionsAPR(chainID, vault, dYFIPrice); ok {
+				vaultAPY.ForwardAPY.Composite.EmissionsMinBoostAPR = minAPR
+				vaultAPY.ForwardAPY.Composite.EmissionsMaxBoostAPR = maxAPR
 			}
-			vaultAPY.ForwardAPY = computeGammaForwardAPY(
-				vault,
-				allStrategiesForVault,
-			)
-			vaultAPY.ForwardAPY.Composite.RewardsAPY = vaultAPY.Extra.GammaRewardAPY
 		}
 
-		if isPendleVault(chainID, vault) {
-			vaultAPY.ForwardAPY = computePendleForwardAPY(
-				vault,
-				allStrategiesForVault,
-			)
+		/**********************************************************************************************
+		** The vaults whose asset is yield-bearing also earn the intrinsic yield of the asset, on top
+		** of the forward APY measured in units of the asset. Both are combined in the total APY.
+		**********************************************************************************************/
+		if underlyingAPR, ok := getUnderlyingAssetAPR(chainID, vault.AssetAddress); ok {
+			vaultAPY.Extra.UnderlyingAssetAPR = bigNumber.NewFloat(underlyingAPR)
+			vaultAPY.ForwardAPY.TotalAPY = computeTotalAPY(vaultAPY.ForwardAPY.NetAPY, underlyingAPR)
 		}
 
+		/**********************************************************************************************
+		** An APY too big to be right comes from a decimals mismatch in one of its sources. It is
+		** quarantined instead of being published.
+		**********************************************************************************************/
+		vaultAPY = guardVaultAPY(chainID, vault.Address, vaultAPY)
+		vaultAPY.ForwardAPY.NetAPR = ToForwardNetAPR(vaultAPY.ForwardAPY)
+
+		/**********************************************************************************************
+		** The part of the APY paid in reward tokens depends on their price. It's detailed token by
+		** token, with how much of the APY a 10% move of the price of the token moves.
+		**********************************************************************************************/
+		vaultAPY.RewardContributions = computeRewardContributions(chainID, vault, vaultAPY, stakingSource)
+
+		/**********************************************************************************************
+		** The forward APY is compared with the previous one and with the APY realized over the last
+		** 7 days, to catch a source going wrong.
+		**********************************************************************************************/
+		var previousAPY *TVaultAPY
+		if previous, ok := safeSyncMap(COMPUTED_APY, chainID).Load(vault.Address); ok {
+			previousVaultAPY := previous.(TVaultAPY)
+			previousAPY = &previousVaultAPY
+			checkAPYDrift(chainID, vault.Address, previousVaultAPY.ForwardAPY.NetAPY, vaultAPY.ForwardAPY.NetAPY)
+		}
+		checkAPYDivergence(chainID, vault.Address, previousAPY, &vaultAPY)
 		safeSyncMap(COMPUTED_APY, chainID).Store(vault.Address, vaultAPY)
 		computedAPYData[vault.Address] = vaultAPY
 	}
 
 	// Save the computed APY data to disk
 	storage.StoreAPYToJson(chainID, computedAPYData)
+	storage.StoreFeesToJson(chainID)
 	logs.Success("📈 [APY DONE]", "chain", chainID, "took", time.Since(start))
 	logs.Success(chainID, `-`, `ComputeChainAPY ✅`) // Legacy format for deploy workflow detection
 }
@@ -11,3 +11,8 @@ type TPricePerShare = models.TPricePerShare
 type TForwardAPY = models.TForwardAPY
 type TVaultAPY = models.TVaultAPY
 type TStrategyAPY = models.TStrategyAPY
+type TFeeImpact = models.TFeeImpact
+type TGasImpact = models.TGasImpact
+type TRewardAPR = models.TRewardAPR
+type TRewardContribution = models.TRewardContribution
+type TAPYDivergence = models.TAPYDivergence
@@ -0,0 +1,68 @@
+package apr
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** TRealizedStrategyAPR is the average net APR of the harvest reports of a strategy over a window,
+** as computed by Kong for each report, and the number of reports it is based on.
+**************************************************************************************************/
+type TRealizedStrategyAPR struct {
+	Address common.Address
+	NetAPR  float64
+	Reports uint64
+}
+
+/**************************************************************************************************
+** GetRealizedStrategiesAPRFromDB retrieves, in one query, the average net APR of the reports
+** (`Reported` or `Harvested` events) emitted since the given time by all the strategies of a
+** chain. Reports without an APR are ignored.
+**
+** @param chainID The blockchain network ID
+** @param since The start of the window
+** @return map[common.Address]TRealizedStrategyAPR The realized APR of each strategy with reports
+** @return error An error if the database is not available or the query fails
+**************************************************************************************************/
+func GetRealizedStrategiesAPRFromDB(chainID uint64, since time.Time) (map[common.Address]TRealizedStrategyAPR, error) {
+	db := storage.GetDB()
+	if db == nil {
+		return nil, errors.New("database connection not available")
+	}
+
+	query := `
+		SELECT
+			address,
+			AVG((hook->'apr'->>'net')::numeric) AS "apr",
+			COUNT(*) AS "reports"
+		FROM evmlog
+		WHERE
+			(chain_id = ?) AND (block_time >= ?)
+			AND (event_name = 'Reported' OR event_name = 'Harvested')
+			AND (hook->'apr'->>'net' IS NOT NULL)
+		GROUP BY address`
+
+	rows := []struct {
+		Address string
+		APR     float64
+		Reports uint64
+	}{}
+	if err := db.Raw(query, chainID, since).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	realizedAPRs := make(map[common.Address]TRealizedStrategyAPR, len(rows))
+	for _, row := range rows {
+		address := common.HexToAddress(row.Address)
+		realizedAPRs[address] = TRealizedStrategyAPR{
+			Address: address,
+			NetAPR:  row.APR,
+			Reports: row.Reports,
+		}
+	}
+	return realizedAPRs, nil
+}
@@ -0,0 +1,295 @@
+package apr
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/fixtures"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/kong"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The regression tests compute the APYs of the chains against recorded responses of the nodes and
+** of the external APIs (common/fixtures), and compare them to the APYs computed when they were
+** recorded (the golden APYs). They fail until fixtures are recorded for all the chains of
+** REGRESSION_CHAIN_IDS (their RPC_URI_FOR_<chainID> set to archive nodes), with:
+**
+**	RPC_FIXTURES_MODE=record go test ./processes/apr -run TestGoldenAPY
+**
+** Recording snapshots the data directory, for the vaults and strategies to be the recorded ones,
+** and the Kong data of the vaults (testdata/fixtures/kong), which is only held in memory. It
+** freezes the clock of the computations at the time of the recording, and rewrites the golden
+** APYs. A recording in which a call failed is rejected, for the goldens never to pin an error.
+** A refactor changing the computations is expected to keep the goldens, or to record them again.
+**************************************************************************************************/
+const REGRESSION_FIXTURES_PATH = `testdata/fixtures`
+const REGRESSION_GOLDEN_PATH = `testdata/golden`
+
+var REGRESSION_CHAIN_IDS = []uint64{1, 747474}
+
+type tFixturesManifest struct {
+	RecordedAt int64    `json:"recordedAt"`
+	ChainIDs   []uint64 `json:"chainIDs"`
+}
+
+var (
+	regressionOnce     sync.Once
+	regressionManifest tFixturesManifest
+	regressionErr      error
+)
+
+func isRecording() bool {
+	return env.RPC_FIXTURES_MODE == fixtures.MODE_RECORD
+}
+
+/**************************************************************************************************
+** setupRegression prepares, once, the replay (or the recording) of the fixtures and the store of
+** the recorded chains, and returns the recorded chains. The test fails if the fixtures of one of
+** REGRESSION_CHAIN_IDS are missing, a regression test which does not run catching nothing.
+**************************************************************************************************/
+func setupRegression(tb testing.TB) []uint64 {
+	fixturesPath, _ := filepath.Abs(REGRESSION_FIXTURES_PATH)
+	manifestPath := filepath.Join(fixturesPath, `manifest.json`)
+	if _, err := os.Stat(manifestPath); err != nil && !isRecording() {
+		tb.Fatal(`no fixtures recorded in ` + REGRESSION_FIXTURES_PATH + `, record them with RPC_FIXTURES_MODE=record`)
+	}
+
+	regressionOnce.Do(func() {
+		if isRecording() {
+			regressionManifest = tFixturesManifest{RecordedAt: time.Now().Unix()}
+			for _, chainID := range REGRESSION_CHAIN_IDS {
+				if _, ok := env.GetChain(chainID); !ok {
+					regressionErr = errors.New(`the chain ` + strconv.FormatUint(chainID, 10) + ` is not supported`)
+					return
+				}
+				regressionManifest.ChainIDs = append(regressionManifest.ChainIDs, chainID)
+			}
+			if regressionErr = copyDirectory(env.BASE_DATA_PATH, filepath.Join(fixturesPath, `data`)); regressionErr != nil {
+				return
+			}
+			content, _ := json.MarshalIndent(regressionManifest, "", "\t")
+			if regressionErr = os.WriteFile(manifestPath, content, 0644); regressionErr != nil {
+				return
+			}
+		} else {
+			content, err := os.ReadFile(manifestPath)
+			if err != nil {
+				regressionErr = err
+				return
+			}
+			if regressionErr = json.Unmarshal(content, &regressionManifest); regressionErr != nil {
+				return
+			}
+			for _, chainID := range REGRESSION_CHAIN_IDS {
+				if !helpers.Contains(regressionManifest.ChainIDs, chainID) {
+					regressionErr = errors.New(`no fixtures recorded for the chain ` + strconv.FormatUint(chainID, 10) + `, record them with RPC_FIXTURES_MODE=record`)
+					return
+				}
+			}
+			env.RPC_FIXTURES_MODE = fixtures.MODE_REPLAY
+		}
+
+		// The computations write to the store, which is a copy of the recorded data directory
+		dataPath, err := os.MkdirTemp(``, `ydaemon-regression-`)
+		if err != nil {
+			regressionErr = err
+			return
+		}
+		if regressionErr = copyDirectory(filepath.Join(fixturesPath, `data`), dataPath); regressionErr != nil {
+			return
+		}
+		env.BASE_DATA_PATH = dataPath
+		env.STORAGE_BACKEND = storage.STORAGE_BACKEND_FILES
+		env.RPC_FIXTURES_PATH = fixturesPath
+		timeNow = func() time.Time {
+			return time.Unix(regressionManifest.RecordedAt, 0)
+		}
+
+		ethereum.Initialize()
+		storage.InitializeStorage()
+		for _, chainID := range regressionManifest.ChainIDs {
+			if regressionErr = loadKongFixture(filepath.Join(fixturesPath, `kong`), chainID); regressionErr != nil {
+				return
+			}
+		}
+	})
+	if regressionErr != nil {
+		tb.Fatal(regressionErr)
+	}
+	return regressionManifest.ChainIDs
+}
+
+/**************************************************************************************************
+** loadKongFixture stores the recorded Kong data of the vaults of a chain, fetching and recording
+** it first when recording.
+**************************************************************************************************/
+func loadKongFixture(kongPath string, chainID uint64) error {
+	fixturePath := filepath.Join(kongPath, strconv.FormatUint(chainID, 10)+`.json`)
+	if isRecording() {
+		vaultsData, err := kong.FetchVaultsFromKong(chainID)
+		if err != nil {
+			return err
+		}
+		schemas := make(map[common.Address]models.TKongVaultSchema)
+		for vaultAddress, data := range vaultsData {
+			schemas[vaultAddress] = data.ToVaultSchema()
+		}
+		content, _ := json.MarshalIndent(schemas, "", "\t")
+		if err := os.MkdirAll(kongPath, 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(fixturePath, content, 0644); err != nil {
+			return err
+		}
+	}
+
+	content, err := os.ReadFile(fixturePath)
+	if err != nil {
+		return err
+	}
+	schemas := make(map[common.Address]models.TKongVaultSchema)
+	if err := json.Unmarshal(content, &schemas); err != nil {
+		return err
+	}
+	for vaultAddress, schema := range schemas {
+		storage.StoreKongVaultData(chainID, vaultAddress, schema)
+	}
+	return nil
+}
+
+/**************************************************************************************************
+** copyDirectory copies the files of a directory, the symlinks (like the CDN checkout) excepted.
+**************************************************************************************************/
+func copyDirectory(source string, destination string) error {
+	return filepath.WalkDir(source, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, _ := filepath.Rel(source, path)
+		target := filepath.Join(destination, relative)
+		if entry.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, 0644)
+	})
+}
+
+/**************************************************************************************************
+** computedAPYs returns the APYs computed for the vaults of a chain, keyed by their address.
+**************************************************************************************************/
+func computedAPYs(chainID uint64) map[string]TVaultAPY {
+	computed := make(map[string]TVaultAPY)
+	safeSyncMap(COMPUTED_APY, chainID).Range(func(key, value any) bool {
+		computed[key.(common.Address).Hex()] = value.(TVaultAPY)
+		return true
+	})
+	return computed
+}
+
+/**************************************************************************************************
+** TestGoldenAPY checks that the APYs computed against the fixtures are the golden ones, vault by
+** vault. The golden APYs are rewritten when recording.
+**************************************************************************************************/
+func TestGoldenAPY(t *testing.T) {
+	for _, chainID := range setupRegression(t) {
+		chainID := chainID
+		t.Run(strconv.FormatUint(chainID, 10), func(t *testing.T) {
+			COMPUTED_APY[chainID] = &sync.Map{}
+			fixtures.ResetFailedCalls()
+			ComputeChainAPY(chainID)
+			if failed := fixtures.FailedCalls(); len(failed) > 0 {
+				t.Fatalf("%d calls could not be answered, the first being %s", len(failed), failed[0])
+			}
+			computed := computedAPYs(chainID)
+			for address, apy := range computed {
+				if apy.ForwardAPY.Type == `` {
+					t.Errorf("no forward APY computed for vault %s", address)
+				}
+			}
+			if t.Failed() {
+				return
+			}
+			goldenPath := filepath.Join(REGRESSION_GOLDEN_PATH, `apy.`+strconv.FormatUint(chainID, 10)+`.json`)
+
+			if isRecording() {
+				content, _ := json.MarshalIndent(computed, "", "\t")
+				if err := os.MkdirAll(REGRESSION_GOLDEN_PATH, 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(goldenPath, content, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			content, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden := make(map[string]json.RawMessage)
+			if err := json.Unmarshal(content, &golden); err != nil {
+				t.Fatal(err)
+			}
+			for address, expected := range golden {
+				apy, ok := computed[address]
+				if !ok {
+					t.Errorf("no APY computed for vault %s", address)
+					continue
+				}
+				expectedAPY := TVaultAPY{}
+				if err := json.Unmarshal(expected, &expectedAPY); err != nil {
+					t.Fatal(err)
+				}
+				got, _ := json.Marshal(apy)
+				want, _ := json.Marshal(expectedAPY)
+				if string(got) != string(want) {
+					t.Errorf("unexpected APY for vault %s:\n got %s\nwant %s", address, got, want)
+				}
+			}
+			for address := range computed {
+				if _, ok := golden[address]; !ok {
+					t.Errorf("unexpected APY computed for vault %s", address)
+				}
+			}
+		})
+	}
+}
+
+/**************************************************************************************************
+** BenchmarkComputeChainAPY measures a full refresh of the APYs of each recorded chain, against
+** the fixtures, the network being out of the measure.
+**************************************************************************************************/
+func BenchmarkComputeChainAPY(b *testing.B) {
+	if isRecording() {
+		b.Skip(`the benchmarks only run against recorded fixtures`)
+	}
+	for _, chainID := range setupRegression(b) {
+		chainID := chainID
+		b.Run(strconv.FormatUint(chainID, 10), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				ComputeChainAPY(chainID)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package apr
+
+import (
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** Curve: the vaults with a Curve, Convex or Frax strategy, computed from the gauges, the pools and
+** the subgraph of Curve and the pools of Frax, fetched once per chain. A vault whose gauge or pool
+** is not found keeps its current forward APY.
+**************************************************************************************************/
+type tCurveAPRSource struct {
+	gauges       []models.CurveGauge
+	pools        []models.CurvePool
+	subgraphData []models.CurveSubgraphData
+	fraxPools    []TFraxPool
+}
+
+func init() {
+	RegisterAPRSource(tCurveAPRSource{})
+}
+
+func (s tCurveAPRSource) Name() string {
+	return `curve`
+}
+
+func (s tCurveAPRSource) Prepare(chainID uint64) TAPRSource {
+	s.gauges = storage.FetchCurveGauges(chainID)
+	s.pools = retrieveCurveGetPools(chainID)
+	s.subgraphData = retrieveCurveSubgraphData(chainID)
+	recordCurveSourcesHealth(chainID, len(s.gauges), len(s.pools), len(s.subgraphData))
+	s.fraxPools = retrieveFraxPools()
+	return s
+}
+
+func (s tCurveAPRSource) Priority() int {
+	return APR_SOURCE_PRIORITY_CURVE
+}
+
+func (s tCurveAPRSource) Match(ctx *TAPRSourceContext) bool {
+	return isCurveVault(ctx.Strategies)
+}
+
+func (s tCurveAPRSource) Compute(ctx *TAPRSourceContext) (TForwardAPY, bool) {
+	forwardAPY := computeCurveLikeForwardAPY(ctx.Vault, ctx.Strategies, s.gauges, s.pools, s.subgraphData, s.fraxPools)
+	return forwardAPY, forwardAPY.NetAPY != nil
+}
@@ -0,0 +1,48 @@
+package apr
+
+import (
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** Gamma: the vaults whose asset is a Gamma hypervisor, the fee APR of the hypervisor being the
+** forward APY, and the Merkl rewards its extra APR. The rewards of the composite are the Merkl
+** rewards and the incentives of the staking contract of the vault.
+**************************************************************************************************/
+type tGammaAPRSource struct{}
+
+func init() {
+	RegisterAPRSource(tGammaAPRSource{})
+}
+
+func (s tGammaAPRSource) Name() string {
+	return `gamma`
+}
+
+func (s tGammaAPRSource) Prepare(chainID uint64) TAPRSource {
+	storage.RefreshGammaCalls(chainID)
+	return s
+}
+
+func (s tGammaAPRSource) Priority() int {
+	return APR_SOURCE_PRIORITY_GAMMA
+}
+
+func (s tGammaAPRSource) Match(ctx *TAPRSourceContext) bool {
+	return isGammaVault(ctx.ChainID, ctx.Vault)
+}
+
+func (s tGammaAPRSource) Compute(ctx *TAPRSourceContext) (TForwardAPY, bool) {
+	if _, extraRewardAPY, ok := calculateGammaExtraRewards(ctx.ChainID, ctx.Vault.AssetAddress); ok {
+		ctx.VaultAPY.Extra.GammaRewardAPY = extraRewardAPY
+	}
+	forwardAPY := computeGammaForwardAPY(ctx.Vault, ctx.Strategies)
+	forwardAPY.Composite.RewardsAPY = bigNumber.NewFloat(0)
+	for _, rewardsAPY := range []*bigNumber.Float{ctx.VaultAPY.Extra.GammaRewardAPY, ctx.VaultAPY.Extra.StakingRewardsAPY} {
+		if rewardsAPY != nil {
+			forwardAPY.Composite.RewardsAPY = bigNumber.NewFloat(0).Add(forwardAPY.Composite.RewardsAPY, rewardsAPY)
+		}
+	}
+	return forwardAPY, true
+}
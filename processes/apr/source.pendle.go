@@ -0,0 +1,26 @@
+package apr
+
+/**************************************************************************************************
+** Pendle: the vaults whose asset is the LP token of a Pendle market.
+**************************************************************************************************/
+type tPendleAPRSource struct{}
+
+func init() {
+	RegisterAPRSource(tPendleAPRSource{})
+}
+
+func (s tPendleAPRSource) Name() string {
+	return `pendle`
+}
+
+func (s tPendleAPRSource) Priority() int {
+	return APR_SOURCE_PRIORITY_PENDLE
+}
+
+func (s tPendleAPRSource) Match(ctx *TAPRSourceContext) bool {
+	return isPendleVault(ctx.ChainID, ctx.Vault)
+}
+
+func (s tPendleAPRSource) Compute(ctx *TAPRSourceContext) (TForwardAPY, bool) {
+	return computePendleForwardAPY(ctx.Vault, ctx.Strategies), true
+}
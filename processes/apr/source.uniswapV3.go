@@ -0,0 +1,48 @@
+package apr
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/env"
+)
+
+/**************************************************************************************************
+** Uniswap v3: the vaults whose strategies hold positions of the Uniswap v3 position manager of the
+** chain, the fees APR of their ranges being the forward APY and the incentives of the staking
+** contract of the vault the rewards of the composite.
+**************************************************************************************************/
+type tUniV3APRSource struct {
+	ranges map[common.Address][]tUniV3Range
+}
+
+func init() {
+	RegisterAPRSource(tUniV3APRSource{})
+}
+
+func (s tUniV3APRSource) Name() string {
+	return `univ3`
+}
+
+func (s tUniV3APRSource) Prepare(chainID uint64) TAPRSource {
+	chain, ok := env.GetChain(chainID)
+	if !ok || (chain.UniV3PositionManager == common.Address{}) {
+		return tUniV3APRSource{}
+	}
+	return tUniV3APRSource{ranges: discoverUniV3Ranges(chainID, chain.UniV3PositionManager)}
+}
+
+func (s tUniV3APRSource) Priority() int {
+	return APR_SOURCE_PRIORITY_UNIV3
+}
+
+func (s tUniV3APRSource) Match(ctx *TAPRSourceContext) bool {
+	for _, strategy := range ctx.Strategies {
+		if _, ok := s.ranges[strategy.Address]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s tUniV3APRSource) Compute(ctx *TAPRSourceContext) (TForwardAPY, bool) {
+	return computeUniV3ForwardAPY(ctx.Vault, ctx.Strategies, s.ranges, ctx.VaultAPY.Extra.StakingRewardsAPY)
+}
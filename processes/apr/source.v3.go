@@ -0,0 +1,28 @@
+package apr
+
+/**************************************************************************************************
+** v3: the v3 vaults, computed from the APR oracle of the chain (or its fallback lens), the lending
+** markets and the overrides. It comes after the protocol sources, used for the vaults none of them
+** computes.
+**************************************************************************************************/
+type tV3APRSource struct{}
+
+func init() {
+	RegisterAPRSource(tV3APRSource{})
+}
+
+func (s tV3APRSource) Name() string {
+	return `v3`
+}
+
+func (s tV3APRSource) Priority() int {
+	return APR_SOURCE_PRIORITY_V3_ORACLE
+}
+
+func (s tV3APRSource) Match(ctx *TAPRSourceContext) bool {
+	return isV3Vault(ctx.Vault)
+}
+
+func (s tV3APRSource) Compute(ctx *TAPRSourceContext) (TForwardAPY, bool) {
+	return computeVaultV3ForwardAPY(ctx.Vault, ctx.Strategies), true
+}
@@ -0,0 +1,55 @@
+package apr
+
+/**************************************************************************************************
+** Velodrome (Optimism) and Aerodrome (Base): the vaults whose asset is a pool with a gauge, the
+** emissions of the gauge and the trading fees of the pool being detailed in the composite.
+**************************************************************************************************/
+type tVeloAPRSource struct{}
+type tAeroAPRSource struct{}
+
+func init() {
+	RegisterAPRSource(tVeloAPRSource{})
+	RegisterAPRSource(tAeroAPRSource{})
+}
+
+func (s tVeloAPRSource) Name() string {
+	return `velodrome`
+}
+
+func (s tVeloAPRSource) Priority() int {
+	return APR_SOURCE_PRIORITY_VELODROME
+}
+
+func (s tVeloAPRSource) Match(ctx *TAPRSourceContext) bool {
+	return ctx.ChainID == 10
+}
+
+func (s tVeloAPRSource) Compute(ctx *TAPRSourceContext) (TForwardAPY, bool) {
+	veloPool, ok := isVeloVault(ctx.ChainID, ctx.Vault)
+	if !ok {
+		return TForwardAPY{}, false
+	}
+	forwardAPY := computeVeloLikeForwardAPY(ctx.Vault, ctx.Strategies, veloPool)
+	return applyVeloGaugeComposite(ctx.Vault, veloPool, forwardAPY), true
+}
+
+func (s tAeroAPRSource) Name() string {
+	return `aerodrome`
+}
+
+func (s tAeroAPRSource) Priority() int {
+	return APR_SOURCE_PRIORITY_VELODROME
+}
+
+func (s tAeroAPRSource) Match(ctx *TAPRSourceContext) bool {
+	return ctx.ChainID == 8453
+}
+
+func (s tAeroAPRSource) Compute(ctx *TAPRSourceContext) (TForwardAPY, bool) {
+	aeroPool, ok := isAeroVault(ctx.ChainID, ctx.Vault)
+	if !ok {
+		return TForwardAPY{}, false
+	}
+	forwardAPY := computeVeloLikeForwardAPY(ctx.Vault, ctx.Strategies, aeroPool)
+	return applyVeloGaugeComposite(ctx.Vault, aeroPool, forwardAPY), true
+}
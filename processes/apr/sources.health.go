@@ -0,0 +1,166 @@
+package apr
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+)
+
+/**************************************************************************************************
+** The external sources the forward APRs are computed from (the Curve API, Convex, the lending
+** markets, Pendle, the Velodrome and Aerodrome gauges, the Velodrome sugar, the APR oracle, the
+** veYFI gauges and the dYFI redemption, ...) are tracked chain by chain: the last
+** success and error, the error counts and the last APR_SOURCE_SAMPLES_SIZE values returned, for a
+** broken integration to be caught before the vaults using it silently show partial APYs.
+**************************************************************************************************/
+const APR_SOURCE_SAMPLES_SIZE = 5
+
+const (
+	APR_SOURCE_CURVE_GAUGES   = `curve.gauges`
+	APR_SOURCE_CURVE_POOLS    = `curve.pools`
+	APR_SOURCE_CURVE_SUBGRAPH = `curve.subgraph`
+	APR_SOURCE_CONVEX         = `convex`
+	APR_SOURCE_PENDLE         = `pendle`
+	APR_SOURCE_VELO_GAUGES    = `velodrome.gauges`
+	APR_SOURCE_VELO_FEES      = `velodrome.fees`
+	APR_SOURCE_GAMMA_FEES     = `gamma.fees`
+	APR_SOURCE_UNIV3_FEES     = `univ3.fees`
+	APR_SOURCE_VELO_SUGAR     = `velodrome.sugar`
+	APR_SOURCE_APR_ORACLE     = `apr.oracle`
+	APR_SOURCE_VEYFI_GAUGES   = `veyfi.gauges`
+	APR_SOURCE_DYFI           = `dyfi.redemption`
+	APR_SOURCE_LENDING_PREFIX = `lending.` // Followed by the LENDING_PROTOCOL_* of the market
+)
+
+const APR_SOURCE_SAMPLE_COUNT = `count` // Key of the samples of the sources returning lists
+const APR_SOURCE_ERROR_NO_RESULT = `no result returned`
+
+type TAPRSourceSample struct {
+	Key       string  `json:"key"`
+	Value     float64 `json:"value"`
+	Timestamp int64   `json:"timestamp"`
+}
+
+type TAPRSourceHealth struct {
+	ChainID           uint64             `json:"chainID"`
+	Source            string             `json:"source"`
+	LastSuccess       int64              `json:"lastSuccess"`
+	LastError         int64              `json:"lastError"`
+	LastErrorMessage  string             `json:"lastErrorMessage,omitempty"`
+	SuccessCount      uint64             `json:"successCount"`
+	ErrorCount        uint64             `json:"errorCount"`
+	ConsecutiveErrors uint64             `json:"consecutiveErrors"`
+	Samples           []TAPRSourceSample `json:"samples"`
+}
+
+var (
+	aprSourcesHealth    = make(map[uint64]map[string]*TAPRSourceHealth)
+	aprSourcesHealthMtx sync.RWMutex
+)
+
+func getAPRSourceHealth(chainID uint64, source string) *TAPRSourceHealth {
+	if _, ok := aprSourcesHealth[chainID]; !ok {
+		aprSourcesHealth[chainID] = make(map[string]*TAPRSourceHealth)
+	}
+	health, ok := aprSourcesHealth[chainID][source]
+	if !ok {
+		health = &TAPRSourceHealth{ChainID: chainID, Source: source, Samples: []TAPRSourceSample{}}
+		aprSourcesHealth[chainID][source] = health
+	}
+	return health
+}
+
+/**************************************************************************************************
+** recordAPRSourceSuccess records a successful fetch from a source, with one of the values it
+** returned, the oldest sample being dropped once there are APR_SOURCE_SAMPLES_SIZE of them.
+**************************************************************************************************/
+func recordAPRSourceSuccess(chainID uint64, source string, key string, value float64) {
+	now := time.Now().Unix()
+	aprSourcesHealthMtx.Lock()
+	defer aprSourcesHealthMtx.Unlock()
+
+	health := getAPRSourceHealth(chainID, source)
+	health.LastSuccess = now
+	health.SuccessCount++
+	health.ConsecutiveErrors = 0
+	health.Samples = append(health.Samples, TAPRSourceSample{Key: key, Value: value, Timestamp: now})
+	if len(health.Samples) > APR_SOURCE_SAMPLES_SIZE {
+		health.Samples = health.Samples[len(health.Samples)-APR_SOURCE_SAMPLES_SIZE:]
+	}
+}
+
+/**************************************************************************************************
+** recordAPRSourceValue records a value returned by a source as a success, a nil value being an
+** error.
+**************************************************************************************************/
+func recordAPRSourceValue(chainID uint64, source string, key string, value *bigNumber.Float) {
+	if value == nil {
+		recordAPRSourceError(chainID, source, APR_SOURCE_ERROR_NO_RESULT+` for `+key)
+		return
+	}
+	asFloat, _ := value.Float64()
+	recordAPRSourceSuccess(chainID, source, key, asFloat)
+}
+
+/**************************************************************************************************
+** recordAPRSourceError records a failed fetch from a source.
+**************************************************************************************************/
+func recordAPRSourceError(chainID uint64, source string, message string) {
+	aprSourcesHealthMtx.Lock()
+	defer aprSourcesHealthMtx.Unlock()
+
+	health := getAPRSourceHealth(chainID, source)
+	health.LastError = time.Now().Unix()
+	health.LastErrorMessage = message
+	health.ErrorCount++
+	health.ConsecutiveErrors++
+}
+
+/**************************************************************************************************
+** RecordVeloSugarFetch records a fetch of the Velodrome sugar, the prices the Velodrome APRs are
+** computed from. It's set as the prices.OnSugarFetched hook by the daemon.
+**************************************************************************************************/
+func RecordVeloSugarFetch(chainID uint64, count int, err error) {
+	if err != nil {
+		recordAPRSourceError(chainID, APR_SOURCE_VELO_SUGAR, err.Error())
+		return
+	}
+	if count == 0 {
+		recordAPRSourceError(chainID, APR_SOURCE_VELO_SUGAR, APR_SOURCE_ERROR_NO_RESULT)
+		return
+	}
+	recordAPRSourceSuccess(chainID, APR_SOURCE_VELO_SUGAR, APR_SOURCE_SAMPLE_COUNT, float64(count))
+}
+
+/**************************************************************************************************
+** ListAPRSourcesHealth returns the health of the APR sources used by a chain, sorted by source.
+**************************************************************************************************/
+func ListAPRSourcesHealth(chainID uint64) []TAPRSourceHealth {
+	aprSourcesHealthMtx.RLock()
+	defer aprSourcesHealthMtx.RUnlock()
+
+	sources := []TAPRSourceHealth{}
+	for _, health := range aprSourcesHealth[chainID] {
+		source := *health
+		source.Samples = append([]TAPRSourceSample{}, health.Samples...)
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Source < sources[j].Source
+	})
+	return sources
+}
+
+/**************************************************************************************************
+** ListAllAPRSourcesHealth returns the health of the APR sources of all the supported chains.
+**************************************************************************************************/
+func ListAllAPRSourcesHealth() []TAPRSourceHealth {
+	sources := []TAPRSourceHealth{}
+	for _, chainID := range env.SUPPORTED_CHAIN_IDS {
+		sources = append(sources, ListAPRSourcesHealth(chainID)...)
+	}
+	return sources
+}
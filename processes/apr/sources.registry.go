@@ -0,0 +1,203 @@
+package apr
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** The forward APY of the vaults is computed by a registry of APR sources, one per protocol (Curve,
+** Velodrome, Gamma, Pendle, ...) and per file (source.<protocol>.go), registered from their init
+** function, the APR oracle of the v3 vaults being one of them. A source matches the vaults it knows
+** and computes their forward APY. Adding a protocol is adding a file, without touching the
+** computation flow of ComputeChainAPY.
+** The sources are tried by increasing priority, the first one matching and returning an APY being
+** kept: the protocols with a dedicated asset (Pendle markets, Gamma hypervisors, Velodrome pools)
+** come before Curve, and the APR oracle of the v3 vaults comes last. The sources can be disabled by
+** chain with APR_SOURCES_DISABLED, and reordered or disabled by the config directory of the
+** sources (see loadAPRSourcesConfig).
+**************************************************************************************************/
+type TAPRSource interface {
+	// Name returns the name of the source, used to disable it
+	Name() string
+	// Priority returns the rank of the source, the lowest being tried first
+	Priority() int
+	// Match returns true if the vault may be computed by the source. It must be cheap: the
+	// expensive checks go in Compute
+	Match(ctx *TAPRSourceContext) bool
+	// Compute returns the forward APY of the vault. A false boolean keeps its current forward APY
+	Compute(ctx *TAPRSourceContext) (TForwardAPY, bool)
+}
+
+/**************************************************************************************************
+** TAPRSourcePreparer is implemented by the sources needing the data of a whole chain, fetched once
+** per computation before the vaults are processed: Prepare returns the source bound to this data.
+**************************************************************************************************/
+type TAPRSourcePreparer interface {
+	TAPRSource
+	Prepare(chainID uint64) TAPRSource
+}
+
+/**************************************************************************************************
+** TAPRSourceContext is the vault a source is asked about: its forward vault (with the pending fees
+** when they are used), its strategies and the APY being computed, for the sources also setting its
+** extra APRs.
+**************************************************************************************************/
+type TAPRSourceContext struct {
+	ChainID    uint64
+	Vault      models.TVault
+	Strategies map[string]models.TStrategy
+	VaultAPY   *TVaultAPY
+}
+
+/**************************************************************************************************
+** TAPRSourceConfig is the config of a source on a chain, read from the config directory of the
+** sources. An unset priority keeps the one of the source.
+**************************************************************************************************/
+type TAPRSourceConfig struct {
+	Priority *int `json:"priority"`
+	Disabled bool `json:"disabled"`
+}
+
+const (
+	APR_SOURCE_PRIORITY_PENDLE    = 10
+	APR_SOURCE_PRIORITY_GAMMA     = 20
+	APR_SOURCE_PRIORITY_VELODROME = 30
+	APR_SOURCE_PRIORITY_UNIV3     = 40
+	APR_SOURCE_PRIORITY_CURVE     = 50
+	APR_SOURCE_PRIORITY_V3_ORACLE = 1000
+)
+
+var aprSources = map[string]TAPRSource{}
+var aprSourcesConfig = map[uint64]map[string]TAPRSourceConfig{}
+var aprSourcesMtx sync.RWMutex
+
+/**************************************************************************************************
+** RegisterAPRSource adds a source to the registry, replacing the one with the same name.
+**************************************************************************************************/
+func RegisterAPRSource(source TAPRSource) {
+	aprSourcesMtx.Lock()
+	defer aprSourcesMtx.Unlock()
+	aprSources[source.Name()] = source
+}
+
+/**************************************************************************************************
+** ListAPRSources returns the names of the registered sources, sorted.
+**************************************************************************************************/
+func ListAPRSources() []string {
+	aprSourcesMtx.RLock()
+	defer aprSourcesMtx.RUnlock()
+	names := []string{}
+	for name := range aprSources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/**************************************************************************************************
+** loadAPRSourcesConfig reads the config of the sources of a chain, in
+** BASE_DATA_PATH/meta/aprSources/<chainID>.json, reloaded on every APY computation:
+** { "<source>": { "priority": 15, "disabled": true } }
+** The file is optional: without it, or if it cannot be decoded, the sources keep their priority and
+** only APR_SOURCES_DISABLED applies.
+**************************************************************************************************/
+func loadAPRSourcesConfig(chainID uint64) {
+	config := map[string]TAPRSourceConfig{}
+	filePath := env.BASE_DATA_PATH + `/meta/aprSources/` + strconv.FormatUint(chainID, 10) + `.json`
+	if content, err := os.ReadFile(filePath); err == nil {
+		if err := json.Unmarshal(content, &config); err != nil {
+			logs.Error(`Failed to decode the APR sources config of chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+			config = map[string]TAPRSourceConfig{}
+		}
+	}
+
+	aprSourcesMtx.Lock()
+	aprSourcesConfig[chainID] = config
+	aprSourcesMtx.Unlock()
+}
+
+/**************************************************************************************************
+** IsAPRSourceDisabled returns true if a source is disabled on a chain, or on all of them, by
+** APR_SOURCES_DISABLED or by the config directory of the sources.
+**************************************************************************************************/
+func IsAPRSourceDisabled(chainID uint64, name string) bool {
+	aprSourcesMtx.RLock()
+	defer aprSourcesMtx.RUnlock()
+	return isAPRSourceDisabled(chainID, name)
+}
+
+func isAPRSourceDisabled(chainID uint64, name string) bool {
+	return env.APR_SOURCES_DISABLED[chainID][name] || env.APR_SOURCES_DISABLED[0][name] || aprSourcesConfig[chainID][name].Disabled
+}
+
+/**************************************************************************************************
+** getAPRSourcePriority returns the priority of a source on a chain, the one of the config directory
+** replacing the one of the source.
+**************************************************************************************************/
+func getAPRSourcePriority(chainID uint64, source TAPRSource) int {
+	if priority := aprSourcesConfig[chainID][source.Name()].Priority; priority != nil {
+		return *priority
+	}
+	return source.Priority()
+}
+
+/**************************************************************************************************
+** listEnabledAPRSources returns the sources enabled on a chain, sorted by priority, then by name.
+**************************************************************************************************/
+func listEnabledAPRSources(chainID uint64) []TAPRSource {
+	aprSourcesMtx.RLock()
+	defer aprSourcesMtx.RUnlock()
+	sources := []TAPRSource{}
+	for _, source := range aprSources {
+		if !isAPRSourceDisabled(chainID, source.Name()) {
+			sources = append(sources, source)
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		priorityI, priorityJ := getAPRSourcePriority(chainID, sources[i]), getAPRSourcePriority(chainID, sources[j])
+		if priorityI != priorityJ {
+			return priorityI < priorityJ
+		}
+		return sources[i].Name() < sources[j].Name()
+	})
+	return sources
+}
+
+/**************************************************************************************************
+** prepareAPRSources returns the sources enabled on a chain, bound to the data of the chain for the
+** ones needing it.
+**************************************************************************************************/
+func prepareAPRSources(chainID uint64) []TAPRSource {
+	loadAPRSourcesConfig(chainID)
+	sources := listEnabledAPRSources(chainID)
+	for i, source := range sources {
+		if preparer, ok := source.(TAPRSourcePreparer); ok {
+			sources[i] = preparer.Prepare(chainID)
+		}
+	}
+	return sources
+}
+
+/**************************************************************************************************
+** computeSourcesForwardAPY returns the forward APY of the first source matching the vault and
+** returning an APY. The boolean is false if no source did.
+**************************************************************************************************/
+func computeSourcesForwardAPY(sources []TAPRSource, ctx *TAPRSourceContext) (TForwardAPY, bool) {
+	for _, source := range sources {
+		if !source.Match(ctx) {
+			continue
+		}
+		if forwardAPY, ok := source.Compute(ctx); ok {
+			return forwardAPY, true
+		}
+	}
+	return TForwardAPY{}, false
+}
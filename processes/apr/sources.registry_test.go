@@ -0,0 +1,125 @@
+package apr
+
+import (
+	"os"
+	"testing"
+
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+)
+
+type tTestAPRSource struct {
+	name     string
+	priority int
+	matches  bool
+	apy      float64
+	ok       bool
+}
+
+func (s tTestAPRSource) Name() string                      { return s.name }
+func (s tTestAPRSource) Priority() int                     { return s.priority }
+func (s tTestAPRSource) Match(ctx *TAPRSourceContext) bool { return s.matches }
+func (s tTestAPRSource) Compute(ctx *TAPRSourceContext) (TForwardAPY, bool) {
+	return TForwardAPY{Type: s.name, NetAPY: bigNumber.NewFloat(s.apy)}, s.ok
+}
+
+func TestBuiltinAPRSourcesAreRegistered(t *testing.T) {
+	registered := map[string]bool{}
+	for _, name := range ListAPRSources() {
+		registered[name] = true
+	}
+	for _, name := range []string{`aerodrome`, `curve`, `gamma`, `pendle`, `univ3`, `v3`, `velodrome`} {
+		if !registered[name] {
+			t.Errorf("expected the %s source to be registered", name)
+		}
+	}
+}
+
+func TestAPRSourcesPriority(t *testing.T) {
+	order := map[string]int{}
+	for i, source := range listEnabledAPRSources(1) {
+		order[source.Name()] = i
+	}
+	for _, pair := range [][2]string{{`pendle`, `gamma`}, {`gamma`, `velodrome`}, {`velodrome`, `univ3`}, {`univ3`, `curve`}, {`curve`, `v3`}} {
+		if order[pair[0]] >= order[pair[1]] {
+			t.Errorf("expected the %s source to be tried before the %s source", pair[0], pair[1])
+		}
+	}
+}
+
+func TestAPRSourcesConfig(t *testing.T) {
+	previous := env.BASE_DATA_PATH
+	defer func() { env.BASE_DATA_PATH = previous }()
+	env.BASE_DATA_PATH = t.TempDir()
+	if err := os.MkdirAll(env.BASE_DATA_PATH+`/meta/aprSources`, 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `{ "curve": { "priority": 1 }, "pendle": { "disabled": true } }`
+	if err := os.WriteFile(env.BASE_DATA_PATH+`/meta/aprSources/1.json`, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loadAPRSourcesConfig(1)
+	defer func() {
+		aprSourcesMtx.Lock()
+		delete(aprSourcesConfig, 1)
+		aprSourcesMtx.Unlock()
+	}()
+
+	sources := listEnabledAPRSources(1)
+	if len(sources) == 0 || sources[0].Name() != `curve` {
+		t.Error("expected the curve source to be tried first")
+	}
+	for _, source := range sources {
+		if source.Name() == `pendle` {
+			t.Error("expected the pendle source to be disabled on chain 1")
+		}
+	}
+	if !IsAPRSourceDisabled(1, `pendle`) || IsAPRSourceDisabled(10, `pendle`) {
+		t.Error("expected pendle disabled only on chain 1")
+	}
+}
+
+func TestComputeSourcesForwardAPY(t *testing.T) {
+	sources := []TAPRSource{
+		tTestAPRSource{name: `a`, matches: false, apy: 0.1, ok: true},
+		tTestAPRSource{name: `b`, matches: true, apy: 0.2, ok: false},
+		tTestAPRSource{name: `c`, matches: true, apy: 0.3, ok: true},
+		tTestAPRSource{name: `d`, matches: true, apy: 0.4, ok: true},
+	}
+	forwardAPY, ok := computeSourcesForwardAPY(sources, &TAPRSourceContext{})
+	if !ok || forwardAPY.Type != `c` {
+		t.Errorf("expected the first matching source returning an APY, got %q", forwardAPY.Type)
+	}
+	if _, ok := computeSourcesForwardAPY(sources[:2], &TAPRSourceContext{}); ok {
+		t.Error("expected no APY when no source returns one")
+	}
+}
+
+func TestAPRSourcesDisabledByChain(t *testing.T) {
+	previous := env.APR_SOURCES_DISABLED
+	defer func() { env.APR_SOURCES_DISABLED = previous }()
+	env.APR_SOURCES_DISABLED = map[uint64]map[string]bool{
+		0:  {`velodrome`: true},
+		10: {`pendle`: true},
+	}
+	RegisterAPRSource(tTestAPRSource{name: `zz-test`})
+	defer func() {
+		aprSourcesMtx.Lock()
+		delete(aprSources, `zz-test`)
+		aprSourcesMtx.Unlock()
+	}()
+
+	names := map[string]bool{}
+	for _, source := range listEnabledAPRSources(10) {
+		names[source.Name()] = true
+	}
+	if names[`velodrome`] || names[`pendle`] {
+		t.Error("expected the velodrome and pendle sources to be disabled on chain 10")
+	}
+	if !names[`zz-test`] {
+		t.Error("expected a registered source to be enabled")
+	}
+	if !IsAPRSourceDisabled(1, `velodrome`) || IsAPRSourceDisabled(1, `pendle`) {
+		t.Error("expected velodrome disabled on all the chains and pendle only on chain 10")
+	}
+}
@@ -0,0 +1,236 @@
+package assets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The icons of the tokens and vaults are fetched from their source (the token assets repository)
+** and pinned to IPFS, with a metadata document per token, for the frontends not to depend on the
+** GitHub-backed URLs, which break and rate-limit. An asset is fetched again when its source
+** changes or every ASSETS_REFRESH_INTERVAL, and only pinned again when its content changed.
+**************************************************************************************************/
+const ASSETS_REFRESH_INTERVAL = 7 * 24 * time.Hour
+const ASSETS_MAX_ICON_SIZE = 1 << 20
+
+/**************************************************************************************************
+** TAssetMetadata is the metadata document pinned for each token.
+**************************************************************************************************/
+type TAssetMetadata struct {
+	ChainID     uint64 `json:"chainID"`
+	Address     string `json:"address"`
+	Name        string `json:"name"`
+	Symbol      string `json:"symbol"`
+	Description string `json:"description"`
+	Icon        string `json:"icon,omitempty"`
+}
+
+type tPinResponse struct {
+	IpfsHash string `json:"IpfsHash"`
+}
+
+var assetsClient = &http.Client{Timeout: 30 * time.Second}
+
+/**************************************************************************************************
+** IsEnabled returns true if a pinning service is configured.
+**************************************************************************************************/
+func IsEnabled() bool {
+	return env.IPFS_PINNING_JWT != ``
+}
+
+/**************************************************************************************************
+** IPFSURI returns the ipfs:// URI of a CID, and GatewayURL its URL on the configured gateway.
+**************************************************************************************************/
+func IPFSURI(cid string) string {
+	return `ipfs://` + cid
+}
+
+func GatewayURL(cid string) string {
+	return env.IPFS_GATEWAY_URL + cid
+}
+
+/**************************************************************************************************
+** ResolveAssets returns the icon to serve for a token, the gateway URL of its pinned icon or its
+** source URL, along with the ipfs:// URIs of its icon and of its metadata, empty when not pinned.
+**************************************************************************************************/
+func ResolveAssets(chainID uint64, tokenAddress common.Address, icon string) (string, string, string) {
+	asset, ok := storage.GetPinnedAsset(chainID, tokenAddress)
+	if !ok {
+		return icon, ``, ``
+	}
+	iconIPFS, metadataIPFS := ``, ``
+	if asset.IconCID != `` && asset.IconSource == icon {
+		icon, iconIPFS = GatewayURL(asset.IconCID), IPFSURI(asset.IconCID)
+	}
+	if asset.MetadataCID != `` {
+		metadataIPFS = IPFSURI(asset.MetadataCID)
+	}
+	return icon, iconIPFS, metadataIPFS
+}
+
+/**************************************************************************************************
+** pinFile pins a file to the pinning service and returns its CID.
+**************************************************************************************************/
+func pinFile(name string, content []byte) (string, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile(`file`, name)
+	if err != nil {
+		return ``, err
+	}
+	if _, err := part.Write(content); err != nil {
+		return ``, err
+	}
+	if err := writer.WriteField(`pinataMetadata`, `{"name":"`+name+`"}`); err != nil {
+		return ``, err
+	}
+	if err := writer.Close(); err != nil {
+		return ``, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, env.IPFS_PINNING_API_URL+`/pinning/pinFileToIPFS`, body)
+	if err != nil {
+		return ``, err
+	}
+	req.Header.Set(`Content-Type`, writer.FormDataContentType())
+	req.Header.Set(`Authorization`, `Bearer `+env.IPFS_PINNING_JWT)
+	resp, err := assetsClient.Do(req)
+	if err != nil {
+		return ``, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return ``, errors.New(`pinning failed with status ` + strconv.Itoa(resp.StatusCode))
+	}
+	var result tPinResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return ``, err
+	}
+	if result.IpfsHash == `` {
+		return ``, errors.New(`pinning returned no CID`)
+	}
+	return result.IpfsHash, nil
+}
+
+/**************************************************************************************************
+** fetchIcon downloads an icon from its source, up to ASSETS_MAX_ICON_SIZE.
+**************************************************************************************************/
+func fetchIcon(uri string) ([]byte, error) {
+	resp, err := assetsClient.Get(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(`icon fetch failed with status ` + strconv.Itoa(resp.StatusCode))
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, ASSETS_MAX_ICON_SIZE))
+}
+
+func hashContent(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+/**************************************************************************************************
+** pinTokenAssets pins the icon and the metadata of a token when they changed since their last
+** pin, and returns the updated pins. A token whose icon can't be fetched still gets its metadata
+** pinned, without icon.
+**************************************************************************************************/
+func pinTokenAssets(chainID uint64, token models.TERC20Token, description string, asset storage.TPinnedAsset) (storage.TPinnedAsset, error) {
+	name := strconv.FormatUint(chainID, 10) + `-` + strings.ToLower(token.Address.Hex())
+	isStale := time.Since(time.Unix(asset.PinnedAt, 0)) > ASSETS_REFRESH_INTERVAL
+	previous := asset // Kept when the pinning service fails, for the next refresh to retry
+
+	var iconErr error
+	if token.Icon != `` && (asset.IconSource != token.Icon || isStale) {
+		asset.IconSource = token.Icon
+		asset.PinnedAt = time.Now().Unix()
+		content, err := fetchIcon(token.Icon)
+		if err != nil {
+			asset.IconHash, asset.IconCID = ``, `` // Fetched again after ASSETS_REFRESH_INTERVAL
+			iconErr = err
+		} else if hash := hashContent(content); hash != asset.IconHash || asset.IconCID == `` {
+			cid, err := pinFile(name+`.png`, content)
+			if err != nil {
+				return previous, err
+			}
+			asset.IconHash, asset.IconCID = hash, cid
+		}
+	}
+
+	metadata := TAssetMetadata{
+		ChainID:     chainID,
+		Address:     token.Address.Hex(),
+		Name:        token.DisplayName,
+		Symbol:      token.DisplaySymbol,
+		Description: description,
+	}
+	if metadata.Name == `` {
+		metadata.Name = token.Name
+	}
+	if metadata.Symbol == `` {
+		metadata.Symbol = token.Symbol
+	}
+	if asset.IconCID != `` {
+		metadata.Icon = IPFSURI(asset.IconCID)
+	}
+	content, _ := json.Marshal(metadata)
+	if hash := hashContent(content); hash != asset.MetadataHash || asset.MetadataCID == `` {
+		cid, err := pinFile(name+`.json`, content)
+		if err != nil {
+			return previous, err
+		}
+		asset.MetadataHash, asset.MetadataCID = hash, cid
+	}
+	return asset, iconErr
+}
+
+/**************************************************************************************************
+** PinChainAssets pins the icons and the metadata of the tokens of a chain, the description of a
+** vault being the one of its metadata. This is a no-op if no pinning service is configured.
+**************************************************************************************************/
+func PinChainAssets(chainID uint64) {
+	if !IsEnabled() {
+		return
+	}
+
+	vaults, _ := storage.ListVaults(chainID)
+	_, tokens := storage.ListERC20(chainID)
+	updatedCount := 0
+	for _, token := range tokens {
+		description := token.Description
+		if vault, ok := vaults[token.Address]; ok && vault.Metadata.Description != `` {
+			description = vault.Metadata.Description
+		}
+		asset, _ := storage.GetPinnedAsset(chainID, token.Address)
+		updated, err := pinTokenAssets(chainID, token, description, asset)
+		if err != nil {
+			logs.Warning(`Failed to pin the assets of token ` + token.Address.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+		}
+		if updated != asset {
+			storage.StorePinnedAsset(chainID, token.Address, updated)
+			updatedCount++
+		}
+	}
+	if updatedCount > 0 {
+		storage.StorePinnedAssetsToJson(chainID)
+	}
+	logs.Info(`Updated the IPFS pins of ` + strconv.Itoa(updatedCount) + ` tokens on chain ` + strconv.FormatUint(chainID, 10))
+}
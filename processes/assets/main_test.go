@@ -0,0 +1,33 @@
+package assets
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** TestResolveAssets checks that the pinned icon is served from the gateway only while it is the
+** one of the current source, the metadata being served as soon as it is pinned.
+**************************************************************************************************/
+func TestResolveAssets(t *testing.T) {
+	chainID := uint64(1)
+	token := common.HexToAddress(`0x1`)
+	source := `https://example.com/logo-128.png`
+
+	if icon, iconIPFS, metadataIPFS := ResolveAssets(chainID, token, source); icon != source || iconIPFS != `` || metadataIPFS != `` {
+		t.Fatalf("expected the source of a token without pins, got %s %s %s", icon, iconIPFS, metadataIPFS)
+	}
+
+	storage.StorePinnedAsset(chainID, token, storage.TPinnedAsset{IconSource: source, IconCID: `bafyicon`, MetadataCID: `bafymeta`})
+	icon, iconIPFS, metadataIPFS := ResolveAssets(chainID, token, source)
+	if icon != GatewayURL(`bafyicon`) || iconIPFS != `ipfs://bafyicon` || metadataIPFS != `ipfs://bafymeta` {
+		t.Errorf("unexpected pinned assets %s %s %s", icon, iconIPFS, metadataIPFS)
+	}
+
+	newSource := `https://example.com/new-logo-128.png`
+	if icon, iconIPFS, _ := ResolveAssets(chainID, token, newSource); icon != newSource || iconIPFS != `` {
+		t.Errorf("expected the new source until it is pinned, got %s %s", icon, iconIPFS)
+	}
+}
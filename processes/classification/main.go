@@ -0,0 +1,152 @@
+package classification
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The asset classes group the tokens by what they are worth: a stablecoin, ETH or BTC, whatever
+** their wrapper. A token is classified from its symbol and its category, a token made of other
+** tokens (an LP token, a vault) being in a class when all of them are. A vault is classified from
+** the stable base asset of its metadata when set, from its asset otherwise.
+**************************************************************************************************/
+type TAssetClass string
+
+const (
+	AssetClassStables TAssetClass = `stables`
+	AssetClassETH     TAssetClass = `eth`
+	AssetClassBTC     TAssetClass = `btc`
+)
+
+var ASSET_CLASSES = []TAssetClass{AssetClassStables, AssetClassETH, AssetClassBTC}
+
+/**************************************************************************************************
+** The patterns of the symbols of each class, matched on the lowercased symbol, the BTC ones first
+** for the pairs of both. STABLE_BASE_ASSETS maps the stable base assets of the vault metadata to
+** their class.
+**************************************************************************************************/
+var BTC_SYMBOL_PATTERNS = []string{`btc`}
+var ETH_SYMBOL_PATTERNS = []string{`eth`}
+var STABLES_SYMBOL_PATTERNS = []string{`usd`, `dai`, `dola`, `mim`, `gho`, `frax`, `eur`, `gbp`, `chf`, `jpy`, `krw`, `aud`}
+var ETH_SYMBOL_EXCEPTIONS = []string{`ethfi`, `ethena`}
+
+var STABLE_BASE_ASSETS = map[string]TAssetClass{
+	`USD`:     AssetClassStables,
+	`EUR`:     AssetClassStables,
+	`AUD`:     AssetClassStables,
+	`CHF`:     AssetClassStables,
+	`KRW`:     AssetClassStables,
+	`GBP`:     AssetClassStables,
+	`JPY`:     AssetClassStables,
+	`Ether`:   AssetClassETH,
+	`Bitcoin`: AssetClassBTC,
+}
+
+/**************************************************************************************************
+** MAX_CLASSIFICATION_DEPTH bounds the nesting of the tokens made of other tokens.
+**************************************************************************************************/
+const MAX_CLASSIFICATION_DEPTH = 3
+
+/**************************************************************************************************
+** IsAssetClass returns true for a known asset class.
+**************************************************************************************************/
+func IsAssetClass(class string) bool {
+	for _, assetClass := range ASSET_CLASSES {
+		if string(assetClass) == class {
+			return true
+		}
+	}
+	return false
+}
+
+/**************************************************************************************************
+** classifySymbol returns the class of a symbol, false if it matches none.
+**************************************************************************************************/
+func classifySymbol(symbol string) (TAssetClass, bool) {
+	symbol = strings.ToLower(symbol)
+	for _, pattern := range BTC_SYMBOL_PATTERNS {
+		if strings.Contains(symbol, pattern) {
+			return AssetClassBTC, true
+		}
+	}
+	for _, pattern := range ETH_SYMBOL_PATTERNS {
+		if strings.Contains(symbol, pattern) && !isETHException(symbol) {
+			return AssetClassETH, true
+		}
+	}
+	for _, pattern := range STABLES_SYMBOL_PATTERNS {
+		if strings.Contains(symbol, pattern) {
+			return AssetClassStables, true
+		}
+	}
+	return ``, false
+}
+
+func isETHException(symbol string) bool {
+	for _, exception := range ETH_SYMBOL_EXCEPTIONS {
+		if strings.Contains(symbol, exception) {
+			return true
+		}
+	}
+	return false
+}
+
+/**************************************************************************************************
+** classifyComponents returns the class shared by all the components of a token, false if they
+** are not all in the same class.
+**************************************************************************************************/
+func classifyComponents(chainID uint64, components []common.Address, depth int) (TAssetClass, bool) {
+	if len(components) == 0 {
+		return ``, false
+	}
+	shared := TAssetClass(``)
+	for _, component := range components {
+		class, ok := classifyToken(chainID, component, depth+1)
+		if !ok || (shared != `` && class != shared) {
+			return ``, false
+		}
+		shared = class
+	}
+	return shared, true
+}
+
+func classifyToken(chainID uint64, tokenAddress common.Address, depth int) (TAssetClass, bool) {
+	if depth > MAX_CLASSIFICATION_DEPTH {
+		return ``, false
+	}
+	token, ok := storage.GetERC20(chainID, tokenAddress)
+	if !ok {
+		return ``, false
+	}
+	if vault, ok := storage.GetVault(chainID, tokenAddress); ok && token.IsVaultLike() {
+		return classifyToken(chainID, vault.AssetAddress, depth+1)
+	}
+	if len(token.UnderlyingTokensAddresses) > 0 {
+		return classifyComponents(chainID, token.UnderlyingTokensAddresses, depth)
+	}
+	if token.Category == `Stablecoin` {
+		return AssetClassStables, true
+	}
+	return classifySymbol(token.Symbol)
+}
+
+/**************************************************************************************************
+** ClassifyToken returns the asset class of a token, false if it is in none.
+**************************************************************************************************/
+func ClassifyToken(chainID uint64, tokenAddress common.Address) (TAssetClass, bool) {
+	return classifyToken(chainID, tokenAddress, 0)
+}
+
+/**************************************************************************************************
+** ClassifyVault returns the asset class of a vault, false if it is in none.
+**************************************************************************************************/
+func ClassifyVault(vault models.TVault) (TAssetClass, bool) {
+	if class, ok := STABLE_BASE_ASSETS[vault.Metadata.Stability.StableBaseAsset]; ok {
+		return class, true
+	}
+	return ClassifyToken(vault.ChainID, vault.AssetAddress)
+}
@@ -0,0 +1,35 @@
+package classification
+
+import "testing"
+
+func TestClassifySymbol(t *testing.T) {
+	cases := map[string]TAssetClass{
+		`USDC`:    AssetClassStables,
+		`crvUSD`:  AssetClassStables,
+		`DAI`:     AssetClassStables,
+		`WETH`:    AssetClassETH,
+		`stETH`:   AssetClassETH,
+		`WBTC`:    AssetClassBTC,
+		`tBTC`:    AssetClassBTC,
+		`WBTCETH`: AssetClassBTC,
+	}
+	for symbol, expected := range cases {
+		if class, ok := classifySymbol(symbol); !ok || class != expected {
+			t.Errorf("expected %s to be in %s, got %v %s", symbol, expected, ok, class)
+		}
+	}
+	for _, symbol := range []string{`CRV`, `YFI`, `ETHFI`} {
+		if class, ok := classifySymbol(symbol); ok {
+			t.Errorf("expected %s to be in no class, got %s", symbol, class)
+		}
+	}
+}
+
+func TestIsAssetClass(t *testing.T) {
+	if !IsAssetClass(`stables`) || !IsAssetClass(`eth`) || !IsAssetClass(`btc`) {
+		t.Error("expected stables, eth and btc to be asset classes")
+	}
+	if IsAssetClass(`crv`) {
+		t.Error("expected crv not to be an asset class")
+	}
+}
@@ -0,0 +1,67 @@
+package competitors
+
+import (
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
+)
+
+/**************************************************************************************************
+** BEEFY_CHAIN_IDS maps the chain names used by the Beefy API to the chain IDs.
+**************************************************************************************************/
+var BEEFY_CHAIN_IDS = map[string]uint64{
+	`ethereum`: 1,
+	`optimism`: 10,
+	`gnosis`:   100,
+	`polygon`:  137,
+	`fantom`:   250,
+	`base`:     8453,
+	`arbitrum`: 42161,
+}
+
+type tBeefyVault struct {
+	ID                  string `json:"id"`
+	Name                string `json:"name"`
+	Chain               string `json:"chain"`
+	Status              string `json:"status"`
+	TokenAddress        string `json:"tokenAddress"`
+	EarnContractAddress string `json:"earnContractAddress"`
+}
+
+/**************************************************************************************************
+** fetchBeefyVaults fetches the active Beefy vaults with their APY (`/apy`, already a fraction) and
+** their TVL (`/tvl`, by chain ID then by vault ID).
+**************************************************************************************************/
+func fetchBeefyVaults() ([]TCompetitorVault, bool) {
+	beefyVaults, err := helpers.FetchJSONWithReject[[]tBeefyVault](env.BEEFY_API_URL + `/vaults`)
+	if err != nil {
+		return nil, false
+	}
+	apys, err := helpers.FetchJSONWithReject[map[string]float64](env.BEEFY_API_URL + `/apy`)
+	if err != nil {
+		return nil, false
+	}
+	tvls, _ := helpers.FetchJSONWithReject[map[string]map[string]float64](env.BEEFY_API_URL + `/tvl`)
+
+	vaults := []TCompetitorVault{}
+	for _, beefyVault := range beefyVaults {
+		chainID, ok := BEEFY_CHAIN_IDS[beefyVault.Chain]
+		if !ok || beefyVault.Status != `active` || !common.IsHexAddress(beefyVault.TokenAddress) {
+			continue
+		}
+		vaults = append(vaults, TCompetitorVault{
+			Protocol: SOURCE_BEEFY,
+			ID:       beefyVault.ID,
+			ChainID:  chainID,
+			Address:  common.HexToAddress(beefyVault.EarnContractAddress),
+			Token:    common.HexToAddress(beefyVault.TokenAddress),
+			Name:     beefyVault.Name,
+			APY:      apys[beefyVault.ID],
+			TVL:      tvls[strconv.FormatUint(chainID, 10)][beefyVault.ID],
+			URL:      `https://app.beefy.com/vault/` + beefyVault.ID,
+		})
+	}
+	return vaults, true
+}
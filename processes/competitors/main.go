@@ -0,0 +1,156 @@
+package competitors
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/addresses"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+)
+
+/**************************************************************************************************
+** The vaults of the competing protocols are fetched from their public APIs by a background job,
+** again once they are older than COMPETITORS_TTL. A source which could not be fetched is retried
+** after COMPETITORS_MIN_BACKOFF, doubled on each new failure up to COMPETITORS_TTL. The job checks
+** the sources due every COMPETITORS_TICK.
+**************************************************************************************************/
+const COMPETITORS_TTL = 30 * time.Minute
+const COMPETITORS_MIN_BACKOFF = time.Minute
+const COMPETITORS_TICK = 30 * time.Second
+
+const (
+	SOURCE_BEEFY     = `beefy`
+	SOURCE_SOMMELIER = `sommelier`
+)
+
+/**************************************************************************************************
+** TCompetitorVault is a vault of a competing protocol. APY is a fraction (0.042 for 4.2%) and TVL
+** is in USD, both as reported by the protocol. URL is the page of the vault, when known.
+**************************************************************************************************/
+type TCompetitorVault struct {
+	Protocol string         `json:"protocol"`
+	ID       string         `json:"id"`
+	ChainID  uint64         `json:"chainID"`
+	Address  common.Address `json:"address"`
+	Token    common.Address `json:"token"`
+	Name     string         `json:"name"`
+	APY      float64        `json:"apy"`
+	TVL      float64        `json:"tvl"`
+	URL      string         `json:"url,omitempty"`
+}
+
+/**************************************************************************************************
+** tCompetitorSource fetches the active vaults of a competing protocol on all the chains. The
+** boolean is false when the source could not be fetched, the vaults fetched before being kept.
+**************************************************************************************************/
+type tCompetitorSource func() ([]TCompetitorVault, bool)
+
+var sources = map[string]tCompetitorSource{
+	SOURCE_BEEFY:     fetchBeefyVaults,
+	SOURCE_SOMMELIER: fetchSommelierVaults,
+}
+
+var (
+	competitorVaults     = make(map[string][]TCompetitorVault)
+	competitorNextFetch  = make(map[string]time.Time)
+	competitorBackoff    = make(map[string]time.Duration)
+	competitorsMtx       sync.RWMutex
+	competitorsStartOnce sync.Once
+)
+
+/**************************************************************************************************
+** IsEnabled returns true if at least one competing protocol is set in COMPETITOR_SOURCES.
+**************************************************************************************************/
+func IsEnabled() bool {
+	return len(ListSources()) > 0
+}
+
+/**************************************************************************************************
+** ListSources returns the competing protocols of COMPETITOR_SOURCES known by the daemon.
+**************************************************************************************************/
+func ListSources() []string {
+	enabled := []string{}
+	for _, source := range env.COMPETITOR_SOURCES {
+		if _, ok := sources[source]; ok && !helpers.Contains(enabled, source) {
+			enabled = append(enabled, source)
+		}
+	}
+	return enabled
+}
+
+/**************************************************************************************************
+** StartRefresh starts the background job fetching the vaults of the enabled competing protocols.
+** The sources enabled by a reload of COMPETITOR_SOURCES are picked up on the next tick.
+**************************************************************************************************/
+func StartRefresh() {
+	competitorsStartOnce.Do(func() {
+		go func() {
+			RefreshCompetitorVaults()
+			ticker := time.NewTicker(COMPETITORS_TICK)
+			defer ticker.Stop()
+			for range ticker.C {
+				RefreshCompetitorVaults()
+			}
+		}()
+	})
+}
+
+/**************************************************************************************************
+** RefreshCompetitorVaults fetches the enabled sources which are due: older than COMPETITORS_TTL,
+** or whose backoff after a failure has elapsed. The upstream APIs are called without holding the
+** lock, for the requests to keep reading the vaults fetched before.
+**************************************************************************************************/
+func RefreshCompetitorVaults() {
+	for _, source := range ListSources() {
+		competitorsMtx.RLock()
+		nextFetch := competitorNextFetch[source]
+		competitorsMtx.RUnlock()
+		if time.Now().Before(nextFetch) {
+			continue
+		}
+
+		vaults, ok := sources[source]()
+
+		competitorsMtx.Lock()
+		if ok {
+			competitorVaults[source] = vaults
+			competitorNextFetch[source] = time.Now().Add(COMPETITORS_TTL)
+			delete(competitorBackoff, source)
+		} else {
+			backoff := competitorBackoff[source] * 2
+			if backoff < COMPETITORS_MIN_BACKOFF {
+				backoff = COMPETITORS_MIN_BACKOFF
+			}
+			if backoff > COMPETITORS_TTL {
+				backoff = COMPETITORS_TTL
+			}
+			competitorBackoff[source] = backoff
+			competitorNextFetch[source] = time.Now().Add(backoff)
+			logs.Warning(`Failed to fetch the vaults of ` + source + `, retrying in ` + backoff.String() + `, the previous ones are kept`)
+		}
+		competitorsMtx.Unlock()
+	}
+}
+
+/**************************************************************************************************
+** ListCompetitorVaults returns the vaults of the enabled competing protocols of a chain whose
+** underlying asset is the given token, as last fetched by the background job. It never calls the
+** upstream APIs.
+**************************************************************************************************/
+func ListCompetitorVaults(chainID uint64, tokenAddress common.Address) []TCompetitorVault {
+	competitorsMtx.RLock()
+	defer competitorsMtx.RUnlock()
+
+	matching := []TCompetitorVault{}
+	for _, source := range ListSources() {
+		for _, vault := range competitorVaults[source] {
+			if vault.ChainID == chainID && addresses.Equals(vault.Token, tokenAddress) {
+				matching = append(matching, vault)
+			}
+		}
+	}
+	return matching
+}
@@ -0,0 +1,51 @@
+package competitors
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
+)
+
+/**************************************************************************************************
+** tSommelierCellar is a cellar of the SOMMELIER_API_URL feed. APY is a fraction and TVL in USD.
+**************************************************************************************************/
+type tSommelierCellar struct {
+	Address string  `json:"address"`
+	ChainID uint64  `json:"chainID"`
+	Asset   string  `json:"asset"`
+	Name    string  `json:"name"`
+	APY     float64 `json:"apy"`
+	TVL     float64 `json:"tvl"`
+}
+
+/**************************************************************************************************
+** fetchSommelierVaults fetches the Sommelier cellars from the SOMMELIER_API_URL feed, a JSON list
+** of cellars. Without the feed, the source cannot be fetched.
+**************************************************************************************************/
+func fetchSommelierVaults() ([]TCompetitorVault, bool) {
+	if env.SOMMELIER_API_URL == `` {
+		return nil, false
+	}
+	cellars, err := helpers.FetchJSONWithReject[[]tSommelierCellar](env.SOMMELIER_API_URL)
+	if err != nil {
+		return nil, false
+	}
+
+	vaults := []TCompetitorVault{}
+	for _, cellar := range cellars {
+		if !common.IsHexAddress(cellar.Address) || !common.IsHexAddress(cellar.Asset) {
+			continue
+		}
+		vaults = append(vaults, TCompetitorVault{
+			Protocol: SOURCE_SOMMELIER,
+			ID:       cellar.Address,
+			ChainID:  cellar.ChainID,
+			Address:  common.HexToAddress(cellar.Address),
+			Token:    common.HexToAddress(cellar.Asset),
+			Name:     cellar.Name,
+			APY:      cellar.APY,
+			TVL:      cellar.TVL,
+		})
+	}
+	return vaults, true
+}
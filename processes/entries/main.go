@@ -0,0 +1,345 @@
+package entries
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	goEth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/backfill"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/apr"
+)
+
+/**************************************************************************************************
+** The entry of a depositor in a vault is the price per share and the forward net APY of the vault
+** when they deposited, for the dashboards to compare the APY they joined at with the one they
+** realized since. The Deposit and Withdraw events of the vaults are indexed from the first refresh
+** of a chain, the APY of the older deposits not being known, then from the last scanned block,
+** each deposit taking the forward net APY of its vault at the refresh indexing it:
+** - v2: Deposit(recipient, shares, amount) and Withdraw(recipient, shares, amount)
+** - v3: Deposit(sender, owner, assets, shares) and Withdraw(sender, receiver, owner, assets, shares)
+** The shares transferred between addresses are not followed.
+**************************************************************************************************/
+var (
+	depositV2Topic  = crypto.Keccak256Hash([]byte(`Deposit(address,uint256,uint256)`))
+	depositV3Topic  = crypto.Keccak256Hash([]byte(`Deposit(address,address,uint256,uint256)`))
+	withdrawV2Topic = crypto.Keccak256Hash([]byte(`Withdraw(address,uint256,uint256)`))
+	withdrawV3Topic = crypto.Keccak256Hash([]byte(`Withdraw(address,address,address,uint256,uint256)`))
+)
+
+/**************************************************************************************************
+** MIN_ENTRY_AGE is the age below which the return since the entry is not annualized, a few days of
+** yield compounding to meaningless APYs.
+**************************************************************************************************/
+const MIN_ENTRY_AGE = 7 * 24 * time.Hour
+
+/**************************************************************************************************
+** tMovement is a deposit or a withdrawal of a depositor, decoded from an event of a vault.
+**************************************************************************************************/
+type tMovement struct {
+	depositor common.Address
+	shares    *bigNumber.Int
+	assets    *bigNumber.Int
+	isDeposit bool
+}
+
+var (
+	entries     = make(map[uint64]map[common.Address]map[common.Address]storage.TDepositorEntry)
+	scannedUpTo = make(map[uint64]uint64)
+	entriesMtx  sync.RWMutex
+)
+
+/**************************************************************************************************
+** RefreshDepositorEntries applies the deposits and the withdrawals of the vaults of a chain since
+** the last refresh to the entries of their depositors, and persists them. The first refresh of a
+** chain only marks the last confirmed block as scanned.
+**************************************************************************************************/
+func RefreshDepositorEntries(chainID uint64) {
+	chain, ok := env.GetChain(chainID)
+	if !ok {
+		return
+	}
+	loadEntries(chainID)
+	end, err := ethereum.GetConfirmedBlockNumber(chainID)
+	if err != nil {
+		return
+	}
+	entriesMtx.RLock()
+	start := scannedUpTo[chainID] + 1
+	isScanned := scannedUpTo[chainID] != 0
+	entriesMtx.RUnlock()
+	if !isScanned {
+		entriesMtx.Lock()
+		scannedUpTo[chainID] = end
+		entriesMtx.Unlock()
+		storeEntries(chainID)
+		return
+	}
+	if start > end {
+		return
+	}
+
+	vaults, allVaults := storage.ListVaults(chainID)
+	vaultAddresses := []common.Address{}
+	for _, vault := range allVaults {
+		vaultAddresses = append(vaultAddresses, vault.Address)
+	}
+
+	client := ethereum.GetRPC(chainID)
+	logsRange := chain.GetLogsRange()
+	scan := backfill.StartScan(chainID, `entries`, start, end, logsRange)
+	defer scan.Finish()
+	movementsCount := 0
+	for chunkStart := start; chunkStart <= end; chunkStart += logsRange {
+		chunkEnd := min(chunkStart+logsRange-1, end)
+		query := goEth.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(chunkStart),
+			ToBlock:   new(big.Int).SetUint64(chunkEnd),
+			Topics:    [][]common.Hash{{depositV2Topic, depositV3Topic, withdrawV2Topic, withdrawV3Topic}},
+		}
+		if chain.Capabilities.SupportsLogsAddressArray {
+			query.Addresses = vaultAddresses
+		}
+		if !scan.Acquire() {
+			break // Backfills paused, resumed from the last scanned block
+		}
+		history, err := client.FilterLogs(context.Background(), query)
+		scan.Release(err)
+		if err != nil {
+			logs.Error(`Failed to filter the deposits of the vaults on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+			break // Retried from the last scanned block on the next refresh
+		}
+
+		blockTimes := make(map[uint64]uint64)
+		entriesMtx.Lock()
+		for _, log := range history {
+			if _, ok := vaults[log.Address]; !ok || log.Removed {
+				continue
+			}
+			movement, ok := decodeMovement(log)
+			if !ok {
+				continue
+			}
+			if _, ok := blockTimes[log.BlockNumber]; !ok {
+				blockTimes[log.BlockNumber] = ethereum.GetBlockTime(chainID, log.BlockNumber)
+			}
+			netAPY, hasAPY := getForwardNetAPY(chainID, log.Address)
+			applyMovement(getVaultEntries(chainID, log.Address), movement, netAPY, hasAPY, blockTimes[log.BlockNumber])
+			movementsCount++
+		}
+		scannedUpTo[chainID] = chunkEnd
+		entriesMtx.Unlock()
+	}
+
+	storeEntries(chainID)
+	logs.Info(`Indexed ` + strconv.Itoa(movementsCount) + ` deposits and withdrawals of the vaults on chain ` + strconv.FormatUint(chainID, 10))
+}
+
+/**************************************************************************************************
+** decodeMovement decodes a Deposit or a Withdraw event of a v2 or a v3 vault, false when it is
+** not one of them or moves no share.
+**************************************************************************************************/
+func decodeMovement(log types.Log) (tMovement, bool) {
+	if len(log.Topics) == 0 || len(log.Data) < 2*32 {
+		return tMovement{}, false
+	}
+	first := bigNumber.SetInt(new(big.Int).SetBytes(log.Data[0:32]))
+	second := bigNumber.SetInt(new(big.Int).SetBytes(log.Data[32:64]))
+	movement := tMovement{}
+	switch {
+	case log.Topics[0] == depositV2Topic && len(log.Topics) == 2:
+		movement = tMovement{depositor: common.BytesToAddress(log.Topics[1].Bytes()), shares: first, assets: second, isDeposit: true}
+	case log.Topics[0] == depositV3Topic && len(log.Topics) == 3:
+		movement = tMovement{depositor: common.BytesToAddress(log.Topics[2].Bytes()), shares: second, assets: first, isDeposit: true}
+	case log.Topics[0] == withdrawV2Topic && len(log.Topics) == 2:
+		movement = tMovement{depositor: common.BytesToAddress(log.Topics[1].Bytes()), shares: first, assets: second}
+	case log.Topics[0] == withdrawV3Topic && len(log.Topics) == 4:
+		movement = tMovement{depositor: common.BytesToAddress(log.Topics[3].Bytes()), shares: second, assets: first}
+	default:
+		return tMovement{}, false
+	}
+	return movement, !movement.shares.IsZero()
+}
+
+/**************************************************************************************************
+** getForwardNetAPY returns the forward net APY of a vault, its historical net APY when it has no
+** forward one, false when its APY is not computed yet.
+**************************************************************************************************/
+func getForwardNetAPY(chainID uint64, vaultAddress common.Address) (float64, bool) {
+	computedAPY, ok := apr.GetComputedAPY(chainID, vaultAddress)
+	if !ok {
+		return 0, false
+	}
+	vaultAPY, ok := computedAPY.(apr.TVaultAPY)
+	if !ok {
+		return 0, false
+	}
+	if vaultAPY.ForwardAPY.NetAPY != nil {
+		netAPY, _ := vaultAPY.ForwardAPY.NetAPY.Float64()
+		return netAPY, true
+	}
+	if vaultAPY.NetAPY != nil {
+		netAPY, _ := vaultAPY.NetAPY.Float64()
+		return netAPY, true
+	}
+	return 0, false
+}
+
+/**************************************************************************************************
+** applyMovement applies a deposit or a withdrawal to the entries of a vault. A deposit adds its
+** shares and assets to the entry of its depositor, its APY and time being weighted by its shares,
+** and a deposit made when the APY of the vault is unknown weighs nothing in the entry APY. A
+** withdrawal takes the same part of every amount of the entry, the entry being dropped with its
+** last shares. The withdrawals of the shares deposited before the first refresh are ignored.
+**************************************************************************************************/
+func applyMovement(vaultEntries map[common.Address]storage.TDepositorEntry, movement tMovement, netAPY float64, hasAPY bool, timestamp uint64) {
+	entry, isKnown := vaultEntries[movement.depositor]
+	if !movement.isDeposit {
+		if !isKnown {
+			return
+		}
+		if movement.shares.Gte(entry.Shares) {
+			delete(vaultEntries, movement.depositor)
+			return
+		}
+		remaining := bigNumber.NewInt(0).Sub(entry.Shares, movement.shares)
+		entry.APYShares *= toFloat(remaining) / toFloat(entry.Shares)
+		entry.Assets = bigNumber.NewInt(0).Div(bigNumber.NewInt(0).Mul(entry.Assets, remaining), entry.Shares)
+		entry.Shares = remaining
+		vaultEntries[movement.depositor] = entry
+		return
+	}
+
+	if !isKnown {
+		entry = storage.TDepositorEntry{Shares: bigNumber.NewInt(0), Assets: bigNumber.NewInt(0)}
+	}
+	heldShares := toFloat(entry.Shares)
+	depositedShares := toFloat(movement.shares)
+	entry.EntryTime = uint64((float64(entry.EntryTime)*heldShares + float64(timestamp)*depositedShares) / (heldShares + depositedShares))
+	if hasAPY {
+		entry.APY = (entry.APY*entry.APYShares + netAPY*depositedShares) / (entry.APYShares + depositedShares)
+		entry.APYShares += depositedShares
+	}
+	entry.Shares = bigNumber.NewInt(0).Add(entry.Shares, movement.shares)
+	entry.Assets = bigNumber.NewInt(0).Add(entry.Assets, movement.assets)
+	entry.LastDepositAt = timestamp
+	vaultEntries[movement.depositor] = entry
+}
+
+func toFloat(value *bigNumber.Int) float64 {
+	result, _ := bigNumber.NewFloat(0).SetInt(value).Float64()
+	return result
+}
+
+func getVaultEntries(chainID uint64, vaultAddress common.Address) map[common.Address]storage.TDepositorEntry {
+	if _, ok := entries[chainID][vaultAddress]; !ok {
+		entries[chainID][vaultAddress] = make(map[common.Address]storage.TDepositorEntry)
+	}
+	return entries[chainID][vaultAddress]
+}
+
+/**************************************************************************************************
+** loadEntries loads the entries last stored for a chain, on its first refresh.
+**************************************************************************************************/
+func loadEntries(chainID uint64) {
+	entriesMtx.RLock()
+	_, isLoaded := entries[chainID]
+	entriesMtx.RUnlock()
+	if isLoaded {
+		return
+	}
+
+	stored := storage.LoadDepositorEntries(chainID)
+	chainEntries := make(map[common.Address]map[common.Address]storage.TDepositorEntry)
+	for vaultAddress, vaultEntries := range stored.Entries {
+		chainEntries[common.HexToAddress(vaultAddress)] = make(map[common.Address]storage.TDepositorEntry)
+		for depositor, entry := range vaultEntries {
+			if entry.Shares == nil || entry.Assets == nil {
+				continue
+			}
+			chainEntries[common.HexToAddress(vaultAddress)][common.HexToAddress(depositor)] = entry
+		}
+	}
+
+	entriesMtx.Lock()
+	entries[chainID] = chainEntries
+	scannedUpTo[chainID] = stored.ScannedUpTo
+	entriesMtx.Unlock()
+}
+
+func storeEntries(chainID uint64) {
+	entriesMtx.RLock()
+	stored := storage.TJsonEntriesStorage{
+		ScannedUpTo: scannedUpTo[chainID],
+		Entries:     make(map[string]map[string]storage.TDepositorEntry),
+	}
+	for vaultAddress, vaultEntries := range entries[chainID] {
+		if len(vaultEntries) == 0 {
+			continue
+		}
+		stored.Entries[vaultAddress.Hex()] = make(map[string]storage.TDepositorEntry)
+		for depositor, entry := range vaultEntries {
+			stored.Entries[vaultAddress.Hex()][depositor.Hex()] = entry
+		}
+	}
+	entriesMtx.RUnlock()
+	storage.StoreDepositorEntries(chainID, stored)
+}
+
+/**************************************************************************************************
+** GetEntryPricePerShare returns the price per share a depositor entered a vault at, the assets
+** paid for the shares, scaled by the decimals of the vault like its price per share.
+**************************************************************************************************/
+func GetEntryPricePerShare(entry storage.TDepositorEntry, decimals uint64) *bigNumber.Int {
+	if entry.Shares == nil || entry.Shares.IsZero() || entry.Assets == nil {
+		return nil
+	}
+	scale := bigNumber.NewInt(0).Exp(bigNumber.NewInt(10), bigNumber.NewUint64(decimals), nil)
+	return bigNumber.NewInt(0).Div(bigNumber.NewInt(0).Mul(entry.Assets, scale), entry.Shares)
+}
+
+/**************************************************************************************************
+** GetDepositorEntries returns the entries of a depositor in the vaults of a chain, by vault.
+**************************************************************************************************/
+func GetDepositorEntries(chainID uint64, depositor common.Address) map[common.Address]storage.TDepositorEntry {
+	entriesMtx.RLock()
+	defer entriesMtx.RUnlock()
+	depositorEntries := make(map[common.Address]storage.TDepositorEntry)
+	for vaultAddress, vaultEntries := range entries[chainID] {
+		if entry, ok := vaultEntries[depositor]; ok {
+			depositorEntries[vaultAddress] = entry
+		}
+	}
+	return depositorEntries
+}
+
+/**************************************************************************************************
+** ComputeReturnSinceEntry returns the return of a position since its entry from the price per
+** share of its vault, and its annualized value past MIN_ENTRY_AGE.
+**************************************************************************************************/
+func ComputeReturnSinceEntry(entryPricePerShare *bigNumber.Int, pricePerShare *bigNumber.Int, entryTime uint64, now time.Time) (*float64, *float64) {
+	if entryPricePerShare == nil || entryPricePerShare.IsZero() || pricePerShare == nil || pricePerShare.IsZero() {
+		return nil, nil
+	}
+	ratio := toFloat(pricePerShare) / toFloat(entryPricePerShare)
+	returnSinceEntry := ratio - 1
+
+	age := now.Sub(time.Unix(int64(entryTime), 0))
+	if age < MIN_ENTRY_AGE {
+		return &returnSinceEntry, nil
+	}
+	years := age.Hours() / (24 * 365)
+	apySinceEntry := math.Pow(ratio, 1/years) - 1
+	return &returnSinceEntry, &apySinceEntry
+}
@@ -0,0 +1,117 @@
+package entries
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+func word(value int64) []byte {
+	return common.LeftPadBytes(big.NewInt(value).Bytes(), 32)
+}
+
+/**************************************************************************************************
+** TestDecodeMovement checks the depositor and the amounts decoded from the v2 and the v3 events.
+**************************************************************************************************/
+func TestDecodeMovement(t *testing.T) {
+	alice := common.HexToAddress(`0xa1`)
+	router := common.HexToAddress(`0xb0`)
+
+	movement, ok := decodeMovement(types.Log{
+		Topics: []common.Hash{depositV2Topic, common.BytesToHash(alice.Bytes())},
+		Data:   append(word(90), word(100)...),
+	})
+	if !ok || !movement.isDeposit || movement.depositor != alice || movement.shares.Uint64() != 90 || movement.assets.Uint64() != 100 {
+		t.Errorf("unexpected v2 deposit: %+v", movement)
+	}
+
+	movement, ok = decodeMovement(types.Log{
+		Topics: []common.Hash{depositV3Topic, common.BytesToHash(router.Bytes()), common.BytesToHash(alice.Bytes())},
+		Data:   append(word(100), word(90)...),
+	})
+	if !ok || !movement.isDeposit || movement.depositor != alice || movement.shares.Uint64() != 90 || movement.assets.Uint64() != 100 {
+		t.Errorf("expected the v3 deposit to be for the owner, got %+v", movement)
+	}
+
+	movement, ok = decodeMovement(types.Log{
+		Topics: []common.Hash{withdrawV3Topic, common.BytesToHash(router.Bytes()), common.BytesToHash(router.Bytes()), common.BytesToHash(alice.Bytes())},
+		Data:   append(word(100), word(90)...),
+	})
+	if !ok || movement.isDeposit || movement.depositor != alice || movement.shares.Uint64() != 90 {
+		t.Errorf("expected the v3 withdrawal to be for the owner, got %+v", movement)
+	}
+
+	if _, ok := decodeMovement(types.Log{
+		Topics: []common.Hash{depositV3Topic, common.BytesToHash(router.Bytes()), common.BytesToHash(alice.Bytes())},
+		Data:   append(word(0), word(0)...),
+	}); ok {
+		t.Errorf("expected a deposit without shares to be ignored")
+	}
+}
+
+/**************************************************************************************************
+** TestApplyMovement checks that the deposits weigh the entry APY and time by their shares, that
+** the withdrawals keep the entry price per share, and that the last withdrawal drops the entry.
+**************************************************************************************************/
+func TestApplyMovement(t *testing.T) {
+	alice := common.HexToAddress(`0xa1`)
+	vaultEntries := make(map[common.Address]storage.TDepositorEntry)
+	deposit := func(shares int64, assets int64) tMovement {
+		return tMovement{depositor: alice, shares: bigNumber.NewInt(shares), assets: bigNumber.NewInt(assets), isDeposit: true}
+	}
+
+	applyMovement(vaultEntries, deposit(100, 100), 0.04, true, 1000)
+	applyMovement(vaultEntries, deposit(100, 120), 0.10, true, 2000)
+	applyMovement(vaultEntries, deposit(200, 240), 0, false, 3000)
+	entry := vaultEntries[alice]
+	if math.Abs(entry.APY-0.07) > 1e-9 || entry.EntryTime != 2250 || entry.LastDepositAt != 3000 {
+		t.Fatalf("unexpected entry after the deposits: %+v", entry)
+	}
+	if pricePerShare := GetEntryPricePerShare(entry, 2); pricePerShare.Uint64() != 115 {
+		t.Errorf("expected an entry price per share of 1.15, got %s", pricePerShare.String())
+	}
+
+	applyMovement(vaultEntries, tMovement{depositor: alice, shares: bigNumber.NewInt(300)}, 0, false, 4000)
+	entry = vaultEntries[alice]
+	if entry.Shares.Uint64() != 100 || entry.Assets.Uint64() != 115 || math.Abs(entry.APY-0.07) > 1e-9 || math.Abs(entry.APYShares-50) > 1e-9 {
+		t.Errorf("unexpected entry after the withdrawal: %+v", entry)
+	}
+
+	applyMovement(vaultEntries, tMovement{depositor: alice, shares: bigNumber.NewInt(100)}, 0, false, 5000)
+	if _, ok := vaultEntries[alice]; ok {
+		t.Errorf("expected the entry to be dropped with its last shares")
+	}
+	applyMovement(vaultEntries, tMovement{depositor: common.HexToAddress(`0xc0`), shares: bigNumber.NewInt(10)}, 0, false, 5000)
+	if len(vaultEntries) != 0 {
+		t.Errorf("expected the withdrawal of an unknown depositor to be ignored")
+	}
+}
+
+/**************************************************************************************************
+** TestComputeReturnSinceEntry checks the return since the entry, only annualized past
+** MIN_ENTRY_AGE.
+**************************************************************************************************/
+func TestComputeReturnSinceEntry(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	entryPricePerShare := bigNumber.NewInt(1_000_000)
+
+	returnSinceEntry, apySinceEntry := ComputeReturnSinceEntry(entryPricePerShare, bigNumber.NewInt(1_010_000), uint64(now.Add(-3*24*time.Hour).Unix()), now)
+	if returnSinceEntry == nil || math.Abs(*returnSinceEntry-0.01) > 1e-9 || apySinceEntry != nil {
+		t.Errorf("expected a 1%% return without APY after 3 days, got %v and %v", returnSinceEntry, apySinceEntry)
+	}
+
+	returnSinceEntry, apySinceEntry = ComputeReturnSinceEntry(entryPricePerShare, bigNumber.NewInt(1_050_000), uint64(now.Add(-365*24*time.Hour).Unix()), now)
+	if returnSinceEntry == nil || apySinceEntry == nil || math.Abs(*apySinceEntry-0.05) > 1e-9 {
+		t.Errorf("expected a 5%% APY after a year, got %v", apySinceEntry)
+	}
+
+	if returnSinceEntry, _ := ComputeReturnSinceEntry(nil, bigNumber.NewInt(1_050_000), 0, now); returnSinceEntry != nil {
+		t.Errorf("expected no return without an entry price per share")
+	}
+}
@@ -0,0 +1,177 @@
+package fees
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The accountant of a v3 vault charges the fees of each strategy of the vault on its reports: the
+** default config of the accountant, or the custom config set for the vault and the strategy. The
+** fee fields of the vault only mirror the default config, so the configs are read from the
+** accountant, and read again when its events show they changed: an applied default config, a
+** custom config set (UpdateCustomFeeConfig) or removed (RemovedCustomFeeConfig), the vault being
+** the first indexed topic of the custom config events.
+**************************************************************************************************/
+var (
+	updateCustomFeeConfigTopic  = crypto.Keccak256Hash([]byte(`UpdateCustomFeeConfig(address,address,(uint16,uint16,uint16,uint16,uint16,uint16))`))
+	removedCustomFeeConfigTopic = crypto.Keccak256Hash([]byte(`RemovedCustomFeeConfig(address,address)`))
+)
+
+/**************************************************************************************************
+** TAccountantFeeConfig is a fee config of an accountant, in basis points. MaxFee caps the fees
+** charged on a report as a share of the gain, MaxGain and MaxLoss are the health check limits of
+** the reports, and RefundRatio is the share of a loss refunded to the vault.
+**************************************************************************************************/
+type TAccountantFeeConfig struct {
+	ManagementFee  uint64 `json:"managementFee"`
+	PerformanceFee uint64 `json:"performanceFee"`
+	RefundRatio    uint64 `json:"refundRatio"`
+	MaxFee         uint64 `json:"maxFee"`
+	MaxGain        uint64 `json:"maxGain"`
+	MaxLoss        uint64 `json:"maxLoss"`
+}
+
+/**************************************************************************************************
+** TAccountantConfig is the config of the accountant of a vault: its default config and the custom
+** configs of the strategies of the vault using one.
+**************************************************************************************************/
+type TAccountantConfig struct {
+	Accountant      common.Address                          `json:"accountant"`
+	Default         TAccountantFeeConfig                    `json:"default"`
+	CustomConfigs   map[common.Address]TAccountantFeeConfig `json:"customConfigs,omitempty"`
+	strategiesCount int
+}
+
+var accountantConfigs = make(map[uint64]map[common.Address]TAccountantConfig)
+
+/**************************************************************************************************
+** decodeCustomConfigChange returns the vault of a custom config event, false for the other events.
+**************************************************************************************************/
+func decodeCustomConfigChange(log types.Log) (common.Address, bool) {
+	if len(log.Topics) < 2 || (log.Topics[0] != updateCustomFeeConfigTopic && log.Topics[0] != removedCustomFeeConfigTopic) {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(log.Topics[1].Bytes()), true
+}
+
+/**************************************************************************************************
+** decodeAccountantFeeConfig decodes a fee config returned by an accountant, false if incomplete.
+**************************************************************************************************/
+func decodeAccountantFeeConfig(raw []interface{}) (TAccountantFeeConfig, bool) {
+	if len(raw) < 6 {
+		return TAccountantFeeConfig{}, false
+	}
+	values := helpers.DecodeUint16s(raw)
+	return TAccountantFeeConfig{
+		ManagementFee:  uint64(values[0]),
+		PerformanceFee: uint64(values[1]),
+		RefundRatio:    uint64(values[2]),
+		MaxFee:         uint64(values[3]),
+		MaxGain:        uint64(values[4]),
+		MaxLoss:        uint64(values[5]),
+	}, true
+}
+
+/**************************************************************************************************
+** refreshAccountantConfigs reads the accountant config of the vaults whose config changed, never
+** read, read with another accountant or before strategies were added to the vault.
+**************************************************************************************************/
+func refreshAccountantConfigs(chainID uint64, vaults []models.TVault, changedVaults map[common.Address]bool) {
+	strategiesByVault := make(map[common.Address][]models.TStrategy)
+	toRead := []models.TVault{}
+	feesMtx.RLock()
+	for _, vault := range vaults {
+		_, strategies := storage.ListStrategiesForVault(chainID, vault.Address)
+		config, ok := accountantConfigs[chainID][vault.Address]
+		if ok && !changedVaults[vault.Address] && config.Accountant == *vault.Accountant && config.strategiesCount == len(strategies) {
+			continue
+		}
+		strategiesByVault[vault.Address] = strategies
+		toRead = append(toRead, vault)
+	}
+	feesMtx.RUnlock()
+	if len(toRead) == 0 {
+		return
+	}
+
+	calls := []ethereum.Call{}
+	isAccountantCalled := make(map[common.Address]bool)
+	for _, vault := range toRead {
+		accountant := *vault.Accountant
+		if !isAccountantCalled[accountant] {
+			calls = append(calls, multicalls.GetDefaultFeeConfig(accountant.Hex(), accountant))
+			isAccountantCalled[accountant] = true
+		}
+		for _, strategy := range strategiesByVault[vault.Address] {
+			name := vault.Address.Hex() + strategy.Address.Hex()
+			calls = append(calls, multicalls.GetUseCustomFeeConfig(name, accountant, vault.Address, strategy.Address))
+			calls = append(calls, multicalls.GetCustomFeeConfig(name, accountant, vault.Address, strategy.Address))
+		}
+	}
+	response := multicalls.Perform(chainID, calls, nil)
+
+	feesMtx.Lock()
+	defer feesMtx.Unlock()
+	if _, ok := accountantConfigs[chainID]; !ok {
+		accountantConfigs[chainID] = make(map[common.Address]TAccountantConfig)
+	}
+	for _, vault := range toRead {
+		accountant := *vault.Accountant
+		defaultConfig, ok := decodeAccountantFeeConfig(response[accountant.Hex()+`defaultConfig`])
+		if !ok {
+			continue // Read again on the next refresh
+		}
+		config := TAccountantConfig{
+			Accountant:      accountant,
+			Default:         defaultConfig,
+			CustomConfigs:   make(map[common.Address]TAccountantFeeConfig),
+			strategiesCount: len(strategiesByVault[vault.Address]),
+		}
+		for _, strategy := range strategiesByVault[vault.Address] {
+			name := vault.Address.Hex() + strategy.Address.Hex()
+			if !helpers.DecodeBool(response[name+`useCustomConfig`]) {
+				continue
+			}
+			if customConfig, ok := decodeAccountantFeeConfig(response[name+`customConfig`]); ok {
+				config.CustomConfigs[strategy.Address] = customConfig
+			}
+		}
+		accountantConfigs[chainID][vault.Address] = config
+	}
+}
+
+/**************************************************************************************************
+** GetAccountantConfig returns the config of the accountant of a vault, false if not read yet.
+**************************************************************************************************/
+func GetAccountantConfig(chainID uint64, vaultAddress common.Address) (TAccountantConfig, bool) {
+	feesMtx.RLock()
+	defer feesMtx.RUnlock()
+	config, ok := accountantConfigs[chainID][vaultAddress]
+	return config, ok
+}
+
+/**************************************************************************************************
+** GetStrategyPerformanceFee returns the performance fee charged by the accountant of a vault on
+** the gains of one of its strategies, in basis points, when the strategy has a custom config. It
+** is capped by the max fee of the config. False means the default config of the accountant, the
+** performance fee of the vault, applies.
+**************************************************************************************************/
+func GetStrategyPerformanceFee(chainID uint64, vaultAddress common.Address, strategyAddress common.Address) (uint64, bool) {
+	feesMtx.RLock()
+	defer feesMtx.RUnlock()
+	customConfig, ok := accountantConfigs[chainID][vaultAddress].CustomConfigs[strategyAddress]
+	if !ok {
+		return 0, false
+	}
+	if customConfig.MaxFee > 0 && customConfig.PerformanceFee > customConfig.MaxFee {
+		return customConfig.MaxFee, true
+	}
+	return customConfig.PerformanceFee, true
+}
@@ -0,0 +1,231 @@
+package fees
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+
+	goEth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/backfill"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The fees of the legacy vaults are set on the vault, which emits UpdatePerformanceFee and
+** UpdateManagementFee with the new value, one fee per event. The single strategy v3 vaults are
+** tokenized strategies, with only a performance fee, set with UpdatePerformanceFee(uint16). The
+** multi strategy v3 vaults get their history from the default config changes of their accountant.
+**************************************************************************************************/
+var (
+	updatePerformanceFeeTopic         = crypto.Keccak256Hash([]byte(`UpdatePerformanceFee(uint256)`))
+	updateManagementFeeTopic          = crypto.Keccak256Hash([]byte(`UpdateManagementFee(uint256)`))
+	updateStrategyPerformanceFeeTopic = crypto.Keccak256Hash([]byte(`UpdatePerformanceFee(uint16)`))
+)
+
+/**************************************************************************************************
+** tVaultFeeEvent is a fee set by a vault event, in basis points. Only one of the two fees is set
+** by an event, the other one being nil.
+**************************************************************************************************/
+type tVaultFeeEvent struct {
+	BlockNumber    uint64
+	Timestamp      uint64
+	PerformanceFee *uint64
+	ManagementFee  *uint64
+}
+
+var (
+	vaultEventsLastScannedBlock = make(map[uint64]uint64)
+	vaultEventsScannedVaults    = make(map[uint64]map[common.Address]bool)
+	vaultEventsMtx              sync.Mutex
+)
+
+/**************************************************************************************************
+** decodeVaultFeeEvent decodes a fee event of a vault, without its timestamp. The new fee is the
+** only word of the data, false for the other events.
+**************************************************************************************************/
+func decodeVaultFeeEvent(log types.Log) (tVaultFeeEvent, bool) {
+	if len(log.Topics) == 0 || len(log.Data) < 32 {
+		return tVaultFeeEvent{}, false
+	}
+	value := new(big.Int).SetBytes(log.Data[:32])
+	if !value.IsUint64() {
+		return tVaultFeeEvent{}, false
+	}
+	fee := value.Uint64()
+	event := tVaultFeeEvent{BlockNumber: log.BlockNumber}
+	switch log.Topics[0] {
+	case updatePerformanceFeeTopic, updateStrategyPerformanceFeeTopic:
+		event.PerformanceFee = &fee
+	case updateManagementFeeTopic:
+		event.ManagementFee = &fee
+	default:
+		return tVaultFeeEvent{}, false
+	}
+	return event, true
+}
+
+/**************************************************************************************************
+** buildFeeChanges turns the fee events of a vault, sorted by block, into fee changes holding both
+** fees. The fee not set by an event is the one of the change known before it in the history, or,
+** when none is known, the first value set for it, the current fee of the vault if never set. The
+** events of a same block give one change.
+**************************************************************************************************/
+func buildFeeChanges(
+	history []models.TFeeSnapshot,
+	events []tVaultFeeEvent,
+	currentPerformanceFee uint64,
+	currentManagementFee uint64,
+) []models.TFeeSnapshot {
+	if len(events) == 0 {
+		return nil
+	}
+
+	performanceFee, managementFee := currentPerformanceFee, currentManagementFee
+	isPerformanceFeeSet, isManagementFeeSet := false, false
+	for _, event := range events {
+		if event.PerformanceFee != nil && !isPerformanceFeeSet {
+			performanceFee, isPerformanceFeeSet = *event.PerformanceFee, true
+		}
+		if event.ManagementFee != nil && !isManagementFeeSet {
+			managementFee, isManagementFeeSet = *event.ManagementFee, true
+		}
+	}
+	for _, change := range history {
+		if change.BlockNumber >= events[0].BlockNumber {
+			break
+		}
+		performanceFee, managementFee = change.PerformanceFee, change.ManagementFee
+	}
+
+	changes := []models.TFeeSnapshot{}
+	for _, event := range events {
+		if event.PerformanceFee != nil {
+			performanceFee = *event.PerformanceFee
+		}
+		if event.ManagementFee != nil {
+			managementFee = *event.ManagementFee
+		}
+		change := models.TFeeSnapshot{
+			BlockNumber:    event.BlockNumber,
+			Timestamp:      event.Timestamp,
+			PerformanceFee: performanceFee,
+			ManagementFee:  managementFee,
+		}
+		if len(changes) > 0 && changes[len(changes)-1].BlockNumber == event.BlockNumber {
+			changes[len(changes)-1] = change
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+/**************************************************************************************************
+** indexVaultFeeEvents scans the fee events of the legacy and single strategy vaults of a chain
+** from the last scanned block, or the activation of the oldest vault not scanned yet, up to the
+** last confirmed block, and stores the fee changes they make in the fee history of the vaults.
+**************************************************************************************************/
+func indexVaultFeeEvents(chainID uint64, vaults []models.TVault) {
+	if len(vaults) == 0 {
+		return
+	}
+	chain, _ := env.GetChain(chainID)
+	client := ethereum.GetRPC(chainID)
+
+	vaultEventsMtx.Lock()
+	lastScanned, ok := vaultEventsLastScannedBlock[chainID]
+	isScannedVault := make(map[common.Address]bool)
+	for vault := range vaultEventsScannedVaults[chainID] {
+		isScannedVault[vault] = true
+	}
+	vaultEventsMtx.Unlock()
+
+	// The vaults added since the last refresh are scanned from their activation
+	start := lastScanned
+	vaultsByAddress := make(map[common.Address]models.TVault)
+	addresses := []common.Address{}
+	for _, vault := range vaults {
+		vaultsByAddress[vault.Address] = vault
+		addresses = append(addresses, vault.Address)
+		if !isScannedVault[vault.Address] && (!ok || vault.Activation < start) {
+			start, ok = vault.Activation, true
+		}
+	}
+	end, err := ethereum.GetConfirmedBlockNumber(chainID)
+	if err != nil || end <= start {
+		return
+	}
+
+	eventsByVault := make(map[common.Address][]tVaultFeeEvent)
+	logsRange := chain.GetLogsRange()
+	scan := backfill.StartScan(chainID, `vaultFees`, start, end, logsRange)
+	defer scan.Finish()
+	for chunkStart := start; chunkStart <= end; chunkStart += logsRange {
+		chunkEnd := chunkStart + logsRange - 1
+		if chunkEnd > end {
+			chunkEnd = end
+		}
+		query := goEth.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(chunkStart),
+			ToBlock:   new(big.Int).SetUint64(chunkEnd),
+			Topics:    [][]common.Hash{{updatePerformanceFeeTopic, updateManagementFeeTopic, updateStrategyPerformanceFeeTopic}},
+		}
+		if chain.Capabilities.SupportsLogsAddressArray {
+			query.Addresses = addresses
+		}
+		if !scan.Acquire() {
+			return // Backfills paused, resumed from the same block
+		}
+		history, err := client.FilterLogs(context.Background(), query)
+		scan.Release(err)
+		if err != nil {
+			logs.Error(`Failed to filter the fee events of the vaults on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+			return // Retried from the same block on the next refresh
+		}
+		for _, log := range history {
+			vault, ok := vaultsByAddress[log.Address]
+			if !ok || log.BlockNumber < vault.Activation {
+				continue
+			}
+			if log.BlockNumber < lastScanned && isScannedVault[vault.Address] {
+				continue // Already indexed
+			}
+			if event, ok := decodeVaultFeeEvent(log); ok {
+				event.Timestamp = ethereum.GetBlockTime(chainID, log.BlockNumber)
+				eventsByVault[vault.Address] = append(eventsByVault[vault.Address], event)
+			}
+		}
+	}
+
+	indexed := 0
+	for vaultAddress, events := range eventsByVault {
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].BlockNumber < events[j].BlockNumber
+		})
+		vault := vaultsByAddress[vaultAddress]
+		history := storage.ListFeeHistory(chainID, vaultAddress)
+		for _, change := range buildFeeChanges(history, events, vault.PerformanceFee, vault.ManagementFee) {
+			storage.StoreFeeChange(chainID, vaultAddress, change)
+			indexed++
+		}
+	}
+	vaultEventsMtx.Lock()
+	defer vaultEventsMtx.Unlock()
+	vaultEventsLastScannedBlock[chainID] = end + 1
+	if _, ok := vaultEventsScannedVaults[chainID]; !ok {
+		vaultEventsScannedVaults[chainID] = make(map[common.Address]bool)
+	}
+	for _, vault := range vaults {
+		vaultEventsScannedVaults[chainID][vault.Address] = true
+	}
+	logs.Info(`Indexed ` + strconv.Itoa(indexed) + ` fee changes of the legacy and single strategy vaults on chain ` + strconv.FormatUint(chainID, 10))
+}
@@ -0,0 +1,108 @@
+package fees
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+func feeOf(value uint64) *uint64 {
+	return &value
+}
+
+/**************************************************************************************************
+** TestBuildFeeChanges checks that the fee events of a vault, one fee each, give changes holding
+** both fees: carried from the change known before them, or from the first value set, and merged
+** when set in the same block.
+**************************************************************************************************/
+func TestBuildFeeChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		history  []models.TFeeSnapshot
+		events   []tVaultFeeEvent
+		expected []models.TFeeSnapshot
+	}{
+		{
+			name: "initial fees set in the same block",
+			events: []tVaultFeeEvent{
+				{BlockNumber: 10, Timestamp: 100, PerformanceFee: feeOf(1000)},
+				{BlockNumber: 10, Timestamp: 100, ManagementFee: feeOf(200)},
+				{BlockNumber: 20, Timestamp: 200, ManagementFee: feeOf(0)},
+			},
+			expected: []models.TFeeSnapshot{
+				{BlockNumber: 10, Timestamp: 100, PerformanceFee: 1000, ManagementFee: 200},
+				{BlockNumber: 20, Timestamp: 200, PerformanceFee: 1000, ManagementFee: 0},
+			},
+		},
+		{
+			name:    "fee carried from the change known before",
+			history: []models.TFeeSnapshot{{BlockNumber: 10, Timestamp: 100, PerformanceFee: 2000, ManagementFee: 0}},
+			events: []tVaultFeeEvent{
+				{BlockNumber: 30, Timestamp: 300, ManagementFee: feeOf(100)},
+			},
+			expected: []models.TFeeSnapshot{
+				{BlockNumber: 30, Timestamp: 300, PerformanceFee: 2000, ManagementFee: 100},
+			},
+		},
+		{
+			name: "never set fee taken from the vault",
+			events: []tVaultFeeEvent{
+				{BlockNumber: 10, Timestamp: 100, PerformanceFee: feeOf(1000)},
+				{BlockNumber: 20, Timestamp: 200, PerformanceFee: feeOf(500)},
+			},
+			expected: []models.TFeeSnapshot{
+				{BlockNumber: 10, Timestamp: 100, PerformanceFee: 1000, ManagementFee: 0},
+				{BlockNumber: 20, Timestamp: 200, PerformanceFee: 500, ManagementFee: 0},
+			},
+		},
+		{
+			name: "events scanned again after a restart",
+			history: []models.TFeeSnapshot{
+				{BlockNumber: 10, Timestamp: 100, PerformanceFee: 1000, ManagementFee: 200},
+				{BlockNumber: 20, Timestamp: 200, PerformanceFee: 1000, ManagementFee: 0},
+			},
+			events: []tVaultFeeEvent{
+				{BlockNumber: 20, Timestamp: 200, ManagementFee: feeOf(0)},
+			},
+			expected: []models.TFeeSnapshot{
+				{BlockNumber: 20, Timestamp: 200, PerformanceFee: 1000, ManagementFee: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := buildFeeChanges(tt.history, tt.events, 1000, 0)
+			if len(changes) != len(tt.expected) {
+				t.Fatalf("expected %d changes, got %d: %+v", len(tt.expected), len(changes), changes)
+			}
+			for i := range changes {
+				if changes[i] != tt.expected[i] {
+					t.Errorf("change %d: expected %+v, got %+v", i, tt.expected[i], changes[i])
+				}
+			}
+		})
+	}
+}
+
+/**************************************************************************************************
+** TestDecodeVaultFeeEvent checks that the fee events of the legacy vaults and of the tokenized
+** strategies are decoded to the fee they set, and that the other events are ignored.
+**************************************************************************************************/
+func TestDecodeVaultFeeEvent(t *testing.T) {
+	data := common.LeftPadBytes([]byte{0x03, 0xe8}, 32)
+
+	event, ok := decodeVaultFeeEvent(types.Log{Topics: []common.Hash{updateStrategyPerformanceFeeTopic}, Data: data})
+	if !ok || event.PerformanceFee == nil || *event.PerformanceFee != 1000 || event.ManagementFee != nil {
+		t.Errorf("expected a performance fee of 1000, got %+v", event)
+	}
+	event, ok = decodeVaultFeeEvent(types.Log{Topics: []common.Hash{updateManagementFeeTopic}, Data: data})
+	if !ok || event.ManagementFee == nil || *event.ManagementFee != 1000 || event.PerformanceFee != nil {
+		t.Errorf("expected a management fee of 1000, got %+v", event)
+	}
+	if _, ok := decodeVaultFeeEvent(types.Log{Topics: []common.Hash{updateDefaultFeeConfigTopic}, Data: data}); ok {
+		t.Error("expected the accountant events to be ignored")
+	}
+}
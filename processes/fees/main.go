@@ -0,0 +1,323 @@
+package fees
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"sync"
+
+	goEth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/backfill"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The fees of the multi-strategy v3 vaults are the default config of their accountant. The
+** accountants apply a new config at once (UpdateDefaultFeeConfig), without queueing it, and it is
+** charged from the next report of each strategy. The changes are indexed for their history, and
+** the default config is read from the accountant (defaultConfig()): while it differs from the fees
+** of the vault, it is pending, the vault not charging it yet.
+**************************************************************************************************/
+var updateDefaultFeeConfigTopic = crypto.Keccak256Hash([]byte(`UpdateDefaultFeeConfig((uint16,uint16,uint16,uint16,uint16,uint16))`))
+
+const FEE_CHANGE_APPLIED = `applied`
+
+/**************************************************************************************************
+** TFeeChange is a change of the default config of the accountant of a vault, in basis points.
+** EffectiveAt is the time of the block it was applied in by the accountant.
+**************************************************************************************************/
+type TFeeChange struct {
+	Type           string      `json:"type"`
+	ManagementFee  uint64      `json:"managementFee"`
+	PerformanceFee uint64      `json:"performanceFee"`
+	EffectiveAt    uint64      `json:"effectiveAt"`
+	TxHash         common.Hash `json:"txHash"`
+	BlockNumber    uint64      `json:"blockNumber"`
+	Timestamp      uint64      `json:"timestamp"`
+}
+
+/**************************************************************************************************
+** TPendingFees are the fees of the default config of the accountant of a vault not reflected yet
+** by its fees, as fractions like the current ones, and the time the accountant applied them at, 0
+** when the change was not indexed. They are charged from the next report of each strategy.
+**************************************************************************************************/
+type TPendingFees struct {
+	Value       models.TFees `json:"value"`
+	EffectiveAt uint64       `json:"effectiveAt"`
+}
+
+/**************************************************************************************************
+** OnFeeChange is called with the fee changes applied since the last refresh, the ones found when a
+** vault is first scanned being its history.
+**************************************************************************************************/
+var OnFeeChange func(chainID uint64, vaultAddress common.Address, change TFeeChange)
+
+var (
+	appliedChanges   = make(map[uint64]map[common.Address]TFeeChange)
+	pendingChanges   = make(map[uint64]map[common.Address]TFeeChange)
+	lastScannedBlock = make(map[uint64]uint64)
+	scannedVaults    = make(map[uint64]map[common.Address]bool)
+	feesMtx          sync.RWMutex
+)
+
+/**************************************************************************************************
+** RefreshVaultsFees indexes the fee events of the vaults of a chain since the last refresh for
+** their fee history, reads the configs of the accountants of the multi-strategy v3 vaults whose
+** config changed, and resolves the pending fees of these vaults from them.
+**************************************************************************************************/
+func RefreshVaultsFees(chainID uint64) {
+	vaultsByAccountant := make(map[common.Address][]models.TVault)
+	vaults := []models.TVault{}
+	vaultsWithFeeEvents := []models.TVault{}
+	_, allVaults := storage.ListVaults(chainID)
+	for _, vault := range allVaults {
+		if vault.Kind != models.VaultKindMultiple {
+			vaultsWithFeeEvents = append(vaultsWithFeeEvents, vault)
+			continue
+		}
+		if vault.Accountant == nil || *vault.Accountant == (common.Address{}) {
+			continue
+		}
+		vaultsByAccountant[*vault.Accountant] = append(vaultsByAccountant[*vault.Accountant], vault)
+		vaults = append(vaults, vault)
+	}
+	indexVaultFeeEvents(chainID, vaultsWithFeeEvents)
+	if len(vaults) == 0 {
+		return
+	}
+
+	changedVaults := indexFeeChanges(chainID, vaults, vaultsByAccountant)
+	refreshAccountantConfigs(chainID, vaults, changedVaults)
+
+	feesMtx.Lock()
+	defer feesMtx.Unlock()
+	resolvePendingFees(chainID, vaults)
+}
+
+/**************************************************************************************************
+** resolvePendingFees sets as pending the default config of the accountant of the vaults whose fees
+** differ from it, with the change which applied it when indexed, and drops the pending fees of the
+** vaults matching it. The caller must hold feesMtx.
+**************************************************************************************************/
+func resolvePendingFees(chainID uint64, vaults []models.TVault) {
+	if _, ok := pendingChanges[chainID]; !ok {
+		pendingChanges[chainID] = make(map[common.Address]TFeeChange)
+	}
+	for _, vault := range vaults {
+		config, ok := accountantConfigs[chainID][vault.Address]
+		if !ok {
+			continue
+		}
+		if config.Default.ManagementFee == vault.ManagementFee && config.Default.PerformanceFee == vault.PerformanceFee {
+			delete(pendingChanges[chainID], vault.Address)
+			continue
+		}
+		pending := TFeeChange{
+			Type:           FEE_CHANGE_APPLIED,
+			ManagementFee:  config.Default.ManagementFee,
+			PerformanceFee: config.Default.PerformanceFee,
+		}
+		if applied, ok := appliedChanges[chainID][vault.Address]; ok && applied.ManagementFee == pending.ManagementFee && applied.PerformanceFee == pending.PerformanceFee {
+			pending = applied
+		}
+		pendingChanges[chainID][vault.Address] = pending
+	}
+}
+
+/**************************************************************************************************
+** indexFeeChanges scans the fee events of the accountants of a chain from the last scanned block,
+** or the activation of the oldest vault, up to the last confirmed block. The changes of an
+** accountant apply to all the vaults using it. The custom configs of the strategies being indexed
+** too, the vaults whose accountant config changed since the last refresh are returned.
+**************************************************************************************************/
+func indexFeeChanges(chainID uint64, vaults []models.TVault, vaultsByAccountant map[common.Address][]models.TVault) map[common.Address]bool {
+	chain, _ := env.GetChain(chainID)
+	client := ethereum.GetRPC(chainID)
+
+	feesMtx.RLock()
+	lastScanned, ok := lastScannedBlock[chainID]
+	isScannedVault := make(map[common.Address]bool)
+	for vault := range scannedVaults[chainID] {
+		isScannedVault[vault] = true
+	}
+	feesMtx.RUnlock()
+
+	// The vaults added since the last refresh are scanned from their activation
+	start := lastScanned
+	for _, vault := range vaults {
+		if !isScannedVault[vault.Address] && (!ok || vault.Activation < start) {
+			start, ok = vault.Activation, true
+		}
+	}
+	end, err := ethereum.GetConfirmedBlockNumber(chainID)
+	if err != nil || end <= start {
+		return nil
+	}
+
+	accountants := []common.Address{}
+	for accountant := range vaultsByAccountant {
+		accountants = append(accountants, accountant)
+	}
+
+	changes := []types.Log{}
+	logsRange := chain.GetLogsRange()
+	scan := backfill.StartScan(chainID, `fees`, start, end, logsRange)
+	defer scan.Finish()
+	for chunkStart := start; chunkStart <= end; chunkStart += logsRange {
+		chunkEnd := chunkStart + logsRange - 1
+		if chunkEnd > end {
+			chunkEnd = end
+		}
+		query := goEth.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(chunkStart),
+			ToBlock:   new(big.Int).SetUint64(chunkEnd),
+			Topics:    [][]common.Hash{{updateDefaultFeeConfigTopic, updateCustomFeeConfigTopic, removedCustomFeeConfigTopic}},
+		}
+		if chain.Capabilities.SupportsLogsAddressArray {
+			query.Addresses = accountants
+		}
+		if !scan.Acquire() {
+			return nil // Backfills paused, resumed from the same block
+		}
+		history, err := client.FilterLogs(context.Background(), query)
+		scan.Release(err)
+		if err != nil {
+			logs.Error(`Failed to filter the fee changes of the vaults on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+			return nil // Retried from the same block on the next refresh
+		}
+		for _, log := range history {
+			if _, ok := vaultsByAccountant[log.Address]; ok {
+				changes = append(changes, log)
+			}
+		}
+	}
+
+	type tNewFeeChange struct {
+		vault  common.Address
+		change TFeeChange
+	}
+	newChanges := []tNewFeeChange{}
+	changedVaults := make(map[common.Address]bool)
+	feesMtx.Lock()
+	indexed := 0
+	for _, log := range changes {
+		if vault, ok := decodeCustomConfigChange(log); ok {
+			changedVaults[vault] = true
+			continue
+		}
+		change, ok := decodeFeeChange(chainID, log)
+		if !ok {
+			continue
+		}
+		for _, vault := range vaultsByAccountant[log.Address] {
+			if log.BlockNumber < lastScanned && isScannedVault[vault.Address] {
+				continue // Already indexed
+			}
+			if log.BlockNumber < vault.Activation {
+				continue
+			}
+			changedVaults[vault.Address] = true
+			storeFeeChange(chainID, vault.Address, change)
+			storage.StoreFeeChange(chainID, vault.Address, models.TFeeSnapshot{
+				BlockNumber:    change.BlockNumber,
+				Timestamp:      change.Timestamp,
+				PerformanceFee: change.PerformanceFee,
+				ManagementFee:  change.ManagementFee,
+			})
+			indexed++
+			if isScannedVault[vault.Address] {
+				newChanges = append(newChanges, tNewFeeChange{vault: vault.Address, change: change})
+			}
+		}
+	}
+	lastScannedBlock[chainID] = end + 1
+	if _, ok := scannedVaults[chainID]; !ok {
+		scannedVaults[chainID] = make(map[common.Address]bool)
+	}
+	for _, vault := range vaults {
+		scannedVaults[chainID][vault.Address] = true
+	}
+	feesMtx.Unlock()
+	logs.Info(`Indexed ` + strconv.Itoa(indexed) + ` fee changes of the vaults on chain ` + strconv.FormatUint(chainID, 10))
+
+	if OnFeeChange != nil {
+		for _, newChange := range newChanges {
+			OnFeeChange(chainID, newChange.vault, newChange.change)
+		}
+	}
+	return changedVaults
+}
+
+/**************************************************************************************************
+** decodeFeeChange decodes an UpdateDefaultFeeConfig event. The config is not indexed: its
+** management and performance fees are the first two words of the data.
+**************************************************************************************************/
+func decodeFeeChange(chainID uint64, log types.Log) (TFeeChange, bool) {
+	if log.Topics[0] != updateDefaultFeeConfigTopic || len(log.Data) < 6*32 {
+		return TFeeChange{}, false
+	}
+	word := func(i int) *big.Int {
+		return new(big.Int).SetBytes(log.Data[i*32 : (i+1)*32])
+	}
+	change := TFeeChange{
+		Type:           FEE_CHANGE_APPLIED,
+		ManagementFee:  word(0).Uint64(),
+		PerformanceFee: word(1).Uint64(),
+		TxHash:         log.TxHash,
+		BlockNumber:    log.BlockNumber,
+		Timestamp:      ethereum.GetBlockTime(chainID, log.BlockNumber),
+	}
+	change.EffectiveAt = change.Timestamp
+	return change, true
+}
+
+/**************************************************************************************************
+** storeFeeChange keeps the last change applied to the default config of the accountant of a vault.
+**************************************************************************************************/
+func storeFeeChange(chainID uint64, vaultAddress common.Address, change TFeeChange) {
+	if _, ok := appliedChanges[chainID]; !ok {
+		appliedChanges[chainID] = make(map[common.Address]TFeeChange)
+	}
+	if applied, ok := appliedChanges[chainID][vaultAddress]; ok && applied.BlockNumber > change.BlockNumber {
+		return
+	}
+	appliedChanges[chainID][vaultAddress] = change
+}
+
+/**************************************************************************************************
+** GetPendingFees returns the fees pending for a vault, false if none is pending.
+**************************************************************************************************/
+func GetPendingFees(chainID uint64, vaultAddress common.Address) (TPendingFees, bool) {
+	feesMtx.RLock()
+	defer feesMtx.RUnlock()
+	pending, ok := pendingChanges[chainID][vaultAddress]
+	if !ok {
+		return TPendingFees{}, false
+	}
+	return TPendingFees{
+		Value: models.TFees{
+			Performance: bigNumber.NewFloat(float64(pending.PerformanceFee) / 10000.0),
+			Management:  bigNumber.NewFloat(float64(pending.ManagementFee) / 10000.0),
+		},
+		EffectiveAt: pending.EffectiveAt,
+	}, true
+}
+
+/**************************************************************************************************
+** GetPendingFeesBps returns the management and performance fees pending for a vault, in basis
+** points, false if none is pending.
+**************************************************************************************************/
+func GetPendingFeesBps(chainID uint64, vaultAddress common.Address) (uint64, uint64, bool) {
+	feesMtx.RLock()
+	defer feesMtx.RUnlock()
+	pending, ok := pendingChanges[chainID][vaultAddress]
+	return pending.ManagementFee, pending.PerformanceFee, ok
+}
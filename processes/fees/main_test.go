@@ -0,0 +1,80 @@
+package fees
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** TestResolvePendingFees checks that the default config of the accountant is pending while the
+** fees of the vault differ from it, with the change which applied it, that the pending fees are
+** exposed as fractions, and that they are dropped once the vault fees match the config.
+**************************************************************************************************/
+func TestResolvePendingFees(t *testing.T) {
+	chainID := uint64(1)
+	vault := models.TVault{Address: common.HexToAddress(`0x1`), ManagementFee: 0, PerformanceFee: 1000}
+	accountantConfigs[chainID] = map[common.Address]TAccountantConfig{
+		vault.Address: {Default: TAccountantFeeConfig{ManagementFee: 0, PerformanceFee: 1000}},
+	}
+
+	resolvePendingFees(chainID, []models.TVault{vault})
+	if _, ok := GetPendingFees(chainID, vault.Address); ok {
+		t.Fatal("expected no pending fees when the vault fees match the accountant config")
+	}
+
+	storeFeeChange(chainID, vault.Address, TFeeChange{Type: FEE_CHANGE_APPLIED, ManagementFee: 50, PerformanceFee: 1500, EffectiveAt: 1700000000, BlockNumber: 20})
+	storeFeeChange(chainID, vault.Address, TFeeChange{Type: FEE_CHANGE_APPLIED, ManagementFee: 0, PerformanceFee: 1000, EffectiveAt: 1600000000, BlockNumber: 10})
+	accountantConfigs[chainID][vault.Address] = TAccountantConfig{Default: TAccountantFeeConfig{ManagementFee: 50, PerformanceFee: 1500}}
+	resolvePendingFees(chainID, []models.TVault{vault})
+	pending, ok := GetPendingFees(chainID, vault.Address)
+	if !ok {
+		t.Fatal("expected the accountant config to be pending")
+	}
+	if performance, _ := pending.Value.Performance.Float64(); performance != 0.15 {
+		t.Errorf("expected a pending performance fee of 0.15, got %v", performance)
+	}
+	if management, _ := pending.Value.Management.Float64(); management != 0.005 {
+		t.Errorf("expected a pending management fee of 0.005, got %v", management)
+	}
+	if pending.EffectiveAt != 1700000000 {
+		t.Errorf("expected the pending fees to be effective at 1700000000, got %d", pending.EffectiveAt)
+	}
+
+	vault.ManagementFee, vault.PerformanceFee = 50, 1500
+	resolvePendingFees(chainID, []models.TVault{vault})
+	if _, ok := GetPendingFees(chainID, vault.Address); ok {
+		t.Error("expected the pending fees to be dropped once the vault fees match")
+	}
+}
+
+/**************************************************************************************************
+** TestGetStrategyPerformanceFee checks that only the strategies with a custom config get their own
+** performance fee, capped by the max fee of the config.
+**************************************************************************************************/
+func TestGetStrategyPerformanceFee(t *testing.T) {
+	chainID := uint64(1)
+	vault := common.HexToAddress(`0x1`)
+	customStrategy := common.HexToAddress(`0x2`)
+	cappedStrategy := common.HexToAddress(`0x3`)
+	accountantConfigs[chainID] = map[common.Address]TAccountantConfig{
+		vault: {
+			Default: TAccountantFeeConfig{PerformanceFee: 1000},
+			CustomConfigs: map[common.Address]TAccountantFeeConfig{
+				customStrategy: {PerformanceFee: 500, MaxFee: 10000},
+				cappedStrategy: {PerformanceFee: 2000, MaxFee: 1500},
+			},
+		},
+	}
+
+	if fee, ok := GetStrategyPerformanceFee(chainID, vault, customStrategy); !ok || fee != 500 {
+		t.Errorf("expected the custom performance fee of 500, got %d (%v)", fee, ok)
+	}
+	if fee, ok := GetStrategyPerformanceFee(chainID, vault, cappedStrategy); !ok || fee != 1500 {
+		t.Errorf("expected the performance fee capped at 1500, got %d (%v)", fee, ok)
+	}
+	if _, ok := GetStrategyPerformanceFee(chainID, vault, common.HexToAddress(`0x4`)); ok {
+		t.Error("expected the default config to apply to a strategy without a custom config")
+	}
+}
@@ -0,0 +1,336 @@
+package governance
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+
+	goEth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/backfill"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+var (
+	roleSetTopic           = crypto.Keccak256Hash([]byte(`RoleSet(address,uint256)`))
+	updateRoleManagerTopic = crypto.Keccak256Hash([]byte(`UpdateRoleManager(address)`))
+)
+
+/**************************************************************************************************
+** The roles of the v3 vaults, as flags of the bitmap returned by `roles(account)` and emitted by
+** the RoleSet events. ROLE_NAMES follows the order of the bits.
+**************************************************************************************************/
+const (
+	ROLE_ADD_STRATEGY_MANAGER uint64 = 1 << iota
+	ROLE_REVOKE_STRATEGY_MANAGER
+	ROLE_FORCE_REVOKE_MANAGER
+	ROLE_ACCOUNTANT_MANAGER
+	ROLE_QUEUE_MANAGER
+	ROLE_REPORTING_MANAGER
+	ROLE_DEBT_MANAGER
+	ROLE_MAX_DEBT_MANAGER
+	ROLE_DEPOSIT_LIMIT_MANAGER
+	ROLE_WITHDRAW_LIMIT_MANAGER
+	ROLE_MINIMUM_IDLE_MANAGER
+	ROLE_PROFIT_UNLOCK_MANAGER
+	ROLE_DEBT_PURCHASER
+	ROLE_EMERGENCY_MANAGER
+)
+
+var ROLE_NAMES = []string{
+	`ADD_STRATEGY_MANAGER`,
+	`REVOKE_STRATEGY_MANAGER`,
+	`FORCE_REVOKE_MANAGER`,
+	`ACCOUNTANT_MANAGER`,
+	`QUEUE_MANAGER`,
+	`REPORTING_MANAGER`,
+	`DEBT_MANAGER`,
+	`MAX_DEBT_MANAGER`,
+	`DEPOSIT_LIMIT_MANAGER`,
+	`WITHDRAW_LIMIT_MANAGER`,
+	`MINIMUM_IDLE_MANAGER`,
+	`PROFIT_UNLOCK_MANAGER`,
+	`DEBT_PURCHASER`,
+	`EMERGENCY_MANAGER`,
+}
+
+/**************************************************************************************************
+** The access control of the v3 vaults, see storage.TVaultGovernance.
+**************************************************************************************************/
+type TRoleHolder = storage.TRoleHolder
+type TRoleChange = storage.TRoleChange
+type TVaultGovernance = storage.TVaultGovernance
+
+var (
+	governances   = make(map[uint64]map[common.Address]*TVaultGovernance)
+	nextBlocks    = make(map[uint64]map[common.Address]uint64)
+	governanceMtx sync.RWMutex
+)
+
+/**************************************************************************************************
+** RoleNames returns the names of the roles of a bitmap.
+**************************************************************************************************/
+func RoleNames(roles uint64) []string {
+	names := []string{}
+	for i, name := range ROLE_NAMES {
+		if roles&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func isV3Vault(vault models.TVault) bool {
+	return vault.Kind == models.VaultKindMultiple || vault.Kind == models.VaultKindSingle
+}
+
+/**************************************************************************************************
+** RefreshVaultsGovernance indexes the role changes of the v3 vaults of a chain since the last
+** refresh, then reads the role manager of each vault and the current roles of every account it
+** ever granted a role to, in one multicall. The accounts without any role left are dropped from
+** the holders but stay in the history. The access control is then persisted.
+**************************************************************************************************/
+func RefreshVaultsGovernance(chainID uint64) {
+	vaults := []models.TVault{}
+	_, allVaults := storage.ListVaults(chainID)
+	for _, vault := range allVaults {
+		if isV3Vault(vault) {
+			vaults = append(vaults, vault)
+		}
+	}
+	if len(vaults) == 0 {
+		return
+	}
+
+	indexRoleChanges(chainID, vaults)
+
+	governanceMtx.RLock()
+	calls := []ethereum.Call{}
+	for _, vault := range vaults {
+		calls = append(calls, multicalls.GetV3RoleManager(vault.Address.Hex(), vault.Address))
+		if governance, ok := governances[chainID][vault.Address]; ok {
+			for _, account := range listAccounts(governance) {
+				calls = append(calls, multicalls.GetV3Roles(vault.Address.Hex()+account.Hex(), vault.Address, account))
+			}
+		}
+	}
+	governanceMtx.RUnlock()
+	response := multicalls.Perform(chainID, calls, nil)
+
+	governanceMtx.Lock()
+	for _, vault := range vaults {
+		governance := getOrCreateGovernance(chainID, vault.Address)
+		governance.RoleManager = helpers.DecodeAddress(response[vault.Address.Hex()+`role_manager`])
+		holders := []TRoleHolder{}
+		for _, account := range listAccounts(governance) {
+			roles := helpers.DecodeBigInt(response[vault.Address.Hex()+account.Hex()+`roles`]).Uint64()
+			if roles == 0 {
+				continue
+			}
+			holders = append(holders, TRoleHolder{Account: account, Roles: roles, Names: RoleNames(roles)})
+		}
+		sort.Slice(holders, func(i, j int) bool {
+			return holders[i].Account.Hex() < holders[j].Account.Hex()
+		})
+		governance.Holders = holders
+	}
+	governanceMtx.Unlock()
+	storeGovernance(chainID)
+}
+
+/**************************************************************************************************
+** indexRoleChanges scans the RoleSet and UpdateRoleManager events of the v3 vaults of a chain up
+** to the last confirmed block, each vault from its next block to scan, or its activation for the
+** vaults added since the last refresh. The changes and the next blocks are kept chunk by chunk and
+** persisted, so a failure or a restart resumes the scan from the last scanned chunk.
+**************************************************************************************************/
+func indexRoleChanges(chainID uint64, vaults []models.TVault) {
+	chain, _ := env.GetChain(chainID)
+	client := ethereum.GetRPC(chainID)
+	loadGovernance(chainID)
+
+	governanceMtx.RLock()
+	vaultNextBlock := make(map[common.Address]uint64)
+	vaultAddresses := []common.Address{}
+	start := uint64(0)
+	for i, vault := range vaults {
+		next, ok := nextBlocks[chainID][vault.Address]
+		if !ok {
+			next = vault.Activation
+		}
+		vaultNextBlock[vault.Address] = next
+		vaultAddresses = append(vaultAddresses, vault.Address)
+		if i == 0 || next < start {
+			start = next
+		}
+	}
+	governanceMtx.RUnlock()
+
+	end, err := ethereum.GetConfirmedBlockNumber(chainID)
+	if err != nil || end <= start {
+		return
+	}
+
+	changesCount := 0
+	blockTimes := make(map[uint64]uint64)
+	logsRange := chain.GetLogsRange()
+	scan := backfill.StartScan(chainID, `governance`, start, end, logsRange)
+	defer scan.Finish()
+	for chunkStart := start; chunkStart <= end; chunkStart += logsRange {
+		chunkEnd := chunkStart + logsRange - 1
+		if chunkEnd > end {
+			chunkEnd = end
+		}
+		query := goEth.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(chunkStart),
+			ToBlock:   new(big.Int).SetUint64(chunkEnd),
+			Topics:    [][]common.Hash{{roleSetTopic, updateRoleManagerTopic}},
+		}
+		if chain.Capabilities.SupportsLogsAddressArray {
+			query.Addresses = vaultAddresses
+		}
+		if !scan.Acquire() {
+			break // Backfills paused, resumed from the last scanned chunk
+		}
+		history, err := client.FilterLogs(context.Background(), query)
+		scan.Release(err)
+		if err != nil {
+			logs.Error(`Failed to filter the role changes of the vaults on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+			break // Retried from the last scanned chunk on the next refresh
+		}
+
+		changes := []types.Log{}
+		for _, log := range history {
+			next, isKnownVault := vaultNextBlock[log.Address]
+			if !isKnownVault || len(log.Topics) < 2 || log.Removed {
+				continue
+			}
+			if log.BlockNumber < next {
+				continue // Already indexed
+			}
+			if _, ok := blockTimes[log.BlockNumber]; !ok {
+				blockTimes[log.BlockNumber] = ethereum.GetBlockTime(chainID, log.BlockNumber)
+			}
+			changes = append(changes, log)
+		}
+
+		governanceMtx.Lock()
+		for _, log := range changes {
+			change := TRoleChange{
+				Account:     common.BytesToAddress(log.Topics[1].Bytes()),
+				TxHash:      log.TxHash,
+				BlockNumber: log.BlockNumber,
+				Timestamp:   blockTimes[log.BlockNumber],
+				Names:       []string{},
+			}
+			if log.Topics[0] == roleSetTopic && len(log.Topics) == 3 {
+				change.Type = `role`
+				change.Roles = new(big.Int).SetBytes(log.Topics[2].Bytes()).Uint64()
+				change.Names = RoleNames(change.Roles)
+			} else {
+				change.Type = `roleManager`
+			}
+			governance := getOrCreateGovernance(chainID, log.Address)
+			governance.History = append(governance.History, change)
+		}
+		for vault, next := range vaultNextBlock {
+			if next <= chunkEnd {
+				vaultNextBlock[vault] = chunkEnd + 1
+				nextBlocks[chainID][vault] = chunkEnd + 1
+			}
+		}
+		governanceMtx.Unlock()
+		changesCount += len(changes)
+	}
+	logs.Info(`Indexed ` + strconv.Itoa(changesCount) + ` role changes of the vaults on chain ` + strconv.FormatUint(chainID, 10))
+}
+
+/**************************************************************************************************
+** loadGovernance loads the access control persisted for a chain, once.
+**************************************************************************************************/
+func loadGovernance(chainID uint64) {
+	governanceMtx.RLock()
+	_, isLoaded := nextBlocks[chainID]
+	governanceMtx.RUnlock()
+	if isLoaded {
+		return
+	}
+
+	stored := storage.LoadVaultsGovernance(chainID)
+	governanceMtx.Lock()
+	governances[chainID] = stored.Governances
+	nextBlocks[chainID] = stored.NextBlocks
+	governanceMtx.Unlock()
+}
+
+func storeGovernance(chainID uint64) {
+	governanceMtx.RLock()
+	stored := storage.TJsonGovernanceStorage{
+		NextBlocks:  make(map[common.Address]uint64),
+		Governances: make(map[common.Address]*TVaultGovernance),
+	}
+	for vault, next := range nextBlocks[chainID] {
+		stored.NextBlocks[vault] = next
+	}
+	for vault, governance := range governances[chainID] {
+		stored.Governances[vault] = &TVaultGovernance{
+			RoleManager: governance.RoleManager,
+			Holders:     append([]TRoleHolder{}, governance.Holders...),
+			History:     append([]TRoleChange{}, governance.History...),
+		}
+	}
+	governanceMtx.RUnlock()
+	storage.StoreVaultsGovernance(chainID, stored)
+}
+
+func getOrCreateGovernance(chainID uint64, vaultAddress common.Address) *TVaultGovernance {
+	if _, ok := governances[chainID]; !ok {
+		governances[chainID] = make(map[common.Address]*TVaultGovernance)
+	}
+	if _, ok := governances[chainID][vaultAddress]; !ok {
+		governances[chainID][vaultAddress] = &TVaultGovernance{Holders: []TRoleHolder{}, History: []TRoleChange{}}
+	}
+	return governances[chainID][vaultAddress]
+}
+
+/**************************************************************************************************
+** listAccounts returns the accounts ever granted a role on a vault, or its role manager.
+**************************************************************************************************/
+func listAccounts(governance *TVaultGovernance) []common.Address {
+	seen := make(map[common.Address]bool)
+	accounts := []common.Address{}
+	for _, change := range governance.History {
+		if !seen[change.Account] {
+			seen[change.Account] = true
+			accounts = append(accounts, change.Account)
+		}
+	}
+	return accounts
+}
+
+/**************************************************************************************************
+** GetVaultGovernance returns the access control of a v3 vault, false if it is not indexed.
+**************************************************************************************************/
+func GetVaultGovernance(chainID uint64, vaultAddress common.Address) (TVaultGovernance, bool) {
+	governanceMtx.RLock()
+	defer governanceMtx.RUnlock()
+	governance, ok := governances[chainID][vaultAddress]
+	if !ok {
+		return TVaultGovernance{}, false
+	}
+	return TVaultGovernance{
+		RoleManager: governance.RoleManager,
+		Holders:     append([]TRoleHolder{}, governance.Holders...),
+		History:     append([]TRoleChange{}, governance.History...),
+	}, true
+}
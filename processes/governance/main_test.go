@@ -0,0 +1,27 @@
+package governance
+
+import (
+	"reflect"
+	"testing"
+)
+
+/**************************************************************************************************
+** TestRoleNames checks that the flags of a role bitmap are named in the order of their bits.
+**************************************************************************************************/
+func TestRoleNames(t *testing.T) {
+	tests := []struct {
+		roles    uint64
+		expected []string
+	}{
+		{0, []string{}},
+		{ROLE_DEBT_MANAGER, []string{`DEBT_MANAGER`}},
+		{ROLE_ADD_STRATEGY_MANAGER | ROLE_EMERGENCY_MANAGER, []string{`ADD_STRATEGY_MANAGER`, `EMERGENCY_MANAGER`}},
+		{16383, ROLE_NAMES},
+		{1 << 20, []string{}},
+	}
+	for _, test := range tests {
+		if names := RoleNames(test.roles); !reflect.DeepEqual(names, test.expected) {
+			t.Errorf("RoleNames(%d) = %v, expected %v", test.roles, names, test.expected)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package holders
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+
+	goEth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/backfill"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+var transferTopic = crypto.Keccak256Hash([]byte(`Transfer(address,address,uint256)`))
+
+/**************************************************************************************************
+** The concentration of a vault is measured on its TOP_HOLDERS_COUNT largest holders.
+**************************************************************************************************/
+const TOP_HOLDERS_COUNT = 10
+
+/**************************************************************************************************
+** THolderStats describes the distribution of the shares of a vault among its holders, from the
+** balances rebuilt from its Transfer events up to Block:
+** - HolderCount is the number of addresses holding shares.
+** - Top10Share is the part of the supply held by the 10 largest holders (0.42 for 42%).
+** - Gini is the Gini coefficient of the balances, from 0 (all holders hold the same amount) to 1
+**   (a single holder holds everything).
+** The contracts holding shares for their users (staking pools, zaps, ...) count as one holder.
+**************************************************************************************************/
+type THolderStats struct {
+	HolderCount uint64  `json:"holderCount"`
+	Top10Share  float64 `json:"top10Share"`
+	Gini        float64 `json:"gini"`
+	Block       uint64  `json:"block"`
+}
+
+var (
+	balances    = make(map[uint64]map[common.Address]map[common.Address]*big.Int)
+	scannedUpTo = make(map[uint64]map[common.Address]uint64)
+	stats       = make(map[uint64]map[common.Address]THolderStats)
+	holdersMtx  sync.RWMutex
+)
+
+/**************************************************************************************************
+** RefreshHolders applies the Transfer events of the vaults of a chain emitted since their last
+** scanned block, up to the last confirmed block, to the balances of their holders, then computes
+** their stats again. A new vault is scanned from its activation block. The balances are loaded
+** from the storage on the first refresh and persisted after every refresh, for the scan to resume
+** where it stopped.
+**************************************************************************************************/
+func RefreshHolders(chainID uint64) {
+	chain, ok := env.GetChain(chainID)
+	if !ok {
+		return
+	}
+	loadHolders(chainID)
+	end, err := ethereum.GetConfirmedBlockNumber(chainID)
+	if err != nil {
+		return
+	}
+
+	// The vaults scanned up to the same block are scanned together
+	vaultsByStart := make(map[uint64][]common.Address)
+	_, allVaults := storage.ListVaults(chainID)
+	holdersMtx.RLock()
+	for _, vault := range allVaults {
+		if vault.Activation == 0 {
+			continue // Without its activation block, the balances of the vault cannot be complete
+		}
+		start := vault.Activation
+		if scanned, ok := scannedUpTo[chainID][vault.Address]; ok {
+			start = scanned + 1
+		}
+		if start <= end {
+			vaultsByStart[start] = append(vaultsByStart[start], vault.Address)
+		}
+	}
+	holdersMtx.RUnlock()
+
+	client := ethereum.GetRPC(chainID)
+	logsRange := chain.GetLogsRange()
+	transfersCount := 0
+	for start, vaultAddresses := range vaultsByStart {
+		for _, group := range groupVaultAddresses(chain, vaultAddresses) {
+			transfersCount += scanTransfers(chainID, client, group, start, end, logsRange)
+		}
+	}
+
+	computeStats(chainID)
+	storeHolders(chainID)
+	logs.Info(`Indexed ` + strconv.Itoa(transfersCount) + ` transfers of the vaults on chain ` + strconv.FormatUint(chainID, 10))
+}
+
+/**************************************************************************************************
+** groupVaultAddresses returns the groups of vaults to filter the logs of together: all of them on
+** the chains whose RPC accepts an array of addresses, one vault per group otherwise, as the
+** Transfer events of all the tokens of the chain cannot be scanned without an address.
+**************************************************************************************************/
+func groupVaultAddresses(chain env.TChain, vaultAddresses []common.Address) [][]common.Address {
+	if chain.Capabilities.SupportsLogsAddressArray {
+		return [][]common.Address{vaultAddresses}
+	}
+	groups := [][]common.Address{}
+	for _, vaultAddress := range vaultAddresses {
+		groups = append(groups, []common.Address{vaultAddress})
+	}
+	return groups
+}
+
+/**************************************************************************************************
+** scanTransfers applies the Transfer events of some vaults from a block up to the end, chunk by
+** chunk, and returns the number of events applied. It stops at the first failed chunk, or when the
+** backfills of the chain are paused, the vaults being scanned again from there on the next refresh.
+**************************************************************************************************/
+func scanTransfers(chainID uint64, client *ethclient.Client, vaultAddresses []common.Address, start uint64, end uint64, logsRange uint64) int {
+	transfersCount := 0
+	scan := backfill.StartScan(chainID, `holders`, start, end, logsRange)
+	defer scan.Finish()
+	for chunkStart := start; chunkStart <= end; chunkStart += logsRange {
+		chunkEnd := min(chunkStart+logsRange-1, end)
+		query := goEth.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(chunkStart),
+			ToBlock:   new(big.Int).SetUint64(chunkEnd),
+			Addresses: vaultAddresses,
+			Topics:    [][]common.Hash{{transferTopic}},
+		}
+		if !scan.Acquire() {
+			break // Backfills paused, resumed from the last scanned block
+		}
+		history, err := client.FilterLogs(context.Background(), query)
+		scan.Release(err)
+		if err != nil {
+			logs.Error(`Failed to filter the transfers of the vaults on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+			break // Retried from the last scanned block on the next refresh
+		}
+		applyTransfers(chainID, vaultAddresses, history, chunkEnd)
+		transfersCount += len(history)
+	}
+	return transfersCount
+}
+
+/**************************************************************************************************
+** applyTransfers moves the shares of the transfers of a chunk of blocks from their sender to their
+** recipient, and marks the vaults as scanned up to the end of the chunk. The zero address, which
+** mints and burns the shares, is not a holder.
+**************************************************************************************************/
+func applyTransfers(chainID uint64, vaultAddresses []common.Address, history []types.Log, chunkEnd uint64) {
+	holdersMtx.Lock()
+	defer holdersMtx.Unlock()
+	for _, log := range history {
+		if log.Removed || len(log.Topics) != 3 || len(log.Data) != 32 {
+			continue
+		}
+		vaultBalances := getVaultBalances(chainID, log.Address)
+		amount := new(big.Int).SetBytes(log.Data)
+		from := common.BytesToAddress(log.Topics[1].Bytes())
+		to := common.BytesToAddress(log.Topics[2].Bytes())
+		if from != (common.Address{}) {
+			balance := new(big.Int).Sub(getBalance(vaultBalances, from), amount)
+			if balance.Sign() <= 0 {
+				delete(vaultBalances, from)
+			} else {
+				vaultBalances[from] = balance
+			}
+		}
+		if to != (common.Address{}) {
+			vaultBalances[to] = new(big.Int).Add(getBalance(vaultBalances, to), amount)
+		}
+	}
+	for _, vaultAddress := range vaultAddresses {
+		scannedUpTo[chainID][vaultAddress] = chunkEnd
+	}
+}
+
+func getVaultBalances(chainID uint64, vaultAddress common.Address) map[common.Address]*big.Int {
+	if _, ok := balances[chainID][vaultAddress]; !ok {
+		balances[chainID][vaultAddress] = make(map[common.Address]*big.Int)
+	}
+	return balances[chainID][vaultAddress]
+}
+
+func getBalance(vaultBalances map[common.Address]*big.Int, holder common.Address) *big.Int {
+	if balance, ok := vaultBalances[holder]; ok {
+		return balance
+	}
+	return new(big.Int)
+}
+
+/**************************************************************************************************
+** computeStats computes the stats of the vaults of a chain from the balances of their holders.
+** The Gini coefficient is computed on the balances sorted in ascending order x(1) ... x(n):
+** G = 2 * sum(i * x(i)) / (n * sum(x(i))) - (n + 1) / n.
+**************************************************************************************************/
+func computeStats(chainID uint64) {
+	holdersMtx.Lock()
+	defer holdersMtx.Unlock()
+
+	chainStats := make(map[common.Address]THolderStats)
+	for vaultAddress, scanned := range scannedUpTo[chainID] {
+		vaultBalances := balances[chainID][vaultAddress]
+		amounts := make([]float64, 0, len(vaultBalances))
+		total := 0.0
+		for _, balance := range vaultBalances {
+			amount, _ := new(big.Float).SetInt(balance).Float64()
+			amounts = append(amounts, amount)
+			total += amount
+		}
+		vaultStats := THolderStats{
+			HolderCount: uint64(len(amounts)),
+			Block:       scanned,
+		}
+		if total > 0 {
+			sort.Float64s(amounts)
+			n := float64(len(amounts))
+			weightedSum := 0.0
+			topSum := 0.0
+			for i, amount := range amounts {
+				weightedSum += float64(i+1) * amount
+				if i >= len(amounts)-TOP_HOLDERS_COUNT {
+					topSum += amount
+				}
+			}
+			vaultStats.Top10Share = topSum / total
+			vaultStats.Gini = max(2*weightedSum/(n*total)-(n+1)/n, 0)
+		}
+		chainStats[vaultAddress] = vaultStats
+	}
+	stats[chainID] = chainStats
+}
+
+/**************************************************************************************************
+** loadHolders loads the balances last stored for a chain, on its first refresh.
+**************************************************************************************************/
+func loadHolders(chainID uint64) {
+	holdersMtx.RLock()
+	_, isLoaded := balances[chainID]
+	holdersMtx.RUnlock()
+	if isLoaded {
+		return
+	}
+
+	stored := storage.LoadHolderBalances(chainID)
+	chainBalances := make(map[common.Address]map[common.Address]*big.Int)
+	chainScannedUpTo := make(map[common.Address]uint64)
+	for vaultAddress, scanned := range stored.ScannedUpTo {
+		chainScannedUpTo[common.HexToAddress(vaultAddress)] = scanned
+	}
+	for vaultAddress, vaultBalances := range stored.Balances {
+		chainBalances[common.HexToAddress(vaultAddress)] = make(map[common.Address]*big.Int)
+		for holder, balance := range vaultBalances {
+			if value, ok := new(big.Int).SetString(balance, 10); ok {
+				chainBalances[common.HexToAddress(vaultAddress)][common.HexToAddress(holder)] = value
+			}
+		}
+	}
+
+	holdersMtx.Lock()
+	balances[chainID] = chainBalances
+	scannedUpTo[chainID] = chainScannedUpTo
+	holdersMtx.Unlock()
+}
+
+func storeHolders(chainID uint64) {
+	holdersMtx.RLock()
+	stored := storage.TJsonHoldersStorage{
+		ScannedUpTo: make(map[string]uint64),
+		Balances:    make(map[string]map[string]string),
+	}
+	for vaultAddress, scanned := range scannedUpTo[chainID] {
+		stored.ScannedUpTo[vaultAddress.Hex()] = scanned
+	}
+	for vaultAddress, vaultBalances := range balances[chainID] {
+		stored.Balances[vaultAddress.Hex()] = make(map[string]string)
+		for holder, balance := range vaultBalances {
+			stored.Balances[vaultAddress.Hex()][holder.Hex()] = balance.String()
+		}
+	}
+	holdersMtx.RUnlock()
+	storage.StoreHolderBalances(chainID, stored)
+}
+
+/**************************************************************************************************
+** GetHolderStats returns the holder stats of a vault. The boolean is false when the vault was not
+** scanned yet.
+**************************************************************************************************/
+func GetHolderStats(chainID uint64, vaultAddress common.Address) (THolderStats, bool) {
+	holdersMtx.RLock()
+	defer holdersMtx.RUnlock()
+	vaultStats, ok := stats[chainID][vaultAddress]
+	return vaultStats, ok
+}
+
+/**************************************************************************************************
+** ListHolderVaults returns the vaults of a chain an address holds shares of, from the balances
+** rebuilt from their Transfer events.
+**************************************************************************************************/
+func ListHolderVaults(chainID uint64, holder common.Address) []common.Address {
+	holdersMtx.RLock()
+	defer holdersMtx.RUnlock()
+	vaultAddresses := []common.Address{}
+	for vaultAddress, vaultBalances := range balances[chainID] {
+		if balance, ok := vaultBalances[holder]; ok && balance.Sign() > 0 {
+			vaultAddresses = append(vaultAddresses, vaultAddress)
+		}
+	}
+	return vaultAddresses
+}
@@ -0,0 +1,62 @@
+package holders
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func transferLog(vault common.Address, from common.Address, to common.Address, amount int64) types.Log {
+	return types.Log{
+		Address: vault,
+		Topics:  []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:    common.LeftPadBytes(big.NewInt(amount).Bytes(), 32),
+	}
+}
+
+/**************************************************************************************************
+** TestHolderStats checks that the transfers move the balances, that the mints and burns do not
+** make the zero address a holder, and the stats computed from the balances.
+**************************************************************************************************/
+func TestHolderStats(t *testing.T) {
+	chainID := uint64(1337)
+	vault := common.HexToAddress(`0x01`)
+	alice := common.HexToAddress(`0xa1`)
+	bob := common.HexToAddress(`0xb0`)
+	carol := common.HexToAddress(`0xc0`)
+	balances[chainID] = make(map[common.Address]map[common.Address]*big.Int)
+	scannedUpTo[chainID] = make(map[common.Address]uint64)
+
+	applyTransfers(chainID, []common.Address{vault}, []types.Log{
+		transferLog(vault, common.Address{}, alice, 100),
+		transferLog(vault, common.Address{}, bob, 100),
+		transferLog(vault, alice, carol, 50),
+	}, 10)
+	computeStats(chainID)
+
+	stats, ok := GetHolderStats(chainID, vault)
+	if !ok || stats.HolderCount != 3 || stats.Block != 10 || stats.Top10Share != 1 {
+		t.Fatalf("unexpected stats after the first transfers: %+v", stats)
+	}
+	// Balances of 50, 50 and 100: G = 2 * (50 + 100 + 300) / (3 * 200) - 4 / 3 = 1 / 6
+	if math.Abs(stats.Gini-1.0/6.0) > 1e-9 {
+		t.Errorf("expected a Gini coefficient of 1/6, got %f", stats.Gini)
+	}
+
+	applyTransfers(chainID, []common.Address{vault}, []types.Log{
+		transferLog(vault, bob, common.Address{}, 100),
+		transferLog(vault, carol, alice, 50),
+	}, 20)
+	computeStats(chainID)
+
+	stats, _ = GetHolderStats(chainID, vault)
+	if stats.HolderCount != 1 || stats.Gini != 0 || stats.Block != 20 {
+		t.Errorf("expected a single holder after the burn, got %+v", stats)
+	}
+	if balance := balances[chainID][vault][alice]; balance == nil || balance.Int64() != 100 {
+		t.Errorf("expected alice to hold 100 shares, got %v", balance)
+	}
+}
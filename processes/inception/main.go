@@ -0,0 +1,306 @@
+package inception
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	goEth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/contracts"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/backfill"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/prices"
+)
+
+/**************************************************************************************************
+** The inception of a vault is backfilled from the archive node of its chain: the creation block is
+** the first block the vault has code at, found by bisecting up to its activation, and the creation
+** transaction is the one of that block emitting the first event of the vault, or deploying it.
+** The price per share, the parameters and the price of the asset are read at the creation block.
+** It only runs on the chains with an archive node, at most INCEPTION_BACKFILL_BATCH vaults per
+** refresh, the ones with the highest TVL first, on the backfill slots of the chain. A vault is only
+** backfilled once.
+**************************************************************************************************/
+const INCEPTION_BACKFILL_BATCH = 25
+
+/**************************************************************************************************
+** MIN_INCEPTION_AGE is the age below which the return since inception is not annualized, a few
+** days of yield compounding to meaningless APYs.
+**************************************************************************************************/
+const MIN_INCEPTION_AGE = 7 * 24 * time.Hour
+
+/**************************************************************************************************
+** TInception is the inception of a vault with its return since then, from its price per share:
+** the raw return (0.05 = +5%) and, past MIN_INCEPTION_AGE, the same return as an APY.
+**************************************************************************************************/
+type TInception struct {
+	models.TVaultInception
+	ReturnSinceInception *float64 `json:"returnSinceInception,omitempty"`
+	APYSinceInception    *float64 `json:"apySinceInception,omitempty"`
+}
+
+var (
+	inceptions   = make(map[uint64]map[common.Address]models.TVaultInception)
+	inceptionMtx sync.RWMutex
+)
+
+/**************************************************************************************************
+** loadChainInceptions returns the inceptions known for a chain, loading them from the storage
+** the first time. The caller must hold inceptionMtx.
+**************************************************************************************************/
+func loadChainInceptions(chainID uint64) map[common.Address]models.TVaultInception {
+	if _, ok := inceptions[chainID]; !ok {
+		inceptions[chainID] = storage.LoadVaultInceptions(chainID)
+	}
+	return inceptions[chainID]
+}
+
+/**************************************************************************************************
+** RefreshVaultsInception backfills the inception of the vaults of a chain that don't have one yet,
+** and persists the new ones.
+**************************************************************************************************/
+func RefreshVaultsInception(chainID uint64) {
+	client, isArchive := ethereum.GetArchiveRPC(chainID)
+	if client == nil || !isArchive {
+		return
+	}
+
+	inceptionMtx.Lock()
+	known := loadChainInceptions(chainID)
+	missing := []models.TVault{}
+	_, vaults := storage.ListVaults(chainID)
+	for _, vault := range vaults {
+		if _, ok := known[vault.Address]; !ok {
+			missing = append(missing, vault)
+		}
+	}
+	inceptionMtx.Unlock()
+	if len(missing) == 0 {
+		return
+	}
+	toBackfill := backfill.SortVaultsByPriority(missing)
+	if len(toBackfill) > INCEPTION_BACKFILL_BATCH {
+		toBackfill = toBackfill[:INCEPTION_BACKFILL_BATCH]
+	}
+
+	backfilled := make(map[common.Address]models.TVaultInception)
+	backfilledMtx := sync.Mutex{}
+	tasks := []backfill.TTask{}
+	for _, vault := range toBackfill {
+		vault := vault
+		tasks = append(tasks, backfill.TTask{
+			Priority: backfill.GetVaultPriority(vault),
+			Run: func() error {
+				inception, err := backfillInception(client, vault)
+				if err != nil {
+					logs.Warning(`Failed to backfill the inception of ` + vault.Address.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+					return err
+				}
+				backfilledMtx.Lock()
+				backfilled[vault.Address] = inception
+				backfilledMtx.Unlock()
+				return nil
+			},
+		})
+	}
+	backfill.Run(chainID, `inception`, tasks)
+	if len(backfilled) == 0 {
+		return
+	}
+
+	inceptionMtx.Lock()
+	known = loadChainInceptions(chainID)
+	for address, inception := range backfilled {
+		known[address] = inception
+	}
+	toStore := make(map[common.Address]models.TVaultInception, len(known))
+	for address, inception := range known {
+		toStore[address] = inception
+	}
+	inceptionMtx.Unlock()
+	storage.StoreVaultInceptions(chainID, toStore)
+	logs.Info(`Backfilled the inception of ` + strconv.Itoa(len(backfilled)) + ` vaults on chain ` + strconv.FormatUint(chainID, 10))
+}
+
+/**************************************************************************************************
+** backfillInception reads the creation of a vault from the archive node.
+**************************************************************************************************/
+func backfillInception(client *ethclient.Client, vault models.TVault) (models.TVaultInception, error) {
+	ctx := context.Background()
+	block, err := findCreationBlock(ctx, client, vault)
+	if err != nil {
+		return models.TVaultInception{}, err
+	}
+	header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+	if err != nil {
+		return models.TVaultInception{}, err
+	}
+	tx, err := findCreationTx(ctx, client, vault.Address, block)
+	if err != nil {
+		return models.TVaultInception{}, err
+	}
+
+	inception := models.TVaultInception{
+		Block:     block,
+		Timestamp: header.Time,
+		TxHash:    tx.Hash(),
+	}
+	if sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+		inception.Deployer = sender
+	}
+
+	/**********************************************************************************************
+	** The parameters of the vault at its creation block. The fees are only exposed by the v2
+	** vaults, and a call failing leaves its parameter unset.
+	**********************************************************************************************/
+	vaultCaller, err := contracts.NewYearnVaultCaller(vault.Address, client)
+	if err != nil {
+		return models.TVaultInception{}, err
+	}
+	opts := &bind.CallOpts{BlockNumber: new(big.Int).SetUint64(block), Context: ctx}
+	pricePerShare, err := vaultCaller.PricePerShare(opts)
+	if err != nil {
+		return models.TVaultInception{}, err
+	}
+	inception.PricePerShare = bigNumber.SetInt(pricePerShare)
+	if managementFee, err := vaultCaller.ManagementFee(opts); err == nil {
+		fee := managementFee.Uint64()
+		inception.ManagementFee = &fee
+	}
+	if performanceFee, err := vaultCaller.PerformanceFee(opts); err == nil {
+		fee := performanceFee.Uint64()
+		inception.PerformanceFee = &fee
+	}
+	if depositLimit, err := vaultCaller.DepositLimit(opts); err == nil {
+		inception.DepositLimit = bigNumber.SetInt(depositLimit)
+	}
+	if price, ok := prices.FetchHistoricalPriceFromLlama(vault.ChainID, vault.AssetAddress, header.Time); ok {
+		inception.AssetPriceUSD = &price
+	}
+	return inception, nil
+}
+
+/**************************************************************************************************
+** findCreationBlock bisects the first block the vault has code at, up to its activation block
+** (the vault existing by then), or up to the last block for the vaults without one.
+**************************************************************************************************/
+func findCreationBlock(ctx context.Context, client *ethclient.Client, vault models.TVault) (uint64, error) {
+	hasCode := func(block uint64) (bool, error) {
+		code, err := client.CodeAt(ctx, vault.Address, new(big.Int).SetUint64(block))
+		return len(code) > 0, err
+	}
+
+	high := vault.Activation
+	if ok, err := hasCode(high); high == 0 || err != nil || !ok {
+		if high, err = client.BlockNumber(ctx); err != nil {
+			return 0, err
+		}
+		if ok, err := hasCode(high); err != nil || !ok {
+			return 0, errors.New(`no code at the last block`)
+		}
+	}
+	low := uint64(0)
+	for low < high {
+		middle := low + (high-low)/2
+		ok, err := hasCode(middle)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			high = middle
+		} else {
+			low = middle + 1
+		}
+	}
+	return high, nil
+}
+
+/**************************************************************************************************
+** findCreationTx returns the transaction of a block that created a vault: the one emitting its
+** first event (the vaults emit their roles and parameters when initialized), or, for a vault
+** silent at its creation, the one whose receipt has it as the contract created.
+**************************************************************************************************/
+func findCreationTx(ctx context.Context, client *ethclient.Client, vaultAddress common.Address, block uint64) (*types.Transaction, error) {
+	blockNumber := new(big.Int).SetUint64(block)
+	vaultLogs, err := client.FilterLogs(ctx, goEth.FilterQuery{
+		FromBlock: blockNumber,
+		ToBlock:   blockNumber,
+		Addresses: []common.Address{vaultAddress},
+	})
+	if err == nil && len(vaultLogs) > 0 {
+		tx, _, err := client.TransactionByHash(ctx, vaultLogs[0].TxHash)
+		return tx, err
+	}
+
+	fullBlock, err := client.BlockByNumber(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range fullBlock.Transactions() {
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return nil, err
+		}
+		if receipt.ContractAddress == vaultAddress {
+			return tx, nil
+		}
+	}
+	return nil, errors.New(`creation transaction not found in block ` + strconv.FormatUint(block, 10))
+}
+
+/**************************************************************************************************
+** GetVaultInception returns the inception of a vault with its return since then, false until it
+** is backfilled. The inceptions stored are served on the chains without an archive node too.
+**************************************************************************************************/
+func GetVaultInception(vault models.TVault) (TInception, bool) {
+	inceptionMtx.RLock()
+	chainInceptions, loaded := inceptions[vault.ChainID]
+	vaultInception, ok := chainInceptions[vault.Address]
+	inceptionMtx.RUnlock()
+	if !loaded {
+		inceptionMtx.Lock()
+		vaultInception, ok = loadChainInceptions(vault.ChainID)[vault.Address]
+		inceptionMtx.Unlock()
+	}
+	if !ok {
+		return TInception{}, false
+	}
+	result := TInception{TVaultInception: vaultInception}
+	result.ReturnSinceInception, result.APYSinceInception = computeReturnSinceInception(vaultInception, vault.LastPricePerShare, time.Now())
+	return result, true
+}
+
+/**************************************************************************************************
+** computeReturnSinceInception returns the return of a vault since its inception from its price
+** per share, and its annualized value past MIN_INCEPTION_AGE.
+**************************************************************************************************/
+func computeReturnSinceInception(inception models.TVaultInception, pricePerShare *bigNumber.Int, now time.Time) (*float64, *float64) {
+	if inception.PricePerShare == nil || inception.PricePerShare.IsZero() || pricePerShare == nil || pricePerShare.IsZero() {
+		return nil, nil
+	}
+	ratio, _ := new(big.Float).Quo(
+		new(big.Float).SetInt(bigNumber.ToInt(pricePerShare)),
+		new(big.Float).SetInt(bigNumber.ToInt(inception.PricePerShare)),
+	).Float64()
+	returnSinceInception := ratio - 1
+
+	age := now.Sub(time.Unix(int64(inception.Timestamp), 0))
+	if age < MIN_INCEPTION_AGE {
+		return &returnSinceInception, nil
+	}
+	years := age.Hours() / (24 * 365)
+	apySinceInception := math.Pow(ratio, 1/years) - 1
+	return &returnSinceInception, &apySinceInception
+}
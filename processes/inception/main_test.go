@@ -0,0 +1,35 @@
+package inception
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+func TestComputeReturnSinceInception(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	inception := models.TVaultInception{
+		Timestamp:     uint64(createdAt.Unix()),
+		PricePerShare: bigNumber.NewInt(1_000_000),
+	}
+
+	returnSinceInception, apySinceInception := computeReturnSinceInception(inception, bigNumber.NewInt(1_210_000), createdAt.Add(2*365*24*time.Hour))
+	if returnSinceInception == nil || *returnSinceInception < 0.2099 || *returnSinceInception > 0.2101 {
+		t.Fatalf("expected a return of 21%%, got %v", returnSinceInception)
+	}
+	if apySinceInception == nil || *apySinceInception < 0.0999 || *apySinceInception > 0.1001 {
+		t.Errorf("expected an APY of 10%% over two years, got %v", apySinceInception)
+	}
+
+	_, apySinceInception = computeReturnSinceInception(inception, bigNumber.NewInt(1_010_000), createdAt.Add(24*time.Hour))
+	if apySinceInception != nil {
+		t.Errorf("expected no APY for a vault created a day ago, got %v", *apySinceInception)
+	}
+
+	inception.PricePerShare = nil
+	if returnSinceInception, _ := computeReturnSinceInception(inception, bigNumber.NewInt(1_010_000), createdAt); returnSinceInception != nil {
+		t.Errorf("expected no return without the inception price per share, got %v", *returnSinceInception)
+	}
+}
@@ -0,0 +1,226 @@
+package keepers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/addresses"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** Keeper types. The known keeper networks are set in the chain configuration, the other keepers
+** are classified on-chain: a Keep3r job exposes the `keep3r()` getter, an address without code
+** is an EOA, anything else is a generic contract.
+**************************************************************************************************/
+const (
+	KEEPER_TYPE_KEEP3R   = `keep3r`
+	KEEPER_TYPE_EOA      = `eoa`
+	KEEPER_TYPE_CONTRACT = `contract`
+	KEEPER_TYPE_UNKNOWN  = `unknown`
+)
+
+/**************************************************************************************************
+** A strategy is considered overdue when its next expected harvest is older than this delay. This
+** leaves some room for the keepers waiting for a better gas price.
+**************************************************************************************************/
+const overdueGracePeriod = 24 * time.Hour
+
+/**************************************************************************************************
+** TKeeperStatus describes the keeper of a strategy and the state of its harvest trigger.
+** TriggerReady is nil when the trigger cannot be read for the strategy. NextExpectedHarvest is the
+** timestamp of the last report plus the maximum delay between two reports (maxReportDelay for v2,
+** profitMaxUnlockTime for v3), 0 if unknown.
+**************************************************************************************************/
+type TKeeperStatus struct {
+	KeeperAddress       string `json:"keeperAddress"`
+	KeeperType          string `json:"keeperType"`
+	TriggerReady        *bool  `json:"triggerReady,omitempty"`
+	NextExpectedHarvest uint64 `json:"nextExpectedHarvest,omitempty"`
+	IsOverdue           bool   `json:"isOverdue"`
+	CheckedAt           uint64 `json:"checkedAt"`
+}
+
+var (
+	keeperStatuses    = make(map[uint64]map[common.Address]TKeeperStatus)
+	keeperStatusesMtx sync.RWMutex
+)
+
+/**************************************************************************************************
+** isV3Strategy returns true if the strategy is attached to a v3 vault.
+**************************************************************************************************/
+func isV3Strategy(strategy models.TStrategy) bool {
+	return strings.HasPrefix(strategy.VaultVersion, `3`) || strings.HasPrefix(strategy.VaultVersion, `~3`)
+}
+
+/**************************************************************************************************
+** classifyKeepers returns the type of each keeper, from the chain configuration first and from
+** the on-chain code of the keeper otherwise.
+**************************************************************************************************/
+func classifyKeepers(chainID uint64, keepers []common.Address) map[common.Address]string {
+	types := make(map[common.Address]string)
+	chain, _ := env.GetChain(chainID)
+	client := ethereum.GetRPC(chainID)
+
+	contractKeepers := []common.Address{}
+	for _, keeper := range keepers {
+		for _, knownKeeper := range chain.Keepers {
+			if addresses.Equals(knownKeeper.Address, keeper) {
+				types[keeper] = knownKeeper.Type
+				break
+			}
+		}
+		if _, ok := types[keeper]; ok {
+			continue
+		}
+		if client == nil {
+			types[keeper] = KEEPER_TYPE_UNKNOWN
+			continue
+		}
+		code, err := client.CodeAt(context.Background(), keeper, nil)
+		if err != nil {
+			types[keeper] = KEEPER_TYPE_UNKNOWN
+			continue
+		}
+		if len(code) == 0 {
+			types[keeper] = KEEPER_TYPE_EOA
+			continue
+		}
+		contractKeepers = append(contractKeepers, keeper)
+	}
+
+	if len(contractKeepers) == 0 {
+		return types
+	}
+	calls := []ethereum.Call{}
+	for _, keeper := range contractKeepers {
+		calls = append(calls, multicalls.GetKeep3r(keeper.Hex(), keeper))
+	}
+	response := multicalls.Perform(chainID, calls, nil)
+	for _, keeper := range contractKeepers {
+		rawKeep3r := response[keeper.Hex()+`keep3r`]
+		if len(rawKeep3r) > 0 && (helpers.DecodeAddress(rawKeep3r) != common.Address{}) {
+			types[keeper] = KEEPER_TYPE_KEEP3R
+		} else {
+			types[keeper] = KEEPER_TYPE_CONTRACT
+		}
+	}
+	return types
+}
+
+/**************************************************************************************************
+** RetrieveKeeperStatuses reads the keeper, the harvest trigger and the maximum report delay of
+** every active strategy of the chain and caches the result.
+** - v2 strategies expose `harvestTrigger` and `maxReportDelay` directly.
+** - v3 strategies are checked with the report trigger contract of the chain, if any, and use
+**   `profitMaxUnlockTime` as the expected delay between two reports.
+**************************************************************************************************/
+func RetrieveKeeperStatuses(chainID uint64) {
+	chain, ok := env.GetChain(chainID)
+	if !ok {
+		return
+	}
+	reportTrigger := chain.ReportTriggerContract.Address
+
+	_, allStrategies := storage.ListStrategies(chainID)
+	strategies := []models.TStrategy{}
+	calls := []ethereum.Call{}
+	for _, strategy := range allStrategies {
+		if strategy.IsRetired || strategy.LastTotalDebt == nil || strategy.LastTotalDebt.IsZero() {
+			continue
+		}
+		strategies = append(strategies, strategy)
+		key := strategy.Address.Hex()
+		calls = append(calls, multicalls.GetKeeper(key, strategy.Address, strategy.VaultVersion))
+		if isV3Strategy(strategy) {
+			calls = append(calls, multicalls.GetProfitMaxUnlockTime(key, strategy.Address, strategy.VaultVersion))
+			if (reportTrigger != common.Address{}) {
+				calls = append(calls, multicalls.GetStrategyReportTrigger(key, reportTrigger, strategy.Address))
+			}
+		} else {
+			calls = append(calls, multicalls.GetHarvestTrigger(key, strategy.Address, strategy.VaultVersion))
+			calls = append(calls, multicalls.GetMaxReportDelay(key, strategy.Address, strategy.VaultVersion))
+		}
+	}
+	if len(calls) == 0 {
+		return
+	}
+	response := multicalls.Perform(chainID, calls, nil)
+
+	keepers := []common.Address{}
+	for _, strategy := range strategies {
+		if rawKeeper := response[strategy.Address.Hex()+`keeper`]; len(rawKeeper) > 0 {
+			keeper := helpers.DecodeAddress(rawKeeper)
+			if !helpers.Contains(keepers, keeper) {
+				keepers = append(keepers, keeper)
+			}
+		}
+	}
+	keeperTypes := classifyKeepers(chainID, keepers)
+
+	now := time.Now()
+	result := make(map[common.Address]TKeeperStatus)
+	for _, strategy := range strategies {
+		key := strategy.Address.Hex()
+		status := TKeeperStatus{
+			KeeperType: KEEPER_TYPE_UNKNOWN,
+			CheckedAt:  uint64(now.Unix()),
+		}
+		if rawKeeper := response[key+`keeper`]; len(rawKeeper) > 0 {
+			keeper := helpers.DecodeAddress(rawKeeper)
+			status.KeeperAddress = keeper.Hex()
+			status.KeeperType = keeperTypes[keeper]
+		}
+
+		reportDelay := uint64(0)
+		if isV3Strategy(strategy) {
+			if rawTrigger := response[key+`strategyReportTrigger`]; len(rawTrigger) > 0 {
+				if isReady, ok := rawTrigger[0].(bool); ok {
+					status.TriggerReady = &isReady
+				}
+			}
+			reportDelay = helpers.DecodeBigInt(response[key+`profitMaxUnlockTime`]).Uint64()
+		} else {
+			if rawTrigger := response[key+`harvestTrigger`]; len(rawTrigger) > 0 {
+				isReady := helpers.DecodeBool(rawTrigger)
+				status.TriggerReady = &isReady
+			}
+			reportDelay = helpers.DecodeBigInt(response[key+`maxReportDelay`]).Uint64()
+		}
+
+		if strategy.LastReport != nil && !strategy.LastReport.IsZero() && reportDelay > 0 {
+			status.NextExpectedHarvest = strategy.LastReport.Uint64() + reportDelay
+			status.IsOverdue = now.Add(-overdueGracePeriod).Unix() > int64(status.NextExpectedHarvest)
+		}
+		result[strategy.Address] = status
+	}
+
+	keeperStatusesMtx.Lock()
+	keeperStatuses[chainID] = result
+	keeperStatusesMtx.Unlock()
+	logs.Info(`Retrieved keeper statuses for ` + strconv.Itoa(len(result)) + ` strategies on chain ` + strconv.FormatUint(chainID, 10))
+}
+
+/**************************************************************************************************
+** GetKeeperStatus returns the last known keeper status for a strategy, if any.
+**************************************************************************************************/
+func GetKeeperStatus(chainID uint64, strategyAddress common.Address) (TKeeperStatus, bool) {
+	keeperStatusesMtx.RLock()
+	defer keeperStatusesMtx.RUnlock()
+
+	if _, ok := keeperStatuses[chainID]; !ok {
+		return TKeeperStatus{}, false
+	}
+	status, ok := keeperStatuses[chainID][strategyAddress]
+	return status, ok
+}
@@ -0,0 +1,175 @@
+package keepers
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/backfill"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The strategies tending their position between two harvests (the leveraged lenders rebalancing
+** their leverage, the farmers compounding their rewards) are called with tend() by their keeper,
+** directly or through a keeper contract. The strategies emit no event on a tend: the calls are
+** found in the traces, on the chains supporting them (SupportsTraces), the others having no tend
+** activity. The tends are indexed from TEND_WINDOW ago on the first refresh, then from the last
+** scanned block, and the activity of a strategy is summarized over TEND_WINDOW.
+**************************************************************************************************/
+var TEND_SELECTOR = crypto.Keccak256([]byte(`tend()`))[:4]
+
+const TEND_WINDOW = 30 * 24 * time.Hour
+
+/**************************************************************************************************
+** TTendActivity summarizes the tends of a strategy over TEND_WINDOW: the time of the last one,
+** their count, their frequency and the average delay between two of them, in seconds (0 with
+** less than two tends).
+**************************************************************************************************/
+type TTendActivity struct {
+	LastTend        uint64  `json:"lastTend"`
+	Count           int     `json:"count"`
+	TendsPerDay     float64 `json:"tendsPerDay"`
+	AverageInterval uint64  `json:"averageInterval,omitempty"`
+}
+
+var (
+	strategyTends        = make(map[uint64]map[common.Address][]uint64)
+	lastTendScannedBlock = make(map[uint64]uint64)
+	tendsMtx             sync.RWMutex
+)
+
+/**************************************************************************************************
+** RefreshStrategiesTends indexes the tends of the active strategies of a chain since the last
+** refresh, and forgets the ones older than TEND_WINDOW.
+**************************************************************************************************/
+func RefreshStrategiesTends(chainID uint64) {
+	chain, ok := env.GetChain(chainID)
+	if !ok || !chain.Capabilities.SupportsTraces || ethereum.GetRPC(chainID) == nil {
+		return
+	}
+	strategyAddresses := []common.Address{}
+	isActive := make(map[common.Address]bool)
+	_, allStrategies := storage.ListStrategies(chainID)
+	for _, strategy := range allStrategies {
+		if strategy.IsRetired || strategy.LastTotalDebt == nil || strategy.LastTotalDebt.IsZero() {
+			continue
+		}
+		strategyAddresses = append(strategyAddresses, strategy.Address)
+		isActive[strategy.Address] = true
+	}
+	if len(strategyAddresses) == 0 {
+		return
+	}
+
+	tendsMtx.RLock()
+	start, isScanned := lastTendScannedBlock[chainID]
+	tendsMtx.RUnlock()
+	if !isScanned {
+		start = ethereum.GetBlockNumberByPeriod(chainID, uint64(TEND_WINDOW.Hours()/24))
+		if start == 0 {
+			return
+		}
+	}
+	end, err := ethereum.GetConfirmedBlockNumber(chainID)
+	if err != nil || end <= start {
+		return
+	}
+
+	newTends := make(map[common.Address][]uint64)
+	newTendsCount := 0
+	logsRange := chain.GetLogsRange()
+	scan := backfill.StartScan(chainID, `tends`, start, end, logsRange)
+	defer scan.Finish()
+	for chunkStart := start; chunkStart <= end; chunkStart += logsRange {
+		chunkEnd := chunkStart + logsRange - 1
+		if chunkEnd > end {
+			chunkEnd = end
+		}
+		if !scan.Acquire() {
+			return // Backfills paused, resumed from the same block
+		}
+		calls, err := ethereum.FilterCallTraces(chainID, chunkStart, chunkEnd, strategyAddresses, TEND_SELECTOR)
+		scan.Release(err)
+		if err != nil {
+			logs.Error(`Failed to trace the tends of the strategies on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+			return // Retried from the same block on the next refresh
+		}
+		for _, call := range calls {
+			if !isActive[call.To] {
+				continue
+			}
+			newTends[call.To] = append(newTends[call.To], ethereum.GetBlockTime(chainID, call.BlockNumber))
+			newTendsCount++
+		}
+	}
+
+	tendsMtx.Lock()
+	if _, ok := strategyTends[chainID]; !ok {
+		strategyTends[chainID] = make(map[common.Address][]uint64)
+	}
+	for strategyAddress, timestamps := range newTends {
+		strategyTends[chainID][strategyAddress] = append(strategyTends[chainID][strategyAddress], timestamps...)
+	}
+	strategyTends[chainID] = pruneTends(strategyTends[chainID], time.Now())
+	lastTendScannedBlock[chainID] = end + 1
+	tendsMtx.Unlock()
+	logs.Info(`Indexed ` + strconv.Itoa(newTendsCount) + ` tends of the strategies on chain ` + strconv.FormatUint(chainID, 10))
+}
+
+/**************************************************************************************************
+** pruneTends drops the tends older than TEND_WINDOW, and sorts the others from the oldest.
+**************************************************************************************************/
+func pruneTends(tends map[common.Address][]uint64, now time.Time) map[common.Address][]uint64 {
+	pruned := make(map[common.Address][]uint64)
+	for strategyAddress, timestamps := range tends {
+		recent := []uint64{}
+		for _, timestamp := range timestamps {
+			if now.Sub(time.Unix(int64(timestamp), 0)) <= TEND_WINDOW {
+				recent = append(recent, timestamp)
+			}
+		}
+		if len(recent) == 0 {
+			continue
+		}
+		sort.Slice(recent, func(i, j int) bool { return recent[i] < recent[j] })
+		pruned[strategyAddress] = recent
+	}
+	return pruned
+}
+
+/**************************************************************************************************
+** summarizeTends computes the activity of a strategy from the timestamps of its tends over
+** TEND_WINDOW, sorted from the oldest.
+**************************************************************************************************/
+func summarizeTends(timestamps []uint64) TTendActivity {
+	activity := TTendActivity{
+		LastTend:    timestamps[len(timestamps)-1],
+		Count:       len(timestamps),
+		TendsPerDay: float64(len(timestamps)) / (TEND_WINDOW.Hours() / 24),
+	}
+	if len(timestamps) > 1 {
+		activity.AverageInterval = (timestamps[len(timestamps)-1] - timestamps[0]) / uint64(len(timestamps)-1)
+	}
+	return activity
+}
+
+/**************************************************************************************************
+** GetTendActivity returns the tend activity of a strategy, false if it was not tended in the last
+** TEND_WINDOW.
+**************************************************************************************************/
+func GetTendActivity(chainID uint64, strategyAddress common.Address) (TTendActivity, bool) {
+	tendsMtx.RLock()
+	defer tendsMtx.RUnlock()
+	timestamps := strategyTends[chainID][strategyAddress]
+	if len(timestamps) == 0 || time.Since(time.Unix(int64(timestamps[len(timestamps)-1]), 0)) > TEND_WINDOW {
+		return TTendActivity{}, false
+	}
+	return summarizeTends(timestamps), true
+}
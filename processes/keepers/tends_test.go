@@ -0,0 +1,43 @@
+package keepers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestPruneTends(t *testing.T) {
+	now := time.Unix(100*24*3600, 0)
+	day := uint64(24 * 3600)
+	nowTimestamp := uint64(now.Unix())
+	strategy := common.HexToAddress(`0x1`)
+	stale := common.HexToAddress(`0x2`)
+	pruned := pruneTends(map[common.Address][]uint64{
+		strategy: {nowTimestamp - day, nowTimestamp - 40*day, nowTimestamp - 3*day},
+		stale:    {nowTimestamp - 31*day},
+	}, now)
+
+	if _, ok := pruned[stale]; ok {
+		t.Error("expected the strategy without recent tend to be dropped")
+	}
+	if got := pruned[strategy]; len(got) != 2 || got[0] != nowTimestamp-3*day || got[1] != nowTimestamp-day {
+		t.Errorf("expected the two recent tends sorted from the oldest, got %v", got)
+	}
+}
+
+func TestSummarizeTends(t *testing.T) {
+	activity := summarizeTends([]uint64{1000, 4000, 7000, 10000})
+	if activity.LastTend != 10000 || activity.Count != 4 {
+		t.Errorf("unexpected last tend %d or count %d", activity.LastTend, activity.Count)
+	}
+	if activity.AverageInterval != 3000 {
+		t.Errorf("expected an average interval of 3000 seconds, got %d", activity.AverageInterval)
+	}
+	if want := 4.0 / 30; activity.TendsPerDay != want {
+		t.Errorf("expected %f tends per day, got %f", want, activity.TendsPerDay)
+	}
+	if single := summarizeTends([]uint64{1000}); single.AverageInterval != 0 {
+		t.Errorf("expected no average interval for a single tend, got %d", single.AverageInterval)
+	}
+}
@@ -0,0 +1,173 @@
+package liquidity
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** A withdrawal from a v3 vault is first paid from its idle assets, then from its strategies, in
+** the order of its default queue. A strategy can only give back its maxWithdraw (its liquidity),
+** and the user bears its share of the unrealised losses of the debt withdrawn from it. The
+** instantly withdrawable assets of a vault are the assets a withdrawal can get this way, a larger
+** withdrawal failing or being paid partially.
+**************************************************************************************************/
+
+/**************************************************************************************************
+** TQueuedStrategy is a strategy of the default queue of a vault: its debt, the assets the vault can
+** withdraw from it, the share of its debt the vault would lose withdrawing it all, and the assets
+** a withdrawal can get from it.
+**************************************************************************************************/
+type TQueuedStrategy struct {
+	Address        common.Address `json:"address"`
+	CurrentDebt    *bigNumber.Int `json:"currentDebt"`
+	MaxWithdraw    *bigNumber.Int `json:"maxWithdraw,omitempty"` // Unset until hydrated, not limiting
+	UnrealisedLoss *bigNumber.Int `json:"unrealisedLoss"`
+	Withdrawable   *bigNumber.Int `json:"withdrawable"`
+}
+
+/**************************************************************************************************
+** TWithdrawalLiquidity is the liquidity of a v3 vault for the withdrawals, in its asset.
+**************************************************************************************************/
+type TWithdrawalLiquidity struct {
+	TotalIdle                 *bigNumber.Int    `json:"totalIdle"`
+	InstantWithdrawableAssets *bigNumber.Int    `json:"instantWithdrawableAssets"`
+	Queue                     []TQueuedStrategy `json:"queue"`
+}
+
+/**************************************************************************************************
+** TUnwoundStrategy is a strategy a withdrawal takes assets from: the debt withdrawn from it, the
+** assets the user gets, and the loss the user bears.
+**************************************************************************************************/
+type TUnwoundStrategy struct {
+	Address common.Address `json:"address"`
+	Debt    *bigNumber.Int `json:"debt"`
+	Assets  *bigNumber.Int `json:"assets"`
+	Loss    *bigNumber.Int `json:"loss"`
+}
+
+/**************************************************************************************************
+** TWithdrawalSimulation is the outcome of the withdrawal of an amount of assets from a vault.
+** ExceedsInstantLiquidity is true when the liquidity of the vault can't pay it all.
+**************************************************************************************************/
+type TWithdrawalSimulation struct {
+	Amount                  *bigNumber.Int     `json:"amount"`
+	FromIdle                *bigNumber.Int     `json:"fromIdle"`
+	Withdrawable            *bigNumber.Int     `json:"withdrawable"`
+	Loss                    *bigNumber.Int     `json:"loss"`
+	ExceedsInstantLiquidity bool               `json:"exceedsInstantLiquidity"`
+	UnwoundStrategies       []TUnwoundStrategy `json:"unwoundStrategies"`
+}
+
+func minInt(x *bigNumber.Int, y *bigNumber.Int) *bigNumber.Int {
+	if x.Lt(y) {
+		return bigNumber.NewInt(0).Clone(x)
+	}
+	return bigNumber.NewInt(0).Clone(y)
+}
+
+/**************************************************************************************************
+** newQueuedStrategy returns the queue entry of a strategy, the assets a withdrawal can get from it
+** being its debt without its unrealised loss, up to its maxWithdraw.
+**************************************************************************************************/
+func newQueuedStrategy(strategy models.TStrategy) TQueuedStrategy {
+	queued := TQueuedStrategy{
+		Address:        strategy.Address,
+		CurrentDebt:    bigNumber.NewInt(0).Safe(strategy.LastTotalDebt),
+		MaxWithdraw:    strategy.LastMaxWithdraw,
+		UnrealisedLoss: bigNumber.NewInt(0).Safe(strategy.LastUnrealisedLoss),
+	}
+	queued.Withdrawable = bigNumber.NewInt(0).Sub(queued.CurrentDebt, queued.UnrealisedLoss)
+	if queued.Withdrawable.Lt(bigNumber.NewInt(0)) {
+		queued.Withdrawable = bigNumber.NewInt(0)
+	}
+	if queued.MaxWithdraw != nil {
+		queued.Withdrawable = minInt(queued.Withdrawable, queued.MaxWithdraw)
+	}
+	return queued
+}
+
+/**************************************************************************************************
+** GetWithdrawalLiquidity returns the liquidity of a v3 vault for the withdrawals, false for the
+** other vaults and the v3 vaults not hydrated yet.
+**************************************************************************************************/
+func GetWithdrawalLiquidity(vault models.TVault) (TWithdrawalLiquidity, bool) {
+	if vault.LastTotalIdle == nil {
+		return TWithdrawalLiquidity{}, false
+	}
+	strategies := []models.TStrategy{}
+	for _, strategyAddress := range vault.DefaultQueue {
+		strategy, ok := storage.GetStrategy(vault.ChainID, strategyAddress, vault.Address)
+		if !ok {
+			strategy = models.TStrategy{Address: strategyAddress} // Nothing withdrawable until hydrated
+		}
+		strategies = append(strategies, strategy)
+	}
+	return computeWithdrawalLiquidity(vault.LastTotalIdle, strategies), true
+}
+
+func computeWithdrawalLiquidity(totalIdle *bigNumber.Int, strategies []models.TStrategy) TWithdrawalLiquidity {
+	liquidity := TWithdrawalLiquidity{
+		TotalIdle:                 totalIdle,
+		InstantWithdrawableAssets: bigNumber.NewInt(0).Clone(totalIdle),
+		Queue:                     []TQueuedStrategy{},
+	}
+	for _, strategy := range strategies {
+		queued := newQueuedStrategy(strategy)
+		liquidity.InstantWithdrawableAssets.Add(queued.Withdrawable)
+		liquidity.Queue = append(liquidity.Queue, queued)
+	}
+	return liquidity
+}
+
+/**************************************************************************************************
+** SimulateWithdrawal simulates the withdrawal of an amount of assets from a vault, the way the v3
+** vaults do it: from the idle assets, then from the strategies of the default queue in order, the
+** loss of the debt withdrawn from a strategy being deducted from the amount.
+**************************************************************************************************/
+func SimulateWithdrawal(liquidity TWithdrawalLiquidity, amount *bigNumber.Int) TWithdrawalSimulation {
+	simulation := TWithdrawalSimulation{
+		Amount:            amount,
+		FromIdle:          minInt(amount, liquidity.TotalIdle),
+		Loss:              bigNumber.NewInt(0),
+		UnwoundStrategies: []TUnwoundStrategy{},
+	}
+	remaining := bigNumber.NewInt(0).Sub(amount, simulation.FromIdle)
+	withdrawable := bigNumber.NewInt(0).Clone(simulation.FromIdle)
+
+	for _, queued := range liquidity.Queue {
+		if remaining.IsZero() {
+			break
+		}
+		if queued.CurrentDebt.IsZero() || queued.Withdrawable.IsZero() {
+			continue
+		}
+		debt := minInt(remaining, queued.CurrentDebt)
+		loss := bigNumber.NewInt(0).Div(bigNumber.NewInt(0).Mul(debt, queued.UnrealisedLoss), queued.CurrentDebt)
+		assets := bigNumber.NewInt(0).Sub(debt, loss)
+		if assets.Gt(queued.Withdrawable) {
+			// Limited by the liquidity of the strategy, the debt withdrawn is scaled down with its loss
+			assets = bigNumber.NewInt(0).Clone(queued.Withdrawable)
+			debt = bigNumber.NewInt(0).Div(
+				bigNumber.NewInt(0).Mul(assets, queued.CurrentDebt),
+				bigNumber.NewInt(0).Sub(queued.CurrentDebt, queued.UnrealisedLoss),
+			)
+			loss = bigNumber.NewInt(0).Sub(debt, assets)
+		}
+		remaining.Sub(debt)
+		withdrawable.Add(assets)
+		simulation.Loss.Add(loss)
+		simulation.UnwoundStrategies = append(simulation.UnwoundStrategies, TUnwoundStrategy{
+			Address: queued.Address,
+			Debt:    debt,
+			Assets:  assets,
+			Loss:    loss,
+		})
+	}
+
+	simulation.Withdrawable = withdrawable
+	simulation.ExceedsInstantLiquidity = remaining.Gt(bigNumber.NewInt(0))
+	return simulation
+}
@@ -0,0 +1,64 @@
+package liquidity
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+var (
+	lossyStrategy   = common.HexToAddress(`0x1111111111111111111111111111111111111111`)
+	limitedStrategy = common.HexToAddress(`0x2222222222222222222222222222222222222222`)
+	emptyStrategy   = common.HexToAddress(`0x3333333333333333333333333333333333333333`)
+)
+
+/**************************************************************************************************
+** testLiquidity is a vault with 100 idle assets and a queue of three strategies: one with 1000 of
+** debt and 100 of unrealised loss, one empty, and one with 1000 of debt of which only 300 can be
+** withdrawn.
+**************************************************************************************************/
+func testLiquidity() TWithdrawalLiquidity {
+	return computeWithdrawalLiquidity(bigNumber.NewInt(100), []models.TStrategy{
+		{Address: lossyStrategy, LastTotalDebt: bigNumber.NewInt(1000), LastMaxWithdraw: bigNumber.NewInt(2000), LastUnrealisedLoss: bigNumber.NewInt(100)},
+		{Address: emptyStrategy, LastTotalDebt: bigNumber.NewInt(0)},
+		{Address: limitedStrategy, LastTotalDebt: bigNumber.NewInt(1000), LastMaxWithdraw: bigNumber.NewInt(300)},
+	})
+}
+
+func TestWithdrawalLiquidity(t *testing.T) {
+	liquidity := testLiquidity()
+	if liquidity.InstantWithdrawableAssets.String() != `1300` {
+		t.Errorf("expected 1300 instantly withdrawable assets, got %s", liquidity.InstantWithdrawableAssets)
+	}
+	if liquidity.Queue[0].Withdrawable.String() != `900` || liquidity.Queue[2].Withdrawable.String() != `300` {
+		t.Errorf("unexpected withdrawable assets %s and %s", liquidity.Queue[0].Withdrawable, liquidity.Queue[2].Withdrawable)
+	}
+}
+
+func TestSimulateWithdrawal(t *testing.T) {
+	liquidity := testLiquidity()
+
+	simulation := SimulateWithdrawal(liquidity, bigNumber.NewInt(80))
+	if simulation.FromIdle.String() != `80` || len(simulation.UnwoundStrategies) != 0 || simulation.ExceedsInstantLiquidity {
+		t.Errorf("expected a withdrawal paid from the idle assets, got %+v", simulation)
+	}
+
+	simulation = SimulateWithdrawal(liquidity, bigNumber.NewInt(600))
+	if len(simulation.UnwoundStrategies) != 1 || simulation.UnwoundStrategies[0].Address != lossyStrategy {
+		t.Fatalf("expected the first strategy to be unwound, got %+v", simulation.UnwoundStrategies)
+	}
+	unwound := simulation.UnwoundStrategies[0]
+	if unwound.Debt.String() != `500` || unwound.Loss.String() != `50` || simulation.Withdrawable.String() != `550` || simulation.ExceedsInstantLiquidity {
+		t.Errorf("unexpected withdrawal %+v from %+v", simulation, unwound)
+	}
+
+	simulation = SimulateWithdrawal(liquidity, bigNumber.NewInt(2000))
+	if len(simulation.UnwoundStrategies) != 2 || simulation.UnwoundStrategies[1].Address != limitedStrategy {
+		t.Fatalf("expected the first and last strategies to be unwound, got %+v", simulation.UnwoundStrategies)
+	}
+	if simulation.UnwoundStrategies[1].Assets.String() != `300` || simulation.Withdrawable.String() != `1300` || !simulation.ExceedsInstantLiquidity {
+		t.Errorf("expected a withdrawal limited by the liquidity of the vault, got %+v", simulation)
+	}
+}
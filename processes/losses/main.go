@@ -0,0 +1,226 @@
+package losses
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	goEth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/backfill"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The harvests of the strategies are reported by their vault with a StrategyReported event, the
+** loss being the second word of its data for all the versions of the vaults: the v2 ones from
+** 0.3.2 (with the debt paid), the v2 0.3.0 and 0.3.1 ones (without it), and the v3 ones. The
+** reports with a loss are indexed from RECENT_LOSS_DURATION ago on the first refresh, then from
+** the last scanned block, and a vault is flagged with its last loss for RECENT_LOSS_DURATION.
+**************************************************************************************************/
+var (
+	strategyReportedV2Topic    = crypto.Keccak256Hash([]byte(`StrategyReported(address,uint256,uint256,uint256,uint256,uint256,uint256,uint256,uint256)`))
+	strategyReportedV2030Topic = crypto.Keccak256Hash([]byte(`StrategyReported(address,uint256,uint256,uint256,uint256,uint256,uint256,uint256)`))
+	strategyReportedV3Topic    = crypto.Keccak256Hash([]byte(`StrategyReported(address,uint256,uint256,uint256,uint256,uint256,uint256)`))
+)
+
+const RECENT_LOSS_DURATION = 30 * 24 * time.Hour
+
+/**************************************************************************************************
+** TLoss is a loss reported by a strategy of a vault: the raw amount of assets lost, its value at
+** the current price of the asset, and the report it comes from.
+**************************************************************************************************/
+type TLoss struct {
+	Strategy    common.Address `json:"strategy"`
+	Amount      *bigNumber.Int `json:"amount"`
+	AmountUSD   float64        `json:"amountUSD"`
+	TxHash      common.Hash    `json:"txHash"`
+	BlockNumber uint64         `json:"blockNumber"`
+	Timestamp   uint64         `json:"timestamp"`
+}
+
+/**************************************************************************************************
+** OnLoss is called with the losses reported since the last refresh, the ones found by the first
+** refresh of a chain being its history. It's set by the daemon to push an immediate alert.
+**************************************************************************************************/
+var OnLoss func(chainID uint64, vault models.TVault, loss TLoss)
+
+var (
+	recentLosses     = make(map[uint64]map[common.Address][]TLoss)
+	lastScannedBlock = make(map[uint64]uint64)
+	lossesMtx        sync.RWMutex
+)
+
+type tNewLoss struct {
+	vault models.TVault
+	loss  TLoss
+}
+
+/**************************************************************************************************
+** RefreshVaultsLosses indexes the losses reported by the strategies of the vaults of a chain since
+** the last refresh, and forgets the ones older than RECENT_LOSS_DURATION.
+**************************************************************************************************/
+func RefreshVaultsLosses(chainID uint64) {
+	chain, _ := env.GetChain(chainID)
+	client := ethereum.GetRPC(chainID)
+	vaults, allVaults := storage.ListVaults(chainID)
+	if len(allVaults) == 0 {
+		return
+	}
+
+	lossesMtx.RLock()
+	start, isScanned := lastScannedBlock[chainID]
+	lossesMtx.RUnlock()
+	if !isScanned {
+		start = ethereum.GetBlockNumberByPeriod(chainID, 30)
+		if start == 0 {
+			return
+		}
+	}
+	end, err := ethereum.GetConfirmedBlockNumber(chainID)
+	if err != nil || end <= start {
+		return
+	}
+
+	vaultAddresses := []common.Address{}
+	for _, vault := range allVaults {
+		vaultAddresses = append(vaultAddresses, vault.Address)
+	}
+
+	newLosses := []tNewLoss{}
+	logsRange := chain.GetLogsRange()
+	scan := backfill.StartScan(chainID, `losses`, start, end, logsRange)
+	defer scan.Finish()
+	for chunkStart := start; chunkStart <= end; chunkStart += logsRange {
+		chunkEnd := chunkStart + logsRange - 1
+		if chunkEnd > end {
+			chunkEnd = end
+		}
+		query := goEth.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(chunkStart),
+			ToBlock:   new(big.Int).SetUint64(chunkEnd),
+			Topics:    [][]common.Hash{{strategyReportedV2Topic, strategyReportedV2030Topic, strategyReportedV3Topic}},
+		}
+		if chain.Capabilities.SupportsLogsAddressArray {
+			query.Addresses = vaultAddresses
+		}
+		if !scan.Acquire() {
+			return // Backfills paused, resumed from the same block
+		}
+		history, err := client.FilterLogs(context.Background(), query)
+		scan.Release(err)
+		if err != nil {
+			logs.Error(`Failed to filter the reports of the vaults on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+			return // Retried from the same block on the next refresh
+		}
+		for _, log := range history {
+			vault, ok := vaults[log.Address]
+			if !ok {
+				continue
+			}
+			if loss, ok := decodeLoss(log); ok {
+				loss.Timestamp = ethereum.GetBlockTime(chainID, log.BlockNumber)
+				loss.AmountUSD = computeLossUSD(chainID, vault, loss.Amount)
+				newLosses = append(newLosses, tNewLoss{vault: vault, loss: loss})
+			}
+		}
+	}
+
+	lossesMtx.Lock()
+	if _, ok := recentLosses[chainID]; !ok {
+		recentLosses[chainID] = make(map[common.Address][]TLoss)
+	}
+	for _, newLoss := range newLosses {
+		recentLosses[chainID][newLoss.vault.Address] = append(recentLosses[chainID][newLoss.vault.Address], newLoss.loss)
+	}
+	recentLosses[chainID] = pruneLosses(recentLosses[chainID], time.Now())
+	lastScannedBlock[chainID] = end + 1
+	lossesMtx.Unlock()
+	logs.Info(`Indexed ` + strconv.Itoa(len(newLosses)) + ` losses of the vaults on chain ` + strconv.FormatUint(chainID, 10))
+
+	if isScanned && OnLoss != nil {
+		for _, newLoss := range newLosses {
+			OnLoss(chainID, newLoss.vault, newLoss.loss)
+		}
+	}
+}
+
+/**************************************************************************************************
+** decodeLoss decodes a report of a strategy, false when it has no loss. The strategy is the only
+** indexed argument of the event, and the loss the second word of its data.
+**************************************************************************************************/
+func decodeLoss(log types.Log) (TLoss, bool) {
+	if len(log.Topics) < 2 || len(log.Data) < 2*32 {
+		return TLoss{}, false
+	}
+	amount := new(big.Int).SetBytes(log.Data[32:64])
+	if amount.Sign() == 0 {
+		return TLoss{}, false
+	}
+	return TLoss{
+		Strategy:    common.BytesToAddress(log.Topics[1].Bytes()),
+		Amount:      bigNumber.SetInt(amount),
+		TxHash:      log.TxHash,
+		BlockNumber: log.BlockNumber,
+	}, true
+}
+
+/**************************************************************************************************
+** computeLossUSD values a loss of a vault at the current price of its asset, 0 when unpriced.
+**************************************************************************************************/
+func computeLossUSD(chainID uint64, vault models.TVault, amount *bigNumber.Int) float64 {
+	asset, okAsset := storage.GetERC20(chainID, vault.AssetAddress)
+	price, okPrice := storage.GetPrice(chainID, vault.AssetAddress)
+	if !okAsset || !okPrice || price.HumanizedPrice == nil {
+		return 0
+	}
+	amountUSD, _ := bigNumber.NewFloat(0).Mul(helpers.ToNormalizedAmount(amount, asset.Decimals), price.HumanizedPrice).Float64()
+	return amountUSD
+}
+
+/**************************************************************************************************
+** pruneLosses drops the losses older than RECENT_LOSS_DURATION, and sorts the others from the most
+** recent.
+**************************************************************************************************/
+func pruneLosses(losses map[common.Address][]TLoss, now time.Time) map[common.Address][]TLoss {
+	pruned := make(map[common.Address][]TLoss)
+	for vaultAddress, vaultLosses := range losses {
+		recent := []TLoss{}
+		for _, loss := range vaultLosses {
+			if now.Sub(time.Unix(int64(loss.Timestamp), 0)) <= RECENT_LOSS_DURATION {
+				recent = append(recent, loss)
+			}
+		}
+		if len(recent) == 0 {
+			continue
+		}
+		sort.Slice(recent, func(i, j int) bool { return recent[i].BlockNumber > recent[j].BlockNumber })
+		pruned[vaultAddress] = recent
+	}
+	return pruned
+}
+
+/**************************************************************************************************
+** GetRecentLoss returns the last loss reported by a strategy of a vault, false if none was
+** reported in the last RECENT_LOSS_DURATION.
+**************************************************************************************************/
+func GetRecentLoss(chainID uint64, vaultAddress common.Address) (TLoss, bool) {
+	lossesMtx.RLock()
+	defer lossesMtx.RUnlock()
+	vaultLosses := recentLosses[chainID][vaultAddress]
+	if len(vaultLosses) == 0 || time.Since(time.Unix(int64(vaultLosses[0].Timestamp), 0)) > RECENT_LOSS_DURATION {
+		return TLoss{}, false
+	}
+	return vaultLosses[0], true
+}
@@ -0,0 +1,46 @@
+package losses
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDecodeLoss(t *testing.T) {
+	strategy := common.HexToAddress(`0x1111111111111111111111111111111111111111`)
+	data := make([]byte, 7*32)
+	big.NewInt(5).FillBytes(data[0:32])     // gain
+	big.NewInt(1234).FillBytes(data[32:64]) // loss
+	log := types.Log{
+		Topics: []common.Hash{strategyReportedV3Topic, common.BytesToHash(strategy.Bytes())},
+		Data:   data,
+	}
+
+	loss, ok := decodeLoss(log)
+	if !ok || loss.Strategy != strategy || loss.Amount.String() != `1234` {
+		t.Fatalf("unexpected loss %+v", loss)
+	}
+
+	big.NewInt(0).FillBytes(data[32:64])
+	if _, ok := decodeLoss(log); ok {
+		t.Errorf("expected a report without loss to be skipped")
+	}
+}
+
+func TestPruneLosses(t *testing.T) {
+	now := time.Now()
+	vault := common.HexToAddress(`0x2222222222222222222222222222222222222222`)
+	pruned := pruneLosses(map[common.Address][]TLoss{
+		vault: {
+			{BlockNumber: 1, Timestamp: uint64(now.Add(-2 * RECENT_LOSS_DURATION).Unix())},
+			{BlockNumber: 2, Timestamp: uint64(now.Add(-time.Hour).Unix())},
+			{BlockNumber: 3, Timestamp: uint64(now.Unix())},
+		},
+	}, now)
+	if len(pruned[vault]) != 2 || pruned[vault][0].BlockNumber != 3 {
+		t.Errorf("expected the two recent losses, the last first, got %+v", pruned[vault])
+	}
+}
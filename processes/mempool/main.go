@@ -0,0 +1,273 @@
+package mempool
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** A pending flow is a deposit or a withdrawal of a vault seen in the mempool. It is dropped once
+** its transaction is mined, or after PENDING_FLOW_TTL if it never is (replaced or dropped by the
+** nodes). The flows are checked every PENDING_FLOW_CHECK_INTERVAL.
+**************************************************************************************************/
+const (
+	PENDING_FLOW_TTL            = 10 * time.Minute
+	PENDING_FLOW_CHECK_INTERVAL = 12 * time.Second
+	FLOW_KIND_DEPOSIT           = `deposit`
+	FLOW_KIND_WITHDRAWAL        = `withdrawal`
+)
+
+/**************************************************************************************************
+** TPendingFlow is a large pending deposit or withdrawal of a vault. Assets is the amount of the
+** asset of the vault moved, normalized by its decimals, converted from the shares with the last
+** price per share for the mints, the redeems and the v2 withdrawals.
+**************************************************************************************************/
+type TPendingFlow struct {
+	ChainID     uint64           `json:"chainID"`
+	Vault       common.Address   `json:"vault"`
+	TxHash      common.Hash      `json:"txHash"`
+	From        common.Address   `json:"from"`
+	Kind        string           `json:"kind"`
+	Method      string           `json:"method"`
+	Assets      *bigNumber.Float `json:"assets"`
+	AmountUSD   float64          `json:"amountUSD"`
+	FirstSeenAt time.Time        `json:"firstSeenAt"`
+}
+
+var (
+	pendingFlows    = make(map[uint64]map[common.Hash]TPendingFlow)
+	pendingFlowsMtx sync.RWMutex
+	vaultFlowsABI   *abi.ABI
+)
+
+func init() {
+	parsedABI, err := abi.JSON(strings.NewReader(helpers.VAULT_FLOWS_ABI))
+	if err != nil {
+		logs.Error(`Failed to parse the vault flows ABI: ` + err.Error())
+		return
+	}
+	vaultFlowsABI = &parsedABI
+}
+
+/**************************************************************************************************
+** IsEnabled returns true if the mempool of a chain is watched: the watch must be enabled with
+** MEMPOOL_WATCH and the chain able to use websockets.
+**************************************************************************************************/
+func IsEnabled(chainID uint64) bool {
+	chain, ok := env.GetChain(chainID)
+	return env.MEMPOOL_WATCH && ok && chain.CanUseWebsocket
+}
+
+/**************************************************************************************************
+** Watch subscribes to the pending transactions of a chain, with their content, and records the
+** deposits and withdrawals of the vaults worth at least MEMPOOL_MIN_FLOW_USD. The subscription is
+** opened again after a failure, the websocket client being reconnected. It never returns, and is
+** meant to run in its own goroutine.
+**************************************************************************************************/
+func Watch(chainID uint64) {
+	if !IsEnabled(chainID) {
+		return
+	}
+	go prunePendingFlows(chainID)
+
+	chainIDStr := strconv.FormatUint(chainID, 10)
+	retryDelay := time.Second
+	for {
+		client, err := ethereum.GetWSClient(chainID, true)
+		if err != nil {
+			time.Sleep(retryDelay)
+			retryDelay = min(retryDelay*2, time.Minute)
+			continue
+		}
+
+		transactions := make(chan *types.Transaction, 256)
+		subscription, err := client.Client().EthSubscribe(context.Background(), transactions, `newPendingTransactions`, true)
+		if err != nil {
+			logs.Error(`Failed to watch the mempool of chain ` + chainIDStr + `: ` + err.Error())
+			// The websocket may be closed: the client is closed and dropped for GetWSClient to open a new one
+			ethereum.ResetWSClient(chainID)
+			time.Sleep(retryDelay)
+			retryDelay = min(retryDelay*2, time.Minute)
+			continue
+		}
+		logs.Info(`Watching the mempool of chain ` + chainIDStr)
+		retryDelay = time.Second
+
+		func() {
+			defer subscription.Unsubscribe()
+			for {
+				select {
+				case err := <-subscription.Err():
+					if err != nil {
+						logs.Warning(`Mempool subscription of chain ` + chainIDStr + ` closed: ` + err.Error())
+					}
+					return
+				case tx := <-transactions:
+					if flow, ok := decodePendingFlow(chainID, tx); ok && flow.AmountUSD >= env.MEMPOOL_MIN_FLOW_USD {
+						recordPendingFlow(flow)
+					}
+				}
+			}
+		}()
+	}
+}
+
+/**************************************************************************************************
+** decodePendingFlow decodes a pending transaction calling the deposit or the withdrawal function
+** of a known vault. The boolean is false for any other transaction, or when the amount cannot be
+** known (the v2 deposit() and withdraw() of the whole balance).
+**************************************************************************************************/
+func decodePendingFlow(chainID uint64, tx *types.Transaction) (TPendingFlow, bool) {
+	if vaultFlowsABI == nil || tx == nil || tx.To() == nil || len(tx.Data()) < 4 {
+		return TPendingFlow{}, false
+	}
+	vault, ok := storage.GetVault(chainID, *tx.To())
+	if !ok {
+		return TPendingFlow{}, false
+	}
+	method, err := vaultFlowsABI.MethodById(tx.Data()[:4])
+	if err != nil || len(method.Inputs) == 0 {
+		return TPendingFlow{}, false
+	}
+	arguments, err := method.Inputs.Unpack(tx.Data()[4:])
+	if err != nil || len(arguments) == 0 {
+		return TPendingFlow{}, false
+	}
+	rawAmount, ok := arguments[0].(*big.Int)
+	if !ok {
+		return TPendingFlow{}, false
+	}
+
+	kind := FLOW_KIND_DEPOSIT
+	if method.RawName == `withdraw` || method.RawName == `redeem` {
+		kind = FLOW_KIND_WITHDRAWAL
+	}
+	isInShares := method.RawName == `mint` || method.RawName == `redeem` || (method.RawName == `withdraw` && !isV3Vault(vault))
+	assets, ok := toAssets(vault, bigNumber.SetInt(rawAmount), isInShares)
+	if !ok {
+		return TPendingFlow{}, false
+	}
+
+	amountUSD := 0.0
+	if price, ok := storage.GetPrice(chainID, vault.AssetAddress); ok && price.HumanizedPrice != nil {
+		amountUSD, _ = bigNumber.NewFloat(0).Mul(assets, price.HumanizedPrice).Float64()
+	}
+	from, _ := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	return TPendingFlow{
+		ChainID:     chainID,
+		Vault:       vault.Address,
+		TxHash:      tx.Hash(),
+		From:        from,
+		Kind:        kind,
+		Method:      method.Sig,
+		Assets:      assets,
+		AmountUSD:   amountUSD,
+		FirstSeenAt: time.Now(),
+	}, true
+}
+
+/**************************************************************************************************
+** toAssets normalizes an amount of a vault by the decimals of its asset, converting it from shares
+** with the last price per share of the vault when needed.
+**************************************************************************************************/
+func toAssets(vault models.TVault, amount *bigNumber.Int, isInShares bool) (*bigNumber.Float, bool) {
+	asset, ok := storage.GetERC20(vault.ChainID, vault.AssetAddress)
+	if !ok {
+		return nil, false
+	}
+	if !isInShares {
+		return helpers.ToNormalizedAmount(amount, asset.Decimals), true
+	}
+	share, ok := storage.GetERC20(vault.ChainID, vault.Address)
+	if !ok || vault.LastPricePerShare == nil {
+		return nil, false
+	}
+	shares := helpers.ToNormalizedAmount(amount, share.Decimals)
+	pricePerShare := helpers.ToNormalizedAmount(vault.LastPricePerShare, asset.Decimals)
+	return bigNumber.NewFloat(0).Mul(shares, pricePerShare), true
+}
+
+func isV3Vault(vault models.TVault) bool {
+	versionMajor := strings.Split(vault.Version, `.`)[0]
+	return vault.Kind == models.VaultKindMultiple || vault.Kind == models.VaultKindSingle || versionMajor == `3` || versionMajor == `~3`
+}
+
+func recordPendingFlow(flow TPendingFlow) {
+	pendingFlowsMtx.Lock()
+	defer pendingFlowsMtx.Unlock()
+	if _, ok := pendingFlows[flow.ChainID]; !ok {
+		pendingFlows[flow.ChainID] = make(map[common.Hash]TPendingFlow)
+	}
+	if _, ok := pendingFlows[flow.ChainID][flow.TxHash]; ok {
+		return
+	}
+	pendingFlows[flow.ChainID][flow.TxHash] = flow
+	logs.Info(`Pending ` + flow.Kind + ` of $` + strconv.FormatFloat(flow.AmountUSD, 'f', 0, 64) + ` in ` +
+		flow.Vault.Hex() + ` on chain ` + strconv.FormatUint(flow.ChainID, 10) + `: ` + flow.TxHash.Hex())
+}
+
+/**************************************************************************************************
+** prunePendingFlows drops, every PENDING_FLOW_CHECK_INTERVAL, the pending flows of a chain whose
+** transaction was mined or that are older than PENDING_FLOW_TTL.
+**************************************************************************************************/
+func prunePendingFlows(chainID uint64) {
+	ticker := time.NewTicker(PENDING_FLOW_CHECK_INTERVAL)
+	defer ticker.Stop()
+	for range ticker.C {
+		pendingFlowsMtx.RLock()
+		flows := []TPendingFlow{}
+		for _, flow := range pendingFlows[chainID] {
+			flows = append(flows, flow)
+		}
+		pendingFlowsMtx.RUnlock()
+
+		settled := []common.Hash{}
+		for _, flow := range flows {
+			if time.Since(flow.FirstSeenAt) > PENDING_FLOW_TTL {
+				settled = append(settled, flow.TxHash)
+			} else if _, err := ethereum.GetRPC(chainID).TransactionReceipt(context.Background(), flow.TxHash); err == nil {
+				settled = append(settled, flow.TxHash)
+			}
+		}
+
+		pendingFlowsMtx.Lock()
+		for _, hash := range settled {
+			delete(pendingFlows[chainID], hash)
+		}
+		pendingFlowsMtx.Unlock()
+	}
+}
+
+/**************************************************************************************************
+** ListPendingFlows returns the large pending deposits and withdrawals of a vault, the largest first.
+**************************************************************************************************/
+func ListPendingFlows(chainID uint64, vaultAddress common.Address) []TPendingFlow {
+	pendingFlowsMtx.RLock()
+	defer pendingFlowsMtx.RUnlock()
+	flows := []TPendingFlow{}
+	for _, flow := range pendingFlows[chainID] {
+		if flow.Vault == vaultAddress {
+			flows = append(flows, flow)
+		}
+	}
+	sort.Slice(flows, func(i, j int) bool {
+		return flows[i].AmountUSD > flows[j].AmountUSD
+	})
+	return flows
+}
@@ -0,0 +1,232 @@
+package migrations
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/addresses"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/apr"
+)
+
+/**************************************************************************************************
+** The sources of a migration, by decreasing priority:
+** - override: set by an operator in BASE_DATA_PATH/meta/vaults/<chainID>.migrations.json,
+** - cms: set in the metadata of the vault,
+** - registry: inferred from the registry, the retired or shutdown vault being migrated to the
+**   endorsed vault of the same token with the highest version.
+**************************************************************************************************/
+const (
+	MIGRATION_SOURCE_OVERRIDE = `override`
+	MIGRATION_SOURCE_CMS      = `cms`
+	MIGRATION_SOURCE_REGISTRY = `registry`
+)
+
+/**************************************************************************************************
+** TVaultMigration is the suggested replacement of a deprecated vault. APYDelta is the forward net
+** APY of the target minus the one of the vault (0.023 for +2.3%), nil when one of them is unknown.
+**************************************************************************************************/
+type TVaultMigration struct {
+	ChainID  uint64         `json:"chainID"`
+	Vault    common.Address `json:"vault"`
+	Target   common.Address `json:"target"`
+	Contract common.Address `json:"contract"`
+	Source   string         `json:"source"`
+	APYDelta *float64       `json:"apyDelta"`
+}
+
+/**************************************************************************************************
+** TMigrationOverrides is the content of the override file of a chain. The contract of a migration
+** defaults to the Contract of the file when not set:
+** { "contract": "0x...", "migrations": { "<vault>": { "target": "0x...", "contract": "0x..." } } }
+**************************************************************************************************/
+type TMigrationOverrides struct {
+	Contract   common.Address                `json:"contract"`
+	Migrations map[string]TMigrationOverride `json:"migrations"`
+}
+
+type TMigrationOverride struct {
+	Target   common.Address `json:"target"`
+	Contract common.Address `json:"contract"`
+}
+
+var resolvedMigrations = make(map[uint64]map[common.Address]TVaultMigration)
+var migrationsMtx sync.RWMutex
+
+/**************************************************************************************************
+** loadMigrationOverrides reads the override file of a chain. The file is optional.
+**************************************************************************************************/
+func loadMigrationOverrides(chainID uint64) TMigrationOverrides {
+	overrides := TMigrationOverrides{Migrations: make(map[string]TMigrationOverride)}
+	filePath := env.BASE_DATA_PATH + `/meta/vaults/` + strconv.FormatUint(chainID, 10) + `.migrations.json`
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return overrides
+	}
+	if err := json.Unmarshal(content, &overrides); err != nil {
+		logs.Error(`Failed to decode the migration overrides of chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+		return TMigrationOverrides{Migrations: make(map[string]TMigrationOverride)}
+	}
+	byVault := make(map[string]TMigrationOverride)
+	for vault, override := range overrides.Migrations {
+		byVault[strings.ToLower(vault)] = override
+	}
+	overrides.Migrations = byVault
+	return overrides
+}
+
+/**************************************************************************************************
+** compareVersions compares two dotted versions (e.g. `0.4.6` and `3.0.2`) numerically. It returns
+** a negative number if a < b, 0 if they are equal and a positive number if a > b.
+**************************************************************************************************/
+func compareVersions(a string, b string) int {
+	partsA := strings.Split(a, `.`)
+	partsB := strings.Split(b, `.`)
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		numberA, numberB := 0, 0
+		if i < len(partsA) {
+			numberA, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			numberB, _ = strconv.Atoi(partsB[i])
+		}
+		if numberA != numberB {
+			return numberA - numberB
+		}
+	}
+	return 0
+}
+
+func isDeprecated(vault models.TVault) bool {
+	return models.IsVaultRetired(vault) || vault.EmergencyShutdown
+}
+
+/**************************************************************************************************
+** FindRegistryTarget returns the vault a deprecated vault should be migrated to: the endorsed and
+** active vault of the same token with the highest version, the most recent one on a tie. It returns
+** false when the vault is not deprecated or has no such replacement.
+**************************************************************************************************/
+func FindRegistryTarget(vault models.TVault, vaults []models.TVault) (models.TVault, bool) {
+	if !isDeprecated(vault) {
+		return models.TVault{}, false
+	}
+	var target models.TVault
+	found := false
+	for _, candidate := range vaults {
+		if addresses.Equals(candidate.Address, vault.Address) || !addresses.Equals(candidate.AssetAddress, vault.AssetAddress) {
+			continue
+		}
+		if !candidate.Endorsed || isDeprecated(candidate) || candidate.Metadata.IsHidden || candidate.Metadata.Migration.Available {
+			continue
+		}
+		if !found {
+			target, found = candidate, true
+			continue
+		}
+		if comparison := compareVersions(candidate.Version, target.Version); comparison > 0 || (comparison == 0 && candidate.Activation > target.Activation) {
+			target = candidate
+		}
+	}
+	return target, found
+}
+
+func getForwardNetAPY(chainID uint64, vaultAddress common.Address) *bigNumber.Float {
+	stored, ok := apr.GetComputedAPY(chainID, vaultAddress)
+	if !ok {
+		return nil
+	}
+	vaultAPY := stored.(apr.TVaultAPY)
+	if vaultAPY.ForwardAPY.NetAPY != nil {
+		return vaultAPY.ForwardAPY.NetAPY
+	}
+	return vaultAPY.NetAPY
+}
+
+func computeAPYDelta(chainID uint64, vault common.Address, target common.Address) *float64 {
+	vaultAPY := getForwardNetAPY(chainID, vault)
+	targetAPY := getForwardNetAPY(chainID, target)
+	if vaultAPY == nil || targetAPY == nil {
+		return nil
+	}
+	delta, _ := bigNumber.NewFloat(0).Sub(targetAPY, vaultAPY).Float64()
+	return &delta
+}
+
+/**************************************************************************************************
+** ResolveMigrations computes the migration of every deprecated vault of a chain from the override
+** file, the CMS metadata and the registry, with the APY delta of the migration. A migration with
+** no target or no migration contract is not available and is skipped. It must run after the APYs
+** are computed.
+**************************************************************************************************/
+func ResolveMigrations(chainID uint64) {
+	overrides := loadMigrationOverrides(chainID)
+	_, vaults := storage.ListVaults(chainID)
+
+	migrations := make(map[common.Address]TVaultMigration)
+	for _, vault := range vaults {
+		migration := TVaultMigration{ChainID: chainID, Vault: vault.Address}
+		if override, ok := overrides.Migrations[strings.ToLower(vault.Address.Hex())]; ok {
+			migration.Target = override.Target
+			migration.Contract = override.Contract
+			migration.Source = MIGRATION_SOURCE_OVERRIDE
+		} else if vault.Metadata.Migration.Available && !addresses.Equals(vault.Metadata.Migration.Target, vault.Address) {
+			migration.Target = vault.Metadata.Migration.Target
+			migration.Contract = vault.Metadata.Migration.Contract
+			migration.Source = MIGRATION_SOURCE_CMS
+		} else if target, ok := FindRegistryTarget(vault, vaults); ok {
+			migration.Target = target.Address
+			migration.Contract = vault.Metadata.Migration.Contract
+			migration.Source = MIGRATION_SOURCE_REGISTRY
+		} else {
+			continue
+		}
+		if (migration.Contract == common.Address{}) {
+			migration.Contract = overrides.Contract
+		}
+		if (migration.Target == common.Address{}) || (migration.Contract == common.Address{}) {
+			continue // Not available without a target and a contract to migrate through
+		}
+		migration.APYDelta = computeAPYDelta(chainID, migration.Vault, migration.Target)
+		migrations[vault.Address] = migration
+	}
+
+	migrationsMtx.Lock()
+	resolvedMigrations[chainID] = migrations
+	migrationsMtx.Unlock()
+	logs.Info(`Resolved ` + strconv.Itoa(len(migrations)) + ` vault migrations on chain ` + strconv.FormatUint(chainID, 10))
+}
+
+/**************************************************************************************************
+** GetMigration returns the migration of a vault, if it is deprecated and has a replacement.
+**************************************************************************************************/
+func GetMigration(chainID uint64, vaultAddress common.Address) (TVaultMigration, bool) {
+	migrationsMtx.RLock()
+	defer migrationsMtx.RUnlock()
+	migration, ok := resolvedMigrations[chainID][vaultAddress]
+	return migration, ok
+}
+
+/**************************************************************************************************
+** ListMigrations returns the migrations of a chain, sorted by vault address.
+**************************************************************************************************/
+func ListMigrations(chainID uint64) []TVaultMigration {
+	migrationsMtx.RLock()
+	defer migrationsMtx.RUnlock()
+	migrations := []TVaultMigration{}
+	for _, migration := range resolvedMigrations[chainID] {
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Vault.Hex() < migrations[j].Vault.Hex()
+	})
+	return migrations
+}
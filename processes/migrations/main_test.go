@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** TestCompareVersions checks that the versions are compared numerically and not as strings.
+**************************************************************************************************/
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{`0.4.6`, `3.0.2`, -1},
+		{`3.0.10`, `3.0.2`, 1},
+		{`3.0`, `3.0.0`, 0},
+		{`0.4.3`, `0.4.3`, 0},
+	}
+	for _, test := range tests {
+		comparison := compareVersions(test.a, test.b)
+		if (comparison < 0 && test.expected >= 0) || (comparison > 0 && test.expected <= 0) || (comparison == 0 && test.expected != 0) {
+			t.Errorf("compareVersions(%s, %s) = %d, expected the sign of %d", test.a, test.b, comparison, test.expected)
+		}
+	}
+}
+
+/**************************************************************************************************
+** TestFindRegistryTarget checks that a retired vault is migrated to the endorsed and active vault
+** of the same token with the highest version, and that an active vault is not migrated.
+**************************************************************************************************/
+func TestFindRegistryTarget(t *testing.T) {
+	token := common.HexToAddress(`0x6B175474E89094C44Da98b954EedeAC495271d0F`)
+	otherToken := common.HexToAddress(`0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48`)
+	retired := models.TVault{Address: common.HexToAddress(`0x01`), AssetAddress: token, Version: `0.4.3`, Endorsed: true}
+	retired.Metadata.IsRetired = true
+	v2 := models.TVault{Address: common.HexToAddress(`0x02`), AssetAddress: token, Version: `0.4.6`, Endorsed: true}
+	v3 := models.TVault{Address: common.HexToAddress(`0x03`), AssetAddress: token, Version: `3.0.2`, Endorsed: true}
+	v3Newer := models.TVault{Address: common.HexToAddress(`0x04`), AssetAddress: token, Version: `3.0.2`, Endorsed: true, Activation: 100}
+	experimental := models.TVault{Address: common.HexToAddress(`0x05`), AssetAddress: token, Version: `3.0.4`}
+	shutdown := models.TVault{Address: common.HexToAddress(`0x06`), AssetAddress: token, Version: `3.0.4`, Endorsed: true, EmergencyShutdown: true}
+	otherAsset := models.TVault{Address: common.HexToAddress(`0x07`), AssetAddress: otherToken, Version: `3.0.4`, Endorsed: true}
+
+	vaults := []models.TVault{retired, v2, v3, v3Newer, experimental, shutdown, otherAsset}
+	target, ok := FindRegistryTarget(retired, vaults)
+	if !ok || target.Address != v3Newer.Address {
+		t.Errorf("expected %s as target, got %s (found: %v)", v3Newer.Address.Hex(), target.Address.Hex(), ok)
+	}
+
+	if _, ok := FindRegistryTarget(v2, vaults); ok {
+		t.Errorf("an active vault should not be migrated")
+	}
+
+	if _, ok := FindRegistryTarget(retired, []models.TVault{retired, experimental, otherAsset}); ok {
+		t.Errorf("a vault without an endorsed replacement should not be migrated")
+	}
+}
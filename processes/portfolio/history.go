@@ -0,0 +1,375 @@
+package portfolio
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/patrickmn/go-cache"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/entries"
+	"github.com/yearn/ydaemon/processes/holders"
+	"github.com/yearn/ydaemon/processes/prices"
+)
+
+/**************************************************************************************************
+** The value history of a user is rebuilt at each point from the chain: the shares of the user in
+** the vaults of their indexed positions (the vaults they deposited in, see processes/entries, or
+** hold shares of, see processes/holders), held or staked in the staking contracts of the vaults,
+** and their price per share are read at the block of the point, on the archive node of the chain
+** when configured, and the underlying tokens are priced at the time of the point with DeFiLlama.
+** The points are at noon UTC, the time of the daily blocks known by the blocktime storage, which
+** covers the last year, and at most MAX_HISTORY_POINTS are computed.
+** The price per share of a vault and the price of a token at a point never change: they are cached
+** for all the users, and the history of a user is cached for USER_HISTORY_CACHE_TTL by granularity.
+**************************************************************************************************/
+type TGranularity string
+
+const (
+	GranularityDaily  TGranularity = `daily`
+	GranularityWeekly TGranularity = `weekly`
+)
+
+var GRANULARITY_INTERVALS = map[TGranularity]time.Duration{
+	GranularityDaily:  24 * time.Hour,
+	GranularityWeekly: 7 * 24 * time.Hour,
+}
+
+const (
+	MAX_HISTORY_POINTS     = 365
+	USER_HISTORY_CACHE_TTL = time.Hour
+)
+
+var ErrHistoryNotSupported = errors.New(`the history is not supported on this chain`)
+
+/**************************************************************************************************
+** TPosition is the position of a user in a vault at a point: the shares held and staked, their
+** price per share, the price of the underlying token and the value of all the shares, in USD.
+**************************************************************************************************/
+type TPosition struct {
+	Vault         common.Address `json:"vault"`
+	Balance       *bigNumber.Int `json:"balance"`
+	StakedBalance *bigNumber.Int `json:"stakedBalance"`
+	PricePerShare *bigNumber.Int `json:"pricePerShare"`
+	AssetPriceUSD float64        `json:"assetPriceUSD"`
+	ValueUSD      float64        `json:"valueUSD"`
+}
+
+/**************************************************************************************************
+** TPoint is the value of the vault holdings of a user at a point. The positions whose underlying
+** token was not priced then are listed without value, and their vault in UnpricedVaults.
+**************************************************************************************************/
+type TPoint struct {
+	Timestamp      uint64           `json:"timestamp"`
+	BlockNumber    uint64           `json:"blockNumber"`
+	ValueUSD       float64          `json:"valueUSD"`
+	Positions      []TPosition      `json:"positions"`
+	UnpricedVaults []common.Address `json:"unpricedVaults,omitempty"`
+}
+
+var (
+	pricePerShareCache = make(map[uint64]map[uint64]map[common.Address]*bigNumber.Int) // chainID -> block -> vault
+	assetPriceCache    = make(map[uint64]map[uint64]map[common.Address]float64)        // chainID -> timestamp -> token
+	cacheMtx           sync.RWMutex
+	userHistoryCache   = cache.New(USER_HISTORY_CACHE_TTL, 2*USER_HISTORY_CACHE_TTL)
+)
+
+/**************************************************************************************************
+** tCachedHistory is the last history computed for a user and a granularity, with the number of
+** points asked and the time each point was computed for, for the requests asking as many points or
+** less to be served from it.
+**************************************************************************************************/
+type tCachedHistory struct {
+	count      int
+	points     []TPoint
+	timestamps []uint64
+}
+
+/**************************************************************************************************
+** listPointsTimestamps returns the timestamps of the points of a history, at noon UTC, from the
+** oldest to the last one before now.
+**************************************************************************************************/
+func listPointsTimestamps(now time.Time, interval time.Duration, count int) []uint64 {
+	now = now.UTC()
+	last := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, time.UTC)
+	if last.After(now) {
+		last = last.AddDate(0, 0, -1)
+	}
+	timestamps := make([]uint64, count)
+	for i := 0; i < count; i++ {
+		timestamps[count-1-i] = uint64(last.Add(-time.Duration(i) * interval).Unix())
+	}
+	return timestamps
+}
+
+/**************************************************************************************************
+** computePositionValue returns the value in USD of some shares of a vault: their amount of
+** underlying tokens, from the price per share, times the price of the token.
+**************************************************************************************************/
+func computePositionValue(balance *bigNumber.Int, pricePerShare *bigNumber.Int, decimals uint64, assetPrice float64) float64 {
+	assets := bigNumber.NewInt(0).Mul(balance, pricePerShare)
+	assets = bigNumber.NewInt(0).Div(assets, bigNumber.NewInt(0).Exp(bigNumber.NewInt(10), bigNumber.NewUint64(decimals), nil))
+	amount, _ := helpers.ToNormalizedAmount(assets, decimals).Float64()
+	return amount * assetPrice
+}
+
+/**************************************************************************************************
+** getPricesPerShare returns the price per share of some vaults at a block, reading the ones not
+** cached yet.
+**************************************************************************************************/
+func getPricesPerShare(chainID uint64, vaults []models.TVault, blockNumber uint64) map[common.Address]*bigNumber.Int {
+	pricesPerShare := make(map[common.Address]*bigNumber.Int)
+	calls := []ethereum.Call{}
+	cacheMtx.RLock()
+	for _, vault := range vaults {
+		if pricePerShare, ok := pricePerShareCache[chainID][blockNumber][vault.Address]; ok {
+			pricesPerShare[vault.Address] = pricePerShare
+			continue
+		}
+		calls = append(calls, multicalls.GetPricePerShare(vault.Address.Hex(), vault.Address))
+	}
+	cacheMtx.RUnlock()
+	if len(calls) == 0 {
+		return pricesPerShare
+	}
+
+	response := multicalls.PerformAtPastBlock(chainID, calls, new(big.Int).SetUint64(blockNumber))
+	cacheMtx.Lock()
+	defer cacheMtx.Unlock()
+	if _, ok := pricePerShareCache[chainID]; !ok {
+		pricePerShareCache[chainID] = make(map[uint64]map[common.Address]*bigNumber.Int)
+	}
+	if _, ok := pricePerShareCache[chainID][blockNumber]; !ok {
+		pricePerShareCache[chainID][blockNumber] = make(map[common.Address]*bigNumber.Int)
+	}
+	for _, call := range calls {
+		rawPricePerShare := response[call.Target.Hex()+`pricePerShare`]
+		if len(rawPricePerShare) == 0 {
+			continue
+		}
+		pricePerShare := helpers.DecodeBigInt(rawPricePerShare)
+		pricePerShareCache[chainID][blockNumber][call.Target] = pricePerShare
+		pricesPerShare[call.Target] = pricePerShare
+	}
+	return pricesPerShare
+}
+
+/**************************************************************************************************
+** getAssetPrices returns the price in USD of some tokens at a timestamp, fetching the ones not
+** cached yet. The tokens not priced then are missing, and asked again on the next request.
+**************************************************************************************************/
+func getAssetPrices(chainID uint64, tokens []common.Address, timestamp uint64) map[common.Address]float64 {
+	assetPrices := make(map[common.Address]float64)
+	toFetch := []common.Address{}
+	cacheMtx.RLock()
+	for _, token := range tokens {
+		if price, ok := assetPriceCache[chainID][timestamp][token]; ok {
+			assetPrices[token] = price
+			continue
+		}
+		toFetch = append(toFetch, token)
+	}
+	cacheMtx.RUnlock()
+	if len(toFetch) == 0 {
+		return assetPrices
+	}
+
+	fetched := prices.FetchHistoricalPricesFromLlama(chainID, toFetch, timestamp)
+	cacheMtx.Lock()
+	defer cacheMtx.Unlock()
+	if _, ok := assetPriceCache[chainID]; !ok {
+		assetPriceCache[chainID] = make(map[uint64]map[common.Address]float64)
+	}
+	if _, ok := assetPriceCache[chainID][timestamp]; !ok {
+		assetPriceCache[chainID][timestamp] = make(map[common.Address]float64)
+	}
+	for token, price := range fetched {
+		assetPriceCache[chainID][timestamp][token] = price
+		assetPrices[token] = price
+	}
+	return assetPrices
+}
+
+/**************************************************************************************************
+** listUserVaults returns the vaults of the indexed positions of a user on a chain: the vaults they
+** deposited in and the ones they hold shares of, sorted by address.
+**************************************************************************************************/
+func listUserVaults(chainID uint64, userAddress common.Address) []models.TVault {
+	vaultAddresses := holders.ListHolderVaults(chainID, userAddress)
+	for vaultAddress := range entries.GetDepositorEntries(chainID, userAddress) {
+		vaultAddresses = append(vaultAddresses, vaultAddress)
+	}
+
+	vaults := []models.TVault{}
+	isListed := make(map[common.Address]bool)
+	for _, vaultAddress := range vaultAddresses {
+		if isListed[vaultAddress] {
+			continue
+		}
+		isListed[vaultAddress] = true
+		if vault, ok := storage.GetVault(chainID, vaultAddress); ok {
+			vaults = append(vaults, vault)
+		}
+	}
+	sort.Slice(vaults, func(i, j int) bool {
+		return bytes.Compare(vaults[i].Address.Bytes(), vaults[j].Address.Bytes()) < 0
+	})
+	return vaults
+}
+
+/**************************************************************************************************
+** listStakingContracts returns the staking contracts of a vault, whose balances are its staked
+** shares: the OP boost, the veYFI gauge, the juiced and the v3 staking contracts.
+**************************************************************************************************/
+func listStakingContracts(chainID uint64, vaultAddress common.Address) []common.Address {
+	stakingContracts := []common.Address{}
+	if staking, ok := storage.GetOPStakingForVault(chainID, vaultAddress); ok {
+		stakingContracts = append(stakingContracts, staking.StakingAddress)
+	}
+	if staking, ok := storage.GetVeYFIStakingForVault(chainID, vaultAddress); ok {
+		stakingContracts = append(stakingContracts, staking.StakingAddress)
+	}
+	if staking, ok := storage.GetJuicedStakingDataForVault(chainID, vaultAddress); ok {
+		stakingContracts = append(stakingContracts, staking.StakingAddress)
+	}
+	if staking, ok := storage.GetV3StakingDataForVault(chainID, vaultAddress); ok {
+		stakingContracts = append(stakingContracts, staking.StakingAddress)
+	}
+	return stakingContracts
+}
+
+/**************************************************************************************************
+** computePoint returns the value of the vault holdings of a user at a block, false if the node did
+** not serve the state of that block.
+**************************************************************************************************/
+func computePoint(chainID uint64, userAddress common.Address, vaults []models.TVault, blockNumber uint64, timestamp uint64) (TPoint, bool) {
+	calls := []ethereum.Call{}
+	stakingContracts := make(map[common.Address][]common.Address)
+	for _, vault := range vaults {
+		calls = append(calls, multicalls.GetBalanceOf(vault.Address.Hex(), vault.Address, userAddress))
+		stakingContracts[vault.Address] = listStakingContracts(chainID, vault.Address)
+		for _, stakingContract := range stakingContracts[vault.Address] {
+			calls = append(calls, multicalls.GetBalanceOf(stakingContract.Hex(), stakingContract, userAddress))
+		}
+	}
+	response := multicalls.PerformAtPastBlock(chainID, calls, new(big.Int).SetUint64(blockNumber))
+	if len(response) == 0 {
+		return TPoint{}, false
+	}
+
+	heldVaults := []models.TVault{}
+	balances := make(map[common.Address]*bigNumber.Int)
+	stakedBalances := make(map[common.Address]*bigNumber.Int)
+	for _, vault := range vaults {
+		balance := bigNumber.NewInt(0)
+		if rawBalance := response[vault.Address.Hex()+`balanceOf`]; len(rawBalance) > 0 {
+			balance = helpers.DecodeBigInt(rawBalance)
+		}
+		stakedBalance := bigNumber.NewInt(0)
+		for _, stakingContract := range stakingContracts[vault.Address] {
+			if rawBalance := response[stakingContract.Hex()+`balanceOf`]; len(rawBalance) > 0 {
+				stakedBalance = bigNumber.NewInt(0).Add(stakedBalance, helpers.DecodeBigInt(rawBalance))
+			}
+		}
+		if !balance.IsZero() || !stakedBalance.IsZero() {
+			heldVaults = append(heldVaults, vault)
+			balances[vault.Address] = balance
+			stakedBalances[vault.Address] = stakedBalance
+		}
+	}
+
+	point := TPoint{Timestamp: timestamp, BlockNumber: blockNumber, Positions: []TPosition{}}
+	if len(heldVaults) == 0 {
+		return point, true
+	}
+	pricesPerShare := getPricesPerShare(chainID, heldVaults, blockNumber)
+	assets := []common.Address{}
+	for _, vault := range heldVaults {
+		assets = append(assets, vault.AssetAddress)
+	}
+	assetPrices := getAssetPrices(chainID, assets, timestamp)
+
+	for _, vault := range heldVaults {
+		position := TPosition{
+			Vault:         vault.Address,
+			Balance:       balances[vault.Address],
+			StakedBalance: stakedBalances[vault.Address],
+			PricePerShare: pricesPerShare[vault.Address],
+		}
+		assetPrice, isPriced := assetPrices[vault.AssetAddress]
+		if position.PricePerShare == nil || !isPriced {
+			point.UnpricedVaults = append(point.UnpricedVaults, vault.Address)
+			point.Positions = append(point.Positions, position)
+			continue
+		}
+		decimals := uint64(18)
+		if token, ok := storage.GetERC20(chainID, vault.Address); ok {
+			decimals = token.Decimals
+		}
+		position.AssetPriceUSD = assetPrice
+		shares := bigNumber.NewInt(0).Add(position.Balance, position.StakedBalance)
+		position.ValueUSD = computePositionValue(shares, position.PricePerShare, decimals, assetPrice)
+		point.ValueUSD += position.ValueUSD
+		point.Positions = append(point.Positions, position)
+	}
+	return point, true
+}
+
+/**************************************************************************************************
+** ComputeUserHistory returns the value of the vault holdings of a user on a chain over the last
+** count points of the granularity, from the oldest, count being at most MAX_HISTORY_POINTS. The
+** points without a known block, or whose state was not served by the node, are skipped.
+**************************************************************************************************/
+func ComputeUserHistory(chainID uint64, userAddress common.Address, granularity TGranularity, count int) ([]TPoint, error) {
+	interval, ok := GRANULARITY_INTERVALS[granularity]
+	if !ok {
+		return nil, errors.New(`unknown granularity ` + string(granularity))
+	}
+	if count <= 0 || count > MAX_HISTORY_POINTS {
+		return nil, errors.New(`the number of points must be between 1 and ` + strconv.Itoa(MAX_HISTORY_POINTS))
+	}
+	if _, ok := env.GetChain(chainID); !ok {
+		return nil, ErrHistoryNotSupported
+	}
+
+	timestamps := listPointsTimestamps(time.Now(), interval, count)
+	cacheKey := strconv.FormatUint(chainID, 10) + `/` + userAddress.Hex() + `/` + string(granularity)
+	if cached, ok := userHistoryCache.Get(cacheKey); ok && cached.(tCachedHistory).count >= count {
+		history := []TPoint{}
+		for i, point := range cached.(tCachedHistory).points {
+			if cached.(tCachedHistory).timestamps[i] >= timestamps[0] {
+				history = append(history, point)
+			}
+		}
+		return history, nil
+	}
+
+	history := []TPoint{}
+	pointsTimestamps := []uint64{}
+	vaults := listUserVaults(chainID, userAddress)
+	if len(vaults) > 0 {
+		for _, timestamp := range timestamps {
+			blockNumber, blockTimestamp, ok := ethereum.GetNearestTimeBlock(chainID, timestamp, 1)
+			if !ok {
+				continue
+			}
+			if point, ok := computePoint(chainID, userAddress, vaults, blockNumber, blockTimestamp); ok {
+				history = append(history, point)
+				pointsTimestamps = append(pointsTimestamps, timestamp)
+			}
+		}
+	}
+	userHistoryCache.Set(cacheKey, tCachedHistory{count: count, points: history, timestamps: pointsTimestamps}, cache.DefaultExpiration)
+	return history, nil
+}
@@ -0,0 +1,60 @@
+package portfolio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+)
+
+func TestListPointsTimestamps(t *testing.T) {
+	morning := time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)
+	timestamps := listPointsTimestamps(morning, GRANULARITY_INTERVALS[GranularityDaily], 3)
+	expected := []time.Time{
+		time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 8, 12, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC),
+	}
+	for i, timestamp := range timestamps {
+		if timestamp != uint64(expected[i].Unix()) {
+			t.Errorf("expected the point %d at %s, got %s", i, expected[i], time.Unix(int64(timestamp), 0).UTC())
+		}
+	}
+
+	evening := time.Date(2024, 3, 10, 20, 0, 0, 0, time.UTC)
+	timestamps = listPointsTimestamps(evening, GRANULARITY_INTERVALS[GranularityWeekly], 2)
+	if timestamps[1] != uint64(time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC).Unix()) ||
+		timestamps[0] != uint64(time.Date(2024, 3, 3, 12, 0, 0, 0, time.UTC).Unix()) {
+		t.Errorf("expected weekly points ending at noon today, got %v", timestamps)
+	}
+}
+
+func TestComputePositionValue(t *testing.T) {
+	balance := bigNumber.NewInt(2_000_000)       // 2 shares of a 6 decimals vault
+	pricePerShare := bigNumber.NewInt(1_100_000) // 1.1 token per share
+	value := computePositionValue(balance, pricePerShare, 6, 0.5)
+	if value < 1.0999 || value > 1.1001 {
+		t.Errorf("expected a value of 1.1 USD, got %f", value)
+	}
+}
+
+func TestComputeUserHistoryLimits(t *testing.T) {
+	user := common.HexToAddress(`0xa1`)
+	for _, count := range []int{0, MAX_HISTORY_POINTS + 1} {
+		if _, err := ComputeUserHistory(1, user, GranularityDaily, count); err == nil {
+			t.Errorf("expected %d points to be refused", count)
+		}
+	}
+
+	timestamps := listPointsTimestamps(time.Now(), GRANULARITY_INTERVALS[GranularityDaily], 3)
+	cached := tCachedHistory{count: 3, timestamps: timestamps}
+	for _, timestamp := range timestamps {
+		cached.points = append(cached.points, TPoint{Timestamp: timestamp - 12})
+	}
+	userHistoryCache.Set(`1/`+user.Hex()+`/daily`, cached, 0)
+	history, err := ComputeUserHistory(1, user, GranularityDaily, 2)
+	if err != nil || len(history) != 2 || history[0].Timestamp != timestamps[1]-12 {
+		t.Errorf("expected the last 2 points of the cached history, got %v (%v)", history, err)
+	}
+}
@@ -24,6 +24,7 @@ var GECKO_CHAIN_NAMES = map[uint64]string{
 	137:    `polygon-pos`,
 	146:    `sonic`,
 	250:    `fantom`,
+	1101:   `polygon-zkevm`,
 	8453:   `base`,
 	42161:  `arbitrum-one`,
 	747474: `katana`,
@@ -7,6 +7,7 @@ import (
 	"math"
 	"math/rand"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -26,6 +27,7 @@ var LLAMA_CHAIN_NAMES = map[uint64]string{
 	100:    `xdai`,
 	137:    `polygon`,
 	250:    `fantom`,
+	1101:   `polygon_zkevm`,
 	8453:   `base`,
 	42161:  `arbitrum`,
 	747474: `katana`,
@@ -60,12 +62,25 @@ func getMainnetAddressForTokenName(tokenName string) (common.Address, bool) {
 	return common.Address{}, false
 }
 
+/**************************************************************************************************
+** getBridgedAJNAToken returns the AJNA token of a chain other than Ethereum, missing on DefiLlama
+** and priced as the mainnet one, false if there is none.
+**************************************************************************************************/
+func getBridgedAJNAToken(chainID uint64) (common.Address, bool) {
+	if chainID == 1 {
+		return common.Address{}, false
+	}
+	ajnaToken, ok := AJNA_TOKENS[chainID]
+	return ajnaToken, ok
+}
+
 /**************************************************************************************************
 ** fetchPriceFromLlama tries to fetch the price for a given token from
 ** the DeFiLlama pricing API, returns nil if there is no data returned
 **************************************************************************************************/
 func fetchPricesFromLlama(chainID uint64, tokens []models.TERC20Token) map[common.Address]models.TPrices {
 	priceMap := make(map[common.Address]models.TPrices)
+	chain, _ := env.GetChain(chainID)
 	chunkSize := 100
 	timeToSleep := rand.Intn(600-100) + 100
 	for i := 0; i < len(tokens); i += chunkSize {
@@ -83,8 +98,8 @@ func fetchPricesFromLlama(chainID uint64, tokens []models.TERC20Token) map[commo
 
 		for _, token := range tokensFromChunk {
 			lowerHex := strings.ToLower(token.Address.Hex())
-			// Handle Katana chain special token mapping
-			if chainID == 747474 {
+			// Handle the chains whose tokens are priced as their mainnet counterpart
+			if chain.Capabilities.PricesTokensAsMainnet {
 				if mainnetTokenName, exists := KATANA_TOKEN_NAMES_TO_MAINNET_NAMES[token.Name]; exists {
 					logs.Info("Katana token", token.Name, "mapped to mainnet token", mainnetTokenName)
 					if mainnetAddress, found := getMainnetAddressForTokenName(mainnetTokenName); found {
@@ -98,20 +113,8 @@ func fetchPricesFromLlama(chainID uint64, tokens []models.TERC20Token) map[commo
 			// Normal token handling
 			tokenString = append(tokenString, LLAMA_CHAIN_NAMES[chainID]+`:`+lowerHex)
 
-			// Handle AJNA tokens
-			if addresses.Equals(token.Address, AJNA_TOKENS[10]) && chainID == 10 {
-				tokenString = append(tokenString, LLAMA_CHAIN_NAMES[1]+`:`+AJNA_TOKENS[1].Hex())
-			}
-			if addresses.Equals(token.Address, AJNA_TOKENS[100]) && chainID == 100 {
-				tokenString = append(tokenString, LLAMA_CHAIN_NAMES[1]+`:`+AJNA_TOKENS[1].Hex())
-			}
-			if addresses.Equals(token.Address, AJNA_TOKENS[137]) && chainID == 137 {
-				tokenString = append(tokenString, LLAMA_CHAIN_NAMES[1]+`:`+AJNA_TOKENS[1].Hex())
-			}
-			if addresses.Equals(token.Address, AJNA_TOKENS[8453]) && chainID == 8453 {
-				tokenString = append(tokenString, LLAMA_CHAIN_NAMES[1]+`:`+AJNA_TOKENS[1].Hex())
-			}
-			if addresses.Equals(token.Address, AJNA_TOKENS[42161]) && chainID == 42161 {
+			// Handle the AJNA tokens bridged from Ethereum, priced as the mainnet one
+			if ajnaToken, ok := getBridgedAJNAToken(chainID); ok && addresses.Equals(token.Address, ajnaToken) {
 				tokenString = append(tokenString, LLAMA_CHAIN_NAMES[1]+`:`+AJNA_TOKENS[1].Hex())
 			}
 		}
@@ -152,8 +155,8 @@ func fetchPricesFromLlama(chainID uint64, tokens []models.TERC20Token) map[commo
 			tokenAddressStr := strings.Split(tokenStr, ":")[1]
 			finalTokenAddress := tokenAddressStr
 
-			// Handle Katana token mapping in response
-			if chainID == 747474 {
+			// Handle the tokens priced as their mainnet counterpart in response
+			if chain.Capabilities.PricesTokensAsMainnet {
 				mainnetAddr := common.HexToAddress(tokenAddressStr)
 				if katanaAddr, exists := katanaToMainnetMapping[mainnetAddr]; exists {
 					finalTokenAddress = katanaAddr.Hex()
@@ -161,20 +164,8 @@ func fetchPricesFromLlama(chainID uint64, tokens []models.TERC20Token) map[commo
 			}
 
 			// Handle AJNA token mapping in response
-			if addresses.Equals(tokenAddressStr, AJNA_TOKENS[1]) && chainID == 10 {
-				finalTokenAddress = AJNA_TOKENS[10].Hex()
-			}
-			if addresses.Equals(tokenAddressStr, AJNA_TOKENS[1]) && chainID == 100 {
-				finalTokenAddress = AJNA_TOKENS[100].Hex()
-			}
-			if addresses.Equals(tokenAddressStr, AJNA_TOKENS[1]) && chainID == 137 {
-				finalTokenAddress = AJNA_TOKENS[137].Hex()
-			}
-			if addresses.Equals(tokenAddressStr, AJNA_TOKENS[1]) && chainID == 8453 {
-				finalTokenAddress = AJNA_TOKENS[8453].Hex()
-			}
-			if addresses.Equals(tokenAddressStr, AJNA_TOKENS[1]) && chainID == 42161 {
-				finalTokenAddress = AJNA_TOKENS[42161].Hex()
+			if ajnaToken, ok := getBridgedAJNAToken(chainID); ok && addresses.Equals(tokenAddressStr, AJNA_TOKENS[1]) {
+				finalTokenAddress = ajnaToken.Hex()
 			}
 
 			key := strings.ToLower(tokenStr)
@@ -224,3 +215,48 @@ func fetchPricesFromLlama(chainID uint64, tokens []models.TERC20Token) map[commo
 
 	return priceMap
 }
+
+/**************************************************************************************************
+** FetchHistoricalPriceFromLlama returns the price in USD of a token at a past timestamp from the
+** DeFiLlama pricing API, false when the chain is not supported or the token was not priced then.
+**************************************************************************************************/
+func FetchHistoricalPriceFromLlama(chainID uint64, tokenAddress common.Address, timestamp uint64) (float64, bool) {
+	price, ok := FetchHistoricalPricesFromLlama(chainID, []common.Address{tokenAddress}, timestamp)[tokenAddress]
+	return price, ok
+}
+
+/**************************************************************************************************
+** FetchHistoricalPricesFromLlama returns the prices in USD of some tokens at a past timestamp from
+** the DeFiLlama pricing API, in a single request. The tokens not priced then are missing.
+**************************************************************************************************/
+func FetchHistoricalPricesFromLlama(chainID uint64, tokenAddresses []common.Address, timestamp uint64) map[common.Address]float64 {
+	prices := make(map[common.Address]float64)
+	chainName, ok := LLAMA_CHAIN_NAMES[chainID]
+	if !ok || len(tokenAddresses) == 0 {
+		return prices
+	}
+	coins := []string{}
+	for _, tokenAddress := range tokenAddresses {
+		coins = append(coins, chainName+`:`+strings.ToLower(tokenAddress.Hex()))
+	}
+	resp, err := http.Get(env.LLAMA_HISTORICAL_PRICE_URL + strconv.FormatUint(timestamp, 10) + `/` + strings.Join(coins, `,`))
+	if err != nil {
+		logs.Warning("🦙 [LLAMA HISTORICAL] failed", "chain", chainID, "tokens", len(tokenAddresses), "error", err)
+		return prices
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		logs.Warning("🦙 [LLAMA HISTORICAL] non-200", "chain", chainID, "status", resp.StatusCode)
+		return prices
+	}
+	priceData := TLlamaPrice{}
+	if err := json.NewDecoder(resp.Body).Decode(&priceData); err != nil {
+		return prices
+	}
+	for i, tokenAddress := range tokenAddresses {
+		if data, ok := priceData.Coins[coins[i]]; ok && data.Price > 0 {
+			prices[tokenAddress] = data.Price
+		}
+	}
+	return prices
+}
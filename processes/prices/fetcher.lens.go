@@ -42,7 +42,8 @@ func fetchPricesFromLens(chainID uint64, blockNumber *uint64, tokens []models.TE
 	** to fetch all the ~250 prices we need. Using the following code reduces the time to a few s
 	** for the same amount of data.
 	**********************************************************************************************/
-	if NO_MULTICALLS {
+	useMulticall := !NO_MULTICALLS && chain.Capabilities.HasMulticall
+	if !useMulticall {
 		client := ethereum.GetRPC(chainID)
 		lensContract, err := contracts.NewOracleCaller(lensAddress, client)
 		if err != nil {
@@ -120,7 +121,7 @@ func fetchPricesFromLens(chainID uint64, blockNumber *uint64, tokens []models.TE
 	** multicall and will later be accessible via a concatened string `tokenAddress + methodName`.
 	**********************************************************************************************/
 	calls := []ethereum.Call{}
-	if !NO_MULTICALLS {
+	if useMulticall {
 		for _, token := range tokens {
 			calls = append(calls, multicalls.GetPriceUsdcRecommendedCall(token.Address.Hex(), lensAddress, token.Address))
 		}
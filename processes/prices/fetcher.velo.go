@@ -66,12 +66,25 @@ func fetchVelo(url string) []TVeloPairData {
 	return factories.Data
 }
 
+/**************************************************************************************************
+** OnSugarFetched is called at the end of every fetch of the Velodrome sugar, with the number of
+** prices it returned and its last error. It's set by the daemon to track the health of the sugar,
+** the Velodrome APRs being computed from its prices.
+**************************************************************************************************/
+var OnSugarFetched func(chainID uint64, count int, err error)
+
 // fetchPricesFromSugar is used to fetch prices from the sugar API (velo).
 func fetchPricesFromSugar(chainID uint64, blockNumber *uint64, tokens []models.TERC20Token) map[common.Address]models.TPrices {
 	priceMap := make(map[common.Address]models.TPrices)
 	if chainID != 10 {
 		return priceMap
 	}
+	var sugarErr error
+	defer func() {
+		if OnSugarFetched != nil {
+			OnSugarFetched(chainID, len(priceMap), sugarErr)
+		}
+	}()
 
 	/**********************************************************************************************
 	** The first step is to prepare the multicall, connecting to the multicall instance and
@@ -86,6 +99,7 @@ func fetchPricesFromSugar(chainID uint64, blockNumber *uint64, tokens []models.T
 		allSugar, err := sugar.All(nil, big.NewInt(int64(callSize)), big.NewInt(start))
 		if len(allSugar) == 0 || err != nil {
 			if err != nil {
+				sugarErr = err
 				logs.Error(`error fetching velo sugar`, err)
 				if callSize > 1 {
 					callSize /= 2
@@ -116,6 +130,7 @@ func fetchPricesFromSugar(chainID uint64, blockNumber *uint64, tokens []models.T
 		}
 		veloSugarContract, err := contracts.NewVeloSugarOracleCaller(VELO_SUGAR_ORACLE_ADDRESS, client)
 		if err != nil {
+			sugarErr = err
 			logs.Error(`error fetching velo sugar contract`, err)
 			return priceMap
 		}
@@ -125,6 +140,7 @@ func fetchPricesFromSugar(chainID uint64, blockNumber *uint64, tokens []models.T
 			append(tokensInVelo, OPT_RATE_CONNECTORS...),
 		)
 		if err != nil {
+			sugarErr = err
 			logs.Error(`error fetching velo sugar prices`, err)
 			return priceMap
 		}
@@ -311,6 +311,12 @@ func fetchPrices(
 	**********************************************************************************************/
 	markPriceErrorSent(chainID, tokenMap, newPriceMap)
 
+	/**********************************************************************************************
+	** The tokens still missing a price keep their last known one, flagged as stale, rather than
+	** bringing the TVL of their vaults to zero.
+	**********************************************************************************************/
+	carryStalePrices(chainID, tokenMap, newPriceMap)
+
 	for _, price := range newPriceMap {
 		storage.StorePrice(chainID, price)
 	}
@@ -6,10 +6,13 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
 	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
 )
 
 func captureOutput(t *testing.T, fn func()) string {
@@ -162,3 +165,68 @@ func TestLogZeroPricesSkipsNonZero(t *testing.T) {
 		t.Fatalf("expected no zero-price warnings, got %q", output)
 	}
 }
+
+func TestCarryStalePricesKeepsLastKnownPrice(t *testing.T) {
+	pricedAddr := common.HexToAddress("0x00000000000000000000000000000000000000DD")
+	unpricedAddr := common.HexToAddress("0x00000000000000000000000000000000000000EE")
+	storage.StorePrice(1, models.TPrices{
+		Address:        pricedAddr,
+		Price:          bigNumber.NewInt(1000000),
+		HumanizedPrice: bigNumber.NewFloat(1),
+		Source:         "test-last",
+	})
+
+	newPriceMap := map[common.Address]models.TPrices{}
+	carryStalePrices(1, map[common.Address]models.TERC20Token{
+		pricedAddr:   {Address: pricedAddr, Symbol: "PRICED"},
+		unpricedAddr: {Address: unpricedAddr, Symbol: "UNPRICED"},
+	}, newPriceMap)
+
+	carried, ok := newPriceMap[pricedAddr]
+	if !ok || !carried.IsStale || carried.StaleSince == 0 || carried.Price.Int64() != 1000000 {
+		t.Fatalf("expected the last known price to be carried as stale, got %+v", carried)
+	}
+	if _, ok := newPriceMap[unpricedAddr]; ok {
+		t.Fatalf("expected no price for a token never priced")
+	}
+
+	unpriced := ListUnpricedTokens(1)
+	if len(unpriced) != 2 {
+		t.Fatalf("expected 2 unpriced tokens, got %d", len(unpriced))
+	}
+	for _, token := range unpriced {
+		if token.Address == pricedAddr.Hex() && (!token.IsStale || token.LastKnownPrice == nil) {
+			t.Fatalf("expected the carried token to be stale with its last price, got %+v", token)
+		}
+		if token.Address == unpricedAddr.Hex() && (token.IsStale || token.LastKnownPrice != nil) {
+			t.Fatalf("expected the token never priced to have no last price, got %+v", token)
+		}
+	}
+}
+
+func TestCarryStalePricesDropsPricesPastMaxAge(t *testing.T) {
+	expiredAddr := common.HexToAddress("0x00000000000000000000000000000000000000FF")
+	staleSince := time.Now().Add(-env.STALE_PRICE_MAX_AGE - time.Hour).Unix()
+	storage.StorePrice(1, models.TPrices{
+		Address:        expiredAddr,
+		Price:          bigNumber.NewInt(1000000),
+		HumanizedPrice: bigNumber.NewFloat(1),
+		Source:         "test-expired",
+		IsStale:        true,
+		StaleSince:     staleSince,
+	})
+
+	newPriceMap := map[common.Address]models.TPrices{}
+	carryStalePrices(1, map[common.Address]models.TERC20Token{
+		expiredAddr: {Address: expiredAddr, Symbol: "EXPIRED"},
+	}, newPriceMap)
+
+	if _, ok := newPriceMap[expiredAddr]; ok {
+		t.Fatalf("expected the price stale for longer than STALE_PRICE_MAX_AGE not to be carried")
+	}
+	for _, token := range ListUnpricedTokens(1) {
+		if token.Address == expiredAddr.Hex() && (token.IsStale || token.LastKnownPrice == nil || token.UnpricedSince != staleSince) {
+			t.Fatalf("expected the expired token to keep its last price without being stale, got %+v", token)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package prices
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/addresses"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** TUnpricedToken is a token no price source priced during the last refresh of its chain. When a
+** price was known before, LastKnownPrice is that price, nil otherwise. It's carried over with
+** IsStale set for STALE_PRICE_MAX_AGE, IsStale being false once the token has no price anymore. Vaults lists the vaults using the token as underlying, with their last TVL.
+**************************************************************************************************/
+type TUnpricedToken struct {
+	ChainID        uint64           `json:"chainID"`
+	Address        string           `json:"address"`
+	Name           string           `json:"name"`
+	Symbol         string           `json:"symbol"`
+	Type           string           `json:"type"`
+	LastKnownPrice *bigNumber.Float `json:"lastKnownPrice"`
+	LastSource     string           `json:"lastSource,omitempty"`
+	IsStale        bool             `json:"isStale"`
+	UnpricedSince  int64            `json:"unpricedSince"`
+	Vaults         []TUnpricedVault `json:"vaults"`
+}
+
+type TUnpricedVault struct {
+	Address string  `json:"address"`
+	Name    string  `json:"name"`
+	TVL     float64 `json:"tvl"`
+}
+
+var (
+	unpricedTokens = make(map[uint64]map[common.Address]TUnpricedToken)
+	unpricedMtx    sync.RWMutex
+)
+
+/**************************************************************************************************
+** OnVaultPriceLost is called when a vault with a TVL of at least UNPRICED_ALERT_MIN_TVL_USD loses
+** the price of its underlying token. It's set by the daemon to push an immediate alert.
+**************************************************************************************************/
+var OnVaultPriceLost func(token TUnpricedToken, vault TUnpricedVault)
+
+/**************************************************************************************************
+** carryStalePrices handles the tokens of a chain left without a price by all the sources: their
+** last known price is carried over in newPriceMap with the stale flag, instead of a zero TVL, for
+** STALE_PRICE_MAX_AGE at most, and they are listed as unpriced. The vaults above the alert threshold are notified once, when their
+** underlying token stops being priced.
+**************************************************************************************************/
+func carryStalePrices(chainID uint64, tokenMap map[common.Address]models.TERC20Token, newPriceMap map[common.Address]models.TPrices) {
+	now := time.Now().Unix()
+	_, vaults := storage.ListVaults(chainID)
+
+	unpricedMtx.Lock()
+	previous := unpricedTokens[chainID]
+	current := make(map[common.Address]TUnpricedToken)
+	lost := []TUnpricedToken{}
+	for _, token := range tokenMap {
+		if price, ok := newPriceMap[token.Address]; ok && price.Price != nil && !price.Price.IsZero() {
+			continue
+		}
+
+		unpriced := TUnpricedToken{
+			ChainID:       chainID,
+			Address:       token.Address.Hex(),
+			Name:          token.Name,
+			Symbol:        token.Symbol,
+			Type:          string(token.Type),
+			UnpricedSince: now,
+			Vaults:        []TUnpricedVault{},
+		}
+		previousToken, wasUnpriced := previous[token.Address]
+		if wasUnpriced {
+			unpriced.UnpricedSince = previousToken.UnpricedSince
+		}
+		isNewlyLost := !wasUnpriced
+		if lastPrice, ok := storage.GetPrice(chainID, token.Address); ok && lastPrice.Price != nil && !lastPrice.Price.IsZero() {
+			if lastPrice.IsStale {
+				isNewlyLost = false // Already lost before a restart, loaded stale from the JSON storage
+			} else {
+				lastPrice.IsStale = true
+				lastPrice.StaleSince = now
+			}
+			unpriced.LastKnownPrice = lastPrice.HumanizedPrice
+			unpriced.LastSource = lastPrice.Source
+			unpriced.UnpricedSince = lastPrice.StaleSince
+			if time.Since(time.Unix(lastPrice.StaleSince, 0)) <= env.STALE_PRICE_MAX_AGE {
+				newPriceMap[token.Address] = lastPrice
+				unpriced.IsStale = true
+			}
+		} else if wasUnpriced && previousToken.LastKnownPrice != nil {
+			unpriced.LastKnownPrice = previousToken.LastKnownPrice
+			unpriced.LastSource = previousToken.LastSource
+		}
+		for _, vault := range vaults {
+			if addresses.Equals(vault.AssetAddress, token.Address) {
+				name := vault.Metadata.DisplayName
+				if vaultToken, ok := tokenMap[vault.Address]; ok && name == `` {
+					name = vaultToken.Name
+				}
+				tvl, _ := storage.GetKongTVL(chainID, vault.Address)
+				unpriced.Vaults = append(unpriced.Vaults, TUnpricedVault{
+					Address: vault.Address.Hex(),
+					Name:    name,
+					TVL:     tvl,
+				})
+			}
+		}
+		current[token.Address] = unpriced
+		if isNewlyLost {
+			lost = append(lost, unpriced)
+		}
+	}
+	unpricedTokens[chainID] = current
+	unpricedMtx.Unlock()
+
+	for _, unpriced := range lost {
+		logs.Warning("🪙 [PRICE LOST]", "chain", chainID, "token", unpriced.Address, "stale", unpriced.IsStale, "vaults", len(unpriced.Vaults))
+		for _, vault := range unpriced.Vaults {
+			if vault.TVL >= env.UNPRICED_ALERT_MIN_TVL_USD && OnVaultPriceLost != nil {
+				OnVaultPriceLost(unpriced, vault)
+			}
+		}
+	}
+}
+
+/**************************************************************************************************
+** ListUnpricedTokens returns the tokens of a chain without a price from any source, sorted by the
+** TVL of their vaults, highest first.
+**************************************************************************************************/
+func ListUnpricedTokens(chainID uint64) []TUnpricedToken {
+	unpricedMtx.RLock()
+	defer unpricedMtx.RUnlock()
+
+	tokens := []TUnpricedToken{}
+	for _, token := range unpricedTokens[chainID] {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if tvlI, tvlJ := getUnpricedTVL(tokens[i]), getUnpricedTVL(tokens[j]); tvlI != tvlJ {
+			return tvlI > tvlJ
+		}
+		return tokens[i].Address < tokens[j].Address
+	})
+	return tokens
+}
+
+/**************************************************************************************************
+** ListAllUnpricedTokens returns the unpriced tokens of all the supported chains, by chain.
+**************************************************************************************************/
+func ListAllUnpricedTokens() map[uint64][]TUnpricedToken {
+	tokens := make(map[uint64][]TUnpricedToken)
+	for _, chainID := range env.SUPPORTED_CHAIN_IDS {
+		tokens[chainID] = ListUnpricedTokens(chainID)
+	}
+	return tokens
+}
+
+func getUnpricedTVL(token TUnpricedToken) float64 {
+	tvl := 0.0
+	for _, vault := range token.Vaults {
+		tvl += vault.TVL
+	}
+	return tvl
+}
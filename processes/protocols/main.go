@@ -0,0 +1,164 @@
+package protocols
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The protocols a strategy is exposed to are labelled by a registry of resolvers, one per protocol
+** and per file (resolver.<protocol>.go), registered from their init function. A resolver matches
+** the strategies on their name and, when it also implements TContractResolver, on their contract
+** interface, read in one multicall per chain.
+** The labels set in the CMS are kept and the labels of the resolvers are appended to them.
+**************************************************************************************************/
+type TProtocolResolver interface {
+	// Protocol returns the label added to the matching strategies
+	Protocol() string
+	// MatchesName returns true if the on-chain or display name of the strategy is the protocol's
+	MatchesName(strategy models.TStrategy) bool
+}
+
+/**************************************************************************************************
+** TContractResolver is implemented by the resolvers also identifying a strategy from its contract:
+** Calls returns the calls to perform on the strategy, named with the given key, and
+** MatchesContract checks their responses.
+**************************************************************************************************/
+type TContractResolver interface {
+	TProtocolResolver
+	Calls(key string, strategy models.TStrategy) []ethereum.Call
+	MatchesContract(key string, strategy models.TStrategy, response map[string][]interface{}) bool
+}
+
+var resolvers = []TProtocolResolver{}
+var resolversMtx sync.RWMutex
+
+/**************************************************************************************************
+** RegisterResolver adds a resolver to the registry.
+**************************************************************************************************/
+func RegisterResolver(resolver TProtocolResolver) {
+	resolversMtx.Lock()
+	defer resolversMtx.Unlock()
+	resolvers = append(resolvers, resolver)
+}
+
+func listResolvers() []TProtocolResolver {
+	resolversMtx.RLock()
+	defer resolversMtx.RUnlock()
+	return append([]TProtocolResolver{}, resolvers...)
+}
+
+/**************************************************************************************************
+** tNameResolver is the resolver of the protocols only identified by the name of the strategies.
+**************************************************************************************************/
+type tNameResolver struct {
+	protocol string
+	patterns []*regexp.Regexp
+}
+
+func newNameResolver(protocol string, patterns ...string) tNameResolver {
+	resolver := tNameResolver{protocol: protocol}
+	for _, pattern := range patterns {
+		resolver.patterns = append(resolver.patterns, regexp.MustCompile(pattern))
+	}
+	return resolver
+}
+
+func (r tNameResolver) Protocol() string {
+	return r.protocol
+}
+
+func (r tNameResolver) MatchesName(strategy models.TStrategy) bool {
+	for _, pattern := range r.patterns {
+		if pattern.MatchString(strategy.Name) || pattern.MatchString(strategy.DisplayName) {
+			return true
+		}
+	}
+	return false
+}
+
+/**************************************************************************************************
+** appendProtocol adds a label to the protocols of a strategy, unless it is already there with any
+** casing.
+**************************************************************************************************/
+func appendProtocol(protocols []string, protocol string) []string {
+	for _, existing := range protocols {
+		if strings.EqualFold(existing, protocol) {
+			return protocols
+		}
+	}
+	return append(protocols, protocol)
+}
+
+/**************************************************************************************************
+** ResolveStrategyProtocols returns the protocols of a strategy: its current labels followed by the
+** ones of the resolvers it matches, sorted. The response of the contract calls can be nil to only
+** match on the names.
+**************************************************************************************************/
+func ResolveStrategyProtocols(key string, strategy models.TStrategy, response map[string][]interface{}) []string {
+	resolved := []string{}
+	for _, resolver := range listResolvers() {
+		if resolver.MatchesName(strategy) {
+			resolved = appendProtocol(resolved, resolver.Protocol())
+			continue
+		}
+		if contractResolver, ok := resolver.(TContractResolver); ok && response != nil {
+			if contractResolver.MatchesContract(key, strategy, response) {
+				resolved = appendProtocol(resolved, resolver.Protocol())
+			}
+		}
+	}
+	sort.Strings(resolved)
+
+	protocols := append([]string{}, strategy.Protocols...)
+	for _, protocol := range resolved {
+		protocols = appendProtocol(protocols, protocol)
+	}
+	return protocols
+}
+
+/**************************************************************************************************
+** RetrieveStrategiesProtocols labels the strategies of a chain with the protocols they are exposed
+** to and stores the strategies whose labels changed.
+**************************************************************************************************/
+func RetrieveStrategiesProtocols(chainID uint64) {
+	strategies, _ := storage.ListStrategies(chainID)
+
+	calls := []ethereum.Call{}
+	for key, strategy := range strategies {
+		for _, resolver := range listResolvers() {
+			if contractResolver, ok := resolver.(TContractResolver); ok {
+				calls = append(calls, contractResolver.Calls(key, strategy)...)
+			}
+		}
+	}
+	var response map[string][]interface{}
+	if len(calls) > 0 {
+		response = multicalls.Perform(chainID, calls, nil)
+	}
+
+	updated := 0
+	for key, strategy := range strategies {
+		protocols := ResolveStrategyProtocols(key, strategy, response)
+		if len(protocols) == len(strategy.Protocols) {
+			continue
+		}
+		strategy.Protocols = protocols
+		storage.StoreStrategy(chainID, strategy)
+		updated++
+	}
+	if updated > 0 {
+		allStrategies, _ := storage.ListStrategies(chainID)
+		storage.StoreStrategiesToJson(chainID, allStrategies)
+	}
+	logs.Info(`Labelled the protocols of ` + strconv.Itoa(updated) + ` strategies on chain ` + strconv.FormatUint(chainID, 10))
+}
@@ -0,0 +1,65 @@
+package protocols
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yearn/ydaemon/internal/models"
+)
+
+/**************************************************************************************************
+** TestResolveStrategyProtocols checks the labels found from the names of some known strategies,
+** and that the labels already set (e.g. by the CMS) are kept first without duplicates.
+**************************************************************************************************/
+func TestResolveStrategyProtocols(t *testing.T) {
+	tests := []struct {
+		name      string
+		strategy  models.TStrategy
+		protocols []string
+	}{
+		{
+			name:      "Convex Curve LP",
+			strategy:  models.TStrategy{Name: "StrategyConvexFactory-crvUSD-USDC"},
+			protocols: []string{"Convex"},
+		},
+		{
+			name:      "Curve through Convex",
+			strategy:  models.TStrategy{Name: "StrategyCurveBoostedFactory", DisplayName: "Convex Curve crvUSD"},
+			protocols: []string{"Convex", "Curve"},
+		},
+		{
+			name:      "Aura Balancer LP",
+			strategy:  models.TStrategy{Name: "StrategyAuraBalancerClonable"},
+			protocols: []string{"Aura", "Balancer"},
+		},
+		{
+			name:      "Compound v3 lender",
+			strategy:  models.TStrategy{Name: "CompoundV3Lender USDC"},
+			protocols: []string{"Compound"},
+		},
+		{
+			name:      "Compounder is not Compound",
+			strategy:  models.TStrategy{Name: "yCRV Auto-Compounder"},
+			protocols: []string{},
+		},
+		{
+			name:      "Existing labels are kept",
+			strategy:  models.TStrategy{Name: "Sturdy Lender", Protocols: []string{"sturdy", "Custom"}},
+			protocols: []string{"sturdy", "Custom"},
+		},
+		{
+			name:      "Silo and StakeDAO",
+			strategy:  models.TStrategy{Name: "Stake DAO Silo"},
+			protocols: []string{"Silo", "StakeDAO"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			protocols := ResolveStrategyProtocols(`key`, test.strategy, nil)
+			if !reflect.DeepEqual(protocols, test.protocols) {
+				t.Errorf("Expected %v, got %v", test.protocols, protocols)
+			}
+		})
+	}
+}
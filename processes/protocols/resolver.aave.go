@@ -0,0 +1,8 @@
+package protocols
+
+/**************************************************************************************************
+** Aave: the lenders to the Aave markets.
+**************************************************************************************************/
+func init() {
+	RegisterResolver(newNameResolver(`Aave`, `(?i)aave`))
+}
@@ -0,0 +1,8 @@
+package protocols
+
+/**************************************************************************************************
+** Aura: the Balancer LP staking strategies, e.g. `StrategyAuraBalancer...`.
+**************************************************************************************************/
+func init() {
+	RegisterResolver(newNameResolver(`Aura`, `(?i)aura`))
+}
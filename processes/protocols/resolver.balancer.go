@@ -0,0 +1,8 @@
+package protocols
+
+/**************************************************************************************************
+** Balancer: the strategies farming Balancer pools, directly or through Aura.
+**************************************************************************************************/
+func init() {
+	RegisterResolver(newNameResolver(`Balancer`, `(?i)balancer`))
+}
@@ -0,0 +1,9 @@
+package protocols
+
+/**************************************************************************************************
+** Compound: the lenders to the Compound markets (Comet for v3). The "compounder", "compounding" and
+** "compounded" strategies are not Compound ones.
+**************************************************************************************************/
+func init() {
+	RegisterResolver(newNameResolver(`Compound`, `(?i)compound(v[23])?([^ei]|$)`, `(?i)comet`))
+}
@@ -0,0 +1,45 @@
+package protocols
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/addresses"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+)
+
+/**************************************************************************************************
+** Convex: the Curve LP strategies staking through the Convex booster. Besides their name, they are
+** identified by their `depositContract()` being the booster.
+**************************************************************************************************/
+var convexBoosters = map[uint64]common.Address{
+	1: common.HexToAddress(`0xF403C135812408BFbE8713b5A23a04b3D48AAE31`),
+}
+
+type tConvexResolver struct {
+	tNameResolver
+}
+
+func (r tConvexResolver) Calls(key string, strategy models.TStrategy) []ethereum.Call {
+	if _, ok := convexBoosters[strategy.ChainID]; !ok {
+		return nil
+	}
+	return []ethereum.Call{multicalls.GetConvexDepositContract(key, strategy.Address)}
+}
+
+func (r tConvexResolver) MatchesContract(key string, strategy models.TStrategy, response map[string][]interface{}) bool {
+	booster, ok := convexBoosters[strategy.ChainID]
+	if !ok {
+		return false
+	}
+	if values := response[key+`depositContract`]; len(values) == 1 {
+		if depositContract, ok := values[0].(common.Address); ok {
+			return addresses.Equals(depositContract, booster)
+		}
+	}
+	return false
+}
+
+func init() {
+	RegisterResolver(tConvexResolver{newNameResolver(`Convex`, `(?i)convex`, `(?i)cvx`)})
+}
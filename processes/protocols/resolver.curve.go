@@ -0,0 +1,9 @@
+package protocols
+
+/**************************************************************************************************
+** Curve: the strategies farming Curve pools, directly or through Convex and Stake DAO, and the
+** Curve lending (LlamaLend) ones.
+**************************************************************************************************/
+func init() {
+	RegisterResolver(newNameResolver(`Curve`, `(?i)curve`, `(?i)llama ?lend`))
+}
@@ -0,0 +1,8 @@
+package protocols
+
+/**************************************************************************************************
+** Morpho: the lenders to the Morpho Blue markets and the MetaMorpho vaults.
+**************************************************************************************************/
+func init() {
+	RegisterResolver(newNameResolver(`Morpho`, `(?i)morpho`))
+}
@@ -0,0 +1,8 @@
+package protocols
+
+/**************************************************************************************************
+** Silo: the lenders to the Silo Finance markets.
+**************************************************************************************************/
+func init() {
+	RegisterResolver(newNameResolver(`Silo`, `(?i)silo`))
+}
@@ -0,0 +1,8 @@
+package protocols
+
+/**************************************************************************************************
+** Stake DAO: the Curve LP strategies boosted through the Stake DAO locker.
+**************************************************************************************************/
+func init() {
+	RegisterResolver(newNameResolver(`StakeDAO`, `(?i)stake ?dao`))
+}
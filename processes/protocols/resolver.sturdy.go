@@ -0,0 +1,8 @@
+package protocols
+
+/**************************************************************************************************
+** Sturdy: the lenders to the Sturdy silos.
+**************************************************************************************************/
+func init() {
+	RegisterResolver(newNameResolver(`Sturdy`, `(?i)sturdy`))
+}
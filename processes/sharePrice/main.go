@@ -0,0 +1,204 @@
+package sharePrice
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** The share price of a vault only moves with the harvests: it goes up by the profit reported by
+** the strategies and, rarely, down by a loss. A share price moving between two snapshots without
+** any report, or a share price far from 1 on a brand-new vault, is the signature of a donation to
+** the vault (ERC4626 inflation attack). A drop beyond the threshold is flagged as well.
+**************************************************************************************************/
+const (
+	SHARE_PRICE_JUMP_THRESHOLD         = 0.05  // Increase without any report between two snapshots
+	SHARE_PRICE_HARVEST_JUMP_THRESHOLD = 0.25  // Increase with a report, too high to be a profit
+	SHARE_PRICE_DROP_THRESHOLD         = 0.005 // Decrease, with or without a report
+	SHARE_PRICE_WARNING_DURATION       = 72 * time.Hour
+	SHARE_PRICE_NEW_VAULT_PERIOD       = 7 * 24 * time.Hour
+)
+
+/**************************************************************************************************
+** Anomaly types.
+**************************************************************************************************/
+const (
+	ANOMALY_UNEXPECTED_JUMP = `unexpectedJump`
+	ANOMALY_DROP            = `drop`
+)
+
+/**************************************************************************************************
+** TSharePriceAnomaly describes a share price movement inconsistent with the harvest reports. The
+** change is relative to the previous share price (0.05 = +5%).
+**************************************************************************************************/
+type TSharePriceAnomaly struct {
+	ChainID               uint64  `json:"chainID"`
+	VaultAddress          string  `json:"vaultAddress"`
+	Type                  string  `json:"type"`
+	PreviousPricePerShare string  `json:"previousPricePerShare"`
+	PricePerShare         string  `json:"pricePerShare"`
+	Change                float64 `json:"change"`
+	HasReport             bool    `json:"hasReport"`
+	DetectedAt            uint64  `json:"detectedAt"`
+}
+
+type tObservation struct {
+	pricePerShare *bigNumber.Int
+	lastReport    uint64
+}
+
+var (
+	observations = make(map[uint64]map[common.Address]tObservation)
+	anomalies    = make(map[uint64]map[common.Address]TSharePriceAnomaly)
+	monitorMtx   sync.RWMutex
+)
+
+/**************************************************************************************************
+** OnSharePriceAnomaly is called for each new anomaly detected. It's set by the daemon to push an
+** immediate alert.
+**************************************************************************************************/
+var OnSharePriceAnomaly func(anomaly TSharePriceAnomaly)
+
+/**************************************************************************************************
+** getLastReport returns the timestamp of the most recent report of the strategies of a vault, 0
+** if none of them reported yet.
+**************************************************************************************************/
+func getLastReport(vault models.TVault) uint64 {
+	lastReport := uint64(0)
+	_, strategies := storage.ListStrategiesForVault(vault.ChainID, vault.Address)
+	for _, strategy := range strategies {
+		if strategy.LastReport != nil && strategy.LastReport.Uint64() > lastReport {
+			lastReport = strategy.LastReport.Uint64()
+		}
+	}
+	return lastReport
+}
+
+/**************************************************************************************************
+** computeChange returns the relative change between two share prices.
+**************************************************************************************************/
+func computeChange(previous *bigNumber.Int, current *bigNumber.Int) float64 {
+	if previous == nil || previous.IsZero() || current == nil {
+		return 0
+	}
+	previousFloat, _ := bigNumber.NewFloat().SetInt(previous).Float64()
+	currentFloat, _ := bigNumber.NewFloat().SetInt(current).Float64()
+	return (currentFloat - previousFloat) / previousFloat
+}
+
+/**************************************************************************************************
+** detectVaultAnomaly compares the share price of a vault with the previous observation. For a
+** vault without previous observation, activated recently and never harvested, the share price is
+** compared with the initial share price of 1.
+**************************************************************************************************/
+func detectVaultAnomaly(vault models.TVault, previous tObservation, hasPrevious bool, lastReport uint64) (TSharePriceAnomaly, bool) {
+	anomaly := TSharePriceAnomaly{
+		ChainID:       vault.ChainID,
+		VaultAddress:  vault.Address.Hex(),
+		PricePerShare: vault.LastPricePerShare.String(),
+		DetectedAt:    uint64(time.Now().Unix()),
+	}
+
+	if !hasPrevious {
+		isNewVault := time.Since(time.Unix(int64(vault.Activation), 0)) < SHARE_PRICE_NEW_VAULT_PERIOD
+		vaultToken, ok := storage.GetERC20(vault.ChainID, vault.Address)
+		if !isNewVault || lastReport != 0 || !ok {
+			return anomaly, false
+		}
+		initialPricePerShare := bigNumber.NewInt(0).Exp(bigNumber.NewInt(10), bigNumber.NewUint64(vaultToken.Decimals), nil)
+		anomaly.PreviousPricePerShare = initialPricePerShare.String()
+		anomaly.Change = computeChange(initialPricePerShare, vault.LastPricePerShare)
+		if anomaly.Change > SHARE_PRICE_JUMP_THRESHOLD {
+			anomaly.Type = ANOMALY_UNEXPECTED_JUMP
+			return anomaly, true
+		}
+		return anomaly, false
+	}
+
+	anomaly.PreviousPricePerShare = previous.pricePerShare.String()
+	anomaly.Change = computeChange(previous.pricePerShare, vault.LastPricePerShare)
+	anomaly.HasReport = lastReport > previous.lastReport
+	switch {
+	case anomaly.Change < -SHARE_PRICE_DROP_THRESHOLD:
+		anomaly.Type = ANOMALY_DROP
+	case !anomaly.HasReport && anomaly.Change > SHARE_PRICE_JUMP_THRESHOLD:
+		anomaly.Type = ANOMALY_UNEXPECTED_JUMP
+	case anomaly.HasReport && anomaly.Change > SHARE_PRICE_HARVEST_JUMP_THRESHOLD:
+		anomaly.Type = ANOMALY_UNEXPECTED_JUMP
+	default:
+		return anomaly, false
+	}
+	return anomaly, true
+}
+
+/**************************************************************************************************
+** DetectSharePriceAnomalies compares the share price of every vault of the chain with the one
+** observed at the previous run and flags the movements inconsistent with the harvest reports.
+** The new anomalies are logged and forwarded to OnSharePriceAnomaly. A vault stays flagged for
+** SHARE_PRICE_WARNING_DURATION.
+**************************************************************************************************/
+func DetectSharePriceAnomalies(chainID uint64) []TSharePriceAnomaly {
+	_, allVaults := storage.ListVaults(chainID)
+
+	monitorMtx.Lock()
+	if observations[chainID] == nil {
+		observations[chainID] = make(map[common.Address]tObservation)
+	}
+	if anomalies[chainID] == nil {
+		anomalies[chainID] = make(map[common.Address]TSharePriceAnomaly)
+	}
+	newAnomalies := []TSharePriceAnomaly{}
+	for _, vault := range allVaults {
+		if vault.LastPricePerShare == nil || vault.LastPricePerShare.IsZero() {
+			continue
+		}
+		lastReport := getLastReport(vault)
+		previous, hasPrevious := observations[chainID][vault.Address]
+		if anomaly, ok := detectVaultAnomaly(vault, previous, hasPrevious, lastReport); ok {
+			anomalies[chainID][vault.Address] = anomaly
+			newAnomalies = append(newAnomalies, anomaly)
+		}
+		observations[chainID][vault.Address] = tObservation{
+			pricePerShare: vault.LastPricePerShare,
+			lastReport:    lastReport,
+		}
+	}
+	monitorMtx.Unlock()
+
+	for _, anomaly := range newAnomalies {
+		logs.Warning(fmt.Sprintf("🚨 [SHARE PRICE] %s chain=%d vault=%s change=%.4f%% hasReport=%t",
+			anomaly.Type, anomaly.ChainID, anomaly.VaultAddress, anomaly.Change*100, anomaly.HasReport))
+		if OnSharePriceAnomaly != nil {
+			OnSharePriceAnomaly(anomaly)
+		}
+	}
+	return newAnomalies
+}
+
+/**************************************************************************************************
+** GetSharePriceWarning returns the last anomaly detected for a vault, if it was detected less than
+** SHARE_PRICE_WARNING_DURATION ago.
+**************************************************************************************************/
+func GetSharePriceWarning(chainID uint64, vaultAddress common.Address) (TSharePriceAnomaly, bool) {
+	monitorMtx.RLock()
+	defer monitorMtx.RUnlock()
+
+	if _, ok := anomalies[chainID]; !ok {
+		return TSharePriceAnomaly{}, false
+	}
+	anomaly, ok := anomalies[chainID][vaultAddress]
+	if !ok {
+		return TSharePriceAnomaly{}, false
+	}
+	if time.Since(time.Unix(int64(anomaly.DetectedAt), 0)) > SHARE_PRICE_WARNING_DURATION {
+		return TSharePriceAnomaly{}, false
+	}
+	return anomaly, true
+}
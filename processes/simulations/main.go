@@ -0,0 +1,243 @@
+package simulations
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/contracts"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** TPendingReport is the result of the simulation of the next `report()` call of a strategy. The
+** profit and loss are expressed in the strategy asset, normalized by its decimals.
+**************************************************************************************************/
+type TPendingReport struct {
+	PendingProfit *bigNumber.Float `json:"pendingProfit"`
+	PendingLoss   *bigNumber.Float `json:"pendingLoss"`
+	SimulatedAt   uint64           `json:"simulatedAt"`
+}
+
+type tSimulationRequest struct {
+	NetworkID      string `json:"network_id"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Input          string `json:"input"`
+	Save           bool   `json:"save"`
+	SimulationType string `json:"simulation_type"`
+}
+
+type tSimulationResponse struct {
+	Transaction struct {
+		Status          bool `json:"status"`
+		TransactionInfo struct {
+			CallTrace struct {
+				Output string `json:"output"`
+			} `json:"call_trace"`
+		} `json:"transaction_info"`
+	} `json:"transaction"`
+}
+
+/**************************************************************************************************
+** MAX_CONCURRENT_SIMULATIONS bounds the number of simulations sent at once to the simulation API,
+** which rate limits the requests of an access key.
+**************************************************************************************************/
+const MAX_CONCURRENT_SIMULATIONS = 5
+
+var (
+	pendingReports    = make(map[uint64]map[common.Address]TPendingReport)
+	pendingReportsMtx sync.RWMutex
+	simulationClient  = &http.Client{Timeout: 30 * time.Second}
+	strategyV3ABI, _  = contracts.YStrategyV3MetaData.GetAbi()
+	performCalls      = multicalls.Perform
+)
+
+/**************************************************************************************************
+** IsEnabled returns true if a simulation API is configured.
+**************************************************************************************************/
+func IsEnabled() bool {
+	return env.SIMULATION_API_URL != ``
+}
+
+/**************************************************************************************************
+** simulateCall sends a single call to the simulation API and returns the raw output of the top
+** level call.
+**************************************************************************************************/
+func simulateCall(chainID uint64, from common.Address, to common.Address, input []byte) ([]byte, error) {
+	body, err := json.Marshal(tSimulationRequest{
+		NetworkID:      strconv.FormatUint(chainID, 10),
+		From:           from.Hex(),
+		To:             to.Hex(),
+		Input:          hexutil.Encode(input),
+		Save:           false,
+		SimulationType: `quick`,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, env.SIMULATION_API_URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(`Content-Type`, `application/json`)
+	if env.SIMULATION_API_KEY != `` {
+		req.Header.Set(`X-Access-Key`, env.SIMULATION_API_KEY)
+	}
+
+	resp, err := simulationClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	rawBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, errors.New(`simulation failed with status ` + strconv.Itoa(resp.StatusCode))
+	}
+
+	var result tSimulationResponse
+	if err := json.Unmarshal(rawBody, &result); err != nil {
+		return nil, err
+	}
+	if !result.Transaction.Status {
+		return nil, errors.New(`simulated transaction reverted`)
+	}
+	return hexutil.Decode(result.Transaction.TransactionInfo.CallTrace.Output)
+}
+
+/**************************************************************************************************
+** simulateReport simulates the next `report()` of a v3 strategy, sent by its keeper, and decodes
+** the returned profit and loss.
+**************************************************************************************************/
+func simulateReport(chainID uint64, strategy common.Address, keeper common.Address, decimals uint64) (TPendingReport, error) {
+	input, err := strategyV3ABI.Pack(`report`)
+	if err != nil {
+		return TPendingReport{}, err
+	}
+
+	output, err := simulateCall(chainID, keeper, strategy, input)
+	if err != nil {
+		return TPendingReport{}, err
+	}
+	values, err := strategyV3ABI.Unpack(`report`, output)
+	if err != nil || len(values) != 2 {
+		return TPendingReport{}, errors.New(`invalid report output`)
+	}
+	profit, _ := values[0].(*big.Int)
+	loss, _ := values[1].(*big.Int)
+
+	return TPendingReport{
+		PendingProfit: helpers.ToNormalizedAmount(bigNumber.SetInt(profit), decimals),
+		PendingLoss:   helpers.ToNormalizedAmount(bigNumber.SetInt(loss), decimals),
+		SimulatedAt:   uint64(time.Now().Unix()),
+	}, nil
+}
+
+/**************************************************************************************************
+** RetrievePendingReports simulates the next report of every active v3 strategy of the chain and
+** caches the result. The keepers sending the reports are read in a single multicall, and at most
+** MAX_CONCURRENT_SIMULATIONS simulations are sent at once. This is a no-op if no simulation API
+** is configured.
+**************************************************************************************************/
+func RetrievePendingReports(chainID uint64) {
+	if !IsEnabled() {
+		return
+	}
+
+	_, allStrategies := storage.ListStrategies(chainID)
+	strategies := []models.TStrategy{}
+	decimals := make(map[common.Address]uint64)
+	calls := []ethereum.Call{}
+	for _, strategy := range allStrategies {
+		if strategy.IsRetired || strategy.LastTotalDebt == nil || strategy.LastTotalDebt.IsZero() {
+			continue
+		}
+		if !strings.HasPrefix(strategy.VaultVersion, `3`) && !strings.HasPrefix(strategy.VaultVersion, `~3`) {
+			continue
+		}
+
+		vault, ok := storage.GetVault(chainID, strategy.VaultAddress)
+		if !ok {
+			continue
+		}
+		asset, ok := storage.GetERC20(chainID, vault.AssetAddress)
+		if !ok {
+			continue
+		}
+		strategies = append(strategies, strategy)
+		decimals[strategy.Address] = asset.Decimals
+		calls = append(calls, multicalls.GetKeeper(strategy.Address.Hex(), strategy.Address, strategy.VaultVersion))
+	}
+	if len(calls) == 0 {
+		return
+	}
+	response := performCalls(chainID, calls, nil)
+
+	result := make(map[common.Address]TPendingReport)
+	resultMtx := sync.Mutex{}
+	semaphore := make(chan struct{}, MAX_CONCURRENT_SIMULATIONS)
+	wg := sync.WaitGroup{}
+	for _, strategy := range strategies {
+		rawKeeper := response[strategy.Address.Hex()+`keeper`]
+		if len(rawKeeper) == 0 {
+			continue
+		}
+		keeper := helpers.DecodeAddress(rawKeeper)
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(strategy common.Address, keeper common.Address, decimals uint64) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			pendingReport, err := simulateReport(chainID, strategy, keeper, decimals)
+			if err != nil {
+				logs.Warning(`Failed to simulate report for strategy ` + strategy.Hex() + ` on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+				return
+			}
+			resultMtx.Lock()
+			result[strategy] = pendingReport
+			resultMtx.Unlock()
+		}(strategy.Address, keeper, decimals[strategy.Address])
+	}
+	wg.Wait()
+
+	pendingReportsMtx.Lock()
+	pendingReports[chainID] = result
+	pendingReportsMtx.Unlock()
+}
+
+/**************************************************************************************************
+** GetPendingReport returns the last simulated report for a strategy, if any.
+**************************************************************************************************/
+func GetPendingReport(chainID uint64, strategyAddress common.Address) (TPendingReport, bool) {
+	pendingReportsMtx.RLock()
+	defer pendingReportsMtx.RUnlock()
+
+	if _, ok := pendingReports[chainID]; !ok {
+		return TPendingReport{}, false
+	}
+	pendingReport, ok := pendingReports[chainID][strategyAddress]
+	return pendingReport, ok
+}
@@ -0,0 +1,192 @@
+package simulations
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+/**************************************************************************************************
+** serveSimulations starts a fake simulation API answering every request with the given profit and
+** loss, and sets it as the configured API for the duration of the test.
+**************************************************************************************************/
+func serveSimulations(t *testing.T, handler http.HandlerFunc) {
+	server := httptest.NewServer(handler)
+	previousURL := env.SIMULATION_API_URL
+	env.SIMULATION_API_URL = server.URL
+	t.Cleanup(func() {
+		server.Close()
+		env.SIMULATION_API_URL = previousURL
+	})
+}
+
+func writeReportOutput(t *testing.T, w http.ResponseWriter, status bool, profit *big.Int, loss *big.Int) {
+	output, err := strategyV3ABI.Methods[`report`].Outputs.Pack(profit, loss)
+	if err != nil {
+		t.Fatalf("Failed to encode the report output: %v", err)
+	}
+	response := tSimulationResponse{}
+	response.Transaction.Status = status
+	response.Transaction.TransactionInfo.CallTrace.Output = hexutil.Encode(output)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+/**************************************************************************************************
+** TestSimulateReport checks the request sent to the simulation API, the report being sent by the
+** keeper of the strategy, and the decoding of the profit and loss it returns.
+**************************************************************************************************/
+func TestSimulateReport(t *testing.T) {
+	strategy := common.HexToAddress(`0x00000000000000000000000000000000000000a1`)
+	keeper := common.HexToAddress(`0x00000000000000000000000000000000000000b1`)
+	reportInput, _ := strategyV3ABI.Pack(`report`)
+
+	tests := []struct {
+		name           string
+		statusCode     int
+		status         bool
+		expectedErr    bool
+		expectedProfit float64
+		expectedLoss   float64
+	}{
+		{name: "profit", statusCode: http.StatusOK, status: true, expectedProfit: 1.5},
+		{name: "reverted", statusCode: http.StatusOK, status: false, expectedErr: true},
+		{name: "api error", statusCode: http.StatusTooManyRequests, status: true, expectedErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serveSimulations(t, func(w http.ResponseWriter, r *http.Request) {
+				request := tSimulationRequest{}
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					t.Errorf("Failed to decode the simulation request: %v", err)
+				}
+				if request.NetworkID != `1` || request.From != keeper.Hex() || request.To != strategy.Hex() {
+					t.Errorf("Expected a report of %s sent by %s on chain 1, got %+v", strategy.Hex(), keeper.Hex(), request)
+				}
+				if request.Input != hexutil.Encode(reportInput) {
+					t.Errorf("Expected the input of report(), got %s", request.Input)
+				}
+				w.WriteHeader(tt.statusCode)
+				writeReportOutput(t, w, tt.status, big.NewInt(1_500_000), big.NewInt(0))
+			})
+
+			report, err := simulateReport(1, strategy, keeper, 6)
+			if tt.expectedErr {
+				if err == nil {
+					t.Error("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to simulate the report: %v", err)
+			}
+			if profit, _ := report.PendingProfit.Float64(); profit != tt.expectedProfit {
+				t.Errorf("Expected a pending profit of %v, got %v", tt.expectedProfit, profit)
+			}
+			if loss, _ := report.PendingLoss.Float64(); loss != tt.expectedLoss {
+				t.Errorf("Expected a pending loss of %v, got %v", tt.expectedLoss, loss)
+			}
+		})
+	}
+}
+
+/**************************************************************************************************
+** TestRetrievePendingReports checks that the reports of the active v3 strategies are simulated,
+** never more than MAX_CONCURRENT_SIMULATIONS at once, and that the strategies without keeper, the
+** retired ones and the v2 ones are skipped.
+**************************************************************************************************/
+func TestRetrievePendingReports(t *testing.T) {
+	const chainID = 1337
+	const strategiesCount = 4 * MAX_CONCURRENT_SIMULATIONS
+	asset := common.HexToAddress(`0x00000000000000000000000000000000000000c1`)
+	vault := common.HexToAddress(`0x00000000000000000000000000000000000000d1`)
+	env.CHAINS[chainID] = env.TChain{ID: chainID}
+	defer delete(env.CHAINS, chainID)
+	storage.StoreERC20(chainID, models.TERC20Token{Address: asset, ChainID: chainID, Decimals: 18})
+	storage.StoreVault(chainID, models.TVault{Address: vault, AssetAddress: asset, ChainID: chainID, Version: `3.0.2`})
+
+	strategy := func(index int, version string, isRetired bool) common.Address {
+		address := common.BigToAddress(big.NewInt(int64(0x1000 + index)))
+		storage.StoreStrategy(chainID, models.TStrategy{
+			Address:       address,
+			VaultAddress:  vault,
+			VaultVersion:  version,
+			ChainID:       chainID,
+			IsRetired:     isRetired,
+			LastTotalDebt: bigNumber.NewInt(1),
+		})
+		return address
+	}
+	active := []common.Address{}
+	for i := 0; i < strategiesCount; i++ {
+		active = append(active, strategy(i, `3.0.2`, false))
+	}
+	withoutKeeper := strategy(strategiesCount, `3.0.2`, false)
+	retired := strategy(strategiesCount+1, `3.0.2`, true)
+	legacy := strategy(strategiesCount+2, `0.4.6`, false)
+
+	keeper := common.HexToAddress(`0x00000000000000000000000000000000000000b1`)
+	keeperCalls := 0
+	previousPerformCalls := performCalls
+	performCalls = func(_ uint64, calls []ethereum.Call, _ *big.Int) map[string][]interface{} {
+		keeperCalls += len(calls)
+		response := map[string][]interface{}{}
+		for _, call := range calls {
+			if call.Target != withoutKeeper {
+				response[call.Name+call.Method] = []interface{}{keeper}
+			}
+		}
+		return response
+	}
+	defer func() { performCalls = previousPerformCalls }()
+
+	inFlight := int32(0)
+	maxInFlight := int32(0)
+	serveSimulations(t, func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			previous := atomic.LoadInt32(&maxInFlight)
+			if current <= previous || atomic.CompareAndSwapInt32(&maxInFlight, previous, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		writeReportOutput(t, w, true, big.NewInt(1e18), big.NewInt(0))
+	})
+
+	RetrievePendingReports(chainID)
+
+	if keeperCalls != strategiesCount+1 {
+		t.Errorf("Expected the keepers of the %d active v3 strategies to be read, got %d calls", strategiesCount+1, keeperCalls)
+	}
+	if maxInFlight > MAX_CONCURRENT_SIMULATIONS {
+		t.Errorf("Expected at most %d simulations at once, got %d", MAX_CONCURRENT_SIMULATIONS, maxInFlight)
+	}
+	for i, address := range active {
+		report, ok := GetPendingReport(chainID, address)
+		if !ok {
+			t.Errorf("Expected a pending report for the strategy %d", i)
+			continue
+		}
+		if profit, _ := report.PendingProfit.Float64(); profit != 1 {
+			t.Errorf("Expected a pending profit of 1, got %v", profit)
+		}
+	}
+	for _, address := range []common.Address{withoutKeeper, retired, legacy} {
+		if _, ok := GetPendingReport(chainID, address); ok {
+			t.Errorf("Expected no pending report for the strategy %s", address.Hex())
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package tokenlist
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/internal/storage"
+	"github.com/yearn/ydaemon/processes/assets"
+)
+
+/**************************************************************************************************
+** The constraints of the tokenlist schema on the tokens. The tokens breaking them would make the
+** whole list invalid, so they are left out.
+**************************************************************************************************/
+const (
+	TOKENLIST_MAX_NAME_LENGTH   = 60
+	TOKENLIST_MAX_SYMBOL_LENGTH = 20
+)
+
+var tokenListNamePattern = regexp.MustCompile(`^[ \S+]+$`)
+var tokenListSymbolPattern = regexp.MustCompile(`^\S+$`)
+
+/**************************************************************************************************
+** isValidTokenListToken checks a token against the constraints of the tokenlist schema.
+**************************************************************************************************/
+func isValidTokenListToken(token storage.TTokenListToken) bool {
+	nameLength := utf8.RuneCountInString(token.Name)
+	symbolLength := utf8.RuneCountInString(token.Symbol)
+	return nameLength > 0 && nameLength <= TOKENLIST_MAX_NAME_LENGTH && tokenListNamePattern.MatchString(token.Name) &&
+		symbolLength > 0 && symbolLength <= TOKENLIST_MAX_SYMBOL_LENGTH && tokenListSymbolPattern.MatchString(token.Symbol) &&
+		token.Decimals <= 255
+}
+
+/**************************************************************************************************
+** RefreshTokenList rebuilds the tokenlist from the share tokens of the Yearn vaults of all the
+** supported chains and stores it, the version being bumped when the tokens changed (see
+** storage.StoreTokenList). The retired vaults are kept, as their tokens are still held, but the
+** blacklisted ones are left out. It runs with the refresh of the vaults, the route only reading
+** the stored list.
+**************************************************************************************************/
+func RefreshTokenList() {
+	tokens := make(map[string]storage.TTokenListToken)
+	for _, chainID := range env.SUPPORTED_CHAIN_IDS {
+		chain, ok := env.GetChain(chainID)
+		if !ok {
+			continue
+		}
+		_, allVaults := storage.ListVaults(chainID)
+		for _, vault := range allVaults {
+			if !vault.Metadata.Inclusion.IsYearn || helpers.Contains(chain.BlacklistedVaults, vault.Address) {
+				continue
+			}
+			vaultToken, ok := storage.GetERC20(chainID, vault.Address)
+			if !ok {
+				continue
+			}
+			logoURI, _, _ := assets.ResolveAssets(chainID, vault.Address, vaultToken.Icon)
+			token := storage.TTokenListToken{
+				ChainID:  chainID,
+				Address:  vault.Address.Hex(),
+				Name:     strings.TrimSpace(vaultToken.Name),
+				Symbol:   strings.TrimSpace(vaultToken.Symbol),
+				Decimals: vaultToken.Decimals,
+				LogoURI:  logoURI,
+			}
+			if !isValidTokenListToken(token) {
+				continue
+			}
+			tokens[strconv.FormatUint(chainID, 10)+`:`+token.Address] = token
+		}
+	}
+	storage.StoreTokenList(tokens)
+}
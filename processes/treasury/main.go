@@ -0,0 +1,335 @@
+package treasury
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+
+	goEth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yearn/ydaemon/common/addresses"
+	"github.com/yearn/ydaemon/common/bigNumber"
+	"github.com/yearn/ydaemon/common/env"
+	"github.com/yearn/ydaemon/common/ethereum"
+	"github.com/yearn/ydaemon/common/helpers"
+	"github.com/yearn/ydaemon/common/logs"
+	"github.com/yearn/ydaemon/internal/backfill"
+	"github.com/yearn/ydaemon/internal/models"
+	"github.com/yearn/ydaemon/internal/multicalls"
+	"github.com/yearn/ydaemon/internal/storage"
+)
+
+var transferTopic = crypto.Keccak256Hash([]byte(`Transfer(address,address,uint256)`))
+
+/**************************************************************************************************
+** THolding is the balance of a token held by one of the treasury addresses, valued at the current
+** price of the token.
+**************************************************************************************************/
+type THolding struct {
+	Holder   common.Address `json:"holder"`
+	Token    common.Address `json:"token"`
+	Symbol   string         `json:"symbol"`
+	Decimals uint64         `json:"decimals"`
+	Balance  string         `json:"balance"`
+	Amount   float64        `json:"amount"`
+	Value    float64        `json:"value"`
+}
+
+/**************************************************************************************************
+** TTransfer is a transfer of tokens received by a treasury address, see storage.TTreasuryTransfer.
+**************************************************************************************************/
+type TTransfer = storage.TTreasuryTransfer
+
+var (
+	holdings    = make(map[uint64][]THolding)
+	feeInflows  = make(map[uint64][]TTransfer)
+	buybacks    = make(map[uint64][]TTransfer)
+	nextBlock   = make(map[uint64]uint64)
+	treasuryMtx sync.RWMutex
+)
+
+/**************************************************************************************************
+** IsTracked returns true when the treasury of the chain is configured.
+**************************************************************************************************/
+func IsTracked(chainID uint64) bool {
+	chain, ok := env.GetChain(chainID)
+	return ok && len(chain.Treasury.Addresses) > 0
+}
+
+/**************************************************************************************************
+** RefreshTreasury reads the holdings of the treasury of a chain and indexes the transfers it
+** received since the last refresh.
+**************************************************************************************************/
+func RefreshTreasury(chainID uint64) {
+	if !IsTracked(chainID) {
+		return
+	}
+	retrieveHoldings(chainID)
+	indexTransfers(chainID)
+}
+
+/**************************************************************************************************
+** retrieveHoldings reads, in one multicall, the balance of every known token of the chain held by
+** the treasury addresses, plus their balance of the native coin. The empty balances are dropped.
+**************************************************************************************************/
+func retrieveHoldings(chainID uint64) {
+	chain, _ := env.GetChain(chainID)
+	tokens, _ := storage.ListERC20(chainID)
+
+	calls := []ethereum.Call{}
+	for _, holder := range chain.Treasury.Addresses {
+		for _, token := range tokens {
+			calls = append(calls, multicalls.GetBalanceOf(holder.Hex()+token.Address.Hex(), token.Address, holder))
+		}
+	}
+	response := multicalls.Perform(chainID, calls, nil)
+
+	newHoldings := []THolding{}
+	for _, holder := range chain.Treasury.Addresses {
+		for _, token := range tokens {
+			balance := helpers.DecodeBigInt(response[holder.Hex()+token.Address.Hex()+`balanceOf`])
+			if balance.IsZero() {
+				continue
+			}
+			newHoldings = append(newHoldings, toHolding(chainID, holder, token, balance))
+		}
+
+		coinBalance, err := ethereum.GetRPC(chainID).BalanceAt(context.Background(), holder, nil)
+		if err != nil {
+			logs.Error(`Failed to read the native balance of the treasury ` + holder.Hex() + `: ` + err.Error())
+			continue
+		}
+		if coinBalance.Sign() > 0 {
+			newHoldings = append(newHoldings, toHolding(chainID, holder, chain.Coin, bigNumber.SetInt(coinBalance)))
+		}
+	}
+	sort.Slice(newHoldings, func(i, j int) bool {
+		return newHoldings[i].Value > newHoldings[j].Value
+	})
+
+	treasuryMtx.Lock()
+	holdings[chainID] = newHoldings
+	treasuryMtx.Unlock()
+}
+
+func toHolding(chainID uint64, holder common.Address, token models.TERC20Token, balance *bigNumber.Int) THolding {
+	amount := helpers.ToNormalizedFloat(balance, token.Decimals)
+	return THolding{
+		Holder:   holder,
+		Token:    token.Address,
+		Symbol:   token.Symbol,
+		Decimals: token.Decimals,
+		Balance:  balance.String(),
+		Amount:   amount,
+		Value:    amount * getPrice(chainID, token),
+	}
+}
+
+/**************************************************************************************************
+** getPrice returns the current USD price of a token. The native coin falls back to the price of
+** the wrapped coin when it is not priced itself.
+**************************************************************************************************/
+func getPrice(chainID uint64, token models.TERC20Token) float64 {
+	price, ok := storage.GetPrice(chainID, token.Address)
+	if !ok && addresses.Equals(token.Address, env.DEFAULT_COIN_ADDRESS) {
+		chain, _ := env.GetChain(chainID)
+		price, ok = storage.GetPrice(chainID, chain.GasPolicy.WrappedCoin)
+	}
+	if !ok || price.HumanizedPrice == nil {
+		return 0
+	}
+	value, _ := price.HumanizedPrice.Float64()
+	return value
+}
+
+/**************************************************************************************************
+** indexTransfers scans the Transfer events received by the treasury addresses since the last
+** scanned block, up to the last confirmed block, and keeps:
+** - the fee inflows: the vault shares minted to the treasury (the v2 vaults mint their fees to
+**   their rewards address) or sent by the accountant of the vault (v3),
+** - the buybacks: the YFI sent to the treasury by one of the buyback contracts.
+** The transfers and the scanned block are kept chunk by chunk and persisted, so a failure or a
+** restart resumes the scan from the last scanned chunk.
+**************************************************************************************************/
+func indexTransfers(chainID uint64) {
+	chain, _ := env.GetChain(chainID)
+	client := ethereum.GetRPC(chainID)
+	loadTransfers(chainID)
+
+	treasuryMtx.RLock()
+	start := nextBlock[chainID]
+	treasuryMtx.RUnlock()
+	if start < chain.Treasury.StartBlock {
+		start = chain.Treasury.StartBlock
+	}
+	end, err := ethereum.GetConfirmedBlockNumber(chainID)
+	if err != nil || end <= start {
+		return
+	}
+
+	recipients := []common.Hash{}
+	for _, holder := range chain.Treasury.Addresses {
+		recipients = append(recipients, common.BytesToHash(holder.Bytes()))
+	}
+
+	feeInflowsCount, buybacksCount := 0, 0
+	blockTimes := make(map[uint64]uint64)
+	logsRange := chain.GetLogsRange()
+	scan := backfill.StartScan(chainID, `treasury`, start, end, logsRange)
+	defer scan.Finish()
+	for chunkStart := start; chunkStart <= end; chunkStart += logsRange {
+		chunkEnd := chunkStart + logsRange - 1
+		if chunkEnd > end {
+			chunkEnd = end
+		}
+		query := goEth.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(chunkStart),
+			ToBlock:   new(big.Int).SetUint64(chunkEnd),
+			Topics:    [][]common.Hash{{transferTopic}, {}, recipients},
+		}
+		if !scan.Acquire() {
+			break // Backfills paused, resumed from the last scanned chunk
+		}
+		history, err := client.FilterLogs(context.Background(), query)
+		scan.Release(err)
+		if err != nil {
+			logs.Error(`Failed to filter the transfers to the treasury on chain ` + strconv.FormatUint(chainID, 10) + `: ` + err.Error())
+			break // Retried from the last scanned chunk on the next refresh
+		}
+
+		newFeeInflows := []TTransfer{}
+		newBuybacks := []TTransfer{}
+		for _, log := range history {
+			if len(log.Topics) != 3 || len(log.Data) != 32 || log.Removed {
+				continue // Not an ERC20 transfer (e.g. an ERC721 one)
+			}
+			if _, ok := blockTimes[log.BlockNumber]; !ok {
+				blockTimes[log.BlockNumber] = ethereum.GetBlockTime(chainID, log.BlockNumber)
+			}
+			from := common.BytesToAddress(log.Topics[1].Bytes())
+			if isBuyback(chain, log, from) {
+				newBuybacks = append(newBuybacks, toTransfer(chainID, log, blockTimes[log.BlockNumber]))
+			} else if isFeeInflow(chainID, log, from) {
+				newFeeInflows = append(newFeeInflows, toTransfer(chainID, log, blockTimes[log.BlockNumber]))
+			}
+		}
+
+		treasuryMtx.Lock()
+		feeInflows[chainID] = append(feeInflows[chainID], newFeeInflows...)
+		buybacks[chainID] = append(buybacks[chainID], newBuybacks...)
+		nextBlock[chainID] = chunkEnd + 1
+		treasuryMtx.Unlock()
+		feeInflowsCount += len(newFeeInflows)
+		buybacksCount += len(newBuybacks)
+	}
+
+	storeTransfers(chainID)
+	logs.Info(`Indexed ` + strconv.Itoa(feeInflowsCount) + ` fee inflows and ` + strconv.Itoa(buybacksCount) + ` buybacks of the treasury on chain ` + strconv.FormatUint(chainID, 10))
+}
+
+/**************************************************************************************************
+** loadTransfers loads the transfers persisted for a chain, once.
+**************************************************************************************************/
+func loadTransfers(chainID uint64) {
+	treasuryMtx.RLock()
+	_, isLoaded := nextBlock[chainID]
+	treasuryMtx.RUnlock()
+	if isLoaded {
+		return
+	}
+
+	stored := storage.LoadTreasuryTransfers(chainID)
+	treasuryMtx.Lock()
+	feeInflows[chainID] = stored.FeeInflows
+	buybacks[chainID] = stored.Buybacks
+	nextBlock[chainID] = stored.NextBlock
+	treasuryMtx.Unlock()
+}
+
+func storeTransfers(chainID uint64) {
+	treasuryMtx.RLock()
+	stored := storage.TJsonTreasuryStorage{
+		NextBlock:  nextBlock[chainID],
+		FeeInflows: append([]TTransfer{}, feeInflows[chainID]...),
+		Buybacks:   append([]TTransfer{}, buybacks[chainID]...),
+	}
+	treasuryMtx.RUnlock()
+	storage.StoreTreasuryTransfers(chainID, stored)
+}
+
+func isBuyback(chain env.TChain, log types.Log, from common.Address) bool {
+	if !addresses.Equals(log.Address, chain.Treasury.BuybackToken) {
+		return false
+	}
+	for _, buyer := range chain.Treasury.BuybackContracts {
+		if addresses.Equals(from, buyer) {
+			return true
+		}
+	}
+	return false
+}
+
+func isFeeInflow(chainID uint64, log types.Log, from common.Address) bool {
+	vault, ok := storage.GetVault(chainID, log.Address)
+	if !ok {
+		return false
+	}
+	if vault.Accountant != nil && addresses.Equals(from, *vault.Accountant) {
+		return true
+	}
+	return addresses.Equals(from, common.Address{})
+}
+
+func toTransfer(chainID uint64, log types.Log, timestamp uint64) TTransfer {
+	token, ok := storage.GetERC20(chainID, log.Address)
+	if !ok {
+		token = models.TERC20Token{Address: log.Address, Decimals: 18}
+	}
+	balance := bigNumber.SetInt(new(big.Int).SetBytes(log.Data))
+	amount := helpers.ToNormalizedFloat(balance, token.Decimals)
+	return TTransfer{
+		ChainID:     chainID,
+		Token:       log.Address,
+		Symbol:      token.Symbol,
+		From:        common.BytesToAddress(log.Topics[1].Bytes()),
+		To:          common.BytesToAddress(log.Topics[2].Bytes()),
+		Balance:     balance.String(),
+		Amount:      amount,
+		Value:       amount * getPrice(chainID, token),
+		TxHash:      log.TxHash,
+		LogIndex:    log.Index,
+		BlockNumber: log.BlockNumber,
+		Timestamp:   timestamp,
+	}
+}
+
+/**************************************************************************************************
+** ListHoldings returns the current holdings of the treasury of a chain, by decreasing value.
+**************************************************************************************************/
+func ListHoldings(chainID uint64) []THolding {
+	treasuryMtx.RLock()
+	defer treasuryMtx.RUnlock()
+	return append([]THolding{}, holdings[chainID]...)
+}
+
+/**************************************************************************************************
+** ListFeeInflows returns the fees received by the treasury of a chain, oldest first.
+**************************************************************************************************/
+func ListFeeInflows(chainID uint64) []TTransfer {
+	treasuryMtx.RLock()
+	defer treasuryMtx.RUnlock()
+	return append([]TTransfer{}, feeInflows[chainID]...)
+}
+
+/**************************************************************************************************
+** ListBuybacks returns the YFI buybacks received by the treasury of a chain, oldest first.
+**************************************************************************************************/
+func ListBuybacks(chainID uint64) []TTransfer {
+	treasuryMtx.RLock()
+	defer treasuryMtx.RUnlock()
+	return append([]TTransfer{}, buybacks[chainID]...)
+}